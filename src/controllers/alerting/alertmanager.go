@@ -0,0 +1,168 @@
+// src/controllers/alerting/alertmanager.go
+
+// Package alerting talks to the alerting backends QRAIOP integrates with.
+package alerting
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// CreatedBy identifies silences created by the operator
+const CreatedBy = "qraiop-controller"
+
+// Matcher matches alerts on a label, as in the Alertmanager v2 API
+type Matcher struct {
+    Name    string `json:"name"`
+    Value   string `json:"value"`
+    IsRegex bool   `json:"isRegex"`
+    IsEqual bool   `json:"isEqual"`
+}
+
+// Silence is an Alertmanager silence. An empty ID creates a new silence,
+// otherwise the existing one is replaced.
+type Silence struct {
+    ID        string    `json:"id,omitempty"`
+    Matchers  []Matcher `json:"matchers"`
+    StartsAt  time.Time `json:"startsAt"`
+    EndsAt    time.Time `json:"endsAt"`
+    CreatedBy string    `json:"createdBy"`
+    Comment   string    `json:"comment"`
+}
+
+// Alertmanager is a client for the Alertmanager v2 API
+type Alertmanager struct {
+    URL  string
+    HTTP *http.Client
+    // Token is sent as bearer token when set, for Alertmanagers behind an
+    // authenticating proxy or Grafana's
+    Token string
+}
+
+// CreateSilence creates or replaces a silence and returns its ID.
+// Alertmanager may assign a new ID when an existing silence is replaced.
+func (a *Alertmanager) CreateSilence(ctx context.Context, s Silence) (string, error) {
+    body, err := json.Marshal(s)
+    if err != nil {
+        return "", err
+    }
+    var resp struct {
+        SilenceID string `json:"silenceID"`
+    }
+    if err := a.do(ctx, http.MethodPost, "/api/v2/silences", body, &resp); err != nil {
+        return "", err
+    }
+    return resp.SilenceID, nil
+}
+
+// ExpireSilence ends a silence now. Expiring an unknown silence is not an error.
+func (a *Alertmanager) ExpireSilence(ctx context.Context, id string) error {
+    err := a.do(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+    if IsNotFound(err) {
+        return nil
+    }
+    return err
+}
+
+// StatusError is a non-2xx Alertmanager or Grafana response
+type StatusError struct {
+    Code    int
+    Message string
+    // Server is the kind of server that responded; alertmanager when unset
+    Server string
+}
+
+func (e *StatusError) Error() string {
+    server := e.Server
+    if server == "" {
+        server = "alertmanager"
+    }
+    return fmt.Sprintf("%s returned %d: %s", server, e.Code, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from Alertmanager or Grafana
+func IsNotFound(err error) bool {
+    se, ok := err.(*StatusError)
+    return ok && se.Code == http.StatusNotFound
+}
+
+func (a *Alertmanager) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+    var reader io.Reader
+    if body != nil {
+        reader = bytes.NewReader(body)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(a.URL, "/")+path, reader)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    if a.Token != "" {
+        req.Header.Set("Authorization", "Bearer "+a.Token)
+    }
+    resp, err := a.HTTP.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+    }
+    if out == nil {
+        return nil
+    }
+    return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Alert is an alert as listed by Alertmanager
+type Alert struct {
+    Labels      map[string]string `json:"labels"`
+    Annotations map[string]string `json:"annotations"`
+    StartsAt    time.Time         `json:"startsAt"`
+    EndsAt      time.Time         `json:"endsAt"`
+    Status      struct {
+        State string `json:"state"`
+    } `json:"status"`
+}
+
+// Alerts lists the alerts matching filter, in Alertmanager's matcher
+// syntax such as namespace="qraiop-system", silenced and inhibited included
+func (a *Alertmanager) Alerts(ctx context.Context, filter ...string) ([]Alert, error) {
+    q := url.Values{}
+    for _, f := range filter {
+        q.Add("filter", f)
+    }
+    var alerts []Alert
+    if err := a.do(ctx, http.MethodGet, "/api/v2/alerts?"+q.Encode(), nil, &alerts); err != nil {
+        return nil, err
+    }
+    return alerts, nil
+}
+
+// PostableAlert is an alert sent to Alertmanager
+type PostableAlert struct {
+    Labels      map[string]string `json:"labels"`
+    Annotations map[string]string `json:"annotations,omitempty"`
+    StartsAt    time.Time         `json:"startsAt,omitempty"`
+    EndsAt      time.Time         `json:"endsAt,omitempty"`
+}
+
+// PostAlerts sends alerts to Alertmanager, which routes them to the
+// receivers like those Prometheus fires
+func (a *Alertmanager) PostAlerts(ctx context.Context, alerts []PostableAlert) error {
+    body, err := json.Marshal(alerts)
+    if err != nil {
+        return err
+    }
+    return a.do(ctx, http.MethodPost, "/api/v2/alerts", body, nil)
+}
@@ -0,0 +1,261 @@
+// src/controllers/alerting/grafana.go
+package alerting
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+)
+
+// Grafana is a client for the alerting provisioning API of Grafana 9.1 and
+// later. Objects it writes are marked as provisioned, so they can't be
+// edited in the Grafana UI and drift from the spec.
+type Grafana struct {
+    URL string
+    // Token is a service account token with the alerting provisioning
+    // and folder permissions
+    Token string
+    OrgID int64
+    HTTP  *http.Client
+}
+
+// ContactPoint is a Grafana contact point with a single integration
+type ContactPoint struct {
+    UID      string            `json:"uid,omitempty"`
+    Name     string            `json:"name"`
+    Type     string            `json:"type"`
+    Settings map[string]string `json:"settings"`
+}
+
+// Route is a node of Grafana's notification policy tree
+type Route struct {
+    Receiver       string     `json:"receiver,omitempty"`
+    GroupBy        []string   `json:"group_by,omitempty"`
+    ObjectMatchers [][]string `json:"object_matchers,omitempty"`
+    Continue       bool       `json:"continue,omitempty"`
+    Routes         []Route    `json:"routes,omitempty"`
+    // Rest keeps the fields the operator doesn't manage, so writing the
+    // tree back doesn't drop them
+    Rest map[string]json.RawMessage `json:"-"`
+}
+
+// Rule is an alert rule on a Prometheus query: it fires once the query's
+// value stays above Threshold for For
+type Rule struct {
+    UID         string
+    Title       string
+    Expr        string
+    Threshold   float64
+    For         string
+    Labels      map[string]string
+    Annotations map[string]string
+}
+
+// AlertmanagerAPI returns a client for Grafana's built-in Alertmanager,
+// which serves the Alertmanager v2 API for silences and alerts
+func (g *Grafana) AlertmanagerAPI() *Alertmanager {
+    return &Alertmanager{URL: strings.TrimSuffix(g.URL, "/") + "/api/alertmanager/grafana", HTTP: g.HTTP, Token: g.Token}
+}
+
+// EnsureFolder creates the folder with uid unless it exists
+func (g *Grafana) EnsureFolder(ctx context.Context, uid, title string) error {
+    err := g.do(ctx, http.MethodGet, "/api/folders/"+url.PathEscape(uid), nil, nil)
+    if !IsNotFound(err) {
+        return err
+    }
+    body, _ := json.Marshal(map[string]string{"uid": uid, "title": title})
+    return g.do(ctx, http.MethodPost, "/api/folders", body, nil)
+}
+
+// ContactPoints lists the contact points whose name starts with prefix
+func (g *Grafana) ContactPoints(ctx context.Context, prefix string) ([]ContactPoint, error) {
+    var all []ContactPoint
+    if err := g.do(ctx, http.MethodGet, "/api/v1/provisioning/contact-points", nil, &all); err != nil {
+        return nil, err
+    }
+    var matched []ContactPoint
+    for _, cp := range all {
+        if strings.HasPrefix(cp.Name, prefix) {
+            matched = append(matched, cp)
+        }
+    }
+    return matched, nil
+}
+
+// PutContactPoint creates the contact point, or replaces the one of the
+// same UID
+func (g *Grafana) PutContactPoint(ctx context.Context, cp ContactPoint) error {
+    body, err := json.Marshal(cp)
+    if err != nil {
+        return err
+    }
+    err = g.do(ctx, http.MethodPut, "/api/v1/provisioning/contact-points/"+url.PathEscape(cp.UID), body, nil)
+    if IsNotFound(err) {
+        return g.do(ctx, http.MethodPost, "/api/v1/provisioning/contact-points", body, nil)
+    }
+    return err
+}
+
+// DeleteContactPoint removes a contact point. Deleting an unknown one is
+// not an error.
+func (g *Grafana) DeleteContactPoint(ctx context.Context, uid string) error {
+    err := g.do(ctx, http.MethodDelete, "/api/v1/provisioning/contact-points/"+url.PathEscape(uid), nil, nil)
+    if IsNotFound(err) {
+        return nil
+    }
+    return err
+}
+
+// PolicyTree returns the root of the notification policy tree
+func (g *Grafana) PolicyTree(ctx context.Context) (*Route, error) {
+    var root Route
+    if err := g.do(ctx, http.MethodGet, "/api/v1/provisioning/policies", nil, &root); err != nil {
+        return nil, err
+    }
+    return &root, nil
+}
+
+// PutPolicyTree replaces the notification policy tree
+func (g *Grafana) PutPolicyTree(ctx context.Context, root *Route) error {
+    body, err := json.Marshal(root)
+    if err != nil {
+        return err
+    }
+    return g.do(ctx, http.MethodPut, "/api/v1/provisioning/policies", body, nil)
+}
+
+// PutRuleGroup replaces the rules of a group in a folder, evaluated every
+// interval seconds
+func (g *Grafana) PutRuleGroup(ctx context.Context, folderUID, group, datasourceUID string, interval int, rules []Rule) error {
+    out := make([]map[string]interface{}, 0, len(rules))
+    for _, r := range rules {
+        out = append(out, map[string]interface{}{
+            "uid":          r.UID,
+            "title":        r.Title,
+            "folderUID":    folderUID,
+            "ruleGroup":    group,
+            "condition":    "B",
+            "for":          r.For,
+            "labels":       r.Labels,
+            "annotations":  r.Annotations,
+            "noDataState":  "OK",
+            "execErrState": "Error",
+            "data": []map[string]interface{}{
+                {
+                    "refId":             "A",
+                    "datasourceUid":     datasourceUID,
+                    "relativeTimeRange": map[string]int{"from": 600, "to": 0},
+                    "model":             map[string]interface{}{"refId": "A", "expr": r.Expr, "instant": true},
+                },
+                {
+                    "refId":         "B",
+                    "datasourceUid": "__expr__",
+                    "model": map[string]interface{}{
+                        "refId":      "B",
+                        "type":       "threshold",
+                        "expression": "A",
+                        "conditions": []map[string]interface{}{{
+                            "evaluator": map[string]interface{}{"type": "gt", "params": []float64{r.Threshold}},
+                        }},
+                    },
+                },
+            },
+        })
+    }
+    body, err := json.Marshal(map[string]interface{}{
+        "title":     group,
+        "folderUid": folderUID,
+        "interval":  interval,
+        "rules":     out,
+    })
+    if err != nil {
+        return err
+    }
+    return g.do(ctx, http.MethodPut, "/api/v1/provisioning/folder/"+url.PathEscape(folderUID)+"/rule-groups/"+url.PathEscape(group), body, nil)
+}
+
+// DeleteRuleGroup removes a rule group. Deleting an unknown one is not an
+// error.
+func (g *Grafana) DeleteRuleGroup(ctx context.Context, folderUID, group string) error {
+    err := g.do(ctx, http.MethodDelete, "/api/v1/provisioning/folder/"+url.PathEscape(folderUID)+"/rule-groups/"+url.PathEscape(group), nil, nil)
+    if IsNotFound(err) {
+        return nil
+    }
+    return err
+}
+
+func (g *Grafana) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+    var reader io.Reader
+    if body != nil {
+        reader = bytes.NewReader(body)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(g.URL, "/")+path, reader)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    req.Header.Set("Authorization", "Bearer "+g.Token)
+    if g.OrgID > 0 {
+        req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(g.OrgID, 10))
+    }
+    resp, err := g.HTTP.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(msg)), Server: "grafana"}
+    }
+    if out == nil {
+        return nil
+    }
+    return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// UnmarshalJSON keeps the fields of a route the operator doesn't manage
+func (r *Route) UnmarshalJSON(b []byte) error {
+    type plain Route
+    if err := json.Unmarshal(b, (*plain)(r)); err != nil {
+        return err
+    }
+    if err := json.Unmarshal(b, &r.Rest); err != nil {
+        return err
+    }
+    for _, k := range []string{"receiver", "group_by", "object_matchers", "continue", "routes"} {
+        delete(r.Rest, k)
+    }
+    return nil
+}
+
+// MarshalJSON writes the managed fields over the kept ones
+func (r Route) MarshalJSON() ([]byte, error) {
+    type plain Route
+    managed, err := json.Marshal(plain(r))
+    if err != nil {
+        return nil, err
+    }
+    if len(r.Rest) == 0 {
+        return managed, nil
+    }
+    fields := make(map[string]json.RawMessage, len(r.Rest)+5)
+    for k, v := range r.Rest {
+        fields[k] = v
+    }
+    var m map[string]json.RawMessage
+    if err := json.Unmarshal(managed, &m); err != nil {
+        return nil, err
+    }
+    for k, v := range m {
+        fields[k] = v
+    }
+    return json.Marshal(fields)
+}
@@ -0,0 +1,89 @@
+// src/controllers/alerting/prometheus.go
+package alerting
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+)
+
+// Prometheus is a client for the instant queries of the Prometheus HTTP API
+type Prometheus struct {
+    URL  string
+    HTTP *http.Client
+    // Token is sent as bearer token when set
+    Token string
+}
+
+// Query evaluates expr now and returns the value of its first sample, or
+// false when the result is empty, e.g. without traffic. NaN results, such
+// as ratios over no requests, count as empty.
+func (p *Prometheus) Query(ctx context.Context, expr string) (float64, bool, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+        strings.TrimSuffix(p.URL, "/")+"/api/v1/query?"+url.Values{"query": {expr}}.Encode(), nil)
+    if err != nil {
+        return 0, false, err
+    }
+    if p.Token != "" {
+        req.Header.Set("Authorization", "Bearer "+p.Token)
+    }
+    resp, err := p.HTTP.Do(req)
+    if err != nil {
+        return 0, false, err
+    }
+    defer resp.Body.Close()
+
+    var body struct {
+        Status string `json:"status"`
+        Error  string `json:"error"`
+        Data   struct {
+            ResultType string          `json:"resultType"`
+            Result     json.RawMessage `json:"result"`
+        } `json:"data"`
+    }
+    if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+        return 0, false, &StatusError{Code: resp.StatusCode, Message: "undecodable response", Server: "prometheus"}
+    }
+    if body.Status != "success" {
+        return 0, false, &StatusError{Code: resp.StatusCode, Message: body.Error, Server: "prometheus"}
+    }
+
+    // Samples are [timestamp, "value"]
+    var sample []interface{}
+    switch body.Data.ResultType {
+    case "scalar":
+        if err := json.Unmarshal(body.Data.Result, &sample); err != nil {
+            return 0, false, err
+        }
+    case "vector":
+        var vector []struct {
+            Value []interface{} `json:"value"`
+        }
+        if err := json.Unmarshal(body.Data.Result, &vector); err != nil {
+            return 0, false, err
+        }
+        if len(vector) == 0 {
+            return 0, false, nil
+        }
+        sample = vector[0].Value
+    default:
+        return 0, false, fmt.Errorf("prometheus returned a %s, want a vector or scalar", body.Data.ResultType)
+    }
+    if len(sample) != 2 {
+        return 0, false, fmt.Errorf("prometheus returned a malformed sample")
+    }
+    s, _ := sample[1].(string)
+    v, err := strconv.ParseFloat(s, 64)
+    if err != nil {
+        return 0, false, fmt.Errorf("prometheus returned value %q: %w", s, err)
+    }
+    if v != v {
+        return 0, false, nil
+    }
+    return v, true, nil
+}
@@ -0,0 +1,132 @@
+// src/controllers/alerting/templates.go
+package alerting
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// maxNotificationSize caps rendered notifications so a runaway template
+// can't produce unbounded messages
+const maxNotificationSize = 32 << 10
+
+// Notification is the data notification templates are executed with
+type Notification struct {
+    Title     string            `json:"title"`
+    Severity  string            `json:"severity"`
+    Instance  string            `json:"instance"`
+    Namespace string            `json:"namespace"`
+    Summary   string            `json:"summary"`
+    Details   string            `json:"details,omitempty"`
+    Time      time.Time         `json:"time"`
+    Labels    map[string]string `json:"labels,omitempty"`
+    // Data is the structured source of the notification, such as an
+    // incident report
+    Data interface{} `json:"data,omitempty"`
+}
+
+// DefaultTemplates are used for channel types without a template of their own
+var DefaultTemplates = map[string]string{
+    "slack":     `*{{ .Title }}* ({{ .Severity }}, {{ .Namespace }}/{{ .Instance }}){{ "\n" }}{{ .Summary }}{{ if .Details }}{{ "\n\n" }}{{ .Details }}{{ end }}`,
+    "email":     `Subject: [{{ upper .Severity }}] {{ .Title }}{{ "\n\n" }}{{ .Summary }}{{ if .Details }}{{ "\n\n" }}{{ .Details }}{{ end }}`,
+    "webhook":   `{{ json . }}`,
+    "pagerduty": `{{ truncate 1024 (printf "%s: %s" .Title .Summary) }}`,
+}
+
+// templateFuncs are the functions available to templates. None of them
+// reach outside the notification.
+var templateFuncs = template.FuncMap{
+    "upper": strings.ToUpper,
+    "lower": strings.ToLower,
+    "json": func(v interface{}) (string, error) {
+        b, err := json.Marshal(v)
+        return string(b), err
+    },
+    "truncate": func(n int, s string) string {
+        if len(s) <= n {
+            return s
+        }
+        return s[:n]
+    },
+    "join": strings.Join,
+}
+
+// TemplateKey is the ConfigMap key holding the template of a channel type
+func TemplateKey(channelType string) string {
+    return channelType + ".tmpl"
+}
+
+// Templates formats notifications per channel type
+type Templates struct {
+    byType map[string]*template.Template
+}
+
+// ParseTemplates parses the templates of a ConfigMap's data, over the
+// defaults. Each is also executed against a sample notification so that
+// references to unknown fields are caught before anything is sent.
+func ParseTemplates(data map[string]string) (*Templates, error) {
+    sources := make(map[string]string, len(DefaultTemplates))
+    for t, src := range DefaultTemplates {
+        sources[t] = src
+    }
+    keys := make([]string, 0, len(data))
+    for k := range data {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        t := strings.TrimSuffix(k, ".tmpl")
+        if _, ok := DefaultTemplates[t]; !ok || t == k {
+            return nil, fmt.Errorf("%s: not a template for a channel type (want one of slack.tmpl, email.tmpl, webhook.tmpl, pagerduty.tmpl)", k)
+        }
+        sources[t] = data[k]
+    }
+
+    tpl := &Templates{byType: make(map[string]*template.Template, len(sources))}
+    sample := Notification{Title: "sample", Severity: "info", Instance: "sample", Namespace: "default", Summary: "sample", Time: time.Now()}
+    for t, src := range sources {
+        parsed, err := template.New(t).Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", TemplateKey(t), err)
+        }
+        if _, err := execute(parsed, sample); err != nil {
+            return nil, fmt.Errorf("%s: %w", TemplateKey(t), err)
+        }
+        tpl.byType[t] = parsed
+    }
+    return tpl, nil
+}
+
+// Render formats n for a channel type. Should a template fail on real data
+// the built-in one is used, so a notification always goes out.
+func (t *Templates) Render(channelType string, n Notification) (string, error) {
+    parsed, ok := t.byType[channelType]
+    if !ok {
+        return "", fmt.Errorf("no template for channel type %q", channelType)
+    }
+    out, err := execute(parsed, n)
+    if err == nil {
+        return out, nil
+    }
+    fallback, parseErr := template.New(channelType).Funcs(templateFuncs).Parse(DefaultTemplates[channelType])
+    if parseErr != nil {
+        return "", err
+    }
+    return execute(fallback, n)
+}
+
+func execute(t *template.Template, n Notification) (string, error) {
+    var buf bytes.Buffer
+    if err := t.Execute(&buf, n); err != nil {
+        return "", err
+    }
+    if buf.Len() > maxNotificationSize {
+        return "", fmt.Errorf("rendered notification is %d bytes, over the %d byte limit", buf.Len(), maxNotificationSize)
+    }
+    return buf.String(), nil
+}
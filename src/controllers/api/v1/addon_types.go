@@ -0,0 +1,100 @@
+// src/controllers/api/v1/addon_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// AddonComponentPrefix starts the component name of every addon
+const AddonComponentPrefix = "addon/"
+
+// QraiopAddonSpec registers a third-party component with the Qraiop
+// instance of its namespace. The addon is either an image, which the
+// operator deploys like its own components, or a set of rendered manifests
+// it applies as given; exactly one of the two is set.
+// +kubebuilder:validation:XValidation:rule="has(self.image) != has(self.manifests)",message="exactly one of image and manifests must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.manifests) || has(self.deployment)",message="manifests need the deployment reporting their readiness"
+type QraiopAddonSpec struct {
+    // Instance is the Qraiop instance the addon extends
+    Instance string `json:"instance"`
+
+    // Image of the addon. It gets a Deployment, ServiceAccount and Service
+    // named qraiop-addon-<name>, and must serve HTTP on Port.
+    Image string `json:"image,omitempty"`
+    // Port the image serves on
+    // +kubebuilder:default=8080
+    Port int32 `json:"port,omitempty"`
+    // Replicas of the image; defaults to the instance's profile
+    Replicas  *int32                       `json:"replicas,omitempty"`
+    Args      []string                     `json:"args,omitempty"`
+    Env       []corev1.EnvVar              `json:"env,omitempty"`
+    Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+    // Manifests are namespaced objects applied into the instance's
+    // namespace and pruned with the addon. They are written with the
+    // operator's identity, or the tenant's under impersonation, so only
+    // kinds it may write are accepted.
+    // +kubebuilder:pruning:PreserveUnknownFields
+    // +kubebuilder:validation:EmbeddedResource
+    Manifests []runtime.RawExtension `json:"manifests,omitempty"`
+    // Deployment among the manifests whose readiness is the addon's
+    Deployment string `json:"deployment,omitempty"`
+
+    // DependsOn are the components that must be Ready before the addon is
+    // rolled out, e.g. cryptography
+    DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// QraiopAddonStatus mirrors the addon's entry in the instance's components
+type QraiopAddonStatus struct {
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+    // Component is the addon's name under the instance's components
+    Component string `json:"component,omitempty"`
+    // Status is Ready, Progressing, Blocked, Disabled or Degraded
+    Status      string       `json:"status,omitempty"`
+    Message     string       `json:"message,omitempty"`
+    LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=qa
+// +kubebuilder:printcolumn:name="Instance",type=string,JSONPath=`.spec.instance`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type QraiopAddon struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   QraiopAddonSpec   `json:"spec,omitempty"`
+    Status QraiopAddonStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type QraiopAddonList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []QraiopAddon `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for QraiopAddon
+func (in *QraiopAddon) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for QraiopAddonList
+func (in *QraiopAddonList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&QraiopAddon{}, &QraiopAddonList{})
+}
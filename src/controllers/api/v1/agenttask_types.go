@@ -0,0 +1,120 @@
+// src/controllers/api/v1/agenttask_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// AgentLabel names the agent a heartbeat Lease belongs to. Each agent
+// renews the Lease qraiop-agent-<name> in its instance namespace; an agent
+// whose Lease is not renewed within its leaseDurationSeconds is NotReady.
+const AgentLabel = "qraiop.io/agent"
+
+// AgentTask phases
+const (
+    TaskQueued    = "Queued"
+    TaskAssigned  = "Assigned"
+    TaskRunning   = "Running"
+    TaskSucceeded = "Succeeded"
+    TaskFailed    = "Failed"
+    TaskExpired   = "Expired"
+)
+
+// AgentTaskSpec defines the desired state of AgentTask
+type AgentTaskSpec struct {
+    // Agent is the agent that should run the task
+    // +kubebuilder:validation:Enum=supervisor;security;infrastructure;monitoring;chaos
+    Agent string `json:"agent"`
+
+    // Type is the kind of task, as understood by the agent, e.g. security_audit
+    Type string `json:"type"`
+
+    // +kubebuilder:validation:Enum=low;medium;high;critical
+    // +kubebuilder:default=medium
+    Priority string `json:"priority,omitempty"`
+
+    // Parameters are passed to the agent as-is
+    Parameters map[string]string `json:"parameters,omitempty"`
+
+    // ExpireAfter is how long the task may wait or run before it is
+    // expired, counted from its creation
+    // +kubebuilder:default="1h"
+    ExpireAfter metav1.Duration `json:"expireAfter,omitempty"`
+
+    // MaxReassignments is how often the task is queued again after its
+    // agent went NotReady before it fails
+    // +kubebuilder:validation:Minimum=0
+    // +kubebuilder:default=3
+    MaxReassignments int32 `json:"maxReassignments,omitempty"`
+}
+
+// AgentTaskStatus defines the observed state of AgentTask. The controller
+// queues, assigns, reassigns and expires tasks; the assigned agent moves
+// them to Running and reports the outcome.
+type AgentTaskStatus struct {
+    // +kubebuilder:validation:Enum=Queued;Assigned;Running;Succeeded;Failed;Expired
+    Phase string `json:"phase,omitempty"`
+
+    // AssignedTo is the holder identity of the agent's Lease, i.e. the pod
+    // running the task
+    AssignedTo string       `json:"assignedTo,omitempty"`
+    AssignedAt *metav1.Time `json:"assignedAt,omitempty"`
+
+    // Reassignments counts how often the task was taken from a NotReady agent
+    Reassignments int32 `json:"reassignments,omitempty"`
+
+    CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+    // Result is the agent's summary of a finished task
+    Result  string `json:"result,omitempty"`
+    Message string `json:"message,omitempty"`
+}
+
+// IsFinished reports whether the task reached a terminal phase
+func (s *AgentTaskStatus) IsFinished() bool {
+    return s.Phase == TaskSucceeded || s.Phase == TaskFailed || s.Phase == TaskExpired
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=at
+// +kubebuilder:printcolumn:name="Agent",type=string,JSONPath=`.spec.agent`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Priority",type=string,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Assigned To",type=string,JSONPath=`.status.assignedTo`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type AgentTask struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   AgentTaskSpec   `json:"spec,omitempty"`
+    Status AgentTaskStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type AgentTaskList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []AgentTask `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for AgentTask
+func (in *AgentTask) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for AgentTaskList
+func (in *AgentTaskList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&AgentTask{}, &AgentTaskList{})
+}
@@ -0,0 +1,141 @@
+// src/controllers/api/v1/alerting_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AlertingConfig configures how QRAIOP alerts are routed and silenced
+type AlertingConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // Provider is where alerts are routed and silenced. With grafana the
+    // operator provisions contact points for the channels, a notification
+    // policy routing the instance's alerts to them, and the QRAIOP alert
+    // rules, and manages silences in Grafana's built-in Alertmanager.
+    // +kubebuilder:validation:Enum=alertmanager;grafana
+    // +kubebuilder:default=alertmanager
+    Provider string `json:"provider,omitempty"`
+
+    // AlertmanagerURL is the base URL of the Alertmanager API,
+    // e.g. http://alertmanager.monitoring:9093
+    AlertmanagerURL string `json:"alertmanagerURL,omitempty"`
+
+    // Grafana configures the grafana provider
+    Grafana *GrafanaConfig `json:"grafana,omitempty"`
+
+    // Silences suppress alerts during planned disruptions such as
+    // maintenance windows
+    Silences []SilenceConfig `json:"silences,omitempty"`
+
+    // Channels are where the monitoring component sends notifications
+    Channels []AlertChannel `json:"channels,omitempty"`
+
+    // ForbidInlineSecrets rejects channels carrying webhook URLs, tokens or
+    // passwords in Config instead of migrating them into a Secret
+    ForbidInlineSecrets bool `json:"forbidInlineSecrets,omitempty"`
+
+    // Templates names a ConfigMap of Go templates, one per channel type as
+    // <type>.tmpl, formatting notifications. Types without one use the
+    // built-in templates. Label the ConfigMap
+    // qraiop.io/notification-templates=true to have it checked on admission.
+    Templates *corev1.LocalObjectReference `json:"templates,omitempty"`
+}
+
+// Alerting providers
+const (
+    AlertingProviderAlertmanager = "alertmanager"
+    AlertingProviderGrafana      = "grafana"
+)
+
+// GrafanaConfig is the Grafana instance alerting is provisioned in
+type GrafanaConfig struct {
+    // URL is the base URL of Grafana, e.g. http://grafana.monitoring:3000
+    URL string `json:"url"`
+
+    // TokenSecret holds a service account token allowed to provision
+    // alerting and create folders
+    TokenSecret corev1.SecretKeySelector `json:"tokenSecret"`
+
+    // OrgID is the organization to provision in; the token's when unset
+    OrgID int64 `json:"orgID,omitempty"`
+
+    // Folder is the title of the folder holding the alert rules
+    // +kubebuilder:default=QRAIOP
+    Folder string `json:"folder,omitempty"`
+
+    // DatasourceUID is the Prometheus data source the alert rules query
+    DatasourceUID string `json:"datasourceUID"`
+}
+
+// NotificationTemplatesLabel marks ConfigMaps of notification templates,
+// which the admission webhook validates
+const NotificationTemplatesLabel = "qraiop.io/notification-templates"
+
+// AlertChannel is a notification channel. Credentials belong in the Secret
+// named by SecretRef, whose keys are merged over Config. Credentials found
+// inline in Config are moved into a generated Secret by the operator.
+type AlertChannel struct {
+    // +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+    // +kubebuilder:validation:MaxLength=40
+    Name string `json:"name"`
+    // +kubebuilder:validation:Enum=slack;email;webhook;pagerduty
+    Type string `json:"type"`
+
+    Config    map[string]string            `json:"config,omitempty"`
+    SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+    // SecretSource syncs the channel's credentials from an external store
+    // instead of SecretRef, e.g. a Vault secret with a routing_key
+    SecretSource *SecretSource `json:"secretSource,omitempty"`
+}
+
+// SilenceConfig is an Alertmanager silence kept in place by the operator
+// for each window of its schedule
+type SilenceConfig struct {
+    Name string `json:"name"`
+
+    // +kubebuilder:validation:MinItems=1
+    Matchers []SilenceMatcher `json:"matchers"`
+    Comment  string           `json:"comment,omitempty"`
+    Schedule SilenceSchedule  `json:"schedule"`
+}
+
+// SilenceMatcher matches alerts on a label
+type SilenceMatcher struct {
+    Name    string `json:"name"`
+    Value   string `json:"value"`
+    IsRegex bool   `json:"isRegex,omitempty"`
+    // NotEqual matches alerts whose label does not match Value
+    NotEqual bool `json:"notEqual,omitempty"`
+}
+
+// SilenceSchedule is either a one-off window (StartsAt/EndsAt) or a weekly
+// recurring window (Days, StartTime, Duration)
+type SilenceSchedule struct {
+    StartsAt *metav1.Time `json:"startsAt,omitempty"`
+    EndsAt   *metav1.Time `json:"endsAt,omitempty"`
+
+    // Days the recurring window opens on
+    Days []Weekday `json:"days,omitempty"`
+    // StartTime is when the recurring window opens, as HH:MM
+    // +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+    StartTime string          `json:"startTime,omitempty"`
+    Duration  metav1.Duration `json:"duration,omitempty"`
+    // TimeZone of StartTime as an IANA name, UTC by default
+    TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
+// SilenceStatus is the Alertmanager silence currently created for a SilenceConfig
+type SilenceStatus struct {
+    Name     string      `json:"name"`
+    ID       string      `json:"id"`
+    StartsAt metav1.Time `json:"startsAt"`
+    EndsAt   metav1.Time `json:"endsAt"`
+    // Hash of the silence as last sent, to detect spec changes
+    Hash string `json:"hash,omitempty"`
+}
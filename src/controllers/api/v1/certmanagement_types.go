@@ -0,0 +1,274 @@
+// src/controllers/api/v1/certmanagement_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CertManagementConfig configures the certificate authorities injected
+// certificates are issued by, and which of them issues which certificate
+type CertManagementConfig struct {
+    // Authorities are the CAs certificates can be issued by
+    // +listType=map
+    // +listMapKey=name
+    // +kubebuilder:validation:MinItems=1
+    Authorities []CertificateAuthority `json:"authorities"`
+
+    // Routes pick the authority of a certificate; the first matching route
+    // wins
+    Routes []IssuanceRoute `json:"routes,omitempty"`
+
+    // DefaultAuthority issues the certificates no route matches. Unset
+    // uses the instance's crypto service.
+    DefaultAuthority string `json:"defaultAuthority,omitempty"`
+}
+
+// Certificate authority types
+const (
+    CertificateAuthorityInternal = "internal"
+    CertificateAuthorityVault    = "vault"
+    CertificateAuthorityEST      = "est"
+    CertificateAuthorityHybrid   = "hybrid"
+)
+
+// IssuedByAnnotation on an injected Secret names the authority that
+// issued its certificate
+const IssuedByAnnotation = "qraiop.io/issued-by"
+
+// CertificateAuthority is a CA certificates can be issued by
+type CertificateAuthority struct {
+    // +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+    // +kubebuilder:validation:MaxLength=40
+    Name string `json:"name"`
+
+    // Type is internal for a crypto service of the instance, vault for a
+    // Vault PKI secrets engine, est for a CA enrolling over EST (RFC 7030)
+    // and hybrid for certificates signed by Vault and a crypto service
+    // +kubebuilder:validation:Enum=internal;vault;est;hybrid
+    Type string `json:"type"`
+
+    Internal *InternalCA `json:"internal,omitempty"`
+    Vault    *VaultCA    `json:"vault,omitempty"`
+    EST      *ESTCA      `json:"est,omitempty"`
+    Hybrid   *HybridCA   `json:"hybrid,omitempty"`
+}
+
+// InternalCA is the PQC CA of one of the instance's crypto services
+type InternalCA struct {
+    // Pool names the crypto pool whose CA issues; unset uses the default
+    // crypto service
+    Pool string `json:"pool,omitempty"`
+}
+
+// VaultCA issues from a role of a Vault PKI secrets engine
+type VaultCA struct {
+    // URL is the base URL of Vault, e.g. https://vault.vault:8200
+    URL string `json:"url"`
+    // Namespace is the Vault Enterprise namespace, if any
+    Namespace string `json:"namespace,omitempty"`
+    // Mount is the path the PKI engine is mounted at
+    // +kubebuilder:default=pki
+    Mount string `json:"mount,omitempty"`
+    // Role is the PKI role certificates are issued from
+    Role string `json:"role"`
+    // TokenSecret holds a Vault token allowed to issue from the role
+    TokenSecret corev1.SecretKeySelector `json:"tokenSecret"`
+}
+
+// HybridCA issues hybrid certificates for organizations whose root of
+// trust must stay in Vault. A crypto service issues the PQC certificate;
+// the classical one is signed in Vault and carries the PQC public key in
+// its subjectAltPublicKeyInfo extension (X.509 9.8), so Vault's signature
+// vouches for both. The PQC half is stored next to the classical one in
+// the injected Secret, as pqc.crt, pqc.key and pqc-ca.crt.
+type HybridCA struct {
+    // Pool names the crypto pool issuing the PQC half; unset uses the
+    // default crypto service
+    Pool string `json:"pool,omitempty"`
+    // Vault signs the classical half
+    Vault VaultSigner `json:"vault"`
+}
+
+// VaultSigner signs certificates in Vault, either through a PKI role or
+// with a Transit key backing a CA certificate
+// +kubebuilder:validation:XValidation:rule="has(self.pki) != has(self.transit)",message="set exactly one of pki and transit"
+type VaultSigner struct {
+    // URL is the base URL of Vault, e.g. https://vault.vault:8200
+    URL string `json:"url"`
+    // Namespace is the Vault Enterprise namespace, if any
+    Namespace string `json:"namespace,omitempty"`
+    // TokenSecret holds a Vault token allowed to sign
+    TokenSecret corev1.SecretKeySelector `json:"tokenSecret"`
+
+    PKI     *VaultPKISigner     `json:"pki,omitempty"`
+    Transit *VaultTransitSigner `json:"transit,omitempty"`
+}
+
+// VaultPKISigner signs with a PKI role. Requests go to sign-verbatim,
+// which keeps the extension carrying the PQC key.
+type VaultPKISigner struct {
+    // +kubebuilder:default=pki
+    Mount string `json:"mount,omitempty"`
+    Role  string `json:"role"`
+}
+
+// VaultTransitSigner signs with a Transit key; the operator assembles the
+// certificate and only its digest is sent to Vault. The key never leaves
+// Vault.
+type VaultTransitSigner struct {
+    // +kubebuilder:default=transit
+    Mount string `json:"mount,omitempty"`
+    Key   string `json:"key"`
+    // CACertificate holds the PEM certificate of the CA whose private key
+    // is the Transit key
+    CACertificate corev1.ConfigMapKeySelector `json:"caCertificate"`
+}
+
+// ESTCA is a CA, typically a corporate one, enrolling over EST. The key
+// pair is generated by the operator and only the request leaves the
+// cluster.
+type ESTCA struct {
+    // URL is the base URL of the EST server; /.well-known/est is appended
+    URL string `json:"url"`
+    // Label selects one of the server's CAs
+    Label string `json:"label,omitempty"`
+    // CredentialsSecret holds the username and password keys of the
+    // enrollment account
+    CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+    // TrustedCA is a PEM bundle the EST server's certificate is verified
+    // against instead of the system roots
+    TrustedCA *corev1.ConfigMapKeySelector `json:"trustedCA,omitempty"`
+}
+
+// IssuanceRoute sends the certificates it matches to an authority. A
+// route matches when every one of its set matchers does.
+type IssuanceRoute struct {
+    Authority string `json:"authority"`
+    // Namespaces the certificate's workload runs in
+    Namespaces []string `json:"namespaces,omitempty"`
+    // SANPatterns match a certificate when one of its DNS names or
+    // addresses matches one of them, e.g. *.corp.example.com
+    SANPatterns []string `json:"sanPatterns,omitempty"`
+}
+
+// CertificateAuthorityStatus is the state of an authority and of the
+// certificates it issued
+type CertificateAuthorityStatus struct {
+    Name  string `json:"name"`
+    Type  string `json:"type"`
+    Ready bool   `json:"ready"`
+    // Message says why the authority is not ready
+    Message string `json:"message,omitempty"`
+    // Certificates are the injected Secrets holding a certificate it issued
+    Certificates []string `json:"certificates,omitempty"`
+    // LastIssued is when the newest of them was issued
+    LastIssued *metav1.Time `json:"lastIssued,omitempty"`
+}
+
+// CertReissueConfig paces the re-issue of injected certificates that no
+// longer match the spec. Renewals of expiring certificates are not held.
+type CertReissueConfig struct {
+    // BatchSize is how many certificates are re-issued at once; the next
+    // batch starts once all of them are
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=5
+    BatchSize int32 `json:"batchSize,omitempty"`
+    // Window limits batches to a recurring maintenance window; without it
+    // they start as soon as the previous one is done
+    Window *RotationWindow `json:"window,omitempty"`
+}
+
+// CertReissueStatus is the progress of re-issuing the injected
+// certificates that no longer match the spec
+type CertReissueStatus struct {
+    // Pending are the Secrets of certificates waiting for a batch
+    Pending []string `json:"pending,omitempty"`
+    // InProgress are the Secrets of the current batch
+    InProgress []string `json:"inProgress,omitempty"`
+    // Reissued counts the certificates re-issued since all last matched
+    Reissued int32 `json:"reissued,omitempty"`
+    // LastBatch is when the current or last batch started
+    LastBatch *metav1.Time `json:"lastBatch,omitempty"`
+}
+
+// CertExpiryForecastConfig sets how far ahead certificate expiry is
+// forecast
+type CertExpiryForecastConfig struct {
+    // Days ahead to look for expiring certificates
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=30
+    Days int32 `json:"days,omitempty"`
+}
+
+// CertExpiryStatus lists the certificates expiring within the forecast
+type CertExpiryStatus struct {
+    Days       int32       `json:"days"`
+    ForecastAt metav1.Time `json:"forecastAt"`
+    // Uncovered counts the certificates nothing renews before they expire
+    Uncovered    int32                 `json:"uncovered,omitempty"`
+    Certificates []ExpiringCertificate `json:"certificates,omitempty"`
+}
+
+// ExpiringCertificate is a TLS Secret whose certificate expires within
+// the forecast
+type ExpiringCertificate struct {
+    Secret   string      `json:"secret"`
+    Subject  string      `json:"subject,omitempty"`
+    NotAfter metav1.Time `json:"notAfter"`
+    // Consumers are the workloads mounting the Secret or reading it into
+    // their environment, as Kind/name
+    Consumers []string `json:"consumers,omitempty"`
+    // Covered is set when the operator or cert-manager renews the
+    // certificate before it expires
+    Covered bool `json:"covered"`
+    // RenewsAt is when the operator renews an injected certificate
+    RenewsAt *metav1.Time `json:"renewsAt,omitempty"`
+    // Reason explains the coverage
+    Reason string `json:"reason"`
+}
+
+// EnrollmentTokenLabel marks a Secret as a bootstrap token of the
+// enrollment API, with the name of the instance it enrolls with. The token
+// is the Secret's token key.
+const EnrollmentTokenLabel = "qraiop.io/enrollment-token"
+
+// Annotations restricting an enrollment token
+const (
+    // EnrollmentSANsAnnotation narrows the SAN patterns of the token,
+    // comma separated
+    EnrollmentSANsAnnotation = "qraiop.io/enrollment-sans"
+    // EnrollmentUsesAnnotation is how many more certificates the token
+    // enrolls; without it the token is unlimited
+    EnrollmentUsesAnnotation = "qraiop.io/enrollment-uses"
+    // EnrollmentExpiresAnnotation is when the token stops working, as
+    // RFC 3339
+    EnrollmentExpiresAnnotation = "qraiop.io/enrollment-expires"
+)
+
+// EnrollmentConfig opens the operator's enrollment API to clients outside
+// the cluster, such as VMs and edge devices. A client presents a bootstrap
+// token and gets a certificate with a key pair generated by the issuing
+// authority, so crypto service authorities hand out PQC keys.
+type EnrollmentConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // Authority issues enrolled certificates. Unset routes them through
+    // the cert management routes without a namespace.
+    Authority string `json:"authority,omitempty"`
+
+    // SANPatterns every requested DNS name and address must match, e.g.
+    // *.edge.example.com
+    // +kubebuilder:validation:MinItems=1
+    SANPatterns []string `json:"sanPatterns"`
+
+    // MaxLifetime caps the lifetime clients may ask for
+    // +kubebuilder:default="720h"
+    MaxLifetime metav1.Duration `json:"maxLifetime,omitempty"`
+
+    // RequestsPerMinute limits enrollments per token and per client address,
+    // on each operator replica
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=6
+    RequestsPerMinute int32 `json:"requestsPerMinute,omitempty"`
+}
@@ -0,0 +1,538 @@
+// src/controllers/api/v1/chaosexperiment_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// ExperimentTarget selects the pods an experiment acts on
+type ExperimentTarget struct {
+    Namespace string            `json:"namespace"`
+    Selector  map[string]string `json:"selector"`
+}
+
+// StorageChaosAnnotation opts a StorageClass into storage faults. Volumes of
+// any other class are never touched.
+const StorageChaosAnnotation = "qraiop.io/allow-storage-chaos"
+
+// StorageFault tunes the volume_detach, volume_fill and io_latency faults
+type StorageFault struct {
+    // Volumes are the names of the target pods' volumes to act on; empty
+    // means every volume backed by a PersistentVolumeClaim
+    Volumes []string `json:"volumes,omitempty"`
+
+    // FillPercent is how full volume_fill leaves each volume. It is capped
+    // below 100 so the application never hits ENOSPC on its own data.
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=95
+    // +kubebuilder:default=90
+    FillPercent int `json:"fillPercent,omitempty"`
+
+    // Latency io_latency adds to every IO on the volumes
+    // +kubebuilder:default="100ms"
+    Latency metav1.Duration `json:"latency,omitempty"`
+}
+
+// HTTPFault tunes the http_delay and http_abort faults. They are injected
+// by the service mesh into calls to the Services in front of the targets,
+// so no privileged containers are involved.
+type HTTPFault struct {
+    // Delay http_delay adds to each affected request
+    // +kubebuilder:default="500ms"
+    Delay metav1.Duration `json:"delay,omitempty"`
+
+    // AbortStatus http_abort answers affected requests with
+    // +kubebuilder:validation:Minimum=400
+    // +kubebuilder:validation:Maximum=599
+    // +kubebuilder:default=503
+    AbortStatus int `json:"abortStatus,omitempty"`
+
+    // RequestPercentage of requests that are affected
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=100
+    RequestPercentage int `json:"requestPercentage,omitempty"`
+}
+
+// NodePressureFault tunes the node_memory_pressure and node_cpu_pressure
+// faults. They load the whole node the targets run on, through its chaos
+// agent, and are stopped early once the kubelet reports pressure.
+type NodePressureFault struct {
+    // MemoryPercent of the node's allocatable memory node_memory_pressure
+    // takes up
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=95
+    // +kubebuilder:default=80
+    MemoryPercent int `json:"memoryPercent,omitempty"`
+
+    // CPUPercent of the node's allocatable CPU node_cpu_pressure keeps busy
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=80
+    CPUPercent int `json:"cpuPercent,omitempty"`
+
+    // SafetyMarginPercent of the node's memory the agent keeps free above
+    // the kubelet's reserved memory and eviction threshold. The agent
+    // releases the load by itself when available memory drops into it.
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=50
+    // +kubebuilder:default=5
+    SafetyMarginPercent int `json:"safetyMarginPercent,omitempty"`
+}
+
+// MaxBlackoutDuration caps monitoring_blackout experiments, during which
+// the cluster runs without working monitoring
+const MaxBlackoutDuration = 5 * 60
+
+// MonitoringBlackoutFault tunes the monitoring_blackout fault. It cuts the
+// target pods of the monitoring pipeline, such as Prometheus or
+// Alertmanager, off the network and expects the blackout to be detected:
+// the experiment passes once a detection alert fires, and the fault is
+// reverted right then. Alerts are never suppressed during it.
+type MonitoringBlackoutFault struct {
+    // DetectionAlerts are Alertmanager matchers, such as
+    // alertname="PrometheusMissingData", selecting the alerts that detect
+    // the blackout. None may fire before the fault is injected.
+    // +kubebuilder:validation:MinItems=1
+    DetectionAlerts []string `json:"detectionAlerts"`
+
+    // AlertmanagerURL is where the detection alerts are looked for, such
+    // as a meta-monitoring Alertmanager. It is required when the targets
+    // include or feed the Alertmanager of the Qraiop instance, which is
+    // used otherwise.
+    AlertmanagerURL string `json:"alertmanagerURL,omitempty"`
+}
+
+// Chaos execution backends
+const (
+    BackendNative    = "native"
+    BackendChaosMesh = "chaos-mesh"
+    BackendLitmus    = "litmus"
+)
+
+// ExperimentConfig describes the fault an experiment injects
+type ExperimentConfig struct {
+    // +kubebuilder:validation:Enum=pod_kill;network_partition;volume_detach;volume_fill;io_latency;http_delay;http_abort;node_memory_pressure;node_cpu_pressure;monitoring_blackout
+    Type   string           `json:"type"`
+    Target ExperimentTarget `json:"target"`
+
+    // Backend injects the fault. Native faults are injected by the
+    // operator itself; chaos-mesh and litmus translate the experiment into
+    // the custom resources of those tools, which must be installed, and
+    // map their results back into the verdict. They run pod_kill and
+    // network_partition on the drawn targets.
+    // +kubebuilder:validation:Enum=native;chaos-mesh;litmus
+    // +kubebuilder:default=native
+    Backend string `json:"backend,omitempty"`
+
+    // Litmus tunes the litmus backend
+    Litmus *LitmusBackend `json:"litmus,omitempty"`
+
+    // Storage tunes storage faults. They only act on volumes whose
+    // StorageClass carries the qraiop.io/allow-storage-chaos annotation.
+    Storage *StorageFault `json:"storage,omitempty"`
+
+    // HTTP tunes the L7 faults, which need the targets in an Istio mesh
+    HTTP *HTTPFault `json:"http,omitempty"`
+
+    // Node tunes the node pressure faults
+    Node *NodePressureFault `json:"node,omitempty"`
+
+    // Blackout tunes the monitoring_blackout fault, whose Duration may not
+    // exceed 300 seconds
+    Blackout *MonitoringBlackoutFault `json:"blackout,omitempty"`
+
+    // Percentage of the matching running pods that are targeted
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=100
+    Percentage int `json:"percentage,omitempty"`
+
+    // Seed makes the draw of targets reproducible: the same seed and the
+    // same matching pods select the same targets. Unset, a seed is drawn
+    // and recorded in status.resolution.seed, so a run can be repeated.
+    Seed *int64 `json:"seed,omitempty"`
+
+    // Duration in seconds the fault is held before it is reverted
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=60
+    Duration int `json:"duration,omitempty"`
+
+    // PolicyExceptions let the helper pods of the experiment, volume_fill
+    // fillers and SQL probe Jobs, past the cluster's admission policies.
+    // They are created when the experiment starts and removed when it
+    // ends. A ChaosPermission of the target namespace must allow them.
+    PolicyExceptions *PolicyExceptions `json:"policyExceptions,omitempty"`
+}
+
+// LitmusBackend tunes the ChaosEngines run for the litmus backend
+type LitmusBackend struct {
+    // ServiceAccount the Litmus experiment pods run as
+    // +kubebuilder:default=litmus-admin
+    ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
+// Policy engines chaos experiments can create exceptions for
+const (
+    PolicyEngineKyverno    = "kyverno"
+    PolicyEngineGatekeeper = "gatekeeper"
+)
+
+// PolicyExceptions are the admission policies an experiment's helper pods
+// are exempted from
+type PolicyExceptions struct {
+    // Engine is the policy engine of the cluster. Kyverno gets a
+    // PolicyException matching only the helper pods; Gatekeeper can only
+    // exclude the experiment's and the target's namespaces as a whole.
+    // +kubebuilder:validation:Enum=kyverno;gatekeeper
+    Engine string `json:"engine"`
+    // Policies are the Kyverno policies and rules to except, required for kyverno
+    Policies []PolicyRuleRef `json:"policies,omitempty"`
+}
+
+// PolicyRuleRef names rules of a Kyverno policy
+type PolicyRuleRef struct {
+    Policy string `json:"policy"`
+    // +kubebuilder:validation:MinItems=1
+    Rules []string `json:"rules"`
+}
+
+// ChaosExperimentSpec defines the desired state of ChaosExperiment
+// +kubebuilder:validation:XValidation:rule="!has(self.backend) || self.backend == 'native' || self.type in ['pod_kill', 'network_partition']",message="chaos-mesh and litmus backends only run pod_kill and network_partition"
+// +kubebuilder:validation:XValidation:rule="!has(self.backend) || self.backend == 'native' || !has(self.ramp)",message="intensity ramps need the native backend"
+type ChaosExperimentSpec struct {
+    ExperimentConfig `json:",inline"`
+
+    // Abort stops a pending or running experiment: injected faults are
+    // reverted and the verdict is set to Aborted
+    Abort bool `json:"abort,omitempty"`
+
+    // DisableAlertSuppression keeps alerts of the targets firing. By default
+    // they are silenced in Alertmanager for the duration of the experiment.
+    DisableAlertSuppression bool `json:"disableAlertSuppression,omitempty"`
+
+    // IgnorePDB deletes pod_kill targets outright even when that violates
+    // their PodDisruptionBudgets. By default such experiments fail their
+    // pre-flight check and targets are removed through the Eviction API.
+    // A ChaosPermission of the target namespace must allow it.
+    IgnorePDB bool `json:"ignorePDB,omitempty"`
+
+    // Probes check that systems outside the targets, such as the database
+    // or queue they depend on, stay healthy. They must pass before the
+    // fault is injected; once one fails while the fault is held, the fault
+    // is reverted and the experiment fails.
+    Probes []SteadyStateProbe `json:"probes,omitempty"`
+
+    // ProbeInterval is how often probes run while the fault is held
+    // +kubebuilder:default="10s"
+    ProbeInterval metav1.Duration `json:"probeInterval,omitempty"`
+
+    // Ramp raises the share of targeted pods step by step up to
+    // Percentage, instead of injecting into all of them at once
+    Ramp *IntensityRamp `json:"ramp,omitempty"`
+}
+
+// IntensityRamp schedules the intensity of an experiment. Each step
+// reverts the fault and injects it again into a freshly drawn share of the
+// matching pods. While an SLO warning fires, the ramp steps back down
+// instead.
+type IntensityRamp struct {
+    // StartPercentage of the matching pods targeted first
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=5
+    StartPercentage int `json:"startPercentage,omitempty"`
+
+    // StepPercentage is added every Interval, up to Percentage
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=5
+    StepPercentage int `json:"stepPercentage,omitempty"`
+
+    // +kubebuilder:default="5m"
+    Interval metav1.Duration `json:"interval,omitempty"`
+
+    // BackoffAlerts are Alertmanager matchers, such as alertname=~".*SLO.*",
+    // selecting the alerts of the target namespace that step the ramp back
+    // down. Empty means every active severity="warning" alert.
+    BackoffAlerts []string `json:"backoffAlerts,omitempty"`
+}
+
+// SteadyStateProbe checks one external dependency. Exactly one of HTTP, TCP
+// and SQL is set.
+type SteadyStateProbe struct {
+    // +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+    // +kubebuilder:validation:MaxLength=30
+    Name string `json:"name"`
+
+    HTTP *HTTPProbe `json:"http,omitempty"`
+    TCP  *TCPProbe  `json:"tcp,omitempty"`
+    SQL  *SQLProbe  `json:"sql,omitempty"`
+
+    // FailureThreshold is how many consecutive failures fail the experiment
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=1
+    FailureThreshold int `json:"failureThreshold,omitempty"`
+
+    // Timeout of a single check
+    // +kubebuilder:default="5s"
+    Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// HTTPProbe expects a status code from a GET request
+type HTTPProbe struct {
+    URL string `json:"url"`
+    // +kubebuilder:default=200
+    ExpectedStatus int `json:"expectedStatus,omitempty"`
+}
+
+// TCPProbe expects a TCP connection to be accepted
+type TCPProbe struct {
+    // Address as host:port
+    Address string `json:"address"`
+}
+
+// SQLProbe pings a database from a Job in the experiment's namespace, so
+// the operator never holds database credentials
+type SQLProbe struct {
+    // +kubebuilder:validation:Enum=postgres;mysql
+    Driver string `json:"driver"`
+    // DSNSecret holds the connection string, e.g. postgres://user:pass@db:5432/app
+    DSNSecret corev1.SecretKeySelector `json:"dsnSecret"`
+    // Image overrides the client image of the driver
+    Image string `json:"image,omitempty"`
+}
+
+// ChaosExperiment phases
+const (
+    ExperimentPending   = "Pending"
+    ExperimentRunning   = "Running"
+    ExperimentCompleted = "Completed"
+    ExperimentFailed    = "Failed"
+    ExperimentAborted   = "Aborted"
+)
+
+// ChaosExperiment verdicts
+const (
+    VerdictPassed  = "Passed"
+    VerdictFailed  = "Failed"
+    VerdictAborted = "Aborted"
+)
+
+// ChaosExperimentStatus defines the observed state of ChaosExperiment
+type ChaosExperimentStatus struct {
+    Phase          string       `json:"phase,omitempty"`
+    Verdict        string       `json:"verdict,omitempty"`
+    Message        string       `json:"message,omitempty"`
+    StartTime      *metav1.Time `json:"startTime,omitempty"`
+    CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+    // Resolution lists the concrete pods and nodes the target resolves to.
+    // It is filled in as soon as the experiment is created, while it is
+    // Pending, so the blast radius can be reviewed before injection.
+    Resolution *TargetResolution `json:"resolution,omitempty"`
+
+    // Targets are the pods the fault was injected into
+    Targets []string `json:"targets,omitempty"`
+    // Nodes are the nodes a node pressure fault was injected into
+    Nodes []string `json:"nodes,omitempty"`
+    // BaselineReady is the number of ready target pods before injection,
+    // used as the steady-state hypothesis
+    BaselineReady int `json:"baselineReady,omitempty"`
+
+    // Preflight is the check of the targets' disruption budgets made before
+    // the fault is injected
+    Preflight *PreflightReport `json:"preflight,omitempty"`
+
+    // Probes are the latest results of the steady-state probes
+    Probes []ProbeStatus `json:"probes,omitempty"`
+
+    // AlertSuppression records the alerts silenced during the experiment
+    AlertSuppression *AlertSuppressionStatus `json:"alertSuppression,omitempty"`
+
+    // PolicyExceptions records the policy exceptions granted to the
+    // experiment's helper pods
+    PolicyExceptions *PolicyExceptionStatus `json:"policyExceptions,omitempty"`
+
+    // Ramp records the steps of the intensity ramp
+    Ramp *RampStatus `json:"ramp,omitempty"`
+
+    // Blackout records how a monitoring blackout was detected
+    Blackout *BlackoutStatus `json:"blackout,omitempty"`
+
+    // Backend is what the external tool running the fault reports
+    Backend *BackendStatus `json:"backend,omitempty"`
+
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// BackendStatus is the state of a fault run by an external chaos tool
+type BackendStatus struct {
+    // Kind and Name of the custom resource running the fault
+    Kind string `json:"kind"`
+    Name string `json:"name"`
+    // Finished is set once the tool has reverted the fault
+    Finished bool `json:"finished,omitempty"`
+    // Verdict the tool reached, Passed or Failed; empty while undecided
+    Verdict string `json:"verdict,omitempty"`
+    Message string `json:"message,omitempty"`
+}
+
+// TargetResolution is what an experiment's target selects at a point in time
+type TargetResolution struct {
+    ResolvedAt metav1.Time `json:"resolvedAt"`
+    // Matched is the number of running pods matching the selector, of
+    // which the experiment's percentage is targeted
+    Matched int `json:"matched"`
+    // Seed the targets were drawn with
+    Seed int64 `json:"seed"`
+    // Pods are the targeted pods in the order the seed drew them from the
+    // matching pods sorted by name
+    Pods []ResolvedPod `json:"pods,omitempty"`
+    // Nodes the targeted pods run on
+    Nodes []string `json:"nodes,omitempty"`
+}
+
+// ResolvedPod is one pod an experiment targets
+type ResolvedPod struct {
+    Name  string `json:"name"`
+    Node  string `json:"node,omitempty"`
+    Ready bool   `json:"ready"`
+}
+
+// Reasons of ramp steps
+const (
+    RampStart    = "Start"
+    RampIncrease = "Increase"
+    RampBackoff  = "Backoff"
+)
+
+// RampStatus is where an intensity ramp stands
+type RampStatus struct {
+    // Percentage of the matching pods currently targeted
+    Percentage int          `json:"percentage"`
+    NextStepAt *metav1.Time `json:"nextStepAt,omitempty"`
+    // Steps taken, oldest first
+    Steps []RampStep `json:"steps,omitempty"`
+}
+
+// RampStep is one change of intensity
+type RampStep struct {
+    At         metav1.Time `json:"at"`
+    Percentage int         `json:"percentage"`
+    // Targets is the number of pods the fault was injected into
+    Targets int `json:"targets"`
+    // Reason is Start, Increase or Backoff
+    Reason  string `json:"reason"`
+    Message string `json:"message,omitempty"`
+}
+
+// BlackoutStatus is the detection of a monitoring blackout
+type BlackoutStatus struct {
+    // DetectedAt is when a detection alert was first seen firing
+    DetectedAt *metav1.Time `json:"detectedAt,omitempty"`
+    // Latency from injection to detection
+    Latency *metav1.Duration `json:"latency,omitempty"`
+    // Alerts are the names of the detection alerts that fired
+    Alerts []string `json:"alerts,omitempty"`
+}
+
+// AlertSuppressionStatus is the Alertmanager silence covering an experiment's targets
+type AlertSuppressionStatus struct {
+    SilenceID string `json:"silenceID"`
+    // Matchers of the silence, as label=value or label=~regex
+    Matchers []string     `json:"matchers"`
+    StartsAt metav1.Time  `json:"startsAt"`
+    EndsAt   metav1.Time  `json:"endsAt"`
+    LiftedAt *metav1.Time `json:"liftedAt,omitempty"`
+}
+
+// PolicyExceptionStatus is what an experiment was exempted from
+type PolicyExceptionStatus struct {
+    Engine string `json:"engine"`
+    // Exception is the Kyverno PolicyException as namespace/name
+    Exception string `json:"exception,omitempty"`
+    // Namespaces excluded from Gatekeeper
+    Namespaces []string    `json:"namespaces,omitempty"`
+    GrantedAt  metav1.Time `json:"grantedAt"`
+    // ExpiresAt is when the exception lapses even if it is never revoked
+    ExpiresAt metav1.Time  `json:"expiresAt"`
+    RevokedAt *metav1.Time `json:"revokedAt,omitempty"`
+}
+
+// PreflightReport lists the PodDisruptionBudgets covering an experiment's targets
+type PreflightReport struct {
+    Budgets []BudgetCheck `json:"budgets,omitempty"`
+    // Violations is the number of budgets the experiment would break
+    Violations int `json:"violations"`
+}
+
+// BudgetCheck compares the disruptions a budget allows with the targets it covers
+type BudgetCheck struct {
+    Name               string `json:"name"`
+    DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+    Targeted           int32  `json:"targeted"`
+    Violated           bool   `json:"violated"`
+}
+
+// ProbeStatus is the latest result of a steady-state probe
+type ProbeStatus struct {
+    Name    string `json:"name"`
+    Healthy bool   `json:"healthy"`
+    // ConsecutiveFailures counts failed checks since the last success
+    ConsecutiveFailures int          `json:"consecutiveFailures,omitempty"`
+    Message             string       `json:"message,omitempty"`
+    LastChecked         *metav1.Time `json:"lastChecked,omitempty"`
+}
+
+// IsFinished reports whether the experiment reached a terminal phase
+func (s *ChaosExperimentStatus) IsFinished() bool {
+    switch s.Phase {
+    case ExperimentCompleted, ExperimentFailed, ExperimentAborted:
+        return true
+    }
+    return false
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Verdict",type=string,JSONPath=`.status.verdict`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ChaosExperiment struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   ChaosExperimentSpec   `json:"spec,omitempty"`
+    Status ChaosExperimentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type ChaosExperimentList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []ChaosExperiment `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for ChaosExperiment
+func (in *ChaosExperiment) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for ChaosExperimentList
+func (in *ChaosExperimentList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&ChaosExperiment{}, &ChaosExperimentList{})
+}
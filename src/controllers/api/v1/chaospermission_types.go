@@ -0,0 +1,77 @@
+// src/controllers/api/v1/chaospermission_types.go
+package v1
+
+import (
+    rbacv1 "k8s.io/api/rbac/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// ChaosPermissionSpec lets subjects run chaos experiments against pods in
+// the ChaosPermission's namespace. Experiments targeting a namespace are
+// refused for anyone no permission there covers, so a namespace without
+// any is off limits to all but the webhook's exempt users.
+type ChaosPermissionSpec struct {
+    // Subjects are the users, groups and ServiceAccounts granted permission
+    // +kubebuilder:validation:MinItems=1
+    Subjects []rbacv1.Subject `json:"subjects"`
+
+    // ExperimentTypes the subjects may run; empty allows every type
+    // +kubebuilder:validation:items:Enum=pod_kill;network_partition;volume_detach;volume_fill;io_latency;http_delay;http_abort;node_memory_pressure;node_cpu_pressure;monitoring_blackout
+    ExperimentTypes []string `json:"experimentTypes,omitempty"`
+
+    // Selector limits targets to pods with these labels: an experiment's
+    // selector must include every one of them
+    Selector map[string]string `json:"selector,omitempty"`
+
+    // MaxPercentage caps the share of matching pods an experiment may target
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    MaxPercentage int `json:"maxPercentage,omitempty"`
+
+    // AllowPolicyExceptions lets the subjects run experiments that exempt
+    // their helper pods from admission policies
+    AllowPolicyExceptions bool `json:"allowPolicyExceptions,omitempty"`
+
+    // AllowIgnorePDB lets the subjects run experiments that delete targets
+    // in violation of their PodDisruptionBudgets
+    AllowIgnorePDB bool `json:"allowIgnorePDB,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Types",type=string,JSONPath=`.spec.experimentTypes`
+// +kubebuilder:printcolumn:name="Max %",type=integer,JSONPath=`.spec.maxPercentage`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ChaosPermission struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec ChaosPermissionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type ChaosPermissionList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []ChaosPermission `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for ChaosPermission
+func (in *ChaosPermission) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for ChaosPermissionList
+func (in *ChaosPermissionList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&ChaosPermission{}, &ChaosPermissionList{})
+}
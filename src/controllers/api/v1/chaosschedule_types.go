@@ -0,0 +1,120 @@
+// src/controllers/api/v1/chaosschedule_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// BusinessHours limit a schedule to working hours, so a failing experiment
+// is noticed by someone on shift
+type BusinessHours struct {
+    // Days are the weekdays experiments may start on
+    // +kubebuilder:validation:MinItems=1
+    // +kubebuilder:validation:items:Enum=mon;tue;wed;thu;fri;sat;sun
+    Days []string `json:"days"`
+    // Start and End are the local times, as 15:04, experiments may start
+    // between
+    // +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+    Start string `json:"start"`
+    // +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+    End string `json:"end"`
+}
+
+// ExclusionWindow is a period no scheduled experiment starts in, e.g. a
+// change freeze
+type ExclusionWindow struct {
+    Start metav1.Time `json:"start"`
+    End   metav1.Time `json:"end"`
+    // Reason is shown when a run is skipped for the window
+    Reason string `json:"reason,omitempty"`
+}
+
+// ChaosScheduleSpec defines the desired state of ChaosSchedule
+type ChaosScheduleSpec struct {
+    // Schedule is a five-field cron expression, e.g. "0 10 * * mon-fri",
+    // or one of @hourly, @daily, @weekly, @monthly and @yearly
+    Schedule string `json:"schedule"`
+
+    // TimeZone is the IANA time zone the schedule and business hours are
+    // read in. Defaults to UTC.
+    TimeZone string `json:"timeZone,omitempty"`
+
+    // Experiment is run on every firing. Its target must be in the
+    // schedule's namespace.
+    Experiment ExperimentConfig `json:"experiment"`
+
+    // ConcurrencyLimit is how many experiments started by schedules may run
+    // at once in the namespace. A firing beyond it is skipped, and
+    // schedules that would exceed it are refused at admission.
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=1
+    ConcurrencyLimit int `json:"concurrencyLimit,omitempty"`
+
+    // BusinessHours, when set, skip firings outside them
+    BusinessHours *BusinessHours `json:"businessHours,omitempty"`
+
+    // ExclusionWindows skip the firings that fall into them
+    ExclusionWindows []ExclusionWindow `json:"exclusionWindows,omitempty"`
+
+    // Suspend stops new firings
+    Suspend bool `json:"suspend,omitempty"`
+}
+
+// ChaosScheduleStatus defines the observed state of ChaosSchedule
+type ChaosScheduleStatus struct {
+    // LastScheduleTime is the last firing, whether it ran or was skipped
+    LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+    // LastExperiment is the ChaosExperiment of the last firing that ran
+    LastExperiment string `json:"lastExperiment,omitempty"`
+    // NextScheduleTime is the next firing
+    NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+    // LastSkipped says why the last firing didn't run, if it didn't
+    LastSkipped string `json:"lastSkipped,omitempty"`
+
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=csched
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.experiment.type`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Next",type=string,JSONPath=`.status.nextScheduleTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ChaosSchedule struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   ChaosScheduleSpec   `json:"spec,omitempty"`
+    Status ChaosScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type ChaosScheduleList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []ChaosSchedule `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for ChaosSchedule
+func (in *ChaosSchedule) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for ChaosScheduleList
+func (in *ChaosScheduleList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&ChaosSchedule{}, &ChaosScheduleList{})
+}
@@ -0,0 +1,87 @@
+// src/controllers/api/v1/chaostrigger_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// TriggeredExperiment is the smoke experiment a ChaosTrigger runs. It
+// targets the pods of the watched Deployment.
+type TriggeredExperiment struct {
+    // +kubebuilder:validation:Enum=pod_kill;network_partition
+    Type string `json:"type"`
+
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=100
+    // +kubebuilder:default=100
+    Percentage int `json:"percentage,omitempty"`
+
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=60
+    Duration int `json:"duration,omitempty"`
+}
+
+// ChaosTriggerSpec defines the desired state of ChaosTrigger
+type ChaosTriggerSpec struct {
+    // Deployment, in the trigger's namespace, whose completed rollouts
+    // start an experiment
+    Deployment string              `json:"deployment"`
+    Experiment TriggeredExperiment `json:"experiment"`
+
+    // Suspend stops new rollouts from starting experiments
+    Suspend bool `json:"suspend,omitempty"`
+}
+
+// ChaosTriggerStatus defines the observed state of ChaosTrigger
+type ChaosTriggerStatus struct {
+    // LastRevision is the Deployment revision last verified
+    LastRevision string `json:"lastRevision,omitempty"`
+    // LastExperiment is the ChaosExperiment started for LastRevision
+    LastExperiment string `json:"lastExperiment,omitempty"`
+    // LastVerdict is the verdict of LastExperiment, empty while it runs
+    LastVerdict string `json:"lastVerdict,omitempty"`
+
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Deployment",type=string,JSONPath=`.spec.deployment`
+// +kubebuilder:printcolumn:name="Revision",type=string,JSONPath=`.status.lastRevision`
+// +kubebuilder:printcolumn:name="Verdict",type=string,JSONPath=`.status.lastVerdict`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ChaosTrigger struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   ChaosTriggerSpec   `json:"spec,omitempty"`
+    Status ChaosTriggerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type ChaosTriggerList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []ChaosTrigger `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for ChaosTrigger
+func (in *ChaosTrigger) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for ChaosTriggerList
+func (in *ChaosTriggerList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&ChaosTrigger{}, &ChaosTriggerList{})
+}
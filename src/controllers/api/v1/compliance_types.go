@@ -0,0 +1,92 @@
+// src/controllers/api/v1/compliance_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Compliance report formats
+const (
+    ComplianceFormatJSON = "json"
+    ComplianceFormatHTML = "html"
+    ComplianceFormatPDF  = "pdf"
+)
+
+// ComplianceReportConfig schedules a cluster-wide crypto compliance scan.
+// Each run writes the report in its formats, each with a detached
+// signature, to object storage or a PersistentVolumeClaim for auditors.
+// The scan runs as the qraiop-compliance ServiceAccount, which needs the
+// qraiop-compliance-reader ClusterRole; configs/k8/namespace.yml binds it
+// for instances in qraiop-system.
+// +kubebuilder:validation:XValidation:rule="!has(self.formats) || !self.formats.exists(f, f == 'pdf') || has(self.renderer)",message="pdf reports need a renderer"
+type ComplianceReportConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Schedule of the scan in cron format
+    // +kubebuilder:default="0 3 * * 1"
+    Schedule string `json:"schedule,omitempty"`
+    // Suspend stops scheduling scans, keeping past reports
+    Suspend bool `json:"suspend,omitempty"`
+    // Formats to export. PDF is rendered from the HTML report, which is
+    // then exported too.
+    // +kubebuilder:validation:items:Enum=json;html;pdf
+    // +kubebuilder:default={json}
+    Formats []string `json:"formats,omitempty"`
+    // SigningKeySecret holds the PEM private key, Ed25519, ECDSA or RSA,
+    // each report is signed with
+    SigningKeySecret corev1.SecretKeySelector `json:"signingKeySecret"`
+    // Storage is where the reports are kept
+    Storage ComplianceStorage `json:"storage"`
+    // Renderer converts the HTML report to PDF
+    Renderer *ComplianceRenderer `json:"renderer,omitempty"`
+    // Image running qraiopctl for the scan; defaults to the QRAIOP image
+    // of the instance's component version
+    Image string `json:"image,omitempty"`
+    // SuccessfulJobsHistoryLimit is how many finished scans are kept
+    // +kubebuilder:validation:Minimum=0
+    // +kubebuilder:default=3
+    SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+}
+
+// ComplianceStorage is where compliance reports are written, each run to a
+// directory named after its start time
+// +kubebuilder:validation:XValidation:rule="has(self.persistentVolumeClaim) != has(self.s3)",message="exactly one of persistentVolumeClaim and s3 must be set"
+type ComplianceStorage struct {
+    // PersistentVolumeClaim in the instance's namespace to write to
+    PersistentVolumeClaim *corev1.LocalObjectReference `json:"persistentVolumeClaim,omitempty"`
+    // S3 uploads to an S3 compatible bucket
+    S3 *S3Storage `json:"s3,omitempty"`
+}
+
+// S3Storage is a bucket of an S3 compatible object store
+type S3Storage struct {
+    Bucket string `json:"bucket"`
+    // Prefix of the report keys
+    Prefix string `json:"prefix,omitempty"`
+    // Endpoint of stores other than AWS S3, e.g. https://minio.example.com
+    Endpoint string `json:"endpoint,omitempty"`
+    Region   string `json:"region,omitempty"`
+    // CredentialsSecret holds AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+    // Without it the pod's own identity, e.g. IRSA, is used.
+    CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+    // Image running the AWS CLI
+    // +kubebuilder:default="amazon/aws-cli:2.17.0"
+    Image string `json:"image,omitempty"`
+}
+
+// ComplianceRenderer converts the HTML report to PDF. Command is run with
+// the HTML file and the PDF to write appended.
+type ComplianceRenderer struct {
+    // +kubebuilder:default="surnet/alpine-wkhtmltopdf:3.20.2-0.12.6-small"
+    Image string `json:"image,omitempty"`
+    // +kubebuilder:default={wkhtmltopdf,--quiet}
+    Command []string `json:"command,omitempty"`
+}
+
+// ComplianceReportStatus tracks the scheduled compliance scans
+type ComplianceReportStatus struct {
+    // CronJob running the scans
+    CronJob            string       `json:"cronJob,omitempty"`
+    LastScheduleTime   *metav1.Time `json:"lastScheduleTime,omitempty"`
+    LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+}
@@ -0,0 +1,73 @@
+// src/controllers/api/v1/deprecations.go
+package v1
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Deprecation is a spec field or value that still works but will be
+// removed from a later API version
+type Deprecation struct {
+    // Path of the field, e.g. spec.cryptography.algorithms[0]
+    Path string
+    // Message says what to use instead
+    Message string
+}
+
+func (d Deprecation) String() string {
+    return d.Path + ": " + d.Message
+}
+
+// renamedAlgorithms maps the pre-standard names of the post-quantum
+// algorithms to their FIPS 203/204/205 names
+var renamedAlgorithms = map[string]string{
+    "kyber512":   "ML-KEM-512",
+    "kyber768":   "ML-KEM-768",
+    "kyber1024":  "ML-KEM-1024",
+    "dilithium2": "ML-DSA-44",
+    "dilithium3": "ML-DSA-65",
+    "dilithium5": "ML-DSA-87",
+}
+
+// Deprecations returns the deprecated fields and values the spec of q
+// uses, in spec order. New deprecations are added here, so the admission
+// webhook warns about them and the Deprecated condition reports them.
+func (q *Qraiop) Deprecations() []Deprecation {
+    var found []Deprecation
+    algorithms := func(path string, names []string) {
+        for i, name := range names {
+            if msg := deprecatedAlgorithm(name); msg != "" {
+                found = append(found, Deprecation{Path: fmt.Sprintf("%s[%d]", path, i), Message: msg})
+            }
+        }
+    }
+    algorithms("spec.cryptography.algorithms", q.Spec.Cryptography.Algorithms)
+    for i, pool := range q.Spec.Cryptography.Pools {
+        algorithms(fmt.Sprintf("spec.cryptography.pools[%d].algorithms", i), pool.Algorithms)
+    }
+    return found
+}
+
+// CanonicalAlgorithm returns the standard name of a pre-standard algorithm
+// name, or name itself
+func CanonicalAlgorithm(name string) string {
+    key := strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(name))
+    if renamed := renamedAlgorithms[strings.TrimPrefix(key, "crystals")]; renamed != "" {
+        return renamed
+    }
+    return name
+}
+
+// deprecatedAlgorithm describes the replacement of a pre-standard
+// algorithm name, or returns ""
+func deprecatedAlgorithm(name string) string {
+    key := strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(name))
+    if key = strings.TrimPrefix(key, "crystals"); renamedAlgorithms[key] != "" {
+        return fmt.Sprintf("%s is deprecated, use %s", name, renamedAlgorithms[key])
+    }
+    if strings.HasPrefix(key, "sphincs") {
+        return fmt.Sprintf("%s is deprecated, use the SLH-DSA parameter set of the same hash and size", name)
+    }
+    return ""
+}
@@ -0,0 +1,99 @@
+// src/controllers/api/v1/monitoringtarget_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// MonitoringTargetLabel is set on the objects generated for a
+// MonitoringTarget, with the target name as value
+const MonitoringTargetLabel = "qraiop.io/monitoring-target"
+
+// MonitoringTargetSpec opts the Services of a workload into the monitoring
+// of a Qraiop instance: they are scraped by the instance's Prometheus,
+// alerted on and shown on the instance's workload dashboard
+type MonitoringTargetSpec struct {
+    // Instance is the Qraiop instance monitoring the workload. Its
+    // monitoring must be enabled.
+    Instance InstanceReference `json:"instance"`
+
+    // Selector matches the Services to scrape, in the target's namespace
+    Selector metav1.LabelSelector `json:"selector"`
+
+    // Port is the name of the Service port serving metrics
+    // +kubebuilder:default="metrics"
+    Port string `json:"port,omitempty"`
+
+    // Path of the metrics endpoint
+    // +kubebuilder:default="/metrics"
+    Path string `json:"path,omitempty"`
+
+    // Interval between scrapes; defaults to the instance's
+    Interval *metav1.Duration `json:"interval,omitempty"`
+
+    // SLOs of the workload, alerted on when they burn their error budget
+    // +listType=map
+    // +listMapKey=name
+    SLOs []ServiceLevelObjective `json:"slos,omitempty"`
+}
+
+// InstanceReference names a Qraiop instance, possibly in another namespace
+type InstanceReference struct {
+    Name string `json:"name"`
+    // Namespace defaults to the referring object's
+    Namespace string `json:"namespace,omitempty"`
+}
+
+// MonitoringTargetStatus defines the observed state of MonitoringTarget
+type MonitoringTargetStatus struct {
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+    // Services are the Services the selector matches
+    Services []string `json:"services,omitempty"`
+    // SLOs are the error budgets of the SLOs, when the instance has a
+    // Prometheus to query
+    SLOs       []SLOStatus        `json:"slos,omitempty"`
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mt
+// +kubebuilder:printcolumn:name="Instance",type=string,JSONPath=`.spec.instance.name`
+// +kubebuilder:printcolumn:name="Services",type=string,JSONPath=`.status.services`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type MonitoringTarget struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   MonitoringTargetSpec   `json:"spec,omitempty"`
+    Status MonitoringTargetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type MonitoringTargetList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []MonitoringTarget `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for MonitoringTarget
+func (in *MonitoringTarget) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for MonitoringTargetList
+func (in *MonitoringTargetList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&MonitoringTarget{}, &MonitoringTargetList{})
+}
@@ -0,0 +1,191 @@
+// src/controllers/api/v1/playbook_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// Playbook actions
+const (
+    PlaybookRestart       = "restart"
+    PlaybookScale         = "scale"
+    PlaybookRotateCert    = "rotateCert"
+    PlaybookRunExperiment = "runExperiment"
+    PlaybookNotify        = "notify"
+)
+
+// Phases of playbook runs and their steps
+const (
+    PlaybookPending    = "Pending"
+    PlaybookRunning    = "Running"
+    PlaybookSucceeded  = "Succeeded"
+    PlaybookFailed     = "Failed"
+    PlaybookRolledBack = "RolledBack"
+)
+
+// PlaybookReplicasAnnotation on a Qraiop instance holds the replicas
+// playbooks scaled its components to, as deployment=replicas pairs
+// separated by commas. They take precedence over the spec and schedules
+// until the playbook's rollback or the annotation is removed.
+const PlaybookReplicasAnnotation = "qraiop.io/playbook-replicas"
+
+// RenewCertAnnotation on an injected certificate Secret has its
+// certificate re-issued now, regardless of its lifetime
+const RenewCertAnnotation = "qraiop.io/renew"
+
+// PlaybookTrigger matches a condition or component status of the
+// instance. Exactly one of condition and component is set.
+type PlaybookTrigger struct {
+    // Condition is a condition type in status.conditions, e.g. Ready
+    Condition string `json:"condition,omitempty"`
+    // Component is a component in status.components, e.g. cryptography
+    Component string `json:"component,omitempty"`
+    // Status the condition or component must have, e.g. False or Degraded
+    Status string `json:"status"`
+    // Reason the condition must have too, when set
+    Reason string `json:"reason,omitempty"`
+    // For is how long the match must hold before the playbook runs
+    For metav1.Duration `json:"for,omitempty"`
+}
+
+// PlaybookStep is one action of a playbook
+type PlaybookStep struct {
+    // +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+    Name string `json:"name"`
+    // Action is what the step does:
+    // restart deletes the component's pods one at a time, waiting for each
+    // replacement to be available;
+    // scale sets the component's replicas and waits for them;
+    // rotateCert re-issues the injected certificate of a Deployment;
+    // runExperiment runs a chaos experiment and fails unless it passes;
+    // notify records an event and, when the instance alerts, sends an
+    // alert through its Alertmanager
+    // +kubebuilder:validation:Enum=restart;scale;rotateCert;runExperiment;notify
+    Action string `json:"action"`
+
+    // Component restarted or scaled, e.g. cryptography or cryptography/<pool>
+    Component string `json:"component,omitempty"`
+    // Replicas the component is scaled to
+    // +kubebuilder:validation:Minimum=0
+    Replicas *int32 `json:"replicas,omitempty"`
+    // Deployment, in the playbook's namespace, whose certificate is rotated
+    Deployment string `json:"deployment,omitempty"`
+    // Experiment run by runExperiment
+    Experiment *ExperimentConfig `json:"experiment,omitempty"`
+    // Message sent by notify
+    Message string `json:"message,omitempty"`
+
+    // Timeout after which the step fails
+    // +kubebuilder:default="5m"
+    Timeout metav1.Duration `json:"timeout,omitempty"`
+    // ContinueOnFailure carries on with the next step when this one fails,
+    // instead of rolling back
+    ContinueOnFailure bool `json:"continueOnFailure,omitempty"`
+}
+
+// PlaybookSpec defines the desired state of Playbook
+type PlaybookSpec struct {
+    // Instance is the Qraiop instance, in the playbook's namespace, whose
+    // status triggers the playbook and whose components it acts on
+    Instance string          `json:"instance"`
+    Trigger  PlaybookTrigger `json:"trigger"`
+
+    // Steps run in order. When one fails or times out, it and the steps
+    // before it are rolled back in reverse order: scale steps restore the
+    // replicas of the instance's spec and runExperiment steps abort their
+    // experiment. The other actions have nothing to roll back.
+    // +kubebuilder:validation:MinItems=1
+    Steps []PlaybookStep `json:"steps"`
+
+    // Cooldown is how long after a run the playbook may not run again
+    // +kubebuilder:default="30m"
+    Cooldown metav1.Duration `json:"cooldown,omitempty"`
+
+    // Suspend stops new runs; a run in progress completes
+    Suspend bool `json:"suspend,omitempty"`
+}
+
+// PlaybookStepStatus is the progress of a step of a run
+type PlaybookStepStatus struct {
+    Name    string `json:"name"`
+    Phase   string `json:"phase"`
+    Message string `json:"message,omitempty"`
+
+    StartedAt  *metav1.Time `json:"startedAt,omitempty"`
+    FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+    // Applied is set once the step's action was applied and the step
+    // waits for its outcome
+    Applied bool `json:"applied,omitempty"`
+    // Previous is the replicas before a scale step
+    Previous *int32 `json:"previous,omitempty"`
+    // Experiment is the ChaosExperiment a runExperiment step started
+    Experiment string `json:"experiment,omitempty"`
+}
+
+// PlaybookRun is one run of a playbook
+type PlaybookRun struct {
+    // Phase is Running, Succeeded, Failed when a step failed and its
+    // rollback did too, or RolledBack
+    Phase string `json:"phase"`
+    // Trigger describes the match that started the run
+    Trigger    string       `json:"trigger"`
+    StartedAt  metav1.Time  `json:"startedAt"`
+    FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+    Steps []PlaybookStepStatus `json:"steps"`
+    // RollingBack is set while completed steps are rolled back
+    RollingBack bool `json:"rollingBack,omitempty"`
+}
+
+// PlaybookStatus defines the observed state of Playbook
+type PlaybookStatus struct {
+    // Current is the run in progress or, once done, the last run
+    Current *PlaybookRun `json:"current,omitempty"`
+    // Runs counts the runs started
+    Runs int32 `json:"runs,omitempty"`
+
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Instance",type=string,JSONPath=`.spec.instance`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.current.phase`
+// +kubebuilder:printcolumn:name="Runs",type=integer,JSONPath=`.status.runs`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type Playbook struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   PlaybookSpec   `json:"spec,omitempty"`
+    Status PlaybookStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type PlaybookList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []Playbook `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for Playbook
+func (in *Playbook) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for PlaybookList
+func (in *PlaybookList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&Playbook{}, &PlaybookList{})
+}
@@ -0,0 +1,73 @@
+// src/controllers/api/v1/prometheus_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrometheusConfig configures how Prometheus scrapes the components
+type PrometheusConfig struct {
+    // ServiceMonitors creates a Prometheus Operator ServiceMonitor for each
+    // component. It requires the monitoring.coreos.com CRDs.
+    ServiceMonitors bool `json:"serviceMonitors,omitempty"`
+
+    // Labels are added to the ServiceMonitors, e.g. to match the
+    // serviceMonitorSelector of the Prometheus scraping them
+    Labels map[string]string `json:"labels,omitempty"`
+
+    // Interval between scrapes
+    // +kubebuilder:default="30s"
+    Interval metav1.Duration `json:"interval,omitempty"`
+
+    // TLS scrapes the components over HTTPS with the certificates the
+    // crypto service issues them, in the Secret <component>-tls
+    TLS bool `json:"tls,omitempty"`
+
+    // BearerTokenSecret is sent as bearer token with every scrape
+    BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+    // RelabelConfigs are applied after the qraiop_instance and
+    // qraiop_component labels the operator sets on every target
+    RelabelConfigs []RelabelConfig `json:"relabelConfigs,omitempty"`
+
+    // Components override how individual components are scraped
+    Components []ComponentScrape `json:"components,omitempty"`
+
+    // URL of the Prometheus HTTP API the operator queries, e.g. for the
+    // error budgets of SLOs
+    URL string `json:"url,omitempty"`
+    // QueryTokenSecret is sent as bearer token with the queries
+    QueryTokenSecret *corev1.SecretKeySelector `json:"queryTokenSecret,omitempty"`
+}
+
+// ComponentScrape overrides where a component serves its metrics
+type ComponentScrape struct {
+    // Component is the component name, or cryptography/<pool> for a crypto pool
+    Component string `json:"component"`
+
+    // Path of the metrics endpoint
+    // +kubebuilder:default="/metrics"
+    Path string `json:"path,omitempty"`
+
+    // Port serves metrics apart from the component's API. It is exposed
+    // as the metrics port of the container and Service.
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:validation:Maximum=65535
+    Port int32 `json:"port,omitempty"`
+
+    // PodMonitor scrapes every pod directly through a PodMonitor instead of
+    // going through the Service, e.g. for per-pod metrics
+    PodMonitor bool `json:"podMonitor,omitempty"`
+}
+
+// RelabelConfig is a Prometheus relabeling rule
+type RelabelConfig struct {
+    SourceLabels []string `json:"sourceLabels,omitempty"`
+    Separator    string   `json:"separator,omitempty"`
+    TargetLabel  string   `json:"targetLabel,omitempty"`
+    Regex        string   `json:"regex,omitempty"`
+    Replacement  string   `json:"replacement,omitempty"`
+    // +kubebuilder:validation:Enum=replace;keep;drop;labelmap;labeldrop;labelkeep;hashmod;lowercase;uppercase
+    Action string `json:"action,omitempty"`
+}
@@ -2,29 +2,1241 @@
 package v1
 
 import (
+    corev1 "k8s.io/api/core/v1"
     metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
     "k8s.io/apimachinery/pkg/runtime"
 )
 
+// PausedAnnotation halts reconciliation of an instance while present,
+// independently of Spec.Paused, so GitOps tools and runbooks can pause an
+// instance without editing its spec. Setting it to "false" has no effect.
+const PausedAnnotation = "qraiop.io/paused"
+
+// ApprovePlanAnnotation approves the plan in Status.PlannedChanges whose
+// hash it holds, for instances with Spec.ApprovalRequired
+const ApprovePlanAnnotation = "qraiop.io/approve-plan"
+
+// Adoption policies for objects the instance does not control
+const (
+    AdoptionAdopt  = "Adopt"
+    AdoptionFail   = "Fail"
+    AdoptionIgnore = "Ignore"
+)
+
 // QraiopSpec defines the desired state of Qraiop
 type QraiopSpec struct {
-    // your spec fields
+    // Paused stops the controller from changing any child resources
+    Paused bool `json:"paused,omitempty"`
+
+    // ApprovalRequired holds changes to child resources back: they are
+    // listed in Status.PlannedChanges and made once the plan's hash is set
+    // in the qraiop.io/approve-plan annotation
+    ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
+    // AdoptionPolicy decides what happens to objects that already exist
+    // where the instance renders one but are not controlled by it, e.g. a
+    // Deployment left by a Helm install: Adopt takes them over, Ignore
+    // leaves them alone and Fail stops the reconcile with an Adoption
+    // condition. Objects controlled by something else are never adopted.
+    // +kubebuilder:validation:Enum=Adopt;Fail;Ignore
+    // +kubebuilder:default=Adopt
+    AdoptionPolicy string `json:"adoptionPolicy,omitempty"`
+
+    // Profile selects how components are sized. Edge renders single-replica,
+    // low-resource variants with slim images and no monitoring stack, for
+    // k3s and other constrained clusters.
+    // +kubebuilder:validation:Enum=Standard;Edge
+    // +kubebuilder:default=Standard
+    Profile string `json:"profile,omitempty"`
+
+    // ComponentVersion is the release of the component images to run.
+    // Versions newer than the operator supports are refused.
+    // +optional
+    ComponentVersion string `json:"componentVersion,omitempty"`
+
+    // CommonLabels and CommonAnnotations are added to every object the
+    // operator creates for this instance, e.g. GitOps tracking, commit IDs
+    // or cost allocation. They never override the operator's own labels.
+    CommonLabels      map[string]string `json:"commonLabels,omitempty"`
+    CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+    // ArgoCD annotates child objects for instances managed through Argo CD
+    ArgoCD *ArgoCDConfig `json:"argoCD,omitempty"`
+
+    // Impersonation makes the operator act in this namespace as a tenant
+    // ServiceAccount instead of its own cluster-wide identity
+    Impersonation *ImpersonationConfig `json:"impersonation,omitempty"`
+
+    Cryptography     CryptographyConfig `json:"cryptography,omitempty"`
+    AIOrchestration  AIConfig           `json:"aiOrchestration,omitempty"`
+    ChaosEngineering ChaosConfig        `json:"chaosEngineering,omitempty"`
+    Monitoring       MonitoringConfig   `json:"monitoring,omitempty"`
+    SecurityPolicies SecurityConfig     `json:"securityPolicies,omitempty"`
+
+    // SecurityPolicyRef takes the security policies from the
+    // QraiopSecurityPolicy of that name in the instance's namespace instead
+    // of SecurityPolicies, so they can be managed under separate RBAC
+    SecurityPolicyRef *corev1.LocalObjectReference `json:"securityPolicyRef,omitempty"`
+
+    // ComponentHealth sets when crashing components are reported Degraded
+    ComponentHealth ComponentHealthConfig `json:"componentHealth,omitempty"`
+
+    // Proxy routes outbound traffic of every component, and of the
+    // operator's own calls on behalf of this instance, through an egress proxy
+    Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+    // SelfTest periodically proves the operator survives losing its leader
+    // and API server throttling
+    SelfTest *SelfTestConfig `json:"selfTest,omitempty"`
+
+    // IncidentReports files a post-incident report whenever the instance
+    // recovers from being Degraded
+    IncidentReports *IncidentReportConfig `json:"incidentReports,omitempty"`
+
+    // Cost exports what each component requests, uses and costs
+    Cost *CostConfig `json:"cost,omitempty"`
+
+    // ComplianceReport schedules a signed crypto compliance report of the
+    // cluster for auditors
+    ComplianceReport *ComplianceReportConfig `json:"complianceReport,omitempty"`
+
+    // Budget caps the summed requests and limits of every workload the
+    // operator runs for the instance. Spec changes whose rollout would
+    // exceed it are not applied.
+    Budget *ResourceBudget `json:"budget,omitempty"`
+}
+
+// ResourceBudget caps the footprint of an instance, e.g. requests of
+// cpu: "4" and memory: 8Gi. Resources left out are not capped.
+type ResourceBudget struct {
+    Requests corev1.ResourceList `json:"requests,omitempty"`
+    Limits   corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// CostConfig prices component resources for cost attribution. Components
+// are charged for the larger of what they request and what they use,
+// usage coming from the metrics API when it is served.
+type CostConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Currency the prices are in, only used as a label
+    // +kubebuilder:default=USD
+    Currency string `json:"currency,omitempty"`
+    // CPUCoreHour is the price of one CPU core for an hour
+    // +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+    CPUCoreHour string `json:"cpuCoreHour,omitempty"`
+    // MemoryGiBHour is the price of one GiB of memory for an hour
+    // +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+    MemoryGiBHour string `json:"memoryGiBHour,omitempty"`
+    // Interval between samples
+    // +kubebuilder:default="5m"
+    Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+// IncidentReportConfig configures post-incident reports. Reports are kept
+// in ConfigMaps labelled qraiop.io/incident-report=<instance>.
+type IncidentReportConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // PostToChannels also sends each report to the slack and webhook
+    // channels of Monitoring.Alerting
+    PostToChannels bool `json:"postToChannels,omitempty"`
+    // Retain is how many reports are kept
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=10
+    Retain int32 `json:"retain,omitempty"`
+}
+
+// ReadyMarkerName is the ConfigMap published in each managed namespace for
+// dependent workloads to wait on; its ReadyMarkerKey is "true" once QRAIOP
+// is ready, including crypto certificates being available
+const (
+    ReadyMarkerName = "qraiop-ready"
+    ReadyMarkerKey  = "ready"
+)
+
+// SelfTestAnnotation runs an operator self-test whenever its value changes
+const SelfTestAnnotation = "qraiop.io/self-test"
+
+// SelfTestConfig schedules operator self-tests
+type SelfTestConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Interval between scheduled self-tests
+    // +kubebuilder:default="168h"
+    Interval metav1.Duration `json:"interval,omitempty"`
+    // FailoverTimeout is how long a new leader may take to take over
+    // +kubebuilder:default="2m"
+    FailoverTimeout metav1.Duration `json:"failoverTimeout,omitempty"`
+}
+
+// ArgoCDConfig makes child objects play well with Argo CD: they are marked
+// IgnoreExtraneous and never pruned, and optionally carry sync waves
+// following component dependency order
+type ArgoCDConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // SyncWaves annotates NetworkPolicies with SyncWaveBase and each
+    // component's objects with a later wave in dependency order
+    SyncWaves    bool  `json:"syncWaves,omitempty"`
+    SyncWaveBase int32 `json:"syncWaveBase,omitempty"`
+}
+
+// ImpersonationConfig limits the operator to a per-tenant ServiceAccount
+// when changing child objects. The operator creates the ServiceAccount and
+// a namespaced Role/RoleBinding for it.
+type ImpersonationConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // ServiceAccountName defaults to qraiop-reconciler
+    ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// ProxyConfig describes an egress HTTP(S) proxy
+type ProxyConfig struct {
+    HTTPProxy  string `json:"httpProxy,omitempty"`
+    HTTPSProxy string `json:"httpsProxy,omitempty"`
+    // NoProxy is a comma-separated list of destinations reached directly.
+    // In-cluster service domains are always added.
+    NoProxy string `json:"noProxy,omitempty"`
+
+    // TrustedCA selects a ConfigMap key with the PEM bundle used to verify
+    // TLS through the proxy. It replaces the system trust store, so it
+    // must also contain the public CAs components rely on.
+    TrustedCA *corev1.ConfigMapKeySelector `json:"trustedCA,omitempty"`
+
+    // EgressCIDRs pins the proxy egress NetworkPolicy to these ranges. When
+    // empty, proxies given by IP are allowed as /32s and proxies given by
+    // hostname are allowed on their port to any destination.
+    EgressCIDRs []string `json:"egressCIDRs,omitempty"`
+}
+
+// ComponentHealthConfig defines the restart thresholds that flip a component
+// to Degraded. Counts are summed over the component's current pods.
+type ComponentHealthConfig struct {
+    // +kubebuilder:default=5
+    // +kubebuilder:validation:Minimum=1
+    MaxRestarts int32 `json:"maxRestarts,omitempty"`
+    // +kubebuilder:default=1
+    // +kubebuilder:validation:Minimum=1
+    MaxOOMKills int32 `json:"maxOOMKills,omitempty"`
+
+    // Smoothing holds back status flips of components whose rollout state
+    // oscillates, to spare events and alerts. Blocked and Disabled, which
+    // follow from the spec, are never held back.
+    Smoothing *StatusSmoothing `json:"smoothing,omitempty"`
+}
+
+// StatusSmoothing is the hysteresis applied to component status: a new
+// status only shows once it has been observed ConsecutiveObservations
+// times in a row and, if set, for at least MinDwell
+type StatusSmoothing struct {
+    // +kubebuilder:default=3
+    // +kubebuilder:validation:Minimum=1
+    ConsecutiveObservations int32            `json:"consecutiveObservations,omitempty"`
+    MinDwell                *metav1.Duration `json:"minDwell,omitempty"`
+}
+
+// Deployment profiles
+const (
+    ProfileStandard = "Standard"
+    ProfileEdge     = "Edge"
+)
+
+// Component names, as used in Status.Components and DependsOn
+const (
+    ComponentCryptography     = "cryptography"
+    ComponentAIOrchestration  = "aiOrchestration"
+    ComponentChaosEngineering = "chaosEngineering"
+    ComponentMonitoring       = "monitoring"
+)
+
+// CryptographyConfig configures the quantum-safe crypto service
+type CryptographyConfig struct {
+    Enabled    bool     `json:"enabled,omitempty"`
+    Algorithms []string `json:"algorithms,omitempty"`
+    // +kubebuilder:validation:Enum=1;3;5
+    SecurityLevel int  `json:"securityLevel,omitempty"`
+    HybridMode    bool `json:"hybridMode,omitempty"`
+
+    ComponentOptions `json:",inline"`
+
+    // DependsOn lists components that must be Ready before this one is
+    // rolled out. Unset uses the default ordering; empty removes it.
+    // +kubebuilder:validation:items:Enum=cryptography;aiOrchestration;chaosEngineering;monitoring
+    DependsOn []string `json:"dependsOn,omitempty"`
+
+    // Client tunes the operator's connections to the crypto service
+    Client *CryptoClientConfig `json:"client,omitempty"`
+
+    // KeyUsage watches per-key operation rates for signs of key compromise
+    KeyUsage *KeyUsageConfig `json:"keyUsage,omitempty"`
+
+    // KeyRotation rotates the crypto service's keys on their own schedule,
+    // independent of the certificates re-issued when the service restarts
+    KeyRotation *KeyRotationConfig `json:"keyRotation,omitempty"`
+
+    // Revocation publishes a revocation list of the certificates the crypto
+    // service issued
+    Revocation *RevocationConfig `json:"revocation,omitempty"`
+
+    // Pools are further crypto services with their own algorithms and
+    // security level, each rendered as the Deployment and Service
+    // qraiop-crypto-<name>. Their Services are labelled with
+    // qraiop.io/crypto-pool and qraiop.io/security-level so consumers can
+    // pick one.
+    // +listType=map
+    // +listMapKey=name
+    Pools []CryptoPool `json:"pools,omitempty"`
+
+    // CertManagement issues injected certificates from further CAs, such
+    // as a corporate CA or Vault PKI, routed by namespace and SAN
+    CertManagement *CertManagementConfig `json:"certManagement,omitempty"`
+
+    // Enrollment issues certificates to clients outside the cluster
+    Enrollment *EnrollmentConfig `json:"enrollment,omitempty"`
+
+    // CertReissue re-issues the injected certificates that no longer match
+    // the algorithms, security level, routes or SANs in batches. Without
+    // it they are re-issued all at once.
+    CertReissue *CertReissueConfig `json:"certReissue,omitempty"`
+
+    // ExpiryForecast reports the TLS certificates of the namespace that
+    // expire soon, the workloads using them and whether they are renewed
+    ExpiryForecast *CertExpiryForecastConfig `json:"expiryForecast,omitempty"`
+}
+
+// Labels on the Services of crypto pools
+const (
+    CryptoPoolLabel    = "qraiop.io/crypto-pool"
+    SecurityLevelLabel = "qraiop.io/security-level"
+)
+
+// CryptoPool is a named crypto service with its own security settings
+type CryptoPool struct {
+    // +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+    // +kubebuilder:validation:MaxLength=40
+    Name       string   `json:"name"`
+    Algorithms []string `json:"algorithms,omitempty"`
+    // +kubebuilder:validation:Enum=1;3;5
+    SecurityLevel int  `json:"securityLevel"`
+    HybridMode    bool `json:"hybridMode,omitempty"`
+    // Replicas overrides the profile's replica count
+    // +kubebuilder:validation:Minimum=1
+    Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// KeyUsageConfig configures anomaly detection on the operation counts the
+// crypto service reports per key
+type KeyUsageConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Interval between usage samples. Defaults to 1m.
+    Interval metav1.Duration `json:"interval,omitempty"`
+    // SpikeFactor is how many times above a key's baseline rate an
+    // operation must run to be reported
+    // +kubebuilder:validation:Minimum=2
+    // +kubebuilder:default=10
+    SpikeFactor int32 `json:"spikeFactor,omitempty"`
+    // MinRate, in operations per minute, below which spikes are ignored
+    // +kubebuilder:validation:Minimum=1
+    // +kubebuilder:default=60
+    MinRate int32 `json:"minRate,omitempty"`
+}
+
+// KeyRotationConfig schedules rotation of the default crypto service's keys
+type KeyRotationConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Interval between rotations
+    // +kubebuilder:default="720h"
+    Interval metav1.Duration `json:"interval,omitempty"`
+    // Window limits rotations to a recurring maintenance window; without
+    // it keys are rotated as soon as they are due
+    Window *RotationWindow `json:"window,omitempty"`
+    // GraceOverlap is how long the previous key stays valid next to the new
+    // one, while consumers roll out with the new key
+    // +kubebuilder:default="24h"
+    GraceOverlap metav1.Duration `json:"graceOverlap,omitempty"`
+}
+
+// RotationWindow is a weekly recurring window
+type RotationWindow struct {
+    // +kubebuilder:validation:MinItems=1
+    Days []Weekday `json:"days"`
+    // StartTime is when the window opens, as HH:MM
+    // +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+    StartTime string          `json:"startTime"`
+    Duration  metav1.Duration `json:"duration"`
+    // TimeZone of StartTime as an IANA name, UTC by default
+    TimeZone string `json:"timeZone,omitempty"`
+}
+
+// InjectCertAnnotation set to "true" on a Deployment has the Qraiop
+// instance of its namespace issue it a certificate, which is mounted into
+// its pods at InjectedCertDir
+const InjectCertAnnotation = "qraiop.io/inject-cert"
+
+// CertSANsAnnotation on a Deployment lists, comma-separated, the DNS names
+// and IP addresses its certificate is issued for besides those of the
+// Service named like the Deployment
+const CertSANsAnnotation = "qraiop.io/cert-sans"
+
+// InjectedCertDir is where injected certificates are mounted, as tls.crt,
+// tls.key and ca.crt
+const InjectedCertDir = "/var/run/qraiop/tls"
+
+// InjectedCertSecret is the Secret holding the injected certificate of a
+// Deployment
+func InjectedCertSecret(deployment string) string {
+    return deployment + "-qraiop-tls"
+}
+
+// RevokeAnnotation on a TLS Secret of the instance revokes its certificate.
+// The value is the reason, e.g. keyCompromise.
+const RevokeAnnotation = "qraiop.io/revoke"
+
+// Revocation reasons, as in RFC 5280
+const (
+    RevocationUnspecified          = "unspecified"
+    RevocationKeyCompromise        = "keyCompromise"
+    RevocationSuperseded           = "superseded"
+    RevocationCessationOfOperation = "cessationOfOperation"
+)
+
+// RevocationConfig configures the revocation list. The list, signed by the
+// crypto service, is published in the ConfigMap qraiop-crl and mounted into
+// every component, which is told about each new list straight away.
+type RevocationConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // Revoked lists certificates to revoke by serial number, next to the
+    // Secrets carrying the qraiop.io/revoke annotation
+    Revoked []RevokedCertificate `json:"revoked,omitempty"`
+    // UpdateInterval is how often the list is re-signed even without changes;
+    // it is the list's nextUpdate
+    // +kubebuilder:default="24h"
+    UpdateInterval metav1.Duration `json:"updateInterval,omitempty"`
+}
+
+// RevokedCertificate is a certificate revoked through the spec
+type RevokedCertificate struct {
+    // SerialNumber in hex
+    // +kubebuilder:validation:Pattern=`^[0-9a-fA-F:]+$`
+    SerialNumber string `json:"serialNumber"`
+    // +kubebuilder:validation:Enum=unspecified;keyCompromise;superseded;cessationOfOperation
+    // +kubebuilder:default=unspecified
+    Reason string `json:"reason,omitempty"`
+}
+
+// ComponentOptions are the settings every component shares
+type ComponentOptions struct {
+    // CloudIdentity binds the component's ServiceAccount to a cloud IAM identity
+    CloudIdentity *CloudIdentity `json:"cloudIdentity,omitempty"`
+
+    // FeatureFlags are rendered into the component's flags ConfigMap,
+    // mounted at /etc/qraiop/flags/flags.json
+    FeatureFlags map[string]string `json:"featureFlags,omitempty"`
+    // FlagReload is how running pods pick up changed flags: Annotation
+    // stamps the new flags hash on the pods, which see it through the
+    // downward API and reload in place; Restart rolls the Deployment
+    // +kubebuilder:validation:Enum=Annotation;Restart
+    // +kubebuilder:default=Annotation
+    FlagReload string `json:"flagReload,omitempty"`
+
+    // Lifecycle controls how the component's pods shut down
+    Lifecycle *LifecycleConfig `json:"lifecycle,omitempty"`
+
+    // Image replaces the component's image, e.g. with a mirror in a
+    // private registry. Without a tag or digest the tag of
+    // Spec.ComponentVersion is appended.
+    Image string `json:"image,omitempty"`
+    // ImagePullPolicy of the component's container
+    // +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+    ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+    // ImagePullSecrets hold the credentials of the private registries the
+    // component's images, including those of sidecars, are pulled from
+    ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+    // Command replaces the image's entrypoint, e.g. to run a debug build
+    Command []string `json:"command,omitempty"`
+    // Args are passed to the component. Flags the operator sets itself,
+    // such as --port, are refused.
+    Args []string `json:"args,omitempty"`
+    // LogLevel of the component
+    // +kubebuilder:validation:Enum=debug;info;warn;error
+    LogLevel string `json:"logLevel,omitempty"`
+
+    // PodLabels and PodAnnotations are added to the component's pods, e.g.
+    // sidecar.istio.io/inject or vault.hashicorp.com/agent-inject. They take
+    // precedence over CommonLabels and CommonAnnotations; the selector
+    // labels and qraiop.io/ keys the operator sets are refused.
+    PodLabels      map[string]string `json:"podLabels,omitempty"`
+    PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+    // DNSPolicy of the component's pods. None resolves through DNSConfig
+    // alone, e.g. with the resolvers of an air-gapped network.
+    // +kubebuilder:validation:Enum=ClusterFirst;ClusterFirstWithHostNet;Default;None
+    DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+    // DNSConfig adds nameservers, search domains and resolver options to
+    // those of DNSPolicy, e.g. for split-horizon DNS
+    DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+    // HostAliases are added to the pods' /etc/hosts, for hosts no resolver
+    // knows about
+    HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+    // InitContainers run after the operator's own, before the component
+    // starts, e.g. to fetch configuration. With restartPolicy Always they
+    // are native sidecars.
+    InitContainers []corev1.Container `json:"initContainers,omitempty"`
+    // Sidecars run alongside the component, e.g. vault-agent or envoy.
+    // Their names and ports may not clash with the operator's containers.
+    Sidecars []corev1.Container `json:"sidecars,omitempty"`
+    // Volumes the init containers and sidecars mount, in addition to the
+    // pod's own
+    Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+    // Deployment sizes and places the component's pods, over the defaults
+    // of Spec.Profile
+    Deployment *ComponentDeploymentConfig `json:"deployment,omitempty"`
+
+    // Schedules scale the component by time of day, e.g. three AI replicas
+    // during office hours. The first active schedule wins; outside all of
+    // them the profile's count applies. With a HorizontalPodAutoscaler on
+    // the component, the schedule raises its minReplicas instead.
+    // +listType=map
+    // +listMapKey=name
+    Schedules []ScalingSchedule `json:"schedules,omitempty"`
 }
 
+// ComponentDeploymentConfig sizes and places the pods of a component
+type ComponentDeploymentConfig struct {
+    // Replicas overrides the profile's replica count. Schedules, an
+    // autoscaler and playbooks still take precedence, and crypto pools
+    // keep their own.
+    // +kubebuilder:validation:Minimum=1
+    Replicas *int32 `json:"replicas,omitempty"`
+    // Resources of the component's container. Each request and limit set
+    // replaces the profile's; the others are kept.
+    Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+    // NodeSelector, Tolerations and Affinity constrain the nodes the pods
+    // are scheduled on
+    NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+    Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+    Affinity     *corev1.Affinity    `json:"affinity,omitempty"`
+}
+
+// ScalingSchedule is a weekly recurring window with its replica count
+type ScalingSchedule struct {
+    Name string `json:"name"`
+    // +kubebuilder:validation:MinItems=1
+    Days []Weekday `json:"days"`
+    // StartTime is when the window opens, as HH:MM
+    // +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+    StartTime string          `json:"startTime"`
+    Duration  metav1.Duration `json:"duration"`
+    // TimeZone of StartTime as an IANA name, UTC by default
+    TimeZone string `json:"timeZone,omitempty"`
+    // +kubebuilder:validation:Minimum=1
+    Replicas int32 `json:"replicas"`
+}
+
+// ScheduledMinReplicasAnnotation on a HorizontalPodAutoscaler keeps its
+// own minReplicas while a scaling schedule has raised it
+const ScheduledMinReplicasAnnotation = "qraiop.io/scheduled-min-replicas"
+
+// LifecycleConfig controls how a component's pods shut down, so rollouts
+// and node drains don't cut off in-flight operations
+type LifecycleConfig struct {
+    // PreStop runs in the component's container before it is sent SIGTERM,
+    // e.g. to stop accepting work and drain what is in flight
+    PreStop *corev1.LifecycleHandler `json:"preStop,omitempty"`
+    // TerminationGracePeriodSeconds is how long a pod gets to shut down,
+    // PreStop included, before it is killed
+    // +kubebuilder:validation:Minimum=0
+    TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// Feature flag reload strategies
+const (
+    FlagReloadAnnotation = "Annotation"
+    FlagReloadRestart    = "Restart"
+)
+
+// Cloud providers with workload identity support
+const (
+    CloudProviderAWS   = "aws"
+    CloudProviderGCP   = "gcp"
+    CloudProviderAzure = "azure"
+)
+
+// CloudIdentity lets a component authenticate to its cloud's KMS or object
+// storage through workload identity: IRSA on EKS, Workload Identity on GKE
+// or Azure Workload Identity on AKS
+type CloudIdentity struct {
+    // +kubebuilder:validation:Enum=aws;gcp;azure
+    Provider string `json:"provider"`
+    // Identity is the IAM role ARN (aws), Google service account email
+    // (gcp) or managed identity client ID (azure)
+    Identity string `json:"identity"`
+    // Audience of the projected token, where the provider supports it (aws)
+    Audience string `json:"audience,omitempty"`
+    // TenantID overrides the cluster's default Azure tenant
+    TenantID string `json:"tenantID,omitempty"`
+    // Annotations are added to the ServiceAccount as-is
+    Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CryptoClientConfig tunes the pool of connections the operator keeps to
+// the crypto service for rotation and signing calls
+type CryptoClientConfig struct {
+    // MaxConnections caps the pool; calls beyond it wait. Defaults to 8.
+    // +kubebuilder:validation:Minimum=1
+    MaxConnections int32 `json:"maxConnections,omitempty"`
+    // IdleTimeout closes pooled connections unused this long. Defaults to 90s.
+    IdleTimeout metav1.Duration `json:"idleTimeout,omitempty"`
+    // KeepAlive is the TCP (or HTTP/2 ping) keepalive interval. Defaults to 30s.
+    KeepAlive metav1.Duration `json:"keepAlive,omitempty"`
+    // CallTimeout is the deadline of each call. Defaults to 10s.
+    CallTimeout metav1.Duration `json:"callTimeout,omitempty"`
+    // HTTP2 multiplexes calls over cleartext HTTP/2 connections
+    HTTP2 bool `json:"http2,omitempty"`
+}
+
+// AIConfig configures the AI orchestration agents
+type AIConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // +kubebuilder:validation:Enum=openai;anthropic;local
+    LLMProvider string `json:"llmProvider,omitempty"`
+
+    // CredentialsSource syncs the provider API keys, e.g. OPENAI_API_KEY,
+    // into the <instance>-llm-credentials Secret, whose keys the agents
+    // get as environment variables
+    CredentialsSource *SecretSource `json:"credentialsSource,omitempty"`
+
+    // Model configures the LLM of every agent without an override in Agents
+    Model *ModelConfig `json:"model,omitempty"`
+
+    // Agents override the model per agent, e.g. a stronger model for the
+    // security agent and a cheap local one for the monitoring agent
+    // +listType=map
+    // +listMapKey=name
+    Agents []AgentConfig `json:"agents,omitempty"`
+
+    // CryptoPool is the crypto pool the agents use instead of the default
+    // crypto service
+    CryptoPool string `json:"cryptoPool,omitempty"`
+
+    ComponentOptions `json:",inline"`
+
+    // DependsOn lists components that must be Ready before this one is
+    // rolled out. Defaults to cryptography, whose certs the agents use.
+    // +kubebuilder:validation:items:Enum=cryptography;aiOrchestration;chaosEngineering;monitoring
+    DependsOn []string `json:"dependsOn,omitempty"`
+
+    // Idle scales the agents to zero when they have had no tasks for a while
+    Idle *IdlePolicy `json:"idle,omitempty"`
+}
+
+// ModelConfig selects and tunes an LLM. Unset fields fall back to the
+// instance-wide model, then to the agents' built-in defaults.
+type ModelConfig struct {
+    // Provider overrides llmProvider
+    // +kubebuilder:validation:Enum=openai;anthropic;local
+    Provider string `json:"provider,omitempty"`
+    // Model is the provider's model name
+    Model string `json:"model,omitempty"`
+    // Temperature is a decimal between 0 and 2
+    // +kubebuilder:validation:Pattern=`^([01](\.[0-9]+)?|2(\.0+)?)$`
+    Temperature string `json:"temperature,omitempty"`
+    // MaxTokens caps the tokens of each completion
+    // +kubebuilder:validation:Minimum=1
+    MaxTokens int32 `json:"maxTokens,omitempty"`
+}
+
+// AgentConfig overrides the model of a single agent
+type AgentConfig struct {
+    // +kubebuilder:validation:Enum=supervisor;security;infrastructure;monitoring;chaos
+    Name string `json:"name"`
+
+    ModelConfig `json:",inline"`
+}
+
+// IdlePolicy scales the AI orchestration component to zero after a period
+// without tasks. It is woken by a new security incident, by the instance
+// turning Degraded, or by changing the qraiop.io/wake annotation.
+type IdlePolicy struct {
+    Enabled bool `json:"enabled,omitempty"`
+    // After is how long the agents may go without tasks before they are
+    // scaled to zero
+    // +kubebuilder:default="30m"
+    After metav1.Duration `json:"after,omitempty"`
+}
+
+// WakeAnnotation wakes an idle AI orchestration component whenever its
+// value changes
+const WakeAnnotation = "qraiop.io/wake"
+
+// ChaosConfig configures the chaos engineering engine
+type ChaosConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    ComponentOptions `json:",inline"`
+
+    // DependsOn lists components that must be Ready before this one is
+    // rolled out. Defaults to monitoring, so experiments are observed.
+    // +kubebuilder:validation:items:Enum=cryptography;aiOrchestration;chaosEngineering;monitoring
+    DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// MonitoringConfig configures the monitoring stack
+type MonitoringConfig struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // Alerting configures Alertmanager integration and silences
+    Alerting *AlertingConfig `json:"alerting,omitempty"`
+
+    // Prometheus configures ServiceMonitors scraping the components
+    Prometheus *PrometheusConfig `json:"prometheus,omitempty"`
+
+    // SLOs of the components, alerted on when they burn their error budget
+    // +listType=map
+    // +listMapKey=name
+    SLOs []ComponentSLO `json:"slos,omitempty"`
+
+    ComponentOptions `json:",inline"`
+
+    // DependsOn lists components that must be Ready before this one is
+    // rolled out. Unset uses the default ordering; empty removes it.
+    // +kubebuilder:validation:items:Enum=cryptography;aiOrchestration;chaosEngineering;monitoring
+    DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// SecurityConfig defines the security policies enforced for an instance
+type SecurityConfig struct {
+    NetworkPolicies NetworkPolicyConfig `json:"networkPolicies,omitempty"`
+
+    // Registries limits the registries pods in the managed namespaces may
+    // pull from, checked by the operator's admission webhook
+    Registries *RegistryPolicy `json:"registries,omitempty"`
+}
+
+const (
+    // RegistryPolicyLabel marks the namespaces whose pods the registry
+    // webhook checks. The operator sets it on the namespaces of enabled
+    // policies; a namespace left labelled without one admits every pod.
+    RegistryPolicyLabel = "qraiop.io/registry-policy"
+
+    // RegistryBypassAnnotation exempts a pod, or the pods of a workload's
+    // template, from the registry allowlist. Its value is the reason.
+    // Setting it takes the bypass verb on registrypolicies.qraiop.io in the
+    // namespace.
+    RegistryBypassAnnotation = "qraiop.io/bypass-registry-policy"
+)
+
+// Registry policy modes
+const (
+    RegistryPolicyEnforce = "Enforce"
+    RegistryPolicyAudit   = "Audit"
+)
+
+// RegistryPolicy is an allowlist of image registries
+type RegistryPolicy struct {
+    Enabled bool `json:"enabled,omitempty"`
+
+    // Allowed registries, optionally with a repository prefix, e.g.
+    // ghcr.io/bailey7220 or *.dkr.ecr.eu-west-1.amazonaws.com. Images
+    // without a registry are on docker.io.
+    // +kubebuilder:validation:MinItems=1
+    Allowed []string `json:"allowed"`
+
+    // Mode Audit admits pods from other registries with a warning
+    // +kubebuilder:validation:Enum=Enforce;Audit
+    // +kubebuilder:default=Enforce
+    Mode string `json:"mode,omitempty"`
+
+    // Namespaces checked besides the instance's own
+    Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// NetworkPolicyConfig defines the NetworkPolicies generated for an instance
+type NetworkPolicyConfig struct {
+    DefaultDenyAll           bool `json:"defaultDenyAll,omitempty"`
+    AllowQraiopCommunication bool `json:"allowQraiopCommunication,omitempty"`
+
+    // Learning observes live traffic before DefaultDenyAll is enforced and
+    // proposes least-privilege allow policies for review
+    Learning *NetworkPolicyLearning `json:"learning,omitempty"`
+
+    // Rollout stages DefaultDenyAll: each namespace is audited first, with
+    // would-be-denied flows counted from the flow collector, and enforced
+    // one at a time on a schedule. Without it DefaultDenyAll is enforced
+    // in the instance namespace at once.
+    Rollout *NetworkPolicyRollout `json:"rollout,omitempty"`
+}
+
+// NetworkPolicyRollout configures the staged rollout of DefaultDenyAll
+type NetworkPolicyRollout struct {
+    // Namespaces to enforce, in order; defaults to the instance namespace
+    Namespaces []string `json:"namespaces,omitempty"`
+
+    // Source selects the flow collector audited flows are read from.
+    // hubble reports what Cilium actually saw.
+    // +kubebuilder:validation:Enum=hubble;conntrack;flowlogs
+    // +kubebuilder:default=conntrack
+    Source string `json:"source,omitempty"`
+
+    // AuditDuration is how long a namespace is audited before enforcement
+    // +kubebuilder:default="24h"
+    AuditDuration metav1.Duration `json:"auditDuration,omitempty"`
+
+    // Interval between enforcing one namespace and auditing the next
+    // +kubebuilder:default="24h"
+    Interval metav1.Duration `json:"interval,omitempty"`
+
+    // MaxDeniedFlows a namespace's audit may find and still be enforced.
+    // Namespaces over it are held and audited again.
+    // +kubebuilder:validation:Minimum=0
+    MaxDeniedFlows int `json:"maxDeniedFlows,omitempty"`
+}
+
+// NetworkPolicy rollout phases of a namespace
+const (
+    RolloutPending  = "Pending"
+    RolloutAuditing = "Auditing"
+    RolloutHeld     = "Held"
+    RolloutEnforced = "Enforced"
+)
+
+// NetworkPolicyLearning configures traffic observation for NetworkPolicy generation
+type NetworkPolicyLearning struct {
+    Enabled bool `json:"enabled"`
+
+    // Source selects which flow collector's records are consumed
+    // +kubebuilder:validation:Enum=hubble;conntrack;flowlogs
+    // +kubebuilder:default=conntrack
+    Source string `json:"source,omitempty"`
+
+    // Duration is how long traffic is observed before a draft is proposed
+    // +kubebuilder:default="24h"
+    Duration metav1.Duration `json:"duration,omitempty"`
+}
+
+// NetworkPolicyLearning phases
+const (
+    LearningPhaseObserving = "Observing"
+    LearningPhaseProposed  = "Proposed"
+    LearningPhaseApproved  = "Approved"
+)
+
+// LearningStatus records the progress of NetworkPolicy learning mode
+type LearningStatus struct {
+    Phase         string       `json:"phase,omitempty"`
+    StartTime     metav1.Time  `json:"startTime,omitempty"`
+    CompletedTime *metav1.Time `json:"completedTime,omitempty"`
+    ObservedFlows int          `json:"observedFlows,omitempty"`
+    DraftName     string       `json:"draftName,omitempty"`
+}
+
+// NamespaceRollout is the DefaultDenyAll rollout state of a namespace
+type NamespaceRollout struct {
+    Namespace string `json:"namespace"`
+    Phase     string `json:"phase"`
+    // AuditStart is when the current audit window opened
+    AuditStart *metav1.Time `json:"auditStart,omitempty"`
+    // DeniedFlows found by the last completed audit
+    DeniedFlows int          `json:"deniedFlows,omitempty"`
+    EnforcedAt  *metav1.Time `json:"enforcedAt,omitempty"`
+    Message     string       `json:"message,omitempty"`
+}
+
+// Component status values
+const (
+    ComponentReady       = "Ready"
+    ComponentProgressing = "Progressing"
+    ComponentBlocked     = "Blocked"
+    ComponentDisabled    = "Disabled"
+    ComponentDegraded    = "Degraded"
+)
+
 // ComponentStatus defines individual component status
 type ComponentStatus struct {
     Status      string      `json:"status"`
     Message     string      `json:"message,omitempty"`
     LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+
+    // Observed is the component's latest raw status while smoothing is
+    // configured; it runs ahead of Status while a flip is held back
+    Observed *ObservedComponentStatus `json:"observed,omitempty"`
+
+    Restarts *RestartStats `json:"restarts,omitempty"`
+    // FeatureFlags is the flag set the component was given and how far
+    // its pods got in reloading it
+    FeatureFlags *FeatureFlagStatus `json:"featureFlags,omitempty"`
+}
+
+// ObservedComponentStatus is a component status as last observed, before
+// smoothing
+type ObservedComponentStatus struct {
+    Status  string `json:"status"`
+    Message string `json:"message,omitempty"`
+    // Since is when the component was first observed in Status
+    Since metav1.Time `json:"since"`
+    // Observations counts the reconciles in a row that observed Status, up
+    // to the number needed to flip
+    Observations int32 `json:"observations"`
+}
+
+// FeatureFlagStatus tracks delivery of a component's feature flags
+type FeatureFlagStatus struct {
+    Flags map[string]string `json:"flags,omitempty"`
+    Hash  string            `json:"hash"`
+    // PodsUpdated of PodsTotal running pods have been told about Hash
+    PodsUpdated int32 `json:"podsUpdated"`
+    PodsTotal   int32 `json:"podsTotal"`
+}
+
+// RestartStats aggregates container restarts across a component's pods
+type RestartStats struct {
+    TotalRestarts int32 `json:"totalRestarts"`
+    // OOMKills counts containers whose last termination was an OOM kill
+    OOMKills int32 `json:"oomKills"`
+    // CrashLooping counts containers waiting in CrashLoopBackOff
+    CrashLooping          int32        `json:"crashLooping"`
+    LastTerminationReason string       `json:"lastTerminationReason,omitempty"`
+    LastTerminationTime   *metav1.Time `json:"lastTerminationTime,omitempty"`
+    // LastTerminatedPod is the pod whose container terminated most recently
+    LastTerminatedPod string `json:"lastTerminatedPod,omitempty"`
 }
 
-// QraiopStatus defines the observed state of Qraiop
+// Self-test phases
+const (
+    SelfTestRunning = "Running"
+    SelfTestPassed  = "Passed"
+    SelfTestFailed  = "Failed"
+)
+
+// SelfTestStatus records the progress and results of an operator self-test
+type SelfTestStatus struct {
+    Phase   string `json:"phase,omitempty"`
+    Trigger string `json:"trigger,omitempty"`
+    // Step is the step awaiting verification across an operator restart
+    Step string `json:"step,omitempty"`
+    // LeaderBefore is the lease holder that was killed
+    LeaderBefore   string           `json:"leaderBefore,omitempty"`
+    StartTime      *metav1.Time     `json:"startTime,omitempty"`
+    StepStartTime  *metav1.Time     `json:"stepStartTime,omitempty"`
+    CompletionTime *metav1.Time     `json:"completionTime,omitempty"`
+    Results        []SelfTestResult `json:"results,omitempty"`
+}
+
+// SelfTestResult is the outcome of one self-test step
+type SelfTestResult struct {
+    Name    string `json:"name"`
+    Passed  bool   `json:"passed"`
+    Message string `json:"message,omitempty"`
+}
+
+// QraiopStatus defines the observed state of Qraiop. It is shaped for
+// generic health checks such as Argo CD's: ObservedGeneration trails
+// metadata.generation until a spec change has been reconciled, the Ready
+// condition is True once every enabled component is ready and otherwise
+// has the Phase as its Reason, and the Paused condition is True while
+// reconciliation is suspended.
 type QraiopStatus struct {
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
     Phase       string                     `json:"phase,omitempty"`
     Message     string                     `json:"message,omitempty"`
     Components  map[string]ComponentStatus `json:"components,omitempty"`
     LastUpdated metav1.Time                `json:"lastUpdated,omitempty"`
     Conditions  []metav1.Condition         `json:"conditions,omitempty"`
+
+    NetworkPolicyLearning *LearningStatus `json:"networkPolicyLearning,omitempty"`
+
+    // NetworkPolicyRollout tracks which namespaces DefaultDenyAll is
+    // audited in and enforced in
+    NetworkPolicyRollout []NamespaceRollout `json:"networkPolicyRollout,omitempty"`
+
+    // RegistryPolicyNamespaces are the namespaces labelled for the registry
+    // webhook, to unlabel those that leave the policy
+    RegistryPolicyNamespaces []string        `json:"registryPolicyNamespaces,omitempty"`
+    SelfTest                 *SelfTestStatus `json:"selfTest,omitempty"`
+
+    // OperatorVersion is the operator version that last completed its
+    // upgrade hooks for this instance
+    OperatorVersion string `json:"operatorVersion,omitempty"`
+
+    // Silences are the Alertmanager silences created from Spec.Monitoring.Alerting
+    Silences []SilenceStatus `json:"silences,omitempty"`
+
+    // Inventory lists every object the controller manages for this
+    // instance. Objects that drop out of it are pruned.
+    Inventory []InventoryEntry `json:"inventory,omitempty"`
+
+    // PlannedChanges are the changes waiting for approval when
+    // Spec.ApprovalRequired is set
+    PlannedChanges *PlannedChanges `json:"plannedChanges,omitempty"`
+
+    // SecurityIncidents are the open incidents detected by the operator
+    SecurityIncidents []SecurityIncident `json:"securityIncidents,omitempty"`
+
+    // DegradedSince is when the current Degraded period began
+    DegradedSince *metav1.Time `json:"degradedSince,omitempty"`
+    // LastIncidentReport is the ConfigMap holding the latest incident report
+    LastIncidentReport string `json:"lastIncidentReport,omitempty"`
+
+    // AIIdle tracks the idle policy of the AI orchestration component
+    AIIdle *IdleStatus `json:"aiIdle,omitempty"`
+
+    // Agents are the heartbeats and task queues of the AI agents
+    Agents []AgentHeartbeat `json:"agents,omitempty"`
+
+    // KeyRotation tracks the scheduled rotation of the crypto keys
+    KeyRotation *KeyRotationStatus `json:"keyRotation,omitempty"`
+
+    // CertReissue tracks the re-issue of the injected certificates that no
+    // longer match the spec
+    CertReissue *CertReissueStatus `json:"certReissue,omitempty"`
+
+    // CertExpiry is the forecast of Spec.Cryptography.ExpiryForecast
+    CertExpiry *CertExpiryStatus `json:"certExpiry,omitempty"`
+
+    // Revocation is the revocation list last published
+    Revocation *RevocationStatus `json:"revocation,omitempty"`
+
+    // Cost is the latest cost sample per subsystem
+    Cost *CostStatus `json:"cost,omitempty"`
+
+    // CryptoOperations are the crypto service calls started but not yet
+    // known to have completed. They are persisted before each call, so a
+    // restarted controller resumes them with the same idempotency key.
+    CryptoOperations []CryptoOperation `json:"cryptoOperations,omitempty"`
+
+    // CryptoService is what the running crypto service reported it
+    // supports. Spec algorithms are negotiated against it.
+    CryptoService *CryptoServiceStatus `json:"cryptoService,omitempty"`
+
+    // CertificateAuthorities are the authorities of Spec.Cryptography.CertManagement
+    CertificateAuthorities []CertificateAuthorityStatus `json:"certificateAuthorities,omitempty"`
+
+    // SLOs are the error budgets of Spec.Monitoring.SLOs
+    SLOs []SLOStatus `json:"slos,omitempty"`
+
+    // ComplianceReport tracks the scans of Spec.ComplianceReport
+    ComplianceReport *ComplianceReportStatus `json:"complianceReport,omitempty"`
+
+    // Resilience scores how well chaos experiments exercise the instance's
+    // namespace
+    Resilience *ResilienceStatus `json:"resilience,omitempty"`
+
+    // Footprint is what the instance's workloads request and are limited
+    // to in total, for namespace capacity planning
+    Footprint *FootprintStatus `json:"footprint,omitempty"`
+
+    // SecretSources are the syncs of the instance's external secrets
+    SecretSources []SecretSourceStatus `json:"secretSources,omitempty"`
+}
+
+// FootprintStatus sums the resources of the workloads of an instance.
+// Deployments and StatefulSets count every replica, Jobs and CronJobs
+// the pods of one run at full parallelism. Containers without a limit add
+// nothing to Limits.
+type FootprintStatus struct {
+    Requests  corev1.ResourceList `json:"requests,omitempty"`
+    Limits    corev1.ResourceList `json:"limits,omitempty"`
+    Workloads []WorkloadFootprint `json:"workloads,omitempty"`
+}
+
+// WorkloadFootprint is what one workload requests and is limited to
+type WorkloadFootprint struct {
+    Kind     string              `json:"kind"`
+    Name     string              `json:"name"`
+    Pods     int32               `json:"pods"`
+    Requests corev1.ResourceList `json:"requests,omitempty"`
+    Limits   corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// ResilienceStatus is the chaos coverage of a namespace over the last 30
+// days and the resilience score derived from it
+type ResilienceStatus struct {
+    // Score out of 100 from the pass rate, the fault types covered, the
+    // time since the last experiment and the experiments run
+    Score       int32        `json:"score"`
+    Experiments int32        `json:"experiments"`
+    Passed      int32        `json:"passed"`
+    Failed      int32        `json:"failed"`
+    FaultTypes  []string     `json:"faultTypes,omitempty"`
+    LastRun     *metav1.Time `json:"lastRun,omitempty"`
+}
+
+// CryptoServiceStatus is the API version and algorithms a crypto service
+// registered
+type CryptoServiceStatus struct {
+    APIVersion string   `json:"apiVersion,omitempty"`
+    Algorithms []string `json:"algorithms,omitempty"`
+    // ImageTag is the tag of the crypto service image that reported them.
+    // Pools run the same build under their own image names.
+    ImageTag   string      `json:"imageTag,omitempty"`
+    ObservedAt metav1.Time `json:"observedAt"`
+}
+
+// CryptoOperation is a pending call to the crypto service
+type CryptoOperation struct {
+    // Type is RotateKeys, RetireKey or SignRevocationList
+    Type string `json:"type"`
+    // IdempotencyKey is sent with every attempt; the service answers a
+    // repeated key with the outcome of the first call
+    IdempotencyKey string `json:"idempotencyKey"`
+    // Subject is what the operation acts on: the key retired or the
+    // number of the list signed
+    Subject   string      `json:"subject,omitempty"`
+    StartedAt metav1.Time `json:"startedAt"`
+    Attempts  int32       `json:"attempts"`
+    LastError string      `json:"lastError,omitempty"`
+}
+
+// Crypto operation types
+const (
+    CryptoOperationRotateKeys         = "RotateKeys"
+    CryptoOperationRetireKey          = "RetireKey"
+    CryptoOperationSignRevocationList = "SignRevocationList"
+)
+
+// CostStatus is the hourly cost of the instance's components
+type CostStatus struct {
+    Currency  string      `json:"currency,omitempty"`
+    SampledAt metav1.Time `json:"sampledAt"`
+    // Hourly is the cost of all components per hour
+    Hourly string `json:"hourly"`
+    // Subsystems break Hourly down into crypto, ai, chaos and monitoring
+    Subsystems []SubsystemCost `json:"subsystems,omitempty"`
+}
+
+// SubsystemCost is what one subsystem's components cost per hour
+type SubsystemCost struct {
+    Subsystem string `json:"subsystem"`
+    Hourly    string `json:"hourly"`
+}
+
+// RevocationStatus is the published revocation list
+type RevocationStatus struct {
+    // Number of the list, increased with every list signed
+    Number      int64       `json:"number"`
+    PublishedAt metav1.Time `json:"publishedAt"`
+    NextUpdate  metav1.Time `json:"nextUpdate"`
+    // Revoked are the certificates on the list
+    Revoked []RevokedEntry `json:"revoked,omitempty"`
+    // Hash of Revoked as last signed, to detect changes
+    Hash string `json:"hash,omitempty"`
+}
+
+// RevokedEntry is a certificate on the revocation list
+type RevokedEntry struct {
+    SerialNumber string      `json:"serialNumber"`
+    Reason       string      `json:"reason"`
+    RevokedAt    metav1.Time `json:"revokedAt"`
+    // Source is the spec or the Secret the revocation came from
+    Source string `json:"source"`
+}
+
+// Key rotation phases
+const (
+    // KeyRotationIdle waits for the next rotation
+    KeyRotationIdle = "Idle"
+    // KeyRotationOverlap has the previous and current key both valid
+    // while consumers roll out
+    KeyRotationOverlap = "Overlap"
+)
+
+// KeyRotationStatus is the state of scheduled key rotation
+type KeyRotationStatus struct {
+    Phase         string `json:"phase"`
+    CurrentKeyID  string `json:"currentKeyId,omitempty"`
+    PreviousKeyID string `json:"previousKeyId,omitempty"`
+    // OverlapEndsAt is when the previous key is retired, once every
+    // consumer runs with the current one
+    OverlapEndsAt *metav1.Time `json:"overlapEndsAt,omitempty"`
+    LastRotated   *metav1.Time `json:"lastRotated,omitempty"`
+    NextRotation  *metav1.Time `json:"nextRotation,omitempty"`
+    // History lists the latest rotations, newest first
+    History []KeyRotationRecord `json:"history,omitempty"`
+}
+
+// KeyRotationRecord is one past rotation
+type KeyRotationRecord struct {
+    KeyID         string       `json:"keyId"`
+    PreviousKeyID string       `json:"previousKeyId,omitempty"`
+    RotatedAt     metav1.Time  `json:"rotatedAt"`
+    RetiredAt     *metav1.Time `json:"retiredAt,omitempty"`
+}
+
+// AgentHeartbeat is the liveness of an agent as seen through its heartbeat Lease
+type AgentHeartbeat struct {
+    Name string `json:"name"`
+    // Ready is false once the agent's Lease has not been renewed in time
+    Ready bool `json:"ready"`
+    // Holder is the pod holding the agent's Lease
+    Holder        string       `json:"holder,omitempty"`
+    LastHeartbeat *metav1.Time `json:"lastHeartbeat,omitempty"`
+    // QueuedTasks and ActiveTasks count the agent's unfinished AgentTasks
+    QueuedTasks int32 `json:"queuedTasks,omitempty"`
+    ActiveTasks int32 `json:"activeTasks,omitempty"`
+}
+
+// IdleStatus is the activity of a component with an idle policy
+type IdleStatus struct {
+    // Idle is set while the component is scaled to zero
+    Idle bool `json:"idle"`
+    // LastActivity is when the component was last seen working on a task
+    LastActivity metav1.Time  `json:"lastActivity"`
+    IdleSince    *metav1.Time `json:"idleSince,omitempty"`
+    // WakeTrigger is the last qraiop.io/wake value acted on
+    WakeTrigger string `json:"wakeTrigger,omitempty"`
+    // WokenBy says what last woke the component
+    WokenBy string `json:"wokenBy,omitempty"`
+}
+
+// Security incident types
+const (
+    IncidentKeyUsageAnomaly = "KeyUsageAnomaly"
+)
+
+// SecurityIncident is a suspected security problem, such as a key used far
+// more than usual, which may mean it was compromised
+type SecurityIncident struct {
+    Type      string `json:"type"`
+    KeyID     string `json:"keyID,omitempty"`
+    Operation string `json:"operation,omitempty"`
+    // RatePerMinute and BaselinePerMinute are the operation rates observed
+    // when the incident was raised and usually
+    RatePerMinute     int64       `json:"ratePerMinute,omitempty"`
+    BaselinePerMinute int64       `json:"baselinePerMinute,omitempty"`
+    Message           string      `json:"message"`
+    DetectedAt        metav1.Time `json:"detectedAt"`
+}
+
+// PlannedChanges is a plan of changes to child resources
+type PlannedChanges struct {
+    // Hash identifies the plan; approve it by setting the
+    // qraiop.io/approve-plan annotation to this value
+    Hash        string          `json:"hash"`
+    Changes     []PlannedChange `json:"changes,omitempty"`
+    GeneratedAt metav1.Time     `json:"generatedAt"`
+}
+
+// PlannedChange is a write the controller intends to make
+type PlannedChange struct {
+    // +kubebuilder:validation:Enum=Create;Update;Patch;Delete
+    Action    string `json:"action"`
+    Kind      string `json:"kind"`
+    Namespace string `json:"namespace,omitempty"`
+    Name      string `json:"name"`
+}
+
+// InventoryEntry identifies an object rendered for an instance
+type InventoryEntry struct {
+    Group     string `json:"group,omitempty"`
+    Version   string `json:"version"`
+    Kind      string `json:"kind"`
+    Namespace string `json:"namespace,omitempty"`
+    Name      string `json:"name"`
+    // Hash of the object as last written
+    Hash string `json:"hash"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,68 @@
+// src/controllers/api/v1/secretsource_types.go
+package v1
+
+import (
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretSource is a secret kept in an external store. The operator syncs
+// it into a Secret of the instance's namespace, so it doesn't have to be
+// created by hand.
+// +kubebuilder:validation:XValidation:rule="has(self.vault) != has(self.aws)",message="set exactly one of vault and aws"
+type SecretSource struct {
+    Vault *VaultSecretSource `json:"vault,omitempty"`
+    AWS   *AWSSecretSource   `json:"aws,omitempty"`
+
+    // RefreshInterval is how often the Secret is synced again, picking up
+    // rotated values. Defaults to 1h.
+    RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// VaultSecretSource reads a secret of a Vault KV version 2 engine. Every
+// key of the secret becomes a key of the synced Secret.
+type VaultSecretSource struct {
+    // URL is the base URL of Vault, e.g. https://vault.vault:8200
+    URL string `json:"url"`
+    // Namespace is the Vault Enterprise namespace, if any
+    Namespace string `json:"namespace,omitempty"`
+    // Mount is the path the KV engine is mounted at
+    // +kubebuilder:default=secret
+    Mount string `json:"mount,omitempty"`
+    // Path is the secret's path within the engine, e.g. qraiop/llm
+    Path string `json:"path"`
+    // TokenSecret holds a Vault token allowed to read the path
+    TokenSecret corev1.SecretKeySelector `json:"tokenSecret"`
+}
+
+// AWSSecretSource reads a secret of AWS Secrets Manager. A secret holding
+// a JSON object is synced key by key; any other value under Key.
+type AWSSecretSource struct {
+    // ARN of the secret
+    // +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:secretsmanager:[a-z0-9-]+:[0-9]{12}:secret:.+$`
+    ARN string `json:"arn"`
+    // Region overrides the region of the ARN, e.g. for a replica
+    Region string `json:"region,omitempty"`
+    // Key is the Secret key a secret that isn't a JSON object is synced to
+    // +kubebuilder:default=value
+    Key string `json:"key,omitempty"`
+    // CredentialsSecret holds the accessKeyID and secretAccessKey keys, and
+    // optionally sessionToken, of an identity allowed to read the secret.
+    // The operator's own identity is never used: anyone able to create an
+    // instance could otherwise copy any secret it may read.
+    CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret"`
+}
+
+// SecretSourceStatus is the last sync of a SecretSource
+type SecretSourceStatus struct {
+    // Secret is the Secret the source is synced to
+    Secret string `json:"secret"`
+    // Source is the Vault path or AWS ARN read
+    Source string `json:"source"`
+    // Keys are the keys synced
+    Keys []string `json:"keys,omitempty"`
+    // SyncedAt is when the Secret was last synced
+    SyncedAt *metav1.Time `json:"syncedAt,omitempty"`
+    // Message says why the last sync failed
+    Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,63 @@
+// src/controllers/api/v1/securitypolicy_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+)
+
+// QraiopSecurityPolicySpec holds the security policies of the Qraiop
+// instances in its namespace that reference it, so they can be owned and
+// reviewed apart from the instances
+type QraiopSecurityPolicySpec struct {
+    SecurityConfig `json:",inline"`
+}
+
+// QraiopSecurityPolicyStatus defines the observed state of QraiopSecurityPolicy
+type QraiopSecurityPolicyStatus struct {
+    ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+    // Instances are the Qraiop instances that reference the policy
+    Instances  []string           `json:"instances,omitempty"`
+    Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=qsp
+// +kubebuilder:printcolumn:name="Default Deny",type=boolean,JSONPath=`.spec.networkPolicies.defaultDenyAll`
+// +kubebuilder:printcolumn:name="Instances",type=string,JSONPath=`.status.instances`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type QraiopSecurityPolicy struct {
+    metav1.TypeMeta   `json:",inline"`
+    metav1.ObjectMeta `json:"metadata,omitempty"`
+
+    Spec   QraiopSecurityPolicySpec   `json:"spec,omitempty"`
+    Status QraiopSecurityPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type QraiopSecurityPolicyList struct {
+    metav1.TypeMeta `json:",inline"`
+    metav1.ListMeta `json:"metadata,omitempty"`
+    Items           []QraiopSecurityPolicy `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object for QraiopSecurityPolicy
+func (in *QraiopSecurityPolicy) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+// DeepCopyObject implements runtime.Object for QraiopSecurityPolicyList
+func (in *QraiopSecurityPolicyList) DeepCopyObject() runtime.Object {
+    if c := in.DeepCopy(); c != nil {
+        return c
+    }
+    return nil
+}
+
+func init() {
+    SchemeBuilder.Register(&QraiopSecurityPolicy{}, &QraiopSecurityPolicyList{})
+}
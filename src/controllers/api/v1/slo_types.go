@@ -0,0 +1,77 @@
+// src/controllers/api/v1/slo_types.go
+package v1
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SLO types
+const (
+    SLOAvailability = "availability"
+    SLOLatency      = "latency"
+)
+
+// SLOBurnRateAlert is the alert raised when an SLO burns its error budget
+// too fast. Page-level burns are critical, slower ones warnings.
+const SLOBurnRateAlert = "QraiopSLOBurnRate"
+
+// ServiceLevelObjective is an availability or latency objective on the
+// request metrics of a service
+// +kubebuilder:validation:XValidation:rule="self.type != 'latency' || has(self.threshold)",message="latency objectives need a threshold"
+type ServiceLevelObjective struct {
+    Name string `json:"name"`
+
+    // Type availability counts requests matching ErrorSelector as bad;
+    // latency counts requests slower than Threshold as bad
+    // +kubebuilder:validation:Enum=availability;latency
+    Type string `json:"type"`
+
+    // Objective is the percentage of good requests, e.g. "99.9"
+    // +kubebuilder:validation:Pattern=`^(100(\.0+)?|[0-9]{1,2}(\.[0-9]+)?)$`
+    Objective string `json:"objective"`
+
+    // Metric is the request counter for availability, e.g.
+    // http_requests_total, or the histogram for latency, e.g.
+    // http_request_duration_seconds
+    Metric string `json:"metric"`
+
+    // ErrorSelector picks the failed requests of an availability objective
+    // +kubebuilder:default=`code=~"5.."`
+    ErrorSelector string `json:"errorSelector,omitempty"`
+
+    // Threshold of a latency objective. It must be a bucket boundary of
+    // the histogram.
+    Threshold *metav1.Duration `json:"threshold,omitempty"`
+
+    // Period the error budget covers. Burn rates are alerted on over
+    // windows of 1h to 3d, scaled to it; those not shorter than the period
+    // are left out.
+    // +kubebuilder:default="720h"
+    Period metav1.Duration `json:"period,omitempty"`
+}
+
+// ComponentSLO is an objective on the requests a component serves
+type ComponentSLO struct {
+    // Component is the component name, or cryptography/<pool> for a crypto pool
+    Component string `json:"component"`
+
+    ServiceLevelObjective `json:",inline"`
+}
+
+// SLOStatus is the state of an SLO's error budget, as last queried
+type SLOStatus struct {
+    Name      string `json:"name"`
+    Objective string `json:"objective"`
+    // ErrorBudgetRemaining is the percentage of the period's error budget
+    // left; negative once it is overspent
+    ErrorBudgetRemaining string `json:"errorBudgetRemaining,omitempty"`
+    // BurnRate is how fast the budget burned over the last hour; 1 spends
+    // exactly the budget over the period
+    BurnRate string `json:"burnRate,omitempty"`
+    // Exhausted is set once the budget is spent. Chaos experiments
+    // targeting the SLO's namespace are refused meanwhile.
+    Exhausted bool `json:"exhausted,omitempty"`
+    // Message explains missing values, e.g. no traffic in the period
+    Message       string       `json:"message,omitempty"`
+    LastEvaluated *metav1.Time `json:"lastEvaluated,omitempty"`
+}
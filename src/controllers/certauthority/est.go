@@ -0,0 +1,177 @@
+// src/controllers/certauthority/est.go
+package certauthority
+
+import (
+    "bytes"
+    "context"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/asn1"
+    "encoding/base64"
+    "encoding/pem"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// EST enrolls with a CA over EST (RFC 7030). Corporate CAs don't offer
+// the PQC algorithms of the crypto services, so the key pair is ECDSA P-256.
+type EST struct {
+    URL string
+    // Label selects one of the server's CAs
+    Label    string
+    Username string
+    Password string
+    HTTP     *http.Client
+}
+
+// IssueCertificate enrolls a new key pair with the CA. EST leaves the
+// lifetime to the CA, so ttl is ignored.
+func (e *EST) IssueCertificate(ctx context.Context, req cryptoclient.CertificateRequest, _ time.Duration) (cryptoclient.Certificate, error) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    tmpl := &x509.CertificateRequest{
+        Subject:  pkix.Name{CommonName: req.CommonName},
+        DNSNames: req.DNSNames,
+    }
+    for _, ip := range req.IPAddresses {
+        if parsed := net.ParseIP(ip); parsed != nil {
+            tmpl.IPAddresses = append(tmpl.IPAddresses, parsed)
+        }
+    }
+    csr, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    body := base64.StdEncoding.EncodeToString(csr)
+    certs, err := e.certs(ctx, http.MethodPost, "simpleenroll", "application/pkcs10", []byte(body))
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    var leaf *x509.Certificate
+    for _, c := range certs {
+        if pub, ok := c.PublicKey.(*ecdsa.PublicKey); ok && pub.Equal(&key.PublicKey) {
+            leaf = c
+        }
+    }
+    if leaf == nil {
+        return cryptoclient.Certificate{}, fmt.Errorf("est server returned no certificate for the enrolled key")
+    }
+    ca, err := e.CACertificates(ctx)
+    if err != nil {
+        return cryptoclient.Certificate{}, fmt.Errorf("reading CA certificates: %w", err)
+    }
+    der, err := x509.MarshalPKCS8PrivateKey(key)
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    return cryptoclient.Certificate{
+        Certificate:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})),
+        PrivateKey:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+        CACertificate: ca,
+    }, nil
+}
+
+// CACertificates returns the PEM certificates of the CA
+func (e *EST) CACertificates(ctx context.Context) (string, error) {
+    certs, err := e.certs(ctx, http.MethodGet, "cacerts", "", nil)
+    if err != nil {
+        return "", err
+    }
+    var out bytes.Buffer
+    for _, c := range certs {
+        _ = pem.Encode(&out, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+    }
+    return out.String(), nil
+}
+
+// certs calls an EST operation answering with a certs-only PKCS#7 message
+func (e *EST) certs(ctx context.Context, method, op, contentType string, body []byte) ([]*x509.Certificate, error) {
+    path := "/.well-known/est/"
+    if e.Label != "" {
+        path += e.Label + "/"
+    }
+    var reader io.Reader
+    if body != nil {
+        reader = bytes.NewReader(body)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(e.URL, "/")+path+op, reader)
+    if err != nil {
+        return nil, err
+    }
+    if contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+        req.Header.Set("Content-Transfer-Encoding", "base64")
+    }
+    if e.Username != "" {
+        req.SetBasicAuth(e.Username, e.Password)
+    }
+    resp, err := e.HTTP.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return nil, err
+    }
+    // 202 means the request waits for manual approval on the CA
+    if resp.StatusCode != http.StatusOK {
+        return nil, &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(data)), Server: "est"}
+    }
+    der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+    if err != nil {
+        return nil, fmt.Errorf("decoding %s response: %w", op, err)
+    }
+    return parsePKCS7Certs(der)
+}
+
+// The parts of a PKCS#7 SignedData message (RFC 2315) holding certificates
+type contentInfo struct {
+    ContentType asn1.ObjectIdentifier
+    Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+    Version          int
+    DigestAlgorithms asn1.RawValue
+    ContentInfo      asn1.RawValue
+    Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+    CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+    SignerInfos      asn1.RawValue
+}
+
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// parsePKCS7Certs returns the certificates of a certs-only PKCS#7 message
+func parsePKCS7Certs(der []byte) ([]*x509.Certificate, error) {
+    var ci contentInfo
+    if _, err := asn1.Unmarshal(der, &ci); err != nil {
+        return nil, fmt.Errorf("parsing PKCS#7: %w", err)
+    }
+    if !ci.ContentType.Equal(oidSignedData) {
+        return nil, fmt.Errorf("PKCS#7 content is %s, not signed data", ci.ContentType)
+    }
+    var sd signedData
+    if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+        return nil, fmt.Errorf("parsing PKCS#7 signed data: %w", err)
+    }
+    certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+    if err != nil {
+        return nil, err
+    }
+    if len(certs) == 0 {
+        return nil, fmt.Errorf("PKCS#7 message holds no certificates")
+    }
+    return certs, nil
+}
@@ -0,0 +1,176 @@
+// src/controllers/certauthority/hybrid.go
+package certauthority
+
+import (
+    "bytes"
+    "context"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/asn1"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// OIDAltPublicKeyInfo is the subjectAltPublicKeyInfo extension (X.509
+// 9.8), holding the PQC public key of a hybrid certificate
+var OIDAltPublicKeyInfo = asn1.ObjectIdentifier{2, 5, 29, 72}
+
+// ClassicalSigner signs the classical half of a hybrid certificate. It
+// returns the PEM certificate and the PEM chain of its CA.
+type ClassicalSigner interface {
+    SignClassical(ctx context.Context, tmpl *x509.Certificate, key crypto.Signer) (string, string, error)
+}
+
+// Hybrid issues hybrid certificates: the PQC half from a crypto service,
+// the classical half, bound to the PQC public key, from Classical
+type Hybrid struct {
+    PQC       Issuer
+    Classical ClassicalSigner
+}
+
+// IssueCertificate issues the PQC certificate, then has a classical one
+// signed for the same names and validity carrying its public key. The
+// classical key pair is ECDSA P-256, which every TLS client speaks.
+func (h *Hybrid) IssueCertificate(ctx context.Context, req cryptoclient.CertificateRequest, ttl time.Duration) (cryptoclient.Certificate, error) {
+    pqc, err := h.PQC.IssueCertificate(ctx, req, ttl)
+    if err != nil {
+        return cryptoclient.Certificate{}, fmt.Errorf("issuing PQC certificate: %w", err)
+    }
+    block, _ := pem.Decode([]byte(pqc.Certificate))
+    if block == nil {
+        return cryptoclient.Certificate{}, errors.New("crypto service returned no PEM certificate")
+    }
+    pqcCert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return cryptoclient.Certificate{}, fmt.Errorf("parsing PQC certificate: %w", err)
+    }
+
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    tmpl := &x509.Certificate{
+        Subject:     pkix.Name{CommonName: req.CommonName},
+        DNSNames:    req.DNSNames,
+        NotBefore:   pqcCert.NotBefore,
+        NotAfter:    pqcCert.NotAfter,
+        KeyUsage:    x509.KeyUsageDigitalSignature,
+        ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+        ExtraExtensions: []pkix.Extension{{
+            Id:    OIDAltPublicKeyInfo,
+            Value: pqcCert.RawSubjectPublicKeyInfo,
+        }},
+    }
+    for _, ip := range req.IPAddresses {
+        if parsed := net.ParseIP(ip); parsed != nil {
+            tmpl.IPAddresses = append(tmpl.IPAddresses, parsed)
+        }
+    }
+    cert, ca, err := h.Classical.SignClassical(ctx, tmpl, key)
+    if err != nil {
+        return cryptoclient.Certificate{}, fmt.Errorf("signing classical certificate: %w", err)
+    }
+    der, err := x509.MarshalPKCS8PrivateKey(key)
+    if err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    return cryptoclient.Certificate{
+        Certificate:   cert,
+        PrivateKey:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+        CACertificate: ca,
+        PQC:           &pqc,
+    }, nil
+}
+
+// CACertificates returns the CA chain of the classical signer, to check
+// Vault is reachable and the signer usable
+func (h *Hybrid) CACertificates(ctx context.Context) (string, error) {
+    checker, ok := h.Classical.(interface {
+        CACertificates(ctx context.Context) (string, error)
+    })
+    if !ok {
+        return "", nil
+    }
+    return checker.CACertificates(ctx)
+}
+
+// SignClassical signs tmpl with the role through sign-verbatim: unlike
+// sign, it copies the extensions of the CSR, the PQC public key among
+// them. The role's TTL caps the lifetime.
+func (v *Vault) SignClassical(ctx context.Context, tmpl *x509.Certificate, key crypto.Signer) (string, string, error) {
+    csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+        Subject:         tmpl.Subject,
+        DNSNames:        tmpl.DNSNames,
+        IPAddresses:     tmpl.IPAddresses,
+        ExtraExtensions: tmpl.ExtraExtensions,
+    }, key)
+    if err != nil {
+        return "", "", err
+    }
+    body := map[string]interface{}{
+        "csr":           string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+        "ttl":           fmt.Sprintf("%ds", int64(time.Until(tmpl.NotAfter).Seconds())),
+        "key_usage":     []string{"DigitalSignature"},
+        "ext_key_usage": []string{"ServerAuth", "ClientAuth"},
+    }
+    var resp struct {
+        Data struct {
+            Certificate string   `json:"certificate"`
+            IssuingCA   string   `json:"issuing_ca"`
+            CAChain     []string `json:"ca_chain"`
+        } `json:"data"`
+    }
+    path := "/v1/" + strings.Trim(v.Mount, "/") + "/sign-verbatim/" + url.PathEscape(v.Role)
+    if err := v.do(ctx, http.MethodPost, path, body, &resp); err != nil {
+        return "", "", err
+    }
+    if err := checkAltPublicKey(resp.Data.Certificate, altPublicKey(tmpl)); err != nil {
+        return "", "", err
+    }
+    ca := resp.Data.IssuingCA
+    if len(resp.Data.CAChain) > 0 {
+        ca = strings.Join(resp.Data.CAChain, "\n")
+    }
+    return resp.Data.Certificate, ca, nil
+}
+
+// altPublicKey returns the PQC public key a template carries
+func altPublicKey(tmpl *x509.Certificate) []byte {
+    for _, ext := range tmpl.ExtraExtensions {
+        if ext.Id.Equal(OIDAltPublicKeyInfo) {
+            return ext.Value
+        }
+    }
+    return nil
+}
+
+// checkAltPublicKey makes sure a signed certificate still carries the PQC
+// public key want, which a CA dropping unknown extensions would lose
+func checkAltPublicKey(certPEM string, want []byte) error {
+    block, _ := pem.Decode([]byte(certPEM))
+    if block == nil {
+        return errors.New("no PEM certificate returned")
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return err
+    }
+    for _, ext := range cert.Extensions {
+        if ext.Id.Equal(OIDAltPublicKeyInfo) && bytes.Equal(ext.Value, want) {
+            return nil
+        }
+    }
+    return errors.New("signed certificate lacks the PQC public key extension")
+}
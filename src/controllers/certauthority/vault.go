@@ -0,0 +1,147 @@
+// src/controllers/certauthority/vault.go
+
+// Package certauthority issues certificates from CAs outside the crypto
+// services: Vault PKI and CAs enrolling over EST, and hybrid certificates
+// whose classical half is signed in Vault.
+package certauthority
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// Issuer issues certificates; the crypto service client is one
+type Issuer interface {
+    IssueCertificate(ctx context.Context, req cryptoclient.CertificateRequest, ttl time.Duration) (cryptoclient.Certificate, error)
+}
+
+// StatusError is a non-2xx response of a CA
+type StatusError struct {
+    Code    int
+    Message string
+    Server  string
+}
+
+func (e *StatusError) Error() string {
+    return fmt.Sprintf("%s returned %d: %s", e.Server, e.Code, e.Message)
+}
+
+// Vault issues from a role of a Vault PKI secrets engine
+type Vault struct {
+    URL       string
+    Namespace string
+    Mount     string
+    Role      string
+    Token     string
+    HTTP      *http.Client
+}
+
+// IssueCertificate issues a certificate from the role, valid for ttl or
+// for the role's default TTL when ttl is 0. The key pair is generated by
+// Vault.
+func (v *Vault) IssueCertificate(ctx context.Context, req cryptoclient.CertificateRequest, ttl time.Duration) (cryptoclient.Certificate, error) {
+    body := map[string]string{
+        "common_name": req.CommonName,
+        "alt_names":   strings.Join(req.DNSNames, ","),
+        "ip_sans":     strings.Join(req.IPAddresses, ","),
+    }
+    if ttl > 0 {
+        body["ttl"] = fmt.Sprintf("%ds", int64(ttl.Seconds()))
+    }
+    var resp struct {
+        Data struct {
+            Certificate string   `json:"certificate"`
+            PrivateKey  string   `json:"private_key"`
+            IssuingCA   string   `json:"issuing_ca"`
+            CAChain     []string `json:"ca_chain"`
+        } `json:"data"`
+    }
+    path := "/v1/" + strings.Trim(v.Mount, "/") + "/issue/" + url.PathEscape(v.Role)
+    if err := v.do(ctx, http.MethodPost, path, body, &resp); err != nil {
+        return cryptoclient.Certificate{}, err
+    }
+    ca := resp.Data.IssuingCA
+    if len(resp.Data.CAChain) > 0 {
+        ca = strings.Join(resp.Data.CAChain, "\n")
+    }
+    return cryptoclient.Certificate{
+        Certificate:   resp.Data.Certificate,
+        PrivateKey:    resp.Data.PrivateKey,
+        CACertificate: ca,
+    }, nil
+}
+
+// CACertificates returns the PEM CA chain of the engine, which needs no
+// token, to check the engine is reachable and unsealed
+func (v *Vault) CACertificates(ctx context.Context) (string, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url("/v1/"+strings.Trim(v.Mount, "/")+"/ca_chain"), nil)
+    if err != nil {
+        return "", err
+    }
+    v.header(req)
+    resp, err := v.HTTP.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+    data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if resp.StatusCode != http.StatusOK {
+        return "", &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(data)), Server: "vault"}
+    }
+    return string(data), nil
+}
+
+func (v *Vault) url(path string) string {
+    return strings.TrimSuffix(v.URL, "/") + path
+}
+
+func (v *Vault) header(req *http.Request) {
+    if v.Namespace != "" {
+        req.Header.Set("X-Vault-Namespace", v.Namespace)
+    }
+}
+
+func (v *Vault) do(ctx context.Context, method, path string, in, out interface{}) error {
+    var body io.Reader
+    if in != nil {
+        data, err := json.Marshal(in)
+        if err != nil {
+            return err
+        }
+        body = bytes.NewReader(data)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, v.url(path), body)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("X-Vault-Token", v.Token)
+    v.header(req)
+    resp, err := v.HTTP.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        var e struct {
+            Errors []string `json:"errors"`
+        }
+        data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        msg := strings.TrimSpace(string(data))
+        if json.Unmarshal(data, &e) == nil && len(e.Errors) > 0 {
+            msg = strings.Join(e.Errors, "; ")
+        }
+        return &StatusError{Code: resp.StatusCode, Message: msg, Server: "vault"}
+    }
+    return json.NewDecoder(resp.Body).Decode(out)
+}
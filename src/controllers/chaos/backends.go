@@ -0,0 +1,87 @@
+// src/controllers/chaos/backends.go
+package chaos
+
+import (
+    "context"
+    "fmt"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Observer is implemented by faults an external chaos tool runs, whose
+// outcome the tool reports in its own resources
+type Observer interface {
+    // Observe returns what the tool reports about the experiment's fault
+    Observe(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) (*qraiopv1.BackendStatus, error)
+}
+
+// backends are the faults of the external backends by experiment type
+var backends = map[string]map[string]Fault{
+    qraiopv1.BackendChaosMesh: {
+        "pod_kill":          chaosMeshFault{kind: "PodChaos", spec: map[string]interface{}{"action": "pod-kill"}},
+        "network_partition": chaosMeshFault{kind: "NetworkChaos", spec: map[string]interface{}{"action": "partition", "direction": "both"}},
+    },
+    qraiopv1.BackendLitmus: {
+        "pod_kill":          litmusFault{experiment: "pod-delete"},
+        "network_partition": litmusFault{experiment: "pod-network-partition"},
+    },
+}
+
+// ForExperiment returns the fault implementing an experiment's type on its
+// backend
+func ForExperiment(exp *qraiopv1.ChaosExperiment) (Fault, error) {
+    backend := exp.Spec.Backend
+    if backend == "" || backend == qraiopv1.BackendNative {
+        return ForType(exp.Spec.Type)
+    }
+    byType, ok := backends[backend]
+    if !ok {
+        return nil, fmt.Errorf("unknown chaos backend %q", backend)
+    }
+    f, ok := byType[exp.Spec.Type]
+    if !ok {
+        return nil, fmt.Errorf("the %s backend does not run %s", backend, exp.Spec.Type)
+    }
+    return f, nil
+}
+
+// backendObjectName names the resources created for an experiment in an
+// external tool
+func backendObjectName(exp *qraiopv1.ChaosExperiment) string {
+    return "qraiop-" + exp.Name
+}
+
+// durationSeconds is how long an external tool holds the fault
+func durationSeconds(exp *qraiopv1.ChaosExperiment) int {
+    if exp.Spec.Duration <= 0 {
+        return 60
+    }
+    return exp.Spec.Duration
+}
+
+// requireKind fails when the cluster doesn't serve an external tool's kind
+func requireKind(c client.Client, gvk schema.GroupVersionKind, tool string) error {
+    if _, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+        return fmt.Errorf("%s is not served by the cluster; install %s: %w", gvk.Kind, tool, err)
+    }
+    return nil
+}
+
+// deleteBackendObject deletes an external tool's resource, which reverts
+// the fault it runs
+func deleteBackendObject(ctx context.Context, c client.Client, gvk schema.GroupVersionKind, namespace, name string) error {
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(gvk)
+    obj.SetNamespace(namespace)
+    obj.SetName(name)
+    if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+        return err
+    }
+    return nil
+}
@@ -0,0 +1,115 @@
+// src/controllers/chaos/chaosmesh.go
+package chaos
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// chaosMeshGroupVersion is the API of the Chaos Mesh kinds
+var chaosMeshGroupVersion = schema.GroupVersion{Group: "chaos-mesh.org", Version: "v1alpha1"}
+
+// chaosMeshFault runs a fault as a Chaos Mesh resource of kind with spec,
+// selecting exactly the drawn targets. Chaos Mesh reverts it after the
+// experiment's duration; deleting the resource reverts it earlier. Its
+// partition has no target side, so it cuts the targets' outgoing traffic.
+type chaosMeshFault struct {
+    kind string
+    spec map[string]interface{}
+}
+
+func (f chaosMeshFault) gvk() schema.GroupVersionKind {
+    return chaosMeshGroupVersion.WithKind(f.kind)
+}
+
+func (f chaosMeshFault) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    if err := requireKind(c, f.gvk(), "Chaos Mesh"); err != nil {
+        return err
+    }
+    names := make([]interface{}, 0, len(targets))
+    for _, p := range targets {
+        names = append(names, p.Name)
+    }
+    spec := map[string]interface{}{
+        "mode": "all",
+        "selector": map[string]interface{}{
+            "pods": map[string]interface{}{exp.Spec.Target.Namespace: names},
+        },
+        "duration": fmt.Sprintf("%ds", durationSeconds(exp)),
+    }
+    for k, v := range f.spec {
+        spec[k] = v
+    }
+    obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+    obj.SetGroupVersionKind(f.gvk())
+    obj.SetName(backendObjectName(exp))
+    obj.SetNamespace(exp.Spec.Target.Namespace)
+    obj.SetLabels(map[string]string{TargetLabel: exp.Name})
+    if err := c.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+    return nil
+}
+
+func (f chaosMeshFault) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    return deleteBackendObject(ctx, c, f.gvk(), exp.Spec.Target.Namespace, backendObjectName(exp))
+}
+
+// Observe maps the conditions and injection records of the Chaos Mesh
+// resource. Chaos Mesh judges no steady state, so only a failed
+// injection or recovery decides the verdict.
+func (f chaosMeshFault) Observe(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) (*qraiopv1.BackendStatus, error) {
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(f.gvk())
+    if err := c.Get(ctx, client.ObjectKey{Namespace: exp.Spec.Target.Namespace, Name: backendObjectName(exp)}, obj); err != nil {
+        return nil, err
+    }
+    st := &qraiopv1.BackendStatus{Kind: f.kind, Name: obj.GetName()}
+
+    conditions := map[string]string{}
+    list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+    for _, item := range list {
+        cond, _ := item.(map[string]interface{})
+        t, _ := cond["type"].(string)
+        s, _ := cond["status"].(string)
+        conditions[t] = s
+    }
+    var failures []string
+    records, _, _ := unstructured.NestedSlice(obj.Object, "status", "experiment", "containerRecords")
+    for _, item := range records {
+        record, _ := item.(map[string]interface{})
+        events, _, _ := unstructured.NestedSlice(record, "events")
+        for _, e := range events {
+            event, _ := e.(map[string]interface{})
+            if event["type"] == "Failed" {
+                msg, _ := event["message"].(string)
+                failures = append(failures, fmt.Sprintf("%v: %s", record["id"], msg))
+            }
+        }
+    }
+    if len(failures) > 0 {
+        st.Verdict, st.Message = qraiopv1.VerdictFailed, "Chaos Mesh failed on "+strings.Join(failures, "; ")
+        return st, nil
+    }
+
+    // A killed pod has nothing to recover
+    st.Finished = conditions["AllRecovered"] == "True" || (f.spec["action"] == "pod-kill" && conditions["AllInjected"] == "True")
+    switch {
+    case st.Finished:
+        st.Message = "fault injected and recovered by Chaos Mesh"
+    case conditions["AllInjected"] == "True":
+        st.Message = "fault injected by Chaos Mesh"
+    default:
+        st.Message = "waiting for Chaos Mesh to inject the fault"
+    }
+    return st, nil
+}
@@ -0,0 +1,159 @@
+// src/controllers/chaos/fault.go
+package chaos
+
+import (
+    "context"
+    "fmt"
+    "math/rand"
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// TargetLabel is set on pods a fault is holding, with the experiment name as value
+const TargetLabel = "qraiop.io/chaos-experiment"
+
+// Fault injects and reverts one kind of failure
+type Fault interface {
+    // Inject applies the fault to the resolved target pods
+    Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error
+    // Cleanup reverts everything Inject did. It must be idempotent since it
+    // runs on completion, abort and deletion of the experiment.
+    Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error
+}
+
+var faults = map[string]Fault{
+    "pod_kill":             podKill{},
+    "network_partition":    networkPartition{},
+    "volume_detach":        volumeDetach{},
+    "volume_fill":          volumeFill{},
+    "io_latency":           ioLatency{},
+    "http_delay":           httpFault{},
+    "http_abort":           httpFault{abort: true},
+    "node_memory_pressure": nodePressure{resource: "memory"},
+    "node_cpu_pressure":    nodePressure{resource: "cpu"},
+    // A blackout isolates the monitoring pods like a partition; what sets
+    // it apart is how the experiment judges it
+    "monitoring_blackout": networkPartition{},
+}
+
+// Override replaces the fault implementing type t, e.g. with a no-op in
+// unit tests, and returns a func restoring the previous one. It is not safe
+// for concurrent use with running experiments.
+func Override(t string, f Fault) (restore func()) {
+    prev, had := faults[t]
+    faults[t] = f
+    return func() {
+        if had {
+            faults[t] = prev
+        } else {
+            delete(faults, t)
+        }
+    }
+}
+
+// Disrupts reports whether a fault type removes its target pods, so they
+// count against their PodDisruptionBudgets
+func Disrupts(t string) bool {
+    return t == "pod_kill" || t == "volume_detach"
+}
+
+// ForType returns the fault implementing an experiment type
+func ForType(t string) (Fault, error) {
+    f, ok := faults[t]
+    if !ok {
+        return nil, fmt.Errorf("unsupported experiment type %q", t)
+    }
+    return f, nil
+}
+
+// MatchingPods returns the running pods selected by the experiment target,
+// sorted by name
+func MatchingPods(ctx context.Context, c client.Reader, target qraiopv1.ExperimentTarget) ([]corev1.Pod, error) {
+    var pods corev1.PodList
+    if err := c.List(ctx, &pods,
+        client.InNamespace(target.Namespace),
+        client.MatchingLabels(target.Selector),
+    ); err != nil {
+        return nil, err
+    }
+
+    running := make([]corev1.Pod, 0, len(pods.Items))
+    for _, p := range pods.Items {
+        if p.Status.Phase == corev1.PodRunning && p.DeletionTimestamp == nil {
+            running = append(running, p)
+        }
+    }
+    sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+    return running, nil
+}
+
+// SelectTargets draws the experiment's percentage of pods with seed,
+// rounding up so a non-empty selection always targets at least one pod.
+// The pods are expected sorted by name, as MatchingPods returns them.
+func SelectTargets(pods []corev1.Pod, percentage int, seed int64) []corev1.Pod {
+    if percentage <= 0 || percentage > 100 {
+        percentage = 100
+    }
+    n := (len(pods)*percentage + 99) / 100
+    drawn := append([]corev1.Pod(nil), pods...)
+    rand.New(rand.NewSource(seed)).Shuffle(len(drawn), func(i, j int) {
+        drawn[i], drawn[j] = drawn[j], drawn[i]
+    })
+    return drawn[:n]
+}
+
+// Seed returns the seed to draw the targets of exp with: the one of its
+// spec, else the one it was resolved with, else a new one
+func Seed(exp *qraiopv1.ChaosExperiment) int64 {
+    switch {
+    case exp.Spec.Seed != nil:
+        return *exp.Spec.Seed
+    case exp.Status.Resolution != nil:
+        return exp.Status.Resolution.Seed
+    }
+    return rand.Int63()
+}
+
+// ResolveTargets returns the running pods the experiment's target matches
+// and the ones of them it would inject into when drawn with seed
+func ResolveTargets(ctx context.Context, c client.Reader, exp *qraiopv1.ChaosExperiment, seed int64) (pods, targets []corev1.Pod, err error) {
+    pods, err = MatchingPods(ctx, c, exp.Spec.Target)
+    if err != nil {
+        return nil, nil, err
+    }
+    return pods, SelectTargets(pods, exp.Spec.Percentage, seed), nil
+}
+
+// Resolution describes the targets drawn with seed out of the matching pods
+func Resolution(pods, targets []corev1.Pod, seed int64, now metav1.Time) *qraiopv1.TargetResolution {
+    res := &qraiopv1.TargetResolution{ResolvedAt: now, Seed: seed, Matched: len(pods), Nodes: TargetNodes(targets)}
+    for _, p := range targets {
+        res.Pods = append(res.Pods, qraiopv1.ResolvedPod{Name: p.Name, Node: p.Spec.NodeName, Ready: podReady(p)})
+    }
+    return res
+}
+
+// ReadyCount returns how many pods have a true Ready condition
+func ReadyCount(pods []corev1.Pod) int {
+    ready := 0
+    for _, p := range pods {
+        if podReady(p) {
+            ready++
+        }
+    }
+    return ready
+}
+
+func podReady(p corev1.Pod) bool {
+    for _, cond := range p.Status.Conditions {
+        if cond.Type == corev1.PodReady {
+            return cond.Status == corev1.ConditionTrue
+        }
+    }
+    return false
+}
@@ -0,0 +1,114 @@
+// src/controllers/chaos/faults.go
+package chaos
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    policyv1 "k8s.io/api/policy/v1"
+    policyv1beta1 "k8s.io/api/policy/v1beta1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// podKill evicts the target pods, or deletes them when the experiment
+// ignores disruption budgets, and relies on their controllers to recreate
+// them, so there is nothing to revert
+type podKill struct{}
+
+func (podKill) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    for i := range targets {
+        pod := &targets[i]
+        var err error
+        if exp.Spec.IgnorePDB {
+            err = c.Delete(ctx, pod)
+        } else {
+            err = c.SubResource("eviction").Create(ctx, pod, eviction(pod))
+        }
+        if client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// eviction is the Eviction of pod in the version the cluster accepts
+func eviction(pod *corev1.Pod) client.Object {
+    meta := metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}
+    if cluster.EvictionGroupVersion() == "policy/v1beta1" {
+        return &policyv1beta1.Eviction{ObjectMeta: meta}
+    }
+    return &policyv1.Eviction{ObjectMeta: meta}
+}
+
+func (podKill) Cleanup(context.Context, client.Client, *qraiopv1.ChaosExperiment) error {
+    return nil
+}
+
+// networkPartition isolates the target pods with a deny-all NetworkPolicy
+// selecting a label added to each target
+type networkPartition struct{}
+
+func partitionPolicyName(exp *qraiopv1.ChaosExperiment) string {
+    return "qraiop-chaos-" + exp.Name
+}
+
+func (networkPartition) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    for i := range targets {
+        pod := &targets[i]
+        patch := client.MergeFrom(pod.DeepCopy())
+        if pod.Labels == nil {
+            pod.Labels = map[string]string{}
+        }
+        pod.Labels[TargetLabel] = exp.Name
+        if err := c.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+
+    policy := &networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      partitionPolicyName(exp),
+            Namespace: exp.Spec.Target.Namespace,
+            Labels:    map[string]string{TargetLabel: exp.Name},
+        },
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{TargetLabel: exp.Name}},
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+        },
+    }
+    if err := c.Create(ctx, policy); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+    return nil
+}
+
+func (networkPartition) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    policy := &networkingv1.NetworkPolicy{}
+    policy.Name = partitionPolicyName(exp)
+    policy.Namespace = exp.Spec.Target.Namespace
+    if err := c.Delete(ctx, policy); client.IgnoreNotFound(err) != nil {
+        return err
+    }
+
+    var pods corev1.PodList
+    if err := c.List(ctx, &pods,
+        client.InNamespace(exp.Spec.Target.Namespace),
+        client.MatchingLabels{TargetLabel: exp.Name},
+    ); err != nil {
+        return err
+    }
+    for i := range pods.Items {
+        pod := &pods.Items[i]
+        patch := client.MergeFrom(pod.DeepCopy())
+        delete(pod.Labels, TargetLabel)
+        if err := c.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
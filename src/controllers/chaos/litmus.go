@@ -0,0 +1,129 @@
+// src/controllers/chaos/litmus.go
+package chaos
+
+import (
+    "context"
+    "sort"
+    "strconv"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+var (
+    // ChaosEngineGVK runs Litmus experiments
+    ChaosEngineGVK = schema.GroupVersionKind{Group: "litmuschaos.io", Version: "v1alpha1", Kind: "ChaosEngine"}
+    // ChaosResultGVK holds the verdict of a Litmus experiment
+    ChaosResultGVK = schema.GroupVersionKind{Group: "litmuschaos.io", Version: "v1alpha1", Kind: "ChaosResult"}
+)
+
+const defaultLitmusServiceAccount = "litmus-admin"
+
+// litmusFault runs a Litmus experiment through a ChaosEngine in the target
+// namespace, which needs the experiment installed from the ChaosHub. The
+// experiment is pinned to the drawn targets and its probes' verdict is
+// mapped into the QRAIOP verdict.
+type litmusFault struct {
+    experiment string
+}
+
+func (f litmusFault) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    if err := requireKind(c, ChaosEngineGVK, "Litmus"); err != nil {
+        return err
+    }
+    names := make([]string, 0, len(targets))
+    for _, p := range targets {
+        names = append(names, p.Name)
+    }
+    account := defaultLitmusServiceAccount
+    if cfg := exp.Spec.Litmus; cfg != nil && cfg.ServiceAccount != "" {
+        account = cfg.ServiceAccount
+    }
+    engine := &unstructured.Unstructured{Object: map[string]interface{}{
+        "spec": map[string]interface{}{
+            "engineState":         "active",
+            "chaosServiceAccount": account,
+            "jobCleanUpPolicy":    "delete",
+            "appinfo": map[string]interface{}{
+                "appns":    exp.Spec.Target.Namespace,
+                "applabel": selectorString(exp.Spec.Target.Selector),
+            },
+            "experiments": []interface{}{map[string]interface{}{
+                "name": f.experiment,
+                "spec": map[string]interface{}{
+                    "components": map[string]interface{}{
+                        "env": []interface{}{
+                            map[string]interface{}{"name": "TOTAL_CHAOS_DURATION", "value": strconv.Itoa(durationSeconds(exp))},
+                            map[string]interface{}{"name": "TARGET_PODS", "value": strings.Join(names, ",")},
+                            map[string]interface{}{"name": "PODS_AFFECTED_PERC", "value": "100"},
+                        },
+                    },
+                },
+            }},
+        },
+    }}
+    engine.SetGroupVersionKind(ChaosEngineGVK)
+    engine.SetName(backendObjectName(exp))
+    engine.SetNamespace(exp.Spec.Target.Namespace)
+    engine.SetLabels(map[string]string{TargetLabel: exp.Name})
+    if err := c.Create(ctx, engine); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+    return nil
+}
+
+// Cleanup deletes the ChaosEngine, which stops the experiment. The
+// ChaosResult is left for the record, as Litmus itself does.
+func (f litmusFault) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    return deleteBackendObject(ctx, c, ChaosEngineGVK, exp.Spec.Target.Namespace, backendObjectName(exp))
+}
+
+// Observe maps the ChaosResult Litmus names <engine>-<experiment>
+func (f litmusFault) Observe(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) (*qraiopv1.BackendStatus, error) {
+    name := backendObjectName(exp) + "-" + f.experiment
+    st := &qraiopv1.BackendStatus{Kind: ChaosResultGVK.Kind, Name: name}
+    result := &unstructured.Unstructured{}
+    result.SetGroupVersionKind(ChaosResultGVK)
+    err := c.Get(ctx, client.ObjectKey{Namespace: exp.Spec.Target.Namespace, Name: name}, result)
+    if apierrors.IsNotFound(err) {
+        st.Message = "waiting for Litmus to start the experiment"
+        return st, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    phase, _, _ := unstructured.NestedString(result.Object, "status", "experimentStatus", "phase")
+    verdict, _, _ := unstructured.NestedString(result.Object, "status", "experimentStatus", "verdict")
+    failStep, _, _ := unstructured.NestedString(result.Object, "status", "experimentStatus", "failStep")
+    st.Finished = phase != "" && phase != "Running" && phase != "Initialized"
+    st.Message = "Litmus " + f.experiment + " " + strings.ToLower(phase)
+    switch verdict {
+    case "Pass":
+        st.Verdict = qraiopv1.VerdictPassed
+        st.Message = "Litmus " + f.experiment + " passed"
+    case "Fail", "Error":
+        st.Verdict = qraiopv1.VerdictFailed
+        st.Message = "Litmus " + f.experiment + " failed"
+        if failStep != "" && failStep != "N/A" {
+            st.Message += ": " + failStep
+        }
+    }
+    return st, nil
+}
+
+// selectorString formats a label selector as Litmus' applabel expects
+func selectorString(selector map[string]string) string {
+    pairs := make([]string, 0, len(selector))
+    for k, v := range selector {
+        pairs = append(pairs, k+"="+v)
+    }
+    sort.Strings(pairs)
+    return strings.Join(pairs, ",")
+}
@@ -0,0 +1,260 @@
+// src/controllers/chaos/mesh.go
+package chaos
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/labels"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// VirtualServiceGVK is the Istio kind L7 faults are injected through
+var VirtualServiceGVK = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"}
+
+// Service meshes told apart by their sidecar
+const (
+    MeshIstio   = "istio"
+    MeshLinkerd = "linkerd"
+)
+
+const (
+    // originalRoutesAnnotation keeps the routes of an existing
+    // VirtualService while a fault is injected into them
+    originalRoutesAnnotation = "qraiop.io/chaos-original-http"
+
+    defaultHTTPDelay   = 500 * time.Millisecond
+    defaultAbortStatus = 503
+)
+
+// DetectMesh returns the service mesh whose sidecar runs in every pod, or ""
+func DetectMesh(pods []corev1.Pod) string {
+    mesh := ""
+    for i, p := range pods {
+        found := ""
+        for _, c := range append(p.Spec.InitContainers, p.Spec.Containers...) {
+            switch c.Name {
+            case "istio-proxy":
+                found = MeshIstio
+            case "linkerd-proxy":
+                found = MeshLinkerd
+            }
+        }
+        if found == "" || (i > 0 && found != mesh) {
+            return ""
+        }
+        mesh = found
+    }
+    return mesh
+}
+
+// httpFault delays or aborts requests to the Services in front of the
+// targets through Istio VirtualService fault injection. An existing
+// VirtualService for a Service gets the fault on each of its routes and
+// has them restored afterwards; otherwise one is created for the fault.
+type httpFault struct {
+    abort bool
+}
+
+func (f httpFault) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    switch DetectMesh(targets) {
+    case MeshIstio:
+    case MeshLinkerd:
+        return fmt.Errorf("linkerd has no fault injection API; %s needs the targets in an Istio mesh", exp.Spec.Type)
+    default:
+        return fmt.Errorf("targets are not in a service mesh; %s needs Istio sidecars", exp.Spec.Type)
+    }
+    if _, err := c.RESTMapper().RESTMapping(VirtualServiceGVK.GroupKind(), VirtualServiceGVK.Version); err != nil {
+        return fmt.Errorf("VirtualService is not served by the cluster: %w", err)
+    }
+    services, err := targetServices(ctx, c, exp.Spec.Target.Namespace, targets)
+    if err != nil {
+        return err
+    }
+    if len(services) == 0 {
+        return fmt.Errorf("no Service selects the targets")
+    }
+
+    var existing unstructured.UnstructuredList
+    existing.SetGroupVersionKind(VirtualServiceGVK.GroupVersion().WithKind(VirtualServiceGVK.Kind + "List"))
+    if err := c.List(ctx, &existing, client.InNamespace(exp.Spec.Target.Namespace)); err != nil {
+        return err
+    }
+    fault := f.fault(exp)
+    for _, svc := range services {
+        if vs := virtualServiceFor(existing.Items, svc); vs != nil {
+            if err := injectIntoRoutes(ctx, c, exp, vs, fault); err != nil {
+                return err
+            }
+            continue
+        }
+        vs := &unstructured.Unstructured{Object: map[string]interface{}{
+            "spec": map[string]interface{}{
+                "hosts": []interface{}{svc.Name},
+                "http": []interface{}{map[string]interface{}{
+                    "fault": fault,
+                    "route": []interface{}{map[string]interface{}{
+                        "destination": map[string]interface{}{"host": svc.Name},
+                    }},
+                }},
+            },
+        }}
+        vs.SetGroupVersionKind(VirtualServiceGVK)
+        vs.SetName("qraiop-chaos-" + exp.Name + "-" + svc.Name)
+        vs.SetNamespace(svc.Namespace)
+        vs.SetLabels(map[string]string{TargetLabel: exp.Name})
+        if err := c.Create(ctx, vs); err != nil && !apierrors.IsAlreadyExists(err) {
+            return err
+        }
+    }
+    return nil
+}
+
+// fault renders the Istio HTTPFaultInjection of the experiment
+func (f httpFault) fault(exp *qraiopv1.ChaosExperiment) map[string]interface{} {
+    cfg := qraiopv1.HTTPFault{}
+    if exp.Spec.HTTP != nil {
+        cfg = *exp.Spec.HTTP
+    }
+    percentage := map[string]interface{}{"value": float64(100)}
+    if cfg.RequestPercentage > 0 && cfg.RequestPercentage <= 100 {
+        percentage["value"] = float64(cfg.RequestPercentage)
+    }
+    if f.abort {
+        status := cfg.AbortStatus
+        if status == 0 {
+            status = defaultAbortStatus
+        }
+        return map[string]interface{}{"abort": map[string]interface{}{"httpStatus": int64(status), "percentage": percentage}}
+    }
+    delay := cfg.Delay.Duration
+    if delay <= 0 {
+        delay = defaultHTTPDelay
+    }
+    return map[string]interface{}{"delay": map[string]interface{}{"fixedDelay": delay.String(), "percentage": percentage}}
+}
+
+// injectIntoRoutes sets the fault on every HTTP route of an existing
+// VirtualService, keeping its routes to restore on cleanup
+func injectIntoRoutes(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, vs *unstructured.Unstructured, fault map[string]interface{}) error {
+    annotations := vs.GetAnnotations()
+    if owner, ok := annotations[TargetLabel]; ok {
+        if owner == exp.Name {
+            return nil
+        }
+        return fmt.Errorf("VirtualService %s already carries a fault of experiment %s", vs.GetName(), owner)
+    }
+    routes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+    if err != nil {
+        return err
+    }
+    original, err := json.Marshal(routes)
+    if err != nil {
+        return err
+    }
+    for i := range routes {
+        if route, ok := routes[i].(map[string]interface{}); ok {
+            route["fault"] = fault
+        }
+    }
+    if err := unstructured.SetNestedSlice(vs.Object, routes, "spec", "http"); err != nil {
+        return err
+    }
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    annotations[TargetLabel] = exp.Name
+    annotations[originalRoutesAnnotation] = string(original)
+    vs.SetAnnotations(annotations)
+    return c.Update(ctx, vs)
+}
+
+func (httpFault) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    if _, err := c.RESTMapper().RESTMapping(VirtualServiceGVK.GroupKind(), VirtualServiceGVK.Version); err != nil {
+        // Nothing can have been injected without the CRD
+        return nil
+    }
+    var list unstructured.UnstructuredList
+    list.SetGroupVersionKind(VirtualServiceGVK.GroupVersion().WithKind(VirtualServiceGVK.Kind + "List"))
+    if err := c.List(ctx, &list, client.InNamespace(exp.Spec.Target.Namespace)); err != nil {
+        return err
+    }
+    for i := range list.Items {
+        vs := &list.Items[i]
+        if vs.GetLabels()[TargetLabel] == exp.Name {
+            if err := c.Delete(ctx, vs); client.IgnoreNotFound(err) != nil {
+                return err
+            }
+            continue
+        }
+        annotations := vs.GetAnnotations()
+        if annotations[TargetLabel] != exp.Name {
+            continue
+        }
+        var routes []interface{}
+        if err := json.Unmarshal([]byte(annotations[originalRoutesAnnotation]), &routes); err != nil {
+            return fmt.Errorf("restoring VirtualService %s: %w", vs.GetName(), err)
+        }
+        if err := unstructured.SetNestedSlice(vs.Object, routes, "spec", "http"); err != nil {
+            return err
+        }
+        delete(annotations, TargetLabel)
+        delete(annotations, originalRoutesAnnotation)
+        vs.SetAnnotations(annotations)
+        if err := c.Update(ctx, vs); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// targetServices returns the Services selecting any of the targets
+func targetServices(ctx context.Context, c client.Reader, namespace string, targets []corev1.Pod) ([]corev1.Service, error) {
+    var services corev1.ServiceList
+    if err := c.List(ctx, &services, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    var matched []corev1.Service
+    for _, svc := range services.Items {
+        if len(svc.Spec.Selector) == 0 {
+            continue
+        }
+        selector := labels.SelectorFromSet(svc.Spec.Selector)
+        for _, p := range targets {
+            if selector.Matches(labels.Set(p.Labels)) {
+                matched = append(matched, svc)
+                break
+            }
+        }
+    }
+    return matched, nil
+}
+
+// virtualServiceFor finds the VirtualService routing a Service, by short
+// or fully qualified host name
+func virtualServiceFor(items []unstructured.Unstructured, svc corev1.Service) *unstructured.Unstructured {
+    names := map[string]bool{
+        svc.Name:                                              true,
+        svc.Name + "." + svc.Namespace:                        true,
+        svc.Name + "." + svc.Namespace + ".svc":               true,
+        svc.Name + "." + svc.Namespace + ".svc.cluster.local": true,
+    }
+    for i := range items {
+        hosts, _, _ := unstructured.NestedStringSlice(items[i].Object, "spec", "hosts")
+        for _, h := range hosts {
+            if names[strings.ToLower(h)] {
+                return &items[i]
+            }
+        }
+    }
+    return nil
+}
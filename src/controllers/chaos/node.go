@@ -0,0 +1,175 @@
+// src/controllers/chaos/node.go
+package chaos
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    defaultMemoryPercent = 80
+    defaultCPUPercent    = 80
+    defaultSafetyMargin  = 5
+)
+
+// pressureConditions are the node conditions the kubelet sets before it
+// starts evicting pods
+var pressureConditions = []corev1.NodeConditionType{
+    corev1.NodeMemoryPressure,
+    corev1.NodeDiskPressure,
+    corev1.NodePIDPressure,
+}
+
+// NodeScoped reports whether a fault type loads the targets' nodes rather
+// than the targets themselves
+func NodeScoped(t string) bool {
+    return t == "node_memory_pressure" || t == "node_cpu_pressure"
+}
+
+// TargetNodes returns the sorted nodes the targets run on
+func TargetNodes(targets []corev1.Pod) []string {
+    seen := make(map[string]bool)
+    var nodes []string
+    for _, p := range targets {
+        if p.Spec.NodeName != "" && !seen[p.Spec.NodeName] {
+            seen[p.Spec.NodeName] = true
+            nodes = append(nodes, p.Spec.NodeName)
+        }
+    }
+    sort.Strings(nodes)
+    return nodes
+}
+
+// NodePressure describes the first of the nodes that is NotReady, gone or
+// reported under pressure by its kubelet, or returns ""
+func NodePressure(ctx context.Context, c client.Reader, nodes []string) (string, error) {
+    for _, name := range nodes {
+        var node corev1.Node
+        err := c.Get(ctx, client.ObjectKey{Name: name}, &node)
+        if apierrors.IsNotFound(err) {
+            return "node " + name + " is gone", nil
+        }
+        if err != nil {
+            return "", err
+        }
+        if msg := nodeUnderPressure(&node); msg != "" {
+            return msg, nil
+        }
+    }
+    return "", nil
+}
+
+func nodeUnderPressure(node *corev1.Node) string {
+    for _, cond := range node.Status.Conditions {
+        if cond.Type == corev1.NodeReady && cond.Status != corev1.ConditionTrue {
+            return "node " + node.Name + " is NotReady"
+        }
+        for _, t := range pressureConditions {
+            if cond.Type == t && cond.Status == corev1.ConditionTrue {
+                return fmt.Sprintf("node %s reports %s: %s", node.Name, t, cond.Message)
+            }
+        }
+    }
+    return ""
+}
+
+func nodeConfig(exp *qraiopv1.ChaosExperiment) qraiopv1.NodePressureFault {
+    cfg := qraiopv1.NodePressureFault{}
+    if exp.Spec.Node != nil {
+        cfg = *exp.Spec.Node
+    }
+    if cfg.MemoryPercent <= 0 || cfg.MemoryPercent > 95 {
+        cfg.MemoryPercent = defaultMemoryPercent
+    }
+    if cfg.CPUPercent <= 0 || cfg.CPUPercent > 100 {
+        cfg.CPUPercent = defaultCPUPercent
+    }
+    if cfg.SafetyMarginPercent <= 0 || cfg.SafetyMarginPercent > 50 {
+        cfg.SafetyMarginPercent = defaultSafetyMargin
+    }
+    return cfg
+}
+
+// nodePressure asks the chaos agent on each target's node to take up memory
+// or CPU on the node itself. Every pod on the node feels it, not only the
+// targets. Nodes already under pressure are refused, and the agent releases
+// the load by itself once available memory drops to the kubelet's reserved
+// memory and eviction threshold plus the safety margin; the controller
+// reverts the fault as soon as a kubelet reports pressure anyway.
+type nodePressure struct {
+    resource string
+}
+
+// agentNodeFault is the request the chaos agents accept for node pressure
+type agentNodeFault struct {
+    ID       string `json:"id"`
+    Resource string `json:"resource"`
+    // MemoryBytes or CPUMillis is the load to add
+    MemoryBytes int64 `json:"memoryBytes,omitempty"`
+    CPUMillis   int64 `json:"cpuMillis,omitempty"`
+    // MinAvailableBytes is the available memory below which the load is released
+    MinAvailableBytes int64  `json:"minAvailableBytes"`
+    Duration          string `json:"duration"`
+}
+
+func (f nodePressure) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    agents, err := chaosAgents(ctx, c)
+    if err != nil {
+        return err
+    }
+    cfg := nodeConfig(exp)
+    seconds := exp.Spec.Duration
+    if seconds <= 0 {
+        seconds = 60
+    }
+    for _, name := range TargetNodes(targets) {
+        agent, ok := agents[name]
+        if !ok {
+            return fmt.Errorf("no chaos agent running on node %s", name)
+        }
+        var node corev1.Node
+        if err := c.Get(ctx, client.ObjectKey{Name: name}, &node); err != nil {
+            return err
+        }
+        if msg := nodeUnderPressure(&node); msg != "" {
+            return fmt.Errorf("refusing to load %s: %s", name, msg)
+        }
+        capacity := node.Status.Capacity.Memory().Value()
+        allocatable := node.Status.Allocatable.Memory().Value()
+        fault := agentNodeFault{
+            ID:       string(exp.UID),
+            Resource: f.resource,
+            // Capacity above allocatable is what the kubelet reserves,
+            // including its hard eviction threshold
+            MinAvailableBytes: capacity - allocatable + capacity*int64(cfg.SafetyMarginPercent)/100,
+            Duration:          (time.Duration(seconds) * time.Second).String(),
+        }
+        if f.resource == "memory" {
+            fault.MemoryBytes = allocatable * int64(cfg.MemoryPercent) / 100
+        } else {
+            fault.CPUMillis = node.Status.Allocatable.Cpu().MilliValue() * int64(cfg.CPUPercent) / 100
+        }
+        body, err := json.Marshal(fault)
+        if err != nil {
+            return err
+        }
+        if err := agentRequest(ctx, http.MethodPost, agentURL(agent, "/v1/faults/node-pressure"), body); err != nil {
+            return fmt.Errorf("chaos agent on %s: %w", name, err)
+        }
+    }
+    return nil
+}
+
+func (nodePressure) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    return dropAgentFaults(ctx, c, exp)
+}
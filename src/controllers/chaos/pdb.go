@@ -0,0 +1,92 @@
+// src/controllers/chaos/pdb.go
+package chaos
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    policyv1 "k8s.io/api/policy/v1"
+    policyv1beta1 "k8s.io/api/policy/v1beta1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/labels"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/compat"
+)
+
+// cluster decides which policy API versions faults use; nil uses the
+// current ones
+var cluster *compat.Cluster
+
+// UseCluster makes faults use the policy API versions the cluster serves
+func UseCluster(c *compat.Cluster) {
+    cluster = c
+}
+
+// budget is the part of a PodDisruptionBudget the pre-flight check reads,
+// whichever API version it came from
+type budget struct {
+    name               string
+    selector           *metav1.LabelSelector
+    disruptionsAllowed int32
+}
+
+func listBudgets(ctx context.Context, c client.Reader, namespace string) ([]budget, error) {
+    var budgets []budget
+    if cluster.GroupVersion("poddisruptionbudgets") == "policy/v1beta1" {
+        var list policyv1beta1.PodDisruptionBudgetList
+        if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+            return nil, err
+        }
+        for _, pdb := range list.Items {
+            budgets = append(budgets, budget{pdb.Name, pdb.Spec.Selector, pdb.Status.DisruptionsAllowed})
+        }
+        return budgets, nil
+    }
+    var list policyv1.PodDisruptionBudgetList
+    if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    for _, pdb := range list.Items {
+        budgets = append(budgets, budget{pdb.Name, pdb.Spec.Selector, pdb.Status.DisruptionsAllowed})
+    }
+    return budgets, nil
+}
+
+// CheckBudgets compares the PodDisruptionBudgets in the targets' namespace
+// with how many of their pods would be disrupted at once
+func CheckBudgets(ctx context.Context, c client.Reader, namespace string, targets []corev1.Pod) (*qraiopv1.PreflightReport, error) {
+    budgets, err := listBudgets(ctx, c, namespace)
+    if err != nil {
+        return nil, err
+    }
+
+    report := &qraiopv1.PreflightReport{}
+    for _, pdb := range budgets {
+        selector, err := metav1.LabelSelectorAsSelector(pdb.selector)
+        if err != nil || selector.Empty() {
+            continue
+        }
+        var targeted int32
+        for _, p := range targets {
+            if selector.Matches(labels.Set(p.Labels)) {
+                targeted++
+            }
+        }
+        if targeted == 0 {
+            continue
+        }
+        check := qraiopv1.BudgetCheck{
+            Name:               pdb.name,
+            DisruptionsAllowed: pdb.disruptionsAllowed,
+            Targeted:           targeted,
+            Violated:           targeted > pdb.disruptionsAllowed,
+        }
+        if check.Violated {
+            report.Violations++
+        }
+        report.Budgets = append(report.Budgets, check)
+    }
+    return report, nil
+}
@@ -0,0 +1,142 @@
+// src/controllers/chaos/schedule.go
+package chaos
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/cron"
+)
+
+// maxFirings bounds the firings Runs lists, a week of every minute
+const maxFirings = 7 * 24 * 60
+
+var weekdays = map[string]time.Weekday{
+    "sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+    "thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Schedule is the cron expression of a ChaosSchedule read in its time zone,
+// with the windows its firings are skipped outside of
+type Schedule struct {
+    cron *cron.Schedule
+    loc  *time.Location
+    spec *qraiopv1.ChaosScheduleSpec
+
+    days       map[time.Weekday]bool
+    start, end int
+}
+
+// NewSchedule parses the schedule, time zone and business hours of spec
+func NewSchedule(spec *qraiopv1.ChaosScheduleSpec) (*Schedule, error) {
+    c, err := cron.Parse(spec.Schedule)
+    if err != nil {
+        return nil, fmt.Errorf("schedule %q: %w", spec.Schedule, err)
+    }
+    loc := time.UTC
+    if spec.TimeZone != "" {
+        if loc, err = time.LoadLocation(spec.TimeZone); err != nil {
+            return nil, fmt.Errorf("timeZone: %w", err)
+        }
+    }
+    s := &Schedule{cron: c, loc: loc, spec: spec}
+    if bh := spec.BusinessHours; bh != nil {
+        s.days = map[time.Weekday]bool{}
+        for _, d := range bh.Days {
+            day, ok := weekdays[strings.ToLower(d)]
+            if !ok {
+                return nil, fmt.Errorf("businessHours: unknown day %q", d)
+            }
+            s.days[day] = true
+        }
+        if s.start, err = clock(bh.Start); err != nil {
+            return nil, fmt.Errorf("businessHours.start: %w", err)
+        }
+        if s.end, err = clock(bh.End); err != nil {
+            return nil, fmt.Errorf("businessHours.end: %w", err)
+        }
+        if s.start == s.end {
+            return nil, fmt.Errorf("businessHours start and end are both %s", bh.Start)
+        }
+    }
+    for i, w := range spec.ExclusionWindows {
+        if !w.End.After(w.Start.Time) {
+            return nil, fmt.Errorf("exclusionWindows[%d] ends before it starts", i)
+        }
+    }
+    return s, nil
+}
+
+// clock parses 15:04 into minutes of the day
+func clock(s string) (int, error) {
+    t, err := time.Parse("15:04", s)
+    if err != nil {
+        return 0, fmt.Errorf("%q is not a time of day like 09:30", s)
+    }
+    return t.Hour()*60 + t.Minute(), nil
+}
+
+// Next returns the first firing after t, or the zero time when the
+// expression never fires
+func (s *Schedule) Next(t time.Time) time.Time {
+    return s.cron.Next(t.In(s.loc))
+}
+
+// Skip returns why a firing at t doesn't run, or "" when it does
+func (s *Schedule) Skip(t time.Time) string {
+    for _, w := range s.spec.ExclusionWindows {
+        if !t.Before(w.Start.Time) && t.Before(w.End.Time) {
+            if w.Reason != "" {
+                return "in exclusion window: " + w.Reason
+            }
+            return fmt.Sprintf("in exclusion window %s to %s", w.Start.UTC().Format(time.RFC3339), w.End.UTC().Format(time.RFC3339))
+        }
+    }
+    if s.days == nil {
+        return ""
+    }
+    local := t.In(s.loc)
+    minute := local.Hour()*60 + local.Minute()
+    day := local.Weekday()
+    inHours := minute >= s.start && minute < s.end
+    if s.end < s.start {
+        // Overnight hours belong to the day they start on
+        inHours = minute >= s.start
+        if minute < s.end {
+            inHours, day = true, (day+6)%7
+        }
+    }
+    if !inHours || !s.days[day] {
+        return "outside business hours"
+    }
+    return ""
+}
+
+// Runs lists the firings in [from, to) that aren't skipped. At most a
+// week's worth of minutes are listed, and at most a year's looked at.
+func (s *Schedule) Runs(from, to time.Time) []time.Time {
+    var runs []time.Time
+    t := s.Next(from.Add(-time.Minute))
+    for n := 0; !t.IsZero() && t.Before(to) && len(runs) < maxFirings && n < 366*24*60; n++ {
+        if s.Skip(t) == "" {
+            runs = append(runs, t)
+        }
+        t = s.Next(t)
+    }
+    return runs
+}
+
+// ScheduledExperiment is the experiment a schedule runs, named name. Its
+// target is always in the schedule's namespace.
+func ScheduledExperiment(s *qraiopv1.ChaosSchedule, name string) *qraiopv1.ChaosExperiment {
+    exp := &qraiopv1.ChaosExperiment{
+        ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.Namespace},
+        Spec:       qraiopv1.ChaosExperimentSpec{ExperimentConfig: *s.Spec.Experiment.DeepCopy()},
+    }
+    exp.Spec.Target.Namespace = s.Namespace
+    return exp
+}
@@ -0,0 +1,393 @@
+// src/controllers/chaos/storage.go
+package chaos
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    storagev1 "k8s.io/api/storage/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    // RoleLabel tells apart the helper pods a storage fault creates
+    RoleLabel = "qraiop.io/chaos-role"
+    // AgentLabel selects the node-local chaos agents io_latency and node
+    // pressure go through
+    AgentLabel = "app.kubernetes.io/name=qraiop-chaos-agent"
+
+    fillerRole  = "volume-filler"
+    fillerImage = "busybox:1.36"
+    fillMount   = "/fill"
+    // fillFile is the only file volume_fill writes, and removes again
+    fillFile = ".qraiop-chaos-fill"
+
+    defaultFillPercent = 90
+    defaultIOLatency   = 100 * time.Millisecond
+    agentPort          = 8080
+)
+
+// agentClient talks to the chaos agents
+var agentClient = &http.Client{Timeout: 10 * time.Second}
+
+func storageConfig(exp *qraiopv1.ChaosExperiment) qraiopv1.StorageFault {
+    cfg := qraiopv1.StorageFault{}
+    if exp.Spec.Storage != nil {
+        cfg = *exp.Spec.Storage
+    }
+    if cfg.FillPercent <= 0 || cfg.FillPercent > 95 {
+        cfg.FillPercent = defaultFillPercent
+    }
+    if cfg.Latency.Duration <= 0 {
+        cfg.Latency.Duration = defaultIOLatency
+    }
+    return cfg
+}
+
+// podVolume is a target's volume backed by a claim
+type podVolume struct {
+    pod    *corev1.Pod
+    volume string
+    claim  *corev1.PersistentVolumeClaim
+}
+
+// targetVolumes resolves the claim-backed volumes of the targets a storage
+// fault acts on. It fails, before anything is touched, when any of them
+// belongs to a StorageClass that didn't opt in.
+func targetVolumes(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) ([]podVolume, error) {
+    wanted := make(map[string]bool)
+    for _, v := range storageConfig(exp).Volumes {
+        wanted[v] = true
+    }
+    classes := make(map[string]bool)
+    var refused []string
+    var volumes []podVolume
+    for i := range targets {
+        pod := &targets[i]
+        for _, v := range pod.Spec.Volumes {
+            if v.PersistentVolumeClaim == nil || (len(wanted) > 0 && !wanted[v.Name]) {
+                continue
+            }
+            var claim corev1.PersistentVolumeClaim
+            if err := c.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: v.PersistentVolumeClaim.ClaimName}, &claim); err != nil {
+                return nil, err
+            }
+            class := ""
+            if claim.Spec.StorageClassName != nil {
+                class = *claim.Spec.StorageClassName
+            }
+            allowed, ok := classes[class]
+            if !ok {
+                var err error
+                if allowed, err = storageChaosAllowed(ctx, c, class); err != nil {
+                    return nil, err
+                }
+                classes[class] = allowed
+            }
+            if !allowed {
+                refused = append(refused, fmt.Sprintf("%s/%s (StorageClass %q)", pod.Name, v.Name, class))
+                continue
+            }
+            volumes = append(volumes, podVolume{pod: pod, volume: v.Name, claim: &claim})
+        }
+    }
+    if len(refused) > 0 {
+        return nil, fmt.Errorf("volumes %s are not opted into storage chaos; annotate their StorageClass with %s=true",
+            strings.Join(refused, ", "), qraiopv1.StorageChaosAnnotation)
+    }
+    if len(volumes) == 0 {
+        return nil, fmt.Errorf("targets have no matching volumes backed by a PersistentVolumeClaim")
+    }
+    return volumes, nil
+}
+
+func storageChaosAllowed(ctx context.Context, c client.Client, class string) (bool, error) {
+    if class == "" {
+        return false, nil
+    }
+    var sc storagev1.StorageClass
+    err := c.Get(ctx, client.ObjectKey{Name: class}, &sc)
+    if apierrors.IsNotFound(err) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    return sc.Annotations[qraiopv1.StorageChaosAnnotation] == "true", nil
+}
+
+// volumeDetach cordons the nodes of the targets and force deletes them, so
+// their replacements are scheduled elsewhere and the volumes have to be
+// detached and attached again
+type volumeDetach struct{}
+
+func (volumeDetach) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    if _, err := targetVolumes(ctx, c, exp, targets); err != nil {
+        return err
+    }
+    for i := range targets {
+        pod := &targets[i]
+        if err := cordon(ctx, c, exp, pod.Spec.NodeName); err != nil {
+            return err
+        }
+        if err := c.Delete(ctx, pod, client.GracePeriodSeconds(0)); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// cordon marks a node unschedulable, labelling it so Cleanup only uncordons
+// the nodes the experiment cordoned itself
+func cordon(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, name string) error {
+    var node corev1.Node
+    if err := c.Get(ctx, client.ObjectKey{Name: name}, &node); err != nil {
+        return client.IgnoreNotFound(err)
+    }
+    if node.Spec.Unschedulable {
+        return nil
+    }
+    patch := client.MergeFrom(node.DeepCopy())
+    if node.Labels == nil {
+        node.Labels = map[string]string{}
+    }
+    node.Labels[TargetLabel] = exp.Name
+    node.Spec.Unschedulable = true
+    return c.Patch(ctx, &node, patch)
+}
+
+func (volumeDetach) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    var nodes corev1.NodeList
+    if err := c.List(ctx, &nodes, client.MatchingLabels{TargetLabel: exp.Name}); err != nil {
+        return err
+    }
+    for i := range nodes.Items {
+        node := &nodes.Items[i]
+        patch := client.MergeFrom(node.DeepCopy())
+        delete(node.Labels, TargetLabel)
+        node.Spec.Unschedulable = false
+        if err := c.Patch(ctx, node, patch); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// volumeFill runs a pod next to each target that writes a single file into
+// the volume until it is as full as requested. The file is removed when the
+// pod is stopped, and by the pod itself once the experiment's duration is
+// over, so nothing is left behind even if cleanup never runs.
+type volumeFill struct{}
+
+func fillerName(exp *qraiopv1.ChaosExperiment, v podVolume) string {
+    name := fmt.Sprintf("qraiop-chaos-fill-%s-%s-%s", exp.Name, v.pod.Name, v.volume)
+    if len(name) > 63 {
+        name = strings.TrimRight(name[:63], "-.")
+    }
+    return name
+}
+
+func (volumeFill) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    volumes, err := targetVolumes(ctx, c, exp, targets)
+    if err != nil {
+        return err
+    }
+    for _, v := range volumes {
+        for _, mode := range v.claim.Spec.AccessModes {
+            if mode == corev1.ReadWriteOncePod {
+                return fmt.Errorf("volume %s of %s is ReadWriteOncePod and can't be filled from another pod", v.volume, v.pod.Name)
+            }
+        }
+    }
+    cfg := storageConfig(exp)
+    for _, v := range volumes {
+        if err := c.Create(ctx, fillerPod(exp, v, cfg.FillPercent)); err != nil && !apierrors.IsAlreadyExists(err) {
+            return err
+        }
+    }
+    return nil
+}
+
+// fillerPod runs on the target's node, so ReadWriteOnce volumes can be
+// mounted a second time, as the target's user, so it may write to them
+func fillerPod(exp *qraiopv1.ChaosExperiment, v podVolume, percent int) *corev1.Pod {
+    seconds := exp.Spec.Duration
+    if seconds <= 0 {
+        seconds = 60
+    }
+    // df reports 1K blocks; only the difference to the requested fill is written
+    script := fmt.Sprintf(`F=%[1]s/%[2]s
+trap 'rm -f "$F"; exit 0' TERM INT EXIT
+set -- $(df -Pk %[1]s | awk 'NR==2 {print $2, $3}')
+N=$(( $1 * %[3]d / 100 - $2 ))
+[ "$N" -gt 0 ] && dd if=/dev/zero of="$F" bs=1024 count="$N" 2>/dev/null
+sleep %[4]d & wait`, fillMount, fillFile, percent, seconds)
+
+    deadline := int64(seconds + 60)
+    return &corev1.Pod{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      fillerName(exp, v),
+            Namespace: v.pod.Namespace,
+            Labels:    map[string]string{TargetLabel: exp.Name, RoleLabel: fillerRole},
+        },
+        Spec: corev1.PodSpec{
+            NodeName:                      v.pod.Spec.NodeName,
+            RestartPolicy:                 corev1.RestartPolicyNever,
+            ActiveDeadlineSeconds:         &deadline,
+            TerminationGracePeriodSeconds: int64Ptr(30),
+            SecurityContext:               v.pod.Spec.SecurityContext.DeepCopy(),
+            Containers: []corev1.Container{{
+                Name:    "fill",
+                Image:   fillerImage,
+                Command: []string{"sh", "-c", script},
+                VolumeMounts: []corev1.VolumeMount{{
+                    Name:      "target",
+                    MountPath: fillMount,
+                }},
+            }},
+            Volumes: []corev1.Volume{{
+                Name: "target",
+                VolumeSource: corev1.VolumeSource{
+                    PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: v.claim.Name},
+                },
+            }},
+        },
+    }
+}
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func (volumeFill) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    // Fillers are stopped gracefully so they remove their file
+    return c.DeleteAllOf(ctx, &corev1.Pod{},
+        client.InNamespace(exp.Spec.Target.Namespace),
+        client.MatchingLabels{TargetLabel: exp.Name, RoleLabel: fillerRole},
+    )
+}
+
+// ioLatency asks the chaos agent on each target's node to delay IO on the
+// target's volumes. Agents drop the fault by themselves after the
+// experiment's duration, in case cleanup never reaches them.
+type ioLatency struct{}
+
+// agentFault is the request the chaos agents accept
+type agentFault struct {
+    ID       string   `json:"id"`
+    PodUID   string   `json:"podUID"`
+    Volumes  []string `json:"volumes"`
+    Latency  string   `json:"latency"`
+    Duration string   `json:"duration"`
+}
+
+func (ioLatency) Inject(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    volumes, err := targetVolumes(ctx, c, exp, targets)
+    if err != nil {
+        return err
+    }
+    agents, err := chaosAgents(ctx, c)
+    if err != nil {
+        return err
+    }
+
+    byPod := make(map[*corev1.Pod][]string)
+    for _, v := range volumes {
+        byPod[v.pod] = append(byPod[v.pod], v.volume)
+    }
+    cfg := storageConfig(exp)
+    seconds := exp.Spec.Duration
+    if seconds <= 0 {
+        seconds = 60
+    }
+    for pod, names := range byPod {
+        agent, ok := agents[pod.Spec.NodeName]
+        if !ok {
+            return fmt.Errorf("no chaos agent running on node %s of %s", pod.Spec.NodeName, pod.Name)
+        }
+        sort.Strings(names)
+        body, err := json.Marshal(agentFault{
+            ID:       string(exp.UID),
+            PodUID:   string(pod.UID),
+            Volumes:  names,
+            Latency:  cfg.Latency.Duration.String(),
+            Duration: (time.Duration(seconds) * time.Second).String(),
+        })
+        if err != nil {
+            return err
+        }
+        if err := agentRequest(ctx, http.MethodPost, agentURL(agent, "/v1/faults/io-latency"), body); err != nil {
+            return fmt.Errorf("chaos agent on %s: %w", pod.Spec.NodeName, err)
+        }
+    }
+    return nil
+}
+
+func (ioLatency) Cleanup(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    return dropAgentFaults(ctx, c, exp)
+}
+
+// dropAgentFaults tells the chaos agents to drop an experiment's fault
+func dropAgentFaults(ctx context.Context, c client.Client, exp *qraiopv1.ChaosExperiment) error {
+    agents, err := chaosAgents(ctx, c)
+    if err != nil {
+        return err
+    }
+    // The fault may be held by any agent, so all of them are told to drop it
+    for node, agent := range agents {
+        if err := agentRequest(ctx, http.MethodDelete, agentURL(agent, "/v1/faults/"+string(exp.UID)), nil); err != nil {
+            return fmt.Errorf("chaos agent on %s: %w", node, err)
+        }
+    }
+    return nil
+}
+
+// chaosAgents returns the running chaos agent pods by node
+func chaosAgents(ctx context.Context, c client.Reader) (map[string]*corev1.Pod, error) {
+    selector, err := metav1.ParseToLabelSelector(AgentLabel)
+    if err != nil {
+        return nil, err
+    }
+    var pods corev1.PodList
+    if err := c.List(ctx, &pods, client.MatchingLabels(selector.MatchLabels)); err != nil {
+        return nil, err
+    }
+    agents := make(map[string]*corev1.Pod)
+    for i := range pods.Items {
+        p := &pods.Items[i]
+        if p.Status.Phase == corev1.PodRunning && p.Status.PodIP != "" {
+            agents[p.Spec.NodeName] = p
+        }
+    }
+    return agents, nil
+}
+
+func agentURL(agent *corev1.Pod, path string) string {
+    return fmt.Sprintf("http://%s:%d%s", agent.Status.PodIP, agentPort, path)
+}
+
+func agentRequest(ctx context.Context, method, url string, body []byte) error {
+    req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := agentClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+        return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+    }
+    return nil
+}
@@ -0,0 +1,392 @@
+// src/controllers/chaos/toolkit.go
+package chaos
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/labels"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// ToolkitExtension names the Chaos Toolkit extension carrying the full
+// QRAIOP experiment, so an exported experiment imports back unchanged
+const ToolkitExtension = "qraiop"
+
+// ToolkitExperiment is a Chaos Toolkit experiment, as far as QRAIOP maps it
+type ToolkitExperiment struct {
+    Version     string             `json:"version,omitempty"`
+    Title       string             `json:"title"`
+    Description string             `json:"description,omitempty"`
+    Tags        []string           `json:"tags,omitempty"`
+    SteadyState *ToolkitHypothesis `json:"steady-state-hypothesis,omitempty"`
+    Method      []ToolkitActivity  `json:"method"`
+    Rollbacks   []ToolkitActivity  `json:"rollbacks,omitempty"`
+    Extensions  []ToolkitExt       `json:"extensions,omitempty"`
+}
+
+// ToolkitHypothesis is the steady-state hypothesis of an experiment
+type ToolkitHypothesis struct {
+    Title  string            `json:"title"`
+    Probes []ToolkitActivity `json:"probes,omitempty"`
+}
+
+// ToolkitActivity is an action or probe
+type ToolkitActivity struct {
+    Type      string          `json:"type"`
+    Name      string          `json:"name"`
+    Provider  ToolkitProvider `json:"provider"`
+    Tolerance interface{}     `json:"tolerance,omitempty"`
+    Pauses    *ToolkitPauses  `json:"pauses,omitempty"`
+}
+
+// ToolkitProvider runs an activity: a Python function of an extension
+// module, an HTTP request or a process
+type ToolkitProvider struct {
+    Type      string                 `json:"type"`
+    Module    string                 `json:"module,omitempty"`
+    Func      string                 `json:"func,omitempty"`
+    Arguments map[string]interface{} `json:"arguments,omitempty"`
+    URL       string                 `json:"url,omitempty"`
+    // Timeout in seconds, or [connect, read] for HTTP
+    Timeout interface{} `json:"timeout,omitempty"`
+}
+
+// ToolkitPauses are the seconds waited before and after an activity
+type ToolkitPauses struct {
+    Before float64 `json:"before,omitempty"`
+    After  float64 `json:"after,omitempty"`
+}
+
+// ToolkitExt is an experiment extension. Only QRAIOP's is read; the others
+// are dropped on import.
+type ToolkitExt struct {
+    Name string `json:"name"`
+    // Experiment is the whole spec; the experiment config is inlined, so
+    // extensions exported with only the config still import
+    Experiment *qraiopv1.ChaosExperimentSpec `json:"experiment,omitempty"`
+}
+
+// descriptionAnnotation holds the description of an imported experiment
+const descriptionAnnotation = "kubernetes.io/description"
+
+// Python functions of the Chaos Toolkit drivers QRAIOP imports
+const (
+    toolkitTerminatePods = "chaosk8s.pod.actions.terminate_pods"
+    toolkitDenyIngress   = "chaosk8s.networking.actions.deny_all_ingress"
+    toolkitDelayFault    = "chaosistio.fault.actions.add_delay_fault"
+    toolkitAbortFault    = "chaosistio.fault.actions.add_abort_fault"
+)
+
+// FromToolkit converts a Chaos Toolkit experiment into a ChaosExperiment
+// in namespace, or the fault's namespace when empty. The experiment's
+// first action of a known driver becomes the fault, held for its pause
+// after; HTTP probes of the steady-state hypothesis become probes. What
+// doesn't map is listed in the returned warnings. An experiment exported
+// by QRAIOP is taken from its extension instead, less abort, ignorePDB and
+// policyExceptions.
+func FromToolkit(t *ToolkitExperiment, namespace string) (*qraiopv1.ChaosExperiment, []string, error) {
+    exp := &qraiopv1.ChaosExperiment{}
+    exp.APIVersion = qraiopv1.GroupVersion.String()
+    exp.Kind = "ChaosExperiment"
+    exp.Name = toolkitName(t.Title, 63)
+    if exp.Name == "" {
+        return nil, nil, fmt.Errorf("the experiment has no title to name it by")
+    }
+    exp.Namespace = namespace
+    if t.Description != "" {
+        exp.Annotations = map[string]string{descriptionAnnotation: t.Description}
+    }
+
+    for _, ext := range t.Extensions {
+        if ext.Name == ToolkitExtension && ext.Experiment != nil {
+            exp.Spec = *ext.Experiment
+            if exp.Namespace == "" {
+                exp.Namespace = exp.Spec.Target.Namespace
+            }
+            return exp, clearPrivileged(&exp.Spec), nil
+        }
+    }
+
+    var warnings []string
+    found := false
+    for _, a := range t.Method {
+        if a.Type != "action" {
+            warnings = append(warnings, fmt.Sprintf("%s %s: only actions of the method are imported", a.Type, a.Name))
+            continue
+        }
+        if found {
+            warnings = append(warnings, fmt.Sprintf("action %s: an experiment injects a single fault, only the first is imported", a.Name))
+            continue
+        }
+        fn := a.Provider.Module + "." + a.Provider.Func
+        warn, err := toolkitFault(&exp.Spec.ExperimentConfig, a.Provider)
+        if err != nil {
+            return nil, nil, fmt.Errorf("action %s: %w", a.Name, err)
+        }
+        if exp.Spec.Type == "" {
+            warnings = append(warnings, fmt.Sprintf("action %s: %s has no QRAIOP equivalent", a.Name, fn))
+            continue
+        }
+        found = true
+        warnings = append(warnings, warn...)
+        if a.Pauses != nil && a.Pauses.After >= 1 {
+            exp.Spec.Duration = int(a.Pauses.After)
+        }
+    }
+    if !found {
+        return nil, warnings, fmt.Errorf("the method has no action QRAIOP can run")
+    }
+    if exp.Namespace == "" {
+        exp.Namespace = exp.Spec.Target.Namespace
+    }
+
+    if t.SteadyState != nil {
+        for _, p := range t.SteadyState.Probes {
+            probe, ok := toolkitProbe(p)
+            if !ok {
+                warnings = append(warnings, fmt.Sprintf("probe %s: only HTTP probes of the steady-state hypothesis are imported", p.Name))
+                continue
+            }
+            exp.Spec.Probes = append(exp.Spec.Probes, probe)
+        }
+    }
+    if len(t.Rollbacks) > 0 {
+        warnings = append(warnings, "rollbacks are not imported; QRAIOP reverts the fault itself")
+    }
+    return exp, warnings, nil
+}
+
+// toolkitFault maps the provider of a driver action onto cfg, leaving its
+// type empty for a function QRAIOP doesn't know
+func toolkitFault(cfg *qraiopv1.ExperimentConfig, p ToolkitProvider) ([]string, error) {
+    args := p.Arguments
+    cfg.Target.Namespace = stringArg(args, "ns", "default")
+    var warnings []string
+
+    switch p.Module + "." + p.Func {
+    case toolkitTerminatePods:
+        cfg.Type = "pod_kill"
+        selector, err := labels.ConvertSelectorToLabelsMap(stringArg(args, "label_selector", ""))
+        if err != nil {
+            return nil, fmt.Errorf("label_selector: %w", err)
+        }
+        cfg.Target.Selector = selector
+        qty := intArg(args, "qty", 1)
+        switch {
+        case boolArg(args, "all"):
+            cfg.Percentage = 100
+        case stringArg(args, "mode", "fixed") == "percentage":
+            cfg.Percentage = min(max(qty, 1), 100)
+        default:
+            // Targets are a share of the matching pods, rounded up
+            cfg.Percentage = 1
+            if qty != 1 {
+                warnings = append(warnings, fmt.Sprintf("a fixed qty of %d pods has no equivalent, 1 percent of the pods and at least one is targeted", qty))
+            }
+        }
+    case toolkitDenyIngress:
+        cfg.Type = "network_partition"
+        cfg.Target.Selector = map[string]string{}
+        if selectors, ok := args["label_selectors"].(map[string]interface{}); ok {
+            for k, v := range selectors {
+                cfg.Target.Selector[k] = fmt.Sprint(v)
+            }
+        }
+    case toolkitDelayFault, toolkitAbortFault:
+        cfg.Type = "http_delay"
+        cfg.HTTP = &qraiopv1.HTTPFault{}
+        if p.Func == "add_abort_fault" {
+            cfg.Type = "http_abort"
+            cfg.HTTP.AbortStatus = intArg(args, "http_status", 503)
+        } else {
+            delay, err := time.ParseDuration(stringArg(args, "fixed_delay", "500ms"))
+            if err != nil {
+                return nil, fmt.Errorf("fixed_delay: %w", err)
+            }
+            cfg.HTTP.Delay = metav1.Duration{Duration: delay}
+        }
+        cfg.HTTP.RequestPercentage = intArg(args, "percentage", 100)
+        // Istio faults name a VirtualService rather than the pods behind it
+        service := stringArg(args, "virtual_service_name", "")
+        cfg.Target.Selector = map[string]string{"app": service}
+        warnings = append(warnings, fmt.Sprintf("the target of VirtualService %s is assumed to be the pods labelled app=%s", service, service))
+    default:
+        return nil, nil
+    }
+    if len(cfg.Target.Selector) == 0 {
+        return nil, fmt.Errorf("the action selects no pods by label")
+    }
+    return warnings, nil
+}
+
+// toolkitProbe maps an HTTP probe, whose tolerance is the expected status
+func toolkitProbe(a ToolkitActivity) (qraiopv1.SteadyStateProbe, bool) {
+    if a.Provider.Type != "http" || a.Provider.URL == "" {
+        return qraiopv1.SteadyStateProbe{}, false
+    }
+    probe := qraiopv1.SteadyStateProbe{
+        Name: toolkitName(a.Name, 30),
+        HTTP: &qraiopv1.HTTPProbe{URL: a.Provider.URL, ExpectedStatus: 200},
+    }
+    if status, ok := a.Tolerance.(float64); ok {
+        probe.HTTP.ExpectedStatus = int(status)
+    }
+    if timeout, ok := a.Provider.Timeout.(float64); ok && timeout > 0 {
+        probe.Timeout = metav1.Duration{Duration: time.Duration(timeout * float64(time.Second))}
+    }
+    return probe, true
+}
+
+// ToToolkit converts a ChaosExperiment into a Chaos Toolkit experiment
+// running its fault through the Kubernetes driver where one exists, with
+// its HTTP probes as the steady-state hypothesis. The whole experiment is
+// kept in the qraiop extension; what the Chaos Toolkit can't run is listed
+// in the returned warnings.
+func ToToolkit(exp *qraiopv1.ChaosExperiment) (*ToolkitExperiment, []string) {
+    cfg := exp.Spec.ExperimentConfig
+    spec := exp.Spec
+    t := &ToolkitExperiment{
+        Version:     "1.0.0",
+        Title:       exp.Name,
+        Description: exp.Annotations[descriptionAnnotation],
+        Tags:        []string{"qraiop", cfg.Type},
+        Method:      []ToolkitActivity{},
+        Extensions:  []ToolkitExt{{Name: ToolkitExtension, Experiment: &spec}},
+    }
+    var warnings []string
+
+    var provider *ToolkitProvider
+    switch cfg.Type {
+    case "pod_kill":
+        percentage := cfg.Percentage
+        if percentage <= 0 {
+            percentage = 100
+        }
+        provider = &ToolkitProvider{Type: "python", Module: "chaosk8s.pod.actions", Func: "terminate_pods", Arguments: map[string]interface{}{
+            "label_selector": selectorString(cfg.Target.Selector),
+            "ns":             cfg.Target.Namespace,
+            "mode":           "percentage",
+            "qty":            percentage,
+            "rand":           true,
+        }}
+    case "network_partition":
+        selectors := map[string]interface{}{}
+        for k, v := range cfg.Target.Selector {
+            selectors[k] = v
+        }
+        provider = &ToolkitProvider{Type: "python", Module: "chaosk8s.networking.actions", Func: "deny_all_ingress", Arguments: map[string]interface{}{
+            "label_selectors": selectors,
+            "ns":              cfg.Target.Namespace,
+        }}
+        if cfg.Percentage > 0 && cfg.Percentage < 100 {
+            warnings = append(warnings, fmt.Sprintf("the Chaos Toolkit partitions all matching pods, not %d percent", cfg.Percentage))
+        }
+    default:
+        warnings = append(warnings, fmt.Sprintf("%s has no Chaos Toolkit driver; only the qraiop extension carries the fault", cfg.Type))
+    }
+    if provider != nil {
+        t.Method = append(t.Method, ToolkitActivity{
+            Type:     "action",
+            Name:     strings.ReplaceAll(cfg.Type, "_", "-"),
+            Provider: *provider,
+            Pauses:   &ToolkitPauses{After: float64(durationSeconds(exp))},
+        })
+        if cfg.Type == "network_partition" {
+            t.Rollbacks = append(t.Rollbacks, ToolkitActivity{
+                Type: "action",
+                Name: "remove-network-partition",
+                Provider: ToolkitProvider{Type: "python", Module: "chaosk8s.networking.actions", Func: "remove_deny_all_ingress", Arguments: map[string]interface{}{
+                    "ns": cfg.Target.Namespace,
+                }},
+            })
+        }
+    }
+
+    for _, p := range exp.Spec.Probes {
+        if p.HTTP == nil {
+            warnings = append(warnings, fmt.Sprintf("probe %s: only HTTP probes are exported to the steady-state hypothesis", p.Name))
+            continue
+        }
+        if t.SteadyState == nil {
+            t.SteadyState = &ToolkitHypothesis{Title: "dependencies stay healthy"}
+        }
+        status := p.HTTP.ExpectedStatus
+        if status == 0 {
+            status = 200
+        }
+        timeout := p.Timeout.Duration
+        if timeout <= 0 {
+            timeout = 5 * time.Second
+        }
+        t.SteadyState.Probes = append(t.SteadyState.Probes, ToolkitActivity{
+            Type:      "probe",
+            Name:      p.Name,
+            Tolerance: status,
+            Provider:  ToolkitProvider{Type: "http", URL: p.HTTP.URL, Timeout: timeout.Seconds()},
+        })
+    }
+    return t, warnings
+}
+
+// clearPrivileged drops what an imported file must not decide for whoever
+// applies it: aborting the experiment, overriding PodDisruptionBudgets and
+// exempting helper pods from admission policies. They are set by hand on
+// the imported experiment, where the webhook checks them.
+func clearPrivileged(spec *qraiopv1.ChaosExperimentSpec) []string {
+    var warnings []string
+    if spec.Abort {
+        spec.Abort = false
+        warnings = append(warnings, "abort is not imported")
+    }
+    if spec.IgnorePDB {
+        spec.IgnorePDB = false
+        warnings = append(warnings, "ignorePDB is not imported, set it on the experiment to override PodDisruptionBudgets")
+    }
+    if spec.PolicyExceptions != nil {
+        spec.PolicyExceptions = nil
+        warnings = append(warnings, "policyExceptions are not imported, set them on the experiment to exempt its helper pods")
+    }
+    return warnings
+}
+
+var notNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// toolkitName turns a title into a Kubernetes name of at most max characters
+func toolkitName(title string, max int) string {
+    name := strings.Trim(notNameChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+    if len(name) > max {
+        name = strings.TrimRight(name[:max], "-")
+    }
+    return name
+}
+
+func stringArg(args map[string]interface{}, key, def string) string {
+    if v, ok := args[key].(string); ok && v != "" {
+        return v
+    }
+    return def
+}
+
+// intArg reads a number, which JSON decodes as float64, or a numeric string
+func intArg(args map[string]interface{}, key string, def int) int {
+    switch v := args[key].(type) {
+    case float64:
+        return int(v)
+    case string:
+        if n, err := strconv.Atoi(v); err == nil {
+            return n
+        }
+    }
+    return def
+}
+
+func boolArg(args map[string]interface{}, key string) bool {
+    v, _ := args[key].(bool)
+    return v
+}
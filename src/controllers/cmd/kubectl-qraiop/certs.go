@@ -0,0 +1,135 @@
+// src/controllers/cmd/kubectl-qraiop/certs.go
+package main
+
+import (
+    "context"
+    "crypto/x509"
+    "encoding/pem"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// runCerts shows each instance's cryptography settings and the TLS
+// certificates issued for QRAIOP in the namespace, with their expiry and
+// whether they are revoked, revokes the certificate of a Secret or shows
+// the expiry forecast of the instances
+func runCerts(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("certs", flag.ExitOnError)
+    cl := addClusterFlags(fs)
+    reason := fs.String("reason", qraiopv1.RevocationKeyCompromise, "revocation reason: unspecified, keyCompromise, superseded or cessationOfOperation")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: kubectl qraiop certs [-n namespace] [revoke [--reason reason] <secret> | forecast]")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+
+    c, namespace, err := cl.client()
+    if err != nil {
+        return err
+    }
+    if fs.Arg(0) == "revoke" {
+        // Flags may also follow the subcommand
+        _ = fs.Parse(fs.Args()[1:])
+        if fs.NArg() != 1 {
+            fs.Usage()
+            os.Exit(2)
+        }
+        if err := c.RevokeCertificate(ctx, namespace, fs.Arg(0), *reason); err != nil {
+            return err
+        }
+        fmt.Printf("secret %s/%s revoked (%s); it is on the next revocation list\n", namespace, fs.Arg(0), *reason)
+        return nil
+    }
+    if fs.Arg(0) == "forecast" {
+        instances, err := c.ListQraiops(ctx, namespace)
+        if err != nil {
+            return err
+        }
+        return printForecast(instances)
+    }
+
+    instances, err := c.ListQraiops(ctx, namespace)
+    if err != nil {
+        return err
+    }
+    revoked := make(map[string]string)
+    for _, q := range instances {
+        cfg := q.Spec.Cryptography
+        fmt.Printf("%s: algorithms=%s securityLevel=%d hybrid=%t status=%s\n", q.Name,
+            strings.Join(cfg.Algorithms, ","), cfg.SecurityLevel, cfg.HybridMode,
+            orNone(q.Status.Components[qraiopv1.ComponentCryptography].Status))
+        if st := q.Status.Revocation; st != nil {
+            fmt.Printf("%s: revocation list %d with %d revoked, next update %s\n", q.Name, st.Number, len(st.Revoked),
+                st.NextUpdate.Format(time.RFC3339))
+            for _, e := range st.Revoked {
+                revoked[e.SerialNumber] = e.Reason
+            }
+        }
+    }
+
+    var secrets corev1.SecretList
+    if err := c.List(ctx, &secrets, client.InNamespace(namespace),
+        client.MatchingLabels{"app.kubernetes.io/part-of": "qraiop"}); err != nil {
+        return err
+    }
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "\nSECRET\tSUBJECT\tISSUER\tNOT AFTER\tEXPIRES IN\tREVOKED")
+    for _, s := range secrets.Items {
+        if s.Type != corev1.SecretTypeTLS {
+            continue
+        }
+        block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+        if block == nil {
+            fmt.Fprintf(tw, "%s\t<invalid>\t\t\t\t\n", s.Name)
+            continue
+        }
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            fmt.Fprintf(tw, "%s\t<invalid: %v>\t\t\t\t\n", s.Name, err)
+            continue
+        }
+        left := "expired"
+        if remaining := time.Until(cert.NotAfter); remaining > 0 {
+            left = remaining.Round(time.Hour).String()
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, cert.Subject.CommonName, cert.Issuer.CommonName,
+            cert.NotAfter.Format(time.RFC3339), left, orNone(revoked[cert.SerialNumber.Text(16)]))
+    }
+    return tw.Flush()
+}
+
+// printForecast lists the certificates expiring within each instance's
+// forecast, soonest first, with what breaks when they do
+func printForecast(instances []qraiopv1.Qraiop) error {
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "INSTANCE\tSECRET\tNOT AFTER\tEXPIRES IN\tRENEWED\tCONSUMERS\tREASON")
+    for _, q := range instances {
+        st := q.Status.CertExpiry
+        if st == nil {
+            fmt.Fprintf(os.Stderr, "%s: no forecast, set spec.cryptography.expiryForecast\n", q.Name)
+            continue
+        }
+        for _, cert := range st.Certificates {
+            left := "expired"
+            if remaining := time.Until(cert.NotAfter.Time); remaining > 0 {
+                left = remaining.Round(time.Hour).String()
+            }
+            renewed := "no"
+            if cert.Covered {
+                renewed = "yes"
+            }
+            fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", q.Name, cert.Secret, cert.NotAfter.Format(time.RFC3339), left,
+                renewed, orNone(strings.Join(cert.Consumers, ",")), cert.Reason)
+        }
+    }
+    return tw.Flush()
+}
@@ -0,0 +1,52 @@
+// src/controllers/cmd/kubectl-qraiop/chaos.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "text/tabwriter"
+)
+
+// runChaos lists the chaos experiments in the namespace or aborts one
+func runChaos(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("chaos", flag.ExitOnError)
+    cl := addClusterFlags(fs)
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: kubectl qraiop chaos [-n namespace] list|abort <experiment>")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+
+    c, namespace, err := cl.client()
+    if err != nil {
+        return err
+    }
+    switch fs.Arg(0) {
+    case "", "list":
+        experiments, err := c.ListChaosExperiments(ctx, namespace)
+        if err != nil {
+            return err
+        }
+        tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(tw, "NAME\tTYPE\tPHASE\tVERDICT\tTARGETS\tAGE")
+        for _, exp := range experiments {
+            fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", exp.Name, exp.Spec.Type, orNone(exp.Status.Phase),
+                orNone(exp.Status.Verdict), strings.Join(exp.Status.Targets, ","), age(exp.CreationTimestamp))
+        }
+        return tw.Flush()
+    case "abort":
+        if fs.NArg() != 2 {
+            fs.Usage()
+            os.Exit(2)
+        }
+        if err := c.AbortChaosExperiment(ctx, namespace, fs.Arg(1)); err != nil {
+            return err
+        }
+        fmt.Printf("chaosexperiment %s/%s abort requested\n", namespace, fs.Arg(1))
+        return nil
+    }
+    return fmt.Errorf("unknown chaos command %q", fs.Arg(0))
+}
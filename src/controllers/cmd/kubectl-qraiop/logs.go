@@ -0,0 +1,41 @@
+// src/controllers/cmd/kubectl-qraiop/logs.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+// runLogs prints the logs of every pod of a component, each line prefixed
+// with its pod
+func runLogs(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("logs", flag.ExitOnError)
+    cl := addClusterFlags(fs)
+    follow := fs.Bool("f", false, "stream new log lines")
+    since := fs.Duration("since", 0, "only show lines newer than this, e.g. 10m (default: all)")
+    tail := fs.Int64("tail", -1, "lines of recent log to show per pod (default: all)")
+    instance := fs.String("instance", "", "Qraiop instance (default: any)")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: kubectl qraiop logs [-n namespace] [-f] [--since 10m] [--tail N] [--instance name] <component|cryptography/<pool>>")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    cfg, namespace, err := cl.config()
+    if err != nil {
+        return err
+    }
+    opts := qraiopclient.LogOptions{Instance: *instance, Follow: *follow, Since: *since}
+    if *tail >= 0 {
+        opts.TailLines = tail
+    }
+    return qraiopclient.StreamComponentLogs(ctx, cfg, namespace, fs.Arg(0), opts, os.Stdout)
+}
@@ -0,0 +1,116 @@
+// src/controllers/cmd/kubectl-qraiop/main.go
+
+// Command kubectl-qraiop is a kubectl plugin for operating QRAIOP. Installed
+// on the PATH it runs as "kubectl qraiop" against the user's kubeconfig:
+//
+//	kubectl qraiop status -n qraiop-system --watch
+//	kubectl qraiop logs cryptography -f
+//	kubectl qraiop chaos abort pod-kill-ai
+//	kubectl qraiop certs
+//	kubectl qraiop certs revoke --reason keyCompromise qraiop-ai-tls
+//	kubectl qraiop certs forecast
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "sort"
+
+    "k8s.io/client-go/rest"
+    "k8s.io/client-go/tools/clientcmd"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+type command struct {
+    summary string
+    run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+    "status": {summary: "show instance phase, conditions and components", run: runStatus},
+    "logs":   {summary: "print the logs of a component's pods", run: runLogs},
+    "chaos":  {summary: "list or abort chaos experiments", run: runChaos},
+    "certs":  {summary: "show the cryptography settings and TLS certificates, revoke one or forecast their expiry", run: runCerts},
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    cmd, ok := commands[os.Args[1]]
+    if !ok {
+        fmt.Fprintf(os.Stderr, "kubectl qraiop: unknown command %q\n", os.Args[1])
+        usage()
+        os.Exit(2)
+    }
+
+    ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer cancel()
+    if err := cmd.run(ctx, os.Args[2:]); err != nil {
+        fmt.Fprintln(os.Stderr, "kubectl qraiop:", err)
+        os.Exit(1)
+    }
+}
+
+func usage() {
+    fmt.Fprintln(os.Stderr, "usage: kubectl qraiop <command> [flags]")
+    fmt.Fprintln(os.Stderr)
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+    }
+}
+
+// cluster holds the kubectl-style connection flags every command accepts
+type cluster struct {
+    kubeconfig string
+    context    string
+    namespace  string
+}
+
+func addClusterFlags(fs *flag.FlagSet) *cluster {
+    c := &cluster{}
+    fs.StringVar(&c.kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+    fs.StringVar(&c.context, "context", "", "kubeconfig context to use")
+    fs.StringVar(&c.namespace, "n", "", "namespace (default: the context's namespace)")
+    fs.StringVar(&c.namespace, "namespace", "", "namespace (default: the context's namespace)")
+    return c
+}
+
+// config loads the user's kubeconfig the way kubectl does, honouring
+// KUBECONFIG, and resolves the namespace to work in
+func (c *cluster) config() (*rest.Config, string, error) {
+    rules := clientcmd.NewDefaultClientConfigLoadingRules()
+    rules.ExplicitPath = c.kubeconfig
+    loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: c.context})
+    cfg, err := loader.ClientConfig()
+    if err != nil {
+        return nil, "", err
+    }
+    namespace := c.namespace
+    if namespace == "" {
+        if namespace, _, err = loader.Namespace(); err != nil {
+            return nil, "", err
+        }
+    }
+    return cfg, namespace, nil
+}
+
+// client returns a qraiop.io client and the namespace to work in
+func (c *cluster) client() (*qraiopclient.Client, string, error) {
+    cfg, namespace, err := c.config()
+    if err != nil {
+        return nil, "", err
+    }
+    qc, err := qraiopclient.New(cfg)
+    return qc, namespace, err
+}
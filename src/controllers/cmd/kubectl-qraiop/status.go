@@ -0,0 +1,175 @@
+// src/controllers/cmd/kubectl-qraiop/status.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "text/tabwriter"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/watch"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+// runStatus prints the status of the instances in the namespace, or of the
+// named one. With --watch it keeps running and prints every component and
+// condition transition as the controller records it.
+func runStatus(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("status", flag.ExitOnError)
+    cl := addClusterFlags(fs)
+    watchFlag := fs.Bool("watch", false, "print component and condition changes as they happen")
+    fs.BoolVar(watchFlag, "w", false, "shorthand for --watch")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: kubectl qraiop status [-n namespace] [--watch] [instance]")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    name := fs.Arg(0)
+
+    cfg, namespace, err := cl.config()
+    if err != nil {
+        return err
+    }
+    scheme, err := qraiopclient.NewScheme()
+    if err != nil {
+        return err
+    }
+    c, err := client.NewWithWatch(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return err
+    }
+
+    var list qraiopv1.QraiopList
+    if err := c.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+        return err
+    }
+    seen := make(map[string]*qraiopv1.Qraiop)
+    for i := range list.Items {
+        q := &list.Items[i]
+        if name != "" && q.Name != name {
+            continue
+        }
+        printStatus(os.Stdout, q)
+        seen[q.Name] = q
+    }
+    if name != "" && seen[name] == nil {
+        return fmt.Errorf("qraiop %s/%s not found", namespace, name)
+    }
+    if !*watchFlag {
+        return nil
+    }
+
+    w, err := c.Watch(ctx, &qraiopv1.QraiopList{}, client.InNamespace(namespace),
+        &client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: list.ResourceVersion}})
+    if err != nil {
+        return err
+    }
+    defer w.Stop()
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    for {
+        select {
+        case <-ctx.Done():
+            return nil
+        case ev, ok := <-w.ResultChan():
+            if !ok {
+                return fmt.Errorf("watch closed by the server")
+            }
+            q, isQraiop := ev.Object.(*qraiopv1.Qraiop)
+            if !isQraiop || (name != "" && q.Name != name) {
+                continue
+            }
+            switch ev.Type {
+            case watch.Added, watch.Modified:
+                printTransitions(tw, seen[q.Name], q)
+                seen[q.Name] = q
+            case watch.Deleted:
+                fmt.Fprintf(tw, "%s\t%s\tinstance\tdeleted\t\n", time.Now().Format(time.TimeOnly), q.Name)
+                delete(seen, q.Name)
+            }
+            tw.Flush()
+        }
+    }
+}
+
+func printStatus(out io.Writer, q *qraiopv1.Qraiop) {
+    fmt.Fprintf(out, "%s/%s  phase=%s  generation=%d/%d\n", q.Namespace, q.Name,
+        orNone(q.Status.Phase), q.Status.ObservedGeneration, q.Generation)
+    if q.Status.Message != "" {
+        fmt.Fprintf(out, "  %s\n", q.Status.Message)
+    }
+
+    tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "\nCONDITION\tSTATUS\tREASON\tAGE\tMESSAGE")
+    for _, c := range q.Status.Conditions {
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, age(c.LastTransitionTime), c.Message)
+    }
+    fmt.Fprintln(tw, "\nCOMPONENT\tSTATUS\tAGE\tMESSAGE")
+    for _, name := range componentNames(q) {
+        cs := q.Status.Components[name]
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, cs.Status, age(cs.LastUpdated), cs.Message)
+    }
+    tw.Flush()
+    fmt.Fprintln(out)
+}
+
+// printTransitions prints what changed between two observations of an instance
+func printTransitions(out io.Writer, prev, cur *qraiopv1.Qraiop) {
+    now := time.Now().Format(time.TimeOnly)
+    if prev == nil {
+        fmt.Fprintf(out, "%s\t%s\tinstance\tadded\t%s\n", now, cur.Name, cur.Status.Phase)
+        prev = &qraiopv1.Qraiop{}
+    }
+    if prev.Status.Phase != cur.Status.Phase {
+        fmt.Fprintf(out, "%s\t%s\tphase\t%s -> %s\t%s\n", now, cur.Name,
+            orNone(prev.Status.Phase), cur.Status.Phase, cur.Status.Message)
+    }
+    for _, c := range cur.Status.Conditions {
+        var before string
+        for _, p := range prev.Status.Conditions {
+            if p.Type == c.Type {
+                before = string(p.Status) + "/" + p.Reason
+            }
+        }
+        if after := string(c.Status) + "/" + c.Reason; after != before {
+            fmt.Fprintf(out, "%s\t%s\tcondition/%s\t%s -> %s\t%s\n", now, cur.Name, c.Type, orNone(before), after, c.Message)
+        }
+    }
+    for _, name := range componentNames(cur) {
+        before, after := prev.Status.Components[name], cur.Status.Components[name]
+        if before.Status != after.Status || before.Message != after.Message {
+            fmt.Fprintf(out, "%s\t%s\tcomponent/%s\t%s -> %s\t%s\n", now, cur.Name, name,
+                orNone(before.Status), after.Status, after.Message)
+        }
+    }
+}
+
+func componentNames(q *qraiopv1.Qraiop) []string {
+    names := make([]string, 0, len(q.Status.Components))
+    for name := range q.Status.Components {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func age(t metav1.Time) string {
+    if t.IsZero() {
+        return "-"
+    }
+    return time.Since(t.Time).Round(time.Second).String()
+}
+
+func orNone(s string) string {
+    if s == "" {
+        return "<none>"
+    }
+    return s
+}
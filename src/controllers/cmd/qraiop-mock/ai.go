@@ -0,0 +1,48 @@
+// src/controllers/cmd/qraiop-mock/ai.go
+package main
+
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// aiMock answers the calls the operator makes to the AI orchestration
+// service. It never runs tasks, so it reports no activity since it started.
+type aiMock struct {
+    mu        sync.Mutex
+    started   time.Time
+    incidents int
+}
+
+func newAIMock() *aiMock {
+    return &aiMock{started: time.Now()}
+}
+
+func (m *aiMock) register(mux *http.ServeMux) {
+    mux.HandleFunc("/v1/tasks/activity", m.activity)
+    mux.HandleFunc("/v1/security/incidents", m.incident)
+}
+
+func (m *aiMock) activity(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "activeTasks": 0,
+        "lastTaskAt":  m.started,
+    })
+}
+
+// incident logs a security incident instead of triaging it
+func (m *aiMock) incident(w http.ResponseWriter, r *http.Request) {
+    var incident json.RawMessage
+    if !readJSON(w, r, &incident) {
+        return
+    }
+    m.mu.Lock()
+    m.incidents++
+    n := m.incidents
+    m.mu.Unlock()
+    log.Printf("security incident #%d: %s", n, incident)
+    writeJSON(w, http.StatusAccepted, map[string]interface{}{"id": n})
+}
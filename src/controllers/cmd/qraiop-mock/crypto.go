@@ -0,0 +1,288 @@
+// src/controllers/cmd/qraiop-mock/crypto.go
+package main
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "math/big"
+    "net"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// mockAlgorithms are reported as supported besides those the operator
+// configured the service with, so algorithm negotiation always succeeds
+var mockAlgorithms = []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024", "ML-DSA-44", "ML-DSA-65", "ML-DSA-87"}
+
+// cryptoMock implements the crypto service API with an in-memory ECDSA CA
+type cryptoMock struct {
+    mu        sync.Mutex
+    caKey     *ecdsa.PrivateKey
+    ca        *x509.Certificate
+    caPEM     string
+    keyID     int
+    usage     map[string]map[string]uint64
+    crl       []byte
+    idempoted map[string][]byte
+}
+
+func newCryptoMock() (*cryptoMock, error) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "QRAIOP mock CA"},
+        NotBefore:             time.Now().Add(-time.Minute),
+        NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+        KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+    if err != nil {
+        return nil, err
+    }
+    ca, err := x509.ParseCertificate(der)
+    if err != nil {
+        return nil, err
+    }
+    return &cryptoMock{
+        caKey:     key,
+        ca:        ca,
+        caPEM:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+        keyID:     1,
+        usage:     make(map[string]map[string]uint64),
+        idempoted: make(map[string][]byte),
+    }, nil
+}
+
+func (m *cryptoMock) register(mux *http.ServeMux) {
+    mux.HandleFunc("/v1/capabilities", m.capabilities)
+    mux.HandleFunc("/v1/certificates", m.idempotent(m.certificates))
+    mux.HandleFunc("/v1/keys/usage", m.keyUsage)
+    mux.HandleFunc("/v1/keys/rotate", m.idempotent(m.rotate))
+    mux.HandleFunc("/v1/keys/retire", m.idempotent(m.retire))
+    mux.HandleFunc("/v1/crl", m.revocationList)
+}
+
+// idempotent answers a repeated Idempotency-Key with the first response
+func (m *cryptoMock) idempotent(h http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        key := r.Header.Get(cryptoclient.IdempotencyKeyHeader)
+        if key == "" {
+            h(w, r)
+            return
+        }
+        m.mu.Lock()
+        body, seen := m.idempoted[key]
+        m.mu.Unlock()
+        if seen {
+            w.Header().Set("Content-Type", "application/json")
+            _, _ = w.Write(body)
+            return
+        }
+        rec := &recorder{ResponseWriter: w}
+        h(rec, r)
+        if rec.status < 300 {
+            m.mu.Lock()
+            m.idempoted[key] = rec.body
+            m.mu.Unlock()
+        }
+    }
+}
+
+type recorder struct {
+    http.ResponseWriter
+    status int
+    body   []byte
+}
+
+func (r *recorder) WriteHeader(status int) {
+    r.status = status
+    r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+    r.body = append(r.body, b...)
+    return r.ResponseWriter.Write(b)
+}
+
+func (m *cryptoMock) currentKey() string {
+    return fmt.Sprintf("mock-%d", m.keyID)
+}
+
+// count records an operation on the current key
+func (m *cryptoMock) count(op string) {
+    key := m.currentKey()
+    if m.usage[key] == nil {
+        m.usage[key] = make(map[string]uint64)
+    }
+    m.usage[key][op]++
+}
+
+func (m *cryptoMock) capabilities(w http.ResponseWriter, r *http.Request) {
+    algorithms := append([]string(nil), mockAlgorithms...)
+    for _, a := range strings.Split(os.Getenv("QRAIOP_ALGORITHMS"), ",") {
+        if a = strings.TrimSpace(a); a != "" {
+            algorithms = append(algorithms, a)
+        }
+    }
+    writeJSON(w, http.StatusOK, cryptoclient.Capabilities{APIVersion: "v1", Algorithms: algorithms})
+}
+
+func (m *cryptoMock) certificates(w http.ResponseWriter, r *http.Request) {
+    var req cryptoclient.CertificateRequest
+    if !readJSON(w, r, &req) {
+        return
+    }
+    ttl := 24 * time.Hour
+    if req.TTLSeconds > 0 {
+        ttl = time.Duration(req.TTLSeconds) * time.Second
+    }
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber: serial,
+        Subject:      pkix.Name{CommonName: req.CommonName},
+        DNSNames:     req.DNSNames,
+        NotBefore:    time.Now().Add(-time.Minute),
+        NotAfter:     time.Now().Add(ttl),
+        KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+        ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+    }
+    for _, ip := range req.IPAddresses {
+        if parsed := net.ParseIP(ip); parsed != nil {
+            tmpl.IPAddresses = append(tmpl.IPAddresses, parsed)
+        }
+    }
+
+    m.mu.Lock()
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, m.ca, &key.PublicKey, m.caKey)
+    m.count("sign")
+    m.mu.Unlock()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    writeJSON(w, http.StatusOK, cryptoclient.Certificate{
+        Certificate:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+        PrivateKey:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+        CACertificate: m.caPEM,
+    })
+}
+
+func (m *cryptoMock) keyUsage(w http.ResponseWriter, r *http.Request) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    keys := make([]cryptoclient.KeyUsage, 0, len(m.usage))
+    for id, ops := range m.usage {
+        copied := make(map[string]uint64, len(ops))
+        for op, n := range ops {
+            copied[op] = n
+        }
+        keys = append(keys, cryptoclient.KeyUsage{KeyID: id, Algorithm: "ECDSA-P256", Operations: copied})
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+func (m *cryptoMock) rotate(w http.ResponseWriter, r *http.Request) {
+    var in struct {
+        OverlapSeconds int64 `json:"overlapSeconds"`
+    }
+    if !readJSON(w, r, &in) {
+        return
+    }
+    m.mu.Lock()
+    previous := m.currentKey()
+    m.keyID++
+    current := m.currentKey()
+    m.mu.Unlock()
+    writeJSON(w, http.StatusOK, cryptoclient.Rotation{KeyID: current, PreviousKeyID: previous})
+}
+
+func (m *cryptoMock) retire(w http.ResponseWriter, r *http.Request) {
+    var in struct {
+        KeyID string `json:"keyId"`
+    }
+    if !readJSON(w, r, &in) {
+        return
+    }
+    m.mu.Lock()
+    delete(m.usage, in.KeyID)
+    m.mu.Unlock()
+    writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// revocationList signs a posted list, or serves the last one signed
+func (m *cryptoMock) revocationList(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodGet {
+        m.mu.Lock()
+        crl := m.crl
+        m.mu.Unlock()
+        if crl == nil {
+            http.NotFound(w, r)
+            return
+        }
+        w.Header().Set("Content-Type", "application/pkix-crl")
+        _, _ = w.Write(crl)
+        return
+    }
+    var list cryptoclient.RevocationList
+    if !readJSON(w, r, &list) {
+        return
+    }
+    tmpl := &x509.RevocationList{
+        Number:     big.NewInt(list.Number),
+        ThisUpdate: list.ThisUpdate,
+        NextUpdate: list.NextUpdate,
+    }
+    for _, c := range list.Revoked {
+        serial, ok := new(big.Int).SetString(strings.ReplaceAll(c.SerialNumber, ":", ""), 16)
+        if !ok {
+            http.Error(w, "invalid serial number "+c.SerialNumber, http.StatusBadRequest)
+            return
+        }
+        tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+            SerialNumber:   serial,
+            RevocationTime: c.RevokedAt,
+        })
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    der, err := x509.CreateRevocationList(rand.Reader, tmpl, m.ca, m.caKey)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    m.count("sign")
+    m.crl = pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+    body, _ := json.Marshal(map[string]string{"crl": string(m.crl)})
+    w.Header().Set("Content-Type", "application/json")
+    _, _ = w.Write(body)
+}
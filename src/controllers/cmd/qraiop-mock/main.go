@@ -0,0 +1,81 @@
+// src/controllers/cmd/qraiop-mock/main.go
+
+// Command qraiop-mock stands in for the QRAIOP component images when the
+// operator runs with --dev-mode, so the reconcile and status lifecycle can
+// be exercised on kind without pulling the real crypto and AI images:
+//
+//	qraiop-mock -component crypto
+//	qraiop-mock -component ai -addr :8080
+//
+// The crypto mock implements the crypto service API the operator calls
+// with a classical ECDSA CA; its certificates are not post-quantum. The
+// AI mock answers the task activity and security incident calls. The
+// chaos and monitoring mocks only serve health checks.
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "log"
+    "net/http"
+    "os"
+    "sync/atomic"
+)
+
+func main() {
+    component := flag.String("component", os.Getenv("QRAIOP_MOCK_COMPONENT"), "component to mock: crypto, ai, chaos or monitoring")
+    addr := flag.String("addr", ":8080", "address to serve on")
+    flag.Parse()
+
+    mux := http.NewServeMux()
+    var requests atomic.Int64
+    mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, "ok") })
+    mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, "ok") })
+    mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+        fmt.Fprintf(w, "# HELP qraiop_mock_requests_total Requests served by the mock.\n# TYPE qraiop_mock_requests_total counter\nqraiop_mock_requests_total{component=%q} %d\n",
+            *component, requests.Load())
+    })
+
+    switch *component {
+    case "crypto":
+        s, err := newCryptoMock()
+        if err != nil {
+            log.Fatalf("qraiop-mock: %v", err)
+        }
+        s.register(mux)
+    case "ai":
+        newAIMock().register(mux)
+    case "chaos", "monitoring":
+    default:
+        log.Fatalf("qraiop-mock: unknown component %q", *component)
+    }
+
+    handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests.Add(1)
+        log.Printf("%s %s", r.Method, r.URL.Path)
+        mux.ServeHTTP(w, r)
+    })
+    log.Printf("mocking %s on %s", *component, *addr)
+    log.Fatal(http.ListenAndServe(*addr, handler))
+}
+
+// writeJSON answers with v as JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes the request body into v, answering 400 when it can't
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+    if r.Method != http.MethodPost {
+        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        return false
+    }
+    if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return false
+    }
+    return true
+}
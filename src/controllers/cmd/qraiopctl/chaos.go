@@ -0,0 +1,188 @@
+// src/controllers/cmd/qraiopctl/chaos.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/yaml"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+// runChaos groups the commands about chaos experiments
+func runChaos(ctx context.Context, args []string) error {
+    if len(args) > 0 {
+        switch args[0] {
+        case "import":
+            return runChaosImport(args[1:])
+        case "export":
+            return runChaosExport(ctx, args[1:])
+        }
+    }
+    return runChaosTargets(ctx, args)
+}
+
+// runChaosTargets lists the pods and nodes an experiment targets
+func runChaosTargets(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("chaos targets", flag.ExitOnError)
+    namespace := fs.String("n", "default", "namespace of the experiment")
+    file := fs.String("f", "", "resolve the experiment in this manifest instead of one in the cluster")
+    live := fs.Bool("live", false, "resolve the target now instead of showing the resolution recorded in the status")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl chaos targets [-n namespace] [-live] <experiment>")
+        fmt.Fprintln(fs.Output(), "       qraiopctl chaos targets -f experiment.yaml")
+        fmt.Fprintln(fs.Output(), "       qraiopctl chaos import [-n namespace] experiment.json")
+        fmt.Fprintln(fs.Output(), "       qraiopctl chaos export [-n namespace] [-f experiment.yaml] [<experiment>]")
+        fs.PrintDefaults()
+    }
+    if len(args) == 0 || args[0] != "targets" {
+        fs.Usage()
+        os.Exit(2)
+    }
+    _ = fs.Parse(args[1:])
+    if (*file == "") == (fs.NArg() != 1) {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    var exp *qraiopv1.ChaosExperiment
+    if *file != "" {
+        raw, err := os.ReadFile(*file)
+        if err != nil {
+            return err
+        }
+        exp = &qraiopv1.ChaosExperiment{}
+        if err := yaml.UnmarshalStrict(raw, exp); err != nil {
+            return fmt.Errorf("%s: %w", *file, err)
+        }
+        *live = true
+    } else if exp, err = c.GetChaosExperiment(ctx, *namespace, fs.Arg(0)); err != nil {
+        return err
+    }
+
+    res := exp.Status.Resolution
+    if *live || res == nil {
+        seed := chaos.Seed(exp)
+        pods, targets, err := chaos.ResolveTargets(ctx, c, exp, seed)
+        if err != nil {
+            return err
+        }
+        res = chaos.Resolution(pods, targets, seed, metav1.Now())
+        fmt.Printf("Target of %s resolved now:\n", exp.Name)
+    } else {
+        fmt.Printf("Target of %s as resolved at %s:\n", exp.Name, res.ResolvedAt.Format("2006-01-02 15:04:05 MST"))
+        if exp.Status.IsFinished() || exp.Status.Phase == qraiopv1.ExperimentRunning {
+            fmt.Printf("(the experiment is %s; -live shows what the target selects today)\n", exp.Status.Phase)
+        }
+    }
+    fmt.Printf("%d of %d running pods matching %v in %s, drawn with seed %d\n\n", len(res.Pods), res.Matched,
+        exp.Spec.Target.Selector, exp.Spec.Target.Namespace, res.Seed)
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "POD\tNODE\tREADY")
+    for _, p := range res.Pods {
+        fmt.Fprintf(tw, "%s\t%s\t%t\n", p.Name, p.Node, p.Ready)
+    }
+    if err := tw.Flush(); err != nil {
+        return err
+    }
+    if chaos.NodeScoped(exp.Spec.Type) {
+        fmt.Printf("\n%s puts pressure on nodes %v\n", exp.Spec.Type, res.Nodes)
+    }
+    return nil
+}
+
+// runChaosImport prints the ChaosExperiment of a Chaos Toolkit experiment
+func runChaosImport(args []string) error {
+    fs := flag.NewFlagSet("chaos import", flag.ExitOnError)
+    namespace := fs.String("n", "", "namespace of the experiment; defaults to the namespace of its target")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl chaos import [-n namespace] experiment.json")
+        fmt.Fprintln(fs.Output(), "Prints the ChaosExperiment to apply; what doesn't map is reported on stderr.")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    raw, err := os.ReadFile(fs.Arg(0))
+    if err != nil {
+        return err
+    }
+    var t chaos.ToolkitExperiment
+    if err := json.Unmarshal(raw, &t); err != nil {
+        return fmt.Errorf("%s: %w", fs.Arg(0), err)
+    }
+    exp, warnings, err := chaos.FromToolkit(&t, *namespace)
+    for _, w := range warnings {
+        fmt.Fprintln(os.Stderr, "warning:", w)
+    }
+    if err != nil {
+        return fmt.Errorf("%s: %w", fs.Arg(0), err)
+    }
+    out, err := yaml.Marshal(exp)
+    if err != nil {
+        return err
+    }
+    _, err = os.Stdout.Write(out)
+    return err
+}
+
+// runChaosExport prints an experiment in the Chaos Toolkit format
+func runChaosExport(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("chaos export", flag.ExitOnError)
+    namespace := fs.String("n", "default", "namespace of the experiment")
+    file := fs.String("f", "", "export the experiment in this manifest instead of one in the cluster")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl chaos export [-n namespace] <experiment>")
+        fmt.Fprintln(fs.Output(), "       qraiopctl chaos export -f experiment.yaml")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if (*file == "") == (fs.NArg() != 1) {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    exp := &qraiopv1.ChaosExperiment{}
+    if *file != "" {
+        raw, err := os.ReadFile(*file)
+        if err != nil {
+            return err
+        }
+        if err := yaml.UnmarshalStrict(raw, exp); err != nil {
+            return fmt.Errorf("%s: %w", *file, err)
+        }
+    } else {
+        c, err := newClient()
+        if err != nil {
+            return err
+        }
+        if exp, err = c.GetChaosExperiment(ctx, *namespace, fs.Arg(0)); err != nil {
+            return err
+        }
+    }
+
+    t, warnings := chaos.ToToolkit(exp)
+    for _, w := range warnings {
+        fmt.Fprintln(os.Stderr, "warning:", w)
+    }
+    out, err := json.MarshalIndent(t, "", "  ")
+    if err != nil {
+        return err
+    }
+    fmt.Println(string(out))
+    return nil
+}
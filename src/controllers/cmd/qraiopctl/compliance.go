@@ -0,0 +1,179 @@
+// src/controllers/cmd/qraiopctl/compliance.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// complianceReportName is the file name of the compliance report, before
+// the format's extension
+const complianceReportName = "compliance"
+
+// runCompliance writes the crypto compliance report of the cluster, or
+// signs or verifies report files. The scheduled scans of
+// spec.complianceReport run it.
+func runCompliance(ctx context.Context, args []string) error {
+    if len(args) > 0 {
+        switch args[0] {
+        case "sign":
+            return runComplianceSign(args[1:])
+        case "verify":
+            return runComplianceVerify(args[1:])
+        }
+    }
+
+    fs := flag.NewFlagSet("compliance", flag.ExitOnError)
+    formats := fs.String("format", "json", "comma separated report formats: json, html")
+    output := fs.String("o", "", "directory to write the reports to (default: json to stdout)")
+    key := fs.String("key", "", "PEM private key to sign each report with")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl compliance [--format json,html] [-o dir] [-key key.pem]")
+        fmt.Fprintln(fs.Output(), "       qraiopctl compliance sign -key key.pem file...")
+        fmt.Fprintln(fs.Output(), "       qraiopctl compliance verify -pub key.pem file...")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if *output == "" && (*formats != "json" || *key != "") {
+        return fmt.Errorf("-o is required for formats other than json and for signing")
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    instances, err := c.ListQraiops(ctx, "")
+    if err != nil {
+        return err
+    }
+    var policies qraiopv1.QraiopSecurityPolicyList
+    if err := c.List(ctx, &policies); err != nil {
+        return err
+    }
+    experiments, err := c.ListChaosExperiments(ctx, "")
+    if err != nil {
+        return err
+    }
+    var secrets corev1.SecretList
+    if err := c.List(ctx, &secrets, client.MatchingLabels{"app.kubernetes.io/part-of": "qraiop"}); err != nil {
+        return err
+    }
+    compliance := report.Audit(instances, policies.Items, experiments, secrets.Items, time.Now())
+
+    if *output == "" {
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetIndent("", "  ")
+        return enc.Encode(compliance)
+    }
+    var keyPEM []byte
+    if *key != "" {
+        if keyPEM, err = os.ReadFile(*key); err != nil {
+            return err
+        }
+    }
+    if err := os.MkdirAll(*output, 0o755); err != nil {
+        return err
+    }
+    for _, format := range strings.Split(*formats, ",") {
+        var data []byte
+        switch format = strings.TrimSpace(format); format {
+        case qraiopv1.ComplianceFormatJSON:
+            data, err = json.MarshalIndent(compliance, "", "  ")
+        case qraiopv1.ComplianceFormatHTML:
+            data, err = compliance.HTML()
+        default:
+            return fmt.Errorf("unknown format %q", format)
+        }
+        if err != nil {
+            return err
+        }
+        path := filepath.Join(*output, complianceReportName+"."+format)
+        if err := os.WriteFile(path, data, 0o644); err != nil {
+            return err
+        }
+        if keyPEM != nil {
+            if err := signFile(path, keyPEM); err != nil {
+                return err
+            }
+        }
+        fmt.Fprintln(os.Stderr, "wrote", path)
+    }
+    return nil
+}
+
+// runComplianceSign signs report files made outside qraiopctl, such as
+// the PDF rendering of the HTML report
+func runComplianceSign(args []string) error {
+    fs := flag.NewFlagSet("compliance sign", flag.ExitOnError)
+    key := fs.String("key", "", "PEM private key to sign with")
+    _ = fs.Parse(args)
+    if *key == "" || fs.NArg() == 0 {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl compliance sign -key key.pem file...")
+        os.Exit(2)
+    }
+    keyPEM, err := os.ReadFile(*key)
+    if err != nil {
+        return err
+    }
+    for _, path := range fs.Args() {
+        if err := signFile(path, keyPEM); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// runComplianceVerify checks report files against their signatures
+func runComplianceVerify(args []string) error {
+    fs := flag.NewFlagSet("compliance verify", flag.ExitOnError)
+    pub := fs.String("pub", "", "PEM public key or certificate of the signing key")
+    _ = fs.Parse(args)
+    if *pub == "" || fs.NArg() == 0 {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl compliance verify -pub key.pem file...")
+        os.Exit(2)
+    }
+    pubPEM, err := os.ReadFile(*pub)
+    if err != nil {
+        return err
+    }
+    for _, path := range fs.Args() {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return err
+        }
+        sig, err := os.ReadFile(path + report.SignatureSuffix)
+        if err != nil {
+            return err
+        }
+        if err := report.Verify(data, sig, pubPEM); err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+        fmt.Println(path + ": signature verified")
+    }
+    return nil
+}
+
+// signFile writes the detached signature of a file next to it
+func signFile(path string, keyPEM []byte) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    sig, err := report.Sign(data, keyPEM)
+    if err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    return os.WriteFile(path+report.SignatureSuffix, sig, 0o644)
+}
@@ -0,0 +1,64 @@
+// src/controllers/cmd/qraiopctl/explain.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "text/tabwriter"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// runExplain shows which fields of the owning Qraiop instance, or operator
+// defaults, produced the fields of a managed object
+func runExplain(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("explain", flag.ExitOnError)
+    namespace := fs.String("n", "qraiop-system", "namespace of the object")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl explain [-n namespace] <kind>/<name>")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    kind, name, ok := strings.Cut(fs.Arg(0), "/")
+    if fs.NArg() != 1 || !ok || name == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    gvk, err := c.RESTMapper().KindFor(schema.GroupVersionResource{Resource: strings.ToLower(kind)})
+    if err != nil {
+        return fmt.Errorf("unknown kind %q: %w", kind, err)
+    }
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(gvk)
+    if err := c.Get(ctx, client.ObjectKey{Namespace: *namespace, Name: name}, obj); err != nil {
+        return err
+    }
+
+    sources := render.Provenance(obj)
+    if len(sources) == 0 {
+        return fmt.Errorf("%s/%s has no provenance; it isn't rendered by QRAIOP or predates explain support", kind, name)
+    }
+    for _, ref := range obj.GetOwnerReferences() {
+        if ref.Kind == "Qraiop" {
+            fmt.Printf("%s %s/%s is rendered from Qraiop %s/%s\n\n", gvk.Kind, *namespace, name, *namespace, ref.Name)
+        }
+    }
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "FIELD\tVALUE\tFROM")
+    for _, s := range sources {
+        fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Field, s.Value, s.From)
+    }
+    return tw.Flush()
+}
@@ -0,0 +1,123 @@
+// src/controllers/cmd/qraiopctl/fleet.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+    "text/tabwriter"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// runFleet summarizes every Qraiop instance of the cluster: their phases,
+// the ones needing attention, the first certificate to expire, the
+// experiments run this week and the resilience of each namespace
+func runFleet(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("fleet", flag.ExitOnError)
+    format := fs.String("o", "text", "output format: text or json")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl fleet [-o text|json]")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    instances, err := c.ListQraiops(ctx, "")
+    if err != nil {
+        return err
+    }
+    experiments, err := c.ListChaosExperiments(ctx, "")
+    if err != nil {
+        return err
+    }
+    var secrets corev1.SecretList
+    if err := c.List(ctx, &secrets, client.MatchingLabels{"app.kubernetes.io/part-of": "qraiop"}); err != nil {
+        return err
+    }
+    f := report.Summarize(instances, experiments, secrets.Items, time.Now())
+
+    switch *format {
+    case "json":
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetIndent("", "  ")
+        return enc.Encode(f)
+    case "text":
+    default:
+        return fmt.Errorf("unknown format %q", *format)
+    }
+
+    fmt.Printf("%d instances in %d namespaces: %s\n", f.Instances, f.Namespaces, counts(f.Phases))
+    if cert := f.OldestCert; cert != nil {
+        left := "expired"
+        if remaining := time.Until(cert.NotAfter); remaining > 0 {
+            left = "in " + remaining.Round(time.Hour).String()
+        }
+        fmt.Printf("first certificate to expire: %s/%s, %s (%s)\n", cert.Namespace, cert.Secret,
+            cert.NotAfter.Format(time.RFC3339), left)
+    }
+    fmt.Printf("chaos experiments this week: %d", f.Total())
+    if f.Total() > 0 {
+        fmt.Printf(" (%s)", counts(f.ExperimentsThisWeek))
+    }
+    fmt.Println()
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    if len(f.Resilience) > 0 {
+        fmt.Println()
+        fmt.Fprintln(tw, "NAMESPACE\tRESILIENCE\tEXPERIMENTS\tPASSED\tFAULT TYPES\tLAST RUN")
+        for _, nc := range f.Resilience {
+            last := "never"
+            if nc.LastRun != nil {
+                last = time.Since(*nc.LastRun).Round(time.Hour).String() + " ago"
+            }
+            fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", nc.Namespace, nc.Score, nc.Experiments, nc.Passed, len(nc.FaultTypes), last)
+        }
+        if err := tw.Flush(); err != nil {
+            return err
+        }
+    }
+    if len(f.Unhealthy) == 0 {
+        return nil
+    }
+
+    fmt.Println()
+    fmt.Fprintln(tw, "NAMESPACE\tINSTANCE\tPHASE\tSINCE\tMESSAGE")
+    for _, u := range f.Unhealthy {
+        since := "-"
+        if u.Since != nil {
+            since = time.Since(*u.Since).Round(time.Minute).String()
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", u.Namespace, u.Name, u.Phase, since, u.Message)
+    }
+    return tw.Flush()
+}
+
+// counts formats a count per key as "Ready 12, Degraded 1"
+func counts(m map[string]int) string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    s := ""
+    for i, k := range keys {
+        if i > 0 {
+            s += ", "
+        }
+        s += fmt.Sprintf("%s %d", k, m[k])
+    }
+    if s == "" {
+        return "none"
+    }
+    return s
+}
@@ -0,0 +1,58 @@
+// src/controllers/cmd/qraiopctl/get.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// runGet prints information recorded on Qraiop instances
+func runGet(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("get", flag.ExitOnError)
+    namespace := fs.String("n", "", "namespace of the instances (default: all namespaces)")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl get inventory [-n namespace] [instance]")
+        fs.PrintDefaults()
+    }
+    if len(args) == 0 || args[0] != "inventory" {
+        fs.Usage()
+        os.Exit(2)
+    }
+    _ = fs.Parse(args[1:])
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    var instances []qraiopv1.Qraiop
+    if fs.NArg() > 0 {
+        if *namespace == "" {
+            return fmt.Errorf("-n is required when naming an instance")
+        }
+        q, err := c.GetQraiop(ctx, *namespace, fs.Arg(0))
+        if err != nil {
+            return err
+        }
+        instances = append(instances, *q)
+    } else if instances, err = c.ListQraiops(ctx, *namespace); err != nil {
+        return err
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "INSTANCE\tKIND\tNAMESPACE\tNAME\tHASH")
+    for _, q := range instances {
+        for _, e := range q.Status.Inventory {
+            kind := e.Kind + "." + e.Version
+            if e.Group != "" {
+                kind += "." + e.Group
+            }
+            fmt.Fprintf(tw, "%s/%s\t%s\t%s\t%s\t%s\n", q.Namespace, q.Name, kind, e.Namespace, e.Name, e.Hash)
+        }
+    }
+    return tw.Flush()
+}
@@ -0,0 +1,50 @@
+// src/controllers/cmd/qraiopctl/logs.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+
+    ctrl "sigs.k8s.io/controller-runtime"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+// runLogs prints the logs of every pod of a component across its replicas,
+// each line prefixed with its pod
+func runLogs(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("logs", flag.ExitOnError)
+    namespace := fs.String("n", "qraiop-system", "namespace of the Qraiop instance")
+    follow := fs.Bool("follow", false, "stream new log lines")
+    fs.BoolVar(follow, "f", false, "shorthand for --follow")
+    since := fs.Duration("since", 0, "only show lines newer than this, e.g. 10m (default: all)")
+    tail := fs.Int64("tail", -1, "lines of recent log to show per pod (default: all)")
+    instance := fs.String("instance", "", "Qraiop instance (default: any)")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl logs [-n namespace] [--follow] [--since 10m] [--tail N] [--instance name] <component|crypto/<pool>>")
+        fmt.Fprintln(fs.Output(), "components: crypto, ai, chaos, monitoring or their spec names")
+        fs.PrintDefaults()
+    }
+    // Flags may also follow the component, as in logs crypto --follow
+    _ = fs.Parse(args)
+    component := fs.Arg(0)
+    if fs.NArg() > 0 {
+        _ = fs.Parse(fs.Args()[1:])
+    }
+    if component == "" || fs.NArg() != 0 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    cfg, err := ctrl.GetConfig()
+    if err != nil {
+        return err
+    }
+    opts := qraiopclient.LogOptions{Instance: *instance, Follow: *follow, Since: *since}
+    if *tail >= 0 {
+        opts.TailLines = tail
+    }
+    return qraiopclient.StreamComponentLogs(ctx, cfg, *namespace, component, opts, os.Stdout)
+}
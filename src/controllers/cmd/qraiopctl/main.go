@@ -0,0 +1,90 @@
+// src/controllers/cmd/qraiopctl/main.go
+
+// Command qraiopctl is a command line client for QRAIOP.
+//
+//	qraiopctl report -n chaos-tests --format junit -o results.xml
+//	qraiopctl get inventory -n qraiop-system production-cluster
+//	qraiopctl seal -n qraiop-system 's3cr3t'
+//	qraiopctl explain -n qraiop-system deployment/qraiop-crypto
+//	qraiopctl chaos targets -n chaos-tests pod-kill-frontend
+//	qraiopctl chaos import terminate-pods.json | kubectl apply -f -
+//	qraiopctl fleet -o json
+//	qraiopctl logs -n qraiop-system crypto --since 10m --follow
+//	qraiopctl orphans -adopt
+//	qraiopctl compliance --format json,html -o reports -key signing.pem
+//	qraiopctl validate configs/k8/qraiop-example.yml
+//	qraiopctl schema Qraiop > qraiop.schema.json
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/signal"
+    "sort"
+
+    ctrl "sigs.k8s.io/controller-runtime"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+type command struct {
+    summary string
+    run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+    "chaos":      {summary: "list the targets of a chaos experiment, or import and export it as Chaos Toolkit JSON", run: runChaos},
+    "compliance": {summary: "export a signed crypto compliance report of the cluster", run: runCompliance},
+    "explain":    {summary: "show which spec fields and defaults produced a managed object", run: runExplain},
+    "fleet":      {summary: "summarize all instances of the cluster for platform dashboards", run: runFleet},
+    "get":        {summary: "show the objects managed for Qraiop instances", run: runGet},
+    "logs":       {summary: "tail the logs of all pods of a component", run: runLogs},
+    "orphans":    {summary: "find, adopt or delete objects of instances that no longer exist", run: runOrphans},
+    "report":     {summary: "export chaos experiment results as JUnit XML or HTML", run: runReport},
+    "schema":     {summary: "print the JSON Schema of a qraiop.io kind", run: runSchema},
+    "seal":       {summary: "encrypt a value for use as qraiopsealed:// in a spec", run: runSeal},
+    "validate":   {summary: "check manifests against the CRD schemas offline", run: runValidate},
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    cmd, ok := commands[os.Args[1]]
+    if !ok {
+        fmt.Fprintf(os.Stderr, "qraiopctl: unknown command %q\n", os.Args[1])
+        usage()
+        os.Exit(2)
+    }
+
+    ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer cancel()
+    if err := cmd.run(ctx, os.Args[2:]); err != nil {
+        fmt.Fprintln(os.Stderr, "qraiopctl:", err)
+        os.Exit(1)
+    }
+}
+
+func usage() {
+    fmt.Fprintln(os.Stderr, "usage: qraiopctl <command> [flags]")
+    fmt.Fprintln(os.Stderr)
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+    }
+}
+
+// newClient returns a client for the cluster of the current kubeconfig context
+func newClient() (*qraiopclient.Client, error) {
+    cfg, err := ctrl.GetConfig()
+    if err != nil {
+        return nil, err
+    }
+    return qraiopclient.New(cfg)
+}
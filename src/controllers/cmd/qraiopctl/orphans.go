@@ -0,0 +1,79 @@
+// src/controllers/cmd/qraiopctl/orphans.go
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+)
+
+// runOrphans lists the objects created for Qraiop instances that no
+// longer exist, e.g. after the CRD was uninstalled, and adopts them into
+// recreated instances or deletes them
+func runOrphans(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("orphans", flag.ExitOnError)
+    namespace := fs.String("n", "", "namespace to look in (default: all namespaces)")
+    format := fs.String("o", "text", "output format: text or json")
+    adopt := fs.Bool("adopt", false, "adopt the orphans whose instance was recreated")
+    del := fs.Bool("delete", false, "delete the orphans no instance can adopt")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl orphans [-n namespace] [-o text|json] [-adopt] [-delete]")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    orphans, err := c.Orphans(ctx, *namespace)
+    if err != nil {
+        return err
+    }
+
+    if *adopt || *del {
+        for _, o := range orphans {
+            ref := fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+            switch {
+            case o.Adoptable && *adopt:
+                q, err := c.GetQraiop(ctx, o.Namespace, o.Instance)
+                if err != nil {
+                    return err
+                }
+                if err := c.Adopt(ctx, o, q); err != nil {
+                    return fmt.Errorf("adopting %s: %w", ref, err)
+                }
+                fmt.Printf("%s adopted by %s\n", ref, o.Instance)
+            case !o.Adoptable && *del:
+                if err := c.DeleteOrphan(ctx, o); err != nil {
+                    return fmt.Errorf("deleting %s: %w", ref, err)
+                }
+                fmt.Printf("%s deleted\n", ref)
+            }
+        }
+        return nil
+    }
+
+    switch *format {
+    case "json":
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetIndent("", "  ")
+        return enc.Encode(orphans)
+    case "text":
+    default:
+        return fmt.Errorf("unknown format %q", *format)
+    }
+    if len(orphans) == 0 {
+        fmt.Println("no orphaned objects")
+        return nil
+    }
+    tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "NAMESPACE\tKIND\tNAME\tINSTANCE\tADOPTABLE")
+    for _, o := range orphans {
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n", o.Namespace, o.Kind, o.Name, o.Instance, o.Adoptable)
+    }
+    return tw.Flush()
+}
@@ -0,0 +1,72 @@
+// src/controllers/cmd/qraiopctl/report.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// runReport writes a report of chaos experiments, all of them or the named
+// ones, so CI pipelines can gate on resilience tests
+func runReport(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("report", flag.ExitOnError)
+    namespace := fs.String("n", "", "namespace of the experiments (default: all namespaces)")
+    format := fs.String("format", "junit", "report format: junit or html")
+    output := fs.String("o", "", "file to write the report to (default: stdout)")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl report [-n namespace] [--format junit|html] [-o file] [experiment...]")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+
+    render := report.JUnit
+    switch *format {
+    case "junit":
+    case "html":
+        render = report.HTML
+    default:
+        return fmt.Errorf("unknown format %q", *format)
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    var experiments []qraiopv1.ChaosExperiment
+    if fs.NArg() > 0 {
+        if *namespace == "" {
+            return fmt.Errorf("-n is required when naming experiments")
+        }
+        for _, name := range fs.Args() {
+            exp, err := c.GetChaosExperiment(ctx, *namespace, name)
+            if err != nil {
+                return err
+            }
+            experiments = append(experiments, *exp)
+        }
+    } else if experiments, err = c.ListChaosExperiments(ctx, *namespace); err != nil {
+        return err
+    }
+    sort.Slice(experiments, func(i, j int) bool {
+        if experiments[i].Namespace != experiments[j].Namespace {
+            return experiments[i].Namespace < experiments[j].Namespace
+        }
+        return experiments[i].Name < experiments[j].Name
+    })
+
+    out, err := render(experiments)
+    if err != nil {
+        return err
+    }
+    if *output == "" {
+        _, err = os.Stdout.Write(out)
+        return err
+    }
+    return os.WriteFile(*output, out, 0o644)
+}
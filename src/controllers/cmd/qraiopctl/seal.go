@@ -0,0 +1,55 @@
+// src/controllers/cmd/qraiopctl/seal.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "github.com/Bailey7220/QRAIOP/controllers/sealed"
+)
+
+// runSeal encrypts a value with the cluster's sealing key. Only the operator
+// can unseal it, and only for instances in the namespace it was sealed for.
+func runSeal(ctx context.Context, args []string) error {
+    fs := flag.NewFlagSet("seal", flag.ExitOnError)
+    namespace := fs.String("n", "", "namespace of the instance the value is for")
+    keyNamespace := fs.String("key-namespace", "qraiop-system", "namespace the operator runs in")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl seal -n namespace [--key-namespace namespace] <value|->")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if *namespace == "" || fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    value := fs.Arg(0)
+    if value == "-" {
+        // Reading stdin keeps the plaintext out of shell history
+        data, err := io.ReadAll(os.Stdin)
+        if err != nil {
+            return err
+        }
+        value = strings.TrimSuffix(string(data), "\n")
+    }
+
+    c, err := newClient()
+    if err != nil {
+        return err
+    }
+    pub, err := c.SealingKey(ctx, *keyNamespace)
+    if err != nil {
+        return fmt.Errorf("reading sealing key: %w", err)
+    }
+    out, err := sealed.Seal(pub, *namespace, value)
+    if err != nil {
+        return err
+    }
+    fmt.Println(out)
+    return nil
+}
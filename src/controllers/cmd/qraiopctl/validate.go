@@ -0,0 +1,82 @@
+// src/controllers/cmd/qraiopctl/validate.go
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/schema"
+)
+
+// runValidate checks manifests against the CRD schemas without a cluster,
+// for CI. Warnings are printed but only errors fail the command.
+func runValidate(_ context.Context, args []string) error {
+    fs := flag.NewFlagSet("validate", flag.ExitOnError)
+    strict := fs.Bool("strict", false, "fail on warnings too, e.g. deprecated fields")
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl validate [--strict] <file|->...")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if fs.NArg() == 0 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    failed := 0
+    for _, file := range fs.Args() {
+        var data []byte
+        var err error
+        if file == "-" {
+            data, err = io.ReadAll(os.Stdin)
+        } else {
+            data, err = os.ReadFile(file)
+        }
+        if err != nil {
+            return err
+        }
+        problems, err := schema.ValidateManifests(data)
+        if err != nil {
+            return fmt.Errorf("%s: %w", file, err)
+        }
+        for _, p := range problems {
+            fmt.Printf("%s: %s\n", file, p)
+            if !p.Warning || *strict {
+                failed++
+            }
+        }
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d problem(s) found", failed)
+    }
+    return nil
+}
+
+// runSchema prints the JSON Schema of a kind, for editors and other
+// validators
+func runSchema(_ context.Context, args []string) error {
+    fs := flag.NewFlagSet("schema", flag.ExitOnError)
+    fs.Usage = func() {
+        fmt.Fprintln(fs.Output(), "usage: qraiopctl schema <kind>")
+        fs.PrintDefaults()
+    }
+    _ = fs.Parse(args)
+    if fs.NArg() != 1 {
+        kinds, err := schema.Kinds()
+        if err == nil {
+            fmt.Fprintln(fs.Output(), "kinds:", strings.Join(kinds, ", "))
+        }
+        fs.Usage()
+        os.Exit(2)
+    }
+    out, err := schema.JSONSchema(fs.Arg(0))
+    if err != nil {
+        return err
+    }
+    fmt.Println(string(out))
+    return nil
+}
@@ -0,0 +1,145 @@
+// src/controllers/compat/compat.go
+
+// Package compat checks the cluster against the Kubernetes versions and
+// APIs the operator supports, and picks the API versions to use on it.
+package compat
+
+import (
+    "fmt"
+    "strings"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    utilversion "k8s.io/apimachinery/pkg/util/version"
+    "k8s.io/client-go/discovery"
+)
+
+// MinKubernetesVersion is the oldest cluster the operator supports
+var MinKubernetesVersion = "1.21"
+
+// API is a resource the operator uses
+type API struct {
+    Resource string
+    // GroupVersions serve the resource, preferred first. Later ones are
+    // deprecated fallbacks for older clusters.
+    GroupVersions []string
+}
+
+// APIs are the resources whose version depends on the cluster
+var APIs = []API{
+    {Resource: "deployments", GroupVersions: []string{"apps/v1"}},
+    {Resource: "networkpolicies", GroupVersions: []string{"networking.k8s.io/v1"}},
+    {Resource: "leases", GroupVersions: []string{"coordination.k8s.io/v1"}},
+    {Resource: "poddisruptionbudgets", GroupVersions: []string{"policy/v1", "policy/v1beta1"}},
+}
+
+// Cluster is what the operator found out about the cluster it runs in
+type Cluster struct {
+    Version *utilversion.Version
+    // served maps resources to the group version used for them
+    served map[string]string
+    // eviction is the policy group version of Eviction bodies
+    eviction string
+}
+
+// Detect asks the API server for its version and the APIs it serves
+func Detect(d discovery.DiscoveryInterface) (*Cluster, error) {
+    info, err := d.ServerVersion()
+    if err != nil {
+        return nil, err
+    }
+    v, err := utilversion.ParseGeneric(info.GitVersion)
+    if err != nil {
+        return nil, fmt.Errorf("parsing server version %q: %w", info.GitVersion, err)
+    }
+    c := &Cluster{Version: v, served: make(map[string]string), eviction: "policy/v1"}
+
+    for _, api := range APIs {
+        for _, gv := range api.GroupVersions {
+            list, err := d.ServerResourcesForGroupVersion(gv)
+            if apierrors.IsNotFound(err) {
+                continue
+            }
+            if err != nil {
+                return nil, err
+            }
+            found := false
+            for _, r := range list.APIResources {
+                if r.Name == api.Resource {
+                    found = true
+                    break
+                }
+            }
+            if found {
+                c.served[api.Resource] = gv
+                break
+            }
+        }
+    }
+
+    core, err := d.ServerResourcesForGroupVersion("v1")
+    if err != nil {
+        return nil, err
+    }
+    for _, r := range core.APIResources {
+        if r.Name == "pods/eviction" && r.Group != "" && r.Version != "" {
+            c.eviction = r.Group + "/" + r.Version
+        }
+    }
+    return c, nil
+}
+
+// Check returns an error when the cluster is older than the operator
+// supports or lacks an API it needs
+func (c *Cluster) Check() error {
+    if c.Version.LessThan(utilversion.MustParseGeneric(MinKubernetesVersion)) {
+        return fmt.Errorf("Kubernetes %s is older than the minimum supported %s", c.Version, MinKubernetesVersion)
+    }
+    var missing []string
+    for _, api := range APIs {
+        if _, ok := c.served[api.Resource]; !ok {
+            missing = append(missing, api.Resource+" ("+strings.Join(api.GroupVersions, " or ")+")")
+        }
+    }
+    if len(missing) > 0 {
+        return fmt.Errorf("cluster does not serve %s", strings.Join(missing, ", "))
+    }
+    return nil
+}
+
+// Deprecations lists the deprecated API versions in use on this cluster
+func (c *Cluster) Deprecations() []string {
+    var deprecated []string
+    for _, api := range APIs {
+        if gv, ok := c.served[api.Resource]; ok && gv != api.GroupVersions[0] {
+            deprecated = append(deprecated, api.Resource+" "+gv)
+        }
+    }
+    if c.eviction != "policy/v1" {
+        deprecated = append(deprecated, "evictions "+c.eviction)
+    }
+    return deprecated
+}
+
+// GroupVersion returns the group version to use for a resource, or its
+// preferred one when the cluster wasn't asked
+func (c *Cluster) GroupVersion(resource string) string {
+    if c != nil {
+        if gv, ok := c.served[resource]; ok {
+            return gv
+        }
+    }
+    for _, api := range APIs {
+        if api.Resource == resource {
+            return api.GroupVersions[0]
+        }
+    }
+    return ""
+}
+
+// EvictionGroupVersion returns the group version of Eviction bodies
+func (c *Cluster) EvictionGroupVersion() string {
+    if c == nil {
+        return "policy/v1"
+    }
+    return c.eviction
+}
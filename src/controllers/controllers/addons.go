@@ -0,0 +1,220 @@
+// src/controllers/controllers/addons.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+
+    "k8s.io/apimachinery/pkg/api/meta"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// AddonLabel is set on every object of an addon, with the addon name as value
+const AddonLabel = "qraiop.io/addon"
+
+// addonRegistry remembers the addons of each instance, loaded at the start
+// of every reconcile, so they are listed with the built-in components
+type addonRegistry struct {
+    mu     sync.Mutex
+    addons map[types.NamespacedName][]qraiopv1.QraiopAddon
+}
+
+func newAddonRegistry() *addonRegistry {
+    return &addonRegistry{addons: make(map[types.NamespacedName][]qraiopv1.QraiopAddon)}
+}
+
+func (a *addonRegistry) set(key types.NamespacedName, addons []qraiopv1.QraiopAddon) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.addons[key] = addons
+}
+
+func (a *addonRegistry) get(key types.NamespacedName) []qraiopv1.QraiopAddon {
+    if a == nil {
+        return nil
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.addons[key]
+}
+
+func (a *addonRegistry) forget(key types.NamespacedName) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    delete(a.addons, key)
+}
+
+// addonComponent is the Status.Components key of an addon
+func addonComponent(name string) string {
+    return qraiopv1.AddonComponentPrefix + name
+}
+
+func addonDeployment(name string) string {
+    return "qraiop-addon-" + name
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiopaddons,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiopaddons/status,verbs=get;update;patch
+
+// loadAddons reads the addons extending q and drops the components of
+// removed addons from the status. Their objects are pruned with the
+// inventory once they are no longer written.
+func (r *QraiopReconciler) loadAddons(ctx context.Context, q *qraiopv1.Qraiop) error {
+    var list qraiopv1.QraiopAddonList
+    if err := r.List(ctx, &list, client.InNamespace(q.Namespace)); err != nil {
+        return err
+    }
+    var addons []qraiopv1.QraiopAddon
+    names := make(map[string]bool)
+    for _, a := range list.Items {
+        if a.Spec.Instance == q.Name && a.DeletionTimestamp == nil {
+            addons = append(addons, a)
+            names[addonComponent(a.Name)] = true
+        }
+    }
+    sort.Slice(addons, func(i, j int) bool { return addons[i].Name < addons[j].Name })
+    r.addons.set(client.ObjectKeyFromObject(q), addons)
+
+    for name := range q.Status.Components {
+        if strings.HasPrefix(name, qraiopv1.AddonComponentPrefix) && !names[name] {
+            delete(q.Status.Components, name)
+        }
+    }
+    return nil
+}
+
+// addonComponents lists the addons of q as components, after the built-in
+// ones. Image addons run as qraiop-addon-<name>; manifest addons report the
+// readiness of the Deployment they name.
+func (r *QraiopReconciler) addonComponents(q *qraiopv1.Qraiop) []component {
+    var components []component
+    for _, a := range r.addons.get(client.ObjectKeyFromObject(q)) {
+        a := a
+        deployment := addonDeployment(a.Name)
+        if len(a.Spec.Manifests) > 0 {
+            deployment = a.Spec.Deployment
+        }
+        components = append(components, component{
+            name:       addonComponent(a.Name),
+            deployment: deployment,
+            enabled:    true,
+            dependsOn:  a.Spec.DependsOn,
+            reconcile: func(ctx context.Context, q *qraiopv1.Qraiop) error {
+                return r.reconcileAddon(ctx, q, &a)
+            },
+        })
+    }
+    return components
+}
+
+func (r *QraiopReconciler) reconcileAddon(ctx context.Context, q *qraiopv1.Qraiop, a *qraiopv1.QraiopAddon) error {
+    if len(a.Spec.Manifests) > 0 {
+        return r.applyAddonManifests(ctx, q, a)
+    }
+    name := addonDeployment(a.Name)
+    path := componentSpecPath(addonComponent(a.Name))
+    deployment := componentDeployment(q, name, a.Spec.Env)
+    deployment.Labels[AddonLabel] = a.Name
+    deployment.Spec.Template.Labels[AddonLabel] = a.Name
+    container := &deployment.Spec.Template.Spec.Containers[0]
+    container.Image = a.Spec.Image
+    render.Explain(deployment, "spec.template.spec.containers[0].image", a.Spec.Image, path+".image")
+    if a.Spec.Port > 0 {
+        container.Ports[0].ContainerPort = a.Spec.Port
+        render.Explain(deployment, "spec.template.spec.containers[0].ports[http]", strconv.Itoa(int(a.Spec.Port)), path+".port")
+    }
+    if len(a.Spec.Args) > 0 {
+        container.Args = a.Spec.Args
+        render.Explain(deployment, "spec.template.spec.containers[0].args", strings.Join(a.Spec.Args, " "), path+".args")
+    }
+    for _, e := range a.Spec.Env {
+        render.Explain(deployment, "spec.template.spec.containers[0].env["+e.Name+"]", e.Value, path+".env")
+    }
+    if a.Spec.Resources != nil {
+        container.Resources = *a.Spec.Resources
+        render.Explain(deployment, "spec.template.spec.containers[0].resources", a.Spec.Resources.Limits.Cpu().String()+" CPU, "+
+            a.Spec.Resources.Limits.Memory().String()+" memory limit", path+".resources")
+    }
+    if a.Spec.Replicas != nil {
+        deployment.Spec.Replicas = int32Ptr(*a.Spec.Replicas)
+        render.Explain(deployment, "spec.replicas", strconv.Itoa(int(*a.Spec.Replicas)), path+".replicas")
+    }
+    return r.applyComponent(ctx, q, addonComponent(a.Name), deployment, qraiopv1.ComponentOptions{})
+}
+
+// applyAddonManifests applies an addon's manifests into q's namespace.
+// Cluster-scoped objects are refused: addons only extend their namespace.
+func (r *QraiopReconciler) applyAddonManifests(ctx context.Context, q *qraiopv1.Qraiop, a *qraiopv1.QraiopAddon) error {
+    for i, raw := range a.Spec.Manifests {
+        obj := &unstructured.Unstructured{}
+        if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+            return &invalidOptionsError{fmt.Errorf("manifest %d of addon %s: %w", i, a.Name, err)}
+        }
+        gvk := obj.GroupVersionKind()
+        mapping, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+        if err != nil {
+            return &invalidOptionsError{fmt.Errorf("manifest %d of addon %s: %s is not served by the cluster", i, a.Name, gvk.Kind)}
+        }
+        if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+            return &invalidOptionsError{fmt.Errorf("manifest %d of addon %s: cluster-scoped %s is not allowed", i, a.Name, gvk.Kind)}
+        }
+        obj.SetNamespace(q.Namespace)
+        labels := obj.GetLabels()
+        if labels == nil {
+            labels = make(map[string]string)
+        }
+        for k, v := range labelsForQraiop(q) {
+            labels[k] = v
+        }
+        labels[AddonLabel] = a.Name
+        obj.SetLabels(labels)
+        if err := r.createOrUpdate(ctx, q, obj); err != nil {
+            return fmt.Errorf("applying %s %s of addon %s: %w", gvk.Kind, obj.GetName(), a.Name, err)
+        }
+    }
+    return nil
+}
+
+// reportAddons copies each addon's component status onto the addon
+func (r *QraiopReconciler) reportAddons(ctx context.Context, q *qraiopv1.Qraiop) error {
+    for _, a := range r.addons.get(client.ObjectKeyFromObject(q)) {
+        a := a
+        name := addonComponent(a.Name)
+        st, ok := q.Status.Components[name]
+        if !ok {
+            continue
+        }
+        if a.Status.ObservedGeneration == a.Generation && a.Status.Status == st.Status && a.Status.Message == st.Message {
+            continue
+        }
+        a.Status.ObservedGeneration = a.Generation
+        a.Status.Component = name
+        a.Status.Status = st.Status
+        a.Status.Message = st.Message
+        updated := st.LastUpdated
+        a.Status.LastUpdated = &updated
+        if err := r.Status().Update(ctx, &a); client.IgnoreNotFound(err) != nil {
+            return fmt.Errorf("reporting status of addon %s: %w", a.Name, err)
+        }
+    }
+    return nil
+}
+
+// instanceForAddon maps a QraiopAddon to the instance it extends
+func (r *QraiopReconciler) instanceForAddon(ctx context.Context, obj client.Object) []reconcile.Request {
+    a, ok := obj.(*qraiopv1.QraiopAddon)
+    if !ok || a.Spec.Instance == "" {
+        return nil
+    }
+    return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: a.Namespace, Name: a.Spec.Instance}}}
+}
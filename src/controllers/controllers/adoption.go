@@ -0,0 +1,68 @@
+// src/controllers/controllers/adoption.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const conditionAdoption = "Adoption"
+
+// adoptable reports whether existing, found where q renders an object, may
+// be overwritten. Objects q controls always may; others follow the
+// instance's adoption policy. Under Fail an error is returned and the
+// Adoption condition names the object.
+func (r *QraiopReconciler) adoptable(ctx context.Context, q *qraiopv1.Qraiop, existing client.Object) (bool, error) {
+    if metav1.IsControlledBy(existing, q) {
+        return true, nil
+    }
+    ref := r.describeObject(existing)
+    owner := metav1.GetControllerOf(existing)
+
+    switch {
+    case q.Spec.AdoptionPolicy == qraiopv1.AdoptionIgnore:
+        if inv := inventoryFrom(ctx); inv != nil {
+            inv.mu.Lock()
+            inv.unowned = append(inv.unowned, ref)
+            inv.mu.Unlock()
+        }
+        return false, nil
+    case owner == nil && q.Spec.AdoptionPolicy != qraiopv1.AdoptionFail:
+        if !r.planning() {
+            r.Log.Info("adopting existing object", "qraiop", client.ObjectKeyFromObject(q), "object", ref)
+        }
+        return true, nil
+    }
+
+    msg := ref + " exists and is not owned by the instance"
+    if owner != nil {
+        msg = fmt.Sprintf("%s is controlled by %s %s", ref, owner.Kind, owner.Name)
+    }
+    setCondition(q, conditionAdoption, metav1.ConditionFalse, "Conflict", msg)
+    return false, fmt.Errorf("%s", msg)
+}
+
+// setAdoptionCondition reports the objects left alone during the reconcile
+func setAdoptionCondition(q *qraiopv1.Qraiop, inv *inventory) {
+    if len(inv.unowned) > 0 {
+        setCondition(q, conditionAdoption, metav1.ConditionFalse, "Ignored",
+            "left alone under the Ignore adoption policy: "+strings.Join(inv.unowned, ", "))
+        return
+    }
+    setCondition(q, conditionAdoption, metav1.ConditionTrue, "Owned", "all rendered objects are owned by the instance")
+}
+
+func (r *QraiopReconciler) describeObject(obj client.Object) string {
+    kind := "object"
+    if gvk, err := apiutil.GVKForObject(obj, r.Scheme); err == nil {
+        kind = gvk.Kind
+    }
+    return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
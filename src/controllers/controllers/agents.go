@@ -0,0 +1,152 @@
+// src/controllers/controllers/agents.go
+package controllers
+
+import (
+    "context"
+    "sort"
+    "strings"
+    "time"
+
+    coordinationv1 "k8s.io/api/coordination/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionAgentsReady = "AgentsReady"
+
+    // agentServiceAccount runs the AI orchestration agents
+    agentServiceAccount = "qraiop-ai"
+
+    // defaultLeaseDuration applies to heartbeat Leases without a duration
+    defaultLeaseDuration = 40 * time.Second
+)
+
+// agentRules let the agents renew their heartbeat Leases and work the
+// AgentTasks of their namespace
+var agentRules = []rbacv1.PolicyRule{
+    {APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch"}},
+    {APIGroups: []string{qraiopv1.GroupVersion.Group}, Resources: []string{"agenttasks"}, Verbs: []string{"get", "list", "watch"}},
+    {APIGroups: []string{qraiopv1.GroupVersion.Group}, Resources: []string{"agenttasks/status"}, Verbs: []string{"get", "update", "patch"}},
+}
+
+// agentLeaseName is the heartbeat Lease of an agent
+func agentLeaseName(agent string) string {
+    return "qraiop-agent-" + agent
+}
+
+// agentHeartbeat returns the holder of an agent's heartbeat Lease and when
+// the heartbeat goes stale. The holder is empty when the agent never
+// reported or its heartbeat is already stale.
+func agentHeartbeat(lease *coordinationv1.Lease, now time.Time) (string, time.Time) {
+    if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil {
+        return "", time.Time{}
+    }
+    duration := defaultLeaseDuration
+    if lease.Spec.LeaseDurationSeconds != nil {
+        duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+    }
+    staleAt := lease.Spec.RenewTime.Add(duration)
+    if !now.Before(staleAt) {
+        return "", staleAt
+    }
+    return *lease.Spec.HolderIdentity, staleAt
+}
+
+// ensureAgentRBAC grants the agents' ServiceAccount agentRules. Like the
+// tenant RBAC it is written with the operator's own identity.
+func (r *QraiopReconciler) ensureAgentRBAC(ctx context.Context, q *qraiopv1.Qraiop) error {
+    if !q.Spec.AIOrchestration.Enabled {
+        return nil
+    }
+    name := agentServiceAccount + "-agent"
+    labels := labelsForQraiop(q)
+    objects := []client.Object{
+        &rbacv1.Role{
+            ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: q.Namespace, Labels: labels},
+            Rules:      agentRules,
+        },
+        &rbacv1.RoleBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: q.Namespace, Labels: labels},
+            RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+            Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: agentServiceAccount, Namespace: q.Namespace}},
+        },
+    }
+    for _, obj := range objects {
+        if err := r.createOrUpdate(ctx, q, obj); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=agenttasks,verbs=get;list;watch
+
+// reconcileAgentHeartbeats reports the heartbeat and task queue of every
+// agent in the status. It returns when the next live heartbeat goes stale.
+func (r *QraiopReconciler) reconcileAgentHeartbeats(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    if !q.Spec.AIOrchestration.Enabled {
+        q.Status.Agents = nil
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionAgentsReady)
+        return 0, nil
+    }
+    var leases coordinationv1.LeaseList
+    if err := r.List(ctx, &leases, client.InNamespace(q.Namespace), client.HasLabels{qraiopv1.AgentLabel}); err != nil {
+        return 0, err
+    }
+    var tasks qraiopv1.AgentTaskList
+    if err := r.List(ctx, &tasks, client.InNamespace(q.Namespace)); err != nil {
+        return 0, err
+    }
+
+    now := time.Now()
+    var next time.Duration
+    var agents []qraiopv1.AgentHeartbeat
+    var notReady []string
+    for i := range leases.Items {
+        lease := &leases.Items[i]
+        name := lease.Labels[qraiopv1.AgentLabel]
+        holder, staleAt := agentHeartbeat(lease, now)
+        agent := qraiopv1.AgentHeartbeat{Name: name, Holder: holder, Ready: holder != ""}
+        if lease.Spec.RenewTime != nil {
+            agent.LastHeartbeat = &metav1.Time{Time: lease.Spec.RenewTime.Time}
+        }
+        for _, t := range tasks.Items {
+            if t.Spec.Agent != name {
+                continue
+            }
+            switch t.Status.Phase {
+            case "", qraiopv1.TaskQueued:
+                agent.QueuedTasks++
+            case qraiopv1.TaskAssigned, qraiopv1.TaskRunning:
+                agent.ActiveTasks++
+            }
+        }
+        if agent.Ready {
+            if wait := staleAt.Sub(now); next == 0 || wait < next {
+                next = wait
+            }
+        } else {
+            notReady = append(notReady, name)
+        }
+        agents = append(agents, agent)
+    }
+    sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+    q.Status.Agents = agents
+
+    switch {
+    case len(agents) == 0:
+        setCondition(q, conditionAgentsReady, metav1.ConditionUnknown, "NoHeartbeats", "no agent has reported a heartbeat yet")
+    case len(notReady) > 0:
+        sort.Strings(notReady)
+        setCondition(q, conditionAgentsReady, metav1.ConditionFalse, "HeartbeatStale",
+            "no recent heartbeat from "+strings.Join(notReady, ", ")+"; their tasks are reassigned")
+    default:
+        setCondition(q, conditionAgentsReady, metav1.ConditionTrue, "Heartbeating", "all agents report heartbeats")
+    }
+    return next, nil
+}
@@ -0,0 +1,171 @@
+// src/controllers/controllers/agenttask_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-logr/logr"
+    coordinationv1 "k8s.io/api/coordination/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const defaultTaskExpiry = time.Hour
+
+// AgentTaskReconciler queues AgentTasks for their agent, assigns them to
+// the pod holding the agent's heartbeat Lease, takes them back when that
+// heartbeat goes stale and expires those nobody finished in time
+type AgentTaskReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=agenttasks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=agenttasks/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *AgentTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("agenttask", req.NamespacedName)
+
+    var task qraiopv1.AgentTask
+    if err := r.Get(ctx, req.NamespacedName, &task); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+    if task.Status.IsFinished() {
+        return ctrl.Result{}, nil
+    }
+
+    now := time.Now()
+    if task.Status.Phase == "" {
+        task.Status.Phase = qraiopv1.TaskQueued
+    }
+    expiry := task.Spec.ExpireAfter.Duration
+    if expiry == 0 {
+        expiry = defaultTaskExpiry
+    }
+    expireAt := task.CreationTimestamp.Add(expiry)
+    if !now.Before(expireAt) {
+        log.Info("task expired", "phase", task.Status.Phase)
+        r.Recorder.Eventf(&task, corev1.EventTypeWarning, "TaskExpired",
+            "%s task not finished within %s", task.Status.Phase, expiry)
+        r.finish(&task, qraiopv1.TaskExpired, fmt.Sprintf("not finished within %s while %s", expiry, task.Status.Phase))
+        return ctrl.Result{}, r.Status().Update(ctx, &task)
+    }
+
+    holder, staleAt, err := r.heartbeat(ctx, &task, now)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    switch task.Status.Phase {
+    case qraiopv1.TaskQueued:
+        if holder == "" {
+            task.Status.Message = "waiting for a heartbeat from the " + task.Spec.Agent + " agent"
+            break
+        }
+        task.Status.Phase = qraiopv1.TaskAssigned
+        task.Status.AssignedTo = holder
+        task.Status.AssignedAt = &metav1.Time{Time: now}
+        task.Status.Message = "assigned to " + holder
+        r.Recorder.Eventf(&task, corev1.EventTypeNormal, "TaskAssigned", "assigned to %s", holder)
+    case qraiopv1.TaskAssigned, qraiopv1.TaskRunning:
+        if holder == task.Status.AssignedTo {
+            break
+        }
+        lost := task.Status.AssignedTo
+        if task.Status.Reassignments >= task.Spec.MaxReassignments {
+            r.Recorder.Eventf(&task, corev1.EventTypeWarning, "TaskFailed",
+                "%s went NotReady and the task was already reassigned %d times", lost, task.Status.Reassignments)
+            r.finish(&task, qraiopv1.TaskFailed, fmt.Sprintf("%s went NotReady after %d reassignments", lost, task.Status.Reassignments))
+            return ctrl.Result{}, r.Status().Update(ctx, &task)
+        }
+        log.Info("agent went NotReady, queueing task again", "agent", lost)
+        r.Recorder.Eventf(&task, corev1.EventTypeWarning, "TaskReassigned", "%s went NotReady, task queued again", lost)
+        task.Status.Phase = qraiopv1.TaskQueued
+        task.Status.AssignedTo = ""
+        task.Status.AssignedAt = nil
+        task.Status.Reassignments++
+        task.Status.Message = lost + " went NotReady"
+        if err := r.Status().Update(ctx, &task); err != nil {
+            return ctrl.Result{}, err
+        }
+        // The next pass assigns it to the agent's new holder, if any
+        return ctrl.Result{Requeue: true}, nil
+    }
+
+    requeueAfter := expireAt.Sub(now)
+    if holder != "" && staleAt.Sub(now) < requeueAfter {
+        requeueAfter = staleAt.Sub(now)
+    }
+    return ctrl.Result{RequeueAfter: requeueAfter}, r.Status().Update(ctx, &task)
+}
+
+// heartbeat reads the heartbeat Lease of the task's agent
+func (r *AgentTaskReconciler) heartbeat(ctx context.Context, task *qraiopv1.AgentTask, now time.Time) (string, time.Time, error) {
+    var lease coordinationv1.Lease
+    err := r.Get(ctx, client.ObjectKey{Namespace: task.Namespace, Name: agentLeaseName(task.Spec.Agent)}, &lease)
+    if apierrors.IsNotFound(err) {
+        return "", time.Time{}, nil
+    }
+    if err != nil {
+        return "", time.Time{}, err
+    }
+    holder, staleAt := agentHeartbeat(&lease, now)
+    return holder, staleAt, nil
+}
+
+func (r *AgentTaskReconciler) finish(task *qraiopv1.AgentTask, phase, message string) {
+    task.Status.Phase = phase
+    task.Status.CompletedAt = &metav1.Time{Time: time.Now()}
+    task.Status.Message = message
+}
+
+// tasksForLease maps an agent's heartbeat Lease to the tasks it may pick up
+// or has lost: queued tasks and tasks assigned to another holder
+func (r *AgentTaskReconciler) tasksForLease(ctx context.Context, obj client.Object) []reconcile.Request {
+    agent, ok := obj.GetLabels()[qraiopv1.AgentLabel]
+    if !ok {
+        return nil
+    }
+    lease, ok := obj.(*coordinationv1.Lease)
+    if !ok {
+        return nil
+    }
+    holder := ""
+    if lease.Spec.HolderIdentity != nil {
+        holder = *lease.Spec.HolderIdentity
+    }
+    var tasks qraiopv1.AgentTaskList
+    if err := r.List(ctx, &tasks, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for _, t := range tasks.Items {
+        if t.Spec.Agent != agent || t.Status.IsFinished() {
+            continue
+        }
+        if t.Status.Phase == "" || t.Status.Phase == qraiopv1.TaskQueued || t.Status.AssignedTo != holder {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&t)})
+        }
+    }
+    return requests
+}
+
+func (r *AgentTaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.AgentTask{}).
+        Watches(&coordinationv1.Lease{}, handler.EnqueueRequestsFromMapFunc(r.tasksForLease)).
+        Complete(r)
+}
@@ -0,0 +1,118 @@
+// src/controllers/controllers/algorithms.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// refreshCryptoCapabilities asks the running crypto service which API
+// version and algorithms it supports and records them on the status. The
+// service is only asked while it is ready; until then, and while it can't
+// be reached, the last answer stands.
+func (r *QraiopReconciler) refreshCryptoCapabilities(ctx context.Context, q *qraiopv1.Qraiop, tag string) {
+    if r.planning() || q.Status.Components[qraiopv1.ComponentCryptography].Status != qraiopv1.ComponentReady {
+        return
+    }
+    caps, err := r.cryptoClient(q).Capabilities(ctx)
+    if err != nil {
+        r.Log.Error(err, "unable to read crypto service capabilities", "qraiop", q.Namespace+"/"+q.Name)
+        return
+    }
+    st := q.Status.CryptoService
+    if st != nil && st.APIVersion == caps.APIVersion && st.ImageTag == tag && sameAlgorithms(st.Algorithms, caps.Algorithms) {
+        return
+    }
+    q.Status.CryptoService = &qraiopv1.CryptoServiceStatus{
+        APIVersion: caps.APIVersion,
+        Algorithms: caps.Algorithms,
+        ImageTag:   tag,
+        ObservedAt: metav1.Now(),
+    }
+}
+
+// negotiateAlgorithms reports the spec algorithms the crypto service can't
+// honor in the UnsupportedAlgorithm condition. Capabilities reported by
+// another build than the one rendered are stale, so nothing is refused on
+// them.
+func negotiateAlgorithms(q *qraiopv1.Qraiop, tag string) {
+    st := q.Status.CryptoService
+    if st == nil || st.ImageTag != tag {
+        return
+    }
+    var problems []string
+    if unsupported := unsupportedAlgorithms(st, q.Spec.Cryptography.Algorithms); len(unsupported) > 0 {
+        problems = append(problems, "spec.cryptography.algorithms: "+strings.Join(unsupported, ", "))
+    }
+    for _, pool := range q.Spec.Cryptography.Pools {
+        if unsupported := unsupportedAlgorithms(st, pool.Algorithms); len(unsupported) > 0 {
+            problems = append(problems, componentSpecPath(cryptoPoolComponent(pool.Name))+".algorithms: "+strings.Join(unsupported, ", "))
+        }
+    }
+    if len(problems) == 0 {
+        setCondition(q, conditionUnsupportedAlgorithm, metav1.ConditionFalse, "Negotiated",
+            "the crypto service supports every requested algorithm")
+        return
+    }
+    setCondition(q, conditionUnsupportedAlgorithm, metav1.ConditionTrue, "UnsupportedAlgorithm",
+        fmt.Sprintf("not supported by crypto service API %s: %s", st.APIVersion, strings.Join(problems, "; ")))
+}
+
+// checkAlgorithms returns an error naming the algorithms of a crypto
+// deployment the service doesn't support, so the deployment is blocked
+// instead of rolled out with a config it can't honor
+func checkAlgorithms(q *qraiopv1.Qraiop, deployment *appsv1.Deployment, algorithms []string) error {
+    st := q.Status.CryptoService
+    if st == nil || st.ImageTag != imageTag(deployment) {
+        return nil
+    }
+    if unsupported := unsupportedAlgorithms(st, algorithms); len(unsupported) > 0 {
+        return &invalidOptionsError{fmt.Errorf("unsupported algorithms %s; the crypto service supports %s",
+            strings.Join(unsupported, ", "), strings.Join(st.Algorithms, ", "))}
+    }
+    return nil
+}
+
+// unsupportedAlgorithms returns the requested algorithms the service did
+// not register, comparing standard names case-insensitively
+func unsupportedAlgorithms(st *qraiopv1.CryptoServiceStatus, requested []string) []string {
+    supported := make(map[string]bool, len(st.Algorithms))
+    for _, a := range st.Algorithms {
+        supported[strings.ToLower(qraiopv1.CanonicalAlgorithm(a))] = true
+    }
+    var unsupported []string
+    for _, a := range requested {
+        if !supported[strings.ToLower(qraiopv1.CanonicalAlgorithm(a))] {
+            unsupported = append(unsupported, a)
+        }
+    }
+    return unsupported
+}
+
+// imageTag is the tag of a component Deployment's image
+func imageTag(deployment *appsv1.Deployment) string {
+    image := deployment.Spec.Template.Spec.Containers[0].Image
+    return image[strings.LastIndex(image, ":")+1:]
+}
+
+func sameAlgorithms(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    a, b = append([]string(nil), a...), append([]string(nil), b...)
+    sort.Strings(a)
+    sort.Strings(b)
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,51 @@
+// src/controllers/controllers/argocd.go
+package controllers
+
+import (
+    "strconv"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Well-known Argo CD annotations
+const (
+    argoCDSyncWaveAnnotation       = "argocd.argoproj.io/sync-wave"
+    argoCDCompareOptionsAnnotation = "argocd.argoproj.io/compare-options"
+    argoCDSyncOptionsAnnotation    = "argocd.argoproj.io/sync-options"
+)
+
+// applyArgoCDMetadata marks a child object for Argo CD: it is expected to
+// be absent from Git, must never be pruned by Argo CD, and syncs in wave.
+// A negative wave leaves the sync-wave annotation off.
+func applyArgoCDMetadata(q *qraiopv1.Qraiop, obj metav1.Object, wave int) {
+    cfg := q.Spec.ArgoCD
+    if cfg == nil || !cfg.Enabled {
+        return
+    }
+    annotations := map[string]string{
+        argoCDCompareOptionsAnnotation: "IgnoreExtraneous",
+        argoCDSyncOptionsAnnotation:    "Prune=false,Delete=false",
+    }
+    if cfg.SyncWaves && wave >= 0 {
+        annotations[argoCDSyncWaveAnnotation] = strconv.Itoa(int(cfg.SyncWaveBase) + wave)
+    }
+    obj.SetAnnotations(mergeMissing(obj.GetAnnotations(), annotations))
+}
+
+// syncWave returns the wave of a component's objects: its position in
+// dependency order, after the NetworkPolicies in wave 0. Objects not
+// belonging to a component get -1.
+func (r *QraiopReconciler) syncWave(q *qraiopv1.Qraiop, deployment string) int {
+    ordered, err := componentOrder(r.components(q))
+    if err != nil {
+        return -1
+    }
+    for i, c := range ordered {
+        if c.deployment == deployment {
+            return i + 1
+        }
+    }
+    return -1
+}
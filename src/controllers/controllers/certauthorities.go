@@ -0,0 +1,355 @@
+// src/controllers/controllers/certauthorities.go
+package controllers
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "net/http"
+    "path"
+    "slices"
+    "sort"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/certauthority"
+)
+
+const conditionCertificateAuthorities = "CertificateAuthorities"
+
+// routeCertificate returns the authority issuing a certificate for a
+// workload in namespace with the given SANs, or "" for the instance's
+// crypto service
+func routeCertificate(cfg *qraiopv1.CertManagementConfig, namespace string, sans []string) string {
+    if cfg == nil {
+        return ""
+    }
+    for _, route := range cfg.Routes {
+        if len(route.Namespaces) > 0 && !slices.Contains(route.Namespaces, namespace) {
+            continue
+        }
+        if len(route.SANPatterns) > 0 && !matchesSAN(route.SANPatterns, sans) {
+            continue
+        }
+        return route.Authority
+    }
+    return cfg.DefaultAuthority
+}
+
+func matchesSAN(patterns, sans []string) bool {
+    for _, san := range sans {
+        for _, p := range patterns {
+            if ok, _ := path.Match(p, san); ok {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// findAuthority returns the authority named name
+func findAuthority(cfg *qraiopv1.CertManagementConfig, name string) *qraiopv1.CertificateAuthority {
+    if cfg == nil {
+        return nil
+    }
+    for i := range cfg.Authorities {
+        if cfg.Authorities[i].Name == name {
+            return &cfg.Authorities[i]
+        }
+    }
+    return nil
+}
+
+// certManagementProblems returns what is wrong with the authorities and
+// routes of q that the CRD schema can't tell
+func certManagementProblems(q *qraiopv1.Qraiop) []string {
+    cfg := q.Spec.Cryptography.CertManagement
+    var problems []string
+    refs := append([]string(nil), cfg.DefaultAuthority)
+    for _, r := range cfg.Routes {
+        refs = append(refs, r.Authority)
+    }
+    for _, name := range refs {
+        if name != "" && findAuthority(cfg, name) == nil {
+            problems = append(problems, fmt.Sprintf("unknown authority %s", name))
+        }
+    }
+    for _, r := range cfg.Routes {
+        for _, p := range r.SANPatterns {
+            if _, err := path.Match(p, ""); err != nil {
+                problems = append(problems, fmt.Sprintf("invalid SAN pattern %q", p))
+            }
+        }
+    }
+    for _, a := range cfg.Authorities {
+        switch {
+        case a.Type == qraiopv1.CertificateAuthorityVault && a.Vault == nil:
+            problems = append(problems, fmt.Sprintf("authority %s needs vault settings", a.Name))
+        case a.Type == qraiopv1.CertificateAuthorityEST && a.EST == nil:
+            problems = append(problems, fmt.Sprintf("authority %s needs est settings", a.Name))
+        case a.Type == qraiopv1.CertificateAuthorityHybrid && a.Hybrid == nil:
+            problems = append(problems, fmt.Sprintf("authority %s needs hybrid settings", a.Name))
+        case a.Type == qraiopv1.CertificateAuthorityInternal && a.Internal != nil && a.Internal.Pool != "" && !hasCryptoPool(q, a.Internal.Pool):
+            problems = append(problems, fmt.Sprintf("authority %s uses unknown crypto pool %s", a.Name, a.Internal.Pool))
+        case a.Type == qraiopv1.CertificateAuthorityHybrid && a.Hybrid.Pool != "" && !hasCryptoPool(q, a.Hybrid.Pool):
+            problems = append(problems, fmt.Sprintf("authority %s uses unknown crypto pool %s", a.Name, a.Hybrid.Pool))
+        }
+    }
+    return problems
+}
+
+func hasCryptoPool(q *qraiopv1.Qraiop, name string) bool {
+    for _, p := range q.Spec.Cryptography.Pools {
+        if p.Name == name {
+            return true
+        }
+    }
+    return false
+}
+
+// authorityIssuer returns the client of an authority of q. Internal
+// authorities use the crypto service pools in clients.
+func authorityIssuer(ctx context.Context, c client.Reader, clients *cryptoClients, q *qraiopv1.Qraiop, a *qraiopv1.CertificateAuthority) (certauthority.Issuer, error) {
+    switch a.Type {
+    case qraiopv1.CertificateAuthorityVault:
+        return vaultAuthority(ctx, c, q, a.Vault)
+    case qraiopv1.CertificateAuthorityEST:
+        return estAuthority(ctx, c, q, a.EST)
+    case qraiopv1.CertificateAuthorityHybrid:
+        return hybridAuthority(ctx, c, clients, q, a.Hybrid)
+    }
+    pool := ""
+    if a.Internal != nil {
+        pool = a.Internal.Pool
+    }
+    return clients.forService(q, pool), nil
+}
+
+func vaultAuthority(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop, cfg *qraiopv1.VaultCA) (*certauthority.Vault, error) {
+    token, err := secretKey(ctx, c, q.Namespace, cfg.TokenSecret)
+    if err != nil {
+        return nil, fmt.Errorf("reading Vault token: %w", err)
+    }
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    mount := cfg.Mount
+    if mount == "" {
+        mount = "pki"
+    }
+    return &certauthority.Vault{
+        URL:       cfg.URL,
+        Namespace: cfg.Namespace,
+        Mount:     mount,
+        Role:      cfg.Role,
+        Token:     token,
+        HTTP:      httpClient,
+    }, nil
+}
+
+// hybridAuthority composes the crypto service pool of cfg, for the PQC
+// half, with the Vault signer of the classical half
+func hybridAuthority(ctx context.Context, c client.Reader, clients *cryptoClients, q *qraiopv1.Qraiop, cfg *qraiopv1.HybridCA) (*certauthority.Hybrid, error) {
+    token, err := secretKey(ctx, c, q.Namespace, cfg.Vault.TokenSecret)
+    if err != nil {
+        return nil, fmt.Errorf("reading Vault token: %w", err)
+    }
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    engine := &certauthority.Vault{URL: cfg.Vault.URL, Namespace: cfg.Vault.Namespace, Token: token, HTTP: httpClient}
+    hybrid := &certauthority.Hybrid{PQC: clients.forService(q, cfg.Pool)}
+    switch {
+    case cfg.Vault.PKI != nil:
+        engine.Mount, engine.Role = cfg.Vault.PKI.Mount, cfg.Vault.PKI.Role
+        if engine.Mount == "" {
+            engine.Mount = "pki"
+        }
+        hybrid.Classical = engine
+    case cfg.Vault.Transit != nil:
+        t := cfg.Vault.Transit
+        engine.Mount = t.Mount
+        if engine.Mount == "" {
+            engine.Mount = "transit"
+        }
+        var cm corev1.ConfigMap
+        if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: t.CACertificate.Name}, &cm); err != nil {
+            return nil, fmt.Errorf("reading Transit CA certificate: %w", err)
+        }
+        block, _ := pem.Decode([]byte(cm.Data[t.CACertificate.Key]))
+        if block == nil {
+            return nil, fmt.Errorf("Transit CA certificate %s/%s is not PEM", t.CACertificate.Name, t.CACertificate.Key)
+        }
+        ca, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("parsing Transit CA certificate: %w", err)
+        }
+        hybrid.Classical = &certauthority.Transit{Engine: engine, Key: t.Key, CA: ca}
+    default:
+        return nil, fmt.Errorf("hybrid authority sets neither pki nor transit")
+    }
+    return hybrid, nil
+}
+
+func estAuthority(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop, cfg *qraiopv1.ESTCA) (*certauthority.EST, error) {
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.TrustedCA != nil {
+        var cm corev1.ConfigMap
+        if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: cfg.TrustedCA.Name}, &cm); err != nil {
+            return nil, fmt.Errorf("reading EST CA bundle: %w", err)
+        }
+        pool := x509.NewCertPool()
+        if !pool.AppendCertsFromPEM([]byte(cm.Data[cfg.TrustedCA.Key])) {
+            return nil, fmt.Errorf("EST CA bundle %s/%s has no PEM certificates", cfg.TrustedCA.Name, cfg.TrustedCA.Key)
+        }
+        transport := httpClient.Transport.(*http.Transport)
+        transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+    }
+    est := &certauthority.EST{URL: cfg.URL, Label: cfg.Label, HTTP: httpClient}
+    if cfg.CredentialsSecret != nil {
+        var secret corev1.Secret
+        if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: cfg.CredentialsSecret.Name}, &secret); err != nil {
+            return nil, fmt.Errorf("reading EST credentials: %w", err)
+        }
+        est.Username = string(secret.Data["username"])
+        est.Password = string(secret.Data["password"])
+    }
+    return est, nil
+}
+
+// authorityPool returns the crypto pool an authority issues from, "" for
+// the default crypto service, and whether it uses one at all
+func authorityPool(a *qraiopv1.CertificateAuthority) (string, bool) {
+    switch a.Type {
+    case qraiopv1.CertificateAuthorityInternal:
+        if a.Internal != nil {
+            return a.Internal.Pool, true
+        }
+        return "", true
+    case qraiopv1.CertificateAuthorityHybrid:
+        if a.Hybrid != nil {
+            return a.Hybrid.Pool, true
+        }
+    }
+    return "", false
+}
+
+// secretKey returns the trimmed value of a Secret key
+func secretKey(ctx context.Context, c client.Reader, namespace string, sel corev1.SecretKeySelector) (string, error) {
+    var secret corev1.Secret
+    if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: sel.Name}, &secret); err != nil {
+        return "", err
+    }
+    value, ok := secret.Data[sel.Key]
+    if !ok {
+        return "", fmt.Errorf("Secret %s has no key %s", sel.Name, sel.Key)
+    }
+    return strings.TrimSpace(string(value)), nil
+}
+
+// checkAuthority returns why an authority can't issue, or ""
+func (r *QraiopReconciler) checkAuthority(ctx context.Context, q *qraiopv1.Qraiop, a *qraiopv1.CertificateAuthority) string {
+    if pool, ok := authorityPool(a); ok {
+        component := qraiopv1.ComponentCryptography
+        if pool != "" {
+            component = cryptoPoolComponent(pool)
+        }
+        if q.Status.Components[component].Status != qraiopv1.ComponentReady {
+            return fmt.Sprintf("crypto service %s is not ready", component)
+        }
+        // The classical half of hybrid authorities is checked below
+        if a.Type == qraiopv1.CertificateAuthorityInternal {
+            return ""
+        }
+    }
+    issuer, err := authorityIssuer(ctx, r, r.cryptoClients, q, a)
+    if err != nil {
+        return err.Error()
+    }
+    checker, ok := issuer.(interface {
+        CACertificates(ctx context.Context) (string, error)
+    })
+    if !ok {
+        return ""
+    }
+    if _, err := checker.CACertificates(ctx); err != nil {
+        return err.Error()
+    }
+    return ""
+}
+
+// reconcileCertAuthorities checks the authorities of q can issue and
+// records which injected certificates each of them issued
+func (r *QraiopReconciler) reconcileCertAuthorities(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.Cryptography.CertManagement
+    if cfg == nil || !q.Spec.Cryptography.Enabled {
+        q.Status.CertificateAuthorities = nil
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionCertificateAuthorities)
+        return nil
+    }
+    if problems := certManagementProblems(q); len(problems) > 0 {
+        setCondition(q, conditionCertificateAuthorities, metav1.ConditionFalse, "InvalidConfig", strings.Join(problems, "; "))
+        return nil
+    }
+
+    var secrets corev1.SecretList
+    if err := r.List(ctx, &secrets, client.InNamespace(q.Namespace), client.HasLabels{injectedForLabel}); err != nil {
+        return err
+    }
+    statuses := make([]qraiopv1.CertificateAuthorityStatus, 0, len(cfg.Authorities))
+    var unready []string
+    for i := range cfg.Authorities {
+        a := &cfg.Authorities[i]
+        st := qraiopv1.CertificateAuthorityStatus{Name: a.Name, Type: a.Type}
+        st.Message = r.checkAuthority(ctx, q, a)
+        st.Ready = st.Message == ""
+        if !st.Ready {
+            unready = append(unready, a.Name+": "+st.Message)
+        }
+        for _, s := range secrets.Items {
+            if s.Annotations[qraiopv1.IssuedByAnnotation] != a.Name {
+                continue
+            }
+            st.Certificates = append(st.Certificates, s.Name)
+            if issued, ok := certIssuedAt(&s); ok && (st.LastIssued == nil || issued.After(st.LastIssued.Time)) {
+                st.LastIssued = &issued
+            }
+        }
+        sort.Strings(st.Certificates)
+        statuses = append(statuses, st)
+    }
+    q.Status.CertificateAuthorities = statuses
+
+    if len(unready) > 0 {
+        setCondition(q, conditionCertificateAuthorities, metav1.ConditionFalse, "AuthorityUnavailable", strings.Join(unready, "; "))
+        return nil
+    }
+    setCondition(q, conditionCertificateAuthorities, metav1.ConditionTrue, "Ready",
+        fmt.Sprintf("%d certificate authorities ready", len(statuses)))
+    return nil
+}
+
+// certIssuedAt returns when the certificate of a Secret became valid
+func certIssuedAt(s *corev1.Secret) (metav1.Time, bool) {
+    block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+    if block == nil {
+        return metav1.Time{}, false
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return metav1.Time{}, false
+    }
+    return metav1.NewTime(cert.NotBefore), true
+}
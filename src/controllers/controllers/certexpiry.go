@@ -0,0 +1,224 @@
+// src/controllers/controllers/certexpiry.go
+package controllers
+
+import (
+    "context"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "sort"
+    "strconv"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    appsv1 "k8s.io/api/apps/v1"
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    // certExpiryInterval is how often the forecast is refreshed
+    certExpiryInterval = time.Hour
+    // certManagerAnnotation names the cert-manager Certificate of a Secret
+    certManagerAnnotation = "cert-manager.io/certificate-name"
+)
+
+var certExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+    Name: "qraiop_certificate_expiry_timestamp_seconds",
+    Help: "Expiry of the TLS certificates expiring within an instance's forecast, by whether the operator or cert-manager renews them first.",
+}, []string{"instance", "namespace", "secret", "covered"})
+
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+
+func init() {
+    metrics.Registry.MustRegister(certExpiry)
+}
+
+// reconcileCertExpiry forecasts which certificates of q's namespace expire
+// within the configured days, which workloads break with them and whether
+// they are renewed in time
+func (r *QraiopReconciler) reconcileCertExpiry(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    instance := q.Namespace + "/" + q.Name
+    cfg := q.Spec.Cryptography.ExpiryForecast
+    if cfg == nil {
+        if q.Status.CertExpiry != nil {
+            certExpiry.DeletePartialMatch(prometheus.Labels{"instance": instance})
+        }
+        q.Status.CertExpiry = nil
+        return 0, nil
+    }
+    days := cfg.Days
+    if days <= 0 {
+        days = 30
+    }
+    now := time.Now()
+    if st := q.Status.CertExpiry; st != nil && st.Days == days {
+        if due := st.ForecastAt.Add(certExpiryInterval); due.After(now) {
+            return due.Sub(now), nil
+        }
+    }
+
+    var secrets corev1.SecretList
+    if err := r.List(ctx, &secrets, client.InNamespace(q.Namespace)); err != nil {
+        return 0, err
+    }
+    var deployments appsv1.DeploymentList
+    if err := r.List(ctx, &deployments, client.InNamespace(q.Namespace)); err != nil {
+        return 0, err
+    }
+    consumers, err := r.secretConsumers(ctx, q.Namespace, deployments.Items)
+    if err != nil {
+        return 0, err
+    }
+    optedIn := make(map[string]bool)
+    for i := range deployments.Items {
+        optedIn[deployments.Items[i].Name] = wantsCert(&deployments.Items[i])
+    }
+
+    horizon := now.Add(time.Duration(days) * 24 * time.Hour)
+    st := &qraiopv1.CertExpiryStatus{Days: days, ForecastAt: metav1.NewTime(now)}
+    certExpiry.DeletePartialMatch(prometheus.Labels{"instance": instance})
+    for i := range secrets.Items {
+        s := &secrets.Items[i]
+        if s.Type != corev1.SecretTypeTLS {
+            continue
+        }
+        block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+        if block == nil {
+            continue
+        }
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil || cert.NotAfter.After(horizon) {
+            continue
+        }
+        e := certCoverage(s, cert, optedIn, now)
+        e.Secret, e.Subject = s.Name, cert.Subject.CommonName
+        e.NotAfter = metav1.NewTime(cert.NotAfter)
+        e.Consumers = consumers[s.Name]
+        if !e.Covered {
+            st.Uncovered++
+        }
+        st.Certificates = append(st.Certificates, e)
+        certExpiry.WithLabelValues(instance, q.Namespace, s.Name, strconv.FormatBool(e.Covered)).Set(float64(cert.NotAfter.Unix()))
+    }
+    sort.Slice(st.Certificates, func(i, j int) bool {
+        return st.Certificates[i].NotAfter.Before(&st.Certificates[j].NotAfter)
+    })
+    q.Status.CertExpiry = st
+    return certExpiryInterval, nil
+}
+
+// certCoverage tells whether the certificate of s is renewed before it
+// expires: injected certificates by the operator, as long as their
+// Deployment still opts in, and those of a cert-manager Certificate by
+// cert-manager
+func certCoverage(s *corev1.Secret, cert *x509.Certificate, optedIn map[string]bool, now time.Time) qraiopv1.ExpiringCertificate {
+    var e qraiopv1.ExpiringCertificate
+    switch {
+    case now.After(cert.NotAfter):
+        e.Reason = "expired"
+    case s.Annotations[qraiopv1.RevokeAnnotation] != "":
+        e.Reason = "revoked and not re-issued until the Secret is deleted"
+    case s.Annotations[certManagerAnnotation] != "":
+        e.Covered = true
+        e.Reason = "renewed by cert-manager Certificate " + s.Annotations[certManagerAnnotation]
+    case s.Labels[injectedForLabel] != "":
+        deployment := s.Labels[injectedForLabel]
+        if !optedIn[deployment] {
+            e.Reason = fmt.Sprintf("Deployment %s no longer opts in to certificate injection", deployment)
+            break
+        }
+        renewAt, _ := certRenewal(s)
+        e.RenewsAt = &metav1.Time{Time: renewAt}
+        // Renewal runs at renewAt; a certificate still here well after it
+        // is stuck, e.g. on an unavailable crypto service
+        if now.After(renewAt.Add(time.Hour)) {
+            e.Reason = "renewal overdue since " + renewAt.Format(time.RFC3339)
+            break
+        }
+        e.Covered = true
+        e.Reason = "renewed by the operator"
+    default:
+        e.Reason = "not renewed by the operator or cert-manager"
+    }
+    return e
+}
+
+// secretConsumers maps Secrets to the workloads of a namespace using
+// them, given its Deployments
+func (r *QraiopReconciler) secretConsumers(ctx context.Context, namespace string, deployments []appsv1.Deployment) (map[string][]string, error) {
+    var (
+        statefulSets appsv1.StatefulSetList
+        cronJobs     batchv1.CronJobList
+    )
+    if err := r.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    if err := r.List(ctx, &cronJobs, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+
+    consumers := make(map[string][]string)
+    add := func(consumer string, spec *corev1.PodSpec) {
+        for secret := range podSecrets(spec) {
+            consumers[secret] = append(consumers[secret], consumer)
+        }
+    }
+    for i := range deployments {
+        d := &deployments[i]
+        add("Deployment/"+d.Name, &d.Spec.Template.Spec)
+        // Injected certificates are mounted by the webhook, so they are
+        // missing from the template
+        if wantsCert(d) {
+            secret := qraiopv1.InjectedCertSecret(d.Name)
+            consumers[secret] = append(consumers[secret], "Deployment/"+d.Name)
+        }
+    }
+    for _, s := range statefulSets.Items {
+        add("StatefulSet/"+s.Name, &s.Spec.Template.Spec)
+    }
+    for _, c := range cronJobs.Items {
+        add("CronJob/"+c.Name, &c.Spec.JobTemplate.Spec.Template.Spec)
+    }
+    for secret, names := range consumers {
+        sort.Strings(names)
+        consumers[secret] = names
+    }
+    return consumers, nil
+}
+
+// podSecrets returns the Secrets a pod mounts or reads into its environment
+func podSecrets(spec *corev1.PodSpec) map[string]bool {
+    secrets := make(map[string]bool)
+    for _, v := range spec.Volumes {
+        if v.Secret != nil {
+            secrets[v.Secret.SecretName] = true
+        }
+        if v.Projected != nil {
+            for _, src := range v.Projected.Sources {
+                if src.Secret != nil {
+                    secrets[src.Secret.Name] = true
+                }
+            }
+        }
+    }
+    containers := append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...)
+    for _, c := range containers {
+        for _, from := range c.EnvFrom {
+            if from.SecretRef != nil {
+                secrets[from.SecretRef.Name] = true
+            }
+        }
+        for _, env := range c.Env {
+            if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+                secrets[env.ValueFrom.SecretKeyRef.Name] = true
+            }
+        }
+    }
+    return secrets
+}
@@ -0,0 +1,339 @@
+// src/controllers/controllers/certinjection.go
+package controllers
+
+import (
+    "context"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "net"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/go-logr/logr"
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/builder"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/predicate"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/certauthority"
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+const (
+    // injectedCertLifetime is how long injected certificates are valid
+    injectedCertLifetime = 30 * 24 * time.Hour
+    // injectedForLabel names the Deployment an injected Secret is for
+    injectedForLabel = "qraiop.io/injected-for"
+    // certSANsAnnotation on an injected Secret records the names its
+    // certificate was issued for, to re-issue it when they change
+    certSANsAnnotation = "qraiop.io/sans"
+    // certPolicyAnnotation on an injected Secret records the algorithms and
+    // security level of the crypto service that issued its certificate
+    certPolicyAnnotation = "qraiop.io/cert-policy"
+    // certReissueAnnotation on an injected Secret no longer matching the
+    // spec admits it to a re-issue batch, when the batch started
+    certReissueAnnotation = "qraiop.io/reissue-batch"
+)
+
+// CertInjectionReconciler issues the certificates of Deployments that opt
+// in with the inject-cert annotation, from the crypto service of the Qraiop
+// instance in their namespace or the authority its cert management routes
+// them to, and renews them once two thirds of their lifetime have passed
+// or when their Secret is annotated with qraiop.io/renew.
+// A certificate whose route, SANs or issuing algorithms changed is
+// re-issued, at once or in the batches of the instance's cert re-issue.
+// The injection webhook mounts them into the pods; the projected files are
+// updated in place on renewal.
+type CertInjectionReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+
+    cryptoClients *cryptoClients
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *CertInjectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("deployment", req.NamespacedName)
+
+    var deployment appsv1.Deployment
+    if err := r.Get(ctx, req.NamespacedName, &deployment); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+    // The Secret of a Deployment that opts out stays, since its running
+    // pods still mount it, and goes with the Deployment it is owned by
+    if !wantsCert(&deployment) || deployment.DeletionTimestamp != nil {
+        return ctrl.Result{}, nil
+    }
+
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances, client.InNamespace(deployment.Namespace)); err != nil {
+        return ctrl.Result{}, err
+    }
+    if len(instances.Items) != 1 {
+        r.Recorder.Eventf(&deployment, corev1.EventTypeWarning, "CertNotIssued",
+            "%s needs exactly one Qraiop instance in namespace %s, found %d", qraiopv1.InjectCertAnnotation, deployment.Namespace, len(instances.Items))
+        return ctrl.Result{}, nil
+    }
+    q := &instances.Items[0]
+    if q.Status.Components[qraiopv1.ComponentCryptography].Status != qraiopv1.ComponentReady {
+        log.Info("waiting for the crypto service", "qraiop", q.Name)
+        return ctrl.Result{RequeueAfter: time.Minute}, nil
+    }
+
+    want := wantedCert(q, &deployment)
+    certs := q.Spec.Cryptography.CertManagement
+    secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+        Name:      qraiopv1.InjectedCertSecret(deployment.Name),
+        Namespace: deployment.Namespace,
+    }}
+    err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+    if err != nil && !apierrors.IsNotFound(err) {
+        return ctrl.Result{}, err
+    }
+    if err == nil {
+        // Re-issuing would drop the revoked certificate from the revocation
+        // list; deleting the Secret gets a new one
+        if _, revoked := secret.Annotations[qraiopv1.RevokeAnnotation]; revoked {
+            return ctrl.Result{}, nil
+        }
+        _, renew := secret.Annotations[qraiopv1.RenewCertAnnotation]
+        if renewAt, ok := certRenewal(secret); ok && time.Now().Before(renewAt) && !renew {
+            if want.matches(secret) {
+                return ctrl.Result{RequeueAfter: time.Until(renewAt)}, nil
+            }
+            // The instance admits it to a batch by annotating the Secret
+            if q.Spec.Cryptography.CertReissue != nil && secret.Annotations[certReissueAnnotation] == "" {
+                log.Info("certificate no longer matches the spec, waiting for a re-issue batch", "secret", secret.Name)
+                return ctrl.Result{RequeueAfter: time.Until(renewAt)}, nil
+            }
+        }
+    }
+
+    var issuer certauthority.Issuer = r.cryptoClients.get(q)
+    if want.authority != "" {
+        a := findAuthority(certs, want.authority)
+        if a == nil {
+            r.Recorder.Eventf(&deployment, corev1.EventTypeWarning, "CertNotIssued", "routed to unknown certificate authority %s", want.authority)
+            return ctrl.Result{}, nil
+        }
+        if issuer, err = authorityIssuer(ctx, r, r.cryptoClients, q, a); err != nil {
+            return ctrl.Result{}, fmt.Errorf("certificate authority %s: %w", want.authority, err)
+        }
+    }
+    cert, err := issuer.IssueCertificate(ctx, cryptoclient.CertificateRequest{
+        CommonName:  want.dnsNames[0],
+        DNSNames:    want.dnsNames,
+        IPAddresses: want.ips,
+    }, injectedCertLifetime)
+    if err != nil {
+        return ctrl.Result{}, fmt.Errorf("issuing certificate: %w", err)
+    }
+    if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+        secret.Type = corev1.SecretTypeTLS
+        secret.Labels = map[string]string{
+            "app.kubernetes.io/managed-by": "qraiop-controller",
+            "app.kubernetes.io/part-of":    "qraiop",
+            injectedForLabel:               deployment.Name,
+        }
+        if secret.Annotations == nil {
+            secret.Annotations = make(map[string]string)
+        }
+        secret.Annotations[certSANsAnnotation] = want.sans
+        if want.authority != "" {
+            secret.Annotations[qraiopv1.IssuedByAnnotation] = want.authority
+        } else {
+            delete(secret.Annotations, qraiopv1.IssuedByAnnotation)
+        }
+        if want.policy != "" {
+            secret.Annotations[certPolicyAnnotation] = want.policy
+        } else {
+            delete(secret.Annotations, certPolicyAnnotation)
+        }
+        delete(secret.Annotations, certReissueAnnotation)
+        delete(secret.Annotations, qraiopv1.RenewCertAnnotation)
+        secret.Data = map[string][]byte{
+            corev1.TLSCertKey:       []byte(cert.Certificate),
+            corev1.TLSPrivateKeyKey: []byte(cert.PrivateKey),
+            "ca.crt":                []byte(cert.CACertificate),
+        }
+        // Hybrid authorities add the PQC half next to the classical one
+        if pqc := cert.PQC; pqc != nil {
+            secret.Data["pqc.crt"] = []byte(pqc.Certificate)
+            secret.Data["pqc.key"] = []byte(pqc.PrivateKey)
+            secret.Data["pqc-ca.crt"] = []byte(pqc.CACertificate)
+        }
+        return controllerutil.SetControllerReference(&deployment, secret, r.Scheme)
+    }); err != nil {
+        return ctrl.Result{}, err
+    }
+    issuedBy := "the crypto service"
+    if want.authority != "" {
+        issuedBy = "certificate authority " + want.authority
+    }
+    r.Recorder.Eventf(&deployment, corev1.EventTypeNormal, "CertIssued",
+        "%s issued certificate for %s into Secret %s", issuedBy, want.sans, secret.Name)
+
+    renewAt, ok := certRenewal(secret)
+    if !ok {
+        return ctrl.Result{}, fmt.Errorf("%s issued an unparseable certificate", issuedBy)
+    }
+    return ctrl.Result{RequeueAfter: time.Until(renewAt)}, nil
+}
+
+// wantsCert reports whether a Deployment opted in to certificate injection
+func wantsCert(d *appsv1.Deployment) bool {
+    return d.Annotations[qraiopv1.InjectCertAnnotation] == "true"
+}
+
+// certSANs returns the DNS names and IP addresses of a Deployment's
+// certificate: those of the Service named like it, then the annotation's
+func certSANs(d *appsv1.Deployment) ([]string, []string) {
+    svc := d.Name + "." + d.Namespace + ".svc"
+    dnsNames := []string{svc, d.Name, d.Name + "." + d.Namespace, svc + ".cluster.local"}
+    var ips []string
+    seen := make(map[string]bool)
+    for _, san := range strings.Split(d.Annotations[qraiopv1.CertSANsAnnotation], ",") {
+        san = strings.TrimSpace(san)
+        if san == "" || seen[san] {
+            continue
+        }
+        seen[san] = true
+        if net.ParseIP(san) != nil {
+            ips = append(ips, san)
+        } else {
+            dnsNames = append(dnsNames, san)
+        }
+    }
+    sort.Strings(ips)
+    return dnsNames, ips
+}
+
+// injectedCert is what the certificate of an opted-in Deployment is issued
+// for and by
+type injectedCert struct {
+    dnsNames, ips []string
+    // sans, authority and policy are recorded on the Secret as issued
+    sans, authority, policy string
+}
+
+// wantedCert returns what q issues the certificate of d for and by
+func wantedCert(q *qraiopv1.Qraiop, d *appsv1.Deployment) injectedCert {
+    dnsNames, ips := certSANs(d)
+    allSANs := append(append([]string(nil), dnsNames...), ips...)
+    authority := routeCertificate(q.Spec.Cryptography.CertManagement, d.Namespace, allSANs)
+    return injectedCert{
+        dnsNames:  dnsNames,
+        ips:       ips,
+        sans:      strings.Join(allSANs, ","),
+        authority: authority,
+        policy:    certPolicy(q, authority),
+    }
+}
+
+// matches reports whether the certificate in an injected Secret was issued
+// as wanted. Secrets issued before policies were recorded match any.
+func (c injectedCert) matches(s *corev1.Secret) bool {
+    policy, recorded := s.Annotations[certPolicyAnnotation]
+    return s.Annotations[certSANsAnnotation] == c.sans &&
+        s.Annotations[qraiopv1.IssuedByAnnotation] == c.authority &&
+        (!recorded || policy == c.policy)
+}
+
+// certPolicy returns the algorithms and security level of the crypto
+// service issuing for authority, or the PQC half of its certificates, or
+// "" for an external authority
+func certPolicy(q *qraiopv1.Qraiop, authority string) string {
+    crypto := q.Spec.Cryptography
+    algorithms, level, hybrid := crypto.Algorithms, crypto.SecurityLevel, crypto.HybridMode
+    if authority != "" {
+        a := findAuthority(crypto.CertManagement, authority)
+        if a == nil {
+            return ""
+        }
+        pool, ok := authorityPool(a)
+        if !ok {
+            return ""
+        }
+        if pool != "" {
+            for _, p := range crypto.Pools {
+                if p.Name == pool {
+                    algorithms, level, hybrid = p.Algorithms, p.SecurityLevel, p.HybridMode
+                }
+            }
+        }
+    }
+    sorted := append([]string(nil), algorithms...)
+    sort.Strings(sorted)
+    policy := fmt.Sprintf("%s;level=%d", strings.Join(sorted, ","), level)
+    if hybrid {
+        policy += ";hybrid"
+    }
+    return policy
+}
+
+// certRenewal returns when the certificate of an injected Secret is due
+// for renewal: after two thirds of its lifetime
+func certRenewal(s *corev1.Secret) (time.Time, bool) {
+    block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+    if block == nil {
+        return time.Time{}, false
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return time.Time{}, false
+    }
+    lifetime := cert.NotAfter.Sub(cert.NotBefore)
+    return cert.NotBefore.Add(lifetime * 2 / 3), true
+}
+
+func (r *CertInjectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    r.cryptoClients = newCryptoClients()
+    r.cryptoClients.suffix = "/certs"
+    optedIn := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+        d, ok := obj.(*appsv1.Deployment)
+        return ok && wantsCert(d)
+    })
+    return ctrl.NewControllerManagedBy(mgr).
+        Named("certinjection").
+        For(&appsv1.Deployment{}, builder.WithPredicates(optedIn, predicate.Or(
+            predicate.GenerationChangedPredicate{},
+            predicate.AnnotationChangedPredicate{},
+        ))).
+        Owns(&corev1.Secret{}).
+        Watches(&qraiopv1.Qraiop{}, handler.EnqueueRequestsFromMapFunc(r.deploymentsForInstance),
+            builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+        Complete(r)
+}
+
+// deploymentsForInstance requeues the opted-in Deployments of an
+// instance's namespace, to re-route their certificates when its cert
+// management changed
+func (r *CertInjectionReconciler) deploymentsForInstance(ctx context.Context, obj client.Object) []reconcile.Request {
+    var deployments appsv1.DeploymentList
+    if err := r.List(ctx, &deployments, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for i := range deployments.Items {
+        if wantsCert(&deployments.Items[i]) {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&deployments.Items[i])})
+        }
+    }
+    return requests
+}
@@ -0,0 +1,151 @@
+// src/controllers/controllers/certreissue.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "slices"
+    "sort"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionCertReissue = "CertReissue"
+
+    defaultReissueBatchSize = 5
+    // certReissuePoll is how often a batch checks for its certificates
+    // being re-issued, as the injected Secrets are not watched here
+    certReissuePoll = 30 * time.Second
+)
+
+// reconcileCertReissue finds the injected certificates of q that no longer
+// match its algorithms, security level, routes or SANs, and admits them to
+// re-issue batches while the window is open. The cert injection controller
+// re-issues the certificates of a batch; the next one starts once it
+// re-issued all of them.
+func (r *QraiopReconciler) reconcileCertReissue(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.Cryptography.CertReissue
+    if cfg == nil || !q.Spec.Cryptography.Enabled {
+        q.Status.CertReissue = nil
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionCertReissue)
+        return 0, nil
+    }
+
+    var secrets corev1.SecretList
+    if err := r.List(ctx, &secrets, client.InNamespace(q.Namespace), client.HasLabels{injectedForLabel}); err != nil {
+        return 0, err
+    }
+    var stale, inProgress []string
+    byName := make(map[string]*corev1.Secret)
+    for i := range secrets.Items {
+        s := &secrets.Items[i]
+        // Revoked certificates are replaced by deleting their Secret
+        if _, revoked := s.Annotations[qraiopv1.RevokeAnnotation]; revoked {
+            continue
+        }
+        var d appsv1.Deployment
+        err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: s.Labels[injectedForLabel]}, &d)
+        if apierrors.IsNotFound(err) {
+            continue
+        }
+        if err != nil {
+            return 0, err
+        }
+        if !wantsCert(&d) || wantedCert(q, &d).matches(s) {
+            continue
+        }
+        if s.Annotations[certReissueAnnotation] != "" {
+            inProgress = append(inProgress, s.Name)
+            continue
+        }
+        stale = append(stale, s.Name)
+        byName[s.Name] = s
+    }
+    sort.Strings(stale)
+    sort.Strings(inProgress)
+
+    st := q.Status.CertReissue
+    if st == nil {
+        st = &qraiopv1.CertReissueStatus{}
+        q.Status.CertReissue = st
+    }
+    if len(st.Pending) == 0 && len(st.InProgress) == 0 && len(stale) > 0 {
+        // The spec changed again after all certificates matched
+        st.Reissued = 0
+    }
+    for _, name := range st.InProgress {
+        if !slices.Contains(inProgress, name) {
+            st.Reissued++
+        }
+    }
+    st.Pending, st.InProgress = stale, inProgress
+
+    switch {
+    case len(stale) == 0 && len(inProgress) == 0:
+        setCondition(q, conditionCertReissue, metav1.ConditionTrue, "Compliant",
+            fmt.Sprintf("all injected certificates match the spec; %d re-issued", st.Reissued))
+        return 0, nil
+    case len(inProgress) > 0:
+        setCondition(q, conditionCertReissue, metav1.ConditionFalse, "Reissuing",
+            fmt.Sprintf("re-issuing %d certificate(s), %d waiting for a later batch", len(inProgress), len(stale)))
+        return certReissuePoll, nil
+    }
+
+    now := time.Now()
+    if cfg.Window != nil {
+        start, end, ok, err := silenceWindow(qraiopv1.SilenceSchedule{
+            Days:      cfg.Window.Days,
+            StartTime: cfg.Window.StartTime,
+            Duration:  cfg.Window.Duration,
+            TimeZone:  cfg.Window.TimeZone,
+        }, now)
+        if err != nil || !ok {
+            msg := "re-issue window never opens"
+            if err != nil {
+                msg = "invalid re-issue window: " + err.Error()
+            }
+            setCondition(q, conditionCertReissue, metav1.ConditionFalse, "InvalidWindow", msg)
+            return 0, nil
+        }
+        if start.After(now) || !end.After(now) {
+            setCondition(q, conditionCertReissue, metav1.ConditionFalse, "WaitingForWindow",
+                fmt.Sprintf("%d certificate(s) no longer match the spec, waiting for the window opening at %s",
+                    len(stale), start.UTC().Format(time.RFC3339)))
+            return start.Sub(now), nil
+        }
+    }
+
+    size := defaultReissueBatchSize
+    if cfg.BatchSize > 0 {
+        size = int(cfg.BatchSize)
+    }
+    batch := stale[:min(size, len(stale))]
+    started := now.UTC().Format(time.RFC3339)
+    for _, name := range batch {
+        s := byName[name]
+        patch := client.MergeFrom(s.DeepCopy())
+        if s.Annotations == nil {
+            s.Annotations = make(map[string]string)
+        }
+        s.Annotations[certReissueAnnotation] = started
+        if err := r.Patch(ctx, s, patch); err != nil {
+            return 0, fmt.Errorf("admitting Secret %s to re-issue: %w", name, err)
+        }
+    }
+    r.Log.Info("started certificate re-issue batch", "qraiop", client.ObjectKeyFromObject(q), "secrets", batch)
+    st.InProgress = append([]string(nil), batch...)
+    st.Pending = stale[len(batch):]
+    st.LastBatch = &metav1.Time{Time: now}
+    setCondition(q, conditionCertReissue, metav1.ConditionFalse, "Reissuing",
+        fmt.Sprintf("re-issuing %d certificate(s), %d waiting for a later batch", len(st.InProgress), len(st.Pending)))
+    return certReissuePoll, nil
+}
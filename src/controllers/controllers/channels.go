@@ -0,0 +1,253 @@
+// src/controllers/controllers/channels.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const conditionAlertChannels = "AlertChannels"
+
+// secretKeyMarkers identify channel config keys holding credentials
+var secretKeyMarkers = []string{"password", "token", "secret", "api_key", "routing_key", "service_key", "webhook_url"}
+
+func isSecretKey(key string) bool {
+    k := strings.ToLower(key)
+    for _, m := range secretKeyMarkers {
+        if strings.Contains(k, m) {
+            return true
+        }
+    }
+    return false
+}
+
+// inlineSecrets returns the credential keys ch carries in plain config
+func inlineSecrets(ch qraiopv1.AlertChannel) []string {
+    var keys []string
+    for k := range ch.Config {
+        if isSecretKey(k) {
+            keys = append(keys, k)
+        }
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// channelSecretName is the Secret inline credentials of a channel move into
+func channelSecretName(q *qraiopv1.Qraiop, channel string) string {
+    return q.Name + "-alert-" + channel
+}
+
+// channelSecret is the Secret holding the credentials of ch, or ""
+func channelSecret(q *qraiopv1.Qraiop, ch qraiopv1.AlertChannel) string {
+    switch {
+    case ch.SecretSource != nil:
+        return channelSourceSecret(q, ch.Name)
+    case ch.SecretRef != nil:
+        return ch.SecretRef.Name
+    }
+    return ""
+}
+
+// reconcileAlertChannels moves credentials found inline in channel config
+// into generated Secrets, or rejects them when the instance forbids inline
+// secrets, and checks that every referenced Secret exists. The outcome is
+// recorded in the AlertChannels condition, which gates rendering them.
+func (r *QraiopReconciler) reconcileAlertChannels(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.Monitoring.Alerting
+    if cfg == nil || len(cfg.Channels) == 0 {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionAlertChannels)
+        return nil
+    }
+
+    // The spec is patched from the stored instance, whose sealed values
+    // stay sealed, and the rendered channels follow
+    stored := storedFrom(ctx, q)
+    migrated := stored.DeepCopy()
+    channels := q.DeepCopy().Spec.Monitoring.Alerting.Channels
+    var problems []string
+    for i, ch := range cfg.Channels {
+        keys := inlineSecrets(ch)
+        if len(keys) == 0 {
+            continue
+        }
+        if ch.SecretSource != nil {
+            problems = append(problems, fmt.Sprintf("%s: move inline %s into the secret source", ch.Name, strings.Join(keys, ", ")))
+            continue
+        }
+        if cfg.ForbidInlineSecrets {
+            problems = append(problems, fmt.Sprintf("%s: inline %s forbidden, use secretRef", ch.Name, strings.Join(keys, ", ")))
+            continue
+        }
+        generated := channelSecretName(q, ch.Name)
+        if ch.SecretRef != nil && ch.SecretRef.Name != generated {
+            problems = append(problems, fmt.Sprintf("%s: move inline %s into Secret %s", ch.Name, strings.Join(keys, ", "), ch.SecretRef.Name))
+            continue
+        }
+        if err := r.migrateChannelSecret(ctx, q, generated, ch, keys); err != nil {
+            return fmt.Errorf("migrating secrets of channel %s: %w", ch.Name, err)
+        }
+        for _, out := range []*qraiopv1.AlertChannel{&migrated.Spec.Monitoring.Alerting.Channels[i], &channels[i]} {
+            for _, k := range keys {
+                delete(out.Config, k)
+            }
+            out.SecretRef = &corev1.LocalObjectReference{Name: generated}
+        }
+    }
+
+    if !equalChannels(stored.Spec.Monitoring.Alerting.Channels, migrated.Spec.Monitoring.Alerting.Channels) {
+        // Patch a copy so status collected so far isn't replaced by the response
+        if err := r.Patch(ctx, migrated, client.MergeFrom(stored)); err != nil {
+            return fmt.Errorf("removing migrated secrets from spec: %w", err)
+        }
+        stored.Spec = migrated.Spec
+        stored.ResourceVersion = migrated.ResourceVersion
+        stored.Generation = migrated.Generation
+        q.Spec.Monitoring.Alerting.Channels = channels
+        q.ResourceVersion = migrated.ResourceVersion
+        q.Generation = migrated.Generation
+        r.Log.Info("moved inline alert channel secrets into Secrets", "qraiop", client.ObjectKeyFromObject(q))
+    }
+
+    for _, ch := range q.Spec.Monitoring.Alerting.Channels {
+        if ch.SecretRef != nil && ch.SecretSource != nil {
+            problems = append(problems, fmt.Sprintf("%s: set secretRef or secretSource, not both", ch.Name))
+            continue
+        }
+        name := channelSecret(q, ch)
+        if name == "" {
+            continue
+        }
+        var secret corev1.Secret
+        err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret)
+        if apierrors.IsNotFound(err) && ch.SecretSource != nil {
+            problems = append(problems, fmt.Sprintf("%s: Secret %s not synced from %s yet", ch.Name, name, describeSource(ch.SecretSource)))
+            continue
+        }
+        if apierrors.IsNotFound(err) {
+            problems = append(problems, fmt.Sprintf("%s: Secret %s not found", ch.Name, name))
+            continue
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    if len(problems) > 0 {
+        setCondition(q, conditionAlertChannels, metav1.ConditionFalse, "InvalidChannels", strings.Join(problems, "; "))
+        return nil
+    }
+    setCondition(q, conditionAlertChannels, metav1.ConditionTrue, "Valid",
+        fmt.Sprintf("%d alert channel(s) configured", len(q.Spec.Monitoring.Alerting.Channels)))
+    return nil
+}
+
+// migrateChannelSecret writes the inline credentials of ch into the
+// generated Secret, keeping keys migrated earlier. The Secret isn't owned by
+// q: once migrated the spec references it like any other user Secret.
+func (r *QraiopReconciler) migrateChannelSecret(ctx context.Context, q *qraiopv1.Qraiop, name string, ch qraiopv1.AlertChannel, keys []string) error {
+    secret := &corev1.Secret{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      name,
+            Namespace: q.Namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Type: corev1.SecretTypeOpaque,
+        Data: make(map[string][]byte),
+    }
+    applyCommonMetadata(q, secret)
+
+    var existing corev1.Secret
+    err := r.Get(ctx, client.ObjectKeyFromObject(secret), &existing)
+    if err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    for k, v := range existing.Data {
+        secret.Data[k] = v
+    }
+    for _, k := range keys {
+        secret.Data[k] = []byte(ch.Config[k])
+    }
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, secret)
+    }
+    secret.ResourceVersion = existing.ResourceVersion
+    return r.Update(ctx, secret)
+}
+
+func equalChannels(a, b []qraiopv1.AlertChannel) bool {
+    x, _ := json.Marshal(a)
+    y, _ := json.Marshal(b)
+    return string(x) == string(y)
+}
+
+// channelFile is a channel as the monitoring component reads it from channels.json
+type channelFile struct {
+    Name   string            `json:"name"`
+    Type   string            `json:"type"`
+    Config map[string]string `json:"config,omitempty"`
+    // SecretDir holds one file per key of the channel's Secret
+    SecretDir string `json:"secretDir,omitempty"`
+}
+
+// applyAlertChannels writes the monitoring component's channels ConfigMap,
+// with the notification templates in use, and mounts it, with each
+// channel's Secret, into the Deployment
+func (r *QraiopReconciler) applyAlertChannels(ctx context.Context, q *qraiopv1.Qraiop, deployment *appsv1.Deployment) error {
+    cfg := q.Spec.Monitoring.Alerting
+    if cfg == nil || !cfg.Enabled || len(cfg.Channels) == 0 {
+        return nil
+    }
+    // Channels with forbidden or missing secrets are left out until fixed
+    if !meta.IsStatusConditionTrue(q.Status.Conditions, conditionAlertChannels) {
+        return nil
+    }
+
+    files := make([]channelFile, 0, len(cfg.Channels))
+    secrets := make(map[string]string)
+    for _, ch := range cfg.Channels {
+        f := channelFile{Name: ch.Name, Type: ch.Type, Config: ch.Config}
+        if name := channelSecret(q, ch); name != "" {
+            f.SecretDir = render.ChannelSecretDir(ch.Name)
+            secrets[ch.Name] = name
+        }
+        files = append(files, f)
+    }
+    data, err := json.MarshalIndent(files, "", "  ")
+    if err != nil {
+        return err
+    }
+    cm := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      deployment.Name + "-channels",
+            Namespace: q.Namespace,
+            Labels:    componentLabels(q, deployment.Name),
+        },
+        Data: map[string]string{render.ChannelsFile: string(data)},
+    }
+    _, templates, err := r.notificationTemplates(ctx, q)
+    if err != nil {
+        return err
+    }
+    for k, v := range templates {
+        cm.Data[k] = v
+    }
+    if err := r.createOrUpdate(ctx, q, cm); err != nil {
+        return err
+    }
+    render.AlertChannels(&deployment.Spec.Template.Spec, cm.Name, secrets)
+    return nil
+}
@@ -0,0 +1,107 @@
+// src/controllers/controllers/chaos_alerts.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// alertmanagerFor returns the Alertmanager of the Qraiop instance in the
+// experiment's namespace, or nil when there is none with alerting enabled
+func (r *ChaosExperimentReconciler) alertmanagerFor(ctx context.Context, exp *qraiopv1.ChaosExperiment) (*alerting.Alertmanager, error) {
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances, client.InNamespace(exp.Namespace)); err != nil {
+        return nil, err
+    }
+    for i := range instances.Items {
+        am, err := instanceAlertmanager(ctx, r, &instances.Items[i])
+        if err != nil || am != nil {
+            return am, err
+        }
+    }
+    return nil, nil
+}
+
+// suppressAlerts silences alerts of the target pods only, for the fault's
+// duration plus the recovery grace period. Failing to silence is reported
+// but doesn't stop the experiment.
+func (r *ChaosExperimentReconciler) suppressAlerts(ctx context.Context, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) {
+    // A blackout is judged by the alerts it raises
+    if exp.Spec.DisableAlertSuppression || isBlackout(exp) || len(targets) == 0 {
+        return
+    }
+    am, err := r.alertmanagerFor(ctx, exp)
+    if err != nil || am == nil {
+        if err != nil {
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "AlertSuppressionFailed", "unable to find Alertmanager: %v", err)
+        }
+        return
+    }
+
+    names := podNames(targets)
+    for i, n := range names {
+        names[i] = regexp.QuoteMeta(n)
+    }
+    matchers := []alerting.Matcher{
+        {Name: "namespace", Value: exp.Spec.Target.Namespace, IsEqual: true},
+        {Name: "pod", Value: strings.Join(names, "|"), IsRegex: true, IsEqual: true},
+    }
+    start := time.Now()
+    end := start.Add(experimentDuration(exp) + recoveryGracePeriod)
+    id, err := am.CreateSilence(ctx, alerting.Silence{
+        Matchers:  matchers,
+        StartsAt:  start,
+        EndsAt:    end,
+        CreatedBy: alerting.CreatedBy,
+        Comment:   fmt.Sprintf("[qraiop chaos %s/%s] %s experiment in progress", exp.Namespace, exp.Name, exp.Spec.Type),
+    })
+    if err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "AlertSuppressionFailed", "unable to silence target alerts: %v", err)
+        return
+    }
+
+    described := make([]string, 0, len(matchers))
+    for _, m := range matchers {
+        op := "="
+        if m.IsRegex {
+            op = "=~"
+        }
+        described = append(described, m.Name+op+m.Value)
+    }
+    exp.Status.AlertSuppression = &qraiopv1.AlertSuppressionStatus{
+        SilenceID: id,
+        Matchers:  described,
+        StartsAt:  metav1.NewTime(start),
+        EndsAt:    metav1.NewTime(end),
+    }
+}
+
+// liftSuppression expires the experiment's silence once it is over
+func (r *ChaosExperimentReconciler) liftSuppression(ctx context.Context, exp *qraiopv1.ChaosExperiment) error {
+    s := exp.Status.AlertSuppression
+    if s == nil || s.LiftedAt != nil {
+        return nil
+    }
+    am, err := r.alertmanagerFor(ctx, exp)
+    if err != nil {
+        return err
+    }
+    if am != nil {
+        if err := am.ExpireSilence(ctx, s.SilenceID); err != nil {
+            return fmt.Errorf("expiring silence %s: %w", s.SilenceID, err)
+        }
+    }
+    now := metav1.Now()
+    s.LiftedAt = &now
+    return nil
+}
@@ -0,0 +1,37 @@
+// src/controllers/controllers/chaos_backend.go
+package controllers
+
+import (
+    "context"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+// backendPollInterval is how often a fault run by an external tool is
+// checked while it is held
+const backendPollInterval = 10 * time.Second
+
+// +kubebuilder:rbac:groups=chaos-mesh.org,resources=podchaos;networkchaos,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=litmuschaos.io,resources=chaosengines,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=litmuschaos.io,resources=chaosresults,verbs=get;list;watch
+
+// observeBackend records in the status what the external tool running the
+// experiment's fault reports, and returns it. The tool's resources may lag
+// behind the fault's injection, so a failure to read them keeps the last
+// report.
+func (r *ChaosExperimentReconciler) observeBackend(ctx context.Context, exp *qraiopv1.ChaosExperiment, obs chaos.Observer) *qraiopv1.BackendStatus {
+    st, err := obs.Observe(ctx, r.Client, exp)
+    if err != nil {
+        if !apierrors.IsNotFound(err) {
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "BackendCheckFailed", "unable to read the %s status: %v", exp.Spec.Backend, err)
+        }
+        return exp.Status.Backend
+    }
+    exp.Status.Backend = st
+    return st
+}
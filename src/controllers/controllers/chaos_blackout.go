@@ -0,0 +1,152 @@
+// src/controllers/controllers/chaos_blackout.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+// blackoutPollInterval is how often detection alerts are looked for while
+// the monitoring pipeline is cut off
+const blackoutPollInterval = 10 * time.Second
+
+func isBlackout(exp *qraiopv1.ChaosExperiment) bool {
+    return exp.Spec.Type == "monitoring_blackout"
+}
+
+// blackoutGuardrails returns why a monitoring_blackout experiment may not
+// run, or ""
+func blackoutGuardrails(exp *qraiopv1.ChaosExperiment) string {
+    switch {
+    case exp.Spec.Blackout == nil || len(exp.Spec.Blackout.DetectionAlerts) == 0:
+        return "monitoring_blackout needs spec.blackout.detectionAlerts"
+    case experimentDuration(exp) > qraiopv1.MaxBlackoutDuration*time.Second:
+        return fmt.Sprintf("monitoring_blackout is limited to %d seconds", qraiopv1.MaxBlackoutDuration)
+    case exp.Spec.Ramp != nil:
+        return "monitoring_blackout can't be ramped"
+    }
+    return ""
+}
+
+// blackoutAlertmanager returns the Alertmanager detection alerts are
+// looked for in
+func (r *ChaosExperimentReconciler) blackoutAlertmanager(ctx context.Context, exp *qraiopv1.ChaosExperiment) (*alerting.Alertmanager, error) {
+    if url := exp.Spec.Blackout.AlertmanagerURL; url != "" {
+        httpClient, err := newHTTPClient(ctx, r, exp.Namespace, nil)
+        if err != nil {
+            return nil, err
+        }
+        return &alerting.Alertmanager{URL: url, HTTP: httpClient}, nil
+    }
+    am, err := r.alertmanagerFor(ctx, exp)
+    if err == nil && am == nil {
+        err = fmt.Errorf("no Qraiop instance with alerting in %s; set spec.blackout.alertmanagerURL", exp.Namespace)
+    }
+    return am, err
+}
+
+// blackoutDetections returns the names of the detection alerts firing
+func (r *ChaosExperimentReconciler) blackoutDetections(ctx context.Context, exp *qraiopv1.ChaosExperiment) ([]string, error) {
+    am, err := r.blackoutAlertmanager(ctx, exp)
+    if err != nil {
+        return nil, err
+    }
+    alerts, err := am.Alerts(ctx, exp.Spec.Blackout.DetectionAlerts...)
+    if err != nil {
+        return nil, err
+    }
+    seen := make(map[string]bool)
+    var names []string
+    for _, a := range alerts {
+        name := a.Labels["alertname"]
+        if a.Status.State != "active" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names, nil
+}
+
+// preflightBlackout returns why a blackout can't be injected: a guardrail
+// is crossed, the detection alerts can't be read, or they already fire and
+// so couldn't tell the blackout apart
+func (r *ChaosExperimentReconciler) preflightBlackout(ctx context.Context, exp *qraiopv1.ChaosExperiment) string {
+    if reason := blackoutGuardrails(exp); reason != "" {
+        return reason
+    }
+    firing, err := r.blackoutDetections(ctx, exp)
+    if err != nil {
+        return "unable to read detection alerts: " + err.Error()
+    }
+    if len(firing) > 0 {
+        return "detection alerts already firing before injection: " + strings.Join(firing, ", ")
+    }
+    return ""
+}
+
+// observeBlackout holds a monitoring blackout until a detection alert
+// fires, reverting it right away to keep the blind time short, and then
+// waits for the monitoring pods to recover. An undetected blackout is
+// reverted after its duration and fails the experiment.
+func (r *ChaosExperimentReconciler) observeBlackout(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault) (ctrl.Result, error) {
+    cleared := meta.FindStatusCondition(exp.Status.Conditions, conditionFaultsCleared)
+    if cleared != nil && cleared.Status == metav1.ConditionTrue {
+        outcome := ""
+        if st := exp.Status.Blackout; st != nil && st.Latency != nil {
+            outcome = fmt.Sprintf("blackout detected after %s by %s", st.Latency.Duration.Round(time.Second), strings.Join(st.Alerts, ", "))
+        }
+        return r.awaitRecovery(ctx, exp, cleared.LastTransitionTime.Time, outcome)
+    }
+
+    firing, err := r.blackoutDetections(ctx, exp)
+    if err != nil {
+        // An unreachable detector detects nothing; the duration bounds the wait
+        r.Log.Error(err, "unable to read detection alerts", "chaosexperiment", exp.Name)
+    }
+    now := time.Now()
+    if len(firing) > 0 {
+        detectedAt := metav1.NewTime(now)
+        exp.Status.Blackout = &qraiopv1.BlackoutStatus{
+            DetectedAt: &detectedAt,
+            Latency:    &metav1.Duration{Duration: now.Sub(exp.Status.StartTime.Time)},
+            Alerts:     firing,
+        }
+        if err := r.revert(ctx, exp, fault, "BlackoutDetected"); err != nil {
+            return ctrl.Result{}, err
+        }
+        r.Recorder.Eventf(exp, corev1.EventTypeNormal, "BlackoutDetected", "blackout detected after %s by %s, fault reverted",
+            exp.Status.Blackout.Latency.Duration.Round(time.Second), strings.Join(firing, ", "))
+        if err := r.Status().Update(ctx, exp); err != nil {
+            return ctrl.Result{}, err
+        }
+        return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+    }
+
+    end := exp.Status.StartTime.Add(experimentDuration(exp))
+    if remaining := end.Sub(now); remaining > 0 {
+        if blackoutPollInterval < remaining {
+            remaining = blackoutPollInterval
+        }
+        return ctrl.Result{RequeueAfter: remaining}, nil
+    }
+    if err := r.revert(ctx, exp, fault, "Completed"); err != nil {
+        return ctrl.Result{}, err
+    }
+    r.Recorder.Eventf(exp, corev1.EventTypeWarning, "BlackoutUndetected", "no detection alert fired within %s, fault reverted", experimentDuration(exp))
+    return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed,
+        fmt.Sprintf("blackout not detected within %s", experimentDuration(exp)))
+}
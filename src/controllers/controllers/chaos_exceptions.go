@@ -0,0 +1,318 @@
+// src/controllers/controllers/chaos_exceptions.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "time"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+const (
+    // exceptionMargin keeps an exception past the experiment's recovery,
+    // for the helper pods' cleanup
+    exceptionMargin = 10 * time.Minute
+    // kyvernoTTLLabel makes Kyverno's cleanup controller delete an exception
+    // the operator never got to revoke
+    kyvernoTTLLabel = "cleanup.kyverno.io/ttl"
+    // gatekeeperExclusionsAnnotation records on the Gatekeeper Config which
+    // experiments each namespace is excluded for, and until when
+    gatekeeperExclusionsAnnotation = "qraiop.io/chaos-exclusions"
+)
+
+var (
+    kyvernoExceptionGK  = schema.GroupKind{Group: "kyverno.io", Kind: "PolicyException"}
+    gatekeeperConfigGVK = schema.GroupVersionKind{Group: "config.gatekeeper.sh", Version: "v1alpha1", Kind: "Config"}
+    // gatekeeperConfig is the Config object Gatekeeper reads
+    gatekeeperConfig = client.ObjectKey{Namespace: "gatekeeper-system", Name: "config"}
+)
+
+// +kubebuilder:rbac:groups=kyverno.io,resources=policyexceptions,verbs=get;create;delete
+// +kubebuilder:rbac:groups=config.gatekeeper.sh,resources=configs,verbs=get;update
+
+// grantPolicyExceptions exempts the experiment's helper pods from the
+// policies of its spec, until its duration and recovery have passed
+func (r *ChaosExperimentReconciler) grantPolicyExceptions(ctx context.Context, exp *qraiopv1.ChaosExperiment) error {
+    spec := exp.Spec.PolicyExceptions
+    if spec == nil || exp.Status.PolicyExceptions != nil {
+        return nil
+    }
+    now := time.Now()
+    ttl := experimentDuration(exp) + recoveryGracePeriod + exceptionMargin
+    st := &qraiopv1.PolicyExceptionStatus{
+        Engine:    spec.Engine,
+        GrantedAt: metav1.NewTime(now),
+        ExpiresAt: metav1.NewTime(now.Add(ttl)),
+    }
+    switch spec.Engine {
+    case qraiopv1.PolicyEngineKyverno:
+        name, err := r.createKyvernoException(ctx, exp, ttl)
+        if err != nil {
+            return err
+        }
+        st.Exception = exp.Namespace + "/" + name
+    case qraiopv1.PolicyEngineGatekeeper:
+        namespaces := exceptionNamespaces(exp)
+        if err := r.updateGatekeeperExclusions(ctx, exp, namespaces, st.ExpiresAt.Time); err != nil {
+            return err
+        }
+        st.Namespaces = namespaces
+    default:
+        return fmt.Errorf("unsupported policy engine %q", spec.Engine)
+    }
+    exp.Status.PolicyExceptions = st
+    return nil
+}
+
+// revokePolicyExceptions removes the exceptions granted to the experiment
+func (r *ChaosExperimentReconciler) revokePolicyExceptions(ctx context.Context, exp *qraiopv1.ChaosExperiment) error {
+    st := exp.Status.PolicyExceptions
+    if st == nil || st.RevokedAt != nil {
+        return nil
+    }
+    switch st.Engine {
+    case qraiopv1.PolicyEngineKyverno:
+        mapping, err := r.RESTMapper().RESTMapping(kyvernoExceptionGK)
+        if err != nil {
+            return err
+        }
+        pe := &unstructured.Unstructured{}
+        pe.SetGroupVersionKind(mapping.GroupVersionKind)
+        pe.SetNamespace(exp.Namespace)
+        pe.SetName(policyExceptionName(exp))
+        if err := r.Delete(ctx, pe); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    case qraiopv1.PolicyEngineGatekeeper:
+        if err := r.updateGatekeeperExclusions(ctx, exp, nil, time.Time{}); err != nil {
+            return err
+        }
+    }
+    now := metav1.Now()
+    st.RevokedAt = &now
+    return nil
+}
+
+func policyExceptionName(exp *qraiopv1.ChaosExperiment) string {
+    return "qraiop-chaos-" + exp.Name
+}
+
+// exceptionNamespaces are where the helper pods run: probe Jobs in the
+// experiment's namespace, fillers in the target's
+func exceptionNamespaces(exp *qraiopv1.ChaosExperiment) []string {
+    if exp.Namespace == exp.Spec.Target.Namespace {
+        return []string{exp.Namespace}
+    }
+    namespaces := []string{exp.Namespace, exp.Spec.Target.Namespace}
+    sort.Strings(namespaces)
+    return namespaces
+}
+
+// createKyvernoException creates a PolicyException matching only the
+// experiment's helper pods and Jobs, in whichever version of the API the
+// installed Kyverno serves
+func (r *ChaosExperimentReconciler) createKyvernoException(ctx context.Context, exp *qraiopv1.ChaosExperiment, ttl time.Duration) (string, error) {
+    if len(exp.Spec.PolicyExceptions.Policies) == 0 {
+        return "", fmt.Errorf("kyverno policy exceptions need the policies and rules to except")
+    }
+    mapping, err := r.RESTMapper().RESTMapping(kyvernoExceptionGK)
+    if err != nil {
+        if meta.IsNoMatchError(err) {
+            return "", fmt.Errorf("kyverno is not installed: no PolicyException API")
+        }
+        return "", err
+    }
+
+    var exceptions []interface{}
+    for _, p := range exp.Spec.PolicyExceptions.Policies {
+        rules := make([]interface{}, 0, len(p.Rules))
+        for _, rule := range p.Rules {
+            // Kyverno generates controller rules for pod policies
+            rules = append(rules, rule, "autogen-"+rule, "autogen-cronjob-"+rule)
+        }
+        exceptions = append(exceptions, map[string]interface{}{"policyName": p.Policy, "ruleNames": rules})
+    }
+    kinds := []interface{}{"Pod", "Job"}
+    match := []interface{}{
+        map[string]interface{}{"resources": map[string]interface{}{
+            "kinds":      kinds,
+            "namespaces": []interface{}{exp.Namespace},
+            "selector":   map[string]interface{}{"matchLabels": map[string]interface{}{chaos.ProbeLabel: exp.Name}},
+        }},
+        map[string]interface{}{"resources": map[string]interface{}{
+            "kinds":      kinds,
+            "namespaces": []interface{}{exp.Spec.Target.Namespace},
+            "selector": map[string]interface{}{
+                "matchLabels":      map[string]interface{}{chaos.TargetLabel: exp.Name},
+                "matchExpressions": []interface{}{map[string]interface{}{"key": chaos.RoleLabel, "operator": "Exists"}},
+            },
+        }},
+    }
+
+    pe := &unstructured.Unstructured{}
+    pe.SetGroupVersionKind(mapping.GroupVersionKind)
+    pe.SetNamespace(exp.Namespace)
+    pe.SetName(policyExceptionName(exp))
+    pe.SetLabels(map[string]string{
+        chaos.TargetLabel: exp.Name,
+        kyvernoTTLLabel:   ttl.Round(time.Minute).String(),
+    })
+    pe.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(exp, qraiopv1.GroupVersion.WithKind("ChaosExperiment"))})
+    if err := unstructured.SetNestedSlice(pe.Object, exceptions, "spec", "exceptions"); err != nil {
+        return "", err
+    }
+    if err := unstructured.SetNestedSlice(pe.Object, match, "spec", "match", "any"); err != nil {
+        return "", err
+    }
+    if err := r.Create(ctx, pe); err != nil && !apierrors.IsAlreadyExists(err) {
+        return "", err
+    }
+    return pe.GetName(), nil
+}
+
+// gatekeeperExclusion is why and until when a namespace is excluded
+type gatekeeperExclusion struct {
+    Experiments []string  `json:"experiments"`
+    Until       time.Time `json:"until"`
+}
+
+// updateGatekeeperExclusions adds the experiment to the exclusions of
+// namespaces, or removes it from all when namespaces is nil. A namespace
+// is excluded from Gatekeeper's webhook while some experiment whose
+// exclusion hasn't lapsed needs it. Only the match entries the operator
+// added are touched.
+func (r *ChaosExperimentReconciler) updateGatekeeperExclusions(ctx context.Context, exp *qraiopv1.ChaosExperiment, namespaces []string, until time.Time) error {
+    cfg := &unstructured.Unstructured{}
+    cfg.SetGroupVersionKind(gatekeeperConfigGVK)
+    if err := r.Get(ctx, gatekeeperConfig, cfg); err != nil {
+        if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+            if namespaces == nil {
+                return nil
+            }
+            return fmt.Errorf("gatekeeper Config %s not found", gatekeeperConfig)
+        }
+        return err
+    }
+
+    exclusions := make(map[string]*gatekeeperExclusion)
+    if raw, ok := cfg.GetAnnotations()[gatekeeperExclusionsAnnotation]; ok {
+        if err := json.Unmarshal([]byte(raw), &exclusions); err != nil {
+            return fmt.Errorf("annotation %s: %w", gatekeeperExclusionsAnnotation, err)
+        }
+    }
+    key := exp.Namespace + "/" + exp.Name
+    now := time.Now()
+    for ns, e := range exclusions {
+        var kept []string
+        for _, k := range e.Experiments {
+            if k != key {
+                kept = append(kept, k)
+            }
+        }
+        e.Experiments = kept
+        if len(kept) == 0 || now.After(e.Until) {
+            delete(exclusions, ns)
+        }
+    }
+    for _, ns := range namespaces {
+        e, ok := exclusions[ns]
+        if !ok {
+            e = &gatekeeperExclusion{}
+            exclusions[ns] = e
+        }
+        e.Experiments = append(e.Experiments, key)
+        if until.After(e.Until) {
+            e.Until = until
+        }
+    }
+
+    match, _, err := unstructured.NestedSlice(cfg.Object, "spec", "match")
+    if err != nil {
+        return err
+    }
+    // Drop the operator's entries, then add one per excluded namespace
+    // that isn't excluded by the cluster admins already
+    var kept []interface{}
+    for _, m := range match {
+        if ownedExclusion(m, cfg.GetAnnotations()[gatekeeperExclusionsAnnotation]) {
+            continue
+        }
+        kept = append(kept, m)
+        entry, ok := m.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        processes, _, _ := unstructured.NestedStringSlice(entry, "processes")
+        for _, p := range processes {
+            if p != "webhook" && p != "*" {
+                continue
+            }
+            excludedNS, _, _ := unstructured.NestedStringSlice(entry, "excludedNamespaces")
+            for _, ns := range excludedNS {
+                delete(exclusions, ns)
+            }
+        }
+    }
+    excluded := make([]string, 0, len(exclusions))
+    for ns := range exclusions {
+        excluded = append(excluded, ns)
+    }
+    sort.Strings(excluded)
+    for _, ns := range excluded {
+        kept = append(kept, map[string]interface{}{
+            "excludedNamespaces": []interface{}{ns},
+            "processes":          []interface{}{"webhook"},
+        })
+    }
+    if err := unstructured.SetNestedSlice(cfg.Object, kept, "spec", "match"); err != nil {
+        return err
+    }
+
+    annotations := cfg.GetAnnotations()
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    if len(exclusions) == 0 {
+        delete(annotations, gatekeeperExclusionsAnnotation)
+    } else {
+        raw, err := json.Marshal(exclusions)
+        if err != nil {
+            return err
+        }
+        annotations[gatekeeperExclusionsAnnotation] = string(raw)
+    }
+    cfg.SetAnnotations(annotations)
+    return r.Update(ctx, cfg)
+}
+
+// ownedExclusion reports whether a match entry of the Gatekeeper Config is
+// one the operator added: a single namespace, excluded from the webhook
+// only, that the exclusions annotation lists
+func ownedExclusion(entry interface{}, annotation string) bool {
+    m, ok := entry.(map[string]interface{})
+    if !ok || annotation == "" {
+        return false
+    }
+    namespaces, _, _ := unstructured.NestedStringSlice(m, "excludedNamespaces")
+    processes, _, _ := unstructured.NestedStringSlice(m, "processes")
+    if len(namespaces) != 1 || len(processes) != 1 || processes[0] != "webhook" {
+        return false
+    }
+    var exclusions map[string]json.RawMessage
+    if err := json.Unmarshal([]byte(annotation), &exclusions); err != nil {
+        return false
+    }
+    _, owned := exclusions[namespaces[0]]
+    return owned
+}
@@ -0,0 +1,70 @@
+// src/controllers/controllers/chaos_probes.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+const defaultProbeInterval = 10 * time.Second
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete;deletecollection
+
+// checkProbes runs the experiment's steady-state probes and records their
+// results. It returns the first probe that reached its failure threshold,
+// or, with strict set, that failed at all, and whether any is still running.
+func (r *ChaosExperimentReconciler) checkProbes(ctx context.Context, exp *qraiopv1.ChaosExperiment, strict bool) (string, bool, error) {
+    previous := make(map[string]qraiopv1.ProbeStatus)
+    for _, s := range exp.Status.Probes {
+        previous[s.Name] = s
+    }
+
+    var failed string
+    pending := false
+    results := make([]qraiopv1.ProbeStatus, 0, len(exp.Spec.Probes))
+    for _, p := range exp.Spec.Probes {
+        st := previous[p.Name]
+        st.Name = p.Name
+        running, failure, err := chaos.RunProbe(ctx, r.Client, exp, p)
+        if err != nil {
+            return "", false, fmt.Errorf("probe %s: %w", p.Name, err)
+        }
+        if running {
+            pending = true
+            results = append(results, st)
+            continue
+        }
+        now := metav1.Now()
+        st.LastChecked = &now
+        if failure == nil {
+            st.Healthy, st.ConsecutiveFailures, st.Message = true, 0, ""
+        } else {
+            st.Healthy = false
+            st.ConsecutiveFailures++
+            st.Message = failure.Error()
+            threshold := p.FailureThreshold
+            if threshold < 1 || strict {
+                threshold = 1
+            }
+            if failed == "" && st.ConsecutiveFailures >= threshold {
+                failed = fmt.Sprintf("probe %s failed %d time(s): %s", p.Name, st.ConsecutiveFailures, st.Message)
+            }
+        }
+        results = append(results, st)
+    }
+    exp.Status.Probes = results
+    return failed, pending, nil
+}
+
+func probeInterval(exp *qraiopv1.ChaosExperiment) time.Duration {
+    if exp.Spec.ProbeInterval.Duration > 0 {
+        return exp.Spec.ProbeInterval.Duration
+    }
+    return defaultProbeInterval
+}
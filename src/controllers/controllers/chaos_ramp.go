@@ -0,0 +1,173 @@
+// src/controllers/controllers/chaos_ramp.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+// Defaults of an intensity ramp
+const (
+    defaultRampStart    = 5
+    defaultRampStep     = 5
+    defaultRampInterval = 5 * time.Minute
+)
+
+// maxPercentage is the share of pods a ramp ends at
+func maxPercentage(exp *qraiopv1.ChaosExperiment) int {
+    if exp.Spec.Percentage <= 0 || exp.Spec.Percentage > 100 {
+        return 100
+    }
+    return exp.Spec.Percentage
+}
+
+// rampStart is the share of pods a ramp starts at
+func rampStart(exp *qraiopv1.ChaosExperiment) int {
+    start := exp.Spec.Ramp.StartPercentage
+    if start <= 0 {
+        start = defaultRampStart
+    }
+    if max := maxPercentage(exp); start > max {
+        return max
+    }
+    return start
+}
+
+func rampInterval(exp *qraiopv1.ChaosExperiment) time.Duration {
+    if d := exp.Spec.Ramp.Interval.Duration; d > 0 {
+        return d
+    }
+    return defaultRampInterval
+}
+
+// startRamp records the first step of the ramp of exp
+func startRamp(exp *qraiopv1.ChaosExperiment, targets int, now metav1.Time) {
+    next := metav1.NewTime(now.Add(rampInterval(exp)))
+    exp.Status.Ramp = &qraiopv1.RampStatus{
+        Percentage: rampStart(exp),
+        NextStepAt: &next,
+        Steps: []qraiopv1.RampStep{{
+            At:         now,
+            Percentage: rampStart(exp),
+            Targets:    targets,
+            Reason:     qraiopv1.RampStart,
+        }},
+    }
+}
+
+// stepRamp takes the next step of the ramp once it is due: one step up,
+// or one step down while an SLO warning fires in the target namespace.
+// The fault is reverted and injected into a share of the matching pods
+// drawn with the experiment's seed. It returns when the next step is due.
+func (r *ChaosExperimentReconciler) stepRamp(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault) (time.Duration, error) {
+    st := exp.Status.Ramp
+    if st == nil || st.NextStepAt == nil {
+        return 0, nil
+    }
+    now := metav1.Now()
+    if wait := st.NextStepAt.Sub(now.Time); wait > 0 {
+        return wait, nil
+    }
+    interval := rampInterval(exp)
+    next := metav1.NewTime(now.Add(interval))
+    st.NextStepAt = &next
+
+    step := exp.Spec.Ramp.StepPercentage
+    if step <= 0 {
+        step = defaultRampStep
+    }
+    percentage, reason, message := st.Percentage, "", ""
+    firing, err := r.sloWarnings(ctx, exp)
+    switch {
+    case err != nil:
+        // Without knowing the SLOs hold, the ramp holds
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "RampHeld", "unable to read SLO alerts: %v", err)
+    case len(firing) > 0:
+        percentage = st.Percentage - step
+        if start := rampStart(exp); percentage < start {
+            percentage = start
+        }
+        reason, message = qraiopv1.RampBackoff, "SLO warnings firing: "+strings.Join(firing, ", ")
+    default:
+        percentage = st.Percentage + step
+        if max := maxPercentage(exp); percentage > max {
+            percentage = max
+        }
+        reason = qraiopv1.RampIncrease
+    }
+    if percentage == st.Percentage {
+        return interval, r.Status().Update(ctx, exp)
+    }
+
+    pods, err := chaos.MatchingPods(ctx, r.Client, exp.Spec.Target)
+    if err != nil {
+        return 0, err
+    }
+    targets := chaos.SelectTargets(pods, percentage, chaos.Seed(exp))
+    if err := fault.Cleanup(ctx, r.Client, exp); err != nil {
+        return 0, err
+    }
+    st.Percentage = percentage
+    st.Steps = append(st.Steps, qraiopv1.RampStep{
+        At:         now,
+        Percentage: percentage,
+        Targets:    len(targets),
+        Reason:     reason,
+        Message:    message,
+    })
+    exp.Status.Targets = podNames(targets)
+    if chaos.NodeScoped(exp.Spec.Type) {
+        exp.Status.Nodes = chaos.TargetNodes(targets)
+    }
+    // Record the targets before touching them so an abort always knows what to revert
+    if err := r.Status().Update(ctx, exp); err != nil {
+        return 0, err
+    }
+    if err := fault.Inject(ctx, r.Client, exp, targets); err != nil {
+        return 0, err
+    }
+    r.Recorder.Eventf(exp, corev1.EventTypeNormal, "RampStep", "%s to %d%%: %s injected into %d pod(s)",
+        strings.ToLower(reason), percentage, exp.Spec.Type, len(targets))
+    return interval, nil
+}
+
+// sloWarnings returns the names of the active alerts of the target
+// namespace that back the ramp off. Alerts silenced for the experiment's
+// own targets are not active, so they don't count.
+func (r *ChaosExperimentReconciler) sloWarnings(ctx context.Context, exp *qraiopv1.ChaosExperiment) ([]string, error) {
+    am, err := r.alertmanagerFor(ctx, exp)
+    if err != nil || am == nil {
+        return nil, err
+    }
+    filter := []string{fmt.Sprintf("namespace=%q", exp.Spec.Target.Namespace)}
+    if len(exp.Spec.Ramp.BackoffAlerts) > 0 {
+        filter = append(filter, exp.Spec.Ramp.BackoffAlerts...)
+    } else {
+        filter = append(filter, `severity="warning"`)
+    }
+    alerts, err := am.Alerts(ctx, filter...)
+    if err != nil {
+        return nil, err
+    }
+    seen := make(map[string]bool)
+    var names []string
+    for _, a := range alerts {
+        name := a.Labels["alertname"]
+        if a.Status.State != "active" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names, nil
+}
@@ -0,0 +1,58 @@
+// src/controllers/controllers/chaos_report.go
+package controllers
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// ReportLabel marks the ConfigMaps holding experiment reports
+const ReportLabel = "qraiop.io/chaos-report"
+
+// storeReport writes the JUnit and HTML reports of a finished experiment to
+// the ConfigMap <experiment>-report, owned by the experiment
+func (r *ChaosExperimentReconciler) storeReport(ctx context.Context, exp *qraiopv1.ChaosExperiment) error {
+    experiments := []qraiopv1.ChaosExperiment{*exp}
+    junit, err := report.JUnit(experiments)
+    if err != nil {
+        return err
+    }
+    html, err := report.HTML(experiments)
+    if err != nil {
+        return err
+    }
+
+    cm := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      exp.Name + "-report",
+            Namespace: exp.Namespace,
+            Labels:    map[string]string{ReportLabel: exp.Name},
+        },
+        Data: map[string]string{
+            "junit.xml":   string(junit),
+            "report.html": string(html),
+        },
+    }
+    if err := ctrl.SetControllerReference(exp, cm, r.Scheme); err != nil {
+        return err
+    }
+
+    var existing corev1.ConfigMap
+    err = r.Get(ctx, client.ObjectKeyFromObject(cm), &existing)
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, cm)
+    }
+    if err != nil {
+        return err
+    }
+    cm.ResourceVersion = existing.ResourceVersion
+    return r.Update(ctx, cm)
+}
@@ -0,0 +1,76 @@
+// src/controllers/controllers/chaos_slo.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "time"
+
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// sloBurnCheckInterval is how often a held fault checks the burn-rate
+// alerts of the target namespace's SLOs
+const sloBurnCheckInterval = 30 * time.Second
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=monitoringtargets,verbs=get;list;watch
+
+// namespaceHasSLOs reports whether a Qraiop instance or MonitoringTarget
+// defines SLOs in namespace
+func namespaceHasSLOs(ctx context.Context, c client.Reader, namespace string) (bool, error) {
+    var instances qraiopv1.QraiopList
+    if err := c.List(ctx, &instances, client.InNamespace(namespace)); err != nil {
+        return false, err
+    }
+    for _, q := range instances.Items {
+        if q.Spec.Monitoring.Enabled && len(q.Spec.Monitoring.SLOs) > 0 {
+            return true, nil
+        }
+    }
+    var targets qraiopv1.MonitoringTargetList
+    if err := c.List(ctx, &targets, client.InNamespace(namespace)); err != nil {
+        return false, err
+    }
+    for _, t := range targets.Items {
+        if len(t.Spec.SLOs) > 0 {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+// sloBurns returns the page-level burn-rate alerts active in the target
+// namespace, and whether there are SLOs there to watch at all. The
+// experiment's silences match its target pods, never these alerts.
+func (r *ChaosExperimentReconciler) sloBurns(ctx context.Context, exp *qraiopv1.ChaosExperiment) ([]string, bool, error) {
+    watched, err := namespaceHasSLOs(ctx, r.Client, exp.Spec.Target.Namespace)
+    if err != nil || !watched {
+        return nil, false, err
+    }
+    am, err := r.alertmanagerFor(ctx, exp)
+    if err != nil || am == nil {
+        return nil, false, err
+    }
+    alerts, err := am.Alerts(ctx,
+        fmt.Sprintf("alertname=%q", qraiopv1.SLOBurnRateAlert),
+        `severity="critical"`,
+        fmt.Sprintf("namespace=%q", exp.Spec.Target.Namespace))
+    if err != nil {
+        return nil, true, err
+    }
+    seen := make(map[string]bool)
+    var burning []string
+    for _, a := range alerts {
+        name := a.Labels["slo"] + " (" + a.Labels["window"] + ")"
+        if a.Status.State != "active" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        burning = append(burning, name)
+    }
+    sort.Strings(burning)
+    return burning, true, nil
+}
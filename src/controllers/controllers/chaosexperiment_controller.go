@@ -0,0 +1,487 @@
+// src/controllers/controllers/chaosexperiment_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/go-logr/logr"
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+const (
+    // chaosCleanupFinalizer keeps an experiment around until its faults are reverted
+    chaosCleanupFinalizer = "qraiop.io/chaos-cleanup"
+
+    conditionFaultsCleared = "FaultsCleared"
+
+    defaultExperimentDuration = 60 * time.Second
+    // recoveryGracePeriod is how long targets get to recover once the fault is reverted
+    recoveryGracePeriod = 2 * time.Minute
+    // nodeSafeguardInterval is how often nodes under a pressure fault are
+    // checked for kubelet pressure
+    nodeSafeguardInterval = 5 * time.Second
+)
+
+type ChaosExperimentReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;patch;delete;deletecollection
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+func (r *ChaosExperimentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("chaosexperiment", req.NamespacedName)
+
+    var exp qraiopv1.ChaosExperiment
+    if err := r.Get(ctx, req.NamespacedName, &exp); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+
+    fault, faultErr := chaos.ForExperiment(&exp)
+
+    if !exp.DeletionTimestamp.IsZero() {
+        if controllerutil.ContainsFinalizer(&exp, chaosCleanupFinalizer) {
+            if fault != nil {
+                if err := fault.Cleanup(ctx, r.Client, &exp); err != nil {
+                    log.Error(err, "unable to revert faults of deleted experiment")
+                    return ctrl.Result{}, err
+                }
+            }
+            if err := r.liftSuppression(ctx, &exp); err != nil {
+                log.Error(err, "unable to lift alert suppression of deleted experiment")
+            }
+            if err := r.revokePolicyExceptions(ctx, &exp); err != nil {
+                log.Error(err, "unable to revoke policy exceptions of deleted experiment")
+                return ctrl.Result{}, err
+            }
+            controllerutil.RemoveFinalizer(&exp, chaosCleanupFinalizer)
+            return ctrl.Result{}, r.Update(ctx, &exp)
+        }
+        return ctrl.Result{}, nil
+    }
+
+    if exp.Status.IsFinished() {
+        return ctrl.Result{}, nil
+    }
+    if faultErr != nil {
+        return r.finish(ctx, &exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, faultErr.Error())
+    }
+
+    if !controllerutil.ContainsFinalizer(&exp, chaosCleanupFinalizer) {
+        controllerutil.AddFinalizer(&exp, chaosCleanupFinalizer)
+        if err := r.Update(ctx, &exp); err != nil {
+            return ctrl.Result{}, err
+        }
+    }
+
+    if exp.Spec.Abort {
+        return r.abort(ctx, &exp, fault)
+    }
+
+    switch exp.Status.Phase {
+    case "":
+        return r.resolve(ctx, &exp)
+    case qraiopv1.ExperimentPending:
+        return r.inject(ctx, &exp, fault)
+    case qraiopv1.ExperimentRunning:
+        if isBlackout(&exp) {
+            return r.observeBlackout(ctx, &exp, fault)
+        }
+        return r.observe(ctx, &exp, fault)
+    }
+    return ctrl.Result{}, nil
+}
+
+// resolve records what the target of a new experiment selects and moves it
+// to Pending, before any probe runs or fault is injected
+func (r *ChaosExperimentReconciler) resolve(ctx context.Context, exp *qraiopv1.ChaosExperiment) (ctrl.Result, error) {
+    seed := chaos.Seed(exp)
+    pods, targets, err := chaos.ResolveTargets(ctx, r.Client, exp, seed)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    exp.Status.Phase = qraiopv1.ExperimentPending
+    exp.Status.Resolution = chaos.Resolution(pods, targets, seed, metav1.Now())
+    if err := r.Status().Update(ctx, exp); err != nil {
+        return ctrl.Result{}, err
+    }
+    return ctrl.Result{Requeue: true}, nil
+}
+
+// inject resolves the targets and applies the fault
+func (r *ChaosExperimentReconciler) inject(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault) (ctrl.Result, error) {
+    if isBlackout(exp) {
+        if reason := r.preflightBlackout(ctx, exp); reason != "" {
+            return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, reason)
+        }
+    }
+    // A namespace whose SLOs have spent their error budget takes no more risk
+    exhausted, err := exhaustedSLOs(ctx, r.Client, exp.Spec.Target.Namespace)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    if len(exhausted) > 0 {
+        return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed,
+            "error budget exhausted for SLO "+strings.Join(exhausted, ", "))
+    }
+    // Probe Jobs already need their exceptions
+    if err := r.grantPolicyExceptions(ctx, exp); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "PolicyExceptionFailed", "unable to grant policy exceptions: %v", err)
+        return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, "policy exceptions: "+err.Error())
+    }
+    // Dependencies have to be healthy before anything is broken
+    if len(exp.Spec.Probes) > 0 {
+        failed, pending, err := r.checkProbes(ctx, exp, true)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        if failed != "" {
+            return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, "steady state not met before injection: "+failed)
+        }
+        if pending {
+            exp.Status.Phase = qraiopv1.ExperimentPending
+            if err := r.Status().Update(ctx, exp); err != nil {
+                return ctrl.Result{}, err
+            }
+            return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+        }
+    }
+
+    // The seed of the resolution keeps the draw the one that was reviewed,
+    // though pods may have come and gone while the experiment was Pending
+    seed := chaos.Seed(exp)
+    pods, targets, err := chaos.ResolveTargets(ctx, r.Client, exp, seed)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    exp.Status.Resolution = chaos.Resolution(pods, targets, seed, metav1.Now())
+    if len(pods) == 0 {
+        return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, "no running pods match the target")
+    }
+
+    // Killed pods count against their disruption budgets; other faults
+    // leave the pods in place
+    if chaos.Disrupts(exp.Spec.Type) {
+        preflight, err := chaos.CheckBudgets(ctx, r.Client, exp.Spec.Target.Namespace, targets)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        exp.Status.Preflight = preflight
+        if preflight.Violations > 0 && !exp.Spec.IgnorePDB {
+            return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, budgetViolations(preflight))
+        }
+    }
+
+    // A ramp starts with a share of the targets, drawn with the same seed
+    now := metav1.Now()
+    injected := targets
+    if exp.Spec.Ramp != nil {
+        injected = chaos.SelectTargets(pods, rampStart(exp), seed)
+        startRamp(exp, len(injected), now)
+    }
+    exp.Status.Phase = qraiopv1.ExperimentRunning
+    exp.Status.StartTime = &now
+    exp.Status.BaselineReady = chaos.ReadyCount(pods)
+    exp.Status.Targets = podNames(injected)
+    if chaos.NodeScoped(exp.Spec.Type) {
+        exp.Status.Nodes = chaos.TargetNodes(injected)
+    }
+    // Alerts of every pod the ramp may reach are silenced up front
+    r.suppressAlerts(ctx, exp, targets)
+    // Record the targets before touching them so an abort always knows what to revert
+    if err := r.Status().Update(ctx, exp); err != nil {
+        return ctrl.Result{}, err
+    }
+
+    if err := fault.Inject(ctx, r.Client, exp, injected); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "InjectionFailed", "unable to inject %s: %v", exp.Spec.Type, err)
+        if cleanupErr := fault.Cleanup(ctx, r.Client, exp); cleanupErr != nil {
+            return ctrl.Result{}, cleanupErr
+        }
+        return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, err.Error())
+    }
+    r.Recorder.Eventf(exp, corev1.EventTypeNormal, "FaultInjected", "%s injected into %d pod(s) in %s",
+        exp.Spec.Type, len(injected), exp.Spec.Target.Namespace)
+    wait := experimentDuration(exp)
+    if exp.Spec.Ramp != nil && rampInterval(exp) < wait {
+        wait = rampInterval(exp)
+    }
+    return ctrl.Result{RequeueAfter: wait}, nil
+}
+
+// observe holds the fault for its duration, reverts it and checks that the
+// targets return to their steady state
+func (r *ChaosExperimentReconciler) observe(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault) (ctrl.Result, error) {
+    end := exp.Status.StartTime.Add(experimentDuration(exp))
+    if remaining := time.Until(end); remaining > 0 {
+        if chaos.NodeScoped(exp.Spec.Type) {
+            pressure, err := chaos.NodePressure(ctx, r.Client, exp.Status.Nodes)
+            if err != nil {
+                return ctrl.Result{}, err
+            }
+            if pressure != "" {
+                // The safeguard ends the fault before the kubelet starts evicting
+                if err := r.revert(ctx, exp, fault, "NodePressure"); err != nil {
+                    return ctrl.Result{}, err
+                }
+                r.Recorder.Eventf(exp, corev1.EventTypeWarning, "SafeguardTripped", "fault reverted early: %s", pressure)
+                return r.finish(ctx, exp, qraiopv1.ExperimentAborted, qraiopv1.VerdictAborted, "stopped by the node safeguard: "+pressure)
+            }
+            if nodeSafeguardInterval < remaining {
+                remaining = nodeSafeguardInterval
+            }
+        }
+        burning, watched, err := r.sloBurns(ctx, exp)
+        if err != nil {
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "SLOCheckFailed", "unable to read SLO burn-rate alerts: %v", err)
+        }
+        if len(burning) > 0 {
+            // The fault costs more error budget than the SLOs can spare
+            if err := r.revert(ctx, exp, fault, "SLOBurn"); err != nil {
+                return ctrl.Result{}, err
+            }
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "SLOBurn", "fault reverted early: SLO %s burning its error budget", strings.Join(burning, ", "))
+            return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed,
+                "SLO burning its error budget: "+strings.Join(burning, ", "))
+        }
+        if watched && sloBurnCheckInterval < remaining {
+            remaining = sloBurnCheckInterval
+        }
+        if obs, ok := fault.(chaos.Observer); ok {
+            if st := r.observeBackend(ctx, exp, obs); st != nil && st.Verdict == qraiopv1.VerdictFailed {
+                if err := r.revert(ctx, exp, fault, "BackendFailed"); err != nil {
+                    return ctrl.Result{}, err
+                }
+                r.Recorder.Eventf(exp, corev1.EventTypeWarning, "BackendFailed", "fault reverted early: %s", st.Message)
+                return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed, st.Message)
+            }
+            if err := r.Status().Update(ctx, exp); err != nil {
+                return ctrl.Result{}, err
+            }
+            if backendPollInterval < remaining {
+                remaining = backendPollInterval
+            }
+        }
+        if exp.Spec.Ramp != nil {
+            next, err := r.stepRamp(ctx, exp, fault)
+            if apierrors.IsConflict(err) {
+                return ctrl.Result{}, err
+            }
+            if err != nil {
+                r.Recorder.Eventf(exp, corev1.EventTypeWarning, "RampFailed", "unable to step the intensity ramp: %v", err)
+                if revertErr := r.revert(ctx, exp, fault, "RampFailed"); revertErr != nil {
+                    return ctrl.Result{}, revertErr
+                }
+                return r.finish(ctx, exp, qraiopv1.ExperimentFailed, qraiopv1.VerdictFailed, "intensity ramp: "+err.Error())
+            }
+            if next > 0 && next < remaining {
+                remaining = next
+            }
+        }
+        if len(exp.Spec.Probes) == 0 {
+            return ctrl.Result{RequeueAfter: remaining}, nil
+        }
+        failed, _, err := r.checkProbes(ctx, exp, false)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        if failed != "" {
+            // Fail fast: a broken dependency ends the fault early
+            if err := r.revert(ctx, exp, fault, "ProbeFailed"); err != nil {
+                return ctrl.Result{}, err
+            }
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "ProbeFailed", "fault reverted early: %s", failed)
+            return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed, failed)
+        }
+        if err := r.Status().Update(ctx, exp); err != nil {
+            return ctrl.Result{}, err
+        }
+        if interval := probeInterval(exp); interval < remaining {
+            remaining = interval
+        }
+        return ctrl.Result{RequeueAfter: remaining}, nil
+    }
+
+    if !meta.IsStatusConditionTrue(exp.Status.Conditions, conditionFaultsCleared) {
+        // An external tool gets the grace period to finish and judge its run
+        if obs, ok := fault.(chaos.Observer); ok {
+            st := r.observeBackend(ctx, exp, obs)
+            finished := st != nil && (st.Finished || st.Verdict == qraiopv1.VerdictFailed)
+            if !finished && time.Since(end) < recoveryGracePeriod {
+                if err := r.Status().Update(ctx, exp); err != nil {
+                    return ctrl.Result{}, err
+                }
+                return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+            }
+            if !finished || st.Verdict == qraiopv1.VerdictFailed {
+                message := exp.Spec.Backend + " did not finish the fault within " + recoveryGracePeriod.String() + " of its duration"
+                if finished {
+                    message = st.Message
+                }
+                if err := r.revert(ctx, exp, fault, "BackendFailed"); err != nil {
+                    return ctrl.Result{}, err
+                }
+                return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed, message)
+            }
+        }
+        if err := r.revert(ctx, exp, fault, "Completed"); err != nil {
+            return ctrl.Result{}, err
+        }
+        r.Recorder.Event(exp, corev1.EventTypeNormal, "FaultReverted", "fault reverted after its duration")
+        if err := r.Status().Update(ctx, exp); err != nil {
+            return ctrl.Result{}, err
+        }
+    }
+
+    outcome := ""
+    if exp.Status.Backend != nil {
+        outcome = exp.Status.Backend.Message
+    }
+    return r.awaitRecovery(ctx, exp, end, outcome)
+}
+
+// awaitRecovery passes the experiment once the targets are back to their
+// baseline readiness, prefixing the message with outcome when set, and
+// fails it when they aren't within the grace period after end
+func (r *ChaosExperimentReconciler) awaitRecovery(ctx context.Context, exp *qraiopv1.ChaosExperiment, end time.Time, outcome string) (ctrl.Result, error) {
+    pods, err := chaos.MatchingPods(ctx, r.Client, exp.Spec.Target)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    ready := chaos.ReadyCount(pods)
+    if ready >= exp.Status.BaselineReady {
+        message := fmt.Sprintf("%d/%d pods ready after recovery", ready, exp.Status.BaselineReady)
+        if outcome != "" {
+            message = outcome + "; " + message
+        }
+        return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictPassed, message)
+    }
+    if time.Since(end) < recoveryGracePeriod {
+        return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+    }
+    return r.finish(ctx, exp, qraiopv1.ExperimentCompleted, qraiopv1.VerdictFailed,
+        fmt.Sprintf("only %d of %d pods ready %s after the fault was reverted", ready, exp.Status.BaselineReady, recoveryGracePeriod))
+}
+
+// abort reverts the fault and marks the experiment Aborted. A failed
+// cleanup is retried rather than reported as aborted.
+func (r *ChaosExperimentReconciler) abort(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault) (ctrl.Result, error) {
+    if err := r.revert(ctx, exp, fault, "Aborted"); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "CleanupFailed", "unable to revert faults on abort: %v", err)
+        return ctrl.Result{}, err
+    }
+    r.Recorder.Event(exp, corev1.EventTypeWarning, "Aborted", "experiment aborted and faults reverted")
+    return r.finish(ctx, exp, qraiopv1.ExperimentAborted, qraiopv1.VerdictAborted, "aborted by request")
+}
+
+func (r *ChaosExperimentReconciler) revert(ctx context.Context, exp *qraiopv1.ChaosExperiment, fault chaos.Fault, reason string) error {
+    if err := fault.Cleanup(ctx, r.Client, exp); err != nil {
+        return err
+    }
+    meta.SetStatusCondition(&exp.Status.Conditions, metav1.Condition{
+        Type:               conditionFaultsCleared,
+        Status:             metav1.ConditionTrue,
+        Reason:             reason,
+        Message:            "all injected faults reverted",
+        ObservedGeneration: exp.Generation,
+    })
+    return nil
+}
+
+// finish moves the experiment to a terminal phase. Faults are reverted by
+// then, so alerts are no longer suppressed and the cleanup finalizer is dropped.
+func (r *ChaosExperimentReconciler) finish(ctx context.Context, exp *qraiopv1.ChaosExperiment, phase, verdict, message string) (ctrl.Result, error) {
+    if len(exp.Spec.Probes) > 0 {
+        if err := chaos.CleanupProbes(ctx, r.Client, exp); err != nil {
+            r.Recorder.Eventf(exp, corev1.EventTypeWarning, "CleanupFailed", "unable to remove probe jobs: %v", err)
+        }
+    }
+    if err := r.liftSuppression(ctx, exp); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "AlertSuppressionFailed", "unable to lift alert suppression: %v", err)
+        return ctrl.Result{}, err
+    }
+    if err := r.revokePolicyExceptions(ctx, exp); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "PolicyExceptionFailed", "unable to revoke policy exceptions: %v", err)
+        return ctrl.Result{}, err
+    }
+    now := metav1.Now()
+    exp.Status.Phase = phase
+    exp.Status.Verdict = verdict
+    exp.Status.Message = message
+    exp.Status.CompletionTime = &now
+    if err := r.Status().Update(ctx, exp); err != nil {
+        return ctrl.Result{}, err
+    }
+    if err := r.storeReport(ctx, exp); err != nil {
+        r.Recorder.Eventf(exp, corev1.EventTypeWarning, "ReportFailed", "unable to store experiment report: %v", err)
+    }
+    if controllerutil.RemoveFinalizer(exp, chaosCleanupFinalizer) {
+        if err := r.Update(ctx, exp); err != nil {
+            return ctrl.Result{}, err
+        }
+    }
+    return ctrl.Result{}, nil
+}
+
+func experimentDuration(exp *qraiopv1.ChaosExperiment) time.Duration {
+    if exp.Spec.Duration <= 0 {
+        return defaultExperimentDuration
+    }
+    return time.Duration(exp.Spec.Duration) * time.Second
+}
+
+// budgetViolations describes the budgets a pre-flight check found violated
+func budgetViolations(preflight *qraiopv1.PreflightReport) string {
+    var violated []string
+    for _, b := range preflight.Budgets {
+        if b.Violated {
+            violated = append(violated, fmt.Sprintf("%s (allows %d, %d targeted)", b.Name, b.DisruptionsAllowed, b.Targeted))
+        }
+    }
+    return "would violate PodDisruptionBudget " + strings.Join(violated, ", ") + "; set ignorePDB to run anyway"
+}
+
+func podNames(pods []corev1.Pod) []string {
+    names := make([]string, 0, len(pods))
+    for _, p := range pods {
+        names = append(names, p.Name)
+    }
+    return names
+}
+
+func (r *ChaosExperimentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.ChaosExperiment{}).
+        Owns(&batchv1.Job{}).
+        Complete(r)
+}
@@ -0,0 +1,166 @@
+// src/controllers/controllers/chaosschedule_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-logr/logr"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+const (
+    // ScheduleLabel names the ChaosSchedule that started an experiment
+    ScheduleLabel = "qraiop.io/chaos-schedule"
+
+    conditionScheduleValid = "ScheduleValid"
+
+    // missedFiringDeadline is how late a firing may still start, e.g.
+    // after the operator was down; later ones are skipped
+    missedFiringDeadline = 5 * time.Minute
+)
+
+// ChaosScheduleReconciler starts the experiment of a ChaosSchedule on each
+// firing inside its business hours and outside its exclusion windows, as
+// long as the namespace's scheduled experiments stay within its
+// concurrency limit
+type ChaosScheduleReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosschedules,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *ChaosScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("chaosschedule", req.NamespacedName)
+
+    var s qraiopv1.ChaosSchedule
+    if err := r.Get(ctx, req.NamespacedName, &s); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+
+    sched, err := chaos.NewSchedule(&s.Spec)
+    if err != nil {
+        setScheduleCondition(&s, metav1.ConditionFalse, "Invalid", err.Error())
+        s.Status.NextScheduleTime = nil
+        return ctrl.Result{}, r.Status().Update(ctx, &s)
+    }
+    setScheduleCondition(&s, metav1.ConditionTrue, "Valid", "schedule "+s.Spec.Schedule)
+
+    now := time.Now()
+    last := s.CreationTimestamp.Time
+    if s.Status.LastScheduleTime != nil {
+        last = s.Status.LastScheduleTime.Time
+    }
+    // Only the latest due firing runs; the ones it replaces were missed
+    due := time.Time{}
+    for t := sched.Next(last); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+        due = t
+    }
+
+    if !due.IsZero() {
+        skipped, err := r.skip(ctx, &s, sched, due, now)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        if skipped == "" {
+            exp, err := r.startExperiment(ctx, &s, due)
+            if err != nil {
+                return ctrl.Result{}, err
+            }
+            log.Info("schedule fired, starting experiment", "experiment", exp.Name)
+            r.Recorder.Eventf(&s, corev1.EventTypeNormal, "ChaosScheduleFired",
+                "running %s experiment %s", exp.Spec.Type, exp.Name)
+            s.Status.LastExperiment = exp.Name
+        } else {
+            r.Recorder.Eventf(&s, corev1.EventTypeNormal, "ChaosScheduleSkipped",
+                "firing at %s skipped: %s", due.UTC().Format(time.RFC3339), skipped)
+        }
+        s.Status.LastScheduleTime = &metav1.Time{Time: due}
+        s.Status.LastSkipped = skipped
+    }
+
+    var result ctrl.Result
+    s.Status.NextScheduleTime = nil
+    if next := sched.Next(now); !next.IsZero() {
+        s.Status.NextScheduleTime = &metav1.Time{Time: next}
+        result.RequeueAfter = next.Sub(now)
+    }
+    return result, r.Status().Update(ctx, &s)
+}
+
+// skip returns why the firing at due doesn't run, or "" when it does
+func (r *ChaosScheduleReconciler) skip(ctx context.Context, s *qraiopv1.ChaosSchedule, sched *chaos.Schedule, due, now time.Time) (string, error) {
+    if s.Spec.Suspend {
+        return "suspended", nil
+    }
+    if now.Sub(due) > missedFiringDeadline {
+        return fmt.Sprintf("missed by %s", now.Sub(due).Round(time.Second)), nil
+    }
+    if reason := sched.Skip(due); reason != "" {
+        return reason, nil
+    }
+
+    var exps qraiopv1.ChaosExperimentList
+    if err := r.List(ctx, &exps, client.InNamespace(s.Namespace), client.HasLabels{ScheduleLabel}); err != nil {
+        return "", err
+    }
+    running := 0
+    for _, exp := range exps.Items {
+        if !exp.Status.IsFinished() {
+            running++
+        }
+    }
+    limit := s.Spec.ConcurrencyLimit
+    if limit < 1 {
+        limit = 1
+    }
+    if running >= limit {
+        return fmt.Sprintf("%d scheduled experiments running, the concurrency limit is %d", running, limit), nil
+    }
+    return "", nil
+}
+
+func (r *ChaosScheduleReconciler) startExperiment(ctx context.Context, s *qraiopv1.ChaosSchedule, due time.Time) (*qraiopv1.ChaosExperiment, error) {
+    exp := chaos.ScheduledExperiment(s, fmt.Sprintf("%s-%d", s.Name, due.Unix()/60))
+    exp.Labels = map[string]string{ScheduleLabel: s.Name}
+    if err := ctrl.SetControllerReference(s, exp, r.Scheme); err != nil {
+        return nil, err
+    }
+    if err := r.Create(ctx, exp); err != nil && !apierrors.IsAlreadyExists(err) {
+        return nil, err
+    }
+    return exp, nil
+}
+
+func setScheduleCondition(s *qraiopv1.ChaosSchedule, status metav1.ConditionStatus, reason, message string) {
+    meta.SetStatusCondition(&s.Status.Conditions, metav1.Condition{
+        Type:               conditionScheduleValid,
+        Status:             status,
+        Reason:             reason,
+        Message:            message,
+        ObservedGeneration: s.Generation,
+    })
+}
+
+func (r *ChaosScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.ChaosSchedule{}).
+        Owns(&qraiopv1.ChaosExperiment{}).
+        Complete(r)
+}
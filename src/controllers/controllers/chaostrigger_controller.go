@@ -0,0 +1,198 @@
+// src/controllers/controllers/chaostrigger_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/go-logr/logr"
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    // TriggerLabel names the ChaosTrigger that started an experiment
+    TriggerLabel = "qraiop.io/chaos-trigger"
+
+    deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+    conditionDeploymentFound = "DeploymentFound"
+)
+
+// ChaosTriggerReconciler runs a smoke experiment whenever a watched
+// Deployment finishes rolling out a new revision, and reports the verdict
+// as events on the Deployment
+type ChaosTriggerReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaostriggers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaostriggers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *ChaosTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("chaostrigger", req.NamespacedName)
+
+    var trigger qraiopv1.ChaosTrigger
+    if err := r.Get(ctx, req.NamespacedName, &trigger); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+
+    var dep appsv1.Deployment
+    err := r.Get(ctx, client.ObjectKey{Namespace: trigger.Namespace, Name: trigger.Spec.Deployment}, &dep)
+    if apierrors.IsNotFound(err) {
+        setTriggerCondition(&trigger, metav1.ConditionFalse, "NotFound", "deployment "+trigger.Spec.Deployment+" not found")
+        return ctrl.Result{}, r.Status().Update(ctx, &trigger)
+    }
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    setTriggerCondition(&trigger, metav1.ConditionTrue, "Found", "watching deployment "+dep.Name)
+
+    running, err := r.reportVerdict(ctx, &trigger, &dep)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+
+    revision := dep.Annotations[deploymentRevisionAnnotation]
+    switch {
+    case trigger.Status.LastRevision == "":
+        // Only rollouts after the trigger was created are verified
+        trigger.Status.LastRevision = revision
+    case revision == trigger.Status.LastRevision, trigger.Spec.Suspend, running, !rolledOut(&dep):
+    default:
+        exp, err := r.startExperiment(ctx, &trigger, &dep, revision)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        log.Info("rollout completed, starting chaos verification", "revision", revision, "experiment", exp.Name)
+        r.Recorder.Eventf(&dep, corev1.EventTypeNormal, "ChaosVerificationStarted",
+            "revision %s rolled out, running %s experiment %s", revision, exp.Spec.Type, exp.Name)
+        trigger.Status.LastRevision = revision
+        trigger.Status.LastExperiment = exp.Name
+        trigger.Status.LastVerdict = ""
+    }
+    return ctrl.Result{}, r.Status().Update(ctx, &trigger)
+}
+
+// reportVerdict posts the verdict of the last experiment to the Deployment
+// once it finished, and reports whether it is still running
+func (r *ChaosTriggerReconciler) reportVerdict(ctx context.Context, trigger *qraiopv1.ChaosTrigger, dep *appsv1.Deployment) (bool, error) {
+    if trigger.Status.LastExperiment == "" || trigger.Status.LastVerdict != "" {
+        return false, nil
+    }
+    var exp qraiopv1.ChaosExperiment
+    err := r.Get(ctx, client.ObjectKey{Namespace: trigger.Namespace, Name: trigger.Status.LastExperiment}, &exp)
+    if apierrors.IsNotFound(err) {
+        trigger.Status.LastVerdict = "Unknown"
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    if !exp.Status.IsFinished() {
+        return true, nil
+    }
+
+    eventType, reason := corev1.EventTypeNormal, "ChaosVerificationPassed"
+    if exp.Status.Verdict != qraiopv1.VerdictPassed {
+        eventType, reason = corev1.EventTypeWarning, "ChaosVerificationFailed"
+    }
+    r.Recorder.Eventf(dep, eventType, reason, "revision %s: %s experiment %s %s: %s",
+        trigger.Status.LastRevision, exp.Spec.Type, exp.Name, exp.Status.Verdict, exp.Status.Message)
+    trigger.Status.LastVerdict = exp.Status.Verdict
+    return false, nil
+}
+
+func (r *ChaosTriggerReconciler) startExperiment(ctx context.Context, trigger *qraiopv1.ChaosTrigger, dep *appsv1.Deployment, revision string) (*qraiopv1.ChaosExperiment, error) {
+    if dep.Spec.Selector == nil || len(dep.Spec.Selector.MatchLabels) == 0 {
+        return nil, fmt.Errorf("deployment %s has no matchLabels selector to target", dep.Name)
+    }
+    exp := &qraiopv1.ChaosExperiment{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      fmt.Sprintf("%s-rev%s", trigger.Name, revision),
+            Namespace: trigger.Namespace,
+            Labels:    map[string]string{TriggerLabel: trigger.Name},
+        },
+        Spec: qraiopv1.ChaosExperimentSpec{
+            ExperimentConfig: qraiopv1.ExperimentConfig{
+                Type: trigger.Spec.Experiment.Type,
+                Target: qraiopv1.ExperimentTarget{
+                    Namespace: dep.Namespace,
+                    Selector:  dep.Spec.Selector.MatchLabels,
+                },
+                Percentage: trigger.Spec.Experiment.Percentage,
+                Duration:   trigger.Spec.Experiment.Duration,
+            },
+        },
+    }
+    if err := ctrl.SetControllerReference(trigger, exp, r.Scheme); err != nil {
+        return nil, err
+    }
+    if err := r.Create(ctx, exp); err != nil && !apierrors.IsAlreadyExists(err) {
+        return nil, err
+    }
+    return exp, nil
+}
+
+// rolledOut reports whether every replica of the Deployment runs its
+// current template and is available
+func rolledOut(dep *appsv1.Deployment) bool {
+    replicas := int32(1)
+    if dep.Spec.Replicas != nil {
+        replicas = *dep.Spec.Replicas
+    }
+    s := dep.Status
+    return s.ObservedGeneration >= dep.Generation &&
+        s.UpdatedReplicas == replicas &&
+        s.AvailableReplicas == replicas &&
+        s.Replicas == replicas
+}
+
+func setTriggerCondition(t *qraiopv1.ChaosTrigger, status metav1.ConditionStatus, reason, message string) {
+    meta.SetStatusCondition(&t.Status.Conditions, metav1.Condition{
+        Type:               conditionDeploymentFound,
+        Status:             status,
+        Reason:             reason,
+        Message:            message,
+        ObservedGeneration: t.Generation,
+    })
+}
+
+// triggersForDeployment maps a Deployment to the triggers watching it
+func (r *ChaosTriggerReconciler) triggersForDeployment(ctx context.Context, obj client.Object) []reconcile.Request {
+    var triggers qraiopv1.ChaosTriggerList
+    if err := r.List(ctx, &triggers, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for _, t := range triggers.Items {
+        if t.Spec.Deployment == obj.GetName() {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&t)})
+        }
+    }
+    return requests
+}
+
+func (r *ChaosTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.ChaosTrigger{}).
+        Owns(&qraiopv1.ChaosExperiment{}).
+        Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.triggersForDeployment)).
+        Complete(r)
+}
@@ -0,0 +1,232 @@
+// src/controllers/controllers/compliance.go
+package controllers
+
+import (
+    "context"
+    "slices"
+    "strings"
+
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    // complianceName names the CronJob and ServiceAccount of the scans
+    complianceName = "qraiop-compliance"
+
+    // complianceWorkDir holds the reports of a scan until they are stored
+    complianceWorkDir = "/work"
+    complianceKeyDir  = "/signing"
+    complianceKeyFile = complianceKeyDir + "/key.pem"
+    complianceMount   = "/reports"
+
+    defaultComplianceSchedule = "0 3 * * 1"
+)
+
+// complianceStamp names the directory of each scan after its start time
+const complianceStamp = `$(date -u +%Y%m%dT%H%M%SZ)`
+
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileComplianceReport schedules the compliance scans of
+// q.Spec.ComplianceReport. A scan runs as a chain of steps sharing a work
+// directory: qraiopctl writes and signs the reports, the renderer converts
+// the HTML report to PDF, which qraiopctl then signs, and the last step
+// copies everything to the storage. Disabled, the CronJob is pruned.
+func (r *QraiopReconciler) reconcileComplianceReport(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.ComplianceReport
+    if cfg == nil || !cfg.Enabled {
+        q.Status.ComplianceReport = nil
+        return nil
+    }
+    sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: complianceName, Namespace: q.Namespace, Labels: labelsForQraiop(q)}}
+    if err := r.createOrUpdate(ctx, q, sa); err != nil {
+        return err
+    }
+    cronJob := complianceCronJob(q)
+    render.Explain(cronJob, "spec.schedule", cronJob.Spec.Schedule, "spec.complianceReport.schedule")
+    if err := r.createOrUpdate(ctx, q, cronJob); err != nil {
+        return err
+    }
+
+    st := &qraiopv1.ComplianceReportStatus{CronJob: cronJob.Name}
+    var current batchv1.CronJob
+    err := r.Get(ctx, client.ObjectKeyFromObject(cronJob), &current)
+    if err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    st.LastScheduleTime = current.Status.LastScheduleTime
+    st.LastSuccessfulTime = current.Status.LastSuccessfulTime
+    q.Status.ComplianceReport = st
+    return nil
+}
+
+// complianceCronJob renders the CronJob of the compliance scans
+func complianceCronJob(q *qraiopv1.Qraiop) *batchv1.CronJob {
+    cfg := q.Spec.ComplianceReport
+    schedule := cfg.Schedule
+    if schedule == "" {
+        schedule = defaultComplianceSchedule
+    }
+    image := cfg.Image
+    if image == "" {
+        tag := "latest"
+        if q.Spec.ComponentVersion != "" {
+            tag = q.Spec.ComponentVersion
+        }
+        image = imageRegistry + "/qraiop:" + tag
+    }
+
+    formats, pdf := []string{}, false
+    for _, f := range cfg.Formats {
+        if f == qraiopv1.ComplianceFormatPDF {
+            pdf = true
+            continue
+        }
+        formats = append(formats, f)
+    }
+    if pdf && !slices.Contains(formats, qraiopv1.ComplianceFormatHTML) {
+        formats = append(formats, qraiopv1.ComplianceFormatHTML)
+    }
+    if len(formats) == 0 {
+        formats = []string{qraiopv1.ComplianceFormatJSON}
+    }
+
+    workMount := corev1.VolumeMount{Name: "work", MountPath: complianceWorkDir}
+    keyMount := corev1.VolumeMount{Name: "signing-key", MountPath: complianceKeyDir, ReadOnly: true}
+    steps := []corev1.Container{{
+        Name:  "report",
+        Image: image,
+        Command: []string{"qraiopctl", "compliance", "--format", strings.Join(formats, ","),
+            "-o", complianceWorkDir, "-key", complianceKeyFile},
+        VolumeMounts: []corev1.VolumeMount{workMount, keyMount},
+    }}
+    if pdf {
+        renderer := cfg.Renderer
+        if renderer == nil {
+            renderer = &qraiopv1.ComplianceRenderer{}
+        }
+        rendererImage := renderer.Image
+        if rendererImage == "" {
+            rendererImage = "surnet/alpine-wkhtmltopdf:3.20.2-0.12.6-small"
+        }
+        command := renderer.Command
+        if len(command) == 0 {
+            command = []string{"wkhtmltopdf", "--quiet"}
+        }
+        report := complianceWorkDir + "/compliance."
+        steps = append(steps, corev1.Container{
+            Name:         "pdf",
+            Image:        rendererImage,
+            Command:      append(append([]string{}, command...), report+qraiopv1.ComplianceFormatHTML, report+qraiopv1.ComplianceFormatPDF),
+            VolumeMounts: []corev1.VolumeMount{workMount},
+        }, corev1.Container{
+            Name:         "sign-pdf",
+            Image:        image,
+            Command:      []string{"qraiopctl", "compliance", "sign", "-key", complianceKeyFile, report + qraiopv1.ComplianceFormatPDF},
+            VolumeMounts: []corev1.VolumeMount{workMount, keyMount},
+        })
+    }
+    for i := range steps {
+        steps[i].SecurityContext = &corev1.SecurityContext{AllowPrivilegeEscalation: boolPtr(false)}
+    }
+
+    volumes := []corev1.Volume{
+        {Name: "work", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+        {Name: "signing-key", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+            SecretName: cfg.SigningKeySecret.Name,
+            Items:      []corev1.KeyToPath{{Key: cfg.SigningKeySecret.Key, Path: "key.pem"}},
+            // The steps run as the images' own, unprivileged users
+            DefaultMode: int32Ptr(0o444),
+        }}},
+    }
+    store := complianceStore(cfg.Storage, image)
+    store.VolumeMounts = append([]corev1.VolumeMount{workMount}, store.VolumeMounts...)
+    store.SecurityContext = &corev1.SecurityContext{AllowPrivilegeEscalation: boolPtr(false)}
+    if pvc := cfg.Storage.PersistentVolumeClaim; pvc != nil {
+        volumes = append(volumes, corev1.Volume{Name: "reports", VolumeSource: corev1.VolumeSource{
+            PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+        }})
+    }
+
+    history := cfg.SuccessfulJobsHistoryLimit
+    if history == nil {
+        history = int32Ptr(3)
+    }
+    labels := componentLabels(q, complianceName)
+    return &batchv1.CronJob{
+        ObjectMeta: metav1.ObjectMeta{Name: complianceName, Namespace: q.Namespace, Labels: labels},
+        Spec: batchv1.CronJobSpec{
+            Schedule:                   schedule,
+            Suspend:                    boolPtr(cfg.Suspend),
+            ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+            SuccessfulJobsHistoryLimit: history,
+            FailedJobsHistoryLimit:     int32Ptr(3),
+            JobTemplate: batchv1.JobTemplateSpec{
+                ObjectMeta: metav1.ObjectMeta{Labels: labels},
+                Spec: batchv1.JobSpec{
+                    BackoffLimit: int32Ptr(2),
+                    Template: corev1.PodTemplateSpec{
+                        ObjectMeta: metav1.ObjectMeta{Labels: labels},
+                        Spec: corev1.PodSpec{
+                            ServiceAccountName: complianceName,
+                            RestartPolicy:      corev1.RestartPolicyNever,
+                            InitContainers:     steps,
+                            Containers:         []corev1.Container{store},
+                            Volumes:            volumes,
+                        },
+                    },
+                },
+            },
+        },
+    }
+}
+
+// complianceStore renders the step copying a scan's reports to the
+// storage, into a directory named after the time it ran
+func complianceStore(storage qraiopv1.ComplianceStorage, image string) corev1.Container {
+    if s3 := storage.S3; s3 != nil {
+        awsImage := s3.Image
+        if awsImage == "" {
+            awsImage = "amazon/aws-cli:2.17.0"
+        }
+        prefix := strings.Trim(s3.Prefix, "/")
+        if prefix != "" {
+            prefix += "/"
+        }
+        script := `aws s3 cp --recursive ` + complianceWorkDir + ` "s3://$BUCKET/$PREFIX` + complianceStamp + `/"`
+        if s3.Endpoint != "" {
+            script += ` --endpoint-url "$ENDPOINT"`
+        }
+        c := corev1.Container{
+            Name:    "upload",
+            Image:   awsImage,
+            Command: []string{"sh", "-c", script},
+            Env: []corev1.EnvVar{
+                {Name: "BUCKET", Value: s3.Bucket},
+                {Name: "PREFIX", Value: prefix},
+                {Name: "ENDPOINT", Value: s3.Endpoint},
+            },
+        }
+        if s3.Region != "" {
+            c.Env = append(c.Env, corev1.EnvVar{Name: "AWS_REGION", Value: s3.Region})
+        }
+        if ref := s3.CredentialsSecret; ref != nil {
+            c.EnvFrom = []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: *ref}}}
+        }
+        return c
+    }
+    return corev1.Container{
+        Name:  "store",
+        Image: image,
+        Command: []string{"sh", "-c", `dir=` + complianceMount + `/` + complianceStamp +
+            ` && mkdir -p "$dir" && cp ` + complianceWorkDir + `/* "$dir"/`},
+        VolumeMounts: []corev1.VolumeMount{{Name: "reports", MountPath: complianceMount}},
+    }
+}
@@ -0,0 +1,426 @@
+// src/controllers/controllers/components.go
+package controllers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// cryptoTerminationGracePeriod is how long crypto pods get by default to
+// finish key operations in flight when they are stopped
+const cryptoTerminationGracePeriod = 60
+
+// component is a QRAIOP component rolled out by the controller
+type component struct {
+    name       string
+    deployment string
+    enabled    bool
+    // disabledReason overrides the status message of a disabled component
+    disabledReason string
+    dependsOn      []string
+    reconcile      func(context.Context, *qraiopv1.Qraiop) error
+}
+
+// defaultDependencies sequences components whose DependsOn is unset: the AI
+// agents need certs issued by the crypto service, and chaos experiments
+// should only run once monitoring can observe them
+var defaultDependencies = map[string][]string{
+    qraiopv1.ComponentAIOrchestration:  {qraiopv1.ComponentCryptography},
+    qraiopv1.ComponentChaosEngineering: {qraiopv1.ComponentMonitoring},
+}
+
+func dependenciesOf(name string, declared []string) []string {
+    if declared != nil {
+        return declared
+    }
+    return defaultDependencies[name]
+}
+
+// aiDependencies defaults the agents to wait for the crypto pool they use
+func aiDependencies(cfg qraiopv1.AIConfig) []string {
+    if cfg.DependsOn == nil && cfg.CryptoPool != "" {
+        return []string{cryptoPoolComponent(cfg.CryptoPool)}
+    }
+    return dependenciesOf(qraiopv1.ComponentAIOrchestration, cfg.DependsOn)
+}
+
+// components lists every component of q in a stable order
+func (r *QraiopReconciler) components(q *qraiopv1.Qraiop) []component {
+    spec := q.Spec
+    components := []component{
+        {
+            name:       qraiopv1.ComponentCryptography,
+            deployment: "qraiop-crypto",
+            enabled:    spec.Cryptography.Enabled,
+            dependsOn:  dependenciesOf(qraiopv1.ComponentCryptography, spec.Cryptography.DependsOn),
+            reconcile:  r.reconcileCryptography,
+        },
+    }
+    // Each crypto pool is a component of its own, sequenced like the
+    // default crypto service
+    for _, pool := range spec.Cryptography.Pools {
+        pool := pool
+        components = append(components, component{
+            name:       cryptoPoolComponent(pool.Name),
+            deployment: cryptoPoolDeployment(pool.Name),
+            enabled:    spec.Cryptography.Enabled,
+            dependsOn:  dependenciesOf(qraiopv1.ComponentCryptography, spec.Cryptography.DependsOn),
+            reconcile: func(ctx context.Context, q *qraiopv1.Qraiop) error {
+                return r.reconcileCryptoPool(ctx, q, pool)
+            },
+        })
+    }
+    components = append(components, []component{
+        {
+            name:       qraiopv1.ComponentMonitoring,
+            deployment: "qraiop-monitoring",
+            enabled:    spec.Monitoring.Enabled,
+            dependsOn:  dependenciesOf(qraiopv1.ComponentMonitoring, spec.Monitoring.DependsOn),
+            reconcile:  r.reconcileMonitoring,
+        },
+        {
+            name:       qraiopv1.ComponentAIOrchestration,
+            deployment: "qraiop-ai",
+            enabled:    spec.AIOrchestration.Enabled,
+            dependsOn:  aiDependencies(spec.AIOrchestration),
+            reconcile:  r.reconcileAIOrchestration,
+        },
+        {
+            name:       qraiopv1.ComponentChaosEngineering,
+            deployment: "qraiop-chaos",
+            enabled:    spec.ChaosEngineering.Enabled,
+            dependsOn:  dependenciesOf(qraiopv1.ComponentChaosEngineering, spec.ChaosEngineering.DependsOn),
+            reconcile:  r.reconcileChaosEngineering,
+        },
+    }...)
+
+    components = append(components, r.addonComponents(q)...)
+
+    // The monitoring stack is too heavy for edge clusters
+    if spec.Profile == qraiopv1.ProfileEdge {
+        for i := range components {
+            if components[i].name == qraiopv1.ComponentMonitoring && components[i].enabled {
+                components[i].enabled = false
+                components[i].disabledReason = "disabled by the Edge profile"
+            }
+        }
+    }
+    return components
+}
+
+// componentOrder sorts components so that each one follows its
+// dependencies, keeping the input order between independent components
+func componentOrder(components []component) ([]component, error) {
+    byName := make(map[string]component, len(components))
+    for _, c := range components {
+        byName[c.name] = c
+    }
+
+    pending := make(map[string]int, len(components))
+    dependents := make(map[string][]string)
+    for _, c := range components {
+        for _, dep := range c.dependsOn {
+            if _, ok := byName[dep]; !ok {
+                return nil, fmt.Errorf("%s depends on unknown component %q", c.name, dep)
+            }
+            pending[c.name]++
+            dependents[dep] = append(dependents[dep], c.name)
+        }
+    }
+
+    ordered := make([]component, 0, len(components))
+    done := make(map[string]bool, len(components))
+    for len(ordered) < len(components) {
+        progressed := false
+        for _, c := range components {
+            if done[c.name] || pending[c.name] > 0 {
+                continue
+            }
+            done[c.name] = true
+            ordered = append(ordered, c)
+            for _, d := range dependents[c.name] {
+                pending[d]--
+            }
+            progressed = true
+        }
+        if !progressed {
+            var cycle []string
+            for _, c := range components {
+                if !done[c.name] {
+                    cycle = append(cycle, c.name)
+                }
+            }
+            return nil, fmt.Errorf("dependency cycle between %s", strings.Join(cycle, ", "))
+        }
+    }
+    return ordered, nil
+}
+
+// reconcileComponents rolls out enabled components in dependency order. A
+// component whose dependencies aren't Ready is left untouched and reported
+// as Blocked with the reason. It reports whether every enabled component is
+// Ready.
+func (r *QraiopReconciler) reconcileComponents(ctx context.Context, q *qraiopv1.Qraiop) (bool, error) {
+    ordered, err := componentOrder(r.components(q))
+    if err != nil {
+        setCondition(q, conditionComponentDependencies, metav1.ConditionFalse, "InvalidDependencies", err.Error())
+        markInventoryPartial(ctx)
+        return false, nil
+    }
+    setCondition(q, conditionComponentDependencies, metav1.ConditionTrue, "Resolved", "component dependencies resolved")
+
+    enabled := make(map[string]bool, len(ordered))
+    for _, c := range ordered {
+        enabled[c.name] = c.enabled
+    }
+
+    allReady := true
+    for _, c := range ordered {
+        if !c.enabled {
+            reason := c.disabledReason
+            if reason == "" {
+                reason = "component is disabled"
+            }
+            setComponentStatus(q, c.name, qraiopv1.ComponentDisabled, reason)
+            continue
+        }
+
+        var waiting []string
+        for _, dep := range c.dependsOn {
+            // A disabled dependency will never become ready, so it doesn't gate
+            if !enabled[dep] {
+                continue
+            }
+            // A Degraded dependency is still serving, so it doesn't block
+            st := q.Status.Components[dep]
+            if st.Status != qraiopv1.ComponentReady && st.Status != qraiopv1.ComponentDegraded {
+                waiting = append(waiting, fmt.Sprintf("%s (%s: %s)", dep, st.Status, st.Message))
+            }
+        }
+        if len(waiting) > 0 {
+            setComponentStatus(q, c.name, qraiopv1.ComponentBlocked, "waiting for "+strings.Join(waiting, "; "))
+            markInventoryPartial(ctx)
+            allReady = false
+            continue
+        }
+
+        if err := c.reconcile(ctx, q); err != nil {
+            var invalid *invalidOptionsError
+            if errors.As(err, &invalid) {
+                setComponentStatus(q, c.name, qraiopv1.ComponentBlocked, invalid.Error())
+                markInventoryPartial(ctx)
+                allReady = false
+                continue
+            }
+            return false, fmt.Errorf("reconciling %s: %w", c.name, err)
+        }
+        status, message, err := r.deploymentReadiness(ctx, q.Namespace, c.deployment)
+        if err != nil {
+            return false, fmt.Errorf("reading %s rollout: %w", c.name, err)
+        }
+        stats, err := r.componentRestarts(ctx, q, c.deployment)
+        if err != nil {
+            return false, fmt.Errorf("reading %s restarts: %w", c.name, err)
+        }
+        if reason := restartDegradation(stats, q.Spec.ComponentHealth); reason != "" {
+            status, message = qraiopv1.ComponentDegraded, reason
+        }
+        status = smoothComponentStatus(q, c.name, status, message)
+        setComponentRestarts(q, c.name, stats)
+        if status != qraiopv1.ComponentReady {
+            allReady = false
+        }
+    }
+    return allReady, nil
+}
+
+// deploymentReadiness maps a Deployment's rollout state to a component status
+func (r *QraiopReconciler) deploymentReadiness(ctx context.Context, namespace, name string) (string, string, error) {
+    var d appsv1.Deployment
+    if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &d); err != nil {
+        return "", "", err
+    }
+    want := int32(1)
+    if d.Spec.Replicas != nil {
+        want = *d.Spec.Replicas
+    }
+    if d.Status.ObservedGeneration < d.Generation {
+        return qraiopv1.ComponentProgressing, "rollout pending", nil
+    }
+    message := fmt.Sprintf("%d/%d replicas ready", d.Status.ReadyReplicas, want)
+    if d.Status.UpdatedReplicas < want || d.Status.ReadyReplicas < want {
+        return qraiopv1.ComponentProgressing, message, nil
+    }
+    return qraiopv1.ComponentReady, message, nil
+}
+
+// cryptoDeployment renders a crypto service Deployment
+func cryptoDeployment(q *qraiopv1.Qraiop, name string, algorithms []string, level int, hybrid bool) *appsv1.Deployment {
+    deployment := componentDeployment(q, name, []corev1.EnvVar{
+        {Name: "QRAIOP_ALGORITHMS", Value: strings.Join(algorithms, ",")},
+        {Name: "QRAIOP_SECURITY_LEVEL", Value: strconv.Itoa(level)},
+        {Name: "QRAIOP_HYBRID_MODE", Value: strconv.FormatBool(hybrid)},
+    })
+    path := "spec.cryptography"
+    if name != "qraiop-crypto" {
+        path = componentSpecPath(cryptoPoolComponent(strings.TrimPrefix(name, "qraiop-crypto-")))
+    }
+    render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_ALGORITHMS]", strings.Join(algorithms, ","), path+".algorithms")
+    render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_SECURITY_LEVEL]", strconv.Itoa(level), path+".securityLevel")
+    render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_HYBRID_MODE]", strconv.FormatBool(hybrid), path+".hybridMode")
+    // The crypto service issues its certificates at startup, so rolling it
+    // for a new operator version re-issues them
+    deployment.Spec.Template.Annotations = map[string]string{operatorVersionAnnotation: q.Status.OperatorVersion}
+    deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = int64Ptr(cryptoTerminationGracePeriod)
+    return deployment
+}
+
+func (r *QraiopReconciler) reconcileCryptography(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.Cryptography
+    deployment := cryptoDeployment(q, "qraiop-crypto", cfg.Algorithms, cfg.SecurityLevel, cfg.HybridMode)
+    // Capabilities are those of the image run, so it is set ahead of
+    // applyComponent
+    render.Image(&deployment.Spec.Template.Spec, cfg.ComponentOptions)
+    r.refreshCryptoCapabilities(ctx, q, imageTag(deployment))
+    negotiateAlgorithms(q, imageTag(deployment))
+    if err := checkAlgorithms(q, deployment, cfg.Algorithms); err != nil {
+        return err
+    }
+    return r.applyComponent(ctx, q, qraiopv1.ComponentCryptography, deployment, cfg.ComponentOptions)
+}
+
+// cryptoPoolComponent is the Status.Components key of a crypto pool
+func cryptoPoolComponent(pool string) string {
+    return qraiopv1.ComponentCryptography + "/" + pool
+}
+
+func cryptoPoolDeployment(pool string) string {
+    return "qraiop-crypto-" + pool
+}
+
+// reconcileCryptoPool rolls out a crypto pool, sharing the component
+// options of the default crypto service
+func (r *QraiopReconciler) reconcileCryptoPool(ctx context.Context, q *qraiopv1.Qraiop, pool qraiopv1.CryptoPool) error {
+    cfg := q.Spec.Cryptography
+    deployment := cryptoDeployment(q, cryptoPoolDeployment(pool.Name), pool.Algorithms, pool.SecurityLevel, pool.HybridMode)
+    if pool.Replicas != nil {
+        deployment.Spec.Replicas = int32Ptr(*pool.Replicas)
+        render.Explain(deployment, "spec.replicas", strconv.Itoa(int(*pool.Replicas)), componentSpecPath(cryptoPoolComponent(pool.Name))+".replicas")
+    }
+    for _, labels := range []map[string]string{deployment.Labels, deployment.Spec.Template.Labels} {
+        labels[qraiopv1.CryptoPoolLabel] = pool.Name
+        labels[qraiopv1.SecurityLevelLabel] = strconv.Itoa(pool.SecurityLevel)
+    }
+    // Pools are checked against the capabilities of the default crypto
+    // service of the same build
+    render.Image(&deployment.Spec.Template.Spec, cfg.ComponentOptions)
+    if err := checkAlgorithms(q, deployment, pool.Algorithms); err != nil {
+        return err
+    }
+    opts := cfg.ComponentOptions
+    if d := opts.Deployment; d != nil && pool.Replicas != nil {
+        // The pool's own replicas win over those it shares
+        shared := *d
+        shared.Replicas = nil
+        opts.Deployment = &shared
+    }
+    return r.applyComponent(ctx, q, cryptoPoolComponent(pool.Name), deployment, opts)
+}
+
+func (r *QraiopReconciler) reconcileAIOrchestration(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.AIOrchestration
+    deployment := componentDeployment(q, "qraiop-ai", []corev1.EnvVar{
+        {Name: "QRAIOP_LLM_PROVIDER", Value: cfg.LLMProvider},
+        {Name: "QRAIOP_CRYPTO_ENDPOINT", Value: cryptoEndpoint(cfg.CryptoPool)},
+    })
+    render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_LLM_PROVIDER]", cfg.LLMProvider, "spec.aiOrchestration.llmProvider")
+    render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_CRYPTO_ENDPOINT]", cryptoEndpoint(cfg.CryptoPool), "spec.aiOrchestration.cryptoPool")
+    if cfg.Model != nil {
+        // QRAIOP_LLM_PROVIDER is already set from llmProvider
+        model := *cfg.Model
+        if model.Provider != "" {
+            env := deployment.Spec.Template.Spec.Containers[0].Env
+            for i := range env {
+                if env[i].Name == "QRAIOP_LLM_PROVIDER" {
+                    env[i].Value = model.Provider
+                }
+            }
+            render.Explain(deployment, "spec.template.spec.containers[0].env[QRAIOP_LLM_PROVIDER]", model.Provider, "spec.aiOrchestration.model.provider")
+            model.Provider = ""
+        }
+        modelEnv(deployment, "QRAIOP_LLM_", model, "spec.aiOrchestration.model")
+    }
+    for i, agent := range cfg.Agents {
+        prefix := "QRAIOP_AGENT_" + strings.ToUpper(agent.Name) + "_LLM_"
+        modelEnv(deployment, prefix, agent.ModelConfig, "spec.aiOrchestration.agents["+strconv.Itoa(i)+"]")
+    }
+    if cfg.CredentialsSource != nil {
+        if err := r.mountLLMCredentials(ctx, q, deployment); err != nil {
+            return err
+        }
+    }
+    if r.aiIdle(ctx, q) {
+        deployment.Spec.Replicas = int32Ptr(0)
+        render.Explain(deployment, "spec.replicas", "0", "spec.aiOrchestration.idle (no agent activity)")
+    }
+    return r.applyComponent(ctx, q, qraiopv1.ComponentAIOrchestration, deployment, cfg.ComponentOptions)
+}
+
+// modelEnv passes the set fields of a model config to the agents as
+// <prefix>PROVIDER, MODEL, TEMPERATURE and MAX_TOKENS. Agents resolve their
+// own QRAIOP_AGENT_<NAME>_LLM_* variables before the QRAIOP_LLM_* ones.
+func modelEnv(deployment *appsv1.Deployment, prefix string, m qraiopv1.ModelConfig, from string) {
+    container := &deployment.Spec.Template.Spec.Containers[0]
+    for _, v := range []struct{ name, value, field string }{
+        {"PROVIDER", m.Provider, "provider"},
+        {"MODEL", m.Model, "model"},
+        {"TEMPERATURE", m.Temperature, "temperature"},
+        {"MAX_TOKENS", maxTokens(m.MaxTokens), "maxTokens"},
+    } {
+        if v.value == "" {
+            continue
+        }
+        container.Env = append(container.Env, corev1.EnvVar{Name: prefix + v.name, Value: v.value})
+        render.Explain(deployment, "spec.template.spec.containers[0].env["+prefix+v.name+"]", v.value, from+"."+v.field)
+    }
+}
+
+func maxTokens(n int32) string {
+    if n == 0 {
+        return ""
+    }
+    return strconv.Itoa(int(n))
+}
+
+// cryptoEndpoint is the in-namespace address of a crypto pool, or of the
+// default crypto service when pool is empty
+func cryptoEndpoint(pool string) string {
+    if pool == "" {
+        return "http://qraiop-crypto:8080"
+    }
+    return "http://" + cryptoPoolDeployment(pool) + ":8080"
+}
+
+func (r *QraiopReconciler) reconcileChaosEngineering(ctx context.Context, q *qraiopv1.Qraiop) error {
+    deployment := componentDeployment(q, "qraiop-chaos", nil)
+    return r.applyComponent(ctx, q, qraiopv1.ComponentChaosEngineering, deployment, q.Spec.ChaosEngineering.ComponentOptions)
+}
+
+func (r *QraiopReconciler) reconcileMonitoring(ctx context.Context, q *qraiopv1.Qraiop) error {
+    deployment := componentDeployment(q, "qraiop-monitoring", nil)
+    if err := r.applyAlertChannels(ctx, q, deployment); err != nil {
+        return err
+    }
+    return r.applyComponent(ctx, q, qraiopv1.ComponentMonitoring, deployment, q.Spec.Monitoring.ComponentOptions)
+}
@@ -0,0 +1,163 @@
+// src/controllers/controllers/conditions.go
+package controllers
+
+import (
+    "strings"
+    "time"
+
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Condition types reported on Qraiop status
+const (
+    conditionReady                 = "Ready"
+    conditionPaused                = "Paused"
+    conditionNetworkPolicyLearning = "NetworkPolicyLearning"
+    conditionComponentDependencies = "ComponentDependencies"
+    conditionVersionSkew           = "VersionSkew"
+    conditionUpgraded              = "Upgraded"
+    conditionSilences              = "Silences"
+    conditionUnsupportedCluster    = "UnsupportedCluster"
+    conditionDeprecated            = "Deprecated"
+    conditionUnsupportedAlgorithm  = "UnsupportedAlgorithm"
+)
+
+// setCondition records a condition against the instance's current generation
+func setCondition(q *qraiopv1.Qraiop, conditionType string, status metav1.ConditionStatus, reason, message string) {
+    meta.SetStatusCondition(&q.Status.Conditions, metav1.Condition{
+        Type:               conditionType,
+        Status:             status,
+        Reason:             reason,
+        Message:            message,
+        ObservedGeneration: q.Generation,
+    })
+}
+
+// reportDeprecations summarizes the deprecated fields and values the spec
+// of q uses in its Deprecated condition, so they can be migrated before
+// they are removed
+func reportDeprecations(q *qraiopv1.Qraiop) {
+    deprecations := q.Deprecations()
+    if len(deprecations) == 0 {
+        setCondition(q, conditionDeprecated, metav1.ConditionFalse, "NoDeprecatedFields", "the spec uses no deprecated fields or values")
+        return
+    }
+    msgs := make([]string, 0, len(deprecations))
+    for _, d := range deprecations {
+        msgs = append(msgs, d.String())
+    }
+    setCondition(q, conditionDeprecated, metav1.ConditionTrue, "DeprecatedFields", strings.Join(msgs, "; "))
+}
+
+// setComponentStatus records a component's status, leaving LastUpdated
+// untouched when nothing changed
+func setComponentStatus(q *qraiopv1.Qraiop, name, status, message string) {
+    if q.Status.Components == nil {
+        q.Status.Components = make(map[string]qraiopv1.ComponentStatus)
+    }
+    cur, ok := q.Status.Components[name]
+    // The rollout isn't observed while the spec holds the component back
+    if status == qraiopv1.ComponentBlocked || status == qraiopv1.ComponentDisabled {
+        cur.Observed = nil
+        q.Status.Components[name] = cur
+    }
+    if ok && cur.Status == status && cur.Message == message {
+        return
+    }
+    cur.Status = status
+    cur.Message = message
+    cur.LastUpdated = metav1.Now()
+    q.Status.Components[name] = cur
+}
+
+// smoothingObservationInterval spaces the observations of a component
+// whose status flip is held back
+const smoothingObservationInterval = 15 * time.Second
+
+// smoothComponentStatus records a component's rollout status through the
+// instance's smoothing, keeping the raw observation in Observed, and
+// returns the status in effect. Without smoothing, or coming from Blocked
+// or Disabled, the status is taken as is.
+func smoothComponentStatus(q *qraiopv1.Qraiop, name, status, message string) string {
+    cfg := q.Spec.ComponentHealth.Smoothing
+    if cfg == nil {
+        setComponentStatus(q, name, status, message)
+        return status
+    }
+    cur := q.Status.Components[name]
+    now := metav1.Now()
+    observed := qraiopv1.ObservedComponentStatus{Status: status, Since: now}
+    if cur.Observed != nil && cur.Observed.Status == status {
+        observed = *cur.Observed
+    }
+    observed.Message = message
+    needed := cfg.ConsecutiveObservations
+    if needed < 1 {
+        needed = 1
+    }
+    if observed.Observations < needed {
+        observed.Observations++
+    }
+
+    effective := cur.Status
+    settled := observed.Observations >= needed && now.Sub(observed.Since.Time) >= minDwell(cfg)
+    if settled || cur.Status == "" || cur.Status == status ||
+        cur.Status == qraiopv1.ComponentBlocked || cur.Status == qraiopv1.ComponentDisabled {
+        setComponentStatus(q, name, status, message)
+        effective = status
+    }
+    cur = q.Status.Components[name]
+    cur.Observed = &observed
+    q.Status.Components[name] = cur
+    return effective
+}
+
+// smoothingRequeue returns when the next held back status flip can happen,
+// or 0 when there is none
+func smoothingRequeue(q *qraiopv1.Qraiop) time.Duration {
+    cfg := q.Spec.ComponentHealth.Smoothing
+    if cfg == nil {
+        return 0
+    }
+    var after time.Duration
+    for _, st := range q.Status.Components {
+        if st.Observed == nil || st.Observed.Status == st.Status {
+            continue
+        }
+        wait := minDwell(cfg) - time.Since(st.Observed.Since.Time)
+        if wait < smoothingObservationInterval {
+            wait = smoothingObservationInterval
+        }
+        if after == 0 || wait < after {
+            after = wait
+        }
+    }
+    return after
+}
+
+func minDwell(cfg *qraiopv1.StatusSmoothing) time.Duration {
+    if cfg.MinDwell == nil {
+        return 0
+    }
+    return cfg.MinDwell.Duration
+}
+
+// setComponentRestarts records a component's restart analytics
+func setComponentRestarts(q *qraiopv1.Qraiop, name string, stats *qraiopv1.RestartStats) {
+    cur := q.Status.Components[name]
+    cur.Restarts = stats
+    q.Status.Components[name] = cur
+}
+
+// setComponentFlags records a component's feature flag delivery
+func setComponentFlags(q *qraiopv1.Qraiop, name string, flags *qraiopv1.FeatureFlagStatus) {
+    if q.Status.Components == nil {
+        q.Status.Components = make(map[string]qraiopv1.ComponentStatus)
+    }
+    cur := q.Status.Components[name]
+    cur.FeatureFlags = flags
+    q.Status.Components[name] = cur
+}
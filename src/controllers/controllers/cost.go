@@ -0,0 +1,195 @@
+// src/controllers/controllers/cost.go
+package controllers
+
+import (
+    "context"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    defaultCostInterval = 5 * time.Minute
+    defaultCurrency     = "USD"
+)
+
+// podMetricsGVK is served by metrics-server; without it only requests count
+var podMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+
+var (
+    componentRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_component_resource_requests",
+        Help: "Resources requested by a component's running pods, in cores and bytes.",
+    }, []string{"instance", "namespace", "component", "subsystem", "resource"})
+    componentUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_component_resource_usage",
+        Help: "Resources used by a component's running pods as reported by the metrics API, in cores and bytes.",
+    }, []string{"instance", "namespace", "component", "subsystem", "resource"})
+    componentHourlyCost = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_component_hourly_cost",
+        Help: "Hourly cost of a component, charging the larger of its requests and usage.",
+    }, []string{"instance", "namespace", "component", "subsystem", "currency"})
+)
+
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+func init() {
+    metrics.Registry.MustRegister(componentRequests, componentUsage, componentHourlyCost)
+}
+
+// costSubsystem groups a component under the subsystem it is billed to
+func costSubsystem(component string) string {
+    switch {
+    case strings.HasPrefix(component, qraiopv1.ComponentCryptography):
+        return "crypto"
+    case component == qraiopv1.ComponentAIOrchestration:
+        return "ai"
+    case component == qraiopv1.ComponentChaosEngineering:
+        return "chaos"
+    }
+    return component
+}
+
+// resourceSample is the CPU in cores and memory in bytes of a component
+type resourceSample struct {
+    cpu    float64
+    memory float64
+}
+
+// reconcileCost samples what every enabled component requests and uses,
+// exports it with its price and keeps the hourly cost per subsystem in the
+// status
+func (r *QraiopReconciler) reconcileCost(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    instance := q.Namespace + "/" + q.Name
+    cfg := q.Spec.Cost
+    if cfg == nil || !cfg.Enabled {
+        if q.Status.Cost != nil {
+            labels := prometheus.Labels{"instance": instance}
+            componentRequests.DeletePartialMatch(labels)
+            componentUsage.DeletePartialMatch(labels)
+            componentHourlyCost.DeletePartialMatch(labels)
+        }
+        q.Status.Cost = nil
+        return 0, nil
+    }
+    interval := defaultCostInterval
+    if cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    now := time.Now()
+    if st := q.Status.Cost; st != nil {
+        if due := st.SampledAt.Add(interval); due.After(now) {
+            return due.Sub(now), nil
+        }
+    }
+    currency := cfg.Currency
+    if currency == "" {
+        currency = defaultCurrency
+    }
+    // Prices are validated by the CRD, so a parse error only means unset
+    cpuPrice, _ := strconv.ParseFloat(cfg.CPUCoreHour, 64)
+    memoryPrice, _ := strconv.ParseFloat(cfg.MemoryGiBHour, 64)
+
+    usage, err := r.podUsage(ctx, q.Namespace)
+    if err != nil {
+        return 0, err
+    }
+    bySubsystem := make(map[string]float64)
+    total := 0.0
+    for _, c := range r.components(q) {
+        if !c.enabled {
+            continue
+        }
+        var pods corev1.PodList
+        if err := r.List(ctx, &pods, client.InNamespace(q.Namespace), client.MatchingLabels(componentSelector(q, c.deployment))); err != nil {
+            return 0, err
+        }
+        var requested, used resourceSample
+        for _, p := range pods.Items {
+            if p.Status.Phase != corev1.PodRunning {
+                continue
+            }
+            for _, ctr := range p.Spec.Containers {
+                requested.cpu += ctr.Resources.Requests.Cpu().AsApproximateFloat64()
+                requested.memory += ctr.Resources.Requests.Memory().AsApproximateFloat64()
+            }
+            if u, ok := usage[p.Name]; ok {
+                used.cpu += u.cpu
+                used.memory += u.memory
+            }
+        }
+        subsystem := costSubsystem(c.name)
+        componentRequests.WithLabelValues(instance, q.Namespace, c.name, subsystem, "cpu").Set(requested.cpu)
+        componentRequests.WithLabelValues(instance, q.Namespace, c.name, subsystem, "memory").Set(requested.memory)
+        if usage != nil {
+            componentUsage.WithLabelValues(instance, q.Namespace, c.name, subsystem, "cpu").Set(used.cpu)
+            componentUsage.WithLabelValues(instance, q.Namespace, c.name, subsystem, "memory").Set(used.memory)
+        }
+        hourly := maxFloat(requested.cpu, used.cpu)*cpuPrice + maxFloat(requested.memory, used.memory)/(1<<30)*memoryPrice
+        componentHourlyCost.WithLabelValues(instance, q.Namespace, c.name, subsystem, currency).Set(hourly)
+        bySubsystem[subsystem] += hourly
+        total += hourly
+    }
+
+    st := &qraiopv1.CostStatus{
+        Currency:  currency,
+        SampledAt: metav1.Time{Time: now},
+        Hourly:    formatCost(total),
+    }
+    for subsystem, hourly := range bySubsystem {
+        st.Subsystems = append(st.Subsystems, qraiopv1.SubsystemCost{Subsystem: subsystem, Hourly: formatCost(hourly)})
+    }
+    sort.Slice(st.Subsystems, func(i, j int) bool { return st.Subsystems[i].Subsystem < st.Subsystems[j].Subsystem })
+    q.Status.Cost = st
+    return interval, nil
+}
+
+// podUsage reads the usage of the namespace's pods from the metrics API.
+// It returns nil when the API is not served.
+func (r *QraiopReconciler) podUsage(ctx context.Context, namespace string) (map[string]resourceSample, error) {
+    if _, err := r.RESTMapper().RESTMapping(podMetricsGVK.GroupKind(), podMetricsGVK.Version); err != nil {
+        return nil, nil
+    }
+    var list unstructured.UnstructuredList
+    list.SetGroupVersionKind(podMetricsGVK.GroupVersion().WithKind(podMetricsGVK.Kind + "List"))
+    if err := r.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    usage := make(map[string]resourceSample, len(list.Items))
+    for _, item := range list.Items {
+        containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+        var s resourceSample
+        for _, ctr := range containers {
+            m, ok := ctr.(map[string]interface{})
+            if !ok {
+                continue
+            }
+            cpu, _, _ := unstructured.NestedString(m, "usage", "cpu")
+            memory, _, _ := unstructured.NestedString(m, "usage", "memory")
+            if q, err := resource.ParseQuantity(cpu); err == nil {
+                s.cpu += q.AsApproximateFloat64()
+            }
+            if q, err := resource.ParseQuantity(memory); err == nil {
+                s.memory += q.AsApproximateFloat64()
+            }
+        }
+        usage[item.GetName()] = s
+    }
+    return usage, nil
+}
+
+func formatCost(v float64) string {
+    return strconv.FormatFloat(v, 'f', 4, 64)
+}
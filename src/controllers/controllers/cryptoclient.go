@@ -0,0 +1,86 @@
+// src/controllers/controllers/cryptoclient.go
+package controllers
+
+import (
+    "strings"
+    "sync"
+
+    "k8s.io/apimachinery/pkg/types"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// cryptoClients keeps one crypto service connection pool per instance, so
+// frequent calls reuse warm connections across reconciles
+type cryptoClients struct {
+    mu      sync.Mutex
+    clients map[types.NamespacedName]*cryptoclient.Client
+    // suffix tells the pools of another controller apart in metrics
+    suffix string
+}
+
+func newCryptoClients() *cryptoClients {
+    return &cryptoClients{clients: make(map[types.NamespacedName]*cryptoclient.Client)}
+}
+
+// get returns the pool for q, replacing it when its settings changed
+func (c *cryptoClients) get(q *qraiopv1.Qraiop) *cryptoclient.Client {
+    return c.forService(q, "")
+}
+
+// forService returns the pool for the crypto service of q, or for its
+// crypto pool named pool
+func (c *cryptoClients) forService(q *qraiopv1.Qraiop, pool string) *cryptoclient.Client {
+    key := types.NamespacedName{Namespace: q.Namespace, Name: q.Name}
+    service := "qraiop-crypto"
+    if pool != "" {
+        key.Name += "/" + pool
+        service = cryptoPoolDeployment(pool)
+    }
+    opts := cryptoClientOptions(q.Spec.Cryptography.Client)
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if cur, ok := c.clients[key]; ok {
+        if cur.Options() == opts {
+            return cur
+        }
+        cur.Close()
+    }
+    endpoint := "http://" + service + "." + q.Namespace + ".svc:8080"
+    client := cryptoclient.New(endpoint, key.String()+c.suffix, opts)
+    c.clients[key] = client
+    return client
+}
+
+// remove closes the pools of a deleted instance
+func (c *cryptoClients) remove(key types.NamespacedName) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for k, cur := range c.clients {
+        if k == key || (k.Namespace == key.Namespace && strings.HasPrefix(k.Name, key.Name+"/")) {
+            cur.Close()
+            delete(c.clients, k)
+        }
+    }
+}
+
+func cryptoClientOptions(cfg *qraiopv1.CryptoClientConfig) cryptoclient.Options {
+    var opts cryptoclient.Options
+    if cfg != nil {
+        opts = cryptoclient.Options{
+            MaxConnections: int(cfg.MaxConnections),
+            IdleTimeout:    cfg.IdleTimeout.Duration,
+            KeepAlive:      cfg.KeepAlive.Duration,
+            CallTimeout:    cfg.CallTimeout.Duration,
+            HTTP2:          cfg.HTTP2,
+        }
+    }
+    return opts.WithDefaults()
+}
+
+// cryptoClient returns the crypto service client of q
+func (r *QraiopReconciler) cryptoClient(q *qraiopv1.Qraiop) *cryptoclient.Client {
+    return r.cryptoClients.get(q)
+}
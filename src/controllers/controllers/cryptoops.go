@@ -0,0 +1,81 @@
+// src/controllers/controllers/cryptoops.go
+package controllers
+
+import (
+    "context"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/uuid"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// pendingCryptoOperation returns the journalled operation of type opType
+func pendingCryptoOperation(q *qraiopv1.Qraiop, opType string) *qraiopv1.CryptoOperation {
+    for i := range q.Status.CryptoOperations {
+        if q.Status.CryptoOperations[i].Type == opType {
+            return &q.Status.CryptoOperations[i]
+        }
+    }
+    return nil
+}
+
+// beginCryptoOperation journals an operation of type opType on subject and
+// returns a context carrying its idempotency key. A pending operation on
+// the same subject, left by an interrupted reconcile, is resumed with its
+// key; one on another subject was superseded and is replaced. New
+// operations are persisted before the call is made, so a restart during
+// the call cannot repeat it under a fresh key.
+func (r *QraiopReconciler) beginCryptoOperation(ctx context.Context, q *qraiopv1.Qraiop, opType, subject string) (context.Context, *qraiopv1.CryptoOperation, error) {
+    if op := pendingCryptoOperation(q, opType); op != nil && op.Subject == subject {
+        op.Attempts++
+        r.Log.Info("resuming crypto operation", "qraiop", client.ObjectKeyFromObject(q), "type", opType,
+            "subject", subject, "attempt", op.Attempts, "startedAt", op.StartedAt.UTC().Format(time.RFC3339))
+        return cryptoclient.WithIdempotencyKey(ctx, op.IdempotencyKey), op, nil
+    }
+    removeCryptoOperation(q, opType)
+    q.Status.CryptoOperations = append(q.Status.CryptoOperations, qraiopv1.CryptoOperation{
+        Type:           opType,
+        IdempotencyKey: string(uuid.NewUUID()),
+        Subject:        subject,
+        // Whole seconds, as the status stores them, so a resumed call sends
+        // the same timestamps as the first one
+        StartedAt: metav1.NewTime(time.Now().Truncate(time.Second)),
+        Attempts:  1,
+    })
+    if err := r.persistStatusField(ctx, q, "cryptoOperations", q.Status.CryptoOperations); err != nil {
+        return nil, nil, err
+    }
+    op := pendingCryptoOperation(q, opType)
+    return cryptoclient.WithIdempotencyKey(ctx, op.IdempotencyKey), op, nil
+}
+
+// failCryptoOperation records why an attempt failed; the operation stays
+// pending and is retried with the same key
+func failCryptoOperation(op *qraiopv1.CryptoOperation, err error) {
+    op.LastError = err.Error()
+}
+
+// finishCryptoOperation drops a completed operation and persists its
+// outcome right away, as the deferred status patch cannot remove the
+// journal entry written before the call
+func (r *QraiopReconciler) finishCryptoOperation(ctx context.Context, q *qraiopv1.Qraiop, opType string) error {
+    removeCryptoOperation(q, opType)
+    return r.persistStatusField(ctx, q, "cryptoOperations", q.Status.CryptoOperations)
+}
+
+func removeCryptoOperation(q *qraiopv1.Qraiop, opType string) {
+    ops := q.Status.CryptoOperations[:0]
+    for _, op := range q.Status.CryptoOperations {
+        if op.Type != opType {
+            ops = append(ops, op)
+        }
+    }
+    if len(ops) == 0 {
+        ops = nil
+    }
+    q.Status.CryptoOperations = ops
+}
@@ -0,0 +1,44 @@
+// src/controllers/controllers/devmode.go
+package controllers
+
+import (
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// DefaultMockImage is the image of cmd/qraiop-mock run under --dev-mode
+const DefaultMockImage = imageRegistry + "/qraiop-mock:latest"
+
+// mockComponents maps component names to what qraiop-mock mocks for them
+var mockComponents = map[string]string{
+    qraiopv1.ComponentCryptography:     "crypto",
+    qraiopv1.ComponentAIOrchestration:  "ai",
+    qraiopv1.ComponentChaosEngineering: "chaos",
+    qraiopv1.ComponentMonitoring:       "monitoring",
+}
+
+// mockComponent swaps a component's image for the mock server in dev
+// mode. Crypto pools run the crypto mock; addons keep their own images.
+func (r *QraiopReconciler) mockComponent(name string, deployment *appsv1.Deployment) {
+    if !r.DevMode {
+        return
+    }
+    mock, ok := mockComponents[strings.SplitN(name, "/", 2)[0]]
+    if !ok {
+        return
+    }
+    image := r.MockImage
+    if image == "" {
+        image = DefaultMockImage
+    }
+    container := &deployment.Spec.Template.Spec.Containers[0]
+    container.Image = image
+    container.Command = nil
+    container.Args = []string{"-component", mock}
+    render.Explain(deployment, "spec.template.spec.containers[0].image", image, "--dev-mode")
+    render.Explain(deployment, "spec.template.spec.containers[0].args", "-component "+mock, "--dev-mode")
+}
@@ -0,0 +1,447 @@
+// src/controllers/controllers/enrollment.go
+package controllers
+
+import (
+    "context"
+    "crypto/subtle"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/json"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/go-logr/logr"
+    "github.com/prometheus/client_golang/prometheus"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/client-go/tools/record"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/certwatcher"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/certauthority"
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+const (
+    // enrollmentPath prefixes the enrollment API:
+    // POST /enroll/v1/<namespace>/<instance>/certificates
+    enrollmentPath = "/enroll/v1/"
+    // enrollmentAuditConfigMap keeps the latest enrollments of an instance
+    enrollmentAuditConfigMap = "qraiop-enrollment-audit"
+    enrollmentAuditKey       = "audit.log"
+    enrollmentAuditEntries   = 200
+    defaultEnrollmentTTL     = 720 * time.Hour
+    defaultEnrollmentRate    = 6
+    // enrollmentLookupRate limits the requests of a client address before
+    // the instance, and so its own rate, is known
+    enrollmentLookupRate = 60
+)
+
+var enrollmentRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+    Name: "qraiop_enrollment_requests_total",
+    Help: "Requests to the enrollment API, by result.",
+}, []string{"result"})
+
+func init() {
+    metrics.Registry.MustRegister(enrollmentRequests)
+}
+
+// EnrollmentServer serves the enrollment API, through which clients
+// outside the cluster get certificates from an instance's authorities with
+// a bootstrap token. Every replica serves it; tokens and the audit log live
+// in the cluster, so they are shared. Rate limits are kept in memory by
+// each replica, so a client spread over n replicas gets n times the rate.
+type EnrollmentServer struct {
+    Client   client.Client
+    Log      logr.Logger
+    Recorder record.EventRecorder
+    // Addr is the address the API is served on, e.g. :8443
+    Addr string
+    // CertDir holds the serving certificate, tls.crt and tls.key
+    CertDir string
+
+    cryptoClients *cryptoClients
+    limiter       *rateLimiter
+}
+
+// enrollmentRequest asks for a certificate. The key pair is generated by
+// the issuing authority.
+type enrollmentRequest struct {
+    CommonName  string   `json:"commonName,omitempty"`
+    DNSNames    []string `json:"dnsNames,omitempty"`
+    IPAddresses []string `json:"ipAddresses,omitempty"`
+    TTLSeconds  int64    `json:"ttlSeconds,omitempty"`
+}
+
+type enrollmentResponse struct {
+    cryptoclient.Certificate
+    Authority string    `json:"authority"`
+    NotAfter  time.Time `json:"notAfter"`
+}
+
+// enrollmentRecord is a line of the audit log
+type enrollmentRecord struct {
+    Time      time.Time `json:"time"`
+    Token     string    `json:"token"`
+    Client    string    `json:"client"`
+    SANs      []string  `json:"sans"`
+    Authority string    `json:"authority"`
+    Serial    string    `json:"serial,omitempty"`
+    NotAfter  time.Time `json:"notAfter,omitempty"`
+}
+
+// NeedLeaderElection is false: every replica serves enrollments
+func (s *EnrollmentServer) NeedLeaderElection() bool {
+    return false
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Start serves the API until ctx is done
+func (s *EnrollmentServer) Start(ctx context.Context) error {
+    s.cryptoClients = newCryptoClients()
+    s.cryptoClients.suffix = "/enrollment"
+    s.limiter = newRateLimiter()
+
+    watcher, err := certwatcher.New(filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key"))
+    if err != nil {
+        return fmt.Errorf("loading enrollment serving certificate: %w", err)
+    }
+    go func() {
+        if err := watcher.Start(ctx); err != nil {
+            s.Log.Error(err, "enrollment serving certificate watcher stopped")
+        }
+    }()
+
+    mux := http.NewServeMux()
+    mux.HandleFunc(enrollmentPath, s.serveEnroll)
+    srv := &http.Server{
+        Addr:              s.Addr,
+        Handler:           mux,
+        ReadHeaderTimeout: 10 * time.Second,
+        TLSConfig:         &tls.Config{GetCertificate: watcher.GetCertificate, MinVersion: tls.VersionTLS12},
+    }
+    go func() {
+        <-ctx.Done()
+        shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        _ = srv.Shutdown(shutdown)
+    }()
+    s.Log.Info("serving enrollment API", "addr", s.Addr)
+    if err := srv.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+        return err
+    }
+    return nil
+}
+
+// enrollmentError is a refused enrollment, with the status it is answered
+// with
+type enrollmentError struct {
+    code   int
+    result string
+    msg    string
+}
+
+func (e *enrollmentError) Error() string {
+    return e.msg
+}
+
+func refuse(code int, result, format string, args ...interface{}) error {
+    return &enrollmentError{code: code, result: result, msg: fmt.Sprintf(format, args...)}
+}
+
+func (s *EnrollmentServer) serveEnroll(w http.ResponseWriter, req *http.Request) {
+    req.Body = http.MaxBytesReader(w, req.Body, 64<<10)
+    resp, err := s.enroll(req)
+    if err != nil {
+        var e *enrollmentError
+        if !errors.As(err, &e) {
+            s.Log.Error(err, "enrollment failed")
+            e = &enrollmentError{code: http.StatusBadGateway, result: "failed", msg: "certificate could not be issued"}
+        }
+        enrollmentRequests.WithLabelValues(e.result).Inc()
+        http.Error(w, e.msg, e.code)
+        return
+    }
+    enrollmentRequests.WithLabelValues("issued").Inc()
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(resp)
+}
+
+func (s *EnrollmentServer) enroll(req *http.Request) (*enrollmentResponse, error) {
+    ctx := req.Context()
+    parts := strings.Split(strings.TrimPrefix(req.URL.Path, enrollmentPath), "/")
+    if len(parts) != 3 || parts[2] != "certificates" {
+        return nil, refuse(http.StatusNotFound, "invalid", "not found")
+    }
+    if req.Method != http.MethodPost {
+        return nil, refuse(http.StatusMethodNotAllowed, "invalid", "use POST")
+    }
+    namespace, name := parts[0], parts[1]
+    clientAddr, _, _ := net.SplitHostPort(req.RemoteAddr)
+    token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+    if !ok || token == "" {
+        return nil, refuse(http.StatusUnauthorized, "unauthorized", "bearer token required")
+    }
+
+    if !s.limiter.allow("lookup/"+clientAddr, enrollmentLookupRate) {
+        return nil, refuse(http.StatusTooManyRequests, "throttled", "too many requests")
+    }
+
+    // Unknown instances and those without enrollment are refused like an
+    // invalid token, so clients can't tell which instances enroll
+    var q qraiopv1.Qraiop
+    if err := s.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &q); err != nil {
+        if apierrors.IsNotFound(err) {
+            return nil, refuse(http.StatusUnauthorized, "unauthorized", "invalid token")
+        }
+        return nil, err
+    }
+    cfg := q.Spec.Cryptography.Enrollment
+    if cfg == nil || !cfg.Enabled || !q.Spec.Cryptography.Enabled {
+        return nil, refuse(http.StatusUnauthorized, "unauthorized", "invalid token")
+    }
+    rate := defaultEnrollmentRate
+    if cfg.RequestsPerMinute > 0 {
+        rate = int(cfg.RequestsPerMinute)
+    }
+    if !s.limiter.allow("addr/"+clientAddr, rate) {
+        return nil, refuse(http.StatusTooManyRequests, "throttled", "too many requests")
+    }
+    secret, err := s.findToken(ctx, &q, token)
+    if err != nil {
+        return nil, err
+    }
+    if !s.limiter.allow("token/"+namespace+"/"+secret.Name, rate) {
+        return nil, refuse(http.StatusTooManyRequests, "throttled", "too many requests")
+    }
+
+    var in enrollmentRequest
+    if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+        return nil, refuse(http.StatusBadRequest, "invalid", "invalid request: %v", err)
+    }
+    for _, ip := range in.IPAddresses {
+        if net.ParseIP(ip) == nil {
+            return nil, refuse(http.StatusBadRequest, "invalid", "invalid IP address %q", ip)
+        }
+    }
+    sans := append(append([]string(nil), in.DNSNames...), in.IPAddresses...)
+    if len(sans) == 0 {
+        return nil, refuse(http.StatusBadRequest, "invalid", "at least one DNS name or IP address is required")
+    }
+    if in.CommonName == "" {
+        in.CommonName = sans[0]
+    }
+    patterns := cfg.SANPatterns
+    if narrowed := secret.Annotations[qraiopv1.EnrollmentSANsAnnotation]; narrowed != "" {
+        patterns = strings.Split(narrowed, ",")
+    }
+    for _, san := range append(sans, in.CommonName) {
+        if !matchesSAN(patterns, []string{san}) {
+            return nil, refuse(http.StatusForbidden, "forbidden", "%s is not allowed for this token", san)
+        }
+    }
+    ttl := defaultEnrollmentTTL
+    if cfg.MaxLifetime.Duration > 0 {
+        ttl = cfg.MaxLifetime.Duration
+    }
+    if requested := time.Duration(in.TTLSeconds) * time.Second; requested > 0 && requested < ttl {
+        ttl = requested
+    }
+
+    authority := cfg.Authority
+    if authority == "" {
+        authority = routeCertificate(q.Spec.Cryptography.CertManagement, "", sans)
+    }
+    var issuer certauthority.Issuer = s.cryptoClients.get(&q)
+    if authority != "" {
+        a := findAuthority(q.Spec.Cryptography.CertManagement, authority)
+        if a == nil {
+            return nil, fmt.Errorf("enrollment routed to unknown certificate authority %s", authority)
+        }
+        if issuer, err = authorityIssuer(ctx, s.Client, s.cryptoClients, &q, a); err != nil {
+            return nil, err
+        }
+    }
+
+    // The use is spent before issuing, so concurrent requests can't
+    // enroll more than the token allows
+    if err := s.spendToken(ctx, secret); err != nil {
+        return nil, err
+    }
+    cert, err := issuer.IssueCertificate(ctx, cryptoclient.CertificateRequest{
+        CommonName:  in.CommonName,
+        DNSNames:    in.DNSNames,
+        IPAddresses: in.IPAddresses,
+    }, ttl)
+    if err != nil {
+        return nil, fmt.Errorf("issuing certificate: %w", err)
+    }
+    if authority == "" {
+        authority = "crypto-service"
+    }
+
+    record := enrollmentRecord{Time: time.Now().UTC(), Token: secret.Name, Client: clientAddr, SANs: sans, Authority: authority}
+    if block, _ := pem.Decode([]byte(cert.Certificate)); block != nil {
+        if parsed, err := x509.ParseCertificate(block.Bytes); err == nil {
+            record.Serial = parsed.SerialNumber.Text(16)
+            record.NotAfter = parsed.NotAfter
+        }
+    }
+    if err := s.audit(ctx, &q, record); err != nil {
+        // The certificate is out; losing its record must not lose it
+        s.Log.Error(err, "unable to record enrollment", "qraiop", name, "namespace", namespace)
+    }
+    s.Log.Info("certificate enrolled", "qraiop", name, "namespace", namespace, "token", secret.Name,
+        "client", clientAddr, "sans", sans, "authority", authority, "serial", record.Serial)
+    s.Recorder.Eventf(secret, corev1.EventTypeNormal, "CertificateEnrolled",
+        "%s enrolled a certificate for %s from %s", clientAddr, strings.Join(sans, ","), authority)
+    return &enrollmentResponse{Certificate: cert, Authority: authority, NotAfter: record.NotAfter}, nil
+}
+
+// findToken returns the bootstrap token Secret of q holding token, when
+// it is still usable
+func (s *EnrollmentServer) findToken(ctx context.Context, q *qraiopv1.Qraiop, token string) (*corev1.Secret, error) {
+    var secrets corev1.SecretList
+    if err := s.Client.List(ctx, &secrets, client.InNamespace(q.Namespace),
+        client.MatchingLabels{qraiopv1.EnrollmentTokenLabel: q.Name}); err != nil {
+        return nil, err
+    }
+    for i := range secrets.Items {
+        secret := &secrets.Items[i]
+        want := secret.Data["token"]
+        if len(want) == 0 || subtle.ConstantTimeCompare(want, []byte(token)) != 1 {
+            continue
+        }
+        if expires := secret.Annotations[qraiopv1.EnrollmentExpiresAnnotation]; expires != "" {
+            at, err := time.Parse(time.RFC3339, expires)
+            if err != nil || time.Now().After(at) {
+                return nil, refuse(http.StatusUnauthorized, "unauthorized", "token expired")
+            }
+        }
+        if uses, ok := secret.Annotations[qraiopv1.EnrollmentUsesAnnotation]; ok {
+            if n, err := strconv.Atoi(uses); err != nil || n <= 0 {
+                return nil, refuse(http.StatusUnauthorized, "unauthorized", "token used up")
+            }
+        }
+        return secret, nil
+    }
+    return nil, refuse(http.StatusUnauthorized, "unauthorized", "invalid token")
+}
+
+// spendToken takes one use off a limited token
+func (s *EnrollmentServer) spendToken(ctx context.Context, secret *corev1.Secret) error {
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        var cur corev1.Secret
+        if err := s.Client.Get(ctx, client.ObjectKeyFromObject(secret), &cur); err != nil {
+            return err
+        }
+        uses, ok := cur.Annotations[qraiopv1.EnrollmentUsesAnnotation]
+        if !ok {
+            return nil
+        }
+        n, err := strconv.Atoi(uses)
+        if err != nil || n <= 0 {
+            return refuse(http.StatusUnauthorized, "unauthorized", "token used up")
+        }
+        cur.Annotations[qraiopv1.EnrollmentUsesAnnotation] = strconv.Itoa(n - 1)
+        return s.Client.Update(ctx, &cur)
+    })
+}
+
+// audit appends an enrollment to the audit log of q, keeping the latest
+// enrollmentAuditEntries
+func (s *EnrollmentServer) audit(ctx context.Context, q *qraiopv1.Qraiop, rec enrollmentRecord) error {
+    line, err := json.Marshal(rec)
+    if err != nil {
+        return err
+    }
+    return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+        cm := &corev1.ConfigMap{}
+        err := s.Client.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: enrollmentAuditConfigMap}, cm)
+        if apierrors.IsNotFound(err) {
+            cm = &corev1.ConfigMap{
+                ObjectMeta: metav1.ObjectMeta{
+                    Name:      enrollmentAuditConfigMap,
+                    Namespace: q.Namespace,
+                    Labels: map[string]string{
+                        "app.kubernetes.io/managed-by": "qraiop-controller",
+                        "app.kubernetes.io/part-of":    "qraiop",
+                    },
+                },
+                Data: map[string]string{enrollmentAuditKey: string(line) + "\n"},
+            }
+            return s.Client.Create(ctx, cm)
+        }
+        if err != nil {
+            return err
+        }
+        lines := strings.SplitAfter(cm.Data[enrollmentAuditKey], "\n")
+        lines = append(lines[:len(lines)-1], string(line)+"\n")
+        if len(lines) > enrollmentAuditEntries {
+            lines = lines[len(lines)-enrollmentAuditEntries:]
+        }
+        if cm.Data == nil {
+            cm.Data = make(map[string]string)
+        }
+        cm.Data[enrollmentAuditKey] = strings.Join(lines, "")
+        return s.Client.Update(ctx, cm)
+    })
+}
+
+// rateLimiter is a token bucket per key, refilled at the rate per minute
+type rateLimiter struct {
+    mu      sync.Mutex
+    buckets map[string]*bucket
+}
+
+type bucket struct {
+    tokens float64
+    last   time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+    return &rateLimiter{buckets: make(map[string]*bucket)}
+}
+
+// allow takes a token from key's bucket of size perMinute
+func (l *rateLimiter) allow(key string, perMinute int) bool {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := time.Now()
+    b, ok := l.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(perMinute), last: now}
+        l.buckets[key] = b
+    }
+    b.tokens += now.Sub(b.last).Minutes() * float64(perMinute)
+    if b.tokens > float64(perMinute) {
+        b.tokens = float64(perMinute)
+    }
+    b.last = now
+    // Full buckets carry no state; forget them so the map stays small
+    for k, other := range l.buckets {
+        if k != key && now.Sub(other.last) > time.Hour {
+            delete(l.buckets, k)
+        }
+    }
+    if b.tokens < 1 {
+        return false
+    }
+    b.tokens--
+    return true
+}
@@ -0,0 +1,98 @@
+// src/controllers/controllers/featureflags.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// FlagsHashAnnotation carries the hash of the feature flags a pod should run with
+const FlagsHashAnnotation = "qraiop.io/feature-flags-hash"
+
+// applyFeatureFlags writes a component's flags ConfigMap and mounts it into
+// the Deployment, returning the flags hash, or "" when it has no flags
+func (r *QraiopReconciler) applyFeatureFlags(ctx context.Context, q *qraiopv1.Qraiop, deployment *appsv1.Deployment, opts qraiopv1.ComponentOptions) (string, error) {
+    if len(opts.FeatureFlags) == 0 {
+        return "", nil
+    }
+    data, err := json.MarshalIndent(opts.FeatureFlags, "", "  ")
+    if err != nil {
+        return "", err
+    }
+    h := fnv.New64a()
+    h.Write(data)
+    hash := fmt.Sprintf("%x", h.Sum64())
+
+    cm := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      deployment.Name + "-flags",
+            Namespace: q.Namespace,
+            Labels:    componentLabels(q, deployment.Name),
+        },
+        Data: map[string]string{render.FlagsFile: string(data)},
+    }
+    if err := r.createOrUpdate(ctx, q, cm); err != nil {
+        return "", err
+    }
+
+    template := &deployment.Spec.Template
+    render.FeatureFlags(&template.Spec, cm.Name)
+    if opts.FlagReload == qraiopv1.FlagReloadRestart {
+        if template.Annotations == nil {
+            template.Annotations = make(map[string]string)
+        }
+        template.Annotations[FlagsHashAnnotation] = hash
+    }
+    return hash, nil
+}
+
+// signalFeatureFlags tells running pods about changed flags by stamping the
+// new hash on them, unless the Restart strategy rolls them instead, and
+// records delivery in the component's status
+func (r *QraiopReconciler) signalFeatureFlags(ctx context.Context, q *qraiopv1.Qraiop, name string, deployment *appsv1.Deployment, opts qraiopv1.ComponentOptions, hash string) error {
+    if hash == "" {
+        setComponentFlags(q, name, nil)
+        return nil
+    }
+
+    var pods corev1.PodList
+    if err := r.List(ctx, &pods, client.InNamespace(q.Namespace), client.MatchingLabels(componentSelector(q, deployment.Name))); err != nil {
+        return err
+    }
+    st := &qraiopv1.FeatureFlagStatus{Flags: opts.FeatureFlags, Hash: hash}
+    for i := range pods.Items {
+        pod := &pods.Items[i]
+        if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+            continue
+        }
+        st.PodsTotal++
+        if pod.Annotations[FlagsHashAnnotation] == hash {
+            st.PodsUpdated++
+            continue
+        }
+        if opts.FlagReload == qraiopv1.FlagReloadRestart {
+            continue
+        }
+        patch := client.MergeFrom(pod.DeepCopy())
+        if pod.Annotations == nil {
+            pod.Annotations = make(map[string]string)
+        }
+        pod.Annotations[FlagsHashAnnotation] = hash
+        if err := r.Patch(ctx, pod, patch); err != nil {
+            return fmt.Errorf("signalling flags to pod %s: %w", pod.Name, err)
+        }
+        st.PodsUpdated++
+    }
+    setComponentFlags(q, name, st)
+    return nil
+}
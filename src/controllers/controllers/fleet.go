@@ -0,0 +1,93 @@
+// src/controllers/controllers/fleet.go
+package controllers
+
+import (
+    "context"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// fleetScrapeTimeout bounds the cache reads of one scrape
+const fleetScrapeTimeout = 10 * time.Second
+
+var (
+    fleetInstancesDesc = prometheus.NewDesc("qraiop_fleet_instances",
+        "Qraiop instances in the cluster by phase.", []string{"phase"}, nil)
+    fleetNamespacesDesc = prometheus.NewDesc("qraiop_fleet_namespaces",
+        "Namespaces holding at least one Qraiop instance.", nil, nil)
+    fleetCertExpiryDesc = prometheus.NewDesc("qraiop_fleet_oldest_cert_expiry_timestamp_seconds",
+        "Expiry of the QRAIOP certificate expiring first, in seconds since the epoch.", []string{"namespace", "secret"}, nil)
+    fleetExperimentsDesc = prometheus.NewDesc("qraiop_fleet_chaos_experiments_week",
+        "Chaos experiments started in the last seven days by verdict.", []string{"verdict"}, nil)
+    fleetResilienceDesc = prometheus.NewDesc("qraiop_fleet_resilience_score",
+        "Resilience score out of 100 of a namespace, from its chaos experiments of the last 30 days.", []string{"namespace"}, nil)
+)
+
+// fleetCollector exports the fleet summary of all instances at scrape
+// time, read from the manager's cache, so dashboards spanning many
+// namespaces need no per-instance queries
+type fleetCollector struct {
+    reader client.Reader
+}
+
+func (c *fleetCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- fleetInstancesDesc
+    ch <- fleetNamespacesDesc
+    ch <- fleetCertExpiryDesc
+    ch <- fleetExperimentsDesc
+    ch <- fleetResilienceDesc
+}
+
+// Collect exports nothing while the cache can't be read, e.g. before it
+// has synced
+func (c *fleetCollector) Collect(ch chan<- prometheus.Metric) {
+    ctx, cancel := context.WithTimeout(context.Background(), fleetScrapeTimeout)
+    defer cancel()
+    f, err := fleetSummary(ctx, c.reader)
+    if err != nil {
+        return
+    }
+    for _, phase := range []string{"Ready", "Progressing", "Degraded", "Blocked", "Initializing"} {
+        if _, ok := f.Phases[phase]; !ok {
+            f.Phases[phase] = 0
+        }
+    }
+    for phase, n := range f.Phases {
+        ch <- prometheus.MustNewConstMetric(fleetInstancesDesc, prometheus.GaugeValue, float64(n), phase)
+    }
+    ch <- prometheus.MustNewConstMetric(fleetNamespacesDesc, prometheus.GaugeValue, float64(f.Namespaces))
+    if cert := f.OldestCert; cert != nil {
+        ch <- prometheus.MustNewConstMetric(fleetCertExpiryDesc, prometheus.GaugeValue,
+            float64(cert.NotAfter.Unix()), cert.Namespace, cert.Secret)
+    }
+    for verdict, n := range f.ExperimentsThisWeek {
+        ch <- prometheus.MustNewConstMetric(fleetExperimentsDesc, prometheus.GaugeValue, float64(n), verdict)
+    }
+    for _, nc := range f.Resilience {
+        ch <- prometheus.MustNewConstMetric(fleetResilienceDesc, prometheus.GaugeValue, float64(nc.Score), nc.Namespace)
+    }
+}
+
+// fleetSummary aggregates every instance, experiment and QRAIOP
+// certificate of the cluster
+func fleetSummary(ctx context.Context, c client.Reader) (*report.Fleet, error) {
+    var instances qraiopv1.QraiopList
+    if err := c.List(ctx, &instances); err != nil {
+        return nil, err
+    }
+    var experiments qraiopv1.ChaosExperimentList
+    if err := c.List(ctx, &experiments); err != nil {
+        return nil, err
+    }
+    var secrets corev1.SecretList
+    if err := c.List(ctx, &secrets, client.MatchingLabels{"app.kubernetes.io/part-of": "qraiop"}); err != nil {
+        return nil, err
+    }
+    return report.Summarize(instances.Items, experiments.Items, secrets.Items, time.Now()), nil
+}
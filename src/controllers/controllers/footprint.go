@@ -0,0 +1,204 @@
+// src/controllers/controllers/footprint.go
+package controllers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const conditionWithinBudget = "WithinBudget"
+
+// workloadKinds are the inventory kinds that run pods, with the typed
+// objects to read them into
+var workloadKinds = map[string]func() client.Object{
+    "apps/Deployment":  func() client.Object { return &appsv1.Deployment{} },
+    "apps/StatefulSet": func() client.Object { return &appsv1.StatefulSet{} },
+    "batch/Job":        func() client.Object { return &batchv1.Job{} },
+    "batch/CronJob":    func() client.Object { return &batchv1.CronJob{} },
+}
+
+// workloadPods returns the pod spec of a workload and how many of its pods
+// run at once
+func workloadPods(obj client.Object) (*corev1.PodSpec, int32, bool) {
+    orOne := func(n *int32) int32 {
+        if n == nil {
+            return 1
+        }
+        return *n
+    }
+    switch w := obj.(type) {
+    case *appsv1.Deployment:
+        return &w.Spec.Template.Spec, orOne(w.Spec.Replicas), true
+    case *appsv1.StatefulSet:
+        return &w.Spec.Template.Spec, orOne(w.Spec.Replicas), true
+    case *batchv1.Job:
+        return &w.Spec.Template.Spec, orOne(w.Spec.Parallelism), true
+    case *batchv1.CronJob:
+        return &w.Spec.JobTemplate.Spec.Template.Spec, orOne(w.Spec.JobTemplate.Spec.Parallelism), true
+    }
+    return nil, 0, false
+}
+
+// podResources is what the scheduler reserves for a pod: its containers
+// and sidecars, or its largest init container if that is more, plus the
+// pod overhead
+func podResources(spec *corev1.PodSpec) (requests, limits corev1.ResourceList) {
+    requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+    sidecarRequests, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+    initRequests, initLimits := corev1.ResourceList{}, corev1.ResourceList{}
+    for _, c := range spec.InitContainers {
+        if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+            addResources(sidecarRequests, c.Resources.Requests)
+            addResources(sidecarLimits, c.Resources.Limits)
+            continue
+        }
+        // An init container runs next to the sidecars started before it
+        req, lim := sidecarRequests.DeepCopy(), sidecarLimits.DeepCopy()
+        addResources(req, c.Resources.Requests)
+        addResources(lim, c.Resources.Limits)
+        maxResources(initRequests, req)
+        maxResources(initLimits, lim)
+    }
+    for _, c := range spec.Containers {
+        addResources(requests, c.Resources.Requests)
+        addResources(limits, c.Resources.Limits)
+    }
+    addResources(requests, sidecarRequests)
+    addResources(limits, sidecarLimits)
+    maxResources(requests, initRequests)
+    maxResources(limits, initLimits)
+    addResources(requests, spec.Overhead)
+    addResources(limits, spec.Overhead)
+    return requests, limits
+}
+
+func addResources(dst, src corev1.ResourceList) {
+    for name, q := range src {
+        sum := dst[name]
+        sum.Add(q)
+        dst[name] = sum
+    }
+}
+
+func maxResources(dst, src corev1.ResourceList) {
+    for name, q := range src {
+        if cur, ok := dst[name]; !ok || q.Cmp(cur) > 0 {
+            dst[name] = q.DeepCopy()
+        }
+    }
+}
+
+// footprint sums the workloads among the inventory entries. Workloads in
+// written are taken as written there; the others are read from the cluster.
+func (r *QraiopReconciler) footprint(ctx context.Context, entries []qraiopv1.InventoryEntry, written map[string]client.Object) (*qraiopv1.FootprintStatus, error) {
+    fp := &qraiopv1.FootprintStatus{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+    for _, e := range entries {
+        newObj, ok := workloadKinds[e.Group+"/"+e.Kind]
+        if !ok {
+            continue
+        }
+        obj, ok := written[entryKey(e)]
+        if !ok {
+            obj = newObj()
+            if err := r.Get(ctx, client.ObjectKey{Namespace: e.Namespace, Name: e.Name}, obj); err != nil {
+                if apierrors.IsNotFound(err) {
+                    continue
+                }
+                return nil, err
+            }
+        }
+        spec, pods, _ := workloadPods(obj)
+        requests, limits := podResources(spec)
+        w := qraiopv1.WorkloadFootprint{Kind: e.Kind, Name: e.Name, Pods: pods, Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+        for i := int32(0); i < pods; i++ {
+            addResources(w.Requests, requests)
+            addResources(w.Limits, limits)
+        }
+        addResources(fp.Requests, w.Requests)
+        addResources(fp.Limits, w.Limits)
+        fp.Workloads = append(fp.Workloads, w)
+    }
+    sort.Slice(fp.Workloads, func(i, j int) bool {
+        if fp.Workloads[i].Kind != fp.Workloads[j].Kind {
+            return fp.Workloads[i].Kind < fp.Workloads[j].Kind
+        }
+        return fp.Workloads[i].Name < fp.Workloads[j].Name
+    })
+    return fp, nil
+}
+
+// reconcileFootprint records what the instance's workloads add up to, as
+// listed in its inventory
+func (r *QraiopReconciler) reconcileFootprint(ctx context.Context, q *qraiopv1.Qraiop) error {
+    fp, err := r.footprint(ctx, q.Status.Inventory, nil)
+    if err != nil {
+        return err
+    }
+    q.Status.Footprint = fp
+    return nil
+}
+
+// overBudget lists the resources of fp above the budget
+func overBudget(fp *qraiopv1.FootprintStatus, budget *qraiopv1.ResourceBudget) []string {
+    var over []string
+    check := func(kind string, total, caps corev1.ResourceList) {
+        for name, limit := range caps {
+            if used := total[name]; used.Cmp(limit) > 0 {
+                over = append(over, fmt.Sprintf("%s.%s %s exceeds %s", kind, name, used.String(), limit.String()))
+            }
+        }
+    }
+    check("requests", fp.Requests, budget.Requests)
+    check("limits", fp.Limits, budget.Limits)
+    sort.Strings(over)
+    return over
+}
+
+// checkBudget works out the footprint reconciling q would leave behind,
+// without making any change, and reports whether it fits Spec.Budget. A
+// spec that does not fit is blocked, keeping the workloads as they are.
+func (r *QraiopReconciler) checkBudget(ctx context.Context, q *qraiopv1.Qraiop) (bool, error) {
+    if q.Spec.Budget == nil {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionWithinBudget)
+        return true, nil
+    }
+    p := newPlan(q)
+    planner := *r
+    planner.Client = &planningClient{Client: r.Client, plan: p}
+    dry := q.DeepCopy()
+    if _, err := planner.apply(ctx, dry); err != nil {
+        return false, err
+    }
+    projected, err := r.footprint(ctx, dry.Status.Inventory, p.workloads)
+    if err != nil {
+        return false, err
+    }
+
+    if over := overBudget(projected, q.Spec.Budget); len(over) > 0 {
+        msg := "rollout would exceed the resource budget: " + strings.Join(over, ", ")
+        setCondition(q, conditionWithinBudget, metav1.ConditionFalse, "BudgetExceeded", msg)
+        block(q, errors.New(msg))
+        return false, nil
+    }
+    setCondition(q, conditionWithinBudget, metav1.ConditionTrue, "WithinBudget",
+        fmt.Sprintf("rollout requests %s of cpu and %s of memory", quantityOf(projected.Requests, corev1.ResourceCPU), quantityOf(projected.Requests, corev1.ResourceMemory)))
+    return true, nil
+}
+
+func quantityOf(list corev1.ResourceList, name corev1.ResourceName) string {
+    q := list[name]
+    return q.String()
+}
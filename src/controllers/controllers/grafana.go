@@ -0,0 +1,369 @@
+// src/controllers/controllers/grafana.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "hash/fnv"
+    "reflect"
+    "slices"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    conditionGrafanaAlerting = "GrafanaAlerting"
+    // grafanaRuleInterval is how often Grafana evaluates the alert rules,
+    // in seconds
+    grafanaRuleInterval = 60
+)
+
+// grafanaSettings renames the channel keys the monitoring component reads
+// to the settings of Grafana's integrations. Other keys are passed as is.
+var grafanaSettings = map[string]map[string]string{
+    "slack":     {"webhook_url": "url", "channel": "recipient"},
+    "email":     {"to": "addresses"},
+    "pagerduty": {"routing_key": "integrationKey", "service_key": "integrationKey"},
+}
+
+// usesGrafana reports whether q's alerting is provisioned in Grafana
+func usesGrafana(q *qraiopv1.Qraiop) bool {
+    cfg := q.Spec.Monitoring.Alerting
+    return cfg != nil && cfg.Enabled && cfg.Provider == qraiopv1.AlertingProviderGrafana && cfg.Grafana != nil
+}
+
+// instanceAlertmanager returns the Alertmanager q's alerts go through:
+// Grafana's built-in one with the grafana provider, or the configured one.
+// It returns nil when q has none.
+func instanceAlertmanager(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop) (*alerting.Alertmanager, error) {
+    cfg := q.Spec.Monitoring.Alerting
+    if cfg == nil || !cfg.Enabled {
+        return nil, nil
+    }
+    if usesGrafana(q) {
+        g, err := grafanaFor(ctx, c, q)
+        if err != nil {
+            return nil, err
+        }
+        return g.AlertmanagerAPI(), nil
+    }
+    if cfg.AlertmanagerURL == "" {
+        return nil, nil
+    }
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    return &alerting.Alertmanager{URL: cfg.AlertmanagerURL, HTTP: httpClient}, nil
+}
+
+// grafanaFor returns a client for the Grafana configured on q, with the
+// token read from its Secret
+func grafanaFor(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop) (*alerting.Grafana, error) {
+    cfg := q.Spec.Monitoring.Alerting.Grafana
+    var secret corev1.Secret
+    if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: cfg.TokenSecret.Name}, &secret); err != nil {
+        return nil, fmt.Errorf("reading Grafana token: %w", err)
+    }
+    token, ok := secret.Data[cfg.TokenSecret.Key]
+    if !ok {
+        return nil, fmt.Errorf("Secret %s has no key %s", cfg.TokenSecret.Name, cfg.TokenSecret.Key)
+    }
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    return &alerting.Grafana{
+        URL:   cfg.URL,
+        Token: strings.TrimSpace(string(token)),
+        OrgID: cfg.OrgID,
+        HTTP:  httpClient,
+    }, nil
+}
+
+// reconcileGrafanaAlerting provisions q's alerting in Grafana: a contact
+// point per channel, a notification policy under the root routing alerts
+// labelled with the instance to all of them, and the QRAIOP alert rules.
+// Contact points of removed channels are deleted. The rest of the policy
+// tree is left alone.
+func (r *QraiopReconciler) reconcileGrafanaAlerting(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.Monitoring.Alerting
+    if !usesGrafana(q) {
+        if meta.FindStatusCondition(q.Status.Conditions, conditionGrafanaAlerting) == nil {
+            return nil
+        }
+        // Switched away from Grafana: remove what was provisioned while its
+        // settings are still there
+        if cfg != nil && cfg.Grafana != nil {
+            if err := r.removeGrafanaAlerting(ctx, q); err != nil {
+                // The condition stays until the removal succeeds
+                setCondition(q, conditionGrafanaAlerting, metav1.ConditionFalse, "RemovalFailed", err.Error())
+                return nil
+            }
+        }
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionGrafanaAlerting)
+        return nil
+    }
+    if cfg.Grafana.DatasourceUID == "" {
+        setCondition(q, conditionGrafanaAlerting, metav1.ConditionFalse, "NoDatasource", "monitoring.alerting.grafana.datasourceUID is not set")
+        return nil
+    }
+    g, err := grafanaFor(ctx, r, q)
+    if err != nil {
+        setCondition(q, conditionGrafanaAlerting, metav1.ConditionFalse, "TokenUnavailable", err.Error())
+        return nil
+    }
+
+    prefix := grafanaName(q, "")
+    existing, err := g.ContactPoints(ctx, prefix)
+    if err != nil {
+        return r.grafanaFailed(q, "contact points", err)
+    }
+    var receivers []string
+    for _, ch := range cfg.Channels {
+        cp, err := r.contactPoint(ctx, q, ch)
+        if err != nil {
+            setCondition(q, conditionGrafanaAlerting, metav1.ConditionFalse, "ChannelInvalid", fmt.Sprintf("channel %s: %v", ch.Name, err))
+            return nil
+        }
+        // Grafana redacts secure settings when listing, so contact points
+        // can't be compared and are written every time
+        if err := g.PutContactPoint(ctx, cp); err != nil {
+            return r.grafanaFailed(q, "contact point "+cp.Name, err)
+        }
+        receivers = append(receivers, cp.Name)
+    }
+
+    root, err := g.PolicyTree(ctx)
+    if err != nil {
+        return r.grafanaFailed(q, "notification policies", err)
+    }
+    if routeInstance(root, q, instancePolicy(q, receivers)) {
+        if err := g.PutPolicyTree(ctx, root); err != nil {
+            return r.grafanaFailed(q, "notification policies", err)
+        }
+    }
+
+    // Contact points can only go once no policy routes to them
+    for _, cp := range existing {
+        if !slices.Contains(receivers, cp.Name) {
+            if err := g.DeleteContactPoint(ctx, cp.UID); err != nil {
+                return r.grafanaFailed(q, "contact point "+cp.Name, err)
+            }
+        }
+    }
+
+    folder, folderUID := grafanaFolder(q)
+    if err := g.EnsureFolder(ctx, folderUID, folder); err != nil {
+        return r.grafanaFailed(q, "folder "+folder, err)
+    }
+    if err := g.PutRuleGroup(ctx, folderUID, grafanaName(q, "rules"), cfg.Grafana.DatasourceUID, grafanaRuleInterval, grafanaRules(q)); err != nil {
+        return r.grafanaFailed(q, "alert rules", err)
+    }
+
+    setCondition(q, conditionGrafanaAlerting, metav1.ConditionTrue, "Provisioned",
+        fmt.Sprintf("%d contact point(s) and the QRAIOP alert rules provisioned in folder %s", len(receivers), folder))
+    return nil
+}
+
+// removeGrafanaAlerting deletes the rule group, policy and contact points
+// provisioned for q
+func (r *QraiopReconciler) removeGrafanaAlerting(ctx context.Context, q *qraiopv1.Qraiop) error {
+    g, err := grafanaFor(ctx, r, q)
+    if err != nil {
+        return err
+    }
+    _, folderUID := grafanaFolder(q)
+    if err := g.DeleteRuleGroup(ctx, folderUID, grafanaName(q, "rules")); err != nil {
+        return fmt.Errorf("alert rules: %w", err)
+    }
+    root, err := g.PolicyTree(ctx)
+    if err != nil {
+        return fmt.Errorf("notification policies: %w", err)
+    }
+    if routeInstance(root, q, nil) {
+        if err := g.PutPolicyTree(ctx, root); err != nil {
+            return fmt.Errorf("notification policies: %w", err)
+        }
+    }
+    existing, err := g.ContactPoints(ctx, grafanaName(q, ""))
+    if err != nil {
+        return fmt.Errorf("contact points: %w", err)
+    }
+    for _, cp := range existing {
+        if err := g.DeleteContactPoint(ctx, cp.UID); err != nil {
+            return fmt.Errorf("contact point %s: %w", cp.Name, err)
+        }
+    }
+    return nil
+}
+
+// grafanaFailed records a failed Grafana call on the condition. Requests
+// Grafana refuses stay reported there rather than failing the reconcile;
+// an unreachable Grafana fails it to be retried.
+func (r *QraiopReconciler) grafanaFailed(q *qraiopv1.Qraiop, what string, err error) error {
+    setCondition(q, conditionGrafanaAlerting, metav1.ConditionFalse, "ProvisioningFailed", fmt.Sprintf("%s: %v", what, err))
+    if _, ok := err.(*alerting.StatusError); ok {
+        return nil
+    }
+    return err
+}
+
+// contactPoint returns the contact point of a channel, with the channel's
+// Secret merged over its config
+func (r *QraiopReconciler) contactPoint(ctx context.Context, q *qraiopv1.Qraiop, ch qraiopv1.AlertChannel) (alerting.ContactPoint, error) {
+    values := make(map[string]string, len(ch.Config))
+    for k, v := range ch.Config {
+        values[k] = v
+    }
+    if name := channelSecret(q, ch); name != "" {
+        var secret corev1.Secret
+        if err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret); err != nil {
+            return alerting.ContactPoint{}, err
+        }
+        for k, v := range secret.Data {
+            values[k] = string(v)
+        }
+    }
+    settings := make(map[string]string, len(values))
+    for k, v := range values {
+        if renamed, ok := grafanaSettings[ch.Type][k]; ok {
+            k = renamed
+        }
+        settings[k] = v
+    }
+    name := grafanaName(q, ch.Name)
+    return alerting.ContactPoint{
+        UID:      grafanaUID("contact-point", name),
+        Name:     name,
+        Type:     ch.Type,
+        Settings: settings,
+    }, nil
+}
+
+// instancePolicy is the policy routing q's alerts to each of receivers.
+// Without receivers there is none and the alerts take the default policy.
+func instancePolicy(q *qraiopv1.Qraiop, receivers []string) *alerting.Route {
+    if len(receivers) == 0 {
+        return nil
+    }
+    policy := &alerting.Route{
+        Receiver:       receivers[0],
+        GroupBy:        []string{"alertname", render.ComponentMetricLabel},
+        ObjectMatchers: instanceMatchers(q),
+    }
+    for _, name := range receivers {
+        policy.Routes = append(policy.Routes, alerting.Route{Receiver: name, Continue: true})
+    }
+    return policy
+}
+
+// routeInstance puts policy in place of the root's route for q, removing
+// it when policy is nil. It reports whether the tree changed.
+func routeInstance(root *alerting.Route, q *qraiopv1.Qraiop, policy *alerting.Route) bool {
+    matchers := instanceMatchers(q)
+    for i, route := range root.Routes {
+        if !reflect.DeepEqual(route.ObjectMatchers, matchers) {
+            continue
+        }
+        if policy == nil {
+            root.Routes = append(root.Routes[:i], root.Routes[i+1:]...)
+            return true
+        }
+        policy.Rest = route.Rest
+        if reflect.DeepEqual(route, *policy) {
+            return false
+        }
+        root.Routes[i] = *policy
+        return true
+    }
+    if policy == nil {
+        return false
+    }
+    // Ahead of the admins' routes, which would otherwise catch the alerts
+    root.Routes = append([]alerting.Route{*policy}, root.Routes...)
+    return true
+}
+
+func instanceMatchers(q *qraiopv1.Qraiop) [][]string {
+    return [][]string{{render.InstanceMetricLabel, "=", q.Namespace + "/" + q.Name}}
+}
+
+// grafanaRules are the alert rules of monitoring.yml, limited to q
+func grafanaRules(q *qraiopv1.Qraiop) []alerting.Rule {
+    instance := q.Namespace + "/" + q.Name
+    selector := fmt.Sprintf(`namespace=%q`, q.Namespace)
+    labels := func(severity string) map[string]string {
+        return map[string]string{render.InstanceMetricLabel: instance, "severity": severity}
+    }
+    rules := []alerting.Rule{
+        {
+            Title:     "QraiopComponentDown",
+            Expr:      fmt.Sprintf(`count by (%s) (up{%s,%s=%q} == 0)`, render.ComponentMetricLabel, selector, render.InstanceMetricLabel, q.Name),
+            Threshold: 0,
+            For:       "5m",
+            Labels:    labels("critical"),
+            Annotations: map[string]string{
+                "summary":     "QRAIOP component is down",
+                "description": fmt.Sprintf("{{ $labels.%s }} of %s has been down for more than 5 minutes.", render.ComponentMetricLabel, instance),
+            },
+        },
+        {
+            Title:     "HighChaosFailureRate",
+            Expr:      fmt.Sprintf(`sum(rate(chaos_experiments_failed_total{%s}[5m]))`, selector),
+            Threshold: 0.1,
+            For:       "10m",
+            Labels:    labels("warning"),
+            Annotations: map[string]string{
+                "summary":     "High chaos experiment failure rate",
+                "description": fmt.Sprintf("Chaos experiments in %s are failing at a high rate.", q.Namespace),
+            },
+        },
+        {
+            Title:     "AIAgentUnresponsive",
+            Expr:      fmt.Sprintf(`max by (agent_id) (time() - ai_agent_last_heartbeat{%s})`, selector),
+            Threshold: 300,
+            For:       "5m",
+            Labels:    labels("warning"),
+            Annotations: map[string]string{
+                "summary":     "AI Agent unresponsive",
+                "description": "AI agent {{ $labels.agent_id }} has not responded in 5+ minutes.",
+            },
+        },
+    }
+    for i := range rules {
+        rules[i].UID = grafanaUID("rule", instance+"/"+rules[i].Title)
+    }
+    return rules
+}
+
+// grafanaName names an object provisioned for q; with an empty suffix it
+// is the prefix of all of them. Kubernetes names have no underscores, so
+// the prefixes of two instances never overlap.
+func grafanaName(q *qraiopv1.Qraiop, suffix string) string {
+    return "qraiop_" + q.Namespace + "_" + q.Name + "_" + suffix
+}
+
+// grafanaFolder returns the title and UID of the folder of q's rules
+func grafanaFolder(q *qraiopv1.Qraiop) (string, string) {
+    folder := q.Spec.Monitoring.Alerting.Grafana.Folder
+    if folder == "" {
+        folder = "QRAIOP"
+    }
+    return folder, grafanaUID("folder", folder)
+}
+
+// grafanaUID derives a stable UID within Grafana's 40 character limit
+func grafanaUID(kind, name string) string {
+    h := fnv.New64a()
+    h.Write([]byte(kind + "/" + name))
+    return fmt.Sprintf("qraiop-%016x", h.Sum64())
+}
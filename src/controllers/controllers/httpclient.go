@@ -0,0 +1,49 @@
+// src/controllers/controllers/httpclient.go
+package controllers
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "fmt"
+    "net/http"
+    "net/url"
+    "time"
+
+    "golang.org/x/net/http/httpproxy"
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// newHTTPClient returns the client the operator uses for its own outbound
+// calls on behalf of an instance (AI providers, registries, alerting), routed
+// through the instance's proxy and trusting its CA bundle when configured
+func newHTTPClient(ctx context.Context, c client.Reader, namespace string, p *qraiopv1.ProxyConfig) (*http.Client, error) {
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    if p != nil {
+        proxyFunc := (&httpproxy.Config{
+            HTTPProxy:  p.HTTPProxy,
+            HTTPSProxy: p.HTTPSProxy,
+            NoProxy:    render.NoProxy(p),
+        }).ProxyFunc()
+        transport.Proxy = func(req *http.Request) (*url.URL, error) {
+            return proxyFunc(req.URL)
+        }
+
+        if p.TrustedCA != nil {
+            var cm corev1.ConfigMap
+            if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: p.TrustedCA.Name}, &cm); err != nil {
+                return nil, fmt.Errorf("reading proxy CA bundle: %w", err)
+            }
+            pool := x509.NewCertPool()
+            if !pool.AppendCertsFromPEM([]byte(cm.Data[p.TrustedCA.Key])) {
+                return nil, fmt.Errorf("proxy CA bundle %s/%s has no PEM certificates", p.TrustedCA.Name, p.TrustedCA.Key)
+            }
+            transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+        }
+    }
+    return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
@@ -0,0 +1,125 @@
+// src/controllers/controllers/idle.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const defaultIdleAfter = 30 * time.Minute
+
+// agentActivity is what the AI orchestration service reports about its tasks
+type agentActivity struct {
+    ActiveTasks int       `json:"activeTasks"`
+    LastTaskAt  time.Time `json:"lastTaskAt"`
+}
+
+// aiIdle applies the idle policy of the AI orchestration component and
+// reports whether it should be scaled to zero. While it runs its activity
+// is polled; while it is idle only a wake signal brings it back.
+func (r *QraiopReconciler) aiIdle(ctx context.Context, q *qraiopv1.Qraiop) bool {
+    cfg := q.Spec.AIOrchestration.Idle
+    if cfg == nil || !cfg.Enabled {
+        q.Status.AIIdle = nil
+        return false
+    }
+    now := metav1.Now()
+    st := q.Status.AIIdle
+    if st == nil {
+        st = &qraiopv1.IdleStatus{LastActivity: now, WakeTrigger: q.Annotations[qraiopv1.WakeAnnotation]}
+        q.Status.AIIdle = st
+    }
+
+    if st.Idle {
+        reason := wakeReason(q, st)
+        if reason == "" {
+            return true
+        }
+        r.Log.Info("waking idle AI orchestration", "qraiop", client.ObjectKeyFromObject(q), "reason", reason)
+        st.Idle = false
+        st.IdleSince = nil
+        st.LastActivity = now
+        st.WokenBy = reason
+        st.WakeTrigger = q.Annotations[qraiopv1.WakeAnnotation]
+        return false
+    }
+    st.WakeTrigger = q.Annotations[qraiopv1.WakeAnnotation]
+
+    activity, err := r.agentActivity(ctx, q)
+    if err != nil {
+        // Agents that can't say whether they are busy are left running
+        r.Log.Error(err, "unable to read AI orchestration activity", "qraiop", client.ObjectKeyFromObject(q))
+        return false
+    }
+    if activity.ActiveTasks > 0 {
+        st.LastActivity = now
+    } else if activity.LastTaskAt.After(st.LastActivity.Time) {
+        st.LastActivity = metav1.NewTime(activity.LastTaskAt)
+    }
+
+    after := defaultIdleAfter
+    if cfg.After.Duration > 0 {
+        after = cfg.After.Duration
+    }
+    if time.Since(st.LastActivity.Time) < after {
+        return false
+    }
+    r.Log.Info("scaling idle AI orchestration to zero", "qraiop", client.ObjectKeyFromObject(q), "lastActivity", st.LastActivity)
+    st.Idle = true
+    st.IdleSince = &now
+    st.WokenBy = ""
+    return true
+}
+
+// wakeReason returns why an idle component must be woken, or "" when it
+// may stay idle
+func wakeReason(q *qraiopv1.Qraiop, st *qraiopv1.IdleStatus) string {
+    if v := q.Annotations[qraiopv1.WakeAnnotation]; v != "" && v != st.WakeTrigger {
+        return "annotation " + qraiopv1.WakeAnnotation + "=" + v
+    }
+    if q.Status.Phase == "Degraded" {
+        return "instance is Degraded"
+    }
+    for _, inc := range q.Status.SecurityIncidents {
+        if st.IdleSince == nil || inc.DetectedAt.After(st.IdleSince.Time) {
+            return "security incident " + inc.Type
+        }
+    }
+    return ""
+}
+
+// agentActivity asks the AI orchestration service about its tasks
+func (r *QraiopReconciler) agentActivity(ctx context.Context, q *qraiopv1.Qraiop) (*agentActivity, error) {
+    httpClient, err := newHTTPClient(ctx, r, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+    defer cancel()
+    url := "http://qraiop-ai." + q.Namespace + ".svc:8080/v1/tasks/activity"
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := httpClient.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("AI orchestration returned %s", resp.Status)
+    }
+    var activity agentActivity
+    if err := json.NewDecoder(resp.Body).Decode(&activity); err != nil {
+        return nil, err
+    }
+    return &activity, nil
+}
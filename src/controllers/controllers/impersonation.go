@@ -0,0 +1,120 @@
+// src/controllers/controllers/impersonation.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "reflect"
+
+    corev1 "k8s.io/api/core/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/client-go/rest"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const defaultTenantServiceAccount = "qraiop-reconciler"
+
+// tenantRules are the permissions the impersonated ServiceAccount gets in
+// its own namespace: exactly what reconciling the components needs
+var tenantRules = []rbacv1.PolicyRule{
+    {APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+    {APIGroups: []string{""}, Resources: []string{"services", "configmaps", "serviceaccounts"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+    {APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch", "patch"}},
+    {APIGroups: []string{"networking.k8s.io"}, Resources: []string{"networkpolicies"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+    {APIGroups: []string{"batch"}, Resources: []string{"cronjobs"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+}
+
+// tenantServiceAccount returns the ServiceAccount impersonated for q, or ""
+// when impersonation is off
+func tenantServiceAccount(q *qraiopv1.Qraiop) string {
+    cfg := q.Spec.Impersonation
+    if cfg == nil || !cfg.Enabled {
+        return ""
+    }
+    if cfg.ServiceAccountName != "" {
+        return cfg.ServiceAccountName
+    }
+    return defaultTenantServiceAccount
+}
+
+// tenantReconciler returns a copy of r whose client impersonates q's tenant
+// ServiceAccount, so changes to child objects are limited to what that
+// account may do in q's namespace. Without impersonation r is returned.
+//
+// The impersonating client reads from the API server directly rather than
+// the manager's cache.
+func (r *QraiopReconciler) tenantReconciler(ctx context.Context, q *qraiopv1.Qraiop) (*QraiopReconciler, error) {
+    sa := tenantServiceAccount(q)
+    if sa == "" {
+        return r, nil
+    }
+    if r.Config == nil {
+        return nil, fmt.Errorf("impersonation requested but the reconciler has no rest config")
+    }
+    if err := r.ensureTenantRBAC(ctx, q, sa); err != nil {
+        return nil, fmt.Errorf("unable to set up RBAC for %s: %w", sa, err)
+    }
+    // Nothing is written while planning, so reads keep the operator's
+    // identity: the tenant's RBAC may not exist yet
+    if r.planning() {
+        return r, nil
+    }
+
+    cfg := rest.CopyConfig(r.Config)
+    cfg.Impersonate = rest.ImpersonationConfig{
+        UserName: fmt.Sprintf("system:serviceaccount:%s:%s", q.Namespace, sa),
+    }
+    c, err := client.New(cfg, client.Options{Scheme: r.Scheme})
+    if err != nil {
+        return nil, err
+    }
+    tenant := *r
+    tenant.Client = c
+    return &tenant, nil
+}
+
+// ensureTenantRBAC creates the tenant ServiceAccount with a Role granting
+// tenantRules in q's namespace. It runs with the operator's own identity.
+func (r *QraiopReconciler) ensureTenantRBAC(ctx context.Context, q *qraiopv1.Qraiop, sa string) error {
+    labels := labelsForQraiop(q)
+    objects := []client.Object{
+        &corev1.ServiceAccount{
+            ObjectMeta: metav1.ObjectMeta{Name: sa, Namespace: q.Namespace, Labels: labels},
+        },
+        &rbacv1.Role{
+            ObjectMeta: metav1.ObjectMeta{Name: sa, Namespace: q.Namespace, Labels: labels},
+            Rules:      tenantRules,
+        },
+        &rbacv1.RoleBinding{
+            ObjectMeta: metav1.ObjectMeta{Name: sa, Namespace: q.Namespace, Labels: labels},
+            RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: sa},
+            Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa, Namespace: q.Namespace}},
+        },
+    }
+    for _, obj := range objects {
+        if err := r.createOrUpdate(ctx, q, obj); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// createOrUpdate applies obj, owned by q, taking over any existing object
+// the adoption policy lets it
+func (r *QraiopReconciler) createOrUpdate(ctx context.Context, q *qraiopv1.Qraiop, obj client.Object) error {
+    applyCommonMetadata(q, obj)
+    applyArgoCDMetadata(q, obj, -1)
+    if err := ctrl.SetControllerReference(q, obj, r.Scheme); err != nil {
+        return err
+    }
+
+    // Read into an empty object of obj's type: the uncached client decodes
+    // over what's there, so a copy of obj would keep q's controller
+    // reference on an unowned object and skip the adoption policy
+    existing := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+    return r.serverSideApply(ctx, q, obj, existing)
+}
@@ -0,0 +1,310 @@
+// src/controllers/controllers/incident_report.go
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// IncidentReportLabel marks incident report ConfigMaps with their instance
+const IncidentReportLabel = "qraiop.io/incident-report"
+
+const defaultIncidentReportsRetained = 10
+
+// trackDegradedPeriod notes when q turns Degraded and files an incident
+// report once it no longer is. A report that can't be filed is retried on
+// the next reconcile.
+func (r *QraiopReconciler) trackDegradedPeriod(ctx context.Context, q *qraiopv1.Qraiop) error {
+    degraded := q.Status.Phase == "Degraded"
+    if degraded {
+        if q.Status.DegradedSince == nil {
+            now := metav1.Now()
+            q.Status.DegradedSince = &now
+        }
+        return nil
+    }
+    if q.Status.DegradedSince == nil {
+        return nil
+    }
+    if cfg := q.Spec.IncidentReports; cfg != nil && cfg.Enabled {
+        if err := r.fileIncidentReport(ctx, q, q.Status.DegradedSince.Time, time.Now()); err != nil {
+            return err
+        }
+    }
+    q.Status.DegradedSince = nil
+    return nil
+}
+
+// fileIncidentReport stores the report of the Degraded period between start
+// and end, drops reports beyond the retention and posts it if asked to
+func (r *QraiopReconciler) fileIncidentReport(ctx context.Context, q *qraiopv1.Qraiop, start, end time.Time) error {
+    inc, err := r.incidentTimeline(ctx, q, start, end)
+    if err != nil {
+        return err
+    }
+    data, err := json.MarshalIndent(inc, "", "  ")
+    if err != nil {
+        return err
+    }
+    markdown := report.IncidentMarkdown(inc)
+
+    labels := labelsForQraiop(q)
+    labels[IncidentReportLabel] = q.Name
+    cm := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      q.Name + "-incident-" + start.UTC().Format("20060102-150405"),
+            Namespace: q.Namespace,
+            Labels:    labels,
+        },
+        Data: map[string]string{"report.json": string(data), "report.md": string(markdown)},
+    }
+    applyCommonMetadata(q, cm)
+    if err := ctrl.SetControllerReference(q, cm, r.Scheme); err != nil {
+        return err
+    }
+    // Reports aren't rendered objects, so they stay out of the inventory
+    if err := r.Create(ctx, cm); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+    q.Status.LastIncidentReport = cm.Name
+    r.Log.Info("filed incident report", "qraiop", client.ObjectKeyFromObject(q), "report", cm.Name, "duration", inc.Duration())
+
+    if err := r.pruneIncidentReports(ctx, q); err != nil {
+        r.Log.Error(err, "unable to remove old incident reports")
+    }
+    if q.Spec.IncidentReports.PostToChannels {
+        if err := r.postIncidentReport(ctx, q, inc, markdown); err != nil {
+            r.Log.Error(err, "unable to post incident report to alert channels")
+        }
+    }
+    return nil
+}
+
+// incidentTimeline gathers what happened to q between start and end:
+// condition changes, events, chaos experiments, AI agent actions, alerts
+// and security incidents
+func (r *QraiopReconciler) incidentTimeline(ctx context.Context, q *qraiopv1.Qraiop, start, end time.Time) (*report.Incident, error) {
+    inc := &report.Incident{Instance: q.Name, Namespace: q.Namespace, Start: start, End: end}
+    within := func(t time.Time) bool { return !t.Before(start) && !t.After(end) }
+    add := func(t time.Time, source, format string, args ...interface{}) {
+        inc.Timeline = append(inc.Timeline, report.TimelineEntry{Time: t, Source: source, Summary: fmt.Sprintf(format, args...)})
+    }
+
+    for _, c := range q.Status.Conditions {
+        if within(c.LastTransitionTime.Time) {
+            add(c.LastTransitionTime.Time, report.SourceCondition, "%s=%s (%s): %s", c.Type, c.Status, c.Reason, c.Message)
+        }
+    }
+    for _, s := range q.Status.SecurityIncidents {
+        if within(s.DetectedAt.Time) {
+            add(s.DetectedAt.Time, report.SourceSecurity, "%s: %s", s.Type, s.Message)
+        }
+    }
+
+    var events corev1.EventList
+    if err := r.List(ctx, &events, client.InNamespace(q.Namespace)); err != nil {
+        return nil, err
+    }
+    for _, e := range events.Items {
+        t := eventTime(e)
+        obj := e.InvolvedObject
+        ours := (obj.Kind == "Qraiop" && obj.Name == q.Name) || strings.HasPrefix(obj.Name, "qraiop-") || obj.Kind == "ChaosExperiment"
+        if !ours || !within(t) {
+            continue
+        }
+        source := report.SourceEvent
+        if e.Source.Component == "qraiop-ai" || e.ReportingController == "qraiop-ai" {
+            source = report.SourceAI
+        }
+        add(t, source, "%s/%s %s: %s", obj.Kind, obj.Name, e.Reason, e.Message)
+    }
+
+    var experiments qraiopv1.ChaosExperimentList
+    if err := r.List(ctx, &experiments); err != nil {
+        return nil, err
+    }
+    for _, exp := range experiments.Items {
+        st := exp.Status
+        if exp.Spec.Target.Namespace != q.Namespace || st.StartTime == nil || st.StartTime.After(end) {
+            continue
+        }
+        if st.CompletionTime != nil && st.CompletionTime.Time.Before(start) {
+            continue
+        }
+        add(st.StartTime.Time, report.SourceExperiment, "%s/%s: %s on %d pod(s), %s %s",
+            exp.Namespace, exp.Name, exp.Spec.Type, len(st.Targets), st.Phase, st.Verdict)
+    }
+
+    am, err := instanceAlertmanager(ctx, r, q)
+    if err != nil {
+        return nil, err
+    }
+    if am != nil {
+        alerts, err := am.Alerts(ctx, fmt.Sprintf("namespace=%q", q.Namespace))
+        if err != nil {
+            // Alertmanager only knows current alerts anyway; report without them
+            r.Log.Error(err, "unable to list alerts for incident report")
+        }
+        for _, a := range alerts {
+            if within(a.StartsAt) {
+                add(a.StartsAt, report.SourceAlert, "%s (%s): %s", a.Labels["alertname"], a.Status.State, a.Annotations["summary"])
+            }
+        }
+    }
+
+    inc.Sort()
+    return inc, nil
+}
+
+func eventTime(e corev1.Event) time.Time {
+    switch {
+    case !e.LastTimestamp.IsZero():
+        return e.LastTimestamp.Time
+    case !e.EventTime.IsZero():
+        return e.EventTime.Time
+    }
+    return e.FirstTimestamp.Time
+}
+
+// pruneIncidentReports keeps the newest reports of q up to the retention
+func (r *QraiopReconciler) pruneIncidentReports(ctx context.Context, q *qraiopv1.Qraiop) error {
+    retain := defaultIncidentReportsRetained
+    if n := q.Spec.IncidentReports.Retain; n > 0 {
+        retain = int(n)
+    }
+    var reports corev1.ConfigMapList
+    if err := r.List(ctx, &reports, client.InNamespace(q.Namespace), client.MatchingLabels{IncidentReportLabel: q.Name}); err != nil {
+        return err
+    }
+    if len(reports.Items) <= retain {
+        return nil
+    }
+    sort.Slice(reports.Items, func(i, j int) bool {
+        return reports.Items[j].CreationTimestamp.Before(&reports.Items[i].CreationTimestamp)
+    })
+    for i := retain; i < len(reports.Items); i++ {
+        if err := r.Delete(ctx, &reports.Items[i]); client.IgnoreNotFound(err) != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// postIncidentReport sends the report, formatted by the notification
+// templates, to the slack and generic webhook channels of q. Other channel
+// types are left to the monitoring component.
+func (r *QraiopReconciler) postIncidentReport(ctx context.Context, q *qraiopv1.Qraiop, inc *report.Incident, markdown []byte) error {
+    cfg := q.Spec.Monitoring.Alerting
+    if cfg == nil || !cfg.Enabled {
+        return nil
+    }
+    httpClient, err := newHTTPClient(ctx, r, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return err
+    }
+    templates, _, err := r.notificationTemplates(ctx, q)
+    if err != nil {
+        return err
+    }
+    n := alerting.Notification{
+        Title:     "Incident report: " + q.Name,
+        Severity:  "info",
+        Instance:  q.Name,
+        Namespace: q.Namespace,
+        Summary:   fmt.Sprintf("%s was Degraded for %s", q.Name, inc.Duration().Round(time.Second)),
+        Details:   string(markdown),
+        Time:      inc.End,
+        Data:      inc,
+    }
+    var failed []string
+    for _, ch := range cfg.Channels {
+        if ch.Type != "slack" && ch.Type != "webhook" {
+            continue
+        }
+        msg, err := templates.Render(ch.Type, n)
+        if err == nil {
+            err = r.postToChannel(ctx, httpClient, q, ch, msg)
+        }
+        if err != nil {
+            failed = append(failed, fmt.Sprintf("%s: %v", ch.Name, err))
+        }
+    }
+    if len(failed) > 0 {
+        return fmt.Errorf("%s", strings.Join(failed, "; "))
+    }
+    return nil
+}
+
+// postToChannel posts a rendered notification to a slack or webhook
+// channel. Slack gets it as the message text, webhooks as the body.
+func (r *QraiopReconciler) postToChannel(ctx context.Context, httpClient *http.Client, q *qraiopv1.Qraiop, ch qraiopv1.AlertChannel, msg string) error {
+    target, err := r.channelSetting(ctx, q, ch, "webhook_url", "url")
+    if err != nil {
+        return err
+    }
+    if target == "" {
+        return fmt.Errorf("no webhook_url or url")
+    }
+    body := []byte(msg)
+    if ch.Type == "slack" {
+        if body, err = json.Marshal(map[string]string{"text": msg}); err != nil {
+            return err
+        }
+    }
+    return postJSON(ctx, httpClient, target, body)
+}
+
+// channelSetting returns the first of keys set on ch, from its Secret or
+// its config
+func (r *QraiopReconciler) channelSetting(ctx context.Context, q *qraiopv1.Qraiop, ch qraiopv1.AlertChannel, keys ...string) (string, error) {
+    var secret corev1.Secret
+    if name := channelSecret(q, ch); name != "" {
+        if err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret); err != nil {
+            return "", err
+        }
+    }
+    for _, k := range keys {
+        if v, ok := secret.Data[k]; ok {
+            return string(v), nil
+        }
+        if v, ok := ch.Config[k]; ok {
+            return v, nil
+        }
+    }
+    return "", nil
+}
+
+func postJSON(ctx context.Context, httpClient *http.Client, url string, body []byte) error {
+    ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := httpClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("POST returned %s", resp.Status)
+    }
+    return nil
+}
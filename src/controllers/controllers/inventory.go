@@ -0,0 +1,136 @@
+// src/controllers/controllers/inventory.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "sort"
+    "sync"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// inventory collects the objects rendered during one reconcile. It travels
+// in the context so writes made through the tenant client are recorded too.
+type inventory struct {
+    mu      sync.Mutex
+    entries map[string]qraiopv1.InventoryEntry
+    // partial is set when parts of the instance were skipped, e.g. blocked
+    // components, so objects missing from this pass must not be pruned
+    partial bool
+    // unowned are the existing objects left alone under the Ignore
+    // adoption policy
+    unowned []string
+}
+
+type inventoryKey struct{}
+
+func withInventory(ctx context.Context) (context.Context, *inventory) {
+    inv := &inventory{entries: make(map[string]qraiopv1.InventoryEntry)}
+    return context.WithValue(ctx, inventoryKey{}, inv), inv
+}
+
+func inventoryFrom(ctx context.Context) *inventory {
+    inv, _ := ctx.Value(inventoryKey{}).(*inventory)
+    return inv
+}
+
+func entryKey(e qraiopv1.InventoryEntry) string {
+    return fmt.Sprintf("%s/%s/%s/%s", e.Group, e.Kind, e.Namespace, e.Name)
+}
+
+// recordInventory adds obj, as about to be written, to the reconcile's inventory
+func (r *QraiopReconciler) recordInventory(ctx context.Context, obj client.Object) error {
+    inv := inventoryFrom(ctx)
+    if inv == nil {
+        return nil
+    }
+    gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+    if err != nil {
+        return err
+    }
+    data, err := json.Marshal(obj)
+    if err != nil {
+        return err
+    }
+    h := fnv.New64a()
+    h.Write(data)
+    e := qraiopv1.InventoryEntry{
+        Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind,
+        Namespace: obj.GetNamespace(), Name: obj.GetName(),
+        Hash: fmt.Sprintf("%x", h.Sum64()),
+    }
+    inv.mu.Lock()
+    inv.entries[entryKey(e)] = e
+    inv.mu.Unlock()
+    return nil
+}
+
+// markInventoryPartial keeps objects of the previous inventory that this
+// reconcile did not get to
+func markInventoryPartial(ctx context.Context) {
+    if inv := inventoryFrom(ctx); inv != nil {
+        inv.mu.Lock()
+        inv.partial = true
+        inv.mu.Unlock()
+    }
+}
+
+// pruneInventory deletes the objects of the previous inventory that were not
+// rendered this time and records the new inventory in status. Only objects
+// still controlled by q are deleted.
+func (r *QraiopReconciler) pruneInventory(ctx context.Context, q *qraiopv1.Qraiop, inv *inventory) error {
+    for _, old := range q.Status.Inventory {
+        if _, ok := inv.entries[entryKey(old)]; ok {
+            continue
+        }
+        if inv.partial {
+            inv.entries[entryKey(old)] = old
+            continue
+        }
+        if err := r.pruneObject(ctx, q, old); err != nil {
+            // Keep it listed so the delete is retried
+            inv.entries[entryKey(old)] = old
+            return fmt.Errorf("pruning %s %s/%s: %w", old.Kind, old.Namespace, old.Name, err)
+        }
+        if r.planning() {
+            continue
+        }
+        r.Log.Info("pruned object no longer rendered", "kind", old.Kind, "namespace", old.Namespace, "name", old.Name)
+    }
+
+    entries := make([]qraiopv1.InventoryEntry, 0, len(inv.entries))
+    for _, e := range inv.entries {
+        entries = append(entries, e)
+    }
+    sort.Slice(entries, func(i, j int) bool { return entryKey(entries[i]) < entryKey(entries[j]) })
+    q.Status.Inventory = entries
+    return nil
+}
+
+func (r *QraiopReconciler) pruneObject(ctx context.Context, q *qraiopv1.Qraiop, e qraiopv1.InventoryEntry) error {
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(schema.GroupVersionKind{Group: e.Group, Version: e.Version, Kind: e.Kind})
+    err := r.Get(ctx, client.ObjectKey{Namespace: e.Namespace, Name: e.Name}, obj)
+    if apierrors.IsNotFound(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if !metav1.IsControlledBy(obj, q) {
+        return nil
+    }
+    uid := obj.GetUID()
+    err = r.Delete(ctx, obj, client.Preconditions{UID: &uid}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+    return client.IgnoreNotFound(err)
+}
@@ -0,0 +1,217 @@
+// src/controllers/controllers/keyrotation.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    conditionKeyRotation = "KeyRotation"
+
+    // CryptoKeyAnnotation carries the current crypto key ID on the pod
+    // template of every crypto consumer, so a rotation rolls them
+    CryptoKeyAnnotation = "qraiop.io/crypto-key-id"
+
+    defaultKeyRotationInterval = 30 * 24 * time.Hour
+    defaultGraceOverlap        = 24 * time.Hour
+    keyRotationHistory         = 10
+    // consumerRolloutPoll is how often an overlap past its end checks again
+    // for consumers still rolling out
+    consumerRolloutPoll = time.Minute
+)
+
+// stampCryptoKey rolls a crypto consumer onto the current key. The crypto
+// services themselves hold both keys and are left alone.
+func stampCryptoKey(q *qraiopv1.Qraiop, component string, deployment *appsv1.Deployment) {
+    st := q.Status.KeyRotation
+    if st == nil || st.CurrentKeyID == "" || strings.HasPrefix(component, qraiopv1.ComponentCryptography) {
+        return
+    }
+    template := &deployment.Spec.Template
+    if template.Annotations == nil {
+        template.Annotations = make(map[string]string)
+    }
+    template.Annotations[CryptoKeyAnnotation] = st.CurrentKeyID
+    render.Explain(deployment, "spec.template.metadata.annotations["+CryptoKeyAnnotation+"]", st.CurrentKeyID, "status.keyRotation.currentKeyId")
+}
+
+// reconcileKeyRotation rotates the default crypto service's keys when they
+// are due and the window is open, then retires the previous key once the
+// grace overlap has passed and every consumer rolled out with the new one
+func (r *QraiopReconciler) reconcileKeyRotation(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.Cryptography.KeyRotation
+    if cfg == nil || !cfg.Enabled || !q.Spec.Cryptography.Enabled {
+        q.Status.KeyRotation = nil
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionKeyRotation)
+        return 0, nil
+    }
+    if q.Status.Components[qraiopv1.ComponentCryptography].Status != qraiopv1.ComponentReady {
+        return 0, nil
+    }
+    interval := defaultKeyRotationInterval
+    if cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    overlap := defaultGraceOverlap
+    if cfg.GraceOverlap.Duration > 0 {
+        overlap = cfg.GraceOverlap.Duration
+    }
+
+    now := time.Now()
+    st := q.Status.KeyRotation
+    if st == nil {
+        // The keys in use when rotation is turned on count as fresh
+        st = &qraiopv1.KeyRotationStatus{Phase: qraiopv1.KeyRotationIdle}
+        st.NextRotation = &metav1.Time{Time: now.Add(interval)}
+        q.Status.KeyRotation = st
+    }
+
+    if st.Phase == qraiopv1.KeyRotationOverlap {
+        if now.Before(st.OverlapEndsAt.Time) {
+            setCondition(q, conditionKeyRotation, metav1.ConditionTrue, "Overlap",
+                fmt.Sprintf("keys %s and %s valid until %s", st.CurrentKeyID, st.PreviousKeyID, st.OverlapEndsAt.UTC().Format(time.RFC3339)))
+            return st.OverlapEndsAt.Sub(now), nil
+        }
+        pending, err := r.consumersOffKey(ctx, q, st.CurrentKeyID)
+        if err != nil {
+            return 0, err
+        }
+        if len(pending) > 0 {
+            setCondition(q, conditionKeyRotation, metav1.ConditionFalse, "ConsumersRollingOut",
+                fmt.Sprintf("key %s stays valid until %s run with key %s", st.PreviousKeyID, strings.Join(pending, ", "), st.CurrentKeyID))
+            return consumerRolloutPoll, nil
+        }
+        opCtx, op, err := r.beginCryptoOperation(ctx, q, qraiopv1.CryptoOperationRetireKey, st.PreviousKeyID)
+        if err != nil {
+            return 0, err
+        }
+        if err := r.cryptoClient(q).RetireKey(opCtx, st.PreviousKeyID); err != nil {
+            failCryptoOperation(op, err)
+            setCondition(q, conditionKeyRotation, metav1.ConditionFalse, "RetireFailed", err.Error())
+            return consumerRolloutPoll, nil
+        }
+        r.Log.Info("retired previous crypto key", "qraiop", client.ObjectKeyFromObject(q), "key", st.PreviousKeyID)
+        if len(st.History) > 0 && st.History[0].KeyID == st.CurrentKeyID {
+            st.History[0].RetiredAt = &metav1.Time{Time: now}
+        }
+        st.Phase = qraiopv1.KeyRotationIdle
+        st.PreviousKeyID = ""
+        st.OverlapEndsAt = nil
+        if err := r.finishCryptoOperation(ctx, q, qraiopv1.CryptoOperationRetireKey); err != nil {
+            return 0, err
+        }
+        st = q.Status.KeyRotation
+    }
+
+    // An interrupted rotation is resumed whatever the schedule says, as the
+    // service may already have made its key current
+    resuming := pendingCryptoOperation(q, qraiopv1.CryptoOperationRotateKeys) != nil
+    due := st.NextRotation.Time
+    if due.After(now) && !resuming {
+        setCondition(q, conditionKeyRotation, metav1.ConditionTrue, "Scheduled",
+            "next rotation due at "+due.UTC().Format(time.RFC3339))
+        return due.Sub(now), nil
+    }
+    if cfg.Window != nil && !resuming {
+        start, end, ok, err := silenceWindow(qraiopv1.SilenceSchedule{
+            Days:      cfg.Window.Days,
+            StartTime: cfg.Window.StartTime,
+            Duration:  cfg.Window.Duration,
+            TimeZone:  cfg.Window.TimeZone,
+        }, now)
+        if err != nil || !ok {
+            msg := "rotation window never opens"
+            if err != nil {
+                msg = "invalid rotation window: " + err.Error()
+            }
+            setCondition(q, conditionKeyRotation, metav1.ConditionFalse, "InvalidWindow", msg)
+            return 0, nil
+        }
+        if start.After(now) || !end.After(now) {
+            setCondition(q, conditionKeyRotation, metav1.ConditionTrue, "WaitingForWindow",
+                "rotation due, waiting for the window opening at "+start.UTC().Format(time.RFC3339))
+            return start.Sub(now), nil
+        }
+    }
+
+    opCtx, op, err := r.beginCryptoOperation(ctx, q, qraiopv1.CryptoOperationRotateKeys, st.CurrentKeyID)
+    if err != nil {
+        return 0, err
+    }
+    st = q.Status.KeyRotation
+    rotation, err := r.cryptoClient(q).RotateKeys(opCtx, overlap)
+    if err != nil {
+        failCryptoOperation(op, err)
+        setCondition(q, conditionKeyRotation, metav1.ConditionFalse, "RotateFailed", err.Error())
+        return consumerRolloutPoll, nil
+    }
+    r.Log.Info("rotated crypto keys", "qraiop", client.ObjectKeyFromObject(q), "key", rotation.KeyID, "previous", rotation.PreviousKeyID)
+    // The overlap runs from the first attempt, when the service rotated
+    now = op.StartedAt.Time
+    rotated := metav1.Time{Time: now}
+    st.Phase = qraiopv1.KeyRotationOverlap
+    st.CurrentKeyID = rotation.KeyID
+    st.PreviousKeyID = rotation.PreviousKeyID
+    st.LastRotated = &rotated
+    st.OverlapEndsAt = &metav1.Time{Time: now.Add(overlap)}
+    st.NextRotation = &metav1.Time{Time: now.Add(interval)}
+    st.History = append([]qraiopv1.KeyRotationRecord{{
+        KeyID:         rotation.KeyID,
+        PreviousKeyID: rotation.PreviousKeyID,
+        RotatedAt:     rotated,
+    }}, st.History...)
+    if len(st.History) > keyRotationHistory {
+        st.History = st.History[:keyRotationHistory]
+    }
+    setCondition(q, conditionKeyRotation, metav1.ConditionTrue, "Overlap",
+        fmt.Sprintf("rotated to key %s; %s valid until %s", st.CurrentKeyID, st.PreviousKeyID, st.OverlapEndsAt.UTC().Format(time.RFC3339)))
+    if err := r.finishCryptoOperation(ctx, q, qraiopv1.CryptoOperationRotateKeys); err != nil {
+        return 0, err
+    }
+    // Consumers are stamped with the new key on the next pass
+    return time.Second, nil
+}
+
+// consumersOffKey lists the enabled crypto consumers not yet fully rolled
+// out with keyID
+func (r *QraiopReconciler) consumersOffKey(ctx context.Context, q *qraiopv1.Qraiop, keyID string) ([]string, error) {
+    var pending []string
+    for _, c := range r.components(q) {
+        if !c.enabled || strings.HasPrefix(c.name, qraiopv1.ComponentCryptography) {
+            continue
+        }
+        var d appsv1.Deployment
+        err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: c.deployment}, &d)
+        if apierrors.IsNotFound(err) {
+            pending = append(pending, c.name)
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        if d.Spec.Template.Annotations[CryptoKeyAnnotation] != keyID {
+            pending = append(pending, c.name)
+            continue
+        }
+        status, _, err := r.deploymentReadiness(ctx, q.Namespace, c.deployment)
+        if err != nil {
+            return nil, err
+        }
+        if status != qraiopv1.ComponentReady {
+            pending = append(pending, c.name)
+        }
+    }
+    return pending, nil
+}
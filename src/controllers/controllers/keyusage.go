@@ -0,0 +1,237 @@
+// src/controllers/controllers/keyusage.go
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionSecurityIncident = "SecurityIncident"
+
+    defaultKeyUsageInterval = time.Minute
+    defaultSpikeFactor      = 10
+    defaultMinRate          = 60
+    // baselineWarmup is how many samples a baseline needs before spikes count
+    baselineWarmup = 5
+    // baselineWeight is the weight of a new sample in the moving baseline
+    baselineWeight = 0.2
+)
+
+var (
+    keyOperations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_crypto_key_operations",
+        Help: "Operations performed with a key since it was loaded, as reported by the crypto service.",
+    }, []string{"instance", "key", "algorithm", "operation"})
+    keyOperationRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_crypto_key_operation_rate",
+        Help: "Operations per minute performed with a key over the last sample interval.",
+    }, []string{"instance", "key", "algorithm", "operation"})
+    keyUsageAnomalies = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "qraiop_crypto_key_usage_anomalies_total",
+        Help: "Key operation rate spikes raised as security incidents.",
+    }, []string{"instance", "key", "operation"})
+)
+
+func init() {
+    metrics.Registry.MustRegister(keyOperations, keyOperationRate, keyUsageAnomalies)
+}
+
+// usageSample is the last count and the baseline rate of one key operation
+type usageSample struct {
+    count    uint64
+    at       time.Time
+    baseline float64
+    samples  int
+}
+
+// keyUsageTracker remembers usage samples per instance between reconciles
+type keyUsageTracker struct {
+    mu      sync.Mutex
+    samples map[types.NamespacedName]map[string]*usageSample
+}
+
+func newKeyUsageTracker() *keyUsageTracker {
+    return &keyUsageTracker{samples: make(map[types.NamespacedName]map[string]*usageSample)}
+}
+
+func (t *keyUsageTracker) forget(key types.NamespacedName) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    delete(t.samples, key)
+}
+
+// reconcileKeyUsage samples the crypto service's per-key operation counts,
+// exports them and raises a security incident when an operation runs far
+// above its baseline rate, e.g. a sudden signing spike from a leaked key
+func (r *QraiopReconciler) reconcileKeyUsage(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.Cryptography.KeyUsage
+    if cfg == nil || !cfg.Enabled || q.Status.Components[qraiopv1.ComponentCryptography].Status != qraiopv1.ComponentReady {
+        return 0, nil
+    }
+    interval := defaultKeyUsageInterval
+    if cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    factor := float64(defaultSpikeFactor)
+    if cfg.SpikeFactor > 0 {
+        factor = float64(cfg.SpikeFactor)
+    }
+    minRate := float64(defaultMinRate)
+    if cfg.MinRate > 0 {
+        minRate = float64(cfg.MinRate)
+    }
+
+    keys, err := r.cryptoClient(q).KeyUsage(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("reading key usage: %w", err)
+    }
+
+    instance := types.NamespacedName{Namespace: q.Namespace, Name: q.Name}
+    r.keyUsage.mu.Lock()
+    samples := r.keyUsage.samples[instance]
+    if samples == nil {
+        samples = make(map[string]*usageSample)
+        r.keyUsage.samples[instance] = samples
+    }
+    now := time.Now()
+    var incidents []qraiopv1.SecurityIncident
+    for _, k := range keys {
+        for op, count := range k.Operations {
+            keyOperations.WithLabelValues(instance.String(), k.KeyID, k.Algorithm, op).Set(float64(count))
+
+            id := k.KeyID + "/" + op
+            s := samples[id]
+            if s == nil || count < s.count {
+                // First sample, or the service restarted and reset its counters
+                samples[id] = &usageSample{count: count, at: now}
+                continue
+            }
+            minutes := now.Sub(s.at).Minutes()
+            if minutes <= 0 {
+                continue
+            }
+            rate := float64(count-s.count) / minutes
+            keyOperationRate.WithLabelValues(instance.String(), k.KeyID, k.Algorithm, op).Set(rate)
+            s.count, s.at = count, now
+
+            if s.samples >= baselineWarmup && rate >= minRate && rate > s.baseline*factor {
+                incidents = append(incidents, qraiopv1.SecurityIncident{
+                    Type:              qraiopv1.IncidentKeyUsageAnomaly,
+                    KeyID:             k.KeyID,
+                    Operation:         op,
+                    RatePerMinute:     int64(rate),
+                    BaselinePerMinute: int64(s.baseline),
+                    Message: fmt.Sprintf("%s operations with key %s at %.0f/min, %.0fx the usual %.0f/min",
+                        op, k.KeyID, rate, rate/maxFloat(s.baseline, 1), s.baseline),
+                })
+                // Keep the spike out of the baseline
+                continue
+            }
+            if s.samples == 0 {
+                s.baseline = rate
+            } else {
+                s.baseline = baselineWeight*rate + (1-baselineWeight)*s.baseline
+            }
+            s.samples++
+        }
+    }
+    r.keyUsage.mu.Unlock()
+
+    r.recordIncidents(ctx, q, incidents)
+    return interval, nil
+}
+
+// recordIncidents replaces the open key usage incidents of q, keeping the
+// detection time of those still open, and hands new ones to the AI agents
+func (r *QraiopReconciler) recordIncidents(ctx context.Context, q *qraiopv1.Qraiop, incidents []qraiopv1.SecurityIncident) {
+    open := make(map[string]qraiopv1.SecurityIncident)
+    for _, inc := range q.Status.SecurityIncidents {
+        open[inc.KeyID+"/"+inc.Operation] = inc
+    }
+    var raised []qraiopv1.SecurityIncident
+    for i := range incidents {
+        inc := &incidents[i]
+        if cur, ok := open[inc.KeyID+"/"+inc.Operation]; ok {
+            inc.DetectedAt = cur.DetectedAt
+            continue
+        }
+        inc.DetectedAt = metav1.Now()
+        raised = append(raised, *inc)
+        keyUsageAnomalies.WithLabelValues(q.Namespace+"/"+q.Name, inc.KeyID, inc.Operation).Inc()
+    }
+    q.Status.SecurityIncidents = incidents
+
+    if len(incidents) == 0 {
+        if meta.FindStatusCondition(q.Status.Conditions, conditionSecurityIncident) != nil {
+            setCondition(q, conditionSecurityIncident, metav1.ConditionFalse, "NoIncidents", "key usage is within its baseline")
+        }
+        return
+    }
+    messages := make([]string, 0, len(incidents))
+    for _, inc := range incidents {
+        messages = append(messages, inc.Message)
+    }
+    setCondition(q, conditionSecurityIncident, metav1.ConditionTrue, qraiopv1.IncidentKeyUsageAnomaly, strings.Join(messages, "; "))
+
+    if len(raised) > 0 && q.Spec.AIOrchestration.Enabled {
+        if err := r.notifySecurityAgent(ctx, q, raised); err != nil {
+            r.Log.Error(err, "unable to hand security incidents to the AI agents", "qraiop", q.Namespace+"/"+q.Name)
+        }
+    }
+}
+
+// notifySecurityAgent posts new incidents to the AI orchestration service,
+// which routes them to the security agent for triage
+func (r *QraiopReconciler) notifySecurityAgent(ctx context.Context, q *qraiopv1.Qraiop, incidents []qraiopv1.SecurityIncident) error {
+    body, err := json.Marshal(map[string]interface{}{
+        "instance":  q.Name,
+        "namespace": q.Namespace,
+        "incidents": incidents,
+    })
+    if err != nil {
+        return err
+    }
+    httpClient, err := newHTTPClient(ctx, r, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return err
+    }
+    ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+    defer cancel()
+    url := "http://qraiop-ai." + q.Namespace + ".svc:8080/v1/security/incidents"
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := httpClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("AI orchestration returned %s", resp.Status)
+    }
+    return nil
+}
+
+func maxFloat(a, b float64) float64 {
+    if a > b {
+        return a
+    }
+    return b
+}
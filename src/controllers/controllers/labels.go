@@ -0,0 +1,40 @@
+// src/controllers/controllers/labels.go
+package controllers
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// labelsForQraiop returns the labels set on every object the controller creates for q
+func labelsForQraiop(q *qraiopv1.Qraiop) map[string]string {
+    return map[string]string{
+        "app.kubernetes.io/managed-by": "qraiop-controller",
+        "app.kubernetes.io/part-of":    "qraiop",
+        "app.kubernetes.io/instance":   q.Name,
+    }
+}
+
+// applyCommonMetadata adds the instance's CommonLabels and CommonAnnotations
+// to obj. Labels and annotations the controller sets itself take precedence.
+func applyCommonMetadata(q *qraiopv1.Qraiop, obj metav1.Object) {
+    obj.SetLabels(mergeMissing(obj.GetLabels(), q.Spec.CommonLabels))
+    obj.SetAnnotations(mergeMissing(obj.GetAnnotations(), q.Spec.CommonAnnotations))
+}
+
+// mergeMissing adds the entries of extra that dst doesn't have
+func mergeMissing(dst, extra map[string]string) map[string]string {
+    if len(extra) == 0 {
+        return dst
+    }
+    if dst == nil {
+        dst = make(map[string]string, len(extra))
+    }
+    for k, v := range extra {
+        if _, ok := dst[k]; !ok {
+            dst[k] = v
+        }
+    }
+    return dst
+}
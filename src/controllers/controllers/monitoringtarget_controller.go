@@ -0,0 +1,324 @@
+// src/controllers/controllers/monitoringtarget_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    "github.com/go-logr/logr"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    // workloadDashboardConfigMap holds the dashboard of an instance's
+    // MonitoringTargets, in the instance's namespace
+    workloadDashboardConfigMap = "qraiop-workloads-dashboard"
+    // grafanaDashboardLabel is the label Grafana's dashboard sidecar loads
+    // ConfigMaps by
+    grafanaDashboardLabel = "grafana_dashboard"
+)
+
+// MonitoringTargetReconciler generates the ServiceMonitor and alert rules
+// of each MonitoringTarget in its namespace, and the workload dashboard of
+// each instance with a row per target
+type MonitoringTargetReconciler struct {
+    client.Client
+    Scheme *runtime.Scheme
+    Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=monitoringtargets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=monitoringtargets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+func (r *MonitoringTargetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    var target qraiopv1.MonitoringTarget
+    err := r.Get(ctx, req.NamespacedName, &target)
+    if apierrors.IsNotFound(err) {
+        // The deleted target's instance is unknown; its objects go with it
+        // through the owner references, its dashboard row by resyncing all
+        return ctrl.Result{}, r.syncAllDashboards(ctx)
+    }
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+
+    q, monitored, err := r.instance(ctx, &target)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    if monitored {
+        err = r.apply(ctx, &target, q)
+    } else {
+        err = r.remove(ctx, &target)
+    }
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    if q != nil {
+        if err := r.syncDashboard(ctx, q); err != nil {
+            return ctrl.Result{}, err
+        }
+    }
+
+    var result ctrl.Result
+    target.Status.SLOs = nil
+    if monitored && len(target.Spec.SLOs) > 0 {
+        prom, err := instancePrometheus(ctx, r, q)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        selectors := make([]string, len(target.Spec.SLOs))
+        for i := range selectors {
+            selectors[i] = render.WorkloadSelector(&target)
+        }
+        target.Status.SLOs = evaluateSLOs(ctx, prom, target.Spec.SLOs, selectors, target.Namespace, "monitoringtarget/"+target.Name)
+        if prom != nil {
+            result.RequeueAfter = sloEvaluationInterval
+        }
+    }
+    target.Status.ObservedGeneration = target.Generation
+    return result, r.Status().Update(ctx, &target)
+}
+
+// instance returns the Qraiop t references, if it exists, and whether it
+// monitors t. When it doesn't, the Ready condition says why.
+func (r *MonitoringTargetReconciler) instance(ctx context.Context, t *qraiopv1.MonitoringTarget) (*qraiopv1.Qraiop, bool, error) {
+    var q qraiopv1.Qraiop
+    err := r.Get(ctx, instanceKey(t), &q)
+    if apierrors.IsNotFound(err) {
+        setTargetCondition(t, metav1.ConditionFalse, "InstanceNotFound", "Qraiop "+instanceKey(t).String()+" not found")
+        return nil, false, nil
+    }
+    if err != nil {
+        return nil, false, err
+    }
+    if !q.Spec.Monitoring.Enabled {
+        setTargetCondition(t, metav1.ConditionFalse, "MonitoringDisabled", "monitoring of Qraiop "+instanceKey(t).String()+" is not enabled")
+        return &q, false, nil
+    }
+    return &q, true, nil
+}
+
+// apply writes the ServiceMonitor and PrometheusRule of t, labelled like
+// the instance's ServiceMonitors so its Prometheus picks them up. That
+// Prometheus must select ServiceMonitors and rules in t's namespace.
+func (r *MonitoringTargetReconciler) apply(ctx context.Context, t *qraiopv1.MonitoringTarget, q *qraiopv1.Qraiop) error {
+    for _, gvk := range []schema.GroupVersionKind{render.ServiceMonitorGVK, render.PrometheusRuleGVK} {
+        if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+            setTargetCondition(t, metav1.ConditionFalse, "CRDMissing",
+                gvk.Kind+" is not served by the cluster; install the Prometheus Operator CRDs")
+            return nil
+        }
+    }
+    selector, err := metav1.LabelSelectorAsSelector(&t.Spec.Selector)
+    if err != nil {
+        setTargetCondition(t, metav1.ConditionFalse, "Invalid", "selector: "+err.Error())
+        return nil
+    }
+    var services corev1.ServiceList
+    if err := r.List(ctx, &services, client.InNamespace(t.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+        return err
+    }
+    t.Status.Services = nil
+    for _, svc := range services.Items {
+        t.Status.Services = append(t.Status.Services, svc.Name)
+    }
+    sort.Strings(t.Status.Services)
+
+    cfg := q.Spec.Monitoring.Prometheus
+    if cfg == nil {
+        cfg = &qraiopv1.PrometheusConfig{}
+    }
+    sm, err := render.WorkloadServiceMonitor(t, q.Name, cfg)
+    if err != nil {
+        setTargetCondition(t, metav1.ConditionFalse, "Invalid", err.Error())
+        return nil
+    }
+    rules, err := render.WorkloadRules(t, cfg)
+    if err != nil {
+        setTargetCondition(t, metav1.ConditionFalse, "Invalid", err.Error())
+        return nil
+    }
+    for _, obj := range []*unstructured.Unstructured{sm, rules} {
+        if err := r.write(ctx, t, obj); err != nil {
+            return fmt.Errorf("%s %s: %w", obj.GetKind(), obj.GetName(), err)
+        }
+    }
+
+    if len(t.Status.Services) == 0 {
+        setTargetCondition(t, metav1.ConditionFalse, "NoServices", "the selector matches no Service in "+t.Namespace)
+        return nil
+    }
+    setTargetCondition(t, metav1.ConditionTrue, "Monitored",
+        fmt.Sprintf("%d Service(s) scraped and alerted on by Qraiop %s", len(t.Status.Services), instanceKey(t)))
+    return nil
+}
+
+// write creates or updates an object generated for t, owned by it
+func (r *MonitoringTargetReconciler) write(ctx context.Context, t *qraiopv1.MonitoringTarget, obj *unstructured.Unstructured) error {
+    if err := ctrl.SetControllerReference(t, obj, r.Scheme); err != nil {
+        return err
+    }
+    existing := &unstructured.Unstructured{}
+    existing.SetGroupVersionKind(obj.GroupVersionKind())
+    err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, obj)
+    }
+    if err != nil {
+        return err
+    }
+    obj.SetResourceVersion(existing.GetResourceVersion())
+    return r.Update(ctx, obj)
+}
+
+// remove deletes the ServiceMonitor and PrometheusRule of a target no
+// instance monitors
+func (r *MonitoringTargetReconciler) remove(ctx context.Context, t *qraiopv1.MonitoringTarget) error {
+    t.Status.Services = nil
+    for _, gvk := range []schema.GroupVersionKind{render.ServiceMonitorGVK, render.PrometheusRuleGVK} {
+        obj := &unstructured.Unstructured{}
+        obj.SetGroupVersionKind(gvk)
+        obj.SetNamespace(t.Namespace)
+        obj.SetName(render.WorkloadObjectName(t))
+        if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+            return err
+        }
+    }
+    return nil
+}
+
+// syncDashboard writes the workload dashboard of q with a row per target
+// referencing it, or removes it once there are none
+func (r *MonitoringTargetReconciler) syncDashboard(ctx context.Context, q *qraiopv1.Qraiop) error {
+    var all qraiopv1.MonitoringTargetList
+    if err := r.List(ctx, &all); err != nil {
+        return err
+    }
+    var targets []qraiopv1.MonitoringTarget
+    for _, t := range all.Items {
+        if t.DeletionTimestamp == nil && instanceKey(&t) == client.ObjectKeyFromObject(q) {
+            targets = append(targets, t)
+        }
+    }
+    cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: workloadDashboardConfigMap, Namespace: q.Namespace}}
+    if len(targets) == 0 || !q.Spec.Monitoring.Enabled {
+        return client.IgnoreNotFound(r.Delete(ctx, cm))
+    }
+    sort.Slice(targets, func(i, j int) bool {
+        return targets[i].Namespace+"/"+targets[i].Name < targets[j].Namespace+"/"+targets[j].Name
+    })
+    dashboard, err := render.WorkloadDashboard(q.Name, targets)
+    if err != nil {
+        return err
+    }
+    cm.Labels = map[string]string{
+        grafanaDashboardLabel:          "1",
+        "app.kubernetes.io/managed-by": "qraiop-controller",
+        "app.kubernetes.io/part-of":    "qraiop",
+    }
+    cm.Data = map[string]string{"qraiop-workloads.json": string(dashboard)}
+    if err := ctrl.SetControllerReference(q, cm, r.Scheme); err != nil {
+        return err
+    }
+
+    var existing corev1.ConfigMap
+    err = r.Get(ctx, client.ObjectKeyFromObject(cm), &existing)
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, cm)
+    }
+    if err != nil {
+        return err
+    }
+    cm.ResourceVersion = existing.ResourceVersion
+    return r.Update(ctx, cm)
+}
+
+// syncAllDashboards resyncs the workload dashboard of every instance
+func (r *MonitoringTargetReconciler) syncAllDashboards(ctx context.Context) error {
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances); err != nil {
+        return err
+    }
+    for i := range instances.Items {
+        if err := r.syncDashboard(ctx, &instances.Items[i]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// instanceKey is the Qraiop a target references
+func instanceKey(t *qraiopv1.MonitoringTarget) client.ObjectKey {
+    ns := t.Spec.Instance.Namespace
+    if ns == "" {
+        ns = t.Namespace
+    }
+    return client.ObjectKey{Namespace: ns, Name: t.Spec.Instance.Name}
+}
+
+func setTargetCondition(t *qraiopv1.MonitoringTarget, status metav1.ConditionStatus, reason, message string) {
+    meta.SetStatusCondition(&t.Status.Conditions, metav1.Condition{
+        Type:               conditionReady,
+        Status:             status,
+        Reason:             reason,
+        Message:            message,
+        ObservedGeneration: t.Generation,
+    })
+}
+
+// targetsForInstance maps a Qraiop to the targets it monitors, so they
+// follow changes of its monitoring
+func (r *MonitoringTargetReconciler) targetsForInstance(ctx context.Context, obj client.Object) []reconcile.Request {
+    var targets qraiopv1.MonitoringTargetList
+    if err := r.List(ctx, &targets); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for _, t := range targets.Items {
+        if instanceKey(&t) == client.ObjectKeyFromObject(obj) {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&t)})
+        }
+    }
+    return requests
+}
+
+// targetsForService maps a Service to the targets in its namespace, so
+// the matched Services in their status follow
+func (r *MonitoringTargetReconciler) targetsForService(ctx context.Context, obj client.Object) []reconcile.Request {
+    var targets qraiopv1.MonitoringTargetList
+    if err := r.List(ctx, &targets, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    requests := make([]reconcile.Request, 0, len(targets.Items))
+    for _, t := range targets.Items {
+        requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&t)})
+    }
+    return requests
+}
+
+func (r *MonitoringTargetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.MonitoringTarget{}).
+        Watches(&qraiopv1.Qraiop{}, handler.EnqueueRequestsFromMapFunc(r.targetsForInstance)).
+        Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.targetsForService)).
+        Complete(r)
+}
@@ -0,0 +1,430 @@
+// src/controllers/controllers/networkpolicy.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "net/url"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/intstr"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/yaml"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/netlearn"
+)
+
+const (
+    // ApprovedAnnotation is set to "true" on a learned NetworkPolicy draft to apply it
+    ApprovedAnnotation = "qraiop.io/approved"
+
+    networkPolicyDraftKey   = "policies.yaml"
+    defaultLearningDuration = 24 * time.Hour
+)
+
+// reconcileNetworkPolicies applies the instance's NetworkPolicies. When
+// learning mode is enabled DefaultDenyAll is held back until the learned
+// allow policies have been reviewed and approved, and a staged rollout
+// enforces it namespace by namespace. The returned duration, if non-zero, is
+// when learning or the rollout next needs attention.
+func (r *QraiopReconciler) reconcileNetworkPolicies(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.SecurityPolicies.NetworkPolicies
+
+    enforce := cfg.DefaultDenyAll
+    var requeueAfter time.Duration
+    if cfg.Learning != nil && cfg.Learning.Enabled {
+        approved, after, err := r.reconcileNetworkPolicyLearning(ctx, q)
+        if err != nil {
+            return 0, err
+        }
+        enforce = enforce && approved
+        requeueAfter = after
+    }
+
+    restrictEgress := cfg.DefaultDenyAll
+    if enforce && cfg.Rollout != nil {
+        ownEnforced, after, err := r.reconcileNetworkPolicyRollout(ctx, q)
+        if err != nil {
+            return 0, err
+        }
+        if after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+            requeueAfter = after
+        }
+        enforce = ownEnforced
+        // Egress of the components is only restricted once their own
+        // namespace is enforced
+        restrictEgress = ownEnforced
+    } else {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionNetworkPolicyRollout)
+        q.Status.NetworkPolicyRollout = nil
+        if enforce {
+            if err := r.createOrUpdateNetworkPolicy(ctx, q, defaultDenyPolicy(q, q.Namespace)); err != nil {
+                return 0, fmt.Errorf("default-deny policy: %w", err)
+            }
+        }
+    }
+
+    if cfg.AllowQraiopCommunication {
+        if err := r.createOrUpdateNetworkPolicy(ctx, q, allowQraiopPolicy(q, restrictEgress)); err != nil {
+            return 0, fmt.Errorf("allow-qraiop policy: %w", err)
+        }
+    }
+
+    if enforce {
+        if q.Spec.Proxy != nil {
+            policy, err := proxyEgressPolicy(q)
+            if err != nil {
+                return 0, fmt.Errorf("proxy egress policy: %w", err)
+            }
+            if err := r.createOrUpdateNetworkPolicy(ctx, q, policy); err != nil {
+                return 0, fmt.Errorf("proxy egress policy: %w", err)
+            }
+        }
+    }
+    return requeueAfter, nil
+}
+
+// reconcileNetworkPolicyLearning advances learning mode and reports whether
+// the learned policies have been approved and applied
+func (r *QraiopReconciler) reconcileNetworkPolicyLearning(ctx context.Context, q *qraiopv1.Qraiop) (bool, time.Duration, error) {
+    learning := q.Spec.SecurityPolicies.NetworkPolicies.Learning
+    source := learning.Source
+    if source == "" {
+        source = "conntrack"
+    }
+    duration := learning.Duration.Duration
+    if duration == 0 {
+        duration = defaultLearningDuration
+    }
+
+    st := q.Status.NetworkPolicyLearning
+    if st == nil {
+        st = &qraiopv1.LearningStatus{Phase: qraiopv1.LearningPhaseObserving, StartTime: metav1.Now()}
+        q.Status.NetworkPolicyLearning = st
+    }
+
+    switch st.Phase {
+    case qraiopv1.LearningPhaseObserving:
+        end := st.StartTime.Add(duration)
+        if remaining := time.Until(end); remaining > 0 {
+            setCondition(q, conditionNetworkPolicyLearning, metav1.ConditionFalse, qraiopv1.LearningPhaseObserving,
+                fmt.Sprintf("observing %s flows until %s", source, end.UTC().Format(time.RFC3339)))
+            return false, remaining, nil
+        }
+
+        observer := &netlearn.ConfigMapObserver{Client: r.Client, Source: source}
+        flows, err := observer.Flows(ctx, q.Namespace, st.StartTime.Time)
+        if err != nil {
+            return false, 0, fmt.Errorf("reading observed flows: %w", err)
+        }
+        pods, err := r.flowPods(ctx, q.Namespace, flows)
+        if err != nil {
+            return false, 0, fmt.Errorf("listing pods: %w", err)
+        }
+        policies := netlearn.Propose(q.Namespace, flows, pods)
+
+        draft, err := r.writeNetworkPolicyDraft(ctx, q, policies)
+        if err != nil {
+            return false, 0, fmt.Errorf("writing network policy draft: %w", err)
+        }
+        now := metav1.Now()
+        st.Phase = qraiopv1.LearningPhaseProposed
+        st.CompletedTime = &now
+        st.ObservedFlows = len(flows)
+        st.DraftName = draft
+        setCondition(q, conditionNetworkPolicyLearning, metav1.ConditionFalse, qraiopv1.LearningPhaseProposed,
+            fmt.Sprintf("%d policies proposed from %d flows; annotate ConfigMap %s with %s=true to apply",
+                len(policies), len(flows), draft, ApprovedAnnotation))
+        return false, 0, nil
+
+    case qraiopv1.LearningPhaseProposed, qraiopv1.LearningPhaseApproved:
+        var draft corev1.ConfigMap
+        err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: st.DraftName}, &draft)
+        if apierrors.IsNotFound(err) {
+            // The draft was discarded, observe again
+            q.Status.NetworkPolicyLearning = nil
+            return false, time.Second, nil
+        }
+        if err != nil {
+            return false, 0, err
+        }
+        // The draft, and once approved the policies applied from it, are
+        // rendered by every reconcile, or the inventory would prune them
+        if err := r.recordInventory(ctx, &draft); err != nil {
+            return false, 0, err
+        }
+        approved := st.Phase == qraiopv1.LearningPhaseApproved
+        if !approved && draft.Annotations[ApprovedAnnotation] != "true" {
+            return false, 0, nil
+        }
+
+        policies, err := parseNetworkPolicyDraft(draft.Data[networkPolicyDraftKey])
+        if err != nil {
+            setCondition(q, conditionNetworkPolicyLearning, metav1.ConditionFalse, "InvalidDraft", err.Error())
+            if approved {
+                // Keep the policies of the draft as it was approved
+                markInventoryPartial(ctx)
+            }
+            return approved, 0, nil
+        }
+        for i := range policies {
+            policies[i].Namespace = q.Namespace
+            if policies[i].Labels == nil {
+                policies[i].Labels = map[string]string{}
+            }
+            for k, v := range labelsForQraiop(q) {
+                policies[i].Labels[k] = v
+            }
+            if err := r.createOrUpdateNetworkPolicy(ctx, q, &policies[i]); err != nil {
+                return false, 0, fmt.Errorf("applying learned policy %s: %w", policies[i].Name, err)
+            }
+        }
+        st.Phase = qraiopv1.LearningPhaseApproved
+        setCondition(q, conditionNetworkPolicyLearning, metav1.ConditionTrue, qraiopv1.LearningPhaseApproved,
+            fmt.Sprintf("%d learned policies applied", len(policies)))
+        return true, 0, nil
+    }
+    return false, 0, nil
+}
+
+// flowPods returns the pods of namespace and, looked up by IP, those of
+// other namespaces the flows were observed with. Peers the reconciler may
+// not read, such as under tenant impersonation, stay unresolved and are
+// proposed as IP blocks.
+func (r *QraiopReconciler) flowPods(ctx context.Context, namespace string, flows []netlearn.Flow) ([]corev1.Pod, error) {
+    var local corev1.PodList
+    if err := r.List(ctx, &local, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    pods := local.Items
+    seen := make(map[string]bool, len(pods))
+    for _, p := range pods {
+        seen[p.Status.PodIP] = true
+    }
+    for _, f := range flows {
+        for _, ip := range []string{f.SourceIP, f.DestinationIP} {
+            if ip == "" || seen[ip] {
+                continue
+            }
+            seen[ip] = true
+            var peers corev1.PodList
+            err := r.List(ctx, &peers, client.MatchingFields{podIPField: ip})
+            if apierrors.IsForbidden(err) {
+                continue
+            }
+            if err != nil {
+                return nil, err
+            }
+            pods = append(pods, peers.Items...)
+        }
+    }
+    return pods, nil
+}
+
+// podIPField indexes pods by IP in the cache. The API server supports the
+// same field selector, so uncached clients look peers up the same way.
+const podIPField = "status.podIP"
+
+func indexPodIP(obj client.Object) []string {
+    if ip := obj.(*corev1.Pod).Status.PodIP; ip != "" {
+        return []string{ip}
+    }
+    return nil
+}
+
+// writeNetworkPolicyDraft stores proposed policies in a ConfigMap for review
+func (r *QraiopReconciler) writeNetworkPolicyDraft(ctx context.Context, q *qraiopv1.Qraiop, policies []networkingv1.NetworkPolicy) (string, error) {
+    docs := make([]string, 0, len(policies))
+    for i := range policies {
+        out, err := yaml.Marshal(&policies[i])
+        if err != nil {
+            return "", err
+        }
+        docs = append(docs, string(out))
+    }
+
+    draft := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      q.Name + "-networkpolicy-draft",
+            Namespace: q.Namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Data: map[string]string{networkPolicyDraftKey: strings.Join(docs, "---\n")},
+    }
+    draft.Labels["qraiop.io/draft"] = "true"
+    applyCommonMetadata(q, draft)
+    applyArgoCDMetadata(q, draft, -1)
+    if err := ctrl.SetControllerReference(q, draft, r.Scheme); err != nil {
+        return "", err
+    }
+    if err := r.recordInventory(ctx, draft); err != nil {
+        return "", err
+    }
+
+    var existing corev1.ConfigMap
+    err := r.Get(ctx, client.ObjectKeyFromObject(draft), &existing)
+    if apierrors.IsNotFound(err) {
+        return draft.Name, r.Create(ctx, draft)
+    }
+    if err != nil {
+        return "", err
+    }
+    draft.ResourceVersion = existing.ResourceVersion
+    return draft.Name, r.Update(ctx, draft)
+}
+
+// parseNetworkPolicyDraft reads back a reviewed draft, including any edits
+func parseNetworkPolicyDraft(data string) ([]networkingv1.NetworkPolicy, error) {
+    var policies []networkingv1.NetworkPolicy
+    for _, doc := range strings.Split(data, "---\n") {
+        if strings.TrimSpace(doc) == "" {
+            continue
+        }
+        var policy networkingv1.NetworkPolicy
+        if err := yaml.UnmarshalStrict([]byte(doc), &policy); err != nil {
+            return nil, fmt.Errorf("invalid policy in draft: %w", err)
+        }
+        if policy.Name == "" {
+            return nil, fmt.Errorf("policy in draft has no name")
+        }
+        policies = append(policies, policy)
+    }
+    return policies, nil
+}
+
+// defaultDenyPolicy denies all ingress and egress for every pod in namespace
+func defaultDenyPolicy(q *qraiopv1.Qraiop, namespace string) *networkingv1.NetworkPolicy {
+    return &networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      "default-deny-all",
+            Namespace: namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: metav1.LabelSelector{},
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+        },
+    }
+}
+
+// allowQraiopPolicy lets QRAIOP components reach each other. Egress is only
+// restricted when default-deny is on, otherwise it would cut components off
+// from everything else.
+func allowQraiopPolicy(q *qraiopv1.Qraiop, restrictEgress bool) *networkingv1.NetworkPolicy {
+    selector := metav1.LabelSelector{MatchLabels: map[string]string{
+        "app.kubernetes.io/part-of":  "qraiop",
+        "app.kubernetes.io/instance": q.Name,
+    }}
+    policy := &networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      "allow-qraiop-communication",
+            Namespace: q.Namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: selector,
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+            Ingress: []networkingv1.NetworkPolicyIngressRule{{
+                From: []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}},
+            }},
+        },
+    }
+    if restrictEgress {
+        policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+        policy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{
+            To: []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}},
+        }}
+    }
+    return policy
+}
+
+// proxyEgressPolicy lets QRAIOP components reach the configured proxies
+// when default-deny would otherwise block all egress
+func proxyEgressPolicy(q *qraiopv1.Qraiop) (*networkingv1.NetworkPolicy, error) {
+    p := q.Spec.Proxy
+    var ports []networkingv1.NetworkPolicyPort
+    var peers []networkingv1.NetworkPolicyPeer
+    anyHost := false
+    seenPort := map[string]bool{}
+    for _, raw := range []string{p.HTTPProxy, p.HTTPSProxy} {
+        if raw == "" {
+            continue
+        }
+        u, err := url.Parse(raw)
+        if err != nil || u.Hostname() == "" {
+            return nil, fmt.Errorf("invalid proxy URL %q", raw)
+        }
+        port := u.Port()
+        if port == "" {
+            port = "80"
+            if u.Scheme == "https" {
+                port = "443"
+            }
+        }
+        if !seenPort[port] {
+            seenPort[port] = true
+            tcp := corev1.ProtocolTCP
+            target := intstr.Parse(port)
+            ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &target})
+        }
+        if ip := net.ParseIP(u.Hostname()); ip != nil {
+            cidr := ip.String() + "/32"
+            if ip.To4() == nil {
+                cidr = ip.String() + "/128"
+            }
+            peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+        } else {
+            anyHost = true
+        }
+    }
+
+    if len(p.EgressCIDRs) > 0 {
+        peers = peers[:0]
+        for _, cidr := range p.EgressCIDRs {
+            peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+        }
+    } else if anyHost {
+        // A hostname can't be matched by NetworkPolicy, so only the port is restricted
+        peers = nil
+    }
+
+    return &networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      "allow-proxy-egress",
+            Namespace: q.Namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{
+                "app.kubernetes.io/part-of":  "qraiop",
+                "app.kubernetes.io/instance": q.Name,
+            }},
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+            Egress:      []networkingv1.NetworkPolicyEgressRule{{To: peers, Ports: ports}},
+        },
+    }, nil
+}
+
+// createOrUpdateNetworkPolicy writes a policy of the instance. Policies in
+// other namespaces, from a staged rollout, can't be owned by it and are
+// only removed through the inventory.
+func (r *QraiopReconciler) createOrUpdateNetworkPolicy(ctx context.Context, q *qraiopv1.Qraiop, policy *networkingv1.NetworkPolicy) error {
+    applyCommonMetadata(q, policy)
+    applyArgoCDMetadata(q, policy, 0)
+    if policy.Namespace == q.Namespace {
+        if err := ctrl.SetControllerReference(q, policy, r.Scheme); err != nil {
+            return err
+        }
+    }
+
+    return r.serverSideApply(ctx, q, policy, &networkingv1.NetworkPolicy{})
+}
@@ -0,0 +1,171 @@
+// src/controllers/controllers/networkpolicy_rollout.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/netlearn"
+)
+
+const (
+    conditionNetworkPolicyRollout = "NetworkPolicyRollout"
+
+    defaultRolloutAuditDuration = 24 * time.Hour
+    defaultRolloutInterval      = 24 * time.Hour
+    // deniedFlowsReported caps the flows named in a held namespace's message
+    deniedFlowsReported = 5
+)
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// reconcileNetworkPolicyRollout advances the staged rollout of
+// DefaultDenyAll. Namespaces are audited and enforced one after another;
+// enforced namespaces keep their policy. It reports whether the instance
+// namespace is enforced and when the rollout next needs attention.
+func (r *QraiopReconciler) reconcileNetworkPolicyRollout(ctx context.Context, q *qraiopv1.Qraiop) (bool, time.Duration, error) {
+    cfg := q.Spec.SecurityPolicies.NetworkPolicies.Rollout
+    namespaces := cfg.Namespaces
+    if len(namespaces) == 0 {
+        namespaces = []string{q.Namespace}
+    }
+    auditDuration := cfg.AuditDuration.Duration
+    if auditDuration == 0 {
+        auditDuration = defaultRolloutAuditDuration
+    }
+    interval := cfg.Interval.Duration
+    if interval == 0 {
+        interval = defaultRolloutInterval
+    }
+
+    previous := make(map[string]qraiopv1.NamespaceRollout)
+    for _, st := range q.Status.NetworkPolicyRollout {
+        previous[st.Namespace] = st
+    }
+
+    var requeueAfter time.Duration
+    var lastEnforced *metav1.Time
+    blocked := false
+    enforced := 0
+    rollout := make([]qraiopv1.NamespaceRollout, 0, len(namespaces))
+    for _, ns := range namespaces {
+        st, ok := previous[ns]
+        if !ok {
+            st = qraiopv1.NamespaceRollout{Namespace: ns, Phase: qraiopv1.RolloutPending}
+        }
+        // Only one namespace is in flight; the ones after it wait
+        if blocked && st.Phase != qraiopv1.RolloutEnforced {
+            st.Phase, st.AuditStart, st.Message = qraiopv1.RolloutPending, nil, ""
+            rollout = append(rollout, st)
+            continue
+        }
+
+        switch st.Phase {
+        case qraiopv1.RolloutPending:
+            if lastEnforced != nil {
+                if wait := time.Until(lastEnforced.Add(interval)); wait > 0 {
+                    st.Message = "audit starts " + lastEnforced.Add(interval).UTC().Format(time.RFC3339)
+                    requeueAfter, blocked = wait, true
+                    break
+                }
+            }
+            now := metav1.Now()
+            st.Phase, st.AuditStart, st.Message = qraiopv1.RolloutAuditing, &now, ""
+            requeueAfter, blocked = auditDuration, true
+
+        case qraiopv1.RolloutAuditing, qraiopv1.RolloutHeld:
+            end := st.AuditStart.Add(auditDuration)
+            if wait := time.Until(end); wait > 0 {
+                requeueAfter, blocked = wait, true
+                break
+            }
+            denied, err := r.auditDefaultDeny(ctx, q, ns, st.AuditStart.Time)
+            if err != nil {
+                return false, 0, fmt.Errorf("auditing namespace %s: %w", ns, err)
+            }
+            st.DeniedFlows = len(denied)
+            if len(denied) > cfg.MaxDeniedFlows {
+                now := metav1.Now()
+                st.Phase, st.AuditStart = qraiopv1.RolloutHeld, &now
+                st.Message = heldMessage(denied)
+                requeueAfter, blocked = auditDuration, true
+                break
+            }
+            now := metav1.Now()
+            st.Phase, st.EnforcedAt, st.Message = qraiopv1.RolloutEnforced, &now, ""
+            fallthrough
+
+        case qraiopv1.RolloutEnforced:
+            if err := r.createOrUpdateNetworkPolicy(ctx, q, defaultDenyPolicy(q, ns)); err != nil {
+                return false, 0, fmt.Errorf("default-deny policy in %s: %w", ns, err)
+            }
+            enforced++
+            lastEnforced = st.EnforcedAt
+        }
+        rollout = append(rollout, st)
+    }
+    q.Status.NetworkPolicyRollout = rollout
+
+    status, reason := metav1.ConditionFalse, "Progressing"
+    if enforced == len(rollout) {
+        status, reason = metav1.ConditionTrue, "Enforced"
+    }
+    setCondition(q, conditionNetworkPolicyRollout, status, reason,
+        fmt.Sprintf("default-deny enforced in %d of %d namespaces", enforced, len(rollout)))
+
+    ownEnforced := false
+    for _, st := range rollout {
+        ownEnforced = ownEnforced || (st.Namespace == q.Namespace && st.Phase == qraiopv1.RolloutEnforced)
+    }
+    return ownEnforced, requeueAfter, nil
+}
+
+// auditDefaultDeny returns the flows seen in namespace since the audit
+// started that a default-deny policy would have blocked
+func (r *QraiopReconciler) auditDefaultDeny(ctx context.Context, q *qraiopv1.Qraiop, namespace string, since time.Time) ([]netlearn.Flow, error) {
+    source := q.Spec.SecurityPolicies.NetworkPolicies.Rollout.Source
+    if source == "" {
+        source = "conntrack"
+    }
+    observer := &netlearn.ConfigMapObserver{Client: r.Client, Source: source}
+    flows, err := observer.Flows(ctx, namespace, since)
+    if err != nil {
+        return nil, err
+    }
+    var pods corev1.PodList
+    if err := r.List(ctx, &pods); err != nil {
+        return nil, err
+    }
+    var policies networkingv1.NetworkPolicyList
+    if err := r.List(ctx, &policies, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    var nsList corev1.NamespaceList
+    if err := r.List(ctx, &nsList); err != nil {
+        return nil, err
+    }
+    nsLabels := make(map[string]map[string]string, len(nsList.Items))
+    for _, ns := range nsList.Items {
+        nsLabels[ns.Name] = ns.Labels
+    }
+    return netlearn.Denied(namespace, flows, pods.Items, policies.Items, nsLabels), nil
+}
+
+func heldMessage(denied []netlearn.Flow) string {
+    shown := make([]string, 0, deniedFlowsReported)
+    for i, f := range denied {
+        if i == deniedFlowsReported {
+            break
+        }
+        shown = append(shown, fmt.Sprintf("%s -> %s:%d", f.SourceIP, f.DestinationIP, f.Port))
+    }
+    return fmt.Sprintf("%d flows would be denied, e.g. %s; add allow policies, audit restarted", len(denied), strings.Join(shown, ", "))
+}
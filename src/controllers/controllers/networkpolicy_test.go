@@ -0,0 +1,108 @@
+// src/controllers/controllers/networkpolicy_test.go
+package controllers
+
+import (
+    "context"
+    "testing"
+
+    "github.com/go-logr/logr"
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// applyAsWrite stands in for server-side apply, which the fake client
+// doesn't support, with a create or a full update
+func applyAsWrite(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+    if patch.Type() != types.ApplyPatchType {
+        return c.Patch(ctx, obj, patch, opts...)
+    }
+    existing := obj.DeepCopyObject().(client.Object)
+    err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+    if apierrors.IsNotFound(err) {
+        return c.Create(ctx, obj)
+    }
+    if err != nil {
+        return err
+    }
+    obj.SetResourceVersion(existing.GetResourceVersion())
+    return c.Update(ctx, obj)
+}
+
+func TestLearnedPoliciesSurvivePruning(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := clientgoscheme.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := qraiopv1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    c := interceptor.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build(), interceptor.Funcs{Patch: applyAsWrite})
+    r := &QraiopReconciler{Client: c, Scheme: scheme, Log: logr.Discard()}
+
+    q := &qraiopv1.Qraiop{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "team", UID: "prod-uid"}}
+    q.Spec.SecurityPolicies.NetworkPolicies.Learning = &qraiopv1.NetworkPolicyLearning{Enabled: true}
+    learned := networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{Name: "allow-api"},
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+        },
+    }
+
+    reconcile := func(step func(ctx context.Context) error) {
+        t.Helper()
+        ctx, inv := withInventory(context.Background())
+        if err := step(ctx); err != nil {
+            t.Fatal(err)
+        }
+        if err := r.pruneInventory(ctx, q, inv); err != nil {
+            t.Fatal(err)
+        }
+    }
+    learn := func(ctx context.Context) error {
+        _, _, err := r.reconcileNetworkPolicyLearning(ctx, q)
+        return err
+    }
+    exists := func(obj client.Object, name string) {
+        t.Helper()
+        if err := c.Get(context.Background(), client.ObjectKey{Namespace: "team", Name: name}, obj); err != nil {
+            t.Fatalf("%s: %v", name, err)
+        }
+    }
+
+    // The reconcile ending observation proposes the draft
+    reconcile(func(ctx context.Context) error {
+        draft, err := r.writeNetworkPolicyDraft(ctx, q, []networkingv1.NetworkPolicy{learned})
+        q.Status.NetworkPolicyLearning = &qraiopv1.LearningStatus{Phase: qraiopv1.LearningPhaseProposed, DraftName: draft}
+        return err
+    })
+    reconcile(learn)
+    reconcile(learn)
+    var draft corev1.ConfigMap
+    exists(&draft, q.Status.NetworkPolicyLearning.DraftName)
+    if q.Status.NetworkPolicyLearning.Phase != qraiopv1.LearningPhaseProposed {
+        t.Fatalf("phase = %s, want the draft still proposed", q.Status.NetworkPolicyLearning.Phase)
+    }
+
+    draft.Annotations = map[string]string{ApprovedAnnotation: "true"}
+    if err := c.Update(context.Background(), &draft); err != nil {
+        t.Fatal(err)
+    }
+    reconcile(learn)
+    reconcile(learn)
+    if q.Status.NetworkPolicyLearning.Phase != qraiopv1.LearningPhaseApproved {
+        t.Fatalf("phase = %s, want approved", q.Status.NetworkPolicyLearning.Phase)
+    }
+    exists(&corev1.ConfigMap{}, draft.Name)
+    exists(&networkingv1.NetworkPolicy{}, learned.Name)
+}
@@ -0,0 +1,215 @@
+// src/controllers/controllers/operator_networkpolicy.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "time"
+
+    "github.com/go-logr/logr"
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/intstr"
+    "k8s.io/apimachinery/pkg/util/wait"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+    // operatorNetworkPolicy is the NetworkPolicy securing the operator pods
+    operatorNetworkPolicy = "qraiop-controller"
+    // operatorPolicyInterval is how often the policy is refreshed, to
+    // follow API server endpoints that move
+    operatorPolicyInterval = 5 * time.Minute
+)
+
+// OperatorNetworkPolicy restricts the traffic of the operator's own pods:
+// ingress only to the webhook port from the API server, to the metrics
+// port from the Prometheus namespaces and to the enrollment port, and
+// egress only to the API server, DNS, the QRAIOP components and the
+// configured CIDRs. It runs on the leader. Disabled, it removes the policy.
+type OperatorNetworkPolicy struct {
+    Client client.Client
+    // Reader reads uncached, so no informers are started for Endpoints
+    Reader client.Reader
+    Log    logr.Logger
+    // Enabled applies the policy; otherwise it is deleted
+    Enabled bool
+    // Namespace and Selector locate the operator pods
+    Namespace string
+    Selector  map[string]string
+    // APIServerCIDRs are where the API server calls webhooks from and
+    // where it is reached. Empty uses the endpoints of the kubernetes
+    // Service, which is right unless the control plane sits behind NAT.
+    APIServerCIDRs []string
+    // MetricsNamespaces may scrape the metrics port, e.g. monitoring
+    MetricsNamespaces []string
+    // EgressCIDRs are further destinations the operator may reach, such
+    // as Alertmanager, Grafana, Vault or AI providers outside the cluster
+    EgressCIDRs []string
+
+    WebhookPort int32
+    MetricsPort int32
+    // EnrollmentPort, when set, is open to every client
+    EnrollmentPort int32
+}
+
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;create;update;delete
+
+func (p *OperatorNetworkPolicy) Start(ctx context.Context) error {
+    if !p.Enabled {
+        policy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: operatorNetworkPolicy, Namespace: p.Namespace}}
+        if err := p.Client.Delete(ctx, policy); client.IgnoreNotFound(err) != nil {
+            p.Log.Error(err, "unable to remove operator NetworkPolicy")
+        }
+        return nil
+    }
+    wait.UntilWithContext(ctx, func(ctx context.Context) {
+        if err := p.apply(ctx); err != nil {
+            p.Log.Error(err, "unable to apply operator NetworkPolicy")
+        }
+    }, operatorPolicyInterval)
+    return nil
+}
+
+func (p *OperatorNetworkPolicy) apply(ctx context.Context) error {
+    apiServer, apiPorts, err := p.apiServer(ctx)
+    if err != nil {
+        return err
+    }
+    want := p.render(apiServer, apiPorts)
+
+    var existing networkingv1.NetworkPolicy
+    err = p.Reader.Get(ctx, client.ObjectKeyFromObject(want), &existing)
+    if apierrors.IsNotFound(err) {
+        p.Log.Info("securing operator pods with NetworkPolicy", "name", want.Name, "apiServer", apiServer)
+        return p.Client.Create(ctx, want)
+    }
+    if err != nil {
+        return err
+    }
+    if equality.Semantic.DeepEqual(existing.Spec, want.Spec) && equality.Semantic.DeepEqual(existing.Labels, want.Labels) {
+        return nil
+    }
+    want.ResourceVersion = existing.ResourceVersion
+    return p.Client.Update(ctx, want)
+}
+
+// apiServer returns the CIDRs and ports of the API server
+func (p *OperatorNetworkPolicy) apiServer(ctx context.Context) ([]string, []int32, error) {
+    var endpoints corev1.Endpoints
+    if err := p.Reader.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "kubernetes"}, &endpoints); err != nil {
+        return nil, nil, fmt.Errorf("reading API server endpoints: %w", err)
+    }
+    cidrs := p.APIServerCIDRs
+    // The Service port, 443, is translated before policies see the traffic
+    ports := []int32{443}
+    for _, subset := range endpoints.Subsets {
+        if len(p.APIServerCIDRs) == 0 {
+            for _, addr := range subset.Addresses {
+                if ip := net.ParseIP(addr.IP); ip != nil {
+                    bits := 32
+                    if ip.To4() == nil {
+                        bits = 128
+                    }
+                    cidrs = append(cidrs, fmt.Sprintf("%s/%d", addr.IP, bits))
+                }
+            }
+        }
+        for _, port := range subset.Ports {
+            if port.Port != 443 {
+                ports = append(ports, port.Port)
+            }
+        }
+    }
+    if len(cidrs) == 0 {
+        return nil, nil, fmt.Errorf("no API server addresses; set --apiserver-cidrs")
+    }
+    return cidrs, ports, nil
+}
+
+func (p *OperatorNetworkPolicy) render(apiServer []string, apiPorts []int32) *networkingv1.NetworkPolicy {
+    tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+    port := func(protocol *corev1.Protocol, n int32) networkingv1.NetworkPolicyPort {
+        p := intstr.FromInt32(n)
+        return networkingv1.NetworkPolicyPort{Protocol: protocol, Port: &p}
+    }
+    blocks := func(cidrs []string) []networkingv1.NetworkPolicyPeer {
+        peers := make([]networkingv1.NetworkPolicyPeer, 0, len(cidrs))
+        for _, c := range cidrs {
+            peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: c}})
+        }
+        return peers
+    }
+
+    var ingress []networkingv1.NetworkPolicyIngressRule
+    if p.WebhookPort > 0 {
+        ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+            Ports: []networkingv1.NetworkPolicyPort{port(&tcp, p.WebhookPort)},
+            From:  blocks(apiServer),
+        })
+    }
+    if p.MetricsPort > 0 && len(p.MetricsNamespaces) > 0 {
+        ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+            Ports: []networkingv1.NetworkPolicyPort{port(&tcp, p.MetricsPort)},
+            From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{
+                MatchExpressions: []metav1.LabelSelectorRequirement{{
+                    Key:      "kubernetes.io/metadata.name",
+                    Operator: metav1.LabelSelectorOpIn,
+                    Values:   p.MetricsNamespaces,
+                }},
+            }}},
+        })
+    }
+    if p.EnrollmentPort > 0 {
+        ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+            Ports: []networkingv1.NetworkPolicyPort{port(&tcp, p.EnrollmentPort)},
+        })
+    }
+
+    apiServerPorts := make([]networkingv1.NetworkPolicyPort, 0, len(apiPorts))
+    for _, n := range apiPorts {
+        apiServerPorts = append(apiServerPorts, port(&tcp, n))
+    }
+    egress := []networkingv1.NetworkPolicyEgressRule{
+        {Ports: apiServerPorts, To: blocks(apiServer)},
+        {
+            Ports: []networkingv1.NetworkPolicyPort{port(&udp, 53), port(&tcp, 53)},
+            To: []networkingv1.NetworkPolicyPeer{{
+                NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+                PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+            }},
+        },
+        {
+            // Crypto services, agents and the other components of every instance
+            To: []networkingv1.NetworkPolicyPeer{{
+                NamespaceSelector: &metav1.LabelSelector{},
+                PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/part-of": "qraiop"}},
+            }},
+        },
+    }
+    if len(p.EgressCIDRs) > 0 {
+        egress = append(egress, networkingv1.NetworkPolicyEgressRule{To: blocks(p.EgressCIDRs)})
+    }
+
+    return &networkingv1.NetworkPolicy{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      operatorNetworkPolicy,
+            Namespace: p.Namespace,
+            Labels: map[string]string{
+                "app.kubernetes.io/managed-by": "qraiop-controller",
+                "app.kubernetes.io/part-of":    "qraiop",
+            },
+        },
+        Spec: networkingv1.NetworkPolicySpec{
+            PodSelector: metav1.LabelSelector{MatchLabels: p.Selector},
+            PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+            Ingress:     ingress,
+            Egress:      egress,
+        },
+    }
+}
@@ -0,0 +1,70 @@
+// src/controllers/controllers/orphans.go
+package controllers
+
+import (
+    "context"
+
+    "github.com/go-logr/logr"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+// OrphanSweep runs once on the leader at startup and finds the objects
+// created for instances that no longer exist, as after the CRD was
+// uninstalled and reinstalled. Those whose instance was recreated under
+// the same name are adopted by it unless its adoption policy says
+// otherwise; the rest are logged for cleanup with qraiopctl orphans.
+type OrphanSweep struct {
+    Client client.Client
+    // Reader reads uncached, so no informers are started for the kinds
+    // only swept once
+    Reader client.Reader
+    Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=services;configmaps;secrets;serviceaccounts,verbs=list;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=list;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=list;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=list;patch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=list;patch
+
+func (s *OrphanSweep) Start(ctx context.Context) error {
+    orphans, err := qraiopclient.FindOrphans(ctx, s.Reader, "")
+    if err != nil {
+        s.Log.Error(err, "unable to look for orphaned objects")
+        return nil
+    }
+    c := qraiopclient.NewForClient(s.Client)
+    left := 0
+    for _, o := range orphans {
+        log := s.Log.WithValues("kind", o.Kind, "namespace", o.Namespace, "name", o.Name, "qraiop", o.Instance)
+        if !o.Adoptable {
+            log.Info("object belongs to no instance; review with qraiopctl orphans")
+            left++
+            continue
+        }
+        q, err := c.GetQraiop(ctx, o.Namespace, o.Instance)
+        if err != nil {
+            log.Error(err, "unable to read the instance to adopt the object")
+            left++
+            continue
+        }
+        if q.Spec.AdoptionPolicy == qraiopv1.AdoptionFail || q.Spec.AdoptionPolicy == qraiopv1.AdoptionIgnore {
+            log.Info("object of a recreated instance left alone under its adoption policy", "policy", q.Spec.AdoptionPolicy)
+            left++
+            continue
+        }
+        if err := c.Adopt(ctx, o, q); err != nil {
+            log.Error(err, "unable to adopt orphaned object")
+            left++
+            continue
+        }
+        log.Info("adopted orphaned object into recreated instance")
+    }
+    if left > 0 {
+        s.Log.Info("orphaned objects left for cleanup", "count", left)
+    }
+    return nil
+}
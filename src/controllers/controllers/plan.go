@@ -0,0 +1,220 @@
+// src/controllers/controllers/plan.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "hash/fnv"
+    "sort"
+    "sync"
+    "time"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionPlanApproval = "PlanApproval"
+
+    // planRefreshInterval is how often a plan waiting for approval is
+    // recomputed against the cluster
+    planRefreshInterval = 10 * time.Minute
+)
+
+// Planned change actions
+const (
+    planCreate = "Create"
+    planUpdate = "Update"
+    planPatch  = "Patch"
+    planDelete = "Delete"
+)
+
+// plan collects the writes a planning reconcile would have made
+type plan struct {
+    mu      sync.Mutex
+    changes map[string]qraiopv1.PlannedChange
+    // hashes are the contents planned for created and updated objects
+    hashes map[string]string
+    // previous are the object hashes of the last inventory, to tell real
+    // updates from rewrites of unchanged objects
+    previous map[string]string
+    // workloads are the workloads as they would have been written, to
+    // project the instance's footprint
+    workloads map[string]client.Object
+}
+
+func newPlan(q *qraiopv1.Qraiop) *plan {
+    p := &plan{
+        changes:   make(map[string]qraiopv1.PlannedChange),
+        hashes:    make(map[string]string),
+        previous:  make(map[string]string),
+        workloads: make(map[string]client.Object),
+    }
+    for _, e := range q.Status.Inventory {
+        p.previous[entryKey(e)] = e.Hash
+    }
+    return p
+}
+
+func (p *plan) add(action string, e qraiopv1.InventoryEntry) {
+    key := entryKey(e)
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    // A create followed by a patch is still a create
+    if cur, ok := p.changes[key]; ok && cur.Action == planCreate {
+        return
+    }
+    p.changes[key] = qraiopv1.PlannedChange{Action: action, Kind: e.Kind, Namespace: e.Namespace, Name: e.Name}
+    if e.Hash != "" {
+        p.hashes[key] = e.Hash
+    }
+}
+
+// write keeps obj when it is a workload
+func (p *plan) write(e qraiopv1.InventoryEntry, obj client.Object) {
+    if _, _, ok := workloadPods(obj); !ok {
+        return
+    }
+    p.mu.Lock()
+    p.workloads[entryKey(e)] = obj.DeepCopyObject().(client.Object)
+    p.mu.Unlock()
+}
+
+// sorted returns the changes in a stable order, with the plan's hash.
+// Creates and updates are only as approved as the content they write, so
+// that content is part of the hash.
+func (p *plan) sorted() ([]qraiopv1.PlannedChange, string) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    keys := make([]string, 0, len(p.changes))
+    for key := range p.changes {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    changes := make([]qraiopv1.PlannedChange, 0, len(keys))
+    h := fnv.New64a()
+    for _, key := range keys {
+        c := p.changes[key]
+        changes = append(changes, c)
+        fmt.Fprintf(h, "%s %s %s\n", c.Action, key, p.hashes[key])
+    }
+    return changes, fmt.Sprintf("%x", h.Sum64())
+}
+
+// planningClient records writes in a plan instead of making them. Reads go
+// to the cluster, and status writes are left alone.
+type planningClient struct {
+    client.Client
+    plan *plan
+}
+
+// entry identifies obj, with the content hash recorded for it in the
+// reconcile's inventory if any
+func (c *planningClient) entry(ctx context.Context, obj client.Object) qraiopv1.InventoryEntry {
+    e := qraiopv1.InventoryEntry{Kind: fmt.Sprintf("%T", obj), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+    gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+    if err != nil {
+        return e
+    }
+    e.Group, e.Version, e.Kind = gvk.Group, gvk.Version, gvk.Kind
+    if inv := inventoryFrom(ctx); inv != nil {
+        inv.mu.Lock()
+        e.Hash = inv.entries[entryKey(e)].Hash
+        inv.mu.Unlock()
+    }
+    return e
+}
+
+func (c *planningClient) Create(ctx context.Context, obj client.Object, _ ...client.CreateOption) error {
+    e := c.entry(ctx, obj)
+    c.plan.write(e, obj)
+    c.plan.add(planCreate, e)
+    return nil
+}
+
+// Update is only planned when the object's content changed since it was
+// last written
+func (c *planningClient) Update(ctx context.Context, obj client.Object, _ ...client.UpdateOption) error {
+    e := c.entry(ctx, obj)
+    c.plan.write(e, obj)
+    if prev, ok := c.plan.previous[entryKey(e)]; ok && e.Hash != "" && prev == e.Hash {
+        return nil
+    }
+    c.plan.add(planUpdate, e)
+    return nil
+}
+
+// Patch plans server-side applies as the create or update they amount to
+func (c *planningClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+    if patch.Type() == types.ApplyPatchType {
+        existing := obj.DeepCopyObject().(client.Object)
+        err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+        if apierrors.IsNotFound(err) {
+            return c.Create(ctx, obj)
+        }
+        if err != nil {
+            return err
+        }
+        return c.Update(ctx, obj)
+    }
+    e := c.entry(ctx, obj)
+    c.plan.write(e, obj)
+    c.plan.add(planPatch, e)
+    return nil
+}
+
+func (c *planningClient) Delete(ctx context.Context, obj client.Object, _ ...client.DeleteOption) error {
+    c.plan.add(planDelete, c.entry(ctx, obj))
+    return nil
+}
+
+// planning reports whether r only plans its writes
+func (r *QraiopReconciler) planning() bool {
+    _, ok := r.Client.(*planningClient)
+    return ok
+}
+
+// reviewPlan works out the changes reconciling q would make, without
+// making them, and publishes them in status. It reports whether they were
+// approved, or there are none, so the reconcile may go ahead for real.
+func (r *QraiopReconciler) reviewPlan(ctx context.Context, q *qraiopv1.Qraiop) (bool, error) {
+    p := newPlan(q)
+    planner := *r
+    planner.Client = &planningClient{Client: r.Client, plan: p}
+    // Plan against a copy so the dry run leaves no trace in status
+    if _, err := planner.apply(ctx, q.DeepCopy()); err != nil {
+        return false, err
+    }
+
+    changes, hash := p.sorted()
+    if len(changes) == 0 {
+        clearPlan(q)
+        return true, nil
+    }
+    if q.Annotations[qraiopv1.ApprovePlanAnnotation] == hash {
+        setCondition(q, conditionPlanApproval, metav1.ConditionTrue, "Approved",
+            fmt.Sprintf("applying %d approved change(s)", len(changes)))
+        q.Status.PlannedChanges = nil
+        return true, nil
+    }
+
+    if cur := q.Status.PlannedChanges; cur == nil || cur.Hash != hash {
+        q.Status.PlannedChanges = &qraiopv1.PlannedChanges{Hash: hash, Changes: changes, GeneratedAt: metav1.Now()}
+    }
+    setCondition(q, conditionPlanApproval, metav1.ConditionFalse, "AwaitingApproval",
+        fmt.Sprintf("%d change(s) planned; approve with annotation %s=%s", len(changes), qraiopv1.ApprovePlanAnnotation, hash))
+    return false, nil
+}
+
+// clearPlan drops the plan of an instance with nothing left to approve
+func clearPlan(q *qraiopv1.Qraiop) {
+    q.Status.PlannedChanges = nil
+    meta.RemoveStatusCondition(&q.Status.Conditions, conditionPlanApproval)
+}
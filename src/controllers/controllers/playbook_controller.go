@@ -0,0 +1,566 @@
+// src/controllers/controllers/playbook_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-logr/logr"
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/tools/record"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/builder"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/predicate"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+const (
+    // PlaybookLabel names the Playbook that started an experiment
+    PlaybookLabel = "qraiop.io/playbook"
+
+    conditionInstanceFound = "InstanceFound"
+
+    // playbookPoll is how often a running step is checked
+    playbookPoll = 10 * time.Second
+    // defaultStepTimeout applies to steps without a timeout
+    defaultStepTimeout = 5 * time.Minute
+    // notifyAlertLifetime is how long a notify step's alert fires
+    notifyAlertLifetime = 15 * time.Minute
+)
+
+// PlaybookReconciler runs the steps of a Playbook, one after the other,
+// when its trigger matches the status of its Qraiop instance, and rolls
+// the run back when a step fails
+type PlaybookReconciler struct {
+    client.Client
+    Scheme   *runtime.Scheme
+    Log      logr.Logger
+    Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=playbooks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=playbooks/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+func (r *PlaybookReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    log := r.Log.WithValues("playbook", req.NamespacedName)
+
+    var pb qraiopv1.Playbook
+    if err := r.Get(ctx, req.NamespacedName, &pb); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+
+    var q qraiopv1.Qraiop
+    err := r.Get(ctx, client.ObjectKey{Namespace: pb.Namespace, Name: pb.Spec.Instance}, &q)
+    if apierrors.IsNotFound(err) {
+        setPlaybookCondition(&pb, metav1.ConditionFalse, "NotFound", "Qraiop instance "+pb.Spec.Instance+" not found")
+        return ctrl.Result{}, r.Status().Update(ctx, &pb)
+    }
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    setPlaybookCondition(&pb, metav1.ConditionTrue, "Found", "watching Qraiop instance "+q.Name)
+
+    if run := pb.Status.Current; run != nil && run.FinishedAt == nil {
+        after, err := r.advance(ctx, &pb, &q, run)
+        if err != nil {
+            return ctrl.Result{}, err
+        }
+        return ctrl.Result{RequeueAfter: after}, r.Status().Update(ctx, &pb)
+    }
+
+    if pb.Spec.Suspend {
+        return ctrl.Result{}, r.Status().Update(ctx, &pb)
+    }
+    since, match, ok := playbookMatch(pb.Spec.Trigger, &q)
+    if !ok {
+        return ctrl.Result{}, r.Status().Update(ctx, &pb)
+    }
+    now := time.Now()
+    due := since.Add(pb.Spec.Trigger.For.Duration)
+    if last := pb.Status.Current; last != nil && last.FinishedAt != nil {
+        if cooled := last.FinishedAt.Add(pb.Spec.Cooldown.Duration); cooled.After(due) {
+            due = cooled
+        }
+    }
+    if due.After(now) {
+        return ctrl.Result{RequeueAfter: due.Sub(now)}, r.Status().Update(ctx, &pb)
+    }
+
+    run := &qraiopv1.PlaybookRun{
+        Phase:     qraiopv1.PlaybookRunning,
+        Trigger:   match,
+        StartedAt: metav1.NewTime(now),
+    }
+    for _, step := range pb.Spec.Steps {
+        run.Steps = append(run.Steps, qraiopv1.PlaybookStepStatus{Name: step.Name, Phase: qraiopv1.PlaybookPending})
+    }
+    pb.Status.Current = run
+    pb.Status.Runs++
+    log.Info("trigger matched, starting playbook", "trigger", match)
+    r.Recorder.Eventf(&pb, corev1.EventTypeNormal, "PlaybookStarted", "%s, running %d step(s)", match, len(run.Steps))
+
+    after, err := r.advance(ctx, &pb, &q, run)
+    if err != nil {
+        return ctrl.Result{}, err
+    }
+    return ctrl.Result{RequeueAfter: after}, r.Status().Update(ctx, &pb)
+}
+
+// playbookMatch reports whether the trigger matches q, since when and how
+func playbookMatch(t qraiopv1.PlaybookTrigger, q *qraiopv1.Qraiop) (time.Time, string, bool) {
+    if t.Component != "" {
+        c, ok := q.Status.Components[t.Component]
+        if !ok || c.Status != t.Status {
+            return time.Time{}, "", false
+        }
+        return c.LastUpdated.Time, fmt.Sprintf("component %s is %s: %s", t.Component, c.Status, c.Message), true
+    }
+    cond := meta.FindStatusCondition(q.Status.Conditions, t.Condition)
+    if cond == nil || string(cond.Status) != t.Status || (t.Reason != "" && cond.Reason != t.Reason) {
+        return time.Time{}, "", false
+    }
+    return cond.LastTransitionTime.Time, fmt.Sprintf("condition %s is %s (%s): %s", cond.Type, cond.Status, cond.Reason, cond.Message), true
+}
+
+// advance runs the steps of a run until one waits, and returns when to
+// check it again. A failed step starts the rollback.
+func (r *PlaybookReconciler) advance(ctx context.Context, pb *qraiopv1.Playbook, q *qraiopv1.Qraiop, run *qraiopv1.PlaybookRun) (time.Duration, error) {
+    if run.RollingBack {
+        return r.rollback(ctx, pb, q, run)
+    }
+    for i := range run.Steps {
+        st := &run.Steps[i]
+        if st.Phase != qraiopv1.PlaybookPending && st.Phase != qraiopv1.PlaybookRunning {
+            continue
+        }
+        now := metav1.Now()
+        if st.StartedAt == nil {
+            st.StartedAt = &now
+            st.Phase = qraiopv1.PlaybookRunning
+        }
+
+        step := playbookStep(pb, i, st.Name)
+        phase, msg := qraiopv1.PlaybookFailed, "the step was removed from the playbook during the run"
+        if step != nil {
+            var err error
+            if phase, msg, err = r.runStep(ctx, pb, q, step, st); err != nil {
+                return 0, err
+            }
+            timeout := step.Timeout.Duration
+            if timeout <= 0 {
+                timeout = defaultStepTimeout
+            }
+            if phase == qraiopv1.PlaybookRunning && now.Sub(st.StartedAt.Time) > timeout {
+                phase, msg = qraiopv1.PlaybookFailed, fmt.Sprintf("timed out after %s: %s", timeout, msg)
+            }
+        }
+        st.Message = msg
+        if phase == qraiopv1.PlaybookRunning {
+            return playbookPoll, nil
+        }
+        st.Phase, st.FinishedAt = phase, &now
+
+        if phase == qraiopv1.PlaybookSucceeded {
+            r.Recorder.Eventf(pb, corev1.EventTypeNormal, "StepSucceeded", "step %s: %s", st.Name, msg)
+            continue
+        }
+        r.Recorder.Eventf(pb, corev1.EventTypeWarning, "StepFailed", "step %s: %s", st.Name, msg)
+        if step != nil && step.ContinueOnFailure {
+            continue
+        }
+        run.RollingBack = true
+        return r.rollback(ctx, pb, q, run)
+    }
+
+    now := metav1.Now()
+    run.Phase, run.FinishedAt = qraiopv1.PlaybookSucceeded, &now
+    r.Recorder.Event(pb, corev1.EventTypeNormal, "PlaybookSucceeded", "all steps completed")
+    return 0, nil
+}
+
+// rollback undoes the started steps of a failed run in reverse order
+func (r *PlaybookReconciler) rollback(ctx context.Context, pb *qraiopv1.Playbook, q *qraiopv1.Qraiop, run *qraiopv1.PlaybookRun) (time.Duration, error) {
+    now := metav1.Now()
+    run.Phase = qraiopv1.PlaybookRolledBack
+    for i := len(run.Steps) - 1; i >= 0; i-- {
+        st := &run.Steps[i]
+        if st.Phase != qraiopv1.PlaybookSucceeded && st.Phase != qraiopv1.PlaybookFailed {
+            continue
+        }
+        step := playbookStep(pb, i, st.Name)
+        if step == nil {
+            continue
+        }
+        undone, err := r.rollbackStep(ctx, pb, q, step, st)
+        if err != nil {
+            st.Message = "rollback failed: " + err.Error()
+            run.Phase = qraiopv1.PlaybookFailed
+            r.Recorder.Eventf(pb, corev1.EventTypeWarning, "RollbackFailed", "step %s: %v", st.Name, err)
+            break
+        }
+        if undone {
+            st.Phase = qraiopv1.PlaybookRolledBack
+        }
+    }
+    run.RollingBack = false
+    run.FinishedAt = &now
+    if run.Phase == qraiopv1.PlaybookRolledBack {
+        r.Recorder.Event(pb, corev1.EventTypeWarning, "PlaybookRolledBack", "a step failed, the run was rolled back")
+    }
+    return 0, nil
+}
+
+// playbookStep returns the spec of the i-th step of a run, or nil when the
+// playbook changed and no longer has it
+func playbookStep(pb *qraiopv1.Playbook, i int, name string) *qraiopv1.PlaybookStep {
+    if i >= len(pb.Spec.Steps) || pb.Spec.Steps[i].Name != name {
+        return nil
+    }
+    return &pb.Spec.Steps[i]
+}
+
+// runStep applies a step or checks on it, and returns its phase. Errors
+// are those of the API server, to be retried.
+func (r *PlaybookReconciler) runStep(ctx context.Context, pb *qraiopv1.Playbook, q *qraiopv1.Qraiop, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (string, string, error) {
+    switch step.Action {
+    case qraiopv1.PlaybookRestart:
+        return r.restartStep(ctx, q, step, st)
+    case qraiopv1.PlaybookScale:
+        return r.scaleStep(ctx, q, step, st)
+    case qraiopv1.PlaybookRotateCert:
+        return r.rotateCertStep(ctx, pb, step, st)
+    case qraiopv1.PlaybookRunExperiment:
+        return r.experimentStep(ctx, pb, step, st)
+    case qraiopv1.PlaybookNotify:
+        return r.notifyStep(ctx, pb, q, step)
+    }
+    return qraiopv1.PlaybookFailed, "unknown action " + step.Action, nil
+}
+
+// rollbackStep undoes a step, and reports whether it had anything to undo
+func (r *PlaybookReconciler) rollbackStep(ctx context.Context, pb *qraiopv1.Playbook, q *qraiopv1.Qraiop, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (bool, error) {
+    if !st.Applied {
+        return false, nil
+    }
+    switch step.Action {
+    case qraiopv1.PlaybookScale:
+        deployment, err := qraiopclient.ComponentDeployment(step.Component)
+        if err != nil {
+            return false, err
+        }
+        return true, r.setPlaybookReplicas(ctx, q, deployment, nil)
+    case qraiopv1.PlaybookRunExperiment:
+        var exp qraiopv1.ChaosExperiment
+        err := r.Get(ctx, client.ObjectKey{Namespace: pb.Namespace, Name: st.Experiment}, &exp)
+        if apierrors.IsNotFound(err) {
+            return false, nil
+        }
+        if err != nil {
+            return false, err
+        }
+        if exp.Status.IsFinished() {
+            return false, nil
+        }
+        patch := client.MergeFrom(exp.DeepCopy())
+        exp.Spec.Abort = true
+        return true, r.Patch(ctx, &exp, patch)
+    }
+    return false, nil
+}
+
+// restartStep deletes the pods of a component that predate the step, one
+// at a time once the Deployment is fully available
+func (r *PlaybookReconciler) restartStep(ctx context.Context, q *qraiopv1.Qraiop, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (string, string, error) {
+    deployment, err := qraiopclient.ComponentDeployment(step.Component)
+    if err != nil {
+        return qraiopv1.PlaybookFailed, err.Error(), nil
+    }
+    var dep appsv1.Deployment
+    err = r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: deployment}, &dep)
+    if apierrors.IsNotFound(err) {
+        return qraiopv1.PlaybookFailed, "deployment " + deployment + " not found", nil
+    }
+    if err != nil {
+        return "", "", err
+    }
+
+    var pods corev1.PodList
+    if err := r.List(ctx, &pods, client.InNamespace(q.Namespace), client.MatchingLabels(componentSelector(q, deployment))); err != nil {
+        return "", "", err
+    }
+    var stale []corev1.Pod
+    for _, pod := range pods.Items {
+        if pod.DeletionTimestamp != nil {
+            return qraiopv1.PlaybookRunning, "waiting for pod " + pod.Name + " to terminate", nil
+        }
+        if pod.CreationTimestamp.Before(st.StartedAt) {
+            stale = append(stale, pod)
+        }
+    }
+    if !rolledOut(&dep) {
+        return qraiopv1.PlaybookRunning, fmt.Sprintf("waiting for %s: %d/%d replicas available", deployment, dep.Status.AvailableReplicas, dep.Status.Replicas), nil
+    }
+    if len(stale) == 0 {
+        return qraiopv1.PlaybookSucceeded, "restarted the pods of " + deployment, nil
+    }
+    if err := r.Delete(ctx, &stale[0]); err != nil && !apierrors.IsNotFound(err) {
+        return "", "", err
+    }
+    st.Applied = true
+    return qraiopv1.PlaybookRunning, fmt.Sprintf("restarting pod %s, %d left", stale[0].Name, len(stale)-1), nil
+}
+
+// scaleStep overrides the replicas of a component on the instance, which
+// the Qraiop controller applies, and waits for them to be available
+func (r *PlaybookReconciler) scaleStep(ctx context.Context, q *qraiopv1.Qraiop, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (string, string, error) {
+    if step.Replicas == nil {
+        return qraiopv1.PlaybookFailed, "scale needs replicas", nil
+    }
+    deployment, err := qraiopclient.ComponentDeployment(step.Component)
+    if err != nil {
+        return qraiopv1.PlaybookFailed, err.Error(), nil
+    }
+    var dep appsv1.Deployment
+    err = r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: deployment}, &dep)
+    if apierrors.IsNotFound(err) {
+        return qraiopv1.PlaybookFailed, "deployment " + deployment + " not found", nil
+    }
+    if err != nil {
+        return "", "", err
+    }
+
+    want := *step.Replicas
+    if !st.Applied {
+        previous := int32(1)
+        if dep.Spec.Replicas != nil {
+            previous = *dep.Spec.Replicas
+        }
+        if err := r.setPlaybookReplicas(ctx, q, deployment, &want); err != nil {
+            return "", "", err
+        }
+        st.Previous, st.Applied = &previous, true
+    }
+    if dep.Spec.Replicas == nil || *dep.Spec.Replicas != want || !rolledOut(&dep) {
+        return qraiopv1.PlaybookRunning, fmt.Sprintf("scaling %s to %d, %d available", deployment, want, dep.Status.AvailableReplicas), nil
+    }
+    return qraiopv1.PlaybookSucceeded, fmt.Sprintf("scaled %s from %d to %d", deployment, *st.Previous, want), nil
+}
+
+// rotateCertStep has the injected certificate of a Deployment renewed and
+// waits for the new one
+func (r *PlaybookReconciler) rotateCertStep(ctx context.Context, pb *qraiopv1.Playbook, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (string, string, error) {
+    if step.Deployment == "" {
+        return qraiopv1.PlaybookFailed, "rotateCert needs a deployment", nil
+    }
+    var secret corev1.Secret
+    err := r.Get(ctx, client.ObjectKey{Namespace: pb.Namespace, Name: qraiopv1.InjectedCertSecret(step.Deployment)}, &secret)
+    if apierrors.IsNotFound(err) {
+        return qraiopv1.PlaybookFailed, "deployment " + step.Deployment + " has no injected certificate", nil
+    }
+    if err != nil {
+        return "", "", err
+    }
+    if _, revoked := secret.Annotations[qraiopv1.RevokeAnnotation]; revoked {
+        return qraiopv1.PlaybookFailed, "the certificate of " + step.Deployment + " is revoked; delete its Secret to get a new one", nil
+    }
+
+    _, pending := secret.Annotations[qraiopv1.RenewCertAnnotation]
+    if !st.Applied {
+        if !pending {
+            patch := client.MergeFrom(secret.DeepCopy())
+            if secret.Annotations == nil {
+                secret.Annotations = make(map[string]string)
+            }
+            secret.Annotations[qraiopv1.RenewCertAnnotation] = time.Now().UTC().Format(time.RFC3339)
+            if err := r.Patch(ctx, &secret, patch); err != nil {
+                return "", "", err
+            }
+        }
+        st.Applied = true
+        return qraiopv1.PlaybookRunning, "renewal of " + secret.Name + " requested", nil
+    }
+    if pending {
+        return qraiopv1.PlaybookRunning, "waiting for the certificate in " + secret.Name + " to be re-issued", nil
+    }
+    return qraiopv1.PlaybookSucceeded, "re-issued the certificate in " + secret.Name, nil
+}
+
+// experimentStep runs a chaos experiment, owned by the playbook, and
+// passes with it
+func (r *PlaybookReconciler) experimentStep(ctx context.Context, pb *qraiopv1.Playbook, step *qraiopv1.PlaybookStep, st *qraiopv1.PlaybookStepStatus) (string, string, error) {
+    if step.Experiment == nil {
+        return qraiopv1.PlaybookFailed, "runExperiment needs an experiment", nil
+    }
+    if !st.Applied {
+        exp := &qraiopv1.ChaosExperiment{
+            ObjectMeta: metav1.ObjectMeta{
+                Name:      fmt.Sprintf("%s-%d-%s", pb.Name, pb.Status.Runs, step.Name),
+                Namespace: pb.Namespace,
+                Labels:    map[string]string{PlaybookLabel: pb.Name},
+            },
+            Spec: qraiopv1.ChaosExperimentSpec{ExperimentConfig: *step.Experiment},
+        }
+        if err := ctrl.SetControllerReference(pb, exp, r.Scheme); err != nil {
+            return "", "", err
+        }
+        if err := r.Create(ctx, exp); err != nil && !apierrors.IsAlreadyExists(err) {
+            return "", "", err
+        }
+        st.Experiment, st.Applied = exp.Name, true
+        return qraiopv1.PlaybookRunning, "started experiment " + exp.Name, nil
+    }
+
+    var exp qraiopv1.ChaosExperiment
+    err := r.Get(ctx, client.ObjectKey{Namespace: pb.Namespace, Name: st.Experiment}, &exp)
+    if apierrors.IsNotFound(err) {
+        return qraiopv1.PlaybookFailed, "experiment " + st.Experiment + " was deleted", nil
+    }
+    if err != nil {
+        return "", "", err
+    }
+    if !exp.Status.IsFinished() {
+        return qraiopv1.PlaybookRunning, "experiment " + exp.Name + " is running", nil
+    }
+    if exp.Status.Verdict != qraiopv1.VerdictPassed {
+        return qraiopv1.PlaybookFailed, fmt.Sprintf("experiment %s %s: %s", exp.Name, exp.Status.Verdict, exp.Status.Message), nil
+    }
+    return qraiopv1.PlaybookSucceeded, "experiment " + exp.Name + " passed", nil
+}
+
+// notifyStep records the message as an event on the instance and sends it
+// as an alert through the instance's Alertmanager, when it has one
+func (r *PlaybookReconciler) notifyStep(ctx context.Context, pb *qraiopv1.Playbook, q *qraiopv1.Qraiop, step *qraiopv1.PlaybookStep) (string, string, error) {
+    msg := step.Message
+    if msg == "" {
+        msg = fmt.Sprintf("playbook %s ran: %s", pb.Name, pb.Status.Current.Trigger)
+    }
+    r.Recorder.Eventf(q, corev1.EventTypeWarning, "PlaybookNotification", "playbook %s: %s", pb.Name, msg)
+
+    am, err := instanceAlertmanager(ctx, r, q)
+    if err != nil {
+        return qraiopv1.PlaybookFailed, "unable to find Alertmanager: " + err.Error(), nil
+    }
+    if am == nil {
+        return qraiopv1.PlaybookSucceeded, "recorded an event on " + q.Name, nil
+    }
+    now := time.Now()
+    if err := am.PostAlerts(ctx, []alerting.PostableAlert{{
+        Labels: map[string]string{
+            "alertname": "QraiopPlaybookNotification",
+            "namespace": pb.Namespace,
+            "instance":  q.Name,
+            "playbook":  pb.Name,
+            "severity":  "warning",
+        },
+        Annotations: map[string]string{"summary": msg},
+        StartsAt:    now,
+        EndsAt:      now.Add(notifyAlertLifetime),
+    }}); err != nil {
+        return qraiopv1.PlaybookFailed, "unable to send the alert: " + err.Error(), nil
+    }
+    return qraiopv1.PlaybookSucceeded, "sent an alert through Alertmanager", nil
+}
+
+// setPlaybookReplicas sets or, with nil replicas, removes the replicas a
+// playbook scaled a deployment of q to
+func (r *PlaybookReconciler) setPlaybookReplicas(ctx context.Context, q *qraiopv1.Qraiop, deployment string, replicas *int32) error {
+    overrides := playbookReplicas(q)
+    current, ok := overrides[deployment]
+    if (replicas == nil && !ok) || (replicas != nil && ok && current == *replicas) {
+        return nil
+    }
+    if replicas == nil {
+        delete(overrides, deployment)
+    } else {
+        overrides[deployment] = *replicas
+    }
+
+    patch := client.MergeFrom(q.DeepCopy())
+    if len(overrides) == 0 {
+        delete(q.Annotations, qraiopv1.PlaybookReplicasAnnotation)
+    } else {
+        pairs := make([]string, 0, len(overrides))
+        for name, n := range overrides {
+            pairs = append(pairs, name+"="+strconv.Itoa(int(n)))
+        }
+        sort.Strings(pairs)
+        if q.Annotations == nil {
+            q.Annotations = make(map[string]string)
+        }
+        q.Annotations[qraiopv1.PlaybookReplicasAnnotation] = strings.Join(pairs, ",")
+    }
+    return r.Patch(ctx, q, patch)
+}
+
+// playbookReplicas parses the replicas playbooks scaled q's deployments to,
+// skipping malformed pairs
+func playbookReplicas(q *qraiopv1.Qraiop) map[string]int32 {
+    overrides := map[string]int32{}
+    for _, pair := range strings.Split(q.Annotations[qraiopv1.PlaybookReplicasAnnotation], ",") {
+        name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+        if !ok {
+            continue
+        }
+        n, err := strconv.ParseInt(value, 10, 32)
+        if err != nil || n < 0 {
+            continue
+        }
+        overrides[name] = int32(n)
+    }
+    return overrides
+}
+
+func setPlaybookCondition(pb *qraiopv1.Playbook, status metav1.ConditionStatus, reason, message string) {
+    meta.SetStatusCondition(&pb.Status.Conditions, metav1.Condition{
+        Type:               conditionInstanceFound,
+        Status:             status,
+        Reason:             reason,
+        Message:            message,
+        ObservedGeneration: pb.Generation,
+    })
+}
+
+// playbooksForInstance maps a Qraiop instance to the playbooks it triggers
+func (r *PlaybookReconciler) playbooksForInstance(ctx context.Context, obj client.Object) []reconcile.Request {
+    var playbooks qraiopv1.PlaybookList
+    if err := r.List(ctx, &playbooks, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for _, pb := range playbooks.Items {
+        if pb.Spec.Instance == obj.GetName() {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pb)})
+        }
+    }
+    return requests
+}
+
+func (r *PlaybookReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        // Running steps are polled, not driven by the playbook's own
+        // status writes
+        For(&qraiopv1.Playbook{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+        Owns(&qraiopv1.ChaosExperiment{}).
+        Watches(&qraiopv1.Qraiop{}, handler.EnqueueRequestsFromMapFunc(r.playbooksForInstance)).
+        Complete(r)
+}
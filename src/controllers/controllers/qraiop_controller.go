@@ -3,57 +3,484 @@ package controllers
 
 import (
     "context"
+    "strings"
     "time"
 
     "github.com/go-logr/logr"
+    appsv1 "k8s.io/api/apps/v1"
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
     metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
     "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/client-go/rest"
     ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/builder"
     "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+    "sigs.k8s.io/controller-runtime/pkg/predicate"
 
     qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/compat"
+    "github.com/Bailey7220/QRAIOP/controllers/version"
 )
 
 type QraiopReconciler struct {
     client.Client
     Scheme *runtime.Scheme
     Log    logr.Logger
+    // Config is used to build impersonating clients
+    Config *rest.Config
+
+    // LeaderElectionNamespace and LeaderElectionID locate the manager's
+    // leader election Lease for the self-test
+    LeaderElectionNamespace string
+    LeaderElectionID        string
+
+    // OperatorNamespace holds the sealing key for qraiopsealed:// values
+    OperatorNamespace string
+
+    // Cluster is the detected cluster version and APIs; nil skips the
+    // compatibility check
+    Cluster *compat.Cluster
+
+    // ResyncPeriod is how often instances are reconciled without changes,
+    // 10 minutes by default. RequeueJitter is the fraction follow-up
+    // requeues are stretched by at most; 0 disables it.
+    ResyncPeriod  time.Duration
+    RequeueJitter float64
+
+    // DevMode runs the components as the mock servers of MockImage, for
+    // local development without the real component images
+    DevMode   bool
+    MockImage string
+
+    cryptoClients *cryptoClients
+    gate          *reconcileGate
+    keyUsage      *keyUsageTracker
+    addons        *addonRegistry
 }
 
 // +kubebuilder:rbac:groups=qraiop.io,resources=qraiops,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=qraiop.io,resources=qraiops/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosexperiments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiopsecuritypolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete;impersonate
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 func (r *QraiopReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
     log := r.Log.WithValues("qraiop", req.NamespacedName)
 
     var qraiop qraiopv1.Qraiop
     if err := r.Get(ctx, req.NamespacedName, &qraiop); err != nil {
+        if apierrors.IsNotFound(err) {
+            r.cryptoClients.remove(req.NamespacedName)
+            r.gate.forget(req.NamespacedName)
+            r.keyUsage.forget(req.NamespacedName)
+            r.addons.forget(req.NamespacedName)
+            return ctrl.Result{}, nil
+        }
         log.Error(err, "unable to fetch Qraiop")
-        return ctrl.Result{}, client.IgnoreNotFound(err)
+        return ctrl.Result{}, err
+    }
+
+    // Deletion goes ahead even when paused, once the children owner
+    // references don't cover are gone
+    if !qraiop.DeletionTimestamp.IsZero() {
+        return r.finalize(ctx, &qraiop)
+    }
+    if err := r.ensureFinalizer(ctx, &qraiop); err != nil {
+        log.Error(err, "unable to add finalizer")
+        return ctrl.Result{}, err
+    }
+
+    if wait := r.gate.delay(req.NamespacedName, qraiop.Generation); wait > 0 {
+        return ctrl.Result{RequeueAfter: wait}, nil
+    }
+
+    // Status changes are collected in memory and written once on return.
+    // qraiop is unsealed for rendering below; writes are built from the
+    // stored copy, so its plaintext is never sent.
+    stored := qraiop.DeepCopy()
+    ctx = withStored(ctx, stored)
+    defer r.patchStatus(ctx, &qraiop, stored)
+
+    if paused, reason := isPaused(&qraiop); paused {
+        log.Info("reconciliation paused", "reason", reason)
+        setCondition(&qraiop, conditionPaused, metav1.ConditionTrue, reason, "reconciliation is paused")
+        return ctrl.Result{}, nil
+    }
+    if meta.IsStatusConditionTrue(qraiop.Status.Conditions, conditionPaused) {
+        setCondition(&qraiop, conditionPaused, metav1.ConditionFalse, "Resumed", "reconciliation resumed")
+    }
+
+    if err := version.CheckComponentVersion(qraiop.Spec.ComponentVersion); err != nil {
+        log.Info("refusing to manage unsupported component version", "componentVersion", qraiop.Spec.ComponentVersion)
+        setCondition(&qraiop, conditionVersionSkew, metav1.ConditionTrue, "UnsupportedComponentVersion", err.Error())
+        block(&qraiop, err)
+        return ctrl.Result{}, nil
+    }
+    setCondition(&qraiop, conditionVersionSkew, metav1.ConditionFalse, "Supported",
+        "component version is supported by operator "+version.Version)
+
+    if r.Cluster != nil {
+        if err := r.Cluster.Check(); err != nil {
+            log.Info("refusing to manage instance on unsupported cluster", "reason", err.Error())
+            setCondition(&qraiop, conditionUnsupportedCluster, metav1.ConditionTrue, "BelowSupportFloor", err.Error())
+            block(&qraiop, err)
+            return ctrl.Result{}, nil
+        }
+        msg := "Kubernetes " + r.Cluster.Version.String() + " is supported"
+        if deprecated := r.Cluster.Deprecations(); len(deprecated) > 0 {
+            msg += "; falling back to deprecated " + strings.Join(deprecated, ", ")
+        }
+        setCondition(&qraiop, conditionUnsupportedCluster, metav1.ConditionFalse, "Supported", msg)
+    }
+
+    reportDeprecations(&qraiop)
+
+    // Nothing is rolled out without the security policies it must follow
+    if resolved, err := r.resolveSecurityPolicy(ctx, &qraiop); err != nil || !resolved {
+        return ctrl.Result{}, err
+    }
+    if unsealed, err := r.unsealSpec(ctx, &qraiop); err != nil || !unsealed {
+        return ctrl.Result{}, err
+    }
+    if err := r.loadAddons(ctx, &qraiop); err != nil {
+        log.Error(err, "unable to list addons")
+        return ctrl.Result{}, err
     }
 
     if qraiop.Status.Phase == "" {
         qraiop.Status.Phase = "Initializing"
         qraiop.Status.Components = make(map[string]qraiopv1.ComponentStatus)
-        qraiop.Status.LastUpdated = metav1.Now()
-        _ = r.Status().Update(ctx, &qraiop)
     }
 
-    // Example component readiness update
-    qraiop.Status.Components["cryptography"] = qraiopv1.ComponentStatus{
-        Status:      "Ready",
-        Message:     "OK",
-        LastUpdated: metav1.Now(),
+    // A spec whose rollout would not fit its budget is not applied at all
+    fits, err := r.checkBudget(ctx, &qraiop)
+    if err != nil {
+        log.Error(err, "unable to project the resource footprint")
+        return ctrl.Result{}, err
+    }
+    if !fits {
+        return ctrl.Result{RequeueAfter: planRefreshInterval}, nil
+    }
+
+    if !qraiop.Spec.ApprovalRequired {
+        clearPlan(&qraiop)
+        return r.apply(ctx, &qraiop)
+    }
+    approved, err := r.reviewPlan(ctx, &qraiop)
+    if err != nil {
+        log.Error(err, "unable to plan changes")
+        return ctrl.Result{}, err
+    }
+    if !approved {
+        // Keep the plan current while it waits for approval
+        return ctrl.Result{RequeueAfter: planRefreshInterval}, nil
+    }
+    return r.apply(ctx, &qraiop)
+}
+
+// block stops q from being rolled out for a reason only the user can fix
+func block(q *qraiopv1.Qraiop, err error) {
+    q.Status.Phase = "Blocked"
+    q.Status.Message = err.Error()
+    setCondition(q, conditionReady, metav1.ConditionFalse, "Blocked", err.Error())
+    q.Status.ObservedGeneration = q.Generation
+}
+
+// apply rolls out the components and everything else the instance renders
+func (r *QraiopReconciler) apply(ctx context.Context, q *qraiopv1.Qraiop) (ctrl.Result, error) {
+    log := r.Log.WithValues("qraiop", client.ObjectKeyFromObject(q))
+
+    upgrading := q.Status.OperatorVersion != version.Version
+    if upgrading {
+        if err := r.runUpgradeHooks(ctx, q, r.preUpgradeHooks()); err != nil {
+            log.Error(err, "pre-upgrade hook failed")
+            return ctrl.Result{}, err
+        }
+    }
+
+    // Every object written from here on is recorded for pruning
+    ctx, inv := withInventory(ctx)
+
+    // Secrets synced from external stores are in place before the alert
+    // channels and agents using them
+    var sourcesAfter time.Duration
+    if !r.planning() {
+        after, err := r.reconcileSecretSources(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to sync secret sources")
+            return ctrl.Result{}, err
+        }
+        sourcesAfter = after
+    }
+
+    if err := r.reconcileAlertChannels(ctx, q); err != nil {
+        log.Error(err, "unable to reconcile alert channels")
+        return ctrl.Result{}, err
+    }
+
+    tenant, err := r.tenantReconciler(ctx, q)
+    if err != nil {
+        log.Error(err, "unable to impersonate tenant service account")
+        return ctrl.Result{}, err
+    }
+
+    if err := r.ensureAgentRBAC(ctx, q); err != nil {
+        log.Error(err, "unable to set up agent RBAC")
+        return ctrl.Result{}, err
     }
 
-    qraiop.Status.Phase = "Ready"
-    qraiop.Status.LastUpdated = metav1.Now()
-    _ = r.Status().Update(ctx, &qraiop)
+    r.checkServiceMonitors(q)
+    ready, err := tenant.reconcileComponents(ctx, q)
+    if err != nil {
+        log.Error(err, "unable to reconcile components")
+        return ctrl.Result{}, err
+    }
+
+    if upgrading {
+        if err := r.finishUpgrade(ctx, q); err != nil {
+            log.Error(err, "post-upgrade hook failed")
+            return ctrl.Result{}, err
+        }
+    }
+
+    resync := resyncDelay(q.UID, r.resyncPeriod(), time.Now())
+    requeueAfter := resync
+    if sourcesAfter > 0 && sourcesAfter < requeueAfter {
+        requeueAfter = sourcesAfter
+    }
+    if after := smoothingRequeue(q); after > 0 && after < requeueAfter {
+        requeueAfter = after
+    }
+    if after := scheduleRequeue(q); after > 0 && after < requeueAfter {
+        requeueAfter = after
+    }
+    after, err := tenant.reconcileNetworkPolicies(ctx, q)
+    if err != nil {
+        log.Error(err, "unable to reconcile network policies")
+        return ctrl.Result{}, err
+    }
+    if after > 0 && after < requeueAfter {
+        requeueAfter = after
+    }
+    if err := tenant.reconcileSLORules(ctx, q); err != nil {
+        log.Error(err, "unable to apply SLO alert rules")
+        return ctrl.Result{}, err
+    }
+    if err := tenant.reconcileComplianceReport(ctx, q); err != nil {
+        log.Error(err, "unable to schedule compliance reports")
+        return ctrl.Result{}, err
+    }
+    setAdoptionCondition(q, inv)
+
+    // The self-test, Grafana alerting, silences, CA, key usage and SLO checks
+    // act outside the planned objects, so they only run for real
+    if !r.planning() {
+        after, err = r.reconcileSelfTest(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to run operator self-test")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        if err := r.reconcileGrafanaAlerting(ctx, q); err != nil {
+            log.Error(err, "unable to provision Grafana alerting")
+            return ctrl.Result{}, err
+        }
+
+        after, err = r.reconcileSilences(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to reconcile alert silences")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        after, err = r.reconcileAgentHeartbeats(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to read agent heartbeats")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        after, err = r.reconcileKeyRotation(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to rotate crypto keys")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        after, err = r.reconcileRevocation(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to publish revocation list")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        if err := r.reconcileCertAuthorities(ctx, q); err != nil {
+            log.Error(err, "unable to check certificate authorities")
+            return ctrl.Result{}, err
+        }
+
+        after, err = r.reconcileCertReissue(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to re-issue certificates")
+            return ctrl.Result{}, err
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        // A crypto service that can't report usage doesn't hold up the rest
+        after, err = r.reconcileKeyUsage(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to check crypto key usage")
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        if err := r.reconcileRegistryPolicy(ctx, q); err != nil {
+            log.Error(err, "unable to label namespaces for the registry policy")
+            return ctrl.Result{}, err
+        }
+
+        if err := r.reportAddons(ctx, q); err != nil {
+            log.Error(err, "unable to report addon status")
+        }
 
-    return ctrl.Result{RequeueAfter: time.Minute * 10}, nil
+        if err := r.reconcileResilience(ctx, q); err != nil {
+            log.Error(err, "unable to score chaos resilience")
+        }
+
+        after, err = r.reconcileCost(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to sample component cost")
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        after, err = r.reconcileCertExpiry(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to forecast certificate expiry")
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+
+        // An unreachable Prometheus leaves the budgets reported as unknown
+        after, err = r.reconcileSLOBudgets(ctx, q)
+        if err != nil {
+            log.Error(err, "unable to query SLO error budgets")
+        }
+        if after > 0 && after < requeueAfter {
+            requeueAfter = after
+        }
+    }
+
+    q.Status.Phase = "Ready"
+    if !ready {
+        q.Status.Phase = "Progressing"
+    }
+    for _, c := range q.Status.Components {
+        if c.Status == qraiopv1.ComponentDegraded {
+            q.Status.Phase = "Degraded"
+        }
+    }
+    if q.Status.Phase == "Ready" {
+        setCondition(q, conditionReady, metav1.ConditionTrue, "Ready", "all enabled components are ready")
+    } else {
+        setCondition(q, conditionReady, metav1.ConditionFalse, q.Status.Phase, "instance is "+q.Status.Phase)
+    }
+    q.Status.ObservedGeneration = q.Generation
+
+    if !r.planning() {
+        if err := r.trackDegradedPeriod(ctx, q); err != nil {
+            log.Error(err, "unable to file incident report")
+        }
+    }
+
+    if err := tenant.publishReadiness(ctx, q); err != nil {
+        log.Error(err, "unable to publish readiness marker")
+        markInventoryPartial(ctx)
+    }
+    if err := r.pruneInventory(ctx, q, inv); err != nil {
+        log.Error(err, "unable to prune objects no longer rendered")
+        return ctrl.Result{}, err
+    }
+    if !r.planning() {
+        if err := r.reconcileFootprint(ctx, q); err != nil {
+            log.Error(err, "unable to measure resource footprint")
+        }
+    }
+
+    if requeueAfter < resync {
+        requeueAfter = jitter(requeueAfter, r.RequeueJitter)
+        requeueDelays.WithLabelValues("followup").Observe(requeueAfter.Seconds())
+    } else {
+        requeueDelays.WithLabelValues("periodic").Observe(requeueAfter.Seconds())
+    }
+    return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// isPaused reports whether reconciliation of q is paused and why
+func isPaused(q *qraiopv1.Qraiop) (bool, string) {
+    if q.Spec.Paused {
+        return true, "SpecPaused"
+    }
+    if v, ok := q.Annotations[qraiopv1.PausedAnnotation]; ok && v != "false" {
+        return true, "AnnotationPaused"
+    }
+    return false, ""
 }
 
 func (r *QraiopReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    r.cryptoClients = newCryptoClients()
+    r.gate = newReconcileGate()
+    r.keyUsage = newKeyUsageTracker()
+    r.addons = newAddonRegistry()
+    if err := metrics.Registry.Register(&fleetCollector{reader: mgr.GetClient()}); err != nil {
+        return err
+    }
+    if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, podIPField, indexPodIP); err != nil {
+        return err
+    }
     return ctrl.NewControllerManagedBy(mgr).
-        For(&qraiopv1.Qraiop{}).
+        // The controller's own status writes don't need another reconcile
+        For(&qraiopv1.Qraiop{}, builder.WithPredicates(predicate.Or(
+            predicate.GenerationChangedPredicate{},
+            predicate.AnnotationChangedPredicate{},
+        ))).
+        Owns(&appsv1.Deployment{}).
+        Owns(&corev1.Service{}).
+        Owns(&networkingv1.NetworkPolicy{}).
+        Owns(&corev1.ConfigMap{}).
+        Owns(&batchv1.CronJob{}).
+        Watches(&qraiopv1.QraiopSecurityPolicy{}, handler.EnqueueRequestsFromMapFunc(r.instancesForPolicy)).
+        Watches(&qraiopv1.QraiopAddon{}, handler.EnqueueRequestsFromMapFunc(r.instanceForAddon)).
         Complete(r)
 }
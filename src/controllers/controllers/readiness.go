@@ -0,0 +1,53 @@
+// src/controllers/controllers/readiness.go
+package controllers
+
+import (
+    "context"
+    "strconv"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// publishReadiness writes the namespace's readiness marker ConfigMap, which
+// dependent workloads wait on before starting. It is ready once the
+// instance is Ready and the crypto service can issue certificates.
+func (r *QraiopReconciler) publishReadiness(ctx context.Context, q *qraiopv1.Qraiop) error {
+    crypto := q.Status.Components[qraiopv1.ComponentCryptography]
+    ready := q.Status.Phase == "Ready" &&
+        (!q.Spec.Cryptography.Enabled || crypto.Status == qraiopv1.ComponentReady)
+
+    data := map[string]string{
+        qraiopv1.ReadyMarkerKey: strconv.FormatBool(ready),
+        "phase":                 q.Status.Phase,
+        "instance":              q.Name,
+        "observedGeneration":    strconv.FormatInt(q.Generation, 10),
+        "cryptography":          crypto.Status,
+    }
+    if q.Spec.Cryptography.Enabled {
+        data["cryptoEndpoint"] = "http://qraiop-crypto." + q.Namespace + ".svc:8080"
+    }
+
+    var existing corev1.ConfigMap
+    if err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: qraiopv1.ReadyMarkerName}, &existing); err == nil &&
+        existing.Data[qraiopv1.ReadyMarkerKey] == data[qraiopv1.ReadyMarkerKey] {
+        // Keep the transition time of an unchanged marker
+        data["lastTransitionTime"] = existing.Data["lastTransitionTime"]
+    }
+    if data["lastTransitionTime"] == "" {
+        data["lastTransitionTime"] = metav1.Now().UTC().Format(time.RFC3339)
+    }
+
+    return r.createOrUpdate(ctx, q, &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      qraiopv1.ReadyMarkerName,
+            Namespace: q.Namespace,
+            Labels:    labelsForQraiop(q),
+        },
+        Data: data,
+    })
+}
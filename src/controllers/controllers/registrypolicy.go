@@ -0,0 +1,103 @@
+// src/controllers/controllers/registrypolicy.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const conditionRegistryPolicy = "RegistryPolicy"
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch
+
+// reconcileRegistryPolicy labels the namespaces under the registry
+// allowlist so the webhook checks their pods, and unlabels those that left
+// it. The webhook reads the allowlist itself from the instances.
+func (r *QraiopReconciler) reconcileRegistryPolicy(ctx context.Context, q *qraiopv1.Qraiop) error {
+    cfg := q.Spec.SecurityPolicies.Registries
+    want := make(map[string]bool)
+    if cfg != nil && cfg.Enabled {
+        want[q.Namespace] = true
+        for _, ns := range cfg.Namespaces {
+            want[ns] = true
+        }
+    }
+
+    var missing []string
+    for ns := range want {
+        found, err := r.labelRegistryNamespace(ctx, ns, true)
+        if err != nil {
+            return err
+        }
+        if !found {
+            missing = append(missing, ns)
+        }
+    }
+    for _, ns := range q.Status.RegistryPolicyNamespaces {
+        if want[ns] {
+            continue
+        }
+        if _, err := r.labelRegistryNamespace(ctx, ns, false); err != nil {
+            return err
+        }
+    }
+
+    namespaces := make([]string, 0, len(want))
+    for ns := range want {
+        namespaces = append(namespaces, ns)
+    }
+    sort.Strings(namespaces)
+    q.Status.RegistryPolicyNamespaces = namespaces
+    if len(want) == 0 {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionRegistryPolicy)
+        return nil
+    }
+    if len(missing) > 0 {
+        sort.Strings(missing)
+        setCondition(q, conditionRegistryPolicy, metav1.ConditionFalse, "NamespaceNotFound",
+            fmt.Sprintf("namespaces %v do not exist yet", missing))
+        return nil
+    }
+    mode := cfg.Mode
+    if mode == "" {
+        mode = qraiopv1.RegistryPolicyEnforce
+    }
+    setCondition(q, conditionRegistryPolicy, metav1.ConditionTrue, mode,
+        fmt.Sprintf("%d registries allowed in %d namespaces", len(cfg.Allowed), len(namespaces)))
+    return nil
+}
+
+// labelRegistryNamespace sets or removes the registry policy label of a
+// namespace. It reports whether the namespace exists.
+func (r *QraiopReconciler) labelRegistryNamespace(ctx context.Context, name string, label bool) (bool, error) {
+    var ns corev1.Namespace
+    err := r.Get(ctx, client.ObjectKey{Name: name}, &ns)
+    if apierrors.IsNotFound(err) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    if _, labelled := ns.Labels[qraiopv1.RegistryPolicyLabel]; labelled == label {
+        return true, nil
+    }
+    patch := client.MergeFrom(ns.DeepCopy())
+    if label {
+        if ns.Labels == nil {
+            ns.Labels = make(map[string]string)
+        }
+        ns.Labels[qraiopv1.RegistryPolicyLabel] = "true"
+    } else {
+        delete(ns.Labels, qraiopv1.RegistryPolicyLabel)
+    }
+    return true, r.Patch(ctx, &ns, patch)
+}
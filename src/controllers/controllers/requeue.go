@@ -0,0 +1,66 @@
+// src/controllers/controllers/requeue.go
+package controllers
+
+import (
+    "hash/fnv"
+    "math/rand"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+    defaultResyncPeriod = 10 * time.Minute
+    // maxRequeueJitter caps the stretch of long follow-up requeues
+    maxRequeueJitter = 30 * time.Second
+)
+
+var requeueDelays = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+    Name:    "qraiop_requeue_delay_seconds",
+    Help:    "Delay of the requeues the Qraiop controller schedules, by kind: periodic resyncs or follow-ups of pending work.",
+    Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 900},
+}, []string{"kind"})
+
+func init() {
+    metrics.Registry.MustRegister(requeueDelays)
+}
+
+// resyncDelay returns the delay until an instance's next periodic
+// reconcile. Each instance has a slot in the period at an offset derived
+// from its UID, so instances created together are spread over the period
+// instead of resyncing in lockstep. The delay is at least half a period,
+// so an event-driven reconcile just before the slot skips it.
+func resyncDelay(uid types.UID, period time.Duration, now time.Time) time.Duration {
+    h := fnv.New64a()
+    h.Write([]byte(uid))
+    offset := time.Duration(h.Sum64() % uint64(period))
+    elapsed := time.Duration(now.UnixNano() % int64(period))
+    wait := (offset - elapsed + period) % period
+    if wait < period/2 {
+        wait += period
+    }
+    return wait
+}
+
+// jitter stretches d by a random fraction of at most factor, and at most
+// maxRequeueJitter, so follow-ups of instances that hit the same condition
+// together don't stay together. Requeues are never brought forward.
+func jitter(d time.Duration, factor float64) time.Duration {
+    spread := time.Duration(float64(d) * factor)
+    if spread > maxRequeueJitter {
+        spread = maxRequeueJitter
+    }
+    if spread <= 0 {
+        return d
+    }
+    return d + time.Duration(rand.Int63n(int64(spread)))
+}
+
+func (r *QraiopReconciler) resyncPeriod() time.Duration {
+    if r.ResyncPeriod > 0 {
+        return r.ResyncPeriod
+    }
+    return defaultResyncPeriod
+}
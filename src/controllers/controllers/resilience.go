@@ -0,0 +1,39 @@
+// src/controllers/controllers/resilience.go
+package controllers
+
+import (
+    "context"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/report"
+)
+
+// reconcileResilience scores the chaos coverage of q's namespace into its
+// status. Experiments don't trigger a reconcile, so the score follows them
+// at the next resync.
+func (r *QraiopReconciler) reconcileResilience(ctx context.Context, q *qraiopv1.Qraiop) error {
+    var experiments qraiopv1.ChaosExperimentList
+    if err := r.List(ctx, &experiments); err != nil {
+        return err
+    }
+    for _, nc := range report.Resilience([]qraiopv1.Qraiop{*q}, experiments.Items, time.Now()) {
+        if nc.Namespace != q.Namespace {
+            continue
+        }
+        st := &qraiopv1.ResilienceStatus{
+            Score:       int32(nc.Score),
+            Experiments: int32(nc.Experiments),
+            Passed:      int32(nc.Passed),
+            Failed:      int32(nc.Failed),
+            FaultTypes:  nc.FaultTypes,
+        }
+        if nc.LastRun != nil {
+            st.LastRun = &metav1.Time{Time: *nc.LastRun}
+        }
+        q.Status.Resilience = st
+    }
+    return nil
+}
@@ -0,0 +1,315 @@
+// src/controllers/controllers/resources.go
+package controllers
+
+import (
+    "context"
+    "strconv"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/resource"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/intstr"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const imageRegistry = "ghcr.io/bailey7220"
+
+// fieldManager owns the fields the operator applies server-side
+const fieldManager = "qraiop-controller"
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func boolPtr(b bool) *bool { return &b }
+
+func int64Ptr(i int64) *int64 { return &i }
+
+// componentLabels are the labels of a component's objects and pods
+func componentLabels(q *qraiopv1.Qraiop, name string) map[string]string {
+    labels := labelsForQraiop(q)
+    labels["app.kubernetes.io/name"] = name
+    return labels
+}
+
+// componentSelector selects the pods of a component. It must never change
+// since Deployment selectors are immutable.
+func componentSelector(q *qraiopv1.Qraiop, name string) map[string]string {
+    return map[string]string{
+        "app.kubernetes.io/name":     name,
+        "app.kubernetes.io/instance": q.Name,
+    }
+}
+
+// profile holds the workload sizing of a deployment profile
+type profile struct {
+    replicas    int32
+    limits      corev1.ResourceList
+    requests    corev1.ResourceList
+    imageSuffix string
+}
+
+// profileFor returns the sizing for q's profile
+func profileFor(q *qraiopv1.Qraiop) profile {
+    if q.Spec.Profile == qraiopv1.ProfileEdge {
+        return profile{
+            replicas: 1,
+            limits: corev1.ResourceList{
+                corev1.ResourceCPU:    resource.MustParse("200m"),
+                corev1.ResourceMemory: resource.MustParse("128Mi"),
+            },
+            requests: corev1.ResourceList{
+                corev1.ResourceCPU:    resource.MustParse("25m"),
+                corev1.ResourceMemory: resource.MustParse("32Mi"),
+            },
+            imageSuffix: "-slim",
+        }
+    }
+    return profile{
+        replicas: 2,
+        limits: corev1.ResourceList{
+            corev1.ResourceCPU:    resource.MustParse("500m"),
+            corev1.ResourceMemory: resource.MustParse("512Mi"),
+        },
+        requests: corev1.ResourceList{
+            corev1.ResourceCPU:    resource.MustParse("100m"),
+            corev1.ResourceMemory: resource.MustParse("128Mi"),
+        },
+    }
+}
+
+// componentDeployment renders the Deployment of a component
+func componentDeployment(q *qraiopv1.Qraiop, name string, env []corev1.EnvVar) *appsv1.Deployment {
+    labels := componentLabels(q, name)
+    size := profileFor(q)
+    tag := "latest"
+    if q.Spec.ComponentVersion != "" {
+        tag = q.Spec.ComponentVersion
+    }
+    deployment := &appsv1.Deployment{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      name,
+            Namespace: q.Namespace,
+            Labels:    labels,
+        },
+        Spec: appsv1.DeploymentSpec{
+            Replicas: int32Ptr(size.replicas),
+            Selector: &metav1.LabelSelector{MatchLabels: componentSelector(q, name)},
+            Template: corev1.PodTemplateSpec{
+                // A map of its own, so pod labels stay off the Deployment
+                ObjectMeta: metav1.ObjectMeta{Labels: componentLabels(q, name)},
+                Spec: corev1.PodSpec{
+                    ServiceAccountName: name,
+                    SecurityContext:    &corev1.PodSecurityContext{RunAsNonRoot: boolPtr(true)},
+                    Containers: []corev1.Container{{
+                        Name:  strings.TrimPrefix(name, "qraiop-"),
+                        Image: imageRegistry + "/" + name + ":" + tag + size.imageSuffix,
+                        Ports: []corev1.ContainerPort{{
+                            Name:          "http",
+                            ContainerPort: 8080,
+                            Protocol:      corev1.ProtocolTCP,
+                        }},
+                        Env: env,
+                        Resources: corev1.ResourceRequirements{
+                            Limits:   size.limits,
+                            Requests: size.requests,
+                        },
+                        SecurityContext: &corev1.SecurityContext{
+                            AllowPrivilegeEscalation: boolPtr(false),
+                            ReadOnlyRootFilesystem:   boolPtr(true),
+                            Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+                        },
+                    }},
+                },
+            },
+        },
+    }
+    render.Proxy(&deployment.Spec.Template.Spec, q.Spec.Proxy)
+
+    profileSource, versionSource := render.Defaulted, render.Defaulted
+    if q.Spec.Profile != "" {
+        profileSource = "spec.profile"
+    }
+    if q.Spec.ComponentVersion != "" {
+        versionSource = "spec.componentVersion"
+    }
+    container := deployment.Spec.Template.Spec.Containers[0]
+    render.Explain(deployment, "spec.replicas", strconv.Itoa(int(size.replicas)), profileSource)
+    render.Explain(deployment, "spec.template.spec.containers[0].image", container.Image, versionSource)
+    render.Explain(deployment, "spec.template.spec.containers[0].resources", size.limits.Cpu().String()+" CPU, "+
+        size.limits.Memory().String()+" memory limit", profileSource)
+    for _, e := range env {
+        render.Explain(deployment, "spec.template.spec.containers[0].env["+e.Name+"]", e.Value, render.Defaulted)
+    }
+    render.ExplainProxy(deployment, q.Spec.Proxy)
+    return deployment
+}
+
+// componentSpecPath is where a component is configured in the instance spec
+func componentSpecPath(name string) string {
+    if pool := strings.TrimPrefix(name, qraiopv1.ComponentCryptography+"/"); pool != name {
+        return "spec.cryptography.pools[" + pool + "]"
+    }
+    if addon := strings.TrimPrefix(name, qraiopv1.AddonComponentPrefix); addon != name {
+        return "qraiopaddon/" + addon + ".spec"
+    }
+    return "spec." + name
+}
+
+// componentServiceAccount renders the ServiceAccount a component runs as
+func componentServiceAccount(q *qraiopv1.Qraiop, name string) *corev1.ServiceAccount {
+    return &corev1.ServiceAccount{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      name,
+            Namespace: q.Namespace,
+            Labels:    componentLabels(q, name),
+        },
+    }
+}
+
+// applyComponent writes a component's ServiceAccount, bound to its cloud
+// identity, its feature flags, its Deployment, its Service and the
+// ServiceMonitor scraping it
+func (r *QraiopReconciler) applyComponent(ctx context.Context, q *qraiopv1.Qraiop, name string, deployment *appsv1.Deployment, opts qraiopv1.ComponentOptions) error {
+    sa := componentServiceAccount(q, deployment.Name)
+    // Options of crypto pools are shared with the default crypto service
+    optsPath := componentSpecPath(strings.SplitN(name, "/", 2)[0])
+    render.ExplainOptions(deployment, optsPath, opts)
+    render.CloudIdentity(sa, &deployment.Spec.Template, opts.CloudIdentity)
+    render.Lifecycle(&deployment.Spec.Template.Spec, opts.Lifecycle)
+    if err := render.CommandLine(&deployment.Spec.Template.Spec, opts); err != nil {
+        return &invalidOptionsError{err}
+    }
+    if err := render.PodMetadata(&deployment.Spec.Template, opts); err != nil {
+        return &invalidOptionsError{err}
+    }
+    if err := render.DNS(&deployment.Spec.Template.Spec, opts); err != nil {
+        return &invalidOptionsError{err}
+    }
+    if err := render.Workload(&deployment.Spec.Template.Spec, opts.Deployment); err != nil {
+        return &invalidOptionsError{err}
+    }
+    render.Image(&deployment.Spec.Template.Spec, opts)
+    r.mockComponent(name, deployment)
+    exposeMetricsPort(q, name, deployment)
+    stampCryptoKey(q, name, deployment)
+    mountRevocationList(q, deployment)
+    // Last, so the clash checks see every container and volume of the pod
+    if err := render.Containers(&deployment.Spec.Template.Spec, opts); err != nil {
+        return &invalidOptionsError{err}
+    }
+    applySpecReplicas(deployment, opts, optsPath)
+    if err := r.applyScaling(ctx, deployment, opts, optsPath); err != nil {
+        return err
+    }
+    applyPlaybookReplicas(q, deployment)
+    if err := r.createOrUpdate(ctx, q, sa); err != nil {
+        return err
+    }
+    hash, err := r.applyFeatureFlags(ctx, q, deployment, opts)
+    if err != nil {
+        return err
+    }
+    if err := r.createOrUpdateDeployment(ctx, q, deployment); err != nil {
+        return err
+    }
+    if err := r.signalFeatureFlags(ctx, q, name, deployment, opts, hash); err != nil {
+        return err
+    }
+    // The Service carries the Deployment's labels, such as a crypto pool's,
+    // so consumers can find it by them
+    service := componentService(q, deployment.Name)
+    for k, v := range deployment.Labels {
+        service.Labels[k] = v
+    }
+    exposeMetricsServicePort(q, name, service)
+    if err := r.createOrUpdateService(ctx, q, service); err != nil {
+        return err
+    }
+    return r.applyServiceMonitor(ctx, q, name, service)
+}
+
+// invalidOptionsError is returned for component options only the user can
+// fix, so the component is reported Blocked instead of retried
+type invalidOptionsError struct {
+    err error
+}
+
+func (e *invalidOptionsError) Error() string { return "invalid options: " + e.err.Error() }
+
+func (e *invalidOptionsError) Unwrap() error { return e.err }
+
+// componentService renders the Service in front of a component
+func componentService(q *qraiopv1.Qraiop, name string) *corev1.Service {
+    return &corev1.Service{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:      name,
+            Namespace: q.Namespace,
+            Labels:    componentLabels(q, name),
+        },
+        Spec: corev1.ServiceSpec{
+            Selector: componentSelector(q, name),
+            Ports: []corev1.ServicePort{{
+                Name:       "http",
+                Port:       8080,
+                TargetPort: intstr.FromString("http"),
+                Protocol:   corev1.ProtocolTCP,
+            }},
+        },
+    }
+}
+
+func (r *QraiopReconciler) createOrUpdateDeployment(ctx context.Context, q *qraiopv1.Qraiop, deployment *appsv1.Deployment) error {
+    applyCommonMetadata(q, deployment)
+    applyCommonMetadata(q, &deployment.Spec.Template)
+    applyArgoCDMetadata(q, deployment, r.syncWave(q, deployment.Name))
+    if err := ctrl.SetControllerReference(q, deployment, r.Scheme); err != nil {
+        return err
+    }
+    return r.serverSideApply(ctx, q, deployment, &appsv1.Deployment{})
+}
+
+func (r *QraiopReconciler) createOrUpdateService(ctx context.Context, q *qraiopv1.Qraiop, service *corev1.Service) error {
+    applyCommonMetadata(q, service)
+    applyArgoCDMetadata(q, service, r.syncWave(q, service.Name))
+    if err := ctrl.SetControllerReference(q, service, r.Scheme); err != nil {
+        return err
+    }
+    return r.serverSideApply(ctx, q, service, &corev1.Service{})
+}
+
+// serverSideApply applies obj, owned by q, as the operator's field
+// manager, taking over any existing object the adoption policy lets it.
+// Fields obj leaves out, such as defaults, the cluster IP or replicas
+// managed by an autoscaler, are left to others; fields the operator stops
+// rendering are removed. existing is read into to check adoption.
+func (r *QraiopReconciler) serverSideApply(ctx context.Context, q *qraiopv1.Qraiop, obj, existing client.Object) error {
+    err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+    if err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    if err == nil {
+        if write, err := r.adoptable(ctx, q, existing); err != nil || !write {
+            return err
+        }
+    }
+    if err := r.recordInventory(ctx, obj); err != nil {
+        return err
+    }
+
+    // Apply requests carry their kind and must not pin a resource version
+    gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+    if err != nil {
+        return err
+    }
+    obj.GetObjectKind().SetGroupVersionKind(gvk)
+    obj.SetResourceVersion("")
+    obj.SetManagedFields(nil)
+    return r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
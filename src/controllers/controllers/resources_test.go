@@ -0,0 +1,30 @@
+// src/controllers/controllers/resources_test.go
+package controllers
+
+import (
+    "testing"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+func TestPodLabelsStayOnThePodTemplate(t *testing.T) {
+    q := &qraiopv1.Qraiop{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "team"}}
+    deployment := componentDeployment(q, "qraiop-crypto", nil)
+    opts := qraiopv1.ComponentOptions{PodLabels: map[string]string{"sidecar.istio.io/inject": "true"}}
+    if err := render.PodMetadata(&deployment.Spec.Template, opts); err != nil {
+        t.Fatal(err)
+    }
+
+    if deployment.Spec.Template.Labels["sidecar.istio.io/inject"] != "true" {
+        t.Errorf("pod template labels = %v, want the pod label", deployment.Spec.Template.Labels)
+    }
+    if _, ok := deployment.Labels["sidecar.istio.io/inject"]; ok {
+        t.Errorf("pod label leaked onto the Deployment: %v", deployment.Labels)
+    }
+    if deployment.Spec.Template.Labels["app.kubernetes.io/name"] != "qraiop-crypto" {
+        t.Errorf("pod template labels = %v, want the component labels", deployment.Spec.Template.Labels)
+    }
+}
@@ -0,0 +1,92 @@
+// src/controllers/controllers/restarts.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    defaultMaxRestarts = 5
+    defaultMaxOOMKills = 1
+)
+
+// componentRestarts aggregates restart analytics over the pods of a component
+func (r *QraiopReconciler) componentRestarts(ctx context.Context, q *qraiopv1.Qraiop, deployment string) (*qraiopv1.RestartStats, error) {
+    var pods corev1.PodList
+    if err := r.List(ctx, &pods,
+        client.InNamespace(q.Namespace),
+        client.MatchingLabels(componentSelector(q, deployment)),
+    ); err != nil {
+        return nil, err
+    }
+    return restartStats(pods.Items), nil
+}
+
+func restartStats(pods []corev1.Pod) *qraiopv1.RestartStats {
+    stats := &qraiopv1.RestartStats{}
+    for _, pod := range pods {
+        statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+        statuses = append(statuses, pod.Status.InitContainerStatuses...)
+        statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+        for _, cs := range statuses {
+            stats.TotalRestarts += cs.RestartCount
+            if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+                stats.CrashLooping++
+            }
+
+            term := cs.LastTerminationState.Terminated
+            if term == nil {
+                continue
+            }
+            if term.Reason == "OOMKilled" {
+                stats.OOMKills++
+            }
+            if stats.LastTerminationTime == nil || term.FinishedAt.After(stats.LastTerminationTime.Time) {
+                finished := term.FinishedAt
+                stats.LastTerminationTime = &finished
+                stats.LastTerminationReason = term.Reason
+                stats.LastTerminatedPod = pod.Name
+            }
+        }
+    }
+    return stats
+}
+
+// restartDegradation explains how stats breach the configured thresholds,
+// or returns "" when the component is healthy
+func restartDegradation(stats *qraiopv1.RestartStats, cfg qraiopv1.ComponentHealthConfig) string {
+    maxRestarts := cfg.MaxRestarts
+    if maxRestarts <= 0 {
+        maxRestarts = defaultMaxRestarts
+    }
+    maxOOMKills := cfg.MaxOOMKills
+    if maxOOMKills <= 0 {
+        maxOOMKills = defaultMaxOOMKills
+    }
+
+    var reasons []string
+    if stats.CrashLooping > 0 {
+        reasons = append(reasons, fmt.Sprintf("%d container(s) in CrashLoopBackOff", stats.CrashLooping))
+    }
+    if stats.OOMKills >= maxOOMKills {
+        reasons = append(reasons, fmt.Sprintf("%d OOM kill(s)", stats.OOMKills))
+    }
+    if stats.TotalRestarts >= maxRestarts {
+        reasons = append(reasons, fmt.Sprintf("%d restarts (threshold %d)", stats.TotalRestarts, maxRestarts))
+    }
+    if len(reasons) == 0 {
+        return ""
+    }
+    if stats.LastTerminationReason != "" {
+        reasons = append(reasons, fmt.Sprintf("last termination %s in %s", stats.LastTerminationReason, stats.LastTerminatedPod))
+    }
+    return strings.Join(reasons, "; ")
+}
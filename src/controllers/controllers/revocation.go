@@ -0,0 +1,290 @@
+// src/controllers/controllers/revocation.go
+package controllers
+
+import (
+    "context"
+    "crypto/x509"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "hash/fnv"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    conditionRevocation = "Revocation"
+
+    // crlConfigMap holds the published revocation list
+    crlConfigMap = "qraiop-crl"
+
+    // CRLNumberAnnotation carries the number of the latest revocation list
+    // on every component pod, so components reload it without waiting for
+    // the kubelet to sync the ConfigMap
+    CRLNumberAnnotation = "qraiop.io/crl-number"
+
+    defaultCRLUpdateInterval = 24 * time.Hour
+)
+
+// mountRevocationList mounts the revocation list into a component
+func mountRevocationList(q *qraiopv1.Qraiop, deployment *appsv1.Deployment) {
+    cfg := q.Spec.Cryptography.Revocation
+    if cfg == nil || !cfg.Enabled || !q.Spec.Cryptography.Enabled {
+        return
+    }
+    render.RevocationList(&deployment.Spec.Template.Spec, crlConfigMap)
+    render.Explain(deployment, "spec.template.spec.volumes[revocation-list]", crlConfigMap, "spec.cryptography.revocation")
+}
+
+// reconcileRevocation collects the revoked certificates, has the crypto
+// service sign a new list when they changed or the current one is due for
+// an update, publishes it and tells every component pod about it
+func (r *QraiopReconciler) reconcileRevocation(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.Cryptography.Revocation
+    if cfg == nil || !cfg.Enabled || !q.Spec.Cryptography.Enabled {
+        if q.Status.Revocation != nil {
+            cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: crlConfigMap, Namespace: q.Namespace}}
+            if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+                return 0, err
+            }
+        }
+        q.Status.Revocation = nil
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionRevocation)
+        return 0, nil
+    }
+    if q.Status.Components[qraiopv1.ComponentCryptography].Status != qraiopv1.ComponentReady {
+        return 0, nil
+    }
+    interval := defaultCRLUpdateInterval
+    if cfg.UpdateInterval.Duration > 0 {
+        interval = cfg.UpdateInterval.Duration
+    }
+
+    revoked, invalid, err := r.revokedCertificates(ctx, q)
+    if err != nil {
+        return 0, err
+    }
+    hash := revokedHash(revoked)
+    now := time.Now()
+    st := q.Status.Revocation
+    // Lists are re-signed a tenth of the interval before they go stale
+    pending := pendingCryptoOperation(q, qraiopv1.CryptoOperationSignRevocationList)
+    if st == nil || st.Hash != hash || now.After(st.NextUpdate.Add(-interval/10)) || pending != nil {
+        number := int64(1)
+        if st != nil {
+            number = st.Number + 1
+        }
+        // The service may have signed an interrupted list; a list with other
+        // entries must not reuse its number
+        if pending != nil {
+            if n, h, ok := parseCRLSubject(pending.Subject); ok && h != hash && n >= number {
+                number = n + 1
+            }
+        }
+        opCtx, op, err := r.beginCryptoOperation(ctx, q, qraiopv1.CryptoOperationSignRevocationList, fmt.Sprintf("%d/%s", number, hash))
+        if err != nil {
+            return 0, err
+        }
+        // A resumed list is sent exactly as first signed
+        thisUpdate := op.StartedAt.Time
+        list := cryptoclient.RevocationList{Number: number, ThisUpdate: thisUpdate, NextUpdate: thisUpdate.Add(interval)}
+        for _, e := range revoked {
+            list.Revoked = append(list.Revoked, cryptoclient.RevokedCertificate{
+                SerialNumber: e.SerialNumber, RevokedAt: e.RevokedAt.Time, Reason: e.Reason,
+            })
+        }
+        crl, err := r.cryptoClient(q).SignRevocationList(opCtx, list)
+        if err != nil {
+            failCryptoOperation(op, err)
+            setCondition(q, conditionRevocation, metav1.ConditionFalse, "SignFailed", err.Error())
+            return time.Minute, nil
+        }
+        if err := r.publishRevocationList(ctx, q, crl, revoked); err != nil {
+            return 0, err
+        }
+        r.Log.Info("published revocation list", "qraiop", client.ObjectKeyFromObject(q), "number", number, "revoked", len(revoked))
+        st = &qraiopv1.RevocationStatus{
+            Number:      number,
+            PublishedAt: metav1.Time{Time: now},
+            NextUpdate:  metav1.Time{Time: list.NextUpdate},
+            Revoked:     revoked,
+            Hash:        hash,
+        }
+        q.Status.Revocation = st
+        if err := r.finishCryptoOperation(ctx, q, qraiopv1.CryptoOperationSignRevocationList); err != nil {
+            return 0, err
+        }
+        st = q.Status.Revocation
+    }
+
+    if err := r.signalRevocationList(ctx, q, st.Number); err != nil {
+        return 0, err
+    }
+    msg := fmt.Sprintf("list %d with %d revoked certificates, next update %s",
+        st.Number, len(st.Revoked), st.NextUpdate.UTC().Format(time.RFC3339))
+    if len(invalid) > 0 {
+        setCondition(q, conditionRevocation, metav1.ConditionFalse, "InvalidSecrets",
+            msg+"; no certificate to revoke in "+strings.Join(invalid, ", "))
+    } else {
+        setCondition(q, conditionRevocation, metav1.ConditionTrue, "Published", msg)
+    }
+    return st.NextUpdate.Add(-interval / 10).Sub(now), nil
+}
+
+// revokedCertificates merges the spec's revocations with those of annotated
+// Secrets, keeping the revocation time of certificates already listed. It
+// also returns the annotated Secrets without a parseable certificate.
+func (r *QraiopReconciler) revokedCertificates(ctx context.Context, q *qraiopv1.Qraiop) ([]qraiopv1.RevokedEntry, []string, error) {
+    known := make(map[string]metav1.Time)
+    if st := q.Status.Revocation; st != nil {
+        for _, e := range st.Revoked {
+            known[e.SerialNumber] = e.RevokedAt
+        }
+    }
+    now := metav1.Now()
+    bySerial := make(map[string]qraiopv1.RevokedEntry)
+    add := func(serial, reason, source string) {
+        serial = normalizeSerial(serial)
+        if reason == "" || reason == "true" {
+            reason = qraiopv1.RevocationUnspecified
+        }
+        at, ok := known[serial]
+        if !ok {
+            at = now
+        }
+        if _, dup := bySerial[serial]; !dup {
+            bySerial[serial] = qraiopv1.RevokedEntry{SerialNumber: serial, Reason: reason, RevokedAt: at, Source: source}
+        }
+    }
+    for _, c := range q.Spec.Cryptography.Revocation.Revoked {
+        add(c.SerialNumber, c.Reason, "spec")
+    }
+
+    var secrets corev1.SecretList
+    if err := r.List(ctx, &secrets, client.InNamespace(q.Namespace)); err != nil {
+        return nil, nil, err
+    }
+    var invalid []string
+    for _, s := range secrets.Items {
+        reason, ok := s.Annotations[qraiopv1.RevokeAnnotation]
+        if !ok {
+            continue
+        }
+        block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+        if block == nil {
+            invalid = append(invalid, s.Name)
+            continue
+        }
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            invalid = append(invalid, s.Name)
+            continue
+        }
+        add(cert.SerialNumber.Text(16), reason, "secret/"+s.Name)
+    }
+
+    revoked := make([]qraiopv1.RevokedEntry, 0, len(bySerial))
+    for _, e := range bySerial {
+        revoked = append(revoked, e)
+    }
+    sort.Slice(revoked, func(i, j int) bool { return revoked[i].SerialNumber < revoked[j].SerialNumber })
+    return revoked, invalid, nil
+}
+
+// normalizeSerial lowercases a hex serial number and drops separators and
+// leading zeros
+func normalizeSerial(s string) string {
+    s = strings.ToLower(strings.ReplaceAll(s, ":", ""))
+    if t := strings.TrimLeft(s, "0"); t != "" {
+        return t
+    }
+    return "0"
+}
+
+// parseCRLSubject splits the subject of a signing operation into the
+// list's number and entries hash
+func parseCRLSubject(subject string) (int64, string, bool) {
+    number, hash, found := strings.Cut(subject, "/")
+    n, err := strconv.ParseInt(number, 10, 64)
+    return n, hash, found && err == nil
+}
+
+func revokedHash(revoked []qraiopv1.RevokedEntry) string {
+    h := fnv.New64a()
+    for _, e := range revoked {
+        fmt.Fprintf(h, "%s/%s;", e.SerialNumber, e.Reason)
+    }
+    return fmt.Sprintf("%x", h.Sum64())
+}
+
+// publishRevocationList writes the signed list to its ConfigMap. It is
+// published data rather than a rendered object, so it stays out of the
+// inventory and is removed when revocation is turned off.
+func (r *QraiopReconciler) publishRevocationList(ctx context.Context, q *qraiopv1.Qraiop, crl []byte, revoked []qraiopv1.RevokedEntry) error {
+    data, err := json.MarshalIndent(revoked, "", "  ")
+    if err != nil {
+        return err
+    }
+    cm := &corev1.ConfigMap{
+        ObjectMeta: metav1.ObjectMeta{Name: crlConfigMap, Namespace: q.Namespace, Labels: labelsForQraiop(q)},
+        Data:       map[string]string{render.CRLFile: string(crl), render.RevokedFile: string(data)},
+    }
+    applyCommonMetadata(q, cm)
+    if err := ctrl.SetControllerReference(q, cm, r.Scheme); err != nil {
+        return err
+    }
+    var existing corev1.ConfigMap
+    err = r.Get(ctx, client.ObjectKeyFromObject(cm), &existing)
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, cm)
+    }
+    if err != nil {
+        return err
+    }
+    cm.ResourceVersion = existing.ResourceVersion
+    return r.Update(ctx, cm)
+}
+
+// signalRevocationList stamps the list number on the running pods of every
+// enabled component that hasn't seen it yet
+func (r *QraiopReconciler) signalRevocationList(ctx context.Context, q *qraiopv1.Qraiop, number int64) error {
+    value := fmt.Sprintf("%d", number)
+    for _, c := range r.components(q) {
+        if !c.enabled {
+            continue
+        }
+        var pods corev1.PodList
+        if err := r.List(ctx, &pods, client.InNamespace(q.Namespace), client.MatchingLabels(componentSelector(q, c.deployment))); err != nil {
+            return err
+        }
+        for i := range pods.Items {
+            pod := &pods.Items[i]
+            if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || pod.Annotations[CRLNumberAnnotation] == value {
+                continue
+            }
+            patch := client.MergeFrom(pod.DeepCopy())
+            if pod.Annotations == nil {
+                pod.Annotations = make(map[string]string)
+            }
+            pod.Annotations[CRLNumberAnnotation] = value
+            if err := r.Patch(ctx, pod, patch); err != nil {
+                return fmt.Errorf("signalling revocation list to pod %s: %w", pod.Name, err)
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,249 @@
+// src/controllers/controllers/scaling.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    autoscalingv2 "k8s.io/api/autoscaling/v2"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+// activeSchedule returns the first schedule whose window is open at now,
+// or nil, and when the scheduled replicas next change
+func activeSchedule(schedules []qraiopv1.ScalingSchedule, now time.Time) (*qraiopv1.ScalingSchedule, time.Time, error) {
+    var active *qraiopv1.ScalingSchedule
+    var next time.Time
+    for i := range schedules {
+        s := &schedules[i]
+        start, end, ok, err := silenceWindow(qraiopv1.SilenceSchedule{
+            Days:      s.Days,
+            StartTime: s.StartTime,
+            Duration:  s.Duration,
+            TimeZone:  s.TimeZone,
+        }, now)
+        if err != nil {
+            return nil, time.Time{}, fmt.Errorf("schedule %s: %w", s.Name, err)
+        }
+        if !ok {
+            continue
+        }
+        boundary := start
+        if !start.After(now) {
+            boundary = end
+            if active == nil {
+                active = s
+            }
+        }
+        if next.IsZero() || boundary.Before(next) {
+            next = boundary
+        }
+    }
+    return active, next, nil
+}
+
+// scheduleRequeue returns when the next scaling schedule of q opens or
+// closes, or 0 without schedules
+func scheduleRequeue(q *qraiopv1.Qraiop) time.Duration {
+    now := time.Now()
+    var after time.Duration
+    for _, opts := range []qraiopv1.ComponentOptions{
+        q.Spec.Cryptography.ComponentOptions,
+        q.Spec.AIOrchestration.ComponentOptions,
+        q.Spec.ChaosEngineering.ComponentOptions,
+        q.Spec.Monitoring.ComponentOptions,
+    } {
+        _, next, err := activeSchedule(opts.Schedules, now)
+        if err != nil || next.IsZero() {
+            continue
+        }
+        // Land just past the boundary, inside the new window
+        wait := next.Sub(now) + time.Second
+        if after == 0 || wait < after {
+            after = wait
+        }
+    }
+    return after
+}
+
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;patch
+
+// applyScaling sets the replicas of a component from its active schedule.
+// A component scaled to zero while idle stays there. When a
+// HorizontalPodAutoscaler targets the component it owns the replicas: they
+// are left out of the applied Deployment and the schedule sets the
+// autoscaler's minReplicas instead, restored once no schedule is active.
+func (r *QraiopReconciler) applyScaling(ctx context.Context, deployment *appsv1.Deployment, opts qraiopv1.ComponentOptions, optsPath string) error {
+    schedule, _, err := activeSchedule(opts.Schedules, time.Now())
+    if err != nil {
+        return &invalidOptionsError{err}
+    }
+    idle := deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0
+
+    hpa, err := r.componentAutoscaler(ctx, deployment)
+    if err != nil {
+        return err
+    }
+    if hpa == nil {
+        if schedule != nil && !idle {
+            deployment.Spec.Replicas = int32Ptr(schedule.Replicas)
+            render.Explain(deployment, "spec.replicas", strconv.Itoa(int(schedule.Replicas)), optsPath+".schedules["+schedule.Name+"]")
+        }
+        return nil
+    }
+
+    if !idle {
+        // Applied, the replicas would be the operator's to force back on
+        // every reconcile
+        deployment.Spec.Replicas = nil
+        render.Explain(deployment, "spec.replicas", "", "horizontalpodautoscaler/"+hpa.Name)
+    }
+    // The autoscaler is not one of the planned objects
+    if r.planning() {
+        return nil
+    }
+    if !idle {
+        if err := r.handOverReplicas(ctx, deployment); err != nil {
+            return err
+        }
+    }
+    return r.scheduleAutoscaler(ctx, hpa, schedule)
+}
+
+// replicasHandover is the field manager the replicas of an autoscaled
+// Deployment are handed over to, until the autoscaler first scales it
+const replicasHandover = fieldManager + "-handover"
+
+// handOverReplicas releases the operator's ownership of the replicas of an
+// existing Deployment without resetting them. Dropped from the operator's
+// apply while it is their only manager, they would fall back to the
+// default of one; applied at their current count by another manager first,
+// they are kept, and the autoscaler takes them over when it next scales.
+func (r *QraiopReconciler) handOverReplicas(ctx context.Context, deployment *appsv1.Deployment) error {
+    var existing appsv1.Deployment
+    err := r.Get(ctx, client.ObjectKeyFromObject(deployment), &existing)
+    if apierrors.IsNotFound(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if existing.Spec.Replicas == nil || !managesReplicas(existing.ManagedFields, fieldManager) {
+        return nil
+    }
+    handover := &unstructured.Unstructured{}
+    handover.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+    handover.SetNamespace(existing.Namespace)
+    handover.SetName(existing.Name)
+    if err := unstructured.SetNestedField(handover.Object, int64(*existing.Spec.Replicas), "spec", "replicas"); err != nil {
+        return err
+    }
+    return r.Patch(ctx, handover, client.Apply, client.FieldOwner(replicasHandover))
+}
+
+// managesReplicas reports whether manager applied spec.replicas
+func managesReplicas(entries []metav1.ManagedFieldsEntry, manager string) bool {
+    for _, e := range entries {
+        if e.Manager != manager || e.Operation != metav1.ManagedFieldsOperationApply || e.FieldsV1 == nil {
+            continue
+        }
+        var fields struct {
+            Spec map[string]json.RawMessage `json:"f:spec"`
+        }
+        if err := json.Unmarshal(e.FieldsV1.Raw, &fields); err != nil {
+            continue
+        }
+        if _, ok := fields.Spec["f:replicas"]; ok {
+            return true
+        }
+    }
+    return false
+}
+
+// applySpecReplicas sets the replicas the component's options ask for over
+// the profile's. A component scaled to zero while idle stays there.
+func applySpecReplicas(deployment *appsv1.Deployment, opts qraiopv1.ComponentOptions, optsPath string) {
+    cfg := opts.Deployment
+    if cfg == nil || cfg.Replicas == nil || (deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0) {
+        return
+    }
+    deployment.Spec.Replicas = int32Ptr(*cfg.Replicas)
+    render.Explain(deployment, "spec.replicas", strconv.Itoa(int(*cfg.Replicas)), optsPath+".deployment.replicas")
+}
+
+// applyPlaybookReplicas keeps the replicas a playbook scaled a component
+// to, over those of the spec, its schedules and its autoscaler. A
+// component scaled to zero while idle stays there.
+func applyPlaybookReplicas(q *qraiopv1.Qraiop, deployment *appsv1.Deployment) {
+    replicas, ok := playbookReplicas(q)[deployment.Name]
+    if !ok || (deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0) {
+        return
+    }
+    deployment.Spec.Replicas = int32Ptr(replicas)
+    render.Explain(deployment, "spec.replicas", strconv.Itoa(int(replicas)), "metadata.annotations["+qraiopv1.PlaybookReplicasAnnotation+"]")
+}
+
+// componentAutoscaler returns the HorizontalPodAutoscaler targeting a
+// component's Deployment, or nil
+func (r *QraiopReconciler) componentAutoscaler(ctx context.Context, deployment *appsv1.Deployment) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+    var hpas autoscalingv2.HorizontalPodAutoscalerList
+    if err := r.List(ctx, &hpas, client.InNamespace(deployment.Namespace)); err != nil {
+        return nil, err
+    }
+    for i := range hpas.Items {
+        ref := hpas.Items[i].Spec.ScaleTargetRef
+        if ref.Kind == "Deployment" && ref.Name == deployment.Name {
+            return &hpas.Items[i], nil
+        }
+    }
+    return nil, nil
+}
+
+// scheduleAutoscaler sets an autoscaler's minReplicas to the schedule's
+// replicas, within its maxReplicas, keeping its own value in an
+// annotation, or restores that value without an active schedule
+func (r *QraiopReconciler) scheduleAutoscaler(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler, schedule *qraiopv1.ScalingSchedule) error {
+    orig := hpa.DeepCopy()
+    saved, scheduled := hpa.Annotations[qraiopv1.ScheduledMinReplicasAnnotation]
+    if schedule == nil {
+        if !scheduled {
+            return nil
+        }
+        hpa.Spec.MinReplicas = nil
+        if n, err := strconv.Atoi(saved); err == nil {
+            hpa.Spec.MinReplicas = int32Ptr(int32(n))
+        }
+        delete(hpa.Annotations, qraiopv1.ScheduledMinReplicasAnnotation)
+        return r.Patch(ctx, hpa, client.MergeFrom(orig))
+    }
+
+    if !scheduled {
+        own := int32(1)
+        if hpa.Spec.MinReplicas != nil {
+            own = *hpa.Spec.MinReplicas
+        }
+        if hpa.Annotations == nil {
+            hpa.Annotations = make(map[string]string)
+        }
+        hpa.Annotations[qraiopv1.ScheduledMinReplicasAnnotation] = strconv.Itoa(int(own))
+    }
+    want := schedule.Replicas
+    if want > hpa.Spec.MaxReplicas {
+        want = hpa.Spec.MaxReplicas
+    }
+    if scheduled && hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas == want {
+        return nil
+    }
+    hpa.Spec.MinReplicas = int32Ptr(want)
+    return r.Patch(ctx, hpa, client.MergeFrom(orig))
+}
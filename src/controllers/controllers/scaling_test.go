@@ -0,0 +1,71 @@
+// src/controllers/controllers/scaling_test.go
+package controllers
+
+import (
+    "context"
+    "testing"
+
+    "github.com/go-logr/logr"
+    appsv1 "k8s.io/api/apps/v1"
+    autoscalingv2 "k8s.io/api/autoscaling/v2"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+func TestAutoscaledReplicasAreNotApplied(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := clientgoscheme.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    meta := metav1.ObjectMeta{Name: "qraiop-crypto", Namespace: "qraiop"}
+    live := &appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(5)}}
+    hpa := &autoscalingv2.HorizontalPodAutoscaler{
+        ObjectMeta: metav1.ObjectMeta{Name: "crypto", Namespace: "qraiop"},
+        Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+            ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "qraiop-crypto"},
+            MaxReplicas:    10,
+        },
+    }
+    r := &QraiopReconciler{
+        Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(live, hpa).Build(),
+        Scheme: scheme,
+        Log:    logr.Discard(),
+    }
+
+    rendered := &appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)}}
+    if err := r.applyScaling(context.Background(), rendered, qraiopv1.ComponentOptions{}, "spec.cryptography"); err != nil {
+        t.Fatal(err)
+    }
+    if rendered.Spec.Replicas != nil {
+        t.Fatalf("replicas of an autoscaled Deployment are applied as %d", *rendered.Spec.Replicas)
+    }
+}
+
+func TestManagesReplicas(t *testing.T) {
+    entry := func(manager string, op metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+        return metav1.ManagedFieldsEntry{Manager: manager, Operation: op, FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)}}
+    }
+    replicas := `{"f:spec":{"f:replicas":{},"f:template":{}}}`
+    template := `{"f:spec":{"f:template":{}}}`
+
+    for _, tc := range []struct {
+        name    string
+        entries []metav1.ManagedFieldsEntry
+        want    bool
+    }{
+        {"applied", []metav1.ManagedFieldsEntry{entry(fieldManager, metav1.ManagedFieldsOperationApply, replicas)}, true},
+        {"not applied", []metav1.ManagedFieldsEntry{entry(fieldManager, metav1.ManagedFieldsOperationApply, template)}, false},
+        {"autoscaler", []metav1.ManagedFieldsEntry{
+            entry(fieldManager, metav1.ManagedFieldsOperationApply, template),
+            entry("kube-controller-manager", metav1.ManagedFieldsOperationUpdate, replicas),
+        }, false},
+    } {
+        if got := managesReplicas(tc.entries, fieldManager); got != tc.want {
+            t.Errorf("%s: managesReplicas = %v, want %v", tc.name, got, tc.want)
+        }
+    }
+}
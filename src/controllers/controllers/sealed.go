@@ -0,0 +1,120 @@
+// src/controllers/controllers/sealed.go
+package controllers
+
+import (
+    "context"
+    "crypto/rsa"
+    "fmt"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/sealed"
+)
+
+const conditionSealedValues = "SealedValues"
+
+// sealingKey returns the cluster's sealing key, generating it and
+// publishing its public half if needed
+func (r *QraiopReconciler) sealingKey(ctx context.Context) (*rsa.PrivateKey, error) {
+    key := client.ObjectKey{Namespace: r.OperatorNamespace, Name: sealed.KeyName}
+    var secret corev1.Secret
+    err := r.Get(ctx, key, &secret)
+    if apierrors.IsNotFound(err) {
+        priv, genErr := sealed.GenerateKey()
+        if genErr != nil {
+            return nil, genErr
+        }
+        secret = corev1.Secret{
+            ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+            Type:       corev1.SecretTypeOpaque,
+            Data:       map[string][]byte{sealed.PrivateKeyFile: sealed.EncodePrivateKey(priv)},
+        }
+        err = r.Create(ctx, &secret)
+        if apierrors.IsAlreadyExists(err) {
+            // Another replica won the race; use its key
+            err = r.Get(ctx, key, &secret)
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+    priv, err := sealed.ParsePrivateKey(secret.Data[sealed.PrivateKeyFile])
+    if err != nil {
+        return nil, fmt.Errorf("Secret %s: %w", key, err)
+    }
+    return priv, r.publishSealingKey(ctx, &priv.PublicKey)
+}
+
+// publishSealingKey keeps the public key readable by anyone who may seal
+// values, without granting access to the Secret
+func (r *QraiopReconciler) publishSealingKey(ctx context.Context, pub *rsa.PublicKey) error {
+    pem := string(sealed.EncodePublicKey(pub))
+    var cm corev1.ConfigMap
+    err := r.Get(ctx, client.ObjectKey{Namespace: r.OperatorNamespace, Name: sealed.KeyName}, &cm)
+    if apierrors.IsNotFound(err) {
+        cm = corev1.ConfigMap{
+            ObjectMeta: metav1.ObjectMeta{Namespace: r.OperatorNamespace, Name: sealed.KeyName},
+            Data:       map[string]string{sealed.PublicKeyFile: pem},
+        }
+        if err := r.Create(ctx, &cm); !apierrors.IsAlreadyExists(err) {
+            return err
+        }
+        return nil
+    }
+    if err != nil || cm.Data[sealed.PublicKeyFile] == pem {
+        return err
+    }
+    if cm.Data == nil {
+        cm.Data = make(map[string]string)
+    }
+    cm.Data[sealed.PublicKeyFile] = pem
+    return r.Update(ctx, &cm)
+}
+
+type storedKey struct{}
+
+// withStored returns a context carrying stored, the instance as read from
+// the API server with its values still sealed
+func withStored(ctx context.Context, stored *qraiopv1.Qraiop) context.Context {
+    return context.WithValue(ctx, storedKey{}, stored)
+}
+
+// storedFrom returns the stored copy of q carried by ctx, or q itself
+// outside a reconcile, where it is never unsealed
+func storedFrom(ctx context.Context, q *qraiopv1.Qraiop) *qraiopv1.Qraiop {
+    if stored, ok := ctx.Value(storedKey{}).(*qraiopv1.Qraiop); ok {
+        return stored
+    }
+    return q
+}
+
+// unsealSpec replaces, in memory only, every qraiopsealed:// value in the
+// spec of q with its plaintext, leaving q a copy to render from. Writes to
+// the instance are built from the stored object instead, see withStored,
+// so plaintext never reaches it. An instance with a value that can't be
+// unsealed is blocked and false returned.
+func (r *QraiopReconciler) unsealSpec(ctx context.Context, q *qraiopv1.Qraiop) (bool, error) {
+    // The key is made up front so values can be sealed before any are used
+    priv, err := r.sealingKey(ctx)
+    if err != nil {
+        return false, fmt.Errorf("loading sealing key: %w", err)
+    }
+    if !sealed.Contains(q.Spec) {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionSealedValues)
+        return true, nil
+    }
+    n, err := sealed.UnsealAll(priv, q.Namespace, &q.Spec)
+    if err != nil {
+        err = fmt.Errorf("unable to unseal %w", err)
+        setCondition(q, conditionSealedValues, metav1.ConditionFalse, "UnsealFailed", err.Error())
+        block(q, err)
+        return false, nil
+    }
+    setCondition(q, conditionSealedValues, metav1.ConditionTrue, "Unsealed", fmt.Sprintf("%d sealed values unsealed", n))
+    return true, nil
+}
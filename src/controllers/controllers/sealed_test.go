@@ -0,0 +1,137 @@
+// src/controllers/controllers/sealed_test.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+
+    "github.com/go-logr/logr"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/sealed"
+)
+
+func TestUnsealedValuesAreNeverWritten(t *testing.T) {
+    scheme := runtime.NewScheme()
+    if err := clientgoscheme.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    if err := qraiopv1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    priv, err := sealed.GenerateKey()
+    if err != nil {
+        t.Fatal(err)
+    }
+    const plaintext = "https://hooks.example.com/s3cr3t"
+    url, err := sealed.Seal(&priv.PublicKey, "team", plaintext)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    q := &qraiopv1.Qraiop{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "team"}}
+    // The inline token is migrated into a Secret, which patches the channels
+    q.Spec.Monitoring.Alerting = &qraiopv1.AlertingConfig{Channels: []qraiopv1.AlertChannel{{
+        Name:   "pager",
+        Type:   "webhook",
+        Config: map[string]string{"url": url, "token": "inline-token"},
+    }}}
+    q.Status.Phase = "Running"
+    key := &corev1.Secret{
+        ObjectMeta: metav1.ObjectMeta{Namespace: "qraiop-system", Name: sealed.KeyName},
+        Data:       map[string][]byte{sealed.PrivateKeyFile: sealed.EncodePrivateKey(priv)},
+    }
+
+    var sent []string
+    record := func(obj client.Object, patch client.Patch) {
+        if _, ok := obj.(*qraiopv1.Qraiop); !ok {
+            return
+        }
+        var data []byte
+        var err error
+        if patch != nil {
+            data, err = patch.Data(obj)
+        } else {
+            data, err = json.Marshal(obj)
+        }
+        if err != nil {
+            t.Fatal(err)
+        }
+        sent = append(sent, string(data))
+    }
+    c := interceptor.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(q, key).WithStatusSubresource(q).Build(), interceptor.Funcs{
+        Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+            record(obj, nil)
+            return c.Update(ctx, obj, opts...)
+        },
+        Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+            record(obj, patch)
+            return c.Patch(ctx, obj, patch, opts...)
+        },
+        SubResourceUpdate: func(ctx context.Context, c client.Client, sub string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+            record(obj, nil)
+            return c.SubResource(sub).Update(ctx, obj, opts...)
+        },
+        SubResourcePatch: func(ctx context.Context, c client.Client, sub string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+            record(obj, patch)
+            return c.SubResource(sub).Patch(ctx, obj, patch, opts...)
+        },
+    })
+    r := &QraiopReconciler{Client: c, Scheme: scheme, Log: logr.Discard(), OperatorNamespace: "qraiop-system"}
+
+    // As Reconcile does: unseal, write the spec and the status
+    ctx := context.Background()
+    var inst qraiopv1.Qraiop
+    if err := c.Get(ctx, client.ObjectKeyFromObject(q), &inst); err != nil {
+        t.Fatal(err)
+    }
+    stored := inst.DeepCopy()
+    ctx = withStored(ctx, stored)
+    if unsealed, err := r.unsealSpec(ctx, &inst); err != nil || !unsealed {
+        t.Fatalf("unsealSpec = %v, %v", unsealed, err)
+    }
+    if err := r.reconcileAlertChannels(ctx, &inst); err != nil {
+        t.Fatal(err)
+    }
+    inst.Status.CryptoOperations = []qraiopv1.CryptoOperation{{Type: qraiopv1.CryptoOperationRotateKeys, Subject: "k1"}}
+    if err := r.persistStatusField(ctx, &inst, "cryptoOperations", inst.Status.CryptoOperations); err != nil {
+        t.Fatal(err)
+    }
+    r.patchStatus(ctx, &inst, stored)
+
+    if len(sent) < 3 {
+        t.Fatalf("expected the spec patch and two status writes, got %d writes", len(sent))
+    }
+    for _, body := range sent {
+        if strings.Contains(body, plaintext) {
+            t.Errorf("unsealed value sent to the API server: %s", body)
+        }
+    }
+
+    if got := inst.Spec.Monitoring.Alerting.Channels[0]; got.Config["url"] != plaintext || got.SecretRef == nil {
+        t.Errorf("rendered channel = %+v, want the unsealed url and the generated Secret", got)
+    }
+    var saved qraiopv1.Qraiop
+    if err := c.Get(ctx, client.ObjectKeyFromObject(q), &saved); err != nil {
+        t.Fatal(err)
+    }
+    ch := saved.Spec.Monitoring.Alerting.Channels[0]
+    if !sealed.IsSealed(ch.Config["url"]) {
+        t.Errorf("stored url = %q, want it still sealed", ch.Config["url"])
+    }
+    if _, ok := ch.Config["token"]; ok || ch.SecretRef == nil {
+        t.Errorf("stored channel = %+v, want the token moved into a Secret", ch)
+    }
+    if saved.Status.Phase != "Running" || len(saved.Status.Conditions) == 0 {
+        t.Errorf("stored status = %+v, want the conditions of the reconcile", saved.Status)
+    }
+}
@@ -0,0 +1,376 @@
+// src/controllers/controllers/secretsources.go
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+    "github.com/Bailey7220/QRAIOP/controllers/secretstore"
+)
+
+const (
+    conditionSecretSources = "SecretSourcesSynced"
+    // secretSourceAnnotation records where a synced Secret comes from
+    secretSourceAnnotation = "qraiop.io/secret-source"
+    // secretSourceRetry is how soon a failed sync is retried
+    secretSourceRetry = time.Minute
+    // llmCredentialsHashAnnotation rolls the agents when their synced
+    // credentials change, since environment variables aren't reloaded
+    llmCredentialsHashAnnotation = "qraiop.io/llm-credentials-hash"
+)
+
+// sourcedSecret is a SecretSource of an instance and the Secret it is
+// synced to
+type sourcedSecret struct {
+    secret string
+    source *qraiopv1.SecretSource
+}
+
+func llmCredentialsSecret(q *qraiopv1.Qraiop) string {
+    return q.Name + "-llm-credentials"
+}
+
+// channelSourceSecret is the Secret the SecretSource of a channel is synced to
+func channelSourceSecret(q *qraiopv1.Qraiop, channel string) string {
+    return channelSecretName(q, channel) + "-synced"
+}
+
+// secretSources lists the SecretSources of q
+func secretSources(q *qraiopv1.Qraiop) []sourcedSecret {
+    var sources []sourcedSecret
+    if ai := q.Spec.AIOrchestration; ai.Enabled && ai.CredentialsSource != nil {
+        sources = append(sources, sourcedSecret{secret: llmCredentialsSecret(q), source: ai.CredentialsSource})
+    }
+    if cfg := q.Spec.Monitoring.Alerting; cfg != nil {
+        for _, ch := range cfg.Channels {
+            if ch.SecretSource != nil {
+                sources = append(sources, sourcedSecret{secret: channelSourceSecret(q, ch.Name), source: ch.SecretSource})
+            }
+        }
+    }
+    return sources
+}
+
+// describeSource names what a SecretSource reads
+func describeSource(src *qraiopv1.SecretSource) string {
+    switch {
+    case src.Vault != nil:
+        mount := src.Vault.Mount
+        if mount == "" {
+            mount = "secret"
+        }
+        return "vault:" + strings.Trim(mount, "/") + "/" + strings.Trim(src.Vault.Path, "/")
+    case src.AWS != nil:
+        return src.AWS.ARN
+    }
+    return ""
+}
+
+func refreshInterval(src *qraiopv1.SecretSource) time.Duration {
+    if src.RefreshInterval != nil && src.RefreshInterval.Duration > 0 {
+        return src.RefreshInterval.Duration
+    }
+    return time.Hour
+}
+
+// reconcileSecretSources syncs the Secrets of q's SecretSources once their
+// refresh interval has passed, or right away when the source changed or
+// the Secret is gone. A failed sync leaves the Secret as last synced, and
+// Secrets of sources since removed are deleted.
+func (r *QraiopReconciler) reconcileSecretSources(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    previous := make(map[string]qraiopv1.SecretSourceStatus, len(q.Status.SecretSources))
+    for _, st := range q.Status.SecretSources {
+        previous[st.Secret] = st
+    }
+    sources := secretSources(q)
+
+    now := time.Now()
+    var (
+        next     time.Duration
+        problems []string
+        statuses []qraiopv1.SecretSourceStatus
+    )
+    for _, s := range sources {
+        st, ok := previous[s.secret]
+        delete(previous, s.secret)
+        if !ok || st.Source != describeSource(s.source) {
+            st = qraiopv1.SecretSourceStatus{Secret: s.secret, Source: describeSource(s.source)}
+        }
+        due, err := r.secretSyncDue(ctx, q, st, refreshInterval(s.source), now)
+        if err != nil {
+            return 0, err
+        }
+        if due <= 0 {
+            if err := r.syncSecret(ctx, q, s); err != nil {
+                st.Message = err.Error()
+                due = secretSourceRetry
+            } else {
+                st.Message = ""
+                st.SyncedAt = &metav1.Time{Time: now}
+                due = refreshInterval(s.source)
+            }
+            st.Keys, err = r.syncedKeys(ctx, q, s.secret)
+            if err != nil {
+                return 0, err
+            }
+        }
+        if st.Message != "" {
+            problems = append(problems, s.secret+": "+st.Message)
+        }
+        if next == 0 || due < next {
+            next = due
+        }
+        statuses = append(statuses, st)
+    }
+
+    for name := range previous {
+        if err := r.deleteSyncedSecret(ctx, q, name); err != nil {
+            return 0, fmt.Errorf("deleting Secret %s of a removed secret source: %w", name, err)
+        }
+    }
+
+    q.Status.SecretSources = statuses
+    switch {
+    case len(sources) == 0:
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionSecretSources)
+    case len(problems) > 0:
+        sort.Strings(problems)
+        setCondition(q, conditionSecretSources, metav1.ConditionFalse, "SyncFailed", strings.Join(problems, "; "))
+    default:
+        setCondition(q, conditionSecretSources, metav1.ConditionTrue, "Synced",
+            fmt.Sprintf("%d secret source(s) synced", len(sources)))
+    }
+    return next, nil
+}
+
+// secretSyncDue returns how long until the Secret of st is synced again;
+// 0 or less means now
+func (r *QraiopReconciler) secretSyncDue(ctx context.Context, q *qraiopv1.Qraiop, st qraiopv1.SecretSourceStatus, interval time.Duration, now time.Time) (time.Duration, error) {
+    // Failed syncs are retried on every reconcile, which comes at least
+    // every secretSourceRetry
+    if st.SyncedAt == nil || st.Message != "" {
+        return 0, nil
+    }
+    var secret corev1.Secret
+    err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: st.Secret}, &secret)
+    if apierrors.IsNotFound(err) {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, err
+    }
+    return st.SyncedAt.Add(interval).Sub(now), nil
+}
+
+// secretStore returns the client of the store a SecretSource reads from
+func secretStore(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop, src *qraiopv1.SecretSource) (secretstore.Store, error) {
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case src.Vault != nil:
+        token, err := secretKey(ctx, c, q.Namespace, src.Vault.TokenSecret)
+        if err != nil {
+            return nil, fmt.Errorf("reading Vault token: %w", err)
+        }
+        mount := src.Vault.Mount
+        if mount == "" {
+            mount = "secret"
+        }
+        return &secretstore.Vault{
+            URL:       src.Vault.URL,
+            Namespace: src.Vault.Namespace,
+            Mount:     mount,
+            Path:      src.Vault.Path,
+            Token:     token,
+            HTTP:      httpClient,
+        }, nil
+    case src.AWS != nil:
+        // Credentials always come from the instance's namespace, so nobody
+        // reads secrets with the operator's identity
+        ref := src.AWS.CredentialsSecret
+        if ref == nil {
+            return nil, errors.New("no AWS credentials: set credentialsSecret")
+        }
+        var secret corev1.Secret
+        if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: ref.Name}, &secret); err != nil {
+            return nil, fmt.Errorf("reading AWS credentials: %w", err)
+        }
+        store := &secretstore.AWS{
+            ARN:             src.AWS.ARN,
+            Region:          src.AWS.Region,
+            Key:             src.AWS.Key,
+            AccessKeyID:     strings.TrimSpace(string(secret.Data["accessKeyID"])),
+            SecretAccessKey: strings.TrimSpace(string(secret.Data["secretAccessKey"])),
+            SessionToken:    strings.TrimSpace(string(secret.Data["sessionToken"])),
+            HTTP:            httpClient,
+        }
+        if store.AccessKeyID == "" || store.SecretAccessKey == "" {
+            return nil, fmt.Errorf("Secret %s holds no accessKeyID and secretAccessKey", ref.Name)
+        }
+        return store, nil
+    }
+    return nil, errors.New("secret source sets neither vault nor aws")
+}
+
+// syncSecret reads a SecretSource and writes what it holds to its Secret,
+// owned by q. The Secret isn't part of the inventory, which is rebuilt on
+// every reconcile, while the Secret is only written once per interval.
+func (r *QraiopReconciler) syncSecret(ctx context.Context, q *qraiopv1.Qraiop, s sourcedSecret) error {
+    store, err := secretStore(ctx, r, q, s.source)
+    if err != nil {
+        return err
+    }
+    data, err := store.Fetch(ctx)
+    if err != nil {
+        return err
+    }
+    if len(data) == 0 {
+        return fmt.Errorf("%s holds no keys", describeSource(s.source))
+    }
+
+    secret := &corev1.Secret{
+        ObjectMeta: metav1.ObjectMeta{
+            Name:        s.secret,
+            Namespace:   q.Namespace,
+            Labels:      labelsForQraiop(q),
+            Annotations: map[string]string{secretSourceAnnotation: describeSource(s.source)},
+        },
+        Type: corev1.SecretTypeOpaque,
+        Data: data,
+    }
+    applyCommonMetadata(q, secret)
+    if err := ctrl.SetControllerReference(q, secret, r.Scheme); err != nil {
+        return err
+    }
+
+    var existing corev1.Secret
+    err = r.Get(ctx, client.ObjectKeyFromObject(secret), &existing)
+    if apierrors.IsNotFound(err) {
+        return r.Create(ctx, secret)
+    }
+    if err != nil {
+        return err
+    }
+    if !metav1.IsControlledBy(&existing, q) {
+        return fmt.Errorf("Secret %s exists and isn't synced by this instance", s.secret)
+    }
+    if sameData(existing.Data, data) && existing.Annotations[secretSourceAnnotation] == describeSource(s.source) {
+        return nil
+    }
+    secret.ResourceVersion = existing.ResourceVersion
+    if err := r.Update(ctx, secret); err != nil {
+        return err
+    }
+    r.Log.Info("synced secret from external store", "qraiop", client.ObjectKeyFromObject(q), "secret", s.secret, "source", describeSource(s.source))
+    return nil
+}
+
+// mountLLMCredentials gives the agents the keys of their synced
+// credentials as environment variables
+func (r *QraiopReconciler) mountLLMCredentials(ctx context.Context, q *qraiopv1.Qraiop, deployment *appsv1.Deployment) error {
+    name := llmCredentialsSecret(q)
+    var secret corev1.Secret
+    err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret)
+    if err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    // Until the first sync the pods wait for the Secret to show up
+    template := &deployment.Spec.Template
+    container := &template.Spec.Containers[0]
+    container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+        SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+    })
+    render.Explain(deployment, "spec.template.spec.containers[0].envFrom[secretRef]", name, "spec.aiOrchestration.credentialsSource")
+    if len(secret.Data) > 0 {
+        if template.Annotations == nil {
+            template.Annotations = make(map[string]string)
+        }
+        template.Annotations[llmCredentialsHashAnnotation] = dataHash(secret.Data)
+    }
+    return nil
+}
+
+func dataHash(data map[string][]byte) string {
+    keys := make([]string, 0, len(data))
+    for k := range data {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    h := sha256.New()
+    for _, k := range keys {
+        h.Write([]byte(k))
+        h.Write([]byte{0})
+        h.Write(data[k])
+        h.Write([]byte{0})
+    }
+    return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func sameData(a, b map[string][]byte) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for k, v := range a {
+        if w, ok := b[k]; !ok || !bytes.Equal(v, w) {
+            return false
+        }
+    }
+    return true
+}
+
+// syncedKeys returns the keys of a synced Secret, if it exists
+func (r *QraiopReconciler) syncedKeys(ctx context.Context, q *qraiopv1.Qraiop, name string) ([]string, error) {
+    var secret corev1.Secret
+    err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret)
+    if apierrors.IsNotFound(err) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    keys := make([]string, 0, len(secret.Data))
+    for k := range secret.Data {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys, nil
+}
+
+// deleteSyncedSecret deletes a Secret q synced, leaving Secrets of the
+// same name it doesn't control alone
+func (r *QraiopReconciler) deleteSyncedSecret(ctx context.Context, q *qraiopv1.Qraiop, name string) error {
+    var secret corev1.Secret
+    err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: name}, &secret)
+    if apierrors.IsNotFound(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if !metav1.IsControlledBy(&secret, q) {
+        return nil
+    }
+    if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    return nil
+}
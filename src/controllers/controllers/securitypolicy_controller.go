@@ -0,0 +1,152 @@
+// src/controllers/controllers/securitypolicy_controller.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+
+    "github.com/go-logr/logr"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/handler"
+    "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionSecurityPolicy = "SecurityPolicy"
+    conditionAccepted       = "Accepted"
+)
+
+// QraiopSecurityPolicyReconciler validates security policies and records
+// which instances use them. The Qraiop controller applies them.
+type QraiopSecurityPolicyReconciler struct {
+    client.Client
+    Scheme *runtime.Scheme
+    Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiopsecuritypolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiopsecuritypolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops,verbs=get;list;watch
+func (r *QraiopSecurityPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+    var policy qraiopv1.QraiopSecurityPolicy
+    if err := r.Get(ctx, req.NamespacedName, &policy); err != nil {
+        return ctrl.Result{}, client.IgnoreNotFound(err)
+    }
+
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances, client.InNamespace(policy.Namespace)); err != nil {
+        return ctrl.Result{}, err
+    }
+    policy.Status.Instances = nil
+    for _, q := range instances.Items {
+        if ref := q.Spec.SecurityPolicyRef; ref != nil && ref.Name == policy.Name {
+            policy.Status.Instances = append(policy.Status.Instances, q.Name)
+        }
+    }
+    sort.Strings(policy.Status.Instances)
+
+    condition := metav1.Condition{
+        Type:               conditionAccepted,
+        Status:             metav1.ConditionTrue,
+        Reason:             "Valid",
+        Message:            fmt.Sprintf("used by %d instance(s)", len(policy.Status.Instances)),
+        ObservedGeneration: policy.Generation,
+    }
+    if err := validateSecurityConfig(policy.Spec.SecurityConfig); err != nil {
+        condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "Invalid", err.Error()
+    }
+    meta.SetStatusCondition(&policy.Status.Conditions, condition)
+    policy.Status.ObservedGeneration = policy.Generation
+    return ctrl.Result{}, r.Status().Update(ctx, &policy)
+}
+
+// validateSecurityConfig checks what the CRD schema can't
+func validateSecurityConfig(cfg qraiopv1.SecurityConfig) error {
+    if l := cfg.NetworkPolicies.Learning; l != nil && l.Enabled && l.Duration.Duration < 0 {
+        return fmt.Errorf("networkPolicies.learning.duration must not be negative")
+    }
+    if reg := cfg.Registries; reg != nil && reg.Enabled {
+        if len(reg.Allowed) == 0 {
+            return fmt.Errorf("registries.allowed must list at least one registry")
+        }
+        for _, a := range reg.Allowed {
+            if strings.TrimSpace(a) == "" || strings.Contains(a, "://") {
+                return fmt.Errorf("registries.allowed: %q is not a registry", a)
+            }
+        }
+    }
+    return nil
+}
+
+// policiesForInstance maps a Qraiop to the security policy it references,
+// so the policy's list of instances follows
+func (r *QraiopSecurityPolicyReconciler) policiesForInstance(ctx context.Context, obj client.Object) []reconcile.Request {
+    q, ok := obj.(*qraiopv1.Qraiop)
+    if !ok || q.Spec.SecurityPolicyRef == nil {
+        return nil
+    }
+    return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: q.Namespace, Name: q.Spec.SecurityPolicyRef.Name}}}
+}
+
+func (r *QraiopSecurityPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewControllerManagedBy(mgr).
+        For(&qraiopv1.QraiopSecurityPolicy{}).
+        Watches(&qraiopv1.Qraiop{}, handler.EnqueueRequestsFromMapFunc(r.policiesForInstance)).
+        Complete(r)
+}
+
+// resolveSecurityPolicy replaces the inline security policies of q, in
+// memory only, with those of the QraiopSecurityPolicy it references. An
+// instance whose policy is missing or invalid is blocked and false returned.
+func (r *QraiopReconciler) resolveSecurityPolicy(ctx context.Context, q *qraiopv1.Qraiop) (bool, error) {
+    ref := q.Spec.SecurityPolicyRef
+    if ref == nil {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionSecurityPolicy)
+        return true, nil
+    }
+    var policy qraiopv1.QraiopSecurityPolicy
+    err := r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: ref.Name}, &policy)
+    if apierrors.IsNotFound(err) {
+        err = fmt.Errorf("QraiopSecurityPolicy %s not found", ref.Name)
+        setCondition(q, conditionSecurityPolicy, metav1.ConditionFalse, "NotFound", err.Error())
+        block(q, err)
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    if err := validateSecurityConfig(policy.Spec.SecurityConfig); err != nil {
+        err = fmt.Errorf("QraiopSecurityPolicy %s is invalid: %w", ref.Name, err)
+        setCondition(q, conditionSecurityPolicy, metav1.ConditionFalse, "Invalid", err.Error())
+        block(q, err)
+        return false, nil
+    }
+    q.Spec.SecurityPolicies = policy.Spec.SecurityConfig
+    setCondition(q, conditionSecurityPolicy, metav1.ConditionTrue, "Resolved",
+        fmt.Sprintf("using QraiopSecurityPolicy %s generation %d", policy.Name, policy.Generation))
+    return true, nil
+}
+
+// instancesForPolicy maps a QraiopSecurityPolicy to the instances using it
+func (r *QraiopReconciler) instancesForPolicy(ctx context.Context, obj client.Object) []reconcile.Request {
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances, client.InNamespace(obj.GetNamespace())); err != nil {
+        return nil
+    }
+    var requests []reconcile.Request
+    for _, q := range instances.Items {
+        if ref := q.Spec.SecurityPolicyRef; ref != nil && ref.Name == obj.GetName() {
+            requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&q)})
+        }
+    }
+    return requests
+}
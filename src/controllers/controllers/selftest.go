@@ -0,0 +1,225 @@
+// src/controllers/controllers/selftest.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    coordinationv1 "k8s.io/api/coordination/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/wait"
+    "k8s.io/client-go/util/retry"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    conditionSelfTest = "SelfTest"
+
+    selfTestStepThrottling = "APIThrottling"
+    selfTestStepFailover   = "LeaderFailover"
+
+    defaultSelfTestInterval = 168 * time.Hour
+    defaultFailoverTimeout  = 2 * time.Minute
+    // throttledRequests is how many requests are rejected with 429 during the throttling step
+    throttledRequests = 3
+)
+
+// throttleBackoff is how the operator retries requests the API server sheds
+var throttleBackoff = wait.Backoff{Duration: 200 * time.Millisecond, Factor: 2, Jitter: 0.1, Steps: 6}
+
+// reconcileSelfTest runs the operator self-test when it is due or has been
+// triggered through the self-test annotation. The failover step kills the
+// current leader, which is normally this process, so progress is persisted
+// in status and verified by whichever replica takes over.
+func (r *QraiopReconciler) reconcileSelfTest(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    cfg := q.Spec.SelfTest
+    st := q.Status.SelfTest
+    if st != nil && st.Phase == qraiopv1.SelfTestRunning {
+        return r.verifyFailover(ctx, q)
+    }
+
+    interval := defaultSelfTestInterval
+    if cfg != nil && cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    trigger := q.Annotations[qraiopv1.SelfTestAnnotation]
+    triggered := trigger != "" && (st == nil || st.Trigger != trigger)
+
+    var untilDue time.Duration
+    due := false
+    if cfg != nil && cfg.Enabled {
+        if st == nil || st.CompletionTime == nil {
+            due = true
+        } else if untilDue = interval - time.Since(st.CompletionTime.Time); untilDue <= 0 {
+            due = true
+        }
+    }
+    if !due && !triggered {
+        return untilDue, nil
+    }
+
+    now := metav1.Now()
+    st = &qraiopv1.SelfTestStatus{Phase: qraiopv1.SelfTestRunning, Trigger: trigger, StartTime: &now}
+    q.Status.SelfTest = st
+    setCondition(q, conditionSelfTest, metav1.ConditionFalse, qraiopv1.SelfTestRunning, "operator self-test in progress")
+
+    st.Results = append(st.Results, r.selfTestThrottling(ctx, q))
+
+    lease, err := r.leaderLease(ctx)
+    if err != nil {
+        return 0, err
+    }
+    if lease == nil {
+        st.Results = append(st.Results, qraiopv1.SelfTestResult{
+            Name: selfTestStepFailover, Passed: true,
+            Message: "skipped: leader election is disabled",
+        })
+        finishSelfTest(q)
+        return 0, nil
+    }
+
+    holder := ""
+    if lease.Spec.HolderIdentity != nil {
+        holder = *lease.Spec.HolderIdentity
+    }
+    stepStart := metav1.Now()
+    st.Step = selfTestStepFailover
+    st.LeaderBefore = holder
+    st.StepStartTime = &stepStart
+    // Persist before killing the leader, which is most likely this process
+    // and would never get to the deferred patch
+    if err := r.persistStatusField(ctx, q, "selfTest", q.Status.SelfTest); err != nil {
+        return 0, err
+    }
+
+    // Leader identities are "<pod name>_<uuid>"
+    leader := &corev1.Pod{}
+    leader.Namespace = r.LeaderElectionNamespace
+    leader.Name = strings.SplitN(holder, "_", 2)[0]
+    if err := r.Delete(ctx, leader); err != nil {
+        st.Results = append(st.Results, qraiopv1.SelfTestResult{
+            Name: selfTestStepFailover, Passed: false,
+            Message: fmt.Sprintf("unable to kill leader pod %s: %v", leader.Name, err),
+        })
+        finishSelfTest(q)
+        return 0, nil
+    }
+    return 5 * time.Second, nil
+}
+
+// verifyFailover checks that another replica took over the leader lease
+func (r *QraiopReconciler) verifyFailover(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    st := q.Status.SelfTest
+    timeout := defaultFailoverTimeout
+    if q.Spec.SelfTest != nil && q.Spec.SelfTest.FailoverTimeout.Duration > 0 {
+        timeout = q.Spec.SelfTest.FailoverTimeout.Duration
+    }
+
+    lease, err := r.leaderLease(ctx)
+    if err != nil {
+        return 0, err
+    }
+    holder := ""
+    if lease != nil && lease.Spec.HolderIdentity != nil {
+        holder = *lease.Spec.HolderIdentity
+    }
+
+    elapsed := time.Since(st.StepStartTime.Time).Round(time.Second)
+    switch {
+    case holder != "" && holder != st.LeaderBefore:
+        st.Results = append(st.Results, qraiopv1.SelfTestResult{
+            Name: selfTestStepFailover, Passed: true,
+            Message: fmt.Sprintf("leadership moved from %s to %s within %s", st.LeaderBefore, holder, elapsed),
+        })
+    case elapsed > timeout:
+        st.Results = append(st.Results, qraiopv1.SelfTestResult{
+            Name: selfTestStepFailover, Passed: false,
+            Message: fmt.Sprintf("no new leader %s after killing %s", elapsed, st.LeaderBefore),
+        })
+    default:
+        return 5 * time.Second, nil
+    }
+    finishSelfTest(q)
+    return 0, nil
+}
+
+// selfTestThrottling checks that the operator's reads recover when the API
+// server sheds load with 429 responses
+func (r *QraiopReconciler) selfTestThrottling(ctx context.Context, q *qraiopv1.Qraiop) qraiopv1.SelfTestResult {
+    throttled := &throttledClient{Client: r.Client, remaining: throttledRequests}
+    start := time.Now()
+    err := retry.OnError(throttleBackoff, apierrors.IsTooManyRequests, func() error {
+        var current qraiopv1.Qraiop
+        return throttled.Get(ctx, client.ObjectKeyFromObject(q), &current)
+    })
+    if err != nil {
+        return qraiopv1.SelfTestResult{
+            Name: selfTestStepThrottling, Passed: false,
+            Message: fmt.Sprintf("read did not recover from throttling: %v", err),
+        }
+    }
+    return qraiopv1.SelfTestResult{
+        Name: selfTestStepThrottling, Passed: true,
+        Message: fmt.Sprintf("recovered from %d throttled requests in %s", throttledRequests, time.Since(start).Round(time.Millisecond)),
+    }
+}
+
+// leaderLease returns the manager's leader election Lease, or nil when
+// leader election isn't in use
+func (r *QraiopReconciler) leaderLease(ctx context.Context) (*coordinationv1.Lease, error) {
+    if r.LeaderElectionID == "" {
+        return nil, nil
+    }
+    var lease coordinationv1.Lease
+    err := r.Get(ctx, client.ObjectKey{Namespace: r.LeaderElectionNamespace, Name: r.LeaderElectionID}, &lease)
+    if apierrors.IsNotFound(err) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &lease, nil
+}
+
+func finishSelfTest(q *qraiopv1.Qraiop) {
+    st := q.Status.SelfTest
+    now := metav1.Now()
+    st.Step = ""
+    st.CompletionTime = &now
+
+    var failed []string
+    for _, res := range st.Results {
+        if !res.Passed {
+            failed = append(failed, res.Name+": "+res.Message)
+        }
+    }
+    if len(failed) > 0 {
+        st.Phase = qraiopv1.SelfTestFailed
+        setCondition(q, conditionSelfTest, metav1.ConditionFalse, qraiopv1.SelfTestFailed, strings.Join(failed, "; "))
+        return
+    }
+    st.Phase = qraiopv1.SelfTestPassed
+    setCondition(q, conditionSelfTest, metav1.ConditionTrue, qraiopv1.SelfTestPassed,
+        fmt.Sprintf("%d self-test steps passed", len(st.Results)))
+}
+
+// throttledClient rejects its first requests with 429, like an API server
+// shedding load through priority and fairness
+type throttledClient struct {
+    client.Client
+    remaining int32
+}
+
+func (c *throttledClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+    if atomic.AddInt32(&c.remaining, -1) >= 0 {
+        return apierrors.NewTooManyRequests("self-test throttling", 1)
+    }
+    return c.Client.Get(ctx, key, obj, opts...)
+}
@@ -0,0 +1,148 @@
+// src/controllers/controllers/servicemonitor.go
+package controllers
+
+import (
+    "context"
+    "strconv"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    "k8s.io/apimachinery/pkg/util/intstr"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const conditionServiceMonitors = "ServiceMonitors"
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete
+
+// checkServiceMonitors records whether ServiceMonitors can be created. Without
+// the Prometheus Operator CRDs they are skipped instead of failing every
+// component.
+func (r *QraiopReconciler) checkServiceMonitors(q *qraiopv1.Qraiop) {
+    cfg := q.Spec.Monitoring.Prometheus
+    if cfg == nil || !cfg.ServiceMonitors {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionServiceMonitors)
+        return
+    }
+    kinds := []schema.GroupVersionKind{render.ServiceMonitorGVK}
+    for _, c := range cfg.Components {
+        if c.PodMonitor {
+            kinds = append(kinds, render.PodMonitorGVK)
+            break
+        }
+    }
+    for _, gvk := range kinds {
+        if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+            setCondition(q, conditionServiceMonitors, metav1.ConditionFalse, "CRDMissing",
+                gvk.Kind+" is not served by the cluster; install the Prometheus Operator CRDs")
+            return
+        }
+    }
+    msg := "components are scraped over HTTP"
+    if cfg.TLS {
+        msg = "components are scraped over HTTPS"
+    }
+    if cfg.BearerTokenSecret != nil {
+        msg += " with bearer token from Secret " + cfg.BearerTokenSecret.Name
+    }
+    setCondition(q, conditionServiceMonitors, metav1.ConditionTrue, "Available", msg)
+}
+
+// componentScrape returns the scrape override of a component, if any
+func componentScrape(q *qraiopv1.Qraiop, component string) (*qraiopv1.ComponentScrape, int) {
+    cfg := q.Spec.Monitoring.Prometheus
+    if cfg == nil {
+        return nil, -1
+    }
+    for i := range cfg.Components {
+        if cfg.Components[i].Component == component {
+            return &cfg.Components[i], i
+        }
+    }
+    return nil, -1
+}
+
+// exposeMetricsPort adds the metrics container port of a component serving
+// metrics apart from its API
+func exposeMetricsPort(q *qraiopv1.Qraiop, component string, deployment *appsv1.Deployment) {
+    scrape, i := componentScrape(q, component)
+    if scrape == nil || scrape.Port == 0 {
+        return
+    }
+    containers := deployment.Spec.Template.Spec.Containers
+    containers[0].Ports = append(containers[0].Ports, corev1.ContainerPort{
+        Name:          render.MetricsPortName,
+        ContainerPort: scrape.Port,
+        Protocol:      corev1.ProtocolTCP,
+    })
+    render.Explain(deployment, "spec.template.spec.containers[0].ports["+render.MetricsPortName+"]",
+        strconv.Itoa(int(scrape.Port)), "spec.monitoring.prometheus.components["+strconv.Itoa(i)+"].port")
+}
+
+// exposeMetricsServicePort adds the Service port in front of the metrics port
+func exposeMetricsServicePort(q *qraiopv1.Qraiop, component string, service *corev1.Service) {
+    scrape, _ := componentScrape(q, component)
+    if scrape == nil || scrape.Port == 0 {
+        return
+    }
+    service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+        Name:       render.MetricsPortName,
+        Port:       scrape.Port,
+        TargetPort: intstr.FromString(render.MetricsPortName),
+        Protocol:   corev1.ProtocolTCP,
+    })
+}
+
+// applyServiceMonitor writes the ServiceMonitor scraping a component's
+// Service, or the PodMonitor scraping its pods when the component asks for one
+func (r *QraiopReconciler) applyServiceMonitor(ctx context.Context, q *qraiopv1.Qraiop, component string, service *corev1.Service) error {
+    if !meta.IsStatusConditionTrue(q.Status.Conditions, conditionServiceMonitors) {
+        return nil
+    }
+    target := render.ScrapeTarget{
+        Instance:  q.Name,
+        Component: component,
+        Service:   service.Name,
+        Namespace: service.Namespace,
+        Labels:    service.Labels,
+        Selector:  componentSelector(q, service.Name),
+    }
+    cfg := q.Spec.Monitoring.Prometheus
+    scrape, i := componentScrape(q, component)
+    if scrape != nil {
+        target.Path = scrape.Path
+        if scrape.Port != 0 {
+            target.Port = render.MetricsPortName
+        }
+    }
+
+    sm, endpoint := render.ServiceMonitor(target, cfg), "spec.endpoints[0]"
+    if scrape != nil && scrape.PodMonitor {
+        sm, endpoint = render.PodMonitor(target, cfg), "spec.podMetricsEndpoints[0]"
+    }
+    if scrape != nil {
+        from := "spec.monitoring.prometheus.components[" + strconv.Itoa(i) + "]"
+        if scrape.Path != "" {
+            render.Explain(sm, endpoint+".path", scrape.Path, from+".path")
+        }
+        if scrape.Port != 0 {
+            render.Explain(sm, endpoint+".port", render.MetricsPortName, from+".port")
+        }
+    }
+    if cfg.TLS {
+        render.Explain(sm, endpoint+".tlsConfig", service.Name+"-tls", "spec.monitoring.prometheus.tls")
+    }
+    if cfg.BearerTokenSecret != nil {
+        render.Explain(sm, endpoint+".authorization", cfg.BearerTokenSecret.Name, "spec.monitoring.prometheus.bearerTokenSecret")
+    }
+    if len(cfg.RelabelConfigs) > 0 {
+        render.Explain(sm, endpoint+".relabelings", strconv.Itoa(len(cfg.RelabelConfigs))+" rules", "spec.monitoring.prometheus.relabelConfigs")
+    }
+    return r.createOrUpdate(ctx, q, sm)
+}
@@ -0,0 +1,189 @@
+// src/controllers/controllers/silences.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "strings"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+var weekdays = map[qraiopv1.Weekday]time.Weekday{
+    "Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+    "Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+// reconcileSilences keeps an Alertmanager silence in place for the current
+// or next window of each configured silence, and expires the silences of
+// entries removed from the spec. The returned duration is when the earliest
+// window ends and the next one has to be created.
+func (r *QraiopReconciler) reconcileSilences(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    var silences []qraiopv1.SilenceConfig
+    if cfg := q.Spec.Monitoring.Alerting; cfg != nil && cfg.Enabled {
+        silences = cfg.Silences
+    }
+    if len(silences) == 0 && len(q.Status.Silences) == 0 {
+        return 0, nil
+    }
+    am, err := instanceAlertmanager(ctx, r, q)
+    if err != nil {
+        if usesGrafana(q) {
+            setCondition(q, conditionSilences, metav1.ConditionFalse, "NoAlertmanager", err.Error())
+            return 0, nil
+        }
+        return 0, err
+    }
+    if am == nil {
+        setCondition(q, conditionSilences, metav1.ConditionFalse, "NoAlertmanager", "monitoring.alerting.alertmanagerURL is not set")
+        return 0, nil
+    }
+
+    current := make(map[string]qraiopv1.SilenceStatus)
+    for _, s := range q.Status.Silences {
+        current[s.Name] = s
+    }
+
+    now := time.Now()
+    var statuses []qraiopv1.SilenceStatus
+    var failed []string
+    var requeueAfter time.Duration
+    for _, s := range silences {
+        cur, had := current[s.Name]
+        delete(current, s.Name)
+
+        start, end, ok, err := silenceWindow(s.Schedule, now)
+        if err != nil {
+            failed = append(failed, fmt.Sprintf("%s: %v", s.Name, err))
+            continue
+        }
+        if !ok {
+            // No window left; an old silence simply runs out
+            continue
+        }
+        if after := end.Sub(now); requeueAfter == 0 || after < requeueAfter {
+            requeueAfter = after
+        }
+
+        hash := silenceHash(s, start, end)
+        if had && cur.Hash == hash {
+            statuses = append(statuses, cur)
+            continue
+        }
+        silence := alerting.Silence{
+            Matchers:  silenceMatchers(s.Matchers),
+            StartsAt:  start,
+            EndsAt:    end,
+            CreatedBy: alerting.CreatedBy,
+            Comment:   fmt.Sprintf("[qraiop %s/%s] %s", q.Namespace, q.Name, s.Comment),
+        }
+        if had && cur.EndsAt.After(now) {
+            silence.ID = cur.ID
+        }
+        id, err := am.CreateSilence(ctx, silence)
+        if err != nil {
+            failed = append(failed, fmt.Sprintf("%s: %v", s.Name, err))
+            if had {
+                statuses = append(statuses, cur)
+            }
+            continue
+        }
+        statuses = append(statuses, qraiopv1.SilenceStatus{
+            Name:     s.Name,
+            ID:       id,
+            StartsAt: metav1.NewTime(start),
+            EndsAt:   metav1.NewTime(end),
+            Hash:     hash,
+        })
+    }
+
+    for _, removed := range current {
+        if !removed.EndsAt.After(now) {
+            continue
+        }
+        if err := am.ExpireSilence(ctx, removed.ID); err != nil {
+            failed = append(failed, fmt.Sprintf("%s: %v", removed.Name, err))
+            statuses = append(statuses, removed)
+        }
+    }
+
+    q.Status.Silences = statuses
+    if len(failed) > 0 {
+        setCondition(q, conditionSilences, metav1.ConditionFalse, "SyncFailed", strings.Join(failed, "; "))
+        return time.Minute, nil
+    }
+    setCondition(q, conditionSilences, metav1.ConditionTrue, "Synced",
+        fmt.Sprintf("%d silences in place", len(statuses)))
+    return requeueAfter, nil
+}
+
+// silenceWindow returns the current or next window of a schedule, and
+// false when the schedule has no window left
+func silenceWindow(s qraiopv1.SilenceSchedule, now time.Time) (time.Time, time.Time, bool, error) {
+    if s.EndsAt != nil {
+        if !s.EndsAt.After(now) {
+            return time.Time{}, time.Time{}, false, nil
+        }
+        start := now
+        if s.StartsAt != nil {
+            start = s.StartsAt.Time
+        }
+        return start, s.EndsAt.Time, true, nil
+    }
+
+    if len(s.Days) == 0 || s.StartTime == "" || s.Duration.Duration <= 0 {
+        return time.Time{}, time.Time{}, false, fmt.Errorf("schedule needs endsAt, or days, startTime and duration")
+    }
+    loc := time.UTC
+    if s.TimeZone != "" {
+        var err error
+        if loc, err = time.LoadLocation(s.TimeZone); err != nil {
+            return time.Time{}, time.Time{}, false, err
+        }
+    }
+    clock, err := time.Parse("15:04", s.StartTime)
+    if err != nil {
+        return time.Time{}, time.Time{}, false, fmt.Errorf("invalid startTime %q", s.StartTime)
+    }
+    days := make(map[time.Weekday]bool)
+    for _, d := range s.Days {
+        days[weekdays[d]] = true
+    }
+
+    // Start a day back for windows that span midnight
+    local := now.In(loc)
+    for d := -1; d <= 7; d++ {
+        day := local.AddDate(0, 0, d)
+        if !days[day.Weekday()] {
+            continue
+        }
+        start := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+        end := start.Add(s.Duration.Duration)
+        if end.After(now) {
+            return start, end, true, nil
+        }
+    }
+    return time.Time{}, time.Time{}, false, nil
+}
+
+func silenceMatchers(matchers []qraiopv1.SilenceMatcher) []alerting.Matcher {
+    out := make([]alerting.Matcher, 0, len(matchers))
+    for _, m := range matchers {
+        out = append(out, alerting.Matcher{Name: m.Name, Value: m.Value, IsRegex: m.IsRegex, IsEqual: !m.NotEqual})
+    }
+    return out
+}
+
+func silenceHash(s qraiopv1.SilenceConfig, start, end time.Time) string {
+    h := fnv.New64a()
+    b, _ := json.Marshal(s)
+    h.Write(b)
+    fmt.Fprintf(h, "%d-%d", start.Unix(), end.Unix())
+    return fmt.Sprintf("%x", h.Sum64())
+}
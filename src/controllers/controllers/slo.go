@@ -0,0 +1,196 @@
+// src/controllers/controllers/slo.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/render"
+)
+
+const (
+    conditionSLOs = "SLOs"
+
+    // sloEvaluationInterval is how often error budgets are queried
+    sloEvaluationInterval = 5 * time.Minute
+)
+
+var sloErrorBudget = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+    Name: "qraiop_slo_error_budget_remaining_ratio",
+    Help: "Fraction of an SLO's error budget left over its period, negative once overspent.",
+}, []string{"namespace", "owner", "slo"})
+
+func init() {
+    metrics.Registry.MustRegister(sloErrorBudget)
+}
+
+// reconcileSLORules writes the PrometheusRule alerting on the burn rate of
+// q's SLOs. Without SLOs it is no longer written and is pruned.
+func (r *QraiopReconciler) reconcileSLORules(ctx context.Context, q *qraiopv1.Qraiop) error {
+    slos := q.Spec.Monitoring.SLOs
+    if len(slos) == 0 || !q.Spec.Monitoring.Enabled {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionSLOs)
+        return nil
+    }
+    gvk := render.PrometheusRuleGVK
+    if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+        setCondition(q, conditionSLOs, metav1.ConditionFalse, "CRDMissing",
+            "PrometheusRule is not served by the cluster; install the Prometheus Operator CRDs")
+        return nil
+    }
+    rules, err := render.InstanceSLORules(q)
+    if err != nil {
+        setCondition(q, conditionSLOs, metav1.ConditionFalse, "Invalid", err.Error())
+        return nil
+    }
+    for i, slo := range slos {
+        render.Explain(rules, "spec.groups[0].rules["+slo.Name+"]", slo.Objective+"% "+slo.Type,
+            "spec.monitoring.slos["+strconv.Itoa(i)+"]")
+    }
+    if err := r.createOrUpdate(ctx, q, rules); err != nil {
+        return err
+    }
+    setCondition(q, conditionSLOs, metav1.ConditionTrue, "Applied",
+        fmt.Sprintf("burn-rate alerts of %d SLO(s) in PrometheusRule %s", len(slos), render.SLORulesName))
+    return nil
+}
+
+// reconcileSLOBudgets queries the error budgets of q's SLOs into its status
+func (r *QraiopReconciler) reconcileSLOBudgets(ctx context.Context, q *qraiopv1.Qraiop) (time.Duration, error) {
+    if len(q.Spec.Monitoring.SLOs) == 0 || !q.Spec.Monitoring.Enabled {
+        q.Status.SLOs = nil
+        return 0, nil
+    }
+    slos := make([]qraiopv1.ServiceLevelObjective, len(q.Spec.Monitoring.SLOs))
+    selectors := make([]string, len(slos))
+    for i, slo := range q.Spec.Monitoring.SLOs {
+        slos[i], selectors[i] = slo.ServiceLevelObjective, render.ComponentSelector(q, slo.Component)
+    }
+    prom, err := instancePrometheus(ctx, r, q)
+    if err != nil {
+        return 0, err
+    }
+    q.Status.SLOs = evaluateSLOs(ctx, prom, slos, selectors, q.Namespace, "qraiop/"+q.Name)
+    if prom == nil {
+        return 0, nil
+    }
+    return sloEvaluationInterval, nil
+}
+
+// instancePrometheus returns a client for the Prometheus API of q, or nil
+// when it has none configured
+func instancePrometheus(ctx context.Context, c client.Reader, q *qraiopv1.Qraiop) (*alerting.Prometheus, error) {
+    cfg := q.Spec.Monitoring.Prometheus
+    if cfg == nil || cfg.URL == "" {
+        return nil, nil
+    }
+    httpClient, err := newHTTPClient(ctx, c, q.Namespace, q.Spec.Proxy)
+    if err != nil {
+        return nil, err
+    }
+    prom := &alerting.Prometheus{URL: cfg.URL, HTTP: httpClient}
+    if ref := cfg.QueryTokenSecret; ref != nil {
+        var secret corev1.Secret
+        if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: ref.Name}, &secret); err != nil {
+            return nil, fmt.Errorf("reading Prometheus query token: %w", err)
+        }
+        prom.Token = strings.TrimSpace(string(secret.Data[ref.Key]))
+    }
+    return prom, nil
+}
+
+// evaluateSLOs queries the remaining error budget and the last hour's burn
+// rate of each SLO on the series of its selector. Without a Prometheus
+// only the objectives are reported.
+func evaluateSLOs(ctx context.Context, prom *alerting.Prometheus, slos []qraiopv1.ServiceLevelObjective, selectors []string, namespace, owner string) []qraiopv1.SLOStatus {
+    now := metav1.Now()
+    statuses := make([]qraiopv1.SLOStatus, 0, len(slos))
+    for i, slo := range slos {
+        st := qraiopv1.SLOStatus{Name: slo.Name, Objective: slo.Objective}
+        budget, err := render.ErrorBudget(slo.Objective)
+        switch {
+        case err != nil:
+            st.Message = err.Error()
+        case prom == nil:
+            st.Message = "monitoring.prometheus.url is not set"
+        case budget == 0:
+            st.Message = "an objective of 100% leaves no error budget"
+        default:
+            st.LastEvaluated = &now
+            st.Message = queryBudget(ctx, prom, &st, slo, selectors[i], budget)
+            if st.ErrorBudgetRemaining != "" {
+                remaining, _ := strconv.ParseFloat(st.ErrorBudgetRemaining, 64)
+                sloErrorBudget.WithLabelValues(namespace, owner, slo.Name).Set(remaining / 100)
+            }
+        }
+        statuses = append(statuses, st)
+    }
+    return statuses
+}
+
+// queryBudget fills the budget and burn rate of st, returning what kept
+// them from being known
+func queryBudget(ctx context.Context, prom *alerting.Prometheus, st *qraiopv1.SLOStatus, slo qraiopv1.ServiceLevelObjective, sel string, budget float64) string {
+    bad, ok, err := prom.Query(ctx, render.SLOPeriodBadRatio(slo, sel))
+    if err != nil {
+        return "querying error budget: " + err.Error()
+    }
+    if !ok {
+        return "no requests in the period"
+    }
+    remaining := 1 - bad/budget
+    st.ErrorBudgetRemaining = strconv.FormatFloat(remaining*100, 'f', 2, 64)
+    st.Exhausted = remaining <= 0
+
+    recent, ok, err := prom.Query(ctx, render.BadRatio(slo, sel, "1h"))
+    if err != nil {
+        return "querying burn rate: " + err.Error()
+    }
+    if ok {
+        st.BurnRate = strconv.FormatFloat(recent/budget, 'f', 2, 64)
+    }
+    return ""
+}
+
+// exhaustedSLOs lists the SLOs of a namespace, of Qraiop instances and
+// MonitoringTargets, whose error budget is spent
+func exhaustedSLOs(ctx context.Context, c client.Reader, namespace string) ([]string, error) {
+    var exhausted []string
+    var instances qraiopv1.QraiopList
+    if err := c.List(ctx, &instances, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    for _, q := range instances.Items {
+        for _, st := range q.Status.SLOs {
+            if st.Exhausted {
+                exhausted = append(exhausted, q.Name+"/"+st.Name)
+            }
+        }
+    }
+    var targets qraiopv1.MonitoringTargetList
+    if err := c.List(ctx, &targets, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    for _, t := range targets.Items {
+        for _, st := range t.Status.SLOs {
+            if st.Exhausted {
+                exhausted = append(exhausted, t.Name+"/"+st.Name)
+            }
+        }
+    }
+    sort.Strings(exhausted)
+    return exhausted, nil
+}
@@ -0,0 +1,116 @@
+// src/controllers/controllers/status.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "k8s.io/apimachinery/pkg/api/equality"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// minReconcileInterval spaces out reconciles of one instance, so a burst of
+// child events (a rollout updating Deployment status many times a second)
+// collapses into a single reconcile. Spec changes are never held back.
+const minReconcileInterval = 2 * time.Second
+
+var (
+    statusWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "qraiop_status_writes_total",
+        Help: "Qraiop status writes by result: written, unchanged (skipped) or failed.",
+    }, []string{"result"})
+    reconcilesCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+        Name: "qraiop_reconciles_coalesced_total",
+        Help: "Reconciles postponed and merged into a later one because the instance was reconciled moments before.",
+    })
+)
+
+func init() {
+    metrics.Registry.MustRegister(statusWrites, reconcilesCoalesced)
+}
+
+// patchStatus writes the status of q in a single merge patch, skipping the
+// write entirely when nothing changed since orig, the stored object, was
+// read. The patch is built from orig with q's status, so the spec q was
+// unsealed into never reaches the API server.
+func (r *QraiopReconciler) patchStatus(ctx context.Context, q *qraiopv1.Qraiop, orig *qraiopv1.Qraiop) {
+    if equality.Semantic.DeepEqual(q.Status, orig.Status) {
+        statusWrites.WithLabelValues("unchanged").Inc()
+        return
+    }
+    q.Status.LastUpdated = metav1.Now()
+    patched := orig.DeepCopy()
+    patched.Status = *q.Status.DeepCopy()
+    if err := r.Status().Patch(ctx, patched, client.MergeFrom(orig)); err != nil {
+        statusWrites.WithLabelValues("failed").Inc()
+        r.Log.Error(err, "unable to update Qraiop status", "qraiop", client.ObjectKeyFromObject(q))
+        return
+    }
+    statusWrites.WithLabelValues("written").Inc()
+}
+
+// persistStatusField writes one field of q's status right away, for what
+// must be stored before the reconcile goes on, such as a journal entry
+// ahead of the call it guards. It is replaced as a whole by a JSON patch
+// of its own, so nothing else of q is sent and the deferred patch still
+// writes the rest of the status once.
+func (r *QraiopReconciler) persistStatusField(ctx context.Context, q *qraiopv1.Qraiop, field string, value interface{}) error {
+    data, err := json.Marshal([]map[string]interface{}{{"op": "add", "path": "/status/" + field, "value": value}})
+    if err != nil {
+        return err
+    }
+    target := &qraiopv1.Qraiop{ObjectMeta: metav1.ObjectMeta{Namespace: q.Namespace, Name: q.Name}}
+    if err := r.Status().Patch(ctx, target, client.RawPatch(types.JSONPatchType, data)); err != nil {
+        statusWrites.WithLabelValues("failed").Inc()
+        return err
+    }
+    statusWrites.WithLabelValues("written").Inc()
+    return nil
+}
+
+// reconcileGate remembers when each instance was last reconciled
+type reconcileGate struct {
+    mu   sync.Mutex
+    last map[types.NamespacedName]gateEntry
+}
+
+type gateEntry struct {
+    at         time.Time
+    generation int64
+}
+
+func newReconcileGate() *reconcileGate {
+    return &reconcileGate{last: make(map[types.NamespacedName]gateEntry)}
+}
+
+// delay returns how long to postpone a reconcile of key, or zero to go
+// ahead, in which case the reconcile is recorded. Requeueing after the
+// returned delay lets the work queue merge every event until then.
+func (g *reconcileGate) delay(key types.NamespacedName, generation int64) time.Duration {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    now := time.Now()
+    if prev, ok := g.last[key]; ok && prev.generation == generation {
+        if wait := minReconcileInterval - now.Sub(prev.at); wait > 0 {
+            reconcilesCoalesced.Inc()
+            return wait
+        }
+    }
+    g.last[key] = gateEntry{at: now, generation: generation}
+    return 0
+}
+
+// forget drops a deleted instance
+func (g *reconcileGate) forget(key types.NamespacedName) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    delete(g.last, key)
+}
@@ -0,0 +1,96 @@
+// src/controllers/controllers/teardown.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// qraiopFinalizer keeps an instance around until the children garbage
+// collection can't reach are removed
+const qraiopFinalizer = "qraiop.io/finalizer"
+
+// ensureFinalizer adds the teardown finalizer to q
+func (r *QraiopReconciler) ensureFinalizer(ctx context.Context, q *qraiopv1.Qraiop) error {
+    if !controllerutil.AddFinalizer(q, qraiopFinalizer) {
+        return nil
+    }
+    return r.Update(ctx, q)
+}
+
+// finalize tears down a deleted instance and then lets it go
+func (r *QraiopReconciler) finalize(ctx context.Context, q *qraiopv1.Qraiop) (ctrl.Result, error) {
+    if !controllerutil.ContainsFinalizer(q, qraiopFinalizer) {
+        return ctrl.Result{}, nil
+    }
+    if err := r.teardown(ctx, q); err != nil {
+        r.Log.Error(err, "unable to tear down deleted instance", "qraiop", client.ObjectKeyFromObject(q))
+        return ctrl.Result{}, err
+    }
+    controllerutil.RemoveFinalizer(q, qraiopFinalizer)
+    return ctrl.Result{}, r.Update(ctx, q)
+}
+
+// teardown removes what owner references don't cover: the inventory
+// objects that are cluster-scoped or in other namespaces, such as
+// NetworkPolicies of a staged rollout, and the registry policy labels of
+// namespaces. Children in q's namespace are left to garbage collection.
+func (r *QraiopReconciler) teardown(ctx context.Context, q *qraiopv1.Qraiop) error {
+    for _, e := range q.Status.Inventory {
+        if e.Namespace == q.Namespace {
+            continue
+        }
+        if err := r.deleteStray(ctx, q, e); err != nil {
+            return fmt.Errorf("deleting %s %s/%s: %w", e.Kind, e.Namespace, e.Name, err)
+        }
+    }
+    for _, ns := range q.Status.RegistryPolicyNamespaces {
+        if _, err := r.labelRegistryNamespace(ctx, ns, false); err != nil {
+            return fmt.Errorf("unlabelling namespace %s: %w", ns, err)
+        }
+    }
+    return nil
+}
+
+// deleteStray deletes an inventory object outside q's namespace. Such
+// objects can't carry q's owner reference, so they are only deleted while
+// controlled by q or, without a controller, labelled as q's.
+func (r *QraiopReconciler) deleteStray(ctx context.Context, q *qraiopv1.Qraiop, e qraiopv1.InventoryEntry) error {
+    obj := &unstructured.Unstructured{}
+    obj.SetGroupVersionKind(schema.GroupVersionKind{Group: e.Group, Version: e.Version, Kind: e.Kind})
+    err := r.Get(ctx, client.ObjectKey{Namespace: e.Namespace, Name: e.Name}, obj)
+    if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if !metav1.IsControlledBy(obj, q) {
+        if metav1.GetControllerOf(obj) != nil {
+            return nil
+        }
+        for k, v := range labelsForQraiop(q) {
+            if obj.GetLabels()[k] != v {
+                return nil
+            }
+        }
+    }
+    uid := obj.GetUID()
+    err = r.Delete(ctx, obj, client.Preconditions{UID: &uid}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+    if err != nil && !apierrors.IsNotFound(err) {
+        return err
+    }
+    r.Log.Info("deleted child of deleted instance", "kind", e.Kind, "namespace", e.Namespace, "name", e.Name)
+    return nil
+}
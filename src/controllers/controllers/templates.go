@@ -0,0 +1,52 @@
+// src/controllers/controllers/templates.go
+package controllers
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const conditionNotificationTemplates = "NotificationTemplates"
+
+// notificationTemplates loads the notification templates of q along with
+// the ConfigMap data they came from. Missing or invalid templates are
+// reported in the NotificationTemplates condition and the built-in ones
+// are used instead.
+func (r *QraiopReconciler) notificationTemplates(ctx context.Context, q *qraiopv1.Qraiop) (*alerting.Templates, map[string]string, error) {
+    defaults, err := alerting.ParseTemplates(nil)
+    if err != nil {
+        return nil, nil, err
+    }
+    cfg := q.Spec.Monitoring.Alerting
+    if cfg == nil || cfg.Templates == nil {
+        meta.RemoveStatusCondition(&q.Status.Conditions, conditionNotificationTemplates)
+        return defaults, nil, nil
+    }
+
+    var cm corev1.ConfigMap
+    err = r.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: cfg.Templates.Name}, &cm)
+    if apierrors.IsNotFound(err) {
+        setCondition(q, conditionNotificationTemplates, metav1.ConditionFalse, "NotFound",
+            "ConfigMap "+cfg.Templates.Name+" not found, using built-in templates")
+        return defaults, nil, nil
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    tpl, err := alerting.ParseTemplates(cm.Data)
+    if err != nil {
+        setCondition(q, conditionNotificationTemplates, metav1.ConditionFalse, "InvalidTemplate",
+            err.Error()+", using built-in templates")
+        return defaults, nil, nil
+    }
+    setCondition(q, conditionNotificationTemplates, metav1.ConditionTrue, "Valid", "templates of ConfigMap "+cm.Name+" in use")
+    return tpl, cm.Data, nil
+}
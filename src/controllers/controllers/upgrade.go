@@ -0,0 +1,91 @@
+// src/controllers/controllers/upgrade.go
+package controllers
+
+import (
+    "context"
+    "fmt"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/version"
+)
+
+// operatorVersionAnnotation records on a pod template the operator version
+// it was rendered for
+const operatorVersionAnnotation = "qraiop.io/operator-version"
+
+// upgradeHook runs once when a new operator version first reconciles an instance
+type upgradeHook struct {
+    name string
+    run  func(context.Context, *qraiopv1.Qraiop) error
+}
+
+// preUpgradeHooks run before components are reconciled by a new operator version
+func (r *QraiopReconciler) preUpgradeHooks() []upgradeHook {
+    return []upgradeHook{
+        {name: "migrate-component-status", run: r.migrateComponentStatus},
+    }
+}
+
+// postUpgradeHooks run once components have been reconciled by a new
+// operator version, after Status.OperatorVersion is updated
+func (r *QraiopReconciler) postUpgradeHooks() []upgradeHook {
+    return []upgradeHook{
+        {name: "reissue-certificates", run: r.reissueCertificates},
+    }
+}
+
+func (r *QraiopReconciler) runUpgradeHooks(ctx context.Context, q *qraiopv1.Qraiop, hooks []upgradeHook) error {
+    for _, h := range hooks {
+        if err := h.run(ctx, q); err != nil {
+            err = fmt.Errorf("upgrade hook %s: %w", h.name, err)
+            setCondition(q, conditionUpgraded, metav1.ConditionFalse, "HookFailed", err.Error())
+            return err
+        }
+    }
+    return nil
+}
+
+// finishUpgrade records the new operator version and runs the post-upgrade
+// hooks. A fresh install has nothing to upgrade from, so only the version
+// is recorded.
+func (r *QraiopReconciler) finishUpgrade(ctx context.Context, q *qraiopv1.Qraiop) error {
+    from := q.Status.OperatorVersion
+    q.Status.OperatorVersion = version.Version
+    if from == "" {
+        return nil
+    }
+    if err := r.runUpgradeHooks(ctx, q, r.postUpgradeHooks()); err != nil {
+        // Retry the post-upgrade hooks on the next reconcile
+        q.Status.OperatorVersion = from
+        return err
+    }
+    setCondition(q, conditionUpgraded, metav1.ConditionTrue, "HooksSucceeded",
+        fmt.Sprintf("upgraded from %s to %s", from, version.Version))
+    return nil
+}
+
+// migrateComponentStatus drops status entries of components this operator
+// no longer manages
+func (r *QraiopReconciler) migrateComponentStatus(_ context.Context, q *qraiopv1.Qraiop) error {
+    known := make(map[string]bool)
+    for _, c := range r.components(q) {
+        known[c.name] = true
+    }
+    for name := range q.Status.Components {
+        if !known[name] {
+            delete(q.Status.Components, name)
+        }
+    }
+    return nil
+}
+
+// reissueCertificates rolls the crypto service for the new operator version
+// so it re-issues its certificates
+func (r *QraiopReconciler) reissueCertificates(ctx context.Context, q *qraiopv1.Qraiop) error {
+    if !q.Spec.Cryptography.Enabled {
+        return nil
+    }
+    return r.reconcileCryptography(ctx, q)
+}
@@ -0,0 +1,183 @@
+// src/controllers/cron/cron.go
+
+// Package cron parses the five-field cron expressions of ChaosSchedules
+package cron
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Schedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type Schedule struct {
+    minute, hour, dom, month, dow uint64
+    // domStar and dowStar record an unrestricted day field: as in cron, a
+    // day matches either restricted day field when both are restricted
+    domStar, dowStar bool
+}
+
+// field is the range and value names of one cron field
+type field struct {
+    name     string
+    min, max int
+    names    []string
+}
+
+var (
+    minutes = field{name: "minute", min: 0, max: 59}
+    hours   = field{name: "hour", min: 0, max: 23}
+    doms    = field{name: "day of month", min: 1, max: 31}
+    months  = field{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+    dows    = field{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// macros are the shorthands cron understands besides the five fields
+var macros = map[string]string{
+    "@yearly":   "0 0 1 1 *",
+    "@annually": "0 0 1 1 *",
+    "@monthly":  "0 0 1 * *",
+    "@weekly":   "0 0 * * 0",
+    "@daily":    "0 0 * * *",
+    "@midnight": "0 0 * * *",
+    "@hourly":   "0 * * * *",
+}
+
+// Parse parses a standard cron expression: minute, hour, day of month,
+// month and day of week, each a *, a value, a range or a list of them,
+// optionally with a /step. Months and days of the week may be named.
+func Parse(expr string) (*Schedule, error) {
+    expr = strings.TrimSpace(expr)
+    if m, ok := macros[strings.ToLower(expr)]; ok {
+        expr = m
+    }
+    fields := strings.Fields(expr)
+    if len(fields) != 5 {
+        return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+    }
+    s := &Schedule{}
+    var err error
+    if s.minute, _, err = minutes.parse(fields[0]); err != nil {
+        return nil, err
+    }
+    if s.hour, _, err = hours.parse(fields[1]); err != nil {
+        return nil, err
+    }
+    if s.dom, s.domStar, err = doms.parse(fields[2]); err != nil {
+        return nil, err
+    }
+    if s.month, _, err = months.parse(fields[3]); err != nil {
+        return nil, err
+    }
+    if s.dow, s.dowStar, err = dows.parse(fields[4]); err != nil {
+        return nil, err
+    }
+    // 7 is another name for Sunday
+    if s.dow&(1<<7) != 0 {
+        s.dow |= 1
+    }
+    return s, nil
+}
+
+// parse returns the bit set of values matched by a field, and whether it
+// is unrestricted
+func (f field) parse(expr string) (uint64, bool, error) {
+    var bits uint64
+    star := false
+    for _, part := range strings.Split(expr, ",") {
+        rng, stepExpr, hasStep := strings.Cut(part, "/")
+        lo, hi := f.min, f.max
+        switch {
+        case rng == "*":
+            star = star || !hasStep
+        case strings.Contains(rng, "-"):
+            a, b, _ := strings.Cut(rng, "-")
+            var err error
+            if lo, err = f.value(a); err != nil {
+                return 0, false, err
+            }
+            if hi, err = f.value(b); err != nil {
+                return 0, false, err
+            }
+            if lo > hi {
+                return 0, false, fmt.Errorf("%s range %s runs backwards", f.name, rng)
+            }
+        default:
+            v, err := f.value(rng)
+            if err != nil {
+                return 0, false, err
+            }
+            lo, hi = v, v
+            if hasStep {
+                hi = f.max
+            }
+        }
+        step := 1
+        if hasStep {
+            var err error
+            if step, err = strconv.Atoi(stepExpr); err != nil || step < 1 {
+                return 0, false, fmt.Errorf("invalid %s step %q", f.name, stepExpr)
+            }
+        }
+        for v := lo; v <= hi; v += step {
+            bits |= 1 << uint(v)
+        }
+    }
+    return bits, star, nil
+}
+
+// value parses a number or name of the field
+func (f field) value(s string) (int, error) {
+    for i, name := range f.names {
+        if name != "" && strings.EqualFold(s, name) {
+            return i, nil
+        }
+    }
+    v, err := strconv.Atoi(s)
+    if err != nil {
+        return 0, fmt.Errorf("invalid %s %q", f.name, s)
+    }
+    if v < f.min || v > f.max {
+        return 0, fmt.Errorf("%s %d out of range %d-%d", f.name, v, f.min, f.max)
+    }
+    return v, nil
+}
+
+// Next returns the first time after t the schedule fires, in t's location,
+// or the zero time when it doesn't fire within five years, e.g. for the
+// 30th of February
+func (s *Schedule) Next(t time.Time) time.Time {
+    t = t.Truncate(time.Minute).Add(time.Minute)
+    limit := t.AddDate(5, 0, 0)
+    for t.Before(limit) {
+        if s.month&(1<<uint(t.Month())) == 0 {
+            t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+            continue
+        }
+        if !s.dayMatches(t) {
+            t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+            continue
+        }
+        if s.hour&(1<<uint(t.Hour())) == 0 {
+            t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+            continue
+        }
+        if s.minute&(1<<uint(t.Minute())) == 0 {
+            t = t.Add(time.Minute)
+            continue
+        }
+        return t
+    }
+    return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+    dom := s.dom&(1<<uint(t.Day())) != 0
+    dow := s.dow&(1<<uint(t.Weekday())) != 0
+    if s.domStar || s.dowStar {
+        return dom && dow
+    }
+    return dom || dow
+}
@@ -0,0 +1,19 @@
+// src/controllers/cryptoclient/capabilities.go
+package cryptoclient
+
+import "context"
+
+// Capabilities is what a running crypto service registers about itself:
+// the version of its API and the algorithms its build implements
+type Capabilities struct {
+    APIVersion string   `json:"apiVersion"`
+    Algorithms []string `json:"algorithms"`
+}
+
+// Capabilities returns the API version and supported algorithms of the
+// service
+func (c *Client) Capabilities(ctx context.Context) (Capabilities, error) {
+    var out Capabilities
+    err := c.Get(ctx, "/v1/capabilities", &out)
+    return out, err
+}
@@ -0,0 +1,36 @@
+// src/controllers/cryptoclient/certificates.go
+package cryptoclient
+
+import (
+    "context"
+    "time"
+)
+
+// CertificateRequest asks the service's CA for a serving certificate
+type CertificateRequest struct {
+    CommonName  string   `json:"commonName"`
+    DNSNames    []string `json:"dnsNames,omitempty"`
+    IPAddresses []string `json:"ipAddresses,omitempty"`
+    TTLSeconds  int64    `json:"ttlSeconds,omitempty"`
+}
+
+// Certificate is an issued certificate with its key and the CA that
+// signed it, all PEM encoded. The key pair uses the algorithms the service
+// is configured with.
+type Certificate struct {
+    Certificate   string `json:"certificate"`
+    PrivateKey    string `json:"privateKey"`
+    CACertificate string `json:"caCertificate"`
+    // PQC is the post-quantum half of a hybrid certificate, whose classical
+    // half is the rest
+    PQC *Certificate `json:"pqc,omitempty"`
+}
+
+// IssueCertificate has the service's CA issue a certificate valid for ttl,
+// or for the service's default lifetime when ttl is 0
+func (c *Client) IssueCertificate(ctx context.Context, req CertificateRequest, ttl time.Duration) (Certificate, error) {
+    var out Certificate
+    req.TTLSeconds = int64(ttl.Seconds())
+    err := c.Call(ctx, "/v1/certificates", req, &out)
+    return out, err
+}
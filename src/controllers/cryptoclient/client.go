@@ -0,0 +1,187 @@
+// src/controllers/cryptoclient/client.go
+
+// Package cryptoclient is the operator's client for the QRAIOP crypto
+// service. Calls share a pool of keepalive connections, optionally over
+// cleartext HTTP/2, and every call gets its own deadline.
+package cryptoclient
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "golang.org/x/net/http2"
+)
+
+// Defaults for an unset Options field
+const (
+    DefaultMaxConnections = 8
+    DefaultIdleTimeout    = 90 * time.Second
+    DefaultKeepAlive      = 30 * time.Second
+    DefaultCallTimeout    = 10 * time.Second
+)
+
+// Options tune the connection pool
+type Options struct {
+    // MaxConnections caps the connections to the service; calls beyond it
+    // wait for a free connection
+    MaxConnections int
+    IdleTimeout    time.Duration
+    KeepAlive      time.Duration
+    // CallTimeout is the deadline of a single call
+    CallTimeout time.Duration
+    // HTTP2 multiplexes calls over cleartext HTTP/2 (h2c) connections
+    HTTP2 bool
+}
+
+// WithDefaults returns o with unset fields defaulted
+func (o Options) WithDefaults() Options {
+    if o.MaxConnections <= 0 {
+        o.MaxConnections = DefaultMaxConnections
+    }
+    if o.IdleTimeout <= 0 {
+        o.IdleTimeout = DefaultIdleTimeout
+    }
+    if o.KeepAlive <= 0 {
+        o.KeepAlive = DefaultKeepAlive
+    }
+    if o.CallTimeout <= 0 {
+        o.CallTimeout = DefaultCallTimeout
+    }
+    return o
+}
+
+// Client calls one crypto service endpoint
+type Client struct {
+    endpoint string
+    opts     Options
+    http     *http.Client
+    metrics  *poolMetrics
+    closer   func()
+}
+
+// New returns a Client for the crypto service at endpoint, e.g.
+// http://qraiop-crypto.qraiop-system.svc:8080. pool names the client in
+// metrics.
+func New(endpoint, pool string, opts Options) *Client {
+    opts = opts.WithDefaults()
+    m := newPoolMetrics(pool, opts.MaxConnections)
+    dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: opts.KeepAlive}
+    dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+        conn, err := dialer.DialContext(ctx, network, addr)
+        if err != nil {
+            return nil, err
+        }
+        return m.track(conn), nil
+    }
+
+    c := &Client{endpoint: strings.TrimSuffix(endpoint, "/"), opts: opts, metrics: m}
+    if opts.HTTP2 {
+        t := &http2.Transport{
+            AllowHTTP: true,
+            DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+                return dial(ctx, network, addr)
+            },
+            ReadIdleTimeout: opts.KeepAlive,
+            PingTimeout:     opts.CallTimeout,
+        }
+        c.http = &http.Client{Transport: t}
+        c.closer = t.CloseIdleConnections
+    } else {
+        t := &http.Transport{
+            DialContext:         dial,
+            MaxIdleConns:        opts.MaxConnections,
+            MaxIdleConnsPerHost: opts.MaxConnections,
+            MaxConnsPerHost:     opts.MaxConnections,
+            IdleConnTimeout:     opts.IdleTimeout,
+        }
+        c.http = &http.Client{Transport: t}
+        c.closer = t.CloseIdleConnections
+    }
+    return c
+}
+
+// Options returns the pool options in effect
+func (c *Client) Options() Options {
+    return c.opts
+}
+
+// Close releases the pooled connections
+func (c *Client) Close() {
+    c.closer()
+    c.metrics.unregister()
+}
+
+// IdempotencyKeyHeader carries the idempotency key of a call. The service
+// remembers the outcome of every key it has seen and answers a repeated
+// call with it instead of running the operation again.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context whose calls carry key, so retrying
+// them after an interruption cannot run the operation twice
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+    return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Call POSTs in as JSON to path and decodes the response into out, within
+// the per-call deadline
+func (c *Client) Call(ctx context.Context, path string, in, out interface{}) error {
+    body, err := json.Marshal(in)
+    if err != nil {
+        return err
+    }
+    return c.do(ctx, http.MethodPost, path, body, out)
+}
+
+// Get fetches path and decodes the JSON response into out
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+    return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+    ctx, cancel := context.WithTimeout(ctx, c.opts.CallTimeout)
+    defer cancel()
+
+    var reader io.Reader
+    if body != nil {
+        reader = bytes.NewReader(body)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+        req.Header.Set(IdempotencyKeyHeader, key)
+    }
+
+    done := c.metrics.start()
+    resp, err := c.http.Do(req)
+    if err != nil {
+        done("error")
+        return err
+    }
+    defer resp.Body.Close()
+    done(strconv.Itoa(resp.StatusCode))
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("crypto service %s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+    }
+    if out == nil {
+        return nil
+    }
+    return json.NewDecoder(resp.Body).Decode(out)
+}
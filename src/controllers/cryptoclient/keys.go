@@ -0,0 +1,79 @@
+// src/controllers/cryptoclient/keys.go
+package cryptoclient
+
+import (
+    "context"
+    "time"
+)
+
+// KeyUsage is what the crypto service reports about one key
+type KeyUsage struct {
+    KeyID     string `json:"keyId"`
+    Algorithm string `json:"algorithm"`
+    // Operations are cumulative counts by operation, e.g. sign, decapsulate,
+    // since the key was loaded
+    Operations map[string]uint64 `json:"operations"`
+}
+
+// KeyUsage returns the operation counts of every key the service holds
+func (c *Client) KeyUsage(ctx context.Context) ([]KeyUsage, error) {
+    var out struct {
+        Keys []KeyUsage `json:"keys"`
+    }
+    if err := c.Get(ctx, "/v1/keys/usage", &out); err != nil {
+        return nil, err
+    }
+    return out.Keys, nil
+}
+
+// Rotation is the outcome of a key rotation
+type Rotation struct {
+    KeyID         string `json:"keyId"`
+    PreviousKeyID string `json:"previousKeyId"`
+}
+
+// RotateKeys makes a new key current. The previous key stays valid for
+// overlap, or until it is retired.
+func (c *Client) RotateKeys(ctx context.Context, overlap time.Duration) (Rotation, error) {
+    var out Rotation
+    in := struct {
+        OverlapSeconds int64 `json:"overlapSeconds"`
+    }{int64(overlap.Seconds())}
+    err := c.Call(ctx, "/v1/keys/rotate", in, &out)
+    return out, err
+}
+
+// RetireKey stops accepting a previous key
+func (c *Client) RetireKey(ctx context.Context, keyID string) error {
+    in := struct {
+        KeyID string `json:"keyId"`
+    }{keyID}
+    return c.Call(ctx, "/v1/keys/retire", in, nil)
+}
+
+// RevokedCertificate is an entry of a revocation list
+type RevokedCertificate struct {
+    SerialNumber string    `json:"serialNumber"`
+    RevokedAt    time.Time `json:"revokedAt"`
+    Reason       string    `json:"reason"`
+}
+
+// RevocationList is a revocation list to be signed by the issuing CA
+type RevocationList struct {
+    Number     int64                `json:"number"`
+    ThisUpdate time.Time            `json:"thisUpdate"`
+    NextUpdate time.Time            `json:"nextUpdate"`
+    Revoked    []RevokedCertificate `json:"revoked"`
+}
+
+// SignRevocationList has the service's CA sign list and returns it as PEM.
+// The service also serves the latest list it signed at /v1/crl.
+func (c *Client) SignRevocationList(ctx context.Context, list RevocationList) ([]byte, error) {
+    var out struct {
+        CRL string `json:"crl"`
+    }
+    if err := c.Call(ctx, "/v1/crl", list, &out); err != nil {
+        return nil, err
+    }
+    return []byte(out.CRL), nil
+}
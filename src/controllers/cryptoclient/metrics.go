@@ -0,0 +1,82 @@
+// src/controllers/cryptoclient/metrics.go
+package cryptoclient
+
+import (
+    "net"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+    openConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_crypto_client_open_connections",
+        Help: "Connections open from the operator to the crypto service.",
+    }, []string{"pool"})
+    maxConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_crypto_client_max_connections",
+        Help: "Connection limit of the operator's crypto service pool.",
+    }, []string{"pool"})
+    inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "qraiop_crypto_client_in_flight_calls",
+        Help: "Calls to the crypto service in progress, including those waiting for a connection.",
+    }, []string{"pool"})
+    callDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+        Name:    "qraiop_crypto_client_call_duration_seconds",
+        Help:    "Duration of calls to the crypto service, including time waiting for a connection.",
+        Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+    }, []string{"pool", "code"})
+)
+
+func init() {
+    metrics.Registry.MustRegister(openConnections, maxConnections, inFlight, callDuration)
+}
+
+// poolMetrics reports the saturation of one pool: open connections against
+// the limit, and calls in flight
+type poolMetrics struct {
+    pool string
+}
+
+func newPoolMetrics(pool string, max int) *poolMetrics {
+    maxConnections.WithLabelValues(pool).Set(float64(max))
+    openConnections.WithLabelValues(pool).Set(0)
+    return &poolMetrics{pool: pool}
+}
+
+// start records a call and returns a func to call with its result code
+func (m *poolMetrics) start() func(code string) {
+    g := inFlight.WithLabelValues(m.pool)
+    g.Inc()
+    begin := time.Now()
+    return func(code string) {
+        g.Dec()
+        callDuration.WithLabelValues(m.pool, code).Observe(time.Since(begin).Seconds())
+    }
+}
+
+// track counts conn as open until it is closed
+func (m *poolMetrics) track(conn net.Conn) net.Conn {
+    g := openConnections.WithLabelValues(m.pool)
+    g.Inc()
+    return &trackedConn{Conn: conn, onClose: g.Dec}
+}
+
+func (m *poolMetrics) unregister() {
+    openConnections.DeleteLabelValues(m.pool)
+    maxConnections.DeleteLabelValues(m.pool)
+    inFlight.DeleteLabelValues(m.pool)
+}
+
+type trackedConn struct {
+    net.Conn
+    once    sync.Once
+    onClose func()
+}
+
+func (c *trackedConn) Close() error {
+    c.once.Do(c.onClose)
+    return c.Conn.Close()
+}
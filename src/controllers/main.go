@@ -4,17 +4,28 @@ package main
 import (
     "flag"
     "fmt"
+    "net"
     "os"
+    "strconv"
+    "strings"
+    "time"
 
+    admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
     "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
     utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+    "k8s.io/client-go/discovery"
     clientgoscheme "k8s.io/client-go/kubernetes/scheme"
     ctrl "sigs.k8s.io/controller-runtime"
     "sigs.k8s.io/controller-runtime/pkg/healthz"
     "sigs.k8s.io/controller-runtime/pkg/log/zap"
 
     qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+    "github.com/Bailey7220/QRAIOP/controllers/compat"
     "github.com/Bailey7220/QRAIOP/controllers/controllers"
+    "github.com/Bailey7220/QRAIOP/controllers/throttle"
+    "github.com/Bailey7220/QRAIOP/controllers/webhooks"
 )
 
 var (
@@ -31,15 +42,83 @@ func main() {
     var metricsAddr string
     var enableLeaderElection bool
     var probeAddr string
-    
+    var enableWebhooks bool
+    var serviceAccount string
+    var webhookFailurePolicy string
+    var webhookExcludeNamespaces string
+    var webhooksBreakGlass bool
+    var enrollmentAddr string
+    var enrollmentCertDir string
+    var resyncPeriod time.Duration
+    var requeueJitter float64
+    var hardened bool
+    var operatorNetworkPolicy string
+    var operatorSelector string
+    var apiServerCIDRs string
+    var metricsNamespaces string
+    var operatorEgressCIDRs string
+    var devMode bool
+    var mockImage string
+    var apiQPS float64
+    var apiBurst int
+    var adaptiveThrottling bool
+
     flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
     flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
     flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+    flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the admission webhooks. Requires a serving certificate.")
+    flag.StringVar(&serviceAccount, "service-account", "qraiop-controller", "ServiceAccount the operator runs as.")
+    flag.StringVar(&webhookFailurePolicy, "webhook-failure-policy", "", "Failure policy (Fail or Ignore) set on every webhook. Empty keeps the policies of the manifests.")
+    flag.StringVar(&webhookExcludeNamespaces, "webhook-exclude-namespaces", "", "Comma-separated namespaces never sent to the webhooks, e.g. kube-system.")
+    flag.BoolVar(&webhooksBreakGlass, "webhooks-break-glass", false, "Stop serving the webhooks and set them all to failurePolicy Ignore, for when they block cluster recovery.")
+    flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute, "How often instances are reconciled without changes. Each instance gets a slot in the period derived from its UID.")
+    flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1, "Fraction, at most, by which follow-up requeues are randomly delayed to spread them out. 0 disables it.")
+    flag.BoolVar(&hardened, "hardened", false, "Turn on the hardening defaults, such as --operator-network-policy.")
+    flag.StringVar(&operatorNetworkPolicy, "operator-network-policy", "auto", "Restrict the operator pods' traffic with a NetworkPolicy: true, false, or auto to follow --hardened. Kubelet probes are not subject to it.")
+    flag.StringVar(&operatorSelector, "operator-selector", "app=qraiop-controller", "Comma-separated labels selecting the operator pods.")
+    flag.StringVar(&apiServerCIDRs, "apiserver-cidrs", "", "Comma-separated CIDRs the API server calls webhooks from and is reached at. Empty uses the endpoints of the kubernetes Service.")
+    flag.StringVar(&metricsNamespaces, "metrics-namespaces", "monitoring", "Comma-separated namespaces allowed to scrape the operator's metrics.")
+    flag.StringVar(&operatorEgressCIDRs, "operator-egress-cidrs", "", "Comma-separated CIDRs the operator may reach besides the API server, DNS and QRAIOP components, e.g. of Alertmanager, Vault or AI providers.")
+    flag.StringVar(&enrollmentAddr, "enrollment-bind-address", "", "The address the certificate enrollment API binds to, e.g. :8443. Empty disables it.")
+    flag.StringVar(&enrollmentCertDir, "enrollment-cert-dir", "/tmp/k8s-enrollment-server/serving-certs", "Directory holding the enrollment API serving certificate, tls.crt and tls.key.")
+    flag.BoolVar(&devMode, "dev-mode", false, "Run the QRAIOP components as the mock servers of --mock-image, for local development on kind.")
+    flag.StringVar(&mockImage, "mock-image", controllers.DefaultMockImage, "Image of the component mock servers run under --dev-mode.")
+    flag.Float64Var(&apiQPS, "kube-api-qps", 20, "Requests per second the operator allows itself against the API server.")
+    flag.IntVar(&apiBurst, "kube-api-burst", 30, "Requests the operator may burst to against the API server.")
+    flag.BoolVar(&adaptiveThrottling, "adaptive-throttling", true, "Halve --kube-api-qps while the API server answers 429 Too Many Requests, recovering gradually once it stops.")
     flag.Parse()
 
     ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
-    mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+    switch admissionregistrationv1.FailurePolicyType(webhookFailurePolicy) {
+    case "", admissionregistrationv1.Fail, admissionregistrationv1.Ignore:
+    default:
+        setupLog.Error(nil, "invalid --webhook-failure-policy, want Fail or Ignore", "value", webhookFailurePolicy)
+        os.Exit(1)
+    }
+    switch operatorNetworkPolicy {
+    case "auto", "true", "false":
+    default:
+        setupLog.Error(nil, "invalid --operator-network-policy, want true, false or auto", "value", operatorNetworkPolicy)
+        os.Exit(1)
+    }
+    if apiQPS <= 0 || apiBurst < 1 {
+        setupLog.Error(nil, "--kube-api-qps and --kube-api-burst must be positive", "qps", apiQPS, "burst", apiBurst)
+        os.Exit(1)
+    }
+    if webhooksBreakGlass {
+        setupLog.Info("BREAK-GLASS: webhooks are not served and set to failurePolicy Ignore; restart without --webhooks-break-glass to restore them")
+        enableWebhooks = false
+    }
+
+    restConfig := ctrl.GetConfigOrDie()
+    throttle.NewLimiter(throttle.Config{
+        QPS:      float32(apiQPS),
+        Burst:    apiBurst,
+        Adaptive: adaptiveThrottling,
+    }).Apply(restConfig)
+
+    mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
         Scheme:                 scheme,
         MetricsBindAddress:     metricsAddr,
         Port:                   9443,
@@ -52,12 +131,209 @@ func main() {
         os.Exit(1)
     }
 
+    // Without discovery the operator assumes a current cluster
+    cluster, err := compat.Detect(discovery.NewDiscoveryClientForConfigOrDie(mgr.GetConfig()))
+    if err != nil {
+        setupLog.Error(err, "unable to detect cluster version and APIs")
+    } else {
+        setupLog.Info("detected cluster", "version", cluster.Version.String(), "deprecatedAPIs", cluster.Deprecations())
+        chaos.UseCluster(cluster)
+    }
+
+    leaderElectionID := ""
+    if enableLeaderElection {
+        leaderElectionID = "qraiop.io"
+    }
     if err = (&controllers.QraiopReconciler{
+        Client:                  mgr.GetClient(),
+        Scheme:                  mgr.GetScheme(),
+        Log:                     ctrl.Log.WithName("controllers").WithName("Qraiop"),
+        Config:                  mgr.GetConfig(),
+        LeaderElectionNamespace: operatorNamespace(),
+        LeaderElectionID:        leaderElectionID,
+        OperatorNamespace:       operatorNamespace(),
+        Cluster:                 cluster,
+        ResyncPeriod:            resyncPeriod,
+        RequeueJitter:           requeueJitter,
+        DevMode:                 devMode,
+        MockImage:               mockImage,
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "Qraiop")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.ChaosExperimentReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("ChaosExperiment"),
+        Recorder: mgr.GetEventRecorderFor("chaosexperiment-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "ChaosExperiment")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.ChaosTriggerReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("ChaosTrigger"),
+        Recorder: mgr.GetEventRecorderFor("chaostrigger-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "ChaosTrigger")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.ChaosScheduleReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("ChaosSchedule"),
+        Recorder: mgr.GetEventRecorderFor("chaosschedule-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "ChaosSchedule")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.PlaybookReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("Playbook"),
+        Recorder: mgr.GetEventRecorderFor("playbook-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "Playbook")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.AgentTaskReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("AgentTask"),
+        Recorder: mgr.GetEventRecorderFor("agenttask-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "AgentTask")
+        os.Exit(1)
+    }
+
+    if err = (&controllers.CertInjectionReconciler{
+        Client:   mgr.GetClient(),
+        Scheme:   mgr.GetScheme(),
+        Log:      ctrl.Log.WithName("controllers").WithName("CertInjection"),
+        Recorder: mgr.GetEventRecorderFor("certinjection-controller"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "CertInjection")
+        os.Exit(1)
+    }
+    if err = (&controllers.QraiopSecurityPolicyReconciler{
         Client: mgr.GetClient(),
         Scheme: mgr.GetScheme(),
-        Log:    ctrl.Log.WithName("controllers").WithName("Qraiop"),
+        Log:    ctrl.Log.WithName("controllers").WithName("QraiopSecurityPolicy"),
     }).SetupWithManager(mgr); err != nil {
-        setupLog.Error(err, "unable to create controller", "controller", "Qraiop")
+        setupLog.Error(err, "unable to create controller", "controller", "QraiopSecurityPolicy")
+        os.Exit(1)
+    }
+    if err = (&controllers.MonitoringTargetReconciler{
+        Client: mgr.GetClient(),
+        Scheme: mgr.GetScheme(),
+        Log:    ctrl.Log.WithName("controllers").WithName("MonitoringTarget"),
+    }).SetupWithManager(mgr); err != nil {
+        setupLog.Error(err, "unable to create controller", "controller", "MonitoringTarget")
+        os.Exit(1)
+    }
+
+    if enableWebhooks {
+        if err = (&webhooks.ChaosExperimentValidator{
+            Client: mgr.GetAPIReader(),
+            Exempt: []string{"system:serviceaccount:" + operatorNamespace() + ":" + serviceAccount},
+        }).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "ChaosExperiment")
+            os.Exit(1)
+        }
+        if err = (&webhooks.ChaosScheduleValidator{
+            Client: mgr.GetAPIReader(),
+            Exempt: []string{"system:serviceaccount:" + operatorNamespace() + ":" + serviceAccount},
+        }).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "ChaosSchedule")
+            os.Exit(1)
+        }
+        if err = (&webhooks.NotificationTemplateValidator{}).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "NotificationTemplates")
+            os.Exit(1)
+        }
+        if err = (&webhooks.RegistryPolicyValidator{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "RegistryPolicy")
+            os.Exit(1)
+        }
+        if err = (&webhooks.RegistryBypassValidator{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "RegistryBypass")
+            os.Exit(1)
+        }
+        if err = (&webhooks.QraiopDeprecationWarner{}).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "QraiopDeprecations")
+            os.Exit(1)
+        }
+        if err = (&webhooks.CertInjector{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+            setupLog.Error(err, "unable to create webhook", "webhook", "CertInjection")
+            os.Exit(1)
+        }
+        if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+            setupLog.Error(err, "unable to set up webhook ready check")
+            os.Exit(1)
+        }
+    }
+    if enableWebhooks || webhooksBreakGlass {
+        exclude := splitList(webhookExcludeNamespaces)
+        if err := mgr.Add(&webhooks.ConfigManager{
+            Client:            mgr.GetClient(),
+            Reader:            mgr.GetAPIReader(),
+            Log:               ctrl.Log.WithName("webhooks").WithName("config"),
+            Name:              "qraiop-validating-webhook",
+            FailurePolicy:     admissionregistrationv1.FailurePolicyType(webhookFailurePolicy),
+            ExcludeNamespaces: exclude,
+            BreakGlass:        webhooksBreakGlass,
+            CertDir:           "/tmp/k8s-webhook-server/serving-certs",
+            Certificate:       types.NamespacedName{Namespace: operatorNamespace(), Name: "qraiop-webhook"},
+        }); err != nil {
+            setupLog.Error(err, "unable to set up webhook configuration manager")
+            os.Exit(1)
+        }
+    }
+
+    if enrollmentAddr != "" {
+        if err := mgr.Add(&controllers.EnrollmentServer{
+            Client:   mgr.GetClient(),
+            Log:      ctrl.Log.WithName("enrollment"),
+            Recorder: mgr.GetEventRecorderFor("enrollment-server"),
+            Addr:     enrollmentAddr,
+            CertDir:  enrollmentCertDir,
+        }); err != nil {
+            setupLog.Error(err, "unable to set up enrollment API")
+            os.Exit(1)
+        }
+    }
+
+    networkPolicy := operatorNetworkPolicy == "true" || (operatorNetworkPolicy == "auto" && hardened)
+    if err := mgr.Add(&controllers.OperatorNetworkPolicy{
+        Client:            mgr.GetClient(),
+        Reader:            mgr.GetAPIReader(),
+        Log:               ctrl.Log.WithName("networkpolicy"),
+        Enabled:           networkPolicy,
+        Namespace:         operatorNamespace(),
+        Selector:          parseLabels(operatorSelector),
+        APIServerCIDRs:    splitList(apiServerCIDRs),
+        MetricsNamespaces: splitList(metricsNamespaces),
+        EgressCIDRs:       splitList(operatorEgressCIDRs),
+        WebhookPort:       webhookPort(enableWebhooks),
+        MetricsPort:       portOf(metricsAddr),
+        EnrollmentPort:    portOf(enrollmentAddr),
+    }); err != nil {
+        setupLog.Error(err, "unable to set up operator NetworkPolicy")
+        os.Exit(1)
+    }
+
+    if err := mgr.Add(&controllers.OrphanSweep{
+        Client: mgr.GetClient(),
+        Reader: mgr.GetAPIReader(),
+        Log:    ctrl.Log.WithName("orphans"),
+    }); err != nil {
+        setupLog.Error(err, "unable to set up orphan sweep")
         os.Exit(1)
     }
 
@@ -76,3 +352,55 @@ func main() {
         os.Exit(1)
     }
 }
+
+// splitList splits a comma-separated flag, dropping empty items
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// parseLabels parses k=v,k2=v2
+func parseLabels(s string) map[string]string {
+    labels := make(map[string]string)
+    for _, item := range splitList(s) {
+        k, v, _ := strings.Cut(item, "=")
+        labels[k] = v
+    }
+    return labels
+}
+
+// portOf returns the port of a bind address such as :8080, or 0
+func portOf(addr string) int32 {
+    _, port, err := net.SplitHostPort(addr)
+    if err != nil {
+        return 0
+    }
+    n, err := strconv.Atoi(port)
+    if err != nil {
+        return 0
+    }
+    return int32(n)
+}
+
+func webhookPort(enabled bool) int32 {
+    if !enabled {
+        return 0
+    }
+    return 9443
+}
+
+// operatorNamespace returns the namespace the manager runs in
+func operatorNamespace() string {
+    if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+        return ns
+    }
+    if ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+        return string(ns)
+    }
+    return "qraiop-system"
+}
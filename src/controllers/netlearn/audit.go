@@ -0,0 +1,160 @@
+// src/controllers/netlearn/audit.go
+package netlearn
+
+import (
+    "net"
+
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/labels"
+)
+
+// Denied returns the flows that a default-deny policy in namespace would
+// block, given the allow policies already there. It evaluates the standard
+// NetworkPolicy semantics against the flows' pods; named ports are assumed
+// to match since the flows only carry numbers. namespaces maps namespace
+// names to their labels, for namespaceSelectors.
+func Denied(namespace string, flows []Flow, pods []corev1.Pod, policies []networkingv1.NetworkPolicy, namespaces map[string]map[string]string) []Flow {
+    byIP := make(map[string]*corev1.Pod, len(pods))
+    for i := range pods {
+        if ip := pods[i].Status.PodIP; ip != "" {
+            byIP[ip] = &pods[i]
+        }
+    }
+    a := &auditor{namespace: namespace, policies: policies, namespaces: namespaces}
+
+    var denied []Flow
+    for _, f := range flows {
+        src, dst := byIP[f.SourceIP], byIP[f.DestinationIP]
+        pt := port{number: f.Port, protocol: protocol(f.Protocol)}
+        if dst != nil && dst.Namespace == namespace && !a.allowed(dst, networkingv1.PolicyTypeIngress, src, f.SourceIP, pt) {
+            denied = append(denied, f)
+            continue
+        }
+        if src != nil && src.Namespace == namespace && !a.allowed(src, networkingv1.PolicyTypeEgress, dst, f.DestinationIP, pt) {
+            denied = append(denied, f)
+        }
+    }
+    return denied
+}
+
+type auditor struct {
+    namespace  string
+    policies   []networkingv1.NetworkPolicy
+    namespaces map[string]map[string]string
+}
+
+// allowed reports whether any policy selecting pod lets the flow through
+// in direction. The peer pod may be nil for addresses outside the cluster.
+func (a *auditor) allowed(pod *corev1.Pod, direction networkingv1.PolicyType, peerPod *corev1.Pod, peerIP string, pt port) bool {
+    for i := range a.policies {
+        spec := &a.policies[i].Spec
+        if !selects(&spec.PodSelector, pod.Labels) || !hasType(spec, direction) {
+            continue
+        }
+        if direction == networkingv1.PolicyTypeIngress {
+            for _, rule := range spec.Ingress {
+                if a.peersMatch(rule.From, peerPod, peerIP) && portsMatch(rule.Ports, pt) {
+                    return true
+                }
+            }
+        } else {
+            for _, rule := range spec.Egress {
+                if a.peersMatch(rule.To, peerPod, peerIP) && portsMatch(rule.Ports, pt) {
+                    return true
+                }
+            }
+        }
+    }
+    return false
+}
+
+func hasType(spec *networkingv1.NetworkPolicySpec, t networkingv1.PolicyType) bool {
+    if len(spec.PolicyTypes) == 0 {
+        // Ingress is implied; egress only when egress rules are present
+        return t == networkingv1.PolicyTypeIngress || len(spec.Egress) > 0
+    }
+    for _, have := range spec.PolicyTypes {
+        if have == t {
+            return true
+        }
+    }
+    return false
+}
+
+func (a *auditor) peersMatch(peers []networkingv1.NetworkPolicyPeer, pod *corev1.Pod, ip string) bool {
+    if len(peers) == 0 {
+        return true
+    }
+    for _, p := range peers {
+        if p.IPBlock != nil {
+            if ipInBlock(ip, p.IPBlock) {
+                return true
+            }
+            continue
+        }
+        if pod == nil {
+            continue
+        }
+        if p.NamespaceSelector == nil {
+            if pod.Namespace == a.namespace && (p.PodSelector == nil || selects(p.PodSelector, pod.Labels)) {
+                return true
+            }
+            continue
+        }
+        if selects(p.NamespaceSelector, a.namespaces[pod.Namespace]) && (p.PodSelector == nil || selects(p.PodSelector, pod.Labels)) {
+            return true
+        }
+    }
+    return false
+}
+
+func portsMatch(ports []networkingv1.NetworkPolicyPort, pt port) bool {
+    if len(ports) == 0 {
+        return true
+    }
+    for _, p := range ports {
+        proto := corev1.ProtocolTCP
+        if p.Protocol != nil {
+            proto = *p.Protocol
+        }
+        if proto != pt.protocol {
+            continue
+        }
+        if p.Port == nil || p.Port.IntValue() == 0 {
+            // Any port, or a named port the flow can't be checked against
+            return true
+        }
+        end := int32(p.Port.IntValue())
+        if p.EndPort != nil {
+            end = *p.EndPort
+        }
+        if pt.number >= int32(p.Port.IntValue()) && pt.number <= end {
+            return true
+        }
+    }
+    return false
+}
+
+func selects(selector *metav1.LabelSelector, set map[string]string) bool {
+    s, err := metav1.LabelSelectorAsSelector(selector)
+    if err != nil {
+        return false
+    }
+    return s.Matches(labels.Set(set))
+}
+
+func ipInBlock(ip string, block *networkingv1.IPBlock) bool {
+    addr := net.ParseIP(ip)
+    _, cidr, err := net.ParseCIDR(block.CIDR)
+    if addr == nil || err != nil || !cidr.Contains(addr) {
+        return false
+    }
+    for _, except := range block.Except {
+        if _, ex, err := net.ParseCIDR(except); err == nil && ex.Contains(addr) {
+            return false
+        }
+    }
+    return true
+}
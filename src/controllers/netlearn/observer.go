@@ -0,0 +1,76 @@
+// src/controllers/netlearn/observer.go
+package netlearn
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+    // FlowRecordLabel marks ConfigMaps holding flow records published by a collector
+    FlowRecordLabel = "qraiop.io/flow-records"
+    // FlowSourceLabel names the collector (hubble, conntrack, flowlogs) that wrote the records
+    FlowSourceLabel = "qraiop.io/flow-source"
+    // FlowRecordKey is the ConfigMap data key holding newline-delimited JSON flows
+    FlowRecordKey = "flows.jsonl"
+)
+
+// Flow is a single observed connection, normalized across collectors
+type Flow struct {
+    SourceIP      string    `json:"src"`
+    DestinationIP string    `json:"dst"`
+    Port          int32     `json:"port"`
+    Protocol      string    `json:"protocol,omitempty"`
+    Timestamp     time.Time `json:"ts"`
+}
+
+// Observer returns the flows observed in a namespace since a point in time
+type Observer interface {
+    Flows(ctx context.Context, namespace string, since time.Time) ([]Flow, error)
+}
+
+// ConfigMapObserver reads flow records that collectors (a Hubble exporter,
+// the conntrack agent or a flow log shipper) publish as ConfigMaps in the
+// observed namespace
+type ConfigMapObserver struct {
+    Client client.Reader
+    Source string
+}
+
+// Flows implements Observer. Malformed records are skipped rather than
+// failing the whole observation window.
+func (o *ConfigMapObserver) Flows(ctx context.Context, namespace string, since time.Time) ([]Flow, error) {
+    var records corev1.ConfigMapList
+    if err := o.Client.List(ctx, &records,
+        client.InNamespace(namespace),
+        client.MatchingLabels{FlowRecordLabel: "true", FlowSourceLabel: o.Source},
+    ); err != nil {
+        return nil, err
+    }
+
+    var flows []Flow
+    for _, cm := range records.Items {
+        scanner := bufio.NewScanner(strings.NewReader(cm.Data[FlowRecordKey]))
+        for scanner.Scan() {
+            line := strings.TrimSpace(scanner.Text())
+            if line == "" {
+                continue
+            }
+            var f Flow
+            if err := json.Unmarshal([]byte(line), &f); err != nil {
+                continue
+            }
+            if f.Timestamp.Before(since) {
+                continue
+            }
+            flows = append(flows, f)
+        }
+    }
+    return flows, nil
+}
@@ -0,0 +1,199 @@
+// src/controllers/netlearn/proposer.go
+package netlearn
+
+import (
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// workloadLabels are checked in order to identify the workload a pod belongs to
+var workloadLabels = []string{"app.kubernetes.io/name", "app"}
+
+// peer is one side of an observed connection, either a workload or a bare IP
+type peer struct {
+    namespace string
+    labelKey  string
+    labelVal  string
+    cidr      string
+}
+
+type port struct {
+    number   int32
+    protocol corev1.Protocol
+}
+
+// workload accumulates the observed peers of a local workload
+type workload struct {
+    labelKey string
+    labelVal string
+    ingress  map[peer]map[port]bool
+    egress   map[peer]map[port]bool
+}
+
+// Propose builds least-privilege NetworkPolicies for the workloads in
+// namespace from observed flows. Peers are resolved to workloads through
+// pod IPs; peers that don't resolve to a pod are allowed by /32 ipBlock.
+// Pods without a workload label can't be selected and are ignored.
+func Propose(namespace string, flows []Flow, pods []corev1.Pod) []networkingv1.NetworkPolicy {
+    byIP := make(map[string]*corev1.Pod, len(pods))
+    for i := range pods {
+        if ip := pods[i].Status.PodIP; ip != "" {
+            byIP[ip] = &pods[i]
+        }
+    }
+
+    workloads := map[string]*workload{}
+    local := func(p peer) *workload {
+        if p.namespace != namespace || p.labelKey == "" {
+            return nil
+        }
+        w, ok := workloads[p.labelVal]
+        if !ok {
+            w = &workload{
+                labelKey: p.labelKey,
+                labelVal: p.labelVal,
+                ingress:  map[peer]map[port]bool{},
+                egress:   map[peer]map[port]bool{},
+            }
+            workloads[p.labelVal] = w
+        }
+        return w
+    }
+
+    for _, f := range flows {
+        src := resolve(f.SourceIP, byIP)
+        dst := resolve(f.DestinationIP, byIP)
+        pt := port{number: f.Port, protocol: protocol(f.Protocol)}
+
+        if w := local(dst); w != nil {
+            add(w.ingress, src, pt)
+        }
+        if w := local(src); w != nil {
+            add(w.egress, dst, pt)
+        }
+    }
+
+    names := make([]string, 0, len(workloads))
+    for name := range workloads {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    policies := make([]networkingv1.NetworkPolicy, 0, len(names))
+    for _, name := range names {
+        w := workloads[name]
+        policy := networkingv1.NetworkPolicy{
+            TypeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+            ObjectMeta: metav1.ObjectMeta{
+                Name:      name + "-learned",
+                Namespace: namespace,
+            },
+            Spec: networkingv1.NetworkPolicySpec{
+                PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{w.labelKey: w.labelVal}},
+                PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+            },
+        }
+        for _, p := range sortedPeers(w.ingress) {
+            policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+                From:  []networkingv1.NetworkPolicyPeer{p.toPolicyPeer()},
+                Ports: policyPorts(w.ingress[p]),
+            })
+        }
+        for _, p := range sortedPeers(w.egress) {
+            policy.Spec.Egress = append(policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+                To:    []networkingv1.NetworkPolicyPeer{p.toPolicyPeer()},
+                Ports: policyPorts(w.egress[p]),
+            })
+        }
+        policies = append(policies, policy)
+    }
+    return policies
+}
+
+func resolve(ip string, byIP map[string]*corev1.Pod) peer {
+    pod, ok := byIP[ip]
+    if !ok {
+        return peer{cidr: ip + "/32"}
+    }
+    for _, key := range workloadLabels {
+        if val, ok := pod.Labels[key]; ok {
+            return peer{namespace: pod.Namespace, labelKey: key, labelVal: val}
+        }
+    }
+    return peer{cidr: ip + "/32"}
+}
+
+func protocol(p string) corev1.Protocol {
+    switch p {
+    case "UDP", "udp":
+        return corev1.ProtocolUDP
+    case "SCTP", "sctp":
+        return corev1.ProtocolSCTP
+    default:
+        return corev1.ProtocolTCP
+    }
+}
+
+func add(rules map[peer]map[port]bool, p peer, pt port) {
+    if rules[p] == nil {
+        rules[p] = map[port]bool{}
+    }
+    rules[p][pt] = true
+}
+
+func sortedPeers(rules map[peer]map[port]bool) []peer {
+    peers := make([]peer, 0, len(rules))
+    for p := range rules {
+        peers = append(peers, p)
+    }
+    sort.Slice(peers, func(i, j int) bool {
+        a, b := peers[i], peers[j]
+        if a.namespace != b.namespace {
+            return a.namespace < b.namespace
+        }
+        if a.labelVal != b.labelVal {
+            return a.labelVal < b.labelVal
+        }
+        return a.cidr < b.cidr
+    })
+    return peers
+}
+
+func policyPorts(ports map[port]bool) []networkingv1.NetworkPolicyPort {
+    sorted := make([]port, 0, len(ports))
+    for p := range ports {
+        sorted = append(sorted, p)
+    }
+    sort.Slice(sorted, func(i, j int) bool {
+        if sorted[i].number != sorted[j].number {
+            return sorted[i].number < sorted[j].number
+        }
+        return sorted[i].protocol < sorted[j].protocol
+    })
+
+    out := make([]networkingv1.NetworkPolicyPort, 0, len(sorted))
+    for _, p := range sorted {
+        proto := p.protocol
+        num := intstr.FromInt32(p.number)
+        out = append(out, networkingv1.NetworkPolicyPort{Protocol: &proto, Port: &num})
+    }
+    return out
+}
+
+func (p peer) toPolicyPeer() networkingv1.NetworkPolicyPeer {
+    if p.cidr != "" {
+        return networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: p.cidr}}
+    }
+    return networkingv1.NetworkPolicyPeer{
+        NamespaceSelector: &metav1.LabelSelector{
+            MatchLabels: map[string]string{"kubernetes.io/metadata.name": p.namespace},
+        },
+        PodSelector: &metav1.LabelSelector{
+            MatchLabels: map[string]string{p.labelKey: p.labelVal},
+        },
+    }
+}
@@ -0,0 +1,146 @@
+// src/controllers/pkg/qraiopclient/client.go
+
+// Package qraiopclient is a typed client, lister and informer helper for the
+// qraiop.io API group, built on controller-runtime, for teams automating
+// against Qraiop and ChaosExperiment resources without copying the API types.
+//
+// One-off reads and writes go through Client:
+//
+//	c, err := qraiopclient.New(ctrl.GetConfigOrDie())
+//	q, err := c.GetQraiop(ctx, "qraiop-system", "production-cluster")
+//	err = c.SetPaused(ctx, "qraiop-system", "production-cluster", true)
+//
+// Long-running automation should share an informer cache instead of
+// polling the API server:
+//
+//	f, err := qraiopclient.NewInformerFactory(cfg, cache.Options{})
+//	informer, err := f.QraiopInformer(ctx)
+//	informer.AddEventHandler(handler)
+//	go f.Start(ctx)
+//	f.WaitForCacheSync(ctx)
+//	instances, err := f.QraiopLister().List(ctx, "", labels.Everything())
+package qraiopclient
+
+import (
+    "context"
+    "crypto/rsa"
+    "encoding/json"
+
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/types"
+    clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+    "k8s.io/client-go/rest"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/sealed"
+)
+
+// NewScheme returns a scheme with the built-in Kubernetes types and the
+// qraiop.io API group registered
+func NewScheme() (*runtime.Scheme, error) {
+    scheme := runtime.NewScheme()
+    if err := clientgoscheme.AddToScheme(scheme); err != nil {
+        return nil, err
+    }
+    if err := qraiopv1.AddToScheme(scheme); err != nil {
+        return nil, err
+    }
+    return scheme, nil
+}
+
+// Client is a typed client for qraiop.io resources. The embedded
+// controller-runtime client handles any other object.
+type Client struct {
+    client.Client
+}
+
+// New returns a Client for the cluster described by cfg
+func New(cfg *rest.Config) (*Client, error) {
+    scheme, err := NewScheme()
+    if err != nil {
+        return nil, err
+    }
+    c, err := client.New(cfg, client.Options{Scheme: scheme})
+    if err != nil {
+        return nil, err
+    }
+    return &Client{Client: c}, nil
+}
+
+// NewForClient wraps an existing controller-runtime client, such as a
+// manager's, whose scheme already has qraiop.io registered
+func NewForClient(c client.Client) *Client {
+    return &Client{Client: c}
+}
+
+// GetQraiop returns the named Qraiop instance
+func (c *Client) GetQraiop(ctx context.Context, namespace, name string) (*qraiopv1.Qraiop, error) {
+    var q qraiopv1.Qraiop
+    if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &q); err != nil {
+        return nil, err
+    }
+    return &q, nil
+}
+
+// ListQraiops returns the Qraiop instances in namespace, or in every
+// namespace when namespace is empty
+func (c *Client) ListQraiops(ctx context.Context, namespace string, opts ...client.ListOption) ([]qraiopv1.Qraiop, error) {
+    var list qraiopv1.QraiopList
+    if namespace != "" {
+        opts = append(opts, client.InNamespace(namespace))
+    }
+    if err := c.List(ctx, &list, opts...); err != nil {
+        return nil, err
+    }
+    return list.Items, nil
+}
+
+// SetPaused pauses or resumes reconciliation of an instance through the
+// qraiop.io/paused annotation, leaving its spec untouched
+func (c *Client) SetPaused(ctx context.Context, namespace, name string, paused bool) error {
+    var value interface{} // null removes the annotation
+    if paused {
+        value = "true"
+    }
+    patch, err := json.Marshal(map[string]interface{}{
+        "metadata": map[string]interface{}{
+            "annotations": map[string]interface{}{qraiopv1.PausedAnnotation: value},
+        },
+    })
+    if err != nil {
+        return err
+    }
+    q := &qraiopv1.Qraiop{}
+    q.Namespace, q.Name = namespace, name
+    return c.Patch(ctx, q, client.RawPatch(types.MergePatchType, patch))
+}
+
+// SealingKey returns the public key the operator running in
+// operatorNamespace unseals qraiopsealed:// values with
+func (c *Client) SealingKey(ctx context.Context, operatorNamespace string) (*rsa.PublicKey, error) {
+    var cm corev1.ConfigMap
+    if err := c.Get(ctx, types.NamespacedName{Namespace: operatorNamespace, Name: sealed.KeyName}, &cm); err != nil {
+        return nil, err
+    }
+    return sealed.ParsePublicKey([]byte(cm.Data[sealed.PublicKeyFile]))
+}
+
+// RevokeCertificate revokes the certificate in a TLS Secret through the
+// qraiop.io/revoke annotation. The operator puts it on the next
+// revocation list it publishes.
+func (c *Client) RevokeCertificate(ctx context.Context, namespace, secret, reason string) error {
+    patch, err := json.Marshal(map[string]interface{}{
+        "metadata": map[string]interface{}{
+            "annotations": map[string]interface{}{qraiopv1.RevokeAnnotation: reason},
+        },
+    })
+    if err != nil {
+        return err
+    }
+    s := &corev1.Secret{}
+    s.Namespace, s.Name = namespace, secret
+    return c.Patch(ctx, s, client.RawPatch(types.MergePatchType, patch))
+}
@@ -0,0 +1,43 @@
+// src/controllers/pkg/qraiopclient/experiments.go
+package qraiopclient
+
+import (
+    "context"
+
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// GetChaosExperiment returns the named experiment
+func (c *Client) GetChaosExperiment(ctx context.Context, namespace, name string) (*qraiopv1.ChaosExperiment, error) {
+    var exp qraiopv1.ChaosExperiment
+    if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &exp); err != nil {
+        return nil, err
+    }
+    return &exp, nil
+}
+
+// ListChaosExperiments returns the experiments in namespace, or in every
+// namespace when namespace is empty
+func (c *Client) ListChaosExperiments(ctx context.Context, namespace string, opts ...client.ListOption) ([]qraiopv1.ChaosExperiment, error) {
+    var list qraiopv1.ChaosExperimentList
+    if namespace != "" {
+        opts = append(opts, client.InNamespace(namespace))
+    }
+    if err := c.List(ctx, &list, opts...); err != nil {
+        return nil, err
+    }
+    return list.Items, nil
+}
+
+// AbortChaosExperiment asks the controller to stop an experiment. The
+// controller reverts its faults and sets the verdict to Aborted; callers
+// can watch Status.Phase to confirm.
+func (c *Client) AbortChaosExperiment(ctx context.Context, namespace, name string) error {
+    exp := &qraiopv1.ChaosExperiment{}
+    exp.Namespace, exp.Name = namespace, name
+    patch := []byte(`{"spec":{"abort":true}}`)
+    return c.Patch(ctx, exp, client.RawPatch(types.MergePatchType, patch))
+}
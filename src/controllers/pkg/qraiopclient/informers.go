@@ -0,0 +1,135 @@
+// src/controllers/pkg/qraiopclient/informers.go
+package qraiopclient
+
+import (
+    "context"
+
+    "k8s.io/apimachinery/pkg/labels"
+    "k8s.io/apimachinery/pkg/types"
+    "k8s.io/client-go/rest"
+    "sigs.k8s.io/controller-runtime/pkg/cache"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// InformerFactory provides shared informers and listers for qraiop.io
+// resources, backed by a single controller-runtime cache
+type InformerFactory struct {
+    cache cache.Cache
+}
+
+// NewInformerFactory returns a factory for the cluster described by cfg.
+// opts.Scheme is replaced with one that has qraiop.io registered.
+func NewInformerFactory(cfg *rest.Config, opts cache.Options) (*InformerFactory, error) {
+    scheme, err := NewScheme()
+    if err != nil {
+        return nil, err
+    }
+    opts.Scheme = scheme
+    c, err := cache.New(cfg, opts)
+    if err != nil {
+        return nil, err
+    }
+    return &InformerFactory{cache: c}, nil
+}
+
+// NewInformerFactoryForCache wraps an existing cache, such as a manager's
+func NewInformerFactoryForCache(c cache.Cache) *InformerFactory {
+    return &InformerFactory{cache: c}
+}
+
+// Start runs the informers until ctx is cancelled
+func (f *InformerFactory) Start(ctx context.Context) error {
+    return f.cache.Start(ctx)
+}
+
+// WaitForCacheSync blocks until every requested informer has synced
+func (f *InformerFactory) WaitForCacheSync(ctx context.Context) bool {
+    return f.cache.WaitForCacheSync(ctx)
+}
+
+// QraiopInformer returns the shared informer for Qraiop, for registering
+// event handlers
+func (f *InformerFactory) QraiopInformer(ctx context.Context) (cache.Informer, error) {
+    return f.cache.GetInformer(ctx, &qraiopv1.Qraiop{})
+}
+
+// QraiopLister returns a lister reading Qraiop instances from the cache
+func (f *InformerFactory) QraiopLister() *QraiopLister {
+    return &QraiopLister{reader: f.cache}
+}
+
+// QraiopLister reads Qraiop instances from an informer cache
+type QraiopLister struct {
+    reader client.Reader
+}
+
+// List returns the instances in namespace matching selector, or in every
+// namespace when namespace is empty
+func (l *QraiopLister) List(ctx context.Context, namespace string, selector labels.Selector) ([]*qraiopv1.Qraiop, error) {
+    var list qraiopv1.QraiopList
+    opts := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+    if namespace != "" {
+        opts = append(opts, client.InNamespace(namespace))
+    }
+    if err := l.reader.List(ctx, &list, opts...); err != nil {
+        return nil, err
+    }
+    out := make([]*qraiopv1.Qraiop, 0, len(list.Items))
+    for i := range list.Items {
+        out = append(out, &list.Items[i])
+    }
+    return out, nil
+}
+
+// Get returns the named instance
+func (l *QraiopLister) Get(ctx context.Context, namespace, name string) (*qraiopv1.Qraiop, error) {
+    var q qraiopv1.Qraiop
+    if err := l.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &q); err != nil {
+        return nil, err
+    }
+    return &q, nil
+}
+
+// ChaosExperimentInformer returns the shared informer for ChaosExperiment
+func (f *InformerFactory) ChaosExperimentInformer(ctx context.Context) (cache.Informer, error) {
+    return f.cache.GetInformer(ctx, &qraiopv1.ChaosExperiment{})
+}
+
+// ChaosExperimentLister returns a lister reading experiments from the cache
+func (f *InformerFactory) ChaosExperimentLister() *ChaosExperimentLister {
+    return &ChaosExperimentLister{reader: f.cache}
+}
+
+// ChaosExperimentLister reads ChaosExperiments from an informer cache
+type ChaosExperimentLister struct {
+    reader client.Reader
+}
+
+// List returns the experiments in namespace matching selector, or in every
+// namespace when namespace is empty
+func (l *ChaosExperimentLister) List(ctx context.Context, namespace string, selector labels.Selector) ([]*qraiopv1.ChaosExperiment, error) {
+    var list qraiopv1.ChaosExperimentList
+    opts := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+    if namespace != "" {
+        opts = append(opts, client.InNamespace(namespace))
+    }
+    if err := l.reader.List(ctx, &list, opts...); err != nil {
+        return nil, err
+    }
+    out := make([]*qraiopv1.ChaosExperiment, 0, len(list.Items))
+    for i := range list.Items {
+        out = append(out, &list.Items[i])
+    }
+    return out, nil
+}
+
+// Get returns the named experiment
+func (l *ChaosExperimentLister) Get(ctx context.Context, namespace, name string) (*qraiopv1.ChaosExperiment, error) {
+    var exp qraiopv1.ChaosExperiment
+    if err := l.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &exp); err != nil {
+        return nil, err
+    }
+    return &exp, nil
+}
@@ -0,0 +1,123 @@
+// src/controllers/pkg/qraiopclient/logs.go
+package qraiopclient
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "strings"
+    "sync"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/labels"
+    "k8s.io/client-go/kubernetes"
+    "k8s.io/client-go/rest"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// ComponentDeployments maps component names to the workloads the operator
+// creates for them
+var ComponentDeployments = map[string]string{
+    qraiopv1.ComponentCryptography:     "qraiop-crypto",
+    qraiopv1.ComponentAIOrchestration:  "qraiop-ai",
+    qraiopv1.ComponentChaosEngineering: "qraiop-chaos",
+    qraiopv1.ComponentMonitoring:       "qraiop-monitoring",
+}
+
+// componentAliases are the short component names accepted on the command line
+var componentAliases = map[string]string{
+    "crypto": qraiopv1.ComponentCryptography,
+    "ai":     qraiopv1.ComponentAIOrchestration,
+    "chaos":  qraiopv1.ComponentChaosEngineering,
+}
+
+// ComponentDeployment returns the Deployment of a component, given by its
+// name, a short name such as crypto, or as cryptography/<pool> for a
+// crypto pool
+func ComponentDeployment(component string) (string, error) {
+    name, pool, _ := strings.Cut(component, "/")
+    if alias, ok := componentAliases[name]; ok {
+        name = alias
+    }
+    if pool != "" && name == qraiopv1.ComponentCryptography {
+        return "qraiop-crypto-" + pool, nil
+    }
+    if deployment, ok := ComponentDeployments[name]; ok && pool == "" {
+        return deployment, nil
+    }
+    return "", fmt.Errorf("unknown component %q", component)
+}
+
+// LogOptions select the log lines StreamComponentLogs prints
+type LogOptions struct {
+    // Instance limits the pods to those of one Qraiop instance
+    Instance string
+    // Follow streams new lines until ctx is done
+    Follow bool
+    // Since only shows lines newer than this
+    Since time.Duration
+    // TailLines only shows this many recent lines per pod
+    TailLines *int64
+}
+
+// StreamComponentLogs writes the logs of every pod of a component across
+// its replicas to w, each line prefixed with its pod. Lines of different
+// pods are interleaved as they arrive. Pods started after the call, e.g.
+// by a rollout, are not picked up.
+func StreamComponentLogs(ctx context.Context, cfg *rest.Config, namespace, component string, opts LogOptions, w io.Writer) error {
+    deployment, err := ComponentDeployment(component)
+    if err != nil {
+        return err
+    }
+    cs, err := kubernetes.NewForConfig(cfg)
+    if err != nil {
+        return err
+    }
+    selector := labels.Set{"app.kubernetes.io/name": deployment}
+    if opts.Instance != "" {
+        selector["app.kubernetes.io/instance"] = opts.Instance
+    }
+    pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+    if err != nil {
+        return err
+    }
+    if len(pods.Items) == 0 {
+        return fmt.Errorf("no pods of %s in %s", component, namespace)
+    }
+
+    logOpts := &corev1.PodLogOptions{Follow: opts.Follow, TailLines: opts.TailLines}
+    if opts.Since > 0 {
+        seconds := int64(opts.Since.Seconds())
+        logOpts.SinceSeconds = &seconds
+    }
+    var (
+        mu   sync.Mutex
+        wg   sync.WaitGroup
+        errs = make(chan error, len(pods.Items))
+    )
+    for _, pod := range pods.Items {
+        wg.Add(1)
+        go func(pod string) {
+            defer wg.Done()
+            stream, err := cs.CoreV1().Pods(namespace).GetLogs(pod, logOpts).Stream(ctx)
+            if err != nil {
+                errs <- fmt.Errorf("%s: %w", pod, err)
+                return
+            }
+            defer stream.Close()
+            scanner := bufio.NewScanner(stream)
+            for scanner.Scan() {
+                mu.Lock()
+                fmt.Fprintf(w, "[%s] %s\n", pod, scanner.Text())
+                mu.Unlock()
+            }
+        }(pod.Name)
+    }
+    wg.Wait()
+    close(errs)
+    return <-errs
+}
@@ -0,0 +1,143 @@
+// src/controllers/pkg/qraiopclient/orphans.go
+package qraiopclient
+
+import (
+    "context"
+    "sort"
+
+    appsv1 "k8s.io/api/apps/v1"
+    batchv1 "k8s.io/api/batch/v1"
+    corev1 "k8s.io/api/core/v1"
+    networkingv1 "k8s.io/api/networking/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// orphanKinds are the kinds the operator creates for an instance, by the
+// list they are found with
+var orphanKinds = []struct {
+    kind string
+    list func() client.ObjectList
+}{
+    {"Deployment", func() client.ObjectList { return &appsv1.DeploymentList{} }},
+    {"Service", func() client.ObjectList { return &corev1.ServiceList{} }},
+    {"ConfigMap", func() client.ObjectList { return &corev1.ConfigMapList{} }},
+    {"Secret", func() client.ObjectList { return &corev1.SecretList{} }},
+    {"ServiceAccount", func() client.ObjectList { return &corev1.ServiceAccountList{} }},
+    {"NetworkPolicy", func() client.ObjectList { return &networkingv1.NetworkPolicyList{} }},
+    {"CronJob", func() client.ObjectList { return &batchv1.CronJobList{} }},
+    {"Role", func() client.ObjectList { return &rbacv1.RoleList{} }},
+    {"RoleBinding", func() client.ObjectList { return &rbacv1.RoleBindingList{} }},
+}
+
+// Orphan is an object the operator created for an instance that no longer
+// exists, typically because uninstalling the CRD deleted every Qraiop
+type Orphan struct {
+    Kind      string `json:"kind"`
+    Namespace string `json:"namespace"`
+    Name      string `json:"name"`
+    // Instance is the name of the Qraiop the object was created for
+    Instance string `json:"instance"`
+    // Adoptable is set when an instance of that name exists again
+    Adoptable bool `json:"adoptable"`
+
+    Object client.Object `json:"-"`
+}
+
+// FindOrphans returns the objects labelled as managed by the operator in
+// namespace, or in every namespace when namespace is empty, that are not
+// controlled by an existing Qraiop, sorted by namespace, kind and name.
+// Objects controlled by something else, such as the injected certificate
+// Secrets of a Deployment, are not orphans.
+func FindOrphans(ctx context.Context, r client.Reader, namespace string) ([]Orphan, error) {
+    var instances qraiopv1.QraiopList
+    if err := r.List(ctx, &instances, client.InNamespace(namespace)); err != nil {
+        return nil, err
+    }
+    uids := make(map[types.UID]bool, len(instances.Items))
+    names := make(map[types.NamespacedName]bool, len(instances.Items))
+    for _, q := range instances.Items {
+        uids[q.UID] = true
+        names[types.NamespacedName{Namespace: q.Namespace, Name: q.Name}] = true
+    }
+
+    var orphans []Orphan
+    for _, k := range orphanKinds {
+        list := k.list()
+        if err := r.List(ctx, list, client.InNamespace(namespace),
+            client.MatchingLabels{"app.kubernetes.io/managed-by": "qraiop-controller"}); err != nil {
+            return nil, err
+        }
+        objs, err := meta.ExtractList(list)
+        if err != nil {
+            return nil, err
+        }
+        for _, item := range objs {
+            obj, ok := item.(client.Object)
+            if !ok {
+                continue
+            }
+            owner := metav1.GetControllerOf(obj)
+            if owner != nil && (owner.Kind != "Qraiop" || uids[owner.UID]) {
+                continue
+            }
+            instance := obj.GetLabels()["app.kubernetes.io/instance"]
+            if instance == "" && owner != nil {
+                instance = owner.Name
+            }
+            orphans = append(orphans, Orphan{
+                Kind:      k.kind,
+                Namespace: obj.GetNamespace(),
+                Name:      obj.GetName(),
+                Instance:  instance,
+                Adoptable: instance != "" && names[types.NamespacedName{Namespace: obj.GetNamespace(), Name: instance}],
+                Object:    obj,
+            })
+        }
+    }
+    sort.Slice(orphans, func(i, j int) bool {
+        a, b := orphans[i], orphans[j]
+        if a.Namespace != b.Namespace {
+            return a.Namespace < b.Namespace
+        }
+        if a.Kind != b.Kind {
+            return a.Kind < b.Kind
+        }
+        return a.Name < b.Name
+    })
+    return orphans, nil
+}
+
+// Orphans returns the orphaned objects in namespace, or in every namespace
+// when namespace is empty
+func (c *Client) Orphans(ctx context.Context, namespace string) ([]Orphan, error) {
+    return FindOrphans(ctx, c, namespace)
+}
+
+// Adopt makes q the controller of an orphan, replacing the owner
+// reference to the instance it was created for
+func (c *Client) Adopt(ctx context.Context, o Orphan, q *qraiopv1.Qraiop) error {
+    obj := o.Object
+    patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+    refs := make([]metav1.OwnerReference, 0, len(obj.GetOwnerReferences()))
+    for _, ref := range obj.GetOwnerReferences() {
+        if ref.Kind != "Qraiop" {
+            refs = append(refs, ref)
+        }
+    }
+    refs = append(refs, *metav1.NewControllerRef(q, qraiopv1.GroupVersion.WithKind("Qraiop")))
+    obj.SetOwnerReferences(refs)
+    return c.Patch(ctx, obj, patch)
+}
+
+// DeleteOrphan deletes an orphan along with the objects it owns
+func (c *Client) DeleteOrphan(ctx context.Context, o Orphan) error {
+    uid := o.Object.GetUID()
+    return client.IgnoreNotFound(c.Delete(ctx, o.Object, client.Preconditions{UID: &uid},
+        client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
@@ -0,0 +1,142 @@
+// src/controllers/pkg/schema/schema.go
+
+// Package schema validates qraiop.io manifests offline, against the
+// OpenAPI schemas of the CRDs and the rules of the admission webhooks that
+// need no cluster, and exports the schemas as JSON Schema for editors and
+// other tooling.
+//
+//	problems, err := schema.ValidateManifests(data)
+//	for _, p := range problems {
+//		fmt.Println(p)
+//	}
+//
+//	js, err := schema.JSONSchema("Qraiop")
+//
+// The CRDs are embedded from crds/, which `make schema` generates from the
+// API types with controller-gen. Rules needing the cluster, such as the
+// ChaosPermission checks on ChaosExperiments, are left to admission.
+package schema
+
+import (
+    "embed"
+    "encoding/json"
+    "fmt"
+    "io/fs"
+    "path"
+    "sort"
+    "strings"
+    "sync"
+
+    "sigs.k8s.io/yaml"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+//go:generate controller-gen crd paths=../../api/... output:crd:dir=crds
+
+//go:embed crds
+var crdFiles embed.FS
+
+// crd is the part of a CustomResourceDefinition the schemas are read from
+type crd struct {
+    Spec struct {
+        Group string `json:"group"`
+        Names struct {
+            Kind string `json:"kind"`
+        } `json:"names"`
+        Versions []struct {
+            Name   string `json:"name"`
+            Schema struct {
+                OpenAPIV3Schema *Schema `json:"openAPIV3Schema"`
+            } `json:"schema"`
+        } `json:"versions"`
+    } `json:"spec"`
+}
+
+var (
+    loadOnce sync.Once
+    schemas  map[string]*Schema
+    loadErr  error
+)
+
+// load reads the v1 schema of every embedded CRD, by kind
+func load() (map[string]*Schema, error) {
+    loadOnce.Do(func() {
+        schemas = make(map[string]*Schema)
+        loadErr = fs.WalkDir(crdFiles, "crds", func(p string, d fs.DirEntry, err error) error {
+            if err != nil || d.IsDir() || path.Ext(p) != ".yaml" {
+                return err
+            }
+            data, err := crdFiles.ReadFile(p)
+            if err != nil {
+                return err
+            }
+            var c crd
+            if err := yaml.Unmarshal(data, &c); err != nil {
+                return fmt.Errorf("%s: %w", p, err)
+            }
+            if c.Spec.Group != qraiopv1.GroupVersion.Group {
+                return nil
+            }
+            for _, v := range c.Spec.Versions {
+                if v.Name == qraiopv1.GroupVersion.Version && v.Schema.OpenAPIV3Schema != nil {
+                    schemas[c.Spec.Names.Kind] = v.Schema.OpenAPIV3Schema
+                }
+            }
+            return nil
+        })
+        if loadErr == nil && len(schemas) == 0 {
+            loadErr = fmt.Errorf("no CRD schemas embedded; run make schema before building")
+        }
+    })
+    return schemas, loadErr
+}
+
+// Kinds returns the kinds schemas are known for
+func Kinds() ([]string, error) {
+    all, err := load()
+    if err != nil {
+        return nil, err
+    }
+    kinds := make([]string, 0, len(all))
+    for k := range all {
+        kinds = append(kinds, k)
+    }
+    sort.Strings(kinds)
+    return kinds, nil
+}
+
+// OpenAPI returns the OpenAPI v3 schema of a kind, as in its CRD
+func OpenAPI(kind string) (*Schema, error) {
+    all, err := load()
+    if err != nil {
+        return nil, err
+    }
+    s, ok := all[kind]
+    if !ok {
+        return nil, fmt.Errorf("unknown kind %s", kind)
+    }
+    return s, nil
+}
+
+// JSONSchema returns the schema of a kind as a JSON Schema (draft 2020-12)
+// document. Kubernetes extensions are kept as x- keywords, which JSON
+// Schema validators ignore.
+func JSONSchema(kind string) ([]byte, error) {
+    s, err := OpenAPI(kind)
+    if err != nil {
+        return nil, err
+    }
+    doc := map[string]interface{}{}
+    raw, err := json.Marshal(s)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(raw, &doc); err != nil {
+        return nil, err
+    }
+    doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+    doc["$id"] = fmt.Sprintf("https://%s/schemas/%s/%s.json", qraiopv1.GroupVersion.Group, qraiopv1.GroupVersion.Version, strings.ToLower(kind))
+    doc["title"] = kind
+    return json.MarshalIndent(doc, "", "  ")
+}
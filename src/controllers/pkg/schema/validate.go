@@ -0,0 +1,324 @@
+// src/controllers/pkg/schema/validate.go
+package schema
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "math"
+    "regexp"
+    "sort"
+    "strings"
+
+    utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Schema is the subset of an OpenAPI v3 schema that structural CRD
+// schemas use
+type Schema struct {
+    Type                 string             `json:"type,omitempty"`
+    Description          string             `json:"description,omitempty"`
+    Format               string             `json:"format,omitempty"`
+    Properties           map[string]*Schema `json:"properties,omitempty"`
+    AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+    Items                *Schema            `json:"items,omitempty"`
+    Required             []string           `json:"required,omitempty"`
+    Enum                 []interface{}      `json:"enum,omitempty"`
+    Pattern              string             `json:"pattern,omitempty"`
+    Minimum              *float64           `json:"minimum,omitempty"`
+    Maximum              *float64           `json:"maximum,omitempty"`
+    MinLength            *int64             `json:"minLength,omitempty"`
+    MaxLength            *int64             `json:"maxLength,omitempty"`
+    MinItems             *int64             `json:"minItems,omitempty"`
+    MaxItems             *int64             `json:"maxItems,omitempty"`
+    Default              interface{}        `json:"default,omitempty"`
+    Nullable             bool               `json:"nullable,omitempty"`
+    AnyOf                []*Schema          `json:"anyOf,omitempty"`
+
+    IntOrString           bool `json:"x-kubernetes-int-or-string,omitempty"`
+    PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+    EmbeddedResource      bool `json:"x-kubernetes-embedded-resource,omitempty"`
+}
+
+// Problem is a reason a manifest would be refused, or, as a warning, a
+// reason it would be accepted with an admission warning
+type Problem struct {
+    // Document is the index of the YAML document in the manifest
+    Document int
+    Kind     string
+    Name     string
+    // Field is the path of the offending field, e.g. spec.cryptography.securityLevel
+    Field   string
+    Message string
+    Warning bool
+}
+
+func (p Problem) String() string {
+    severity := "error"
+    if p.Warning {
+        severity = "warning"
+    }
+    obj := fmt.Sprintf("document %d", p.Document)
+    if p.Kind != "" {
+        obj = p.Kind + "/" + p.Name
+    }
+    if p.Field == "" {
+        return fmt.Sprintf("%s: %s: %s", severity, obj, p.Message)
+    }
+    return fmt.Sprintf("%s: %s: %s: %s", severity, obj, p.Field, p.Message)
+}
+
+// ValidateManifests checks every qraiop.io document of a YAML or JSON
+// stream. Documents of other API groups are skipped. The error is only set
+// when the stream can't be parsed.
+func ValidateManifests(data []byte) ([]Problem, error) {
+    decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+    var problems []Problem
+    for i := 0; ; i++ {
+        var doc map[string]interface{}
+        if err := decoder.Decode(&doc); err != nil {
+            if err == io.EOF {
+                return problems, nil
+            }
+            return problems, fmt.Errorf("document %d: %w", i, err)
+        }
+        if doc == nil {
+            continue
+        }
+        found, err := Validate(doc)
+        if err != nil {
+            return problems, fmt.Errorf("document %d: %w", i, err)
+        }
+        for _, p := range found {
+            p.Document = i
+            problems = append(problems, p)
+        }
+    }
+}
+
+// Validate checks one object, decoded from YAML or JSON, if it is of the
+// qraiop.io group
+func Validate(obj map[string]interface{}) ([]Problem, error) {
+    apiVersion, _ := obj["apiVersion"].(string)
+    kind, _ := obj["kind"].(string)
+    group := strings.SplitN(apiVersion, "/", 2)[0]
+    if group != qraiopv1.GroupVersion.Group {
+        return nil, nil
+    }
+    name := ""
+    if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+        name, _ = meta["name"].(string)
+    }
+    problem := func(field, message string, warning bool) Problem {
+        return Problem{Kind: kind, Name: name, Field: field, Message: message, Warning: warning}
+    }
+
+    if apiVersion != qraiopv1.GroupVersion.String() {
+        return []Problem{problem("apiVersion", fmt.Sprintf("unsupported version, want %s", qraiopv1.GroupVersion), false)}, nil
+    }
+    s, err := OpenAPI(kind)
+    if err != nil {
+        if _, loadErr := load(); loadErr != nil {
+            return nil, loadErr
+        }
+        return []Problem{problem("kind", err.Error(), false)}, nil
+    }
+
+    var problems []Problem
+    // metadata is checked by the API server, not the CRD schema
+    for _, key := range sortedKeys(obj) {
+        if key == "metadata" || key == "apiVersion" || key == "kind" {
+            continue
+        }
+        prop, ok := s.Properties[key]
+        if !ok {
+            problems = append(problems, problem(key, "unknown field", false))
+            continue
+        }
+        for _, e := range prop.validate(key, obj[key]) {
+            problems = append(problems, problem(e.field, e.message, false))
+        }
+    }
+    for _, req := range s.Required {
+        if _, ok := obj[req]; !ok && req != "metadata" {
+            problems = append(problems, problem(req, "required field is missing", false))
+        }
+    }
+    if name == "" {
+        problems = append(problems, problem("metadata.name", "required field is missing", false))
+    }
+
+    // The deprecation warnings of the Qraiop admission webhook
+    if kind == "Qraiop" && len(problems) == 0 {
+        var q qraiopv1.Qraiop
+        raw, err := json.Marshal(obj)
+        if err != nil {
+            return nil, err
+        }
+        if err := json.Unmarshal(raw, &q); err != nil {
+            return append(problems, problem("", err.Error(), false)), nil
+        }
+        for _, d := range q.Deprecations() {
+            problems = append(problems, problem(d.Path, d.Message, true))
+        }
+    }
+    return problems, nil
+}
+
+type fieldError struct {
+    field   string
+    message string
+}
+
+// validate checks value against s, reporting errors at field
+func (s *Schema) validate(field string, value interface{}) []fieldError {
+    fail := func(format string, args ...interface{}) []fieldError {
+        return []fieldError{{field: field, message: fmt.Sprintf(format, args...)}}
+    }
+    if value == nil {
+        if s.Nullable {
+            return nil
+        }
+        return fail("must not be null")
+    }
+    if s.IntOrString {
+        switch value.(type) {
+        case string, float64:
+            return nil
+        }
+        return fail("must be an integer or a string")
+    }
+    if len(s.AnyOf) > 0 {
+        matched := false
+        for _, alt := range s.AnyOf {
+            if len(alt.validate(field, value)) == 0 {
+                matched = true
+                break
+            }
+        }
+        if !matched {
+            return fail("matches none of the allowed forms")
+        }
+    }
+    if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+        return fail("must be one of %s", enumList(s.Enum))
+    }
+
+    switch s.Type {
+    case "object":
+        m, ok := value.(map[string]interface{})
+        if !ok {
+            return fail("must be an object")
+        }
+        return s.validateObject(field, m)
+    case "array":
+        items, ok := value.([]interface{})
+        if !ok {
+            return fail("must be an array")
+        }
+        var errs []fieldError
+        if s.MinItems != nil && int64(len(items)) < *s.MinItems {
+            errs = append(errs, fail("must have at least %d items", *s.MinItems)...)
+        }
+        if s.MaxItems != nil && int64(len(items)) > *s.MaxItems {
+            errs = append(errs, fail("must have at most %d items", *s.MaxItems)...)
+        }
+        if s.Items != nil {
+            for i, item := range items {
+                errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item)...)
+            }
+        }
+        return errs
+    case "string":
+        str, ok := value.(string)
+        if !ok {
+            return fail("must be a string")
+        }
+        var errs []fieldError
+        length := int64(len([]rune(str)))
+        if s.MinLength != nil && length < *s.MinLength {
+            errs = append(errs, fail("must be at least %d characters", *s.MinLength)...)
+        }
+        if s.MaxLength != nil && length > *s.MaxLength {
+            errs = append(errs, fail("must be at most %d characters", *s.MaxLength)...)
+        }
+        if s.Pattern != "" {
+            re, err := regexp.Compile(s.Pattern)
+            if err == nil && !re.MatchString(str) {
+                errs = append(errs, fail("must match %s", s.Pattern)...)
+            }
+        }
+        return errs
+    case "integer", "number":
+        n, ok := value.(float64)
+        if !ok {
+            return fail("must be a %s", s.Type)
+        }
+        if s.Type == "integer" && n != math.Trunc(n) {
+            return fail("must be an integer")
+        }
+        if s.Minimum != nil && n < *s.Minimum {
+            return fail("must be at least %v", *s.Minimum)
+        }
+        if s.Maximum != nil && n > *s.Maximum {
+            return fail("must be at most %v", *s.Maximum)
+        }
+    case "boolean":
+        if _, ok := value.(bool); !ok {
+            return fail("must be a boolean")
+        }
+    }
+    return nil
+}
+
+func (s *Schema) validateObject(field string, m map[string]interface{}) []fieldError {
+    var errs []fieldError
+    for _, key := range sortedKeys(m) {
+        child := field + "." + key
+        switch {
+        case s.Properties[key] != nil:
+            errs = append(errs, s.Properties[key].validate(child, m[key])...)
+        case s.AdditionalProperties != nil:
+            errs = append(errs, s.AdditionalProperties.validate(child, m[key])...)
+        case !s.PreserveUnknownFields && !s.EmbeddedResource:
+            // The API server would prune it silently
+            errs = append(errs, fieldError{field: child, message: "unknown field"})
+        }
+    }
+    for _, req := range s.Required {
+        // The API server defaults before it validates
+        if _, ok := m[req]; !ok && (s.Properties[req] == nil || s.Properties[req].Default == nil) {
+            errs = append(errs, fieldError{field: field + "." + req, message: "required field is missing"})
+        }
+    }
+    return errs
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+    for _, e := range enum {
+        if fmt.Sprint(e) == fmt.Sprint(value) {
+            return true
+        }
+    }
+    return false
+}
+
+func enumList(enum []interface{}) string {
+    parts := make([]string, len(enum))
+    for i, e := range enum {
+        parts[i] = fmt.Sprint(e)
+    }
+    return strings.Join(parts, ", ")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
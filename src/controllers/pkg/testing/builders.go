@@ -0,0 +1,180 @@
+// src/controllers/pkg/testing/builders.go
+
+// Package testing has fakes and fixtures for unit testing code built on the
+// qraiop.io API without a cluster: builders for Qraiop and ChaosExperiment
+// objects, a fake controller-runtime client with the API group registered,
+// an in-process crypto service, an in-process LLM provider and a no-op chaos
+// fault.
+//
+//	q := qraioptesting.NewQraiop("qraiop-system", "test",
+//	    qraioptesting.WithCryptography("ML-KEM-768", "ML-DSA-65"),
+//	    qraioptesting.WithAI("openai"))
+//	c := qraioptesting.NewFakeClient(q)
+//
+//	crypto := qraioptesting.NewCryptoService()
+//	defer crypto.Close()
+//	rotation, err := crypto.Client().RotateKeys(ctx, time.Hour)
+package testing
+
+import (
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+// QraiopOption sets a field of a Qraiop built by NewQraiop
+type QraiopOption func(*qraiopv1.Qraiop)
+
+// NewQraiop returns a Qraiop with every component disabled, to be turned on
+// by opts
+func NewQraiop(namespace, name string, opts ...QraiopOption) *qraiopv1.Qraiop {
+    q := &qraiopv1.Qraiop{
+        TypeMeta: metav1.TypeMeta{APIVersion: qraiopv1.GroupVersion.String(), Kind: "Qraiop"},
+        ObjectMeta: metav1.ObjectMeta{
+            Namespace:  namespace,
+            Name:       name,
+            UID:        types.UID("uid-" + namespace + "-" + name),
+            Generation: 1,
+        },
+    }
+    for _, opt := range opts {
+        opt(q)
+    }
+    return q
+}
+
+// WithCryptography enables the crypto service with algorithms
+func WithCryptography(algorithms ...string) QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.Cryptography.Enabled = true
+        q.Spec.Cryptography.Algorithms = algorithms
+    }
+}
+
+// WithAI enables the AI orchestration agents on provider
+func WithAI(provider string) QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.AIOrchestration.Enabled = true
+        q.Spec.AIOrchestration.LLMProvider = provider
+    }
+}
+
+// WithChaos enables chaos engineering
+func WithChaos() QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.ChaosEngineering.Enabled = true
+    }
+}
+
+// WithMonitoring enables monitoring
+func WithMonitoring() QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.Monitoring.Enabled = true
+    }
+}
+
+// WithProfile sets the sizing profile, e.g. production
+func WithProfile(profile string) QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.Profile = profile
+    }
+}
+
+// WithPaused pauses reconciliation of the instance
+func WithPaused() QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        q.Spec.Paused = true
+    }
+}
+
+// WithComponentStatus sets the status of a component, e.g. to have
+// cryptography Ready
+func WithComponentStatus(component, status string) QraiopOption {
+    return func(q *qraiopv1.Qraiop) {
+        if q.Status.Components == nil {
+            q.Status.Components = make(map[string]qraiopv1.ComponentStatus)
+        }
+        st := q.Status.Components[component]
+        st.Status = status
+        st.LastUpdated = metav1.Now()
+        q.Status.Components[component] = st
+    }
+}
+
+// ExperimentOption sets a field of a ChaosExperiment built by
+// NewChaosExperiment
+type ExperimentOption func(*qraiopv1.ChaosExperiment)
+
+// NewChaosExperiment returns an experiment of faultType against the pods
+// matching selector in its namespace, at 100% for 60 seconds
+func NewChaosExperiment(namespace, name, faultType string, selector map[string]string, opts ...ExperimentOption) *qraiopv1.ChaosExperiment {
+    exp := &qraiopv1.ChaosExperiment{
+        TypeMeta: metav1.TypeMeta{APIVersion: qraiopv1.GroupVersion.String(), Kind: "ChaosExperiment"},
+        ObjectMeta: metav1.ObjectMeta{
+            Namespace:  namespace,
+            Name:       name,
+            UID:        types.UID("uid-" + namespace + "-" + name),
+            Generation: 1,
+        },
+        Spec: qraiopv1.ChaosExperimentSpec{
+            ExperimentConfig: qraiopv1.ExperimentConfig{
+                Type:       faultType,
+                Target:     qraiopv1.ExperimentTarget{Namespace: namespace, Selector: selector},
+                Percentage: 100,
+                Duration:   60,
+            },
+        },
+    }
+    for _, opt := range opts {
+        opt(exp)
+    }
+    return exp
+}
+
+// WithPercentage sets the share of matching pods targeted
+func WithPercentage(percentage int) ExperimentOption {
+    return func(exp *qraiopv1.ChaosExperiment) {
+        exp.Spec.Percentage = percentage
+    }
+}
+
+// WithSeed fixes the seed the targets are drawn with
+func WithSeed(seed int64) ExperimentOption {
+    return func(exp *qraiopv1.ChaosExperiment) {
+        exp.Spec.Seed = &seed
+    }
+}
+
+// WithDuration sets how long the fault is held, in seconds
+func WithDuration(seconds int) ExperimentOption {
+    return func(exp *qraiopv1.ChaosExperiment) {
+        exp.Spec.Duration = seconds
+    }
+}
+
+// WithProbes sets the steady-state probes
+func WithProbes(probes ...qraiopv1.SteadyStateProbe) ExperimentOption {
+    return func(exp *qraiopv1.ChaosExperiment) {
+        exp.Spec.Probes = probes
+    }
+}
+
+// NewFakeClient returns an in-memory client holding objs, with the
+// built-in types and the qraiop.io API group registered. Status writes of
+// qraiop.io objects go through the status subresource as on a cluster.
+func NewFakeClient(objs ...client.Object) client.WithWatch {
+    scheme, err := qraiopclient.NewScheme()
+    if err != nil {
+        panic(err)
+    }
+    return fake.NewClientBuilder().
+        WithScheme(scheme).
+        WithObjects(objs...).
+        WithStatusSubresource(&qraiopv1.Qraiop{}, &qraiopv1.ChaosExperiment{}, &qraiopv1.ChaosTrigger{},
+            &qraiopv1.QraiopAddon{}, &qraiopv1.QraiopSecurityPolicy{}, &qraiopv1.AgentTask{}).
+        Build()
+}
@@ -0,0 +1,81 @@
+// src/controllers/pkg/testing/chaos.go
+package testing
+
+import (
+    "context"
+    "sync"
+
+    corev1 "k8s.io/api/core/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+// NoopFault is a chaos.Fault that touches nothing and records what it was
+// asked to do, so experiments run end to end against a fake client
+type NoopFault struct {
+    // InjectErr, when set, is returned by every Inject
+    InjectErr error
+
+    mu       sync.Mutex
+    injected map[string][]string
+    cleaned  map[string]int
+}
+
+// NewNoopFault returns a fault recording its calls
+func NewNoopFault() *NoopFault {
+    return &NoopFault{injected: make(map[string][]string), cleaned: make(map[string]int)}
+}
+
+// Install replaces the faults of types with f until the returned func is
+// called, e.g.
+//
+//	defer qraioptesting.NewNoopFault().Install("pod_kill", "network_partition")()
+func (f *NoopFault) Install(types ...string) (restore func()) {
+    restores := make([]func(), 0, len(types))
+    for _, t := range types {
+        restores = append(restores, chaos.Override(t, f))
+    }
+    return func() {
+        for i := len(restores) - 1; i >= 0; i-- {
+            restores[i]()
+        }
+    }
+}
+
+// Inject records the names of the targeted pods
+func (f *NoopFault) Inject(_ context.Context, _ client.Client, exp *qraiopv1.ChaosExperiment, targets []corev1.Pod) error {
+    if f.InjectErr != nil {
+        return f.InjectErr
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    key := exp.Namespace + "/" + exp.Name
+    for _, p := range targets {
+        f.injected[key] = append(f.injected[key], p.Name)
+    }
+    return nil
+}
+
+// Cleanup counts the reverts of an experiment
+func (f *NoopFault) Cleanup(_ context.Context, _ client.Client, exp *qraiopv1.ChaosExperiment) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.cleaned[exp.Namespace+"/"+exp.Name]++
+    return nil
+}
+
+// Injected returns the pods the experiment namespace/name targeted
+func (f *NoopFault) Injected(namespace, name string) []string {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return append([]string(nil), f.injected[namespace+"/"+name]...)
+}
+
+// Cleanups returns how often the experiment namespace/name was reverted
+func (f *NoopFault) Cleanups(namespace, name string) int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.cleaned[namespace+"/"+name]
+}
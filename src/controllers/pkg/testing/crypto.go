@@ -0,0 +1,218 @@
+// src/controllers/pkg/testing/crypto.go
+package testing
+
+import (
+    "bytes"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+
+    "github.com/Bailey7220/QRAIOP/controllers/cryptoclient"
+)
+
+// CryptoService is an in-process crypto service speaking the API of
+// cryptoclient. Keys are named key-1, key-2, ... in rotation order and
+// revocation lists are "signed" by wrapping their JSON in a PEM block.
+// Repeated calls with the same idempotency key get the first response, as
+// from the real service.
+type CryptoService struct {
+    // URL is the service's endpoint
+    URL string
+
+    server *httptest.Server
+
+    mu       sync.Mutex
+    keys     []string
+    retired  map[string]bool
+    usage    map[string]map[string]uint64
+    crl      []byte
+    calls    []CryptoCall
+    failures map[string]int
+    replies  map[string][]byte
+}
+
+// CryptoCall is a request the service received
+type CryptoCall struct {
+    Method         string
+    Path           string
+    IdempotencyKey string
+    Body           []byte
+}
+
+// NewCryptoService starts a service holding a single current key, key-1
+func NewCryptoService() *CryptoService {
+    s := &CryptoService{
+        keys:     []string{"key-1"},
+        retired:  make(map[string]bool),
+        usage:    make(map[string]map[string]uint64),
+        failures: make(map[string]int),
+        replies:  make(map[string][]byte),
+    }
+    mux := http.NewServeMux()
+    mux.HandleFunc("/v1/keys/usage", s.handleUsage)
+    mux.HandleFunc("/v1/keys/rotate", s.handleRotate)
+    mux.HandleFunc("/v1/keys/retire", s.handleRetire)
+    mux.HandleFunc("/v1/crl", s.handleCRL)
+    s.server = httptest.NewServer(s.record(mux))
+    s.URL = s.server.URL
+    return s
+}
+
+// Client returns a cryptoclient for the service
+func (s *CryptoService) Client() *cryptoclient.Client {
+    return cryptoclient.New(s.URL, "fake", cryptoclient.Options{})
+}
+
+// Close shuts the service down
+func (s *CryptoService) Close() {
+    s.server.Close()
+}
+
+// CurrentKey returns the ID of the current key
+func (s *CryptoService) CurrentKey() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.keys[len(s.keys)-1]
+}
+
+// Retired reports whether keyID was retired
+func (s *CryptoService) Retired(keyID string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.retired[keyID]
+}
+
+// RecordUsage adds count operations, e.g. sign, to keyID's usage
+func (s *CryptoService) RecordUsage(keyID, operation string, count uint64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.usage[keyID] == nil {
+        s.usage[keyID] = make(map[string]uint64)
+    }
+    s.usage[keyID][operation] += count
+}
+
+// FailNext makes the next n calls to path fail with 503
+func (s *CryptoService) FailNext(path string, n int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.failures[path] = n
+}
+
+// Calls returns the requests received so far, failed ones included
+func (s *CryptoService) Calls() []CryptoCall {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]CryptoCall(nil), s.calls...)
+}
+
+// record logs every call, fails those FailNext asked for and replays the
+// responses of repeated idempotency keys
+func (s *CryptoService) record(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+        body, _ := io.ReadAll(req.Body)
+        req.Body = io.NopCloser(bytes.NewReader(body))
+        key := req.Header.Get(cryptoclient.IdempotencyKeyHeader)
+        s.mu.Lock()
+        s.calls = append(s.calls, CryptoCall{Method: req.Method, Path: req.URL.Path, IdempotencyKey: key, Body: body})
+        if s.failures[req.URL.Path] > 0 {
+            s.failures[req.URL.Path]--
+            s.mu.Unlock()
+            http.Error(w, "injected failure", http.StatusServiceUnavailable)
+            return
+        }
+        reply, replay := s.replies[key]
+        s.mu.Unlock()
+        if key != "" && replay {
+            w.Header().Set("Content-Type", "application/json")
+            w.Write(reply)
+            return
+        }
+
+        rec := httptest.NewRecorder()
+        next.ServeHTTP(rec, req)
+        if key != "" && rec.Code < 300 {
+            s.mu.Lock()
+            s.replies[key] = rec.Body.Bytes()
+            s.mu.Unlock()
+        }
+        for k, v := range rec.Header() {
+            w.Header()[k] = v
+        }
+        w.WriteHeader(rec.Code)
+        w.Write(rec.Body.Bytes())
+    })
+}
+
+func (s *CryptoService) handleUsage(w http.ResponseWriter, req *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var out struct {
+        Keys []cryptoclient.KeyUsage `json:"keys"`
+    }
+    for _, id := range s.keys {
+        if s.retired[id] {
+            continue
+        }
+        ops := make(map[string]uint64)
+        for op, n := range s.usage[id] {
+            ops[op] = n
+        }
+        out.Keys = append(out.Keys, cryptoclient.KeyUsage{KeyID: id, Algorithm: "ML-DSA-65", Operations: ops})
+    }
+    writeJSON(w, out)
+}
+
+func (s *CryptoService) handleRotate(w http.ResponseWriter, req *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    previous := s.keys[len(s.keys)-1]
+    current := fmt.Sprintf("key-%d", len(s.keys)+1)
+    s.keys = append(s.keys, current)
+    writeJSON(w, cryptoclient.Rotation{KeyID: current, PreviousKeyID: previous})
+}
+
+func (s *CryptoService) handleRetire(w http.ResponseWriter, req *http.Request) {
+    var in struct {
+        KeyID string `json:"keyId"`
+    }
+    if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if in.KeyID == s.keys[len(s.keys)-1] {
+        http.Error(w, "the current key cannot be retired", http.StatusConflict)
+        return
+    }
+    s.retired[in.KeyID] = true
+    w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *CryptoService) handleCRL(w http.ResponseWriter, req *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if req.Method == http.MethodGet {
+        w.Header().Set("Content-Type", "application/x-pem-file")
+        w.Write(s.crl)
+        return
+    }
+    var list cryptoclient.RevocationList
+    if err := json.NewDecoder(req.Body).Decode(&list); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    data, _ := json.Marshal(list)
+    s.crl = pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: data})
+    writeJSON(w, map[string]string{"crl": string(s.crl)})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(v)
+}
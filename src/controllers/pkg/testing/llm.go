@@ -0,0 +1,192 @@
+// src/controllers/pkg/testing/llm.go
+package testing
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+)
+
+// LLMProvider is an in-process LLM provider serving the OpenAI chat
+// completions API at /v1/chat/completions and the Anthropic messages API
+// at /v1/messages, for agents pointed at URL. Every completion is produced
+// by the Respond func; by default it echoes the last user message.
+type LLMProvider struct {
+    // URL is the provider's endpoint
+    URL string
+
+    server *httptest.Server
+
+    mu       sync.Mutex
+    respond  func(model string, messages []LLMMessage) string
+    requests []LLMRequest
+}
+
+// LLMMessage is one message of a conversation
+type LLMMessage struct {
+    Role    string `json:"role"`
+    Content string `json:"content"`
+}
+
+// LLMRequest is a completion request the provider received
+type LLMRequest struct {
+    // API is openai or anthropic
+    API      string
+    Model    string
+    System   string
+    Messages []LLMMessage
+}
+
+// NewLLMProvider starts a provider echoing the last user message
+func NewLLMProvider() *LLMProvider {
+    p := &LLMProvider{respond: echoLastUserMessage}
+    mux := http.NewServeMux()
+    mux.HandleFunc("/v1/chat/completions", p.handleOpenAI)
+    mux.HandleFunc("/v1/messages", p.handleAnthropic)
+    p.server = httptest.NewServer(mux)
+    p.URL = p.server.URL
+    return p
+}
+
+// Close shuts the provider down
+func (p *LLMProvider) Close() {
+    p.server.Close()
+}
+
+// Respond sets the func producing every completion
+func (p *LLMProvider) Respond(f func(model string, messages []LLMMessage) string) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.respond = f
+}
+
+// RespondWith answers every request with reply
+func (p *LLMProvider) RespondWith(reply string) {
+    p.Respond(func(string, []LLMMessage) string { return reply })
+}
+
+// Requests returns the completion requests received so far
+func (p *LLMProvider) Requests() []LLMRequest {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return append([]LLMRequest(nil), p.requests...)
+}
+
+// complete records req and returns its reply and sequence number
+func (p *LLMProvider) complete(req LLMRequest) (string, int) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.requests = append(p.requests, req)
+    return p.respond(req.Model, req.Messages), len(p.requests)
+}
+
+func (p *LLMProvider) handleOpenAI(w http.ResponseWriter, req *http.Request) {
+    var in struct {
+        Model    string       `json:"model"`
+        Messages []LLMMessage `json:"messages"`
+    }
+    if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    r := LLMRequest{API: "openai", Model: in.Model}
+    for _, m := range in.Messages {
+        if m.Role == "system" {
+            r.System = m.Content
+            continue
+        }
+        r.Messages = append(r.Messages, m)
+    }
+    reply, n := p.complete(r)
+    writeJSON(w, map[string]interface{}{
+        "id":      fmt.Sprintf("chatcmpl-fake-%d", n),
+        "object":  "chat.completion",
+        "created": 0,
+        "model":   in.Model,
+        "choices": []map[string]interface{}{{
+            "index":         0,
+            "message":       LLMMessage{Role: "assistant", Content: reply},
+            "finish_reason": "stop",
+        }},
+        "usage": map[string]int{
+            "prompt_tokens":     countTokens(r.System, r.Messages),
+            "completion_tokens": countTokens(reply, nil),
+            "total_tokens":      countTokens(r.System, r.Messages) + countTokens(reply, nil),
+        },
+    })
+}
+
+func (p *LLMProvider) handleAnthropic(w http.ResponseWriter, req *http.Request) {
+    var in struct {
+        Model    string          `json:"model"`
+        System   json.RawMessage `json:"system"`
+        Messages []struct {
+            Role    string          `json:"role"`
+            Content json.RawMessage `json:"content"`
+        } `json:"messages"`
+    }
+    if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    r := LLMRequest{API: "anthropic", Model: in.Model, System: contentText(in.System)}
+    for _, m := range in.Messages {
+        r.Messages = append(r.Messages, LLMMessage{Role: m.Role, Content: contentText(m.Content)})
+    }
+    reply, n := p.complete(r)
+    writeJSON(w, map[string]interface{}{
+        "id":          fmt.Sprintf("msg_fake_%d", n),
+        "type":        "message",
+        "role":        "assistant",
+        "model":       in.Model,
+        "content":     []map[string]string{{"type": "text", "text": reply}},
+        "stop_reason": "end_turn",
+        "usage": map[string]int{
+            "input_tokens":  countTokens(r.System, r.Messages),
+            "output_tokens": countTokens(reply, nil),
+        },
+    })
+}
+
+// contentText flattens Anthropic content, either a string or a list of
+// blocks, into its text
+func contentText(raw json.RawMessage) string {
+    var text string
+    if json.Unmarshal(raw, &text) == nil {
+        return text
+    }
+    var blocks []struct {
+        Type string `json:"type"`
+        Text string `json:"text"`
+    }
+    json.Unmarshal(raw, &blocks)
+    var parts []string
+    for _, b := range blocks {
+        if b.Type == "text" {
+            parts = append(parts, b.Text)
+        }
+    }
+    return strings.Join(parts, "\n")
+}
+
+func echoLastUserMessage(_ string, messages []LLMMessage) string {
+    for i := len(messages) - 1; i >= 0; i-- {
+        if messages[i].Role == "user" {
+            return messages[i].Content
+        }
+    }
+    return ""
+}
+
+// countTokens approximates tokens as words, which is all tests of token
+// accounting need
+func countTokens(system string, messages []LLMMessage) int {
+    n := len(strings.Fields(system))
+    for _, m := range messages {
+        n += len(strings.Fields(m.Content))
+    }
+    return n
+}
@@ -0,0 +1,58 @@
+// src/controllers/render/channels.go
+package render
+
+import (
+    "sort"
+
+    corev1 "k8s.io/api/core/v1"
+)
+
+const (
+    channelsVolume = "alert-channels"
+    channelsDir    = "/etc/qraiop/alerting"
+    secretsDir     = "/etc/qraiop/channels"
+
+    // ChannelsFile is the key of the channel list in its ConfigMap
+    ChannelsFile = "channels.json"
+)
+
+// ChannelSecretDir is where the Secret of a channel is mounted
+func ChannelSecretDir(channel string) string {
+    return secretsDir + "/" + channel
+}
+
+// AlertChannels mounts the channels ConfigMap and the Secret of each
+// channel, keyed by channel name, into the component's container
+func AlertChannels(spec *corev1.PodSpec, configMap string, secrets map[string]string) {
+    spec.Volumes = append(spec.Volumes, corev1.Volume{
+        Name: channelsVolume,
+        VolumeSource: corev1.VolumeSource{
+            ConfigMap: &corev1.ConfigMapVolumeSource{
+                LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+            },
+        },
+    })
+    mounts := []corev1.VolumeMount{{Name: channelsVolume, MountPath: channelsDir, ReadOnly: true}}
+
+    channels := make([]string, 0, len(secrets))
+    for ch := range secrets {
+        channels = append(channels, ch)
+    }
+    sort.Strings(channels)
+    for _, ch := range channels {
+        volume := "channel-" + ch
+        spec.Volumes = append(spec.Volumes, corev1.Volume{
+            Name:         volume,
+            VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secrets[ch]}},
+        })
+        mounts = append(mounts, corev1.VolumeMount{Name: volume, MountPath: ChannelSecretDir(ch), ReadOnly: true})
+    }
+
+    if len(spec.Containers) > 0 {
+        c := &spec.Containers[0]
+        c.VolumeMounts = append(c.VolumeMounts, mounts...)
+        c.Env = setEnv(c.Env, corev1.EnvVar{Name: "QRAIOP_ALERT_CHANNELS", Value: channelsDir + "/" + ChannelsFile})
+        // Templates, as <channel type>.tmpl, sit next to the channel list
+        c.Env = setEnv(c.Env, corev1.EnvVar{Name: "QRAIOP_ALERT_TEMPLATES", Value: channelsDir})
+    }
+}
@@ -0,0 +1,54 @@
+// src/controllers/render/command.go
+package render
+
+import (
+    "fmt"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// reservedFlags are set by the operator, through the environment, and
+// can't be overridden with Args since the component would then disagree
+// with its Service, probes or config mounts
+var reservedFlags = []string{"--port", "--config", "--crypto-endpoint", "--alert-channels", "--log-level"}
+
+// CheckArgs returns an error naming any reserved flag in args
+func CheckArgs(args []string) error {
+    var reserved []string
+    for _, a := range args {
+        for _, f := range reservedFlags {
+            if a == f || strings.HasPrefix(a, f+"=") {
+                reserved = append(reserved, f)
+            }
+        }
+    }
+    if len(reserved) > 0 {
+        return fmt.Errorf("args set %s, which the operator manages; use logLevel or the component's settings instead", strings.Join(reserved, ", "))
+    }
+    return nil
+}
+
+// CommandLine sets the component container's command, args and log level
+// from opts. Args are checked against the reserved flags first.
+func CommandLine(spec *corev1.PodSpec, opts qraiopv1.ComponentOptions) error {
+    if err := CheckArgs(opts.Args); err != nil {
+        return err
+    }
+    if len(spec.Containers) == 0 {
+        return nil
+    }
+    c := &spec.Containers[0]
+    if len(opts.Command) > 0 {
+        c.Command = append([]string(nil), opts.Command...)
+    }
+    if len(opts.Args) > 0 {
+        c.Args = append([]string(nil), opts.Args...)
+    }
+    if opts.LogLevel != "" {
+        c.Env = setEnv(c.Env, corev1.EnvVar{Name: "QRAIOP_LOG_LEVEL", Value: opts.LogLevel})
+    }
+    return nil
+}
@@ -0,0 +1,97 @@
+// src/controllers/render/containers.go
+package render
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Containers adds the init containers, sidecars and volumes of opts to a
+// component's pod spec. Names and ports clashing with the operator's
+// containers or each other, and mounts of volumes the pod doesn't have,
+// are refused so the component is blocked instead of failing to roll out.
+func Containers(spec *corev1.PodSpec, opts qraiopv1.ComponentOptions) error {
+    if len(opts.InitContainers) == 0 && len(opts.Sidecars) == 0 && len(opts.Volumes) == 0 {
+        return nil
+    }
+    names := map[string]bool{}
+    ports := map[string]string{}
+    for _, list := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+        for _, c := range list {
+            names[c.Name] = true
+            for _, p := range c.Ports {
+                ports[portKey(p)] = c.Name
+            }
+        }
+    }
+    volumes := map[string]bool{}
+    for _, v := range spec.Volumes {
+        volumes[v.Name] = true
+    }
+
+    var problems []string
+    for _, v := range opts.Volumes {
+        if volumes[v.Name] {
+            problems = append(problems, "volume "+v.Name+" is taken")
+        }
+        volumes[v.Name] = true
+    }
+    check := func(kind string, c corev1.Container) {
+        switch {
+        case c.Name == "":
+            problems = append(problems, kind+" without a name")
+            return
+        case names[c.Name]:
+            problems = append(problems, fmt.Sprintf("%s %s: the name is taken", kind, c.Name))
+        }
+        names[c.Name] = true
+        if c.Image == "" {
+            problems = append(problems, fmt.Sprintf("%s %s: no image", kind, c.Name))
+        }
+        for _, p := range c.Ports {
+            if owner, ok := ports[portKey(p)]; ok {
+                problems = append(problems, fmt.Sprintf("%s %s: port %s is taken by container %s", kind, c.Name, portKey(p), owner))
+            }
+            ports[portKey(p)] = c.Name
+        }
+        for _, m := range c.VolumeMounts {
+            if !volumes[m.Name] {
+                problems = append(problems, fmt.Sprintf("%s %s: mounts unknown volume %s", kind, c.Name, m.Name))
+            }
+        }
+    }
+    for _, c := range opts.InitContainers {
+        check("init container", c)
+    }
+    for _, c := range opts.Sidecars {
+        check("sidecar", c)
+    }
+    if len(problems) > 0 {
+        return fmt.Errorf("containers: %s", strings.Join(problems, "; "))
+    }
+
+    for _, v := range opts.Volumes {
+        spec.Volumes = append(spec.Volumes, *v.DeepCopy())
+    }
+    for _, c := range opts.InitContainers {
+        spec.InitContainers = append(spec.InitContainers, *c.DeepCopy())
+    }
+    for _, c := range opts.Sidecars {
+        spec.Containers = append(spec.Containers, *c.DeepCopy())
+    }
+    return nil
+}
+
+// portKey identifies a container port within the pod's network namespace
+func portKey(p corev1.ContainerPort) string {
+    protocol := p.Protocol
+    if protocol == "" {
+        protocol = corev1.ProtocolTCP
+    }
+    return strconv.Itoa(int(p.ContainerPort)) + "/" + string(protocol)
+}
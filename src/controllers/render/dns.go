@@ -0,0 +1,79 @@
+// src/controllers/render/dns.go
+package render
+
+import (
+    "fmt"
+    "net"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/util/validation"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Limits the API server enforces on a pod's DNS config
+const (
+    maxDNSNameservers = 3
+    maxDNSSearches    = 32
+)
+
+// CheckDNS returns an error naming the DNS settings of opts the API server
+// would refuse, so the component is blocked instead of failing to roll out
+func CheckDNS(opts qraiopv1.ComponentOptions) error {
+    var problems []string
+    c := opts.DNSConfig
+    if opts.DNSPolicy == corev1.DNSNone && (c == nil || len(c.Nameservers) == 0) {
+        problems = append(problems, "dnsPolicy None needs at least one dnsConfig nameserver")
+    }
+    if c != nil {
+        if len(c.Nameservers) > maxDNSNameservers {
+            problems = append(problems, fmt.Sprintf("%d nameservers, at most %d are allowed", len(c.Nameservers), maxDNSNameservers))
+        }
+        for _, ns := range c.Nameservers {
+            if net.ParseIP(ns) == nil {
+                problems = append(problems, "nameserver "+ns+" is not an IP address")
+            }
+        }
+        if len(c.Searches) > maxDNSSearches {
+            problems = append(problems, fmt.Sprintf("%d search domains, at most %d are allowed", len(c.Searches), maxDNSSearches))
+        }
+        for _, s := range c.Searches {
+            if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(s, ".")); len(errs) > 0 {
+                problems = append(problems, fmt.Sprintf("search domain %s: %s", s, strings.Join(errs, ", ")))
+            }
+        }
+    }
+    for _, h := range opts.HostAliases {
+        if net.ParseIP(h.IP) == nil {
+            problems = append(problems, "host alias IP "+h.IP+" is not an IP address")
+        }
+        for _, name := range h.Hostnames {
+            if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+                problems = append(problems, fmt.Sprintf("host alias %s: %s", name, strings.Join(errs, ", ")))
+            }
+        }
+    }
+    if len(problems) > 0 {
+        return fmt.Errorf("dns: %s", strings.Join(problems, "; "))
+    }
+    return nil
+}
+
+// DNS sets the DNS policy, DNS config and host aliases of opts on a
+// component's pod spec, after checking them
+func DNS(spec *corev1.PodSpec, opts qraiopv1.ComponentOptions) error {
+    if err := CheckDNS(opts); err != nil {
+        return err
+    }
+    if opts.DNSPolicy != "" {
+        spec.DNSPolicy = opts.DNSPolicy
+    }
+    if opts.DNSConfig != nil {
+        spec.DNSConfig = opts.DNSConfig.DeepCopy()
+    }
+    for _, h := range opts.HostAliases {
+        spec.HostAliases = append(spec.HostAliases, *h.DeepCopy())
+    }
+    return nil
+}
@@ -0,0 +1,40 @@
+// src/controllers/render/env.go
+
+// Package render holds the pod-level transformations applied to every
+// workload the controller generates, so that cross-cutting settings are
+// rendered the same way for each component.
+package render
+
+import (
+    corev1 "k8s.io/api/core/v1"
+)
+
+// setEnv sets vars on envs, replacing any variable with the same name
+func setEnv(envs []corev1.EnvVar, vars ...corev1.EnvVar) []corev1.EnvVar {
+    for _, v := range vars {
+        replaced := false
+        for i := range envs {
+            if envs[i].Name == v.Name {
+                envs[i] = v
+                replaced = true
+                break
+            }
+        }
+        if !replaced {
+            envs = append(envs, v)
+        }
+    }
+    return envs
+}
+
+// allContainers returns pointers to every init and regular container of spec
+func allContainers(spec *corev1.PodSpec) []*corev1.Container {
+    containers := make([]*corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers))
+    for i := range spec.InitContainers {
+        containers = append(containers, &spec.InitContainers[i])
+    }
+    for i := range spec.Containers {
+        containers = append(containers, &spec.Containers[i])
+    }
+    return containers
+}
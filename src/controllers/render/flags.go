@@ -0,0 +1,55 @@
+// src/controllers/render/flags.go
+package render
+
+import (
+    corev1 "k8s.io/api/core/v1"
+)
+
+const (
+    flagsVolume   = "feature-flags"
+    flagsDir      = "/etc/qraiop/flags"
+    podInfoVolume = "podinfo"
+    podInfoDir    = "/etc/qraiop/podinfo"
+
+    // FlagsFile is the key of the feature flags in their ConfigMap
+    FlagsFile = "flags.json"
+)
+
+// FeatureFlags mounts the flags ConfigMap into every container, together
+// with the pod's own annotations through the downward API. Components
+// watch the annotations file and re-read the flags when the operator
+// stamps a new flags hash on the pod.
+func FeatureFlags(spec *corev1.PodSpec, configMap string) {
+    spec.Volumes = append(spec.Volumes,
+        corev1.Volume{
+            Name: flagsVolume,
+            VolumeSource: corev1.VolumeSource{
+                ConfigMap: &corev1.ConfigMapVolumeSource{
+                    LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+                },
+            },
+        },
+        corev1.Volume{
+            Name: podInfoVolume,
+            VolumeSource: corev1.VolumeSource{
+                DownwardAPI: &corev1.DownwardAPIVolumeSource{
+                    Items: []corev1.DownwardAPIVolumeFile{{
+                        Path:     "annotations",
+                        FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+                    }},
+                },
+            },
+        },
+    )
+    for i := range spec.Containers {
+        c := &spec.Containers[i]
+        c.VolumeMounts = append(c.VolumeMounts,
+            corev1.VolumeMount{Name: flagsVolume, MountPath: flagsDir, ReadOnly: true},
+            corev1.VolumeMount{Name: podInfoVolume, MountPath: podInfoDir, ReadOnly: true},
+        )
+        c.Env = setEnv(c.Env,
+            corev1.EnvVar{Name: "QRAIOP_FEATURE_FLAGS", Value: flagsDir + "/" + FlagsFile},
+            corev1.EnvVar{Name: "QRAIOP_POD_ANNOTATIONS", Value: podInfoDir + "/annotations"},
+        )
+    }
+}
@@ -0,0 +1,46 @@
+// src/controllers/render/identity.go
+package render
+
+import (
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// CloudIdentity binds a component's ServiceAccount to a cloud identity by
+// annotating it the way each provider's workload identity webhook expects.
+// Azure additionally needs its label on the pods.
+func CloudIdentity(sa *corev1.ServiceAccount, pod *corev1.PodTemplateSpec, id *qraiopv1.CloudIdentity) {
+    if id == nil {
+        return
+    }
+    annotations := make(map[string]string)
+    switch id.Provider {
+    case qraiopv1.CloudProviderAWS:
+        annotations["eks.amazonaws.com/role-arn"] = id.Identity
+        if id.Audience != "" {
+            annotations["eks.amazonaws.com/audience"] = id.Audience
+        }
+    case qraiopv1.CloudProviderGCP:
+        annotations["iam.gke.io/gcp-service-account"] = id.Identity
+    case qraiopv1.CloudProviderAzure:
+        annotations["azure.workload.identity/client-id"] = id.Identity
+        if id.TenantID != "" {
+            annotations["azure.workload.identity/tenant-id"] = id.TenantID
+        }
+        if pod.Labels == nil {
+            pod.Labels = make(map[string]string)
+        }
+        pod.Labels["azure.workload.identity/use"] = "true"
+    }
+    for k, v := range id.Annotations {
+        annotations[k] = v
+    }
+
+    if sa.Annotations == nil {
+        sa.Annotations = make(map[string]string, len(annotations))
+    }
+    for k, v := range annotations {
+        sa.Annotations[k] = v
+    }
+}
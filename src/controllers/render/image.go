@@ -0,0 +1,50 @@
+// src/controllers/render/image.go
+package render
+
+import (
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Image sets the image of the component container, how it is pulled and
+// the pod's pull secrets from opts. An image without a tag or digest gets
+// the tag of the one it replaces, so it follows Spec.ComponentVersion.
+// Applying it twice changes nothing.
+func Image(spec *corev1.PodSpec, opts qraiopv1.ComponentOptions) {
+    container := &spec.Containers[0]
+    if opts.Image != "" {
+        image := opts.Image
+        if !hasTagOrDigest(image) {
+            if i := strings.LastIndex(container.Image, ":"); i > strings.LastIndex(container.Image, "/") {
+                image += container.Image[i:]
+            }
+        }
+        container.Image = image
+    }
+    if opts.ImagePullPolicy != "" {
+        container.ImagePullPolicy = opts.ImagePullPolicy
+    }
+    for _, s := range opts.ImagePullSecrets {
+        if !hasPullSecret(spec, s.Name) {
+            spec.ImagePullSecrets = append(spec.ImagePullSecrets, s)
+        }
+    }
+}
+
+// hasTagOrDigest tells an image reference from a repository; the port of
+// a registry host, as in localhost:5000/qraiop, is no tag
+func hasTagOrDigest(image string) bool {
+    return strings.Contains(image, "@") || strings.LastIndex(image, ":") > strings.LastIndex(image, "/")
+}
+
+func hasPullSecret(spec *corev1.PodSpec, name string) bool {
+    for _, s := range spec.ImagePullSecrets {
+        if s.Name == name {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,28 @@
+// src/controllers/render/lifecycle.go
+package render
+
+import (
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Lifecycle sets the component container's preStop hook and the pod's
+// termination grace period from cfg, which may be nil
+func Lifecycle(spec *corev1.PodSpec, cfg *qraiopv1.LifecycleConfig) {
+    if cfg == nil {
+        return
+    }
+    if cfg.TerminationGracePeriodSeconds != nil {
+        grace := *cfg.TerminationGracePeriodSeconds
+        spec.TerminationGracePeriodSeconds = &grace
+    }
+    if cfg.PreStop != nil && len(spec.Containers) > 0 {
+        // The component's own container comes first; sidecars stop on their own terms
+        c := &spec.Containers[0]
+        if c.Lifecycle == nil {
+            c.Lifecycle = &corev1.Lifecycle{}
+        }
+        c.Lifecycle.PreStop = cfg.PreStop.DeepCopy()
+    }
+}
@@ -0,0 +1,186 @@
+// src/controllers/render/monitoringtarget.go
+package render
+
+import (
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// PrometheusRuleGVK is the Prometheus Operator's kind for alert rules
+var PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// TargetMetricLabel names the MonitoringTarget on the series scraped for it
+const TargetMetricLabel = "qraiop_target"
+
+// WorkloadObjectName is the name of the ServiceMonitor and PrometheusRule
+// generated for a MonitoringTarget
+func WorkloadObjectName(t *qraiopv1.MonitoringTarget) string {
+    return "qraiop-target-" + t.Name
+}
+
+// WorkloadSelector is the PromQL selector of a target's series
+func WorkloadSelector(t *qraiopv1.MonitoringTarget) string {
+    return fmt.Sprintf(`namespace=%q,%s=%q`, t.Namespace, TargetMetricLabel, t.Name)
+}
+
+func workloadMetadata(t *qraiopv1.MonitoringTarget, cfg *qraiopv1.PrometheusConfig) map[string]interface{} {
+    labels := map[string]interface{}{
+        qraiopv1.MonitoringTargetLabel: t.Name,
+        "app.kubernetes.io/managed-by": "qraiop-controller",
+    }
+    for k, v := range cfg.Labels {
+        labels[k] = v
+    }
+    return map[string]interface{}{
+        "name":      WorkloadObjectName(t),
+        "namespace": t.Namespace,
+        "labels":    labels,
+    }
+}
+
+// WorkloadServiceMonitor renders the ServiceMonitor scraping the Services
+// of a MonitoringTarget. It carries the labels of the instance's
+// ServiceMonitors, so the same Prometheus picks it up, and its targets are
+// relabeled with the instance and the target.
+func WorkloadServiceMonitor(t *qraiopv1.MonitoringTarget, instance string, cfg *qraiopv1.PrometheusConfig) (*unstructured.Unstructured, error) {
+    selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&t.Spec.Selector)
+    if err != nil {
+        return nil, err
+    }
+    interval := 30 * time.Second
+    if cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    if t.Spec.Interval != nil && t.Spec.Interval.Duration > 0 {
+        interval = t.Spec.Interval.Duration
+    }
+    port, path := t.Spec.Port, t.Spec.Path
+    if port == "" {
+        port = MetricsPortName
+    }
+    if path == "" {
+        path = "/metrics"
+    }
+
+    obj := &unstructured.Unstructured{Object: map[string]interface{}{
+        "metadata": workloadMetadata(t, cfg),
+        "spec": map[string]interface{}{
+            "selector": selector,
+            "endpoints": []interface{}{map[string]interface{}{
+                "port":     port,
+                "path":     path,
+                "interval": interval.String(),
+                "relabelings": []interface{}{
+                    map[string]interface{}{"action": "replace", "targetLabel": InstanceMetricLabel, "replacement": instance},
+                    map[string]interface{}{"action": "replace", "targetLabel": TargetMetricLabel, "replacement": t.Name},
+                },
+            }},
+        },
+    }}
+    obj.SetGroupVersionKind(ServiceMonitorGVK)
+    return obj, nil
+}
+
+// WorkloadRules renders the PrometheusRule alerting on a MonitoringTarget:
+// a target being down, and each SLO burning its error budget
+func WorkloadRules(t *qraiopv1.MonitoringTarget, cfg *qraiopv1.PrometheusConfig) (*unstructured.Unstructured, error) {
+    sel := WorkloadSelector(t)
+    target := t.Namespace + "/" + t.Name
+    labels := func(severity string) map[string]interface{} {
+        return map[string]interface{}{TargetMetricLabel: target, "namespace": t.Namespace, "severity": severity}
+    }
+    rules := []interface{}{
+        map[string]interface{}{
+            "alert":  "QraiopTargetDown",
+            "expr":   fmt.Sprintf(`up{%s} == 0`, sel),
+            "for":    "5m",
+            "labels": labels("critical"),
+            "annotations": map[string]interface{}{
+                "summary":     "Monitored workload is down",
+                "description": fmt.Sprintf("{{ $labels.instance }} of %s has not been scraped for more than 5 minutes.", target),
+            },
+        },
+    }
+    for _, slo := range t.Spec.SLOs {
+        burn, err := SLORules(slo, sel, map[string]interface{}{TargetMetricLabel: target, "namespace": t.Namespace})
+        if err != nil {
+            return nil, err
+        }
+        rules = append(rules, burn...)
+    }
+
+    obj := &unstructured.Unstructured{Object: map[string]interface{}{
+        "metadata": workloadMetadata(t, cfg),
+        "spec": map[string]interface{}{
+            "groups": []interface{}{map[string]interface{}{
+                "name":  "qraiop-target-" + t.Namespace + "-" + t.Name,
+                "rules": rules,
+            }},
+        },
+    }}
+    obj.SetGroupVersionKind(PrometheusRuleGVK)
+    return obj, nil
+}
+
+// WorkloadDashboard renders the Grafana dashboard of an instance's
+// MonitoringTargets, a row each with their scrape health and SLIs. targets must be sorted.
+func WorkloadDashboard(instance string, targets []qraiopv1.MonitoringTarget) ([]byte, error) {
+    var panels []interface{}
+    y := 0
+    id := 1
+    panel := func(title, kind, expr string, x, w int) map[string]interface{} {
+        p := map[string]interface{}{
+            "id":         id,
+            "title":      title,
+            "type":       kind,
+            "datasource": map[string]interface{}{"type": "prometheus", "uid": "${datasource}"},
+            "gridPos":    map[string]interface{}{"h": 6, "w": w, "x": x, "y": y},
+            "targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": expr}},
+        }
+        id++
+        return p
+    }
+    for i := range targets {
+        t := &targets[i]
+        sel := WorkloadSelector(t)
+        panels = append(panels, map[string]interface{}{
+            "id":        id,
+            "type":      "row",
+            "title":     t.Namespace + "/" + t.Name,
+            "collapsed": false,
+            "gridPos":   map[string]interface{}{"h": 1, "w": 24, "x": 0, "y": y},
+        })
+        id++
+        y++
+        panels = append(panels, panel("Targets up", "stat", fmt.Sprintf(`sum(up{%s}) / count(up{%s})`, sel, sel), 0, 6))
+        // The rest of the row is shared by the scrape duration and the SLIs
+        x, w := 6, 18/(len(t.Spec.SLOs)+1)
+        panels = append(panels, panel("Scrape duration", "timeseries", fmt.Sprintf(`max(scrape_duration_seconds{%s})`, sel), x, w))
+        for _, slo := range t.Spec.SLOs {
+            x += w
+            panels = append(panels, panel("SLI "+slo.Name+" ("+slo.Objective+"%)", "timeseries", "1 - "+BadRatio(slo, sel, "5m"), x, w))
+        }
+        y += 6
+    }
+
+    return json.MarshalIndent(map[string]interface{}{
+        "uid":           "qraiop-workloads-" + instance,
+        "title":         "QRAIOP Workloads (" + instance + ")",
+        "tags":          []string{"qraiop"},
+        "schemaVersion": 39,
+        "time":          map[string]interface{}{"from": "now-6h", "to": "now"},
+        "templating": map[string]interface{}{"list": []interface{}{map[string]interface{}{
+            "name":  "datasource",
+            "type":  "datasource",
+            "query": "prometheus",
+        }}},
+        "panels": panels,
+    }, "", "  ")
+}
@@ -0,0 +1,80 @@
+// src/controllers/render/podmetadata.go
+package render
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/util/validation"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// selectorLabels pick a component's pods and must never change on them
+var selectorLabels = []string{"app.kubernetes.io/name", "app.kubernetes.io/instance"}
+
+// CheckPodMetadata returns an error naming the pod labels and annotations
+// of opts that are invalid or collide with the operator's own
+func CheckPodMetadata(opts qraiopv1.ComponentOptions) error {
+    var problems []string
+    for k, v := range opts.PodLabels {
+        for _, s := range selectorLabels {
+            if k == s {
+                problems = append(problems, "label "+k+" is a selector label")
+            }
+        }
+        if reservedKey(k) {
+            problems = append(problems, "label "+k+" is set by the operator")
+        }
+        if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+            problems = append(problems, fmt.Sprintf("label %s: %s", k, strings.Join(errs, ", ")))
+        }
+        if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+            problems = append(problems, fmt.Sprintf("label %s value: %s", k, strings.Join(errs, ", ")))
+        }
+    }
+    for k := range opts.PodAnnotations {
+        if reservedKey(k) {
+            problems = append(problems, "annotation "+k+" is set by the operator")
+        }
+        if errs := validation.IsQualifiedName(strings.ToLower(k)); len(errs) > 0 {
+            problems = append(problems, fmt.Sprintf("annotation %s: %s", k, strings.Join(errs, ", ")))
+        }
+    }
+    if len(problems) > 0 {
+        sort.Strings(problems)
+        return fmt.Errorf("pod metadata: %s", strings.Join(problems, "; "))
+    }
+    return nil
+}
+
+// PodMetadata adds the PodLabels and PodAnnotations of opts to a
+// component's pod template, after checking them. They are added before the
+// instance's common metadata, which only fills in keys still missing.
+func PodMetadata(template *corev1.PodTemplateSpec, opts qraiopv1.ComponentOptions) error {
+    if err := CheckPodMetadata(opts); err != nil {
+        return err
+    }
+    if len(opts.PodLabels) > 0 && template.Labels == nil {
+        template.Labels = make(map[string]string, len(opts.PodLabels))
+    }
+    for k, v := range opts.PodLabels {
+        template.Labels[k] = v
+    }
+    if len(opts.PodAnnotations) > 0 && template.Annotations == nil {
+        template.Annotations = make(map[string]string, len(opts.PodAnnotations))
+    }
+    for k, v := range opts.PodAnnotations {
+        template.Annotations[k] = v
+    }
+    return nil
+}
+
+// reservedKey reports whether a label or annotation key belongs to the
+// operator, which stamps qraiop.io/ keys on pods to signal them
+func reservedKey(k string) bool {
+    prefix, _, found := strings.Cut(k, "/")
+    return found && (prefix == qraiopv1.GroupVersion.Group || strings.HasSuffix(prefix, "."+qraiopv1.GroupVersion.Group))
+}
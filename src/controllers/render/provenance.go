@@ -0,0 +1,166 @@
+// src/controllers/render/provenance.go
+package render
+
+import (
+    "encoding/json"
+    "sort"
+    "strconv"
+    "strings"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// ProvenanceAnnotation records on a rendered object, as JSON, which spec
+// fields or defaults produced its fields. It is read by qraiopctl explain.
+const ProvenanceAnnotation = "qraiop.io/provenance"
+
+// Defaulted is the source of fields the instance leaves to the operator
+const Defaulted = "operator default"
+
+// Source is where a rendered field got its value
+type Source struct {
+    // Field is the path of the rendered field, e.g. spec.replicas
+    Field string `json:"field"`
+    Value string `json:"value,omitempty"`
+    // From is the instance field the value came from, or Defaulted
+    From string `json:"from"`
+}
+
+// Explain records that field of obj was rendered as value from the
+// instance field from. A later record for the same field replaces the
+// earlier one, so overrides win as they do in the rendered object.
+func Explain(obj metav1.Object, field, value, from string) {
+    sources := Provenance(obj)
+    replaced := false
+    for i := range sources {
+        if sources[i].Field == field {
+            sources[i] = Source{Field: field, Value: value, From: from}
+            replaced = true
+        }
+    }
+    if !replaced {
+        sources = append(sources, Source{Field: field, Value: value, From: from})
+    }
+    sort.Slice(sources, func(i, j int) bool { return sources[i].Field < sources[j].Field })
+    data, err := json.Marshal(sources)
+    if err != nil {
+        return
+    }
+    annotations := obj.GetAnnotations()
+    if annotations == nil {
+        annotations = make(map[string]string)
+    }
+    annotations[ProvenanceAnnotation] = string(data)
+    obj.SetAnnotations(annotations)
+}
+
+// Provenance returns the sources recorded on obj
+func Provenance(obj metav1.Object) []Source {
+    var sources []Source
+    if data, ok := obj.GetAnnotations()[ProvenanceAnnotation]; ok {
+        _ = json.Unmarshal([]byte(data), &sources)
+    }
+    return sources
+}
+
+// ExplainOptions records the fields of a component's Deployment set from
+// its component options, found at path in the instance spec
+func ExplainOptions(obj metav1.Object, path string, opts qraiopv1.ComponentOptions) {
+    const container = "spec.template.spec.containers[0]"
+    if opts.Image != "" {
+        Explain(obj, container+".image", opts.Image, path+".image")
+    }
+    if opts.ImagePullPolicy != "" {
+        Explain(obj, container+".imagePullPolicy", string(opts.ImagePullPolicy), path+".imagePullPolicy")
+    }
+    for _, s := range opts.ImagePullSecrets {
+        Explain(obj, "spec.template.spec.imagePullSecrets["+s.Name+"]", "", path+".imagePullSecrets")
+    }
+    if len(opts.Command) > 0 {
+        Explain(obj, container+".command", strings.Join(opts.Command, " "), path+".command")
+    }
+    if len(opts.Args) > 0 {
+        Explain(obj, container+".args", strings.Join(opts.Args, " "), path+".args")
+    }
+    if opts.LogLevel != "" {
+        Explain(obj, container+".env[QRAIOP_LOG_LEVEL]", opts.LogLevel, path+".logLevel")
+    }
+    if len(opts.FeatureFlags) > 0 {
+        Explain(obj, "spec.template.spec.volumes[flags]", strconv.Itoa(len(opts.FeatureFlags))+" flags", path+".featureFlags")
+    }
+    if l := opts.Lifecycle; l != nil {
+        if l.TerminationGracePeriodSeconds != nil {
+            Explain(obj, "spec.template.spec.terminationGracePeriodSeconds",
+                strconv.FormatInt(*l.TerminationGracePeriodSeconds, 10), path+".lifecycle.terminationGracePeriodSeconds")
+        }
+        if l.PreStop != nil {
+            Explain(obj, container+".lifecycle.preStop", "", path+".lifecycle.preStop")
+        }
+    }
+    if id := opts.CloudIdentity; id != nil {
+        Explain(obj, "serviceAccount.annotations", id.Provider+" "+id.Identity, path+".cloudIdentity")
+    }
+    for k, v := range opts.PodLabels {
+        Explain(obj, "spec.template.metadata.labels["+k+"]", v, path+".podLabels")
+    }
+    for k, v := range opts.PodAnnotations {
+        Explain(obj, "spec.template.metadata.annotations["+k+"]", v, path+".podAnnotations")
+    }
+    if opts.DNSPolicy != "" {
+        Explain(obj, "spec.template.spec.dnsPolicy", string(opts.DNSPolicy), path+".dnsPolicy")
+    }
+    if c := opts.DNSConfig; c != nil {
+        Explain(obj, "spec.template.spec.dnsConfig", strings.Join(c.Nameservers, ","), path+".dnsConfig")
+    }
+    for _, h := range opts.HostAliases {
+        Explain(obj, "spec.template.spec.hostAliases["+h.IP+"]", strings.Join(h.Hostnames, ","), path+".hostAliases")
+    }
+    for _, c := range opts.InitContainers {
+        Explain(obj, "spec.template.spec.initContainers["+c.Name+"]", c.Image, path+".initContainers")
+    }
+    for _, c := range opts.Sidecars {
+        Explain(obj, "spec.template.spec.containers["+c.Name+"]", c.Image, path+".sidecars")
+    }
+    for _, v := range opts.Volumes {
+        Explain(obj, "spec.template.spec.volumes["+v.Name+"]", "", path+".volumes")
+    }
+    if d := opts.Deployment; d != nil {
+        if r := d.Resources; r != nil {
+            for name, q := range r.Requests {
+                Explain(obj, container+".resources.requests["+string(name)+"]", q.String(), path+".deployment.resources")
+            }
+            for name, q := range r.Limits {
+                Explain(obj, container+".resources.limits["+string(name)+"]", q.String(), path+".deployment.resources")
+            }
+        }
+        for k, v := range d.NodeSelector {
+            Explain(obj, "spec.template.spec.nodeSelector["+k+"]", v, path+".deployment.nodeSelector")
+        }
+        if len(d.Tolerations) > 0 {
+            Explain(obj, "spec.template.spec.tolerations", strconv.Itoa(len(d.Tolerations))+" tolerations", path+".deployment.tolerations")
+        }
+        if d.Affinity != nil {
+            Explain(obj, "spec.template.spec.affinity", "", path+".deployment.affinity")
+        }
+    }
+}
+
+// ExplainProxy records the proxy environment set from p
+func ExplainProxy(obj metav1.Object, p *qraiopv1.ProxyConfig) {
+    if p == nil {
+        return
+    }
+    const env = "spec.template.spec.containers[*].env"
+    if p.HTTPProxy != "" {
+        Explain(obj, env+"[HTTP_PROXY]", p.HTTPProxy, "spec.proxy.httpProxy")
+    }
+    if p.HTTPSProxy != "" {
+        Explain(obj, env+"[HTTPS_PROXY]", p.HTTPSProxy, "spec.proxy.httpsProxy")
+    }
+    Explain(obj, env+"[NO_PROXY]", NoProxy(p), "spec.proxy.noProxy")
+    if p.TrustedCA != nil {
+        Explain(obj, "spec.template.spec.volumes["+proxyCAVolume+"]", p.TrustedCA.Name, "spec.proxy.trustedCA")
+    }
+}
@@ -0,0 +1,94 @@
+// src/controllers/render/proxy.go
+package render
+
+import (
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    proxyCAVolume = "proxy-ca"
+    proxyCADir    = "/etc/qraiop/proxy-ca"
+    proxyCAFile   = "ca-bundle.crt"
+)
+
+// inClusterNoProxy are destinations that must never go through the proxy
+var inClusterNoProxy = []string{"localhost", "127.0.0.1", ".svc", ".cluster.local"}
+
+// NoProxy returns the effective NO_PROXY list for p
+func NoProxy(p *qraiopv1.ProxyConfig) string {
+    entries := make([]string, 0, len(inClusterNoProxy)+1)
+    for _, e := range strings.Split(p.NoProxy, ",") {
+        if e = strings.TrimSpace(e); e != "" {
+            entries = append(entries, e)
+        }
+    }
+    for _, e := range inClusterNoProxy {
+        found := false
+        for _, have := range entries {
+            if have == e {
+                found = true
+                break
+            }
+        }
+        if !found {
+            entries = append(entries, e)
+        }
+    }
+    return strings.Join(entries, ",")
+}
+
+// Proxy sets the proxy environment, in both the upper and lower case forms
+// different runtimes read, on every container of spec and mounts the
+// trusted CA bundle when one is configured
+func Proxy(spec *corev1.PodSpec, p *qraiopv1.ProxyConfig) {
+    if p == nil {
+        return
+    }
+
+    var vars []corev1.EnvVar
+    for _, kv := range [][2]string{
+        {"HTTP_PROXY", p.HTTPProxy},
+        {"HTTPS_PROXY", p.HTTPSProxy},
+        {"NO_PROXY", NoProxy(p)},
+    } {
+        if kv[1] == "" {
+            continue
+        }
+        vars = append(vars,
+            corev1.EnvVar{Name: kv[0], Value: kv[1]},
+            corev1.EnvVar{Name: strings.ToLower(kv[0]), Value: kv[1]},
+        )
+    }
+
+    if p.TrustedCA != nil {
+        bundle := proxyCADir + "/" + proxyCAFile
+        vars = append(vars,
+            corev1.EnvVar{Name: "SSL_CERT_FILE", Value: bundle},
+            corev1.EnvVar{Name: "REQUESTS_CA_BUNDLE", Value: bundle},
+        )
+        spec.Volumes = append(spec.Volumes, corev1.Volume{
+            Name: proxyCAVolume,
+            VolumeSource: corev1.VolumeSource{
+                ConfigMap: &corev1.ConfigMapVolumeSource{
+                    LocalObjectReference: p.TrustedCA.LocalObjectReference,
+                    Items:                []corev1.KeyToPath{{Key: p.TrustedCA.Key, Path: proxyCAFile}},
+                },
+            },
+        })
+    }
+
+    for _, c := range allContainers(spec) {
+        c.Env = setEnv(c.Env, vars...)
+        if p.TrustedCA != nil {
+            c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+                Name:      proxyCAVolume,
+                MountPath: proxyCADir,
+                ReadOnly:  true,
+            })
+        }
+    }
+}
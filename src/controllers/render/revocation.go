@@ -0,0 +1,61 @@
+// src/controllers/render/revocation.go
+package render
+
+import (
+    corev1 "k8s.io/api/core/v1"
+)
+
+const (
+    crlVolume = "revocation-list"
+    crlDir    = "/etc/qraiop/crl"
+
+    // CRLFile is the key of the signed revocation list in its ConfigMap,
+    // RevokedFile that of the same list as JSON
+    CRLFile     = "crl.pem"
+    RevokedFile = "revoked.json"
+)
+
+// RevocationList mounts the revocation list ConfigMap into every container,
+// together with the pod's annotations like FeatureFlags does. The ConfigMap
+// is optional so pods start before the first list is published; components
+// re-read it when the operator stamps a new list number on the pod.
+func RevocationList(spec *corev1.PodSpec, configMap string) {
+    optional := true
+    spec.Volumes = append(spec.Volumes, corev1.Volume{
+        Name: crlVolume,
+        VolumeSource: corev1.VolumeSource{
+            ConfigMap: &corev1.ConfigMapVolumeSource{
+                LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+                Optional:             &optional,
+            },
+        },
+    })
+    podInfo := false
+    for _, v := range spec.Volumes {
+        podInfo = podInfo || v.Name == podInfoVolume
+    }
+    if !podInfo {
+        spec.Volumes = append(spec.Volumes, corev1.Volume{
+            Name: podInfoVolume,
+            VolumeSource: corev1.VolumeSource{
+                DownwardAPI: &corev1.DownwardAPIVolumeSource{
+                    Items: []corev1.DownwardAPIVolumeFile{{
+                        Path:     "annotations",
+                        FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+                    }},
+                },
+            },
+        })
+    }
+    for i := range spec.Containers {
+        c := &spec.Containers[i]
+        c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: crlVolume, MountPath: crlDir, ReadOnly: true})
+        if !podInfo {
+            c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: podInfoVolume, MountPath: podInfoDir, ReadOnly: true})
+        }
+        c.Env = setEnv(c.Env,
+            corev1.EnvVar{Name: "QRAIOP_CRL", Value: crlDir + "/" + CRLFile},
+            corev1.EnvVar{Name: "QRAIOP_POD_ANNOTATIONS", Value: podInfoDir + "/annotations"},
+        )
+    }
+}
@@ -0,0 +1,148 @@
+// src/controllers/render/servicemonitor.go
+package render
+
+import (
+    "time"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime/schema"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// ServiceMonitorGVK and PodMonitorGVK are the Prometheus Operator's scrape kinds
+var (
+    ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+    PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+)
+
+// Labels every scraped target of a component carries
+const (
+    InstanceMetricLabel  = "qraiop_instance"
+    ComponentMetricLabel = "qraiop_component"
+)
+
+// MetricsPortName names the container and Service port of a component
+// serving metrics on a port of its own
+const MetricsPortName = "metrics"
+
+// ScrapeTarget is the Service, or pods, a ServiceMonitor or PodMonitor scrapes
+type ScrapeTarget struct {
+    Instance  string
+    Component string
+    Service   string
+    Namespace string
+    Labels    map[string]string
+    Selector  map[string]string
+    // Path and Port default to /metrics on the http port
+    Path string
+    Port string
+}
+
+// ServiceMonitor renders the ServiceMonitor scraping a component's Service.
+// Targets are relabeled with the instance and component before any of the
+// user's rules run, so those can rely on them.
+func ServiceMonitor(t ScrapeTarget, cfg *qraiopv1.PrometheusConfig) *unstructured.Unstructured {
+    return scrapeObject(ServiceMonitorGVK, "endpoints", t, cfg)
+}
+
+// PodMonitor renders the PodMonitor scraping each pod of a component
+func PodMonitor(t ScrapeTarget, cfg *qraiopv1.PrometheusConfig) *unstructured.Unstructured {
+    return scrapeObject(PodMonitorGVK, "podMetricsEndpoints", t, cfg)
+}
+
+func scrapeObject(gvk schema.GroupVersionKind, endpointsField string, t ScrapeTarget, cfg *qraiopv1.PrometheusConfig) *unstructured.Unstructured {
+    labels := make(map[string]interface{}, len(t.Labels)+len(cfg.Labels))
+    for k, v := range t.Labels {
+        labels[k] = v
+    }
+    for k, v := range cfg.Labels {
+        labels[k] = v
+    }
+    selector := make(map[string]interface{}, len(t.Selector))
+    for k, v := range t.Selector {
+        selector[k] = v
+    }
+
+    obj := &unstructured.Unstructured{Object: map[string]interface{}{
+        "metadata": map[string]interface{}{
+            "name":      t.Service,
+            "namespace": t.Namespace,
+            "labels":    labels,
+        },
+        "spec": map[string]interface{}{
+            "selector":     map[string]interface{}{"matchLabels": selector},
+            endpointsField: []interface{}{scrapeEndpoint(t, cfg)},
+        },
+    }}
+    obj.SetGroupVersionKind(gvk)
+    return obj
+}
+
+func scrapeEndpoint(t ScrapeTarget, cfg *qraiopv1.PrometheusConfig) map[string]interface{} {
+    interval := 30 * time.Second
+    if cfg.Interval.Duration > 0 {
+        interval = cfg.Interval.Duration
+    }
+    relabelings := []interface{}{
+        map[string]interface{}{"action": "replace", "targetLabel": InstanceMetricLabel, "replacement": t.Instance},
+        map[string]interface{}{"action": "replace", "targetLabel": ComponentMetricLabel, "replacement": t.Component},
+    }
+    for _, rc := range cfg.RelabelConfigs {
+        relabelings = append(relabelings, relabeling(rc))
+    }
+    path, port := t.Path, t.Port
+    if path == "" {
+        path = "/metrics"
+    }
+    if port == "" {
+        port = "http"
+    }
+
+    endpoint := map[string]interface{}{
+        "port":        port,
+        "path":        path,
+        "interval":    interval.String(),
+        "relabelings": relabelings,
+    }
+    if cfg.TLS {
+        secret := t.Service + "-tls"
+        endpoint["scheme"] = "https"
+        endpoint["tlsConfig"] = map[string]interface{}{
+            "serverName": t.Service + "." + t.Namespace + ".svc",
+            "ca":         map[string]interface{}{"secret": map[string]interface{}{"name": secret, "key": "ca.crt"}},
+            "cert":       map[string]interface{}{"secret": map[string]interface{}{"name": secret, "key": "tls.crt"}},
+            "keySecret":  map[string]interface{}{"name": secret, "key": "tls.key"},
+        }
+    }
+    if ref := cfg.BearerTokenSecret; ref != nil {
+        endpoint["authorization"] = map[string]interface{}{
+            "type":        "Bearer",
+            "credentials": map[string]interface{}{"name": ref.Name, "key": ref.Key},
+        }
+    }
+    return endpoint
+}
+
+func relabeling(rc qraiopv1.RelabelConfig) map[string]interface{} {
+    out := make(map[string]interface{})
+    if len(rc.SourceLabels) > 0 {
+        labels := make([]interface{}, len(rc.SourceLabels))
+        for i, l := range rc.SourceLabels {
+            labels[i] = l
+        }
+        out["sourceLabels"] = labels
+    }
+    for k, v := range map[string]string{
+        "separator":   rc.Separator,
+        "targetLabel": rc.TargetLabel,
+        "regex":       rc.Regex,
+        "replacement": rc.Replacement,
+        "action":      rc.Action,
+    } {
+        if v != "" {
+            out[k] = v
+        }
+    }
+    return out
+}
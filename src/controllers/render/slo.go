@@ -0,0 +1,171 @@
+// src/controllers/render/slo.go
+package render
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// SLORulesName is the PrometheusRule holding an instance's SLO alerts
+const SLORulesName = "qraiop-slos"
+
+// defaultSLOPeriod is the period of SLOs without one
+const defaultSLOPeriod = 30 * 24 * time.Hour
+
+// burnWindow alerts when Budget of the error budget is spent within Long,
+// confirmed over Short so the alert clears soon after the burn stops
+type burnWindow struct {
+    Budget   float64
+    Long     time.Duration
+    Short    time.Duration
+    Severity string
+}
+
+// burnWindows are the multi-window burn-rate alerts of the SRE workbook:
+// 2% of the budget in an hour or 5% in 6 hours page, 10% in a day or in
+// 3 days open a ticket
+var burnWindows = []burnWindow{
+    {0.02, time.Hour, 5 * time.Minute, "critical"},
+    {0.05, 6 * time.Hour, 30 * time.Minute, "critical"},
+    {0.10, 24 * time.Hour, 2 * time.Hour, "warning"},
+    {0.10, 72 * time.Hour, 6 * time.Hour, "warning"},
+}
+
+// SLOPeriod returns the period of an SLO
+func SLOPeriod(slo qraiopv1.ServiceLevelObjective) time.Duration {
+    if slo.Period.Duration <= 0 {
+        return defaultSLOPeriod
+    }
+    return slo.Period.Duration
+}
+
+// SLORules renders the burn-rate alerts of an SLO on the series of sel,
+// with labels added to each. A window is left out when it isn't shorter
+// than the period, or when its burn rate would be below 1, which spends
+// no more than the budget and is no reason to alert.
+func SLORules(slo qraiopv1.ServiceLevelObjective, sel string, labels map[string]interface{}) ([]interface{}, error) {
+    budget, err := ErrorBudget(slo.Objective)
+    if err != nil {
+        return nil, fmt.Errorf("slo %s: %w", slo.Name, err)
+    }
+    period := SLOPeriod(slo)
+    var rules []interface{}
+    for _, w := range burnWindows {
+        factor := w.Budget * float64(period) / float64(w.Long)
+        if w.Long >= period || factor < 1 {
+            continue
+        }
+        threshold := strconv.FormatFloat(factor*budget, 'g', 6, 64)
+        ruleLabels := map[string]interface{}{
+            "slo":      slo.Name,
+            "severity": w.Severity,
+            "window":   promDuration(w.Long),
+        }
+        for k, v := range labels {
+            ruleLabels[k] = v
+        }
+        rules = append(rules, map[string]interface{}{
+            "alert": qraiopv1.SLOBurnRateAlert,
+            "expr": fmt.Sprintf("%s > %s and %s > %s",
+                BadRatio(slo, sel, promDuration(w.Long)), threshold, BadRatio(slo, sel, promDuration(w.Short)), threshold),
+            "for":    "2m",
+            "labels": ruleLabels,
+            "annotations": map[string]interface{}{
+                "summary": "SLO is burning its error budget",
+                "description": fmt.Sprintf("SLO %s (%s%% %s) burned %.0f%% of its %s error budget within %s, %.1fx the sustainable rate.",
+                    slo.Name, slo.Objective, slo.Type, w.Budget*100, promDuration(period), promDuration(w.Long), factor),
+            },
+        })
+    }
+    return rules, nil
+}
+
+// ErrorBudget returns the fraction of requests an objective such as "99.9"
+// allows to be bad
+func ErrorBudget(objective string) (float64, error) {
+    pct, err := strconv.ParseFloat(objective, 64)
+    if err != nil || pct <= 0 || pct > 100 {
+        return 0, fmt.Errorf("objective %q is not a percentage", objective)
+    }
+    return (100 - pct) / 100, nil
+}
+
+// BadRatio is the PromQL fraction of bad requests of an SLO over a range
+func BadRatio(slo qraiopv1.ServiceLevelObjective, sel, over string) string {
+    if slo.Type == qraiopv1.SLOLatency {
+        le := "+Inf"
+        if slo.Threshold != nil {
+            le = strconv.FormatFloat(slo.Threshold.Duration.Seconds(), 'g', -1, 64)
+        }
+        return fmt.Sprintf(`(1 - sum(rate(%s_bucket{%s,le=%q}[%s])) / sum(rate(%s_count{%s}[%s])))`,
+            slo.Metric, sel, le, over, slo.Metric, sel, over)
+    }
+    errors := slo.ErrorSelector
+    if errors == "" {
+        errors = `code=~"5.."`
+    }
+    return fmt.Sprintf(`(sum(rate(%s{%s,%s}[%s])) / sum(rate(%s{%s}[%s])))`,
+        slo.Metric, sel, errors, over, slo.Metric, sel, over)
+}
+
+// SLOPeriodBadRatio is the fraction of bad requests over an SLO's period
+func SLOPeriodBadRatio(slo qraiopv1.ServiceLevelObjective, sel string) string {
+    return BadRatio(slo, sel, promDuration(SLOPeriod(slo)))
+}
+
+// promDuration formats a duration the way PromQL ranges accept, e.g. 1h30m
+func promDuration(d time.Duration) string {
+    s := d.Round(time.Second).String()
+    s = strings.Replace(s, "m0s", "m", 1)
+    return strings.Replace(s, "h0m", "h", 1)
+}
+
+// ComponentSelector is the PromQL selector of a component's series
+func ComponentSelector(q *qraiopv1.Qraiop, component string) string {
+    return fmt.Sprintf(`namespace=%q,%s=%q,%s=%q`, q.Namespace, InstanceMetricLabel, q.Name, ComponentMetricLabel, component)
+}
+
+// InstanceSLORules renders the PrometheusRule with the burn-rate alerts of
+// an instance's SLOs, labelled like its ServiceMonitors
+func InstanceSLORules(q *qraiopv1.Qraiop) (*unstructured.Unstructured, error) {
+    var rules []interface{}
+    for _, slo := range q.Spec.Monitoring.SLOs {
+        burn, err := SLORules(slo.ServiceLevelObjective, ComponentSelector(q, slo.Component), map[string]interface{}{
+            InstanceMetricLabel:  q.Namespace + "/" + q.Name,
+            ComponentMetricLabel: slo.Component,
+            "namespace":          q.Namespace,
+        })
+        if err != nil {
+            return nil, err
+        }
+        rules = append(rules, burn...)
+    }
+
+    labels := map[string]interface{}{}
+    if cfg := q.Spec.Monitoring.Prometheus; cfg != nil {
+        for k, v := range cfg.Labels {
+            labels[k] = v
+        }
+    }
+    obj := &unstructured.Unstructured{Object: map[string]interface{}{
+        "metadata": map[string]interface{}{
+            "name":      SLORulesName,
+            "namespace": q.Namespace,
+            "labels":    labels,
+        },
+        "spec": map[string]interface{}{
+            "groups": []interface{}{map[string]interface{}{
+                "name":  "qraiop-slos-" + q.Name,
+                "rules": rules,
+            }},
+        },
+    }}
+    obj.SetGroupVersionKind(PrometheusRuleGVK)
+    return obj, nil
+}
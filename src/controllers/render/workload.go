@@ -0,0 +1,67 @@
+// src/controllers/render/workload.go
+package render
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// Workload sets the resources of the component container and the node
+// placement of the pod from cfg, which may be nil. Replicas are left to
+// the caller, which knows what else scales the component.
+func Workload(spec *corev1.PodSpec, cfg *qraiopv1.ComponentDeploymentConfig) error {
+    if cfg == nil {
+        return nil
+    }
+    if cfg.Resources != nil && len(spec.Containers) > 0 {
+        res := &spec.Containers[0].Resources
+        res.Requests = mergeResources(res.Requests, cfg.Resources.Requests)
+        res.Limits = mergeResources(res.Limits, cfg.Resources.Limits)
+        var problems []string
+        for name, request := range res.Requests {
+            if limit, ok := res.Limits[name]; ok && request.Cmp(limit) > 0 {
+                problems = append(problems, fmt.Sprintf("%s request %s exceeds its limit %s", name, request.String(), limit.String()))
+            }
+        }
+        if len(problems) > 0 {
+            sort.Strings(problems)
+            return fmt.Errorf("resources: %s", strings.Join(problems, "; "))
+        }
+    }
+    if len(cfg.NodeSelector) > 0 {
+        if spec.NodeSelector == nil {
+            spec.NodeSelector = make(map[string]string, len(cfg.NodeSelector))
+        }
+        for k, v := range cfg.NodeSelector {
+            spec.NodeSelector[k] = v
+        }
+    }
+    for _, t := range cfg.Tolerations {
+        spec.Tolerations = append(spec.Tolerations, *t.DeepCopy())
+    }
+    if cfg.Affinity != nil {
+        spec.Affinity = cfg.Affinity.DeepCopy()
+    }
+    return nil
+}
+
+// mergeResources returns base with the quantities of override replacing
+// its own
+func mergeResources(base, override corev1.ResourceList) corev1.ResourceList {
+    if len(override) == 0 {
+        return base
+    }
+    merged := base.DeepCopy()
+    if merged == nil {
+        merged = make(corev1.ResourceList, len(override))
+    }
+    for name, q := range override {
+        merged[name] = q.DeepCopy()
+    }
+    return merged
+}
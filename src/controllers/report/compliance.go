@@ -0,0 +1,418 @@
+// src/controllers/report/compliance.go
+package report
+
+import (
+    "bytes"
+    "crypto/ecdsa"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "html/template"
+    "sort"
+    "strings"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/api/meta"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const (
+    // certExpiryWarning is how close to expiry a certificate is reported
+    certExpiryWarning = 30 * 24 * time.Hour
+
+    // chaosCoverageWindow is the window experiments count towards coverage
+    chaosCoverageWindow = 30 * 24 * time.Hour
+)
+
+// postQuantumFamilies are the prefixes of the standard names of
+// post-quantum algorithms
+var postQuantumFamilies = []string{"ML-KEM", "ML-DSA", "SLH-DSA", "FN-DSA", "FALCON", "SPHINCS"}
+
+// Compliance is the crypto compliance report of a cluster for auditors
+type Compliance struct {
+    GeneratedAt  time.Time         `json:"generatedAt"`
+    PQC          PQCAdoption       `json:"pqc"`
+    Certificates CertHygiene       `json:"certificates"`
+    Violations   []PolicyViolation `json:"violations,omitempty"`
+    Chaos        ChaosCoverage     `json:"chaos"`
+}
+
+// PQCAdoption is how far the instances moved to post-quantum algorithms
+type PQCAdoption struct {
+    Instances int `json:"instances"`
+    // PostQuantum counts the instances whose crypto service offers a
+    // post-quantum algorithm
+    PostQuantum int `json:"postQuantum"`
+    // Hybrid counts those combining them with classical algorithms
+    Hybrid int `json:"hybrid"`
+    // Algorithms counts the instances offering each algorithm, by its
+    // standard name
+    Algorithms map[string]int `json:"algorithms"`
+    // Classical are the instances, as namespace/name, without one
+    Classical []string `json:"classical,omitempty"`
+}
+
+// CertHygiene summarizes the certificates of the TLS Secrets
+type CertHygiene struct {
+    Total    int           `json:"total"`
+    Expired  int           `json:"expired"`
+    Expiring int           `json:"expiring"`
+    Weak     int           `json:"weak"`
+    Findings []CertFinding `json:"findings,omitempty"`
+}
+
+// CertFinding is a problem with a certificate
+type CertFinding struct {
+    Namespace string     `json:"namespace"`
+    Secret    string     `json:"secret"`
+    Subject   string     `json:"subject,omitempty"`
+    NotAfter  *time.Time `json:"notAfter,omitempty"`
+    Problem   string     `json:"problem"`
+}
+
+// PolicyViolation is an instance falling short of a security policy
+type PolicyViolation struct {
+    Namespace string `json:"namespace"`
+    Instance  string `json:"instance"`
+    Rule      string `json:"rule"`
+    Message   string `json:"message"`
+}
+
+// Policy rules checked for violations
+const (
+    RuleDefaultDeny          = "default-deny"
+    RuleRegistryAllowlist    = "registry-allowlist"
+    RuleSecurityPolicy       = "security-policy"
+    RuleUnsupportedAlgorithm = "unsupported-algorithm"
+    RuleDeprecatedAlgorithm  = "deprecated-algorithm"
+)
+
+// ChaosCoverage is how well the namespaces running QRAIOP are exercised by
+// chaos experiments
+type ChaosCoverage struct {
+    // Window experiments are counted over
+    Window string `json:"window"`
+    // Uncovered counts the namespaces without experiments in the window
+    Uncovered  int                 `json:"uncovered"`
+    Namespaces []NamespaceCoverage `json:"namespaces"`
+}
+
+// NamespaceCoverage is the chaos coverage of a namespace, by the
+// experiments targeting it
+type NamespaceCoverage struct {
+    Namespace   string     `json:"namespace"`
+    Experiments int        `json:"experiments"`
+    Passed      int        `json:"passed"`
+    Failed      int        `json:"failed"`
+    FaultTypes  []string   `json:"faultTypes,omitempty"`
+    LastRun     *time.Time `json:"lastRun,omitempty"`
+    // Score is the resilience score of the namespace out of 100
+    Score int `json:"score"`
+}
+
+// IsPostQuantum reports whether an algorithm is post-quantum, by its
+// standard or pre-standard name
+func IsPostQuantum(algorithm string) bool {
+    name := strings.ToUpper(qraiopv1.CanonicalAlgorithm(algorithm))
+    for _, family := range postQuantumFamilies {
+        if strings.HasPrefix(name, family) {
+            return true
+        }
+    }
+    return false
+}
+
+// Audit builds the compliance report from the instances, security
+// policies, experiments and TLS Secrets of a cluster
+func Audit(instances []qraiopv1.Qraiop, policies []qraiopv1.QraiopSecurityPolicy, experiments []qraiopv1.ChaosExperiment, secrets []corev1.Secret, now time.Time) *Compliance {
+    c := &Compliance{
+        GeneratedAt: now,
+        PQC:         PQCAdoption{Instances: len(instances), Algorithms: make(map[string]int)},
+    }
+    for i := range instances {
+        q := &instances[i]
+        c.auditAlgorithms(q)
+        c.auditPolicies(q, policies)
+    }
+    sort.Strings(c.PQC.Classical)
+    sort.SliceStable(c.Violations, func(i, j int) bool {
+        a, b := c.Violations[i], c.Violations[j]
+        if a.Namespace != b.Namespace {
+            return a.Namespace < b.Namespace
+        }
+        return a.Instance < b.Instance
+    })
+    for _, s := range secrets {
+        c.auditSecret(s, now)
+    }
+    sort.SliceStable(c.Certificates.Findings, func(i, j int) bool {
+        a, b := c.Certificates.Findings[i], c.Certificates.Findings[j]
+        if a.Namespace != b.Namespace {
+            return a.Namespace < b.Namespace
+        }
+        return a.Secret < b.Secret
+    })
+    c.Chaos = chaosCoverage(instances, experiments, now)
+    return c
+}
+
+// auditAlgorithms counts the algorithms of an instance's crypto service
+// and its pools
+func (c *Compliance) auditAlgorithms(q *qraiopv1.Qraiop) {
+    crypto := q.Spec.Cryptography
+    algorithms := map[string]bool{}
+    hybrid := false
+    if crypto.Enabled {
+        for _, a := range crypto.Algorithms {
+            algorithms[qraiopv1.CanonicalAlgorithm(a)] = true
+        }
+        hybrid = crypto.HybridMode
+        for _, pool := range crypto.Pools {
+            for _, a := range pool.Algorithms {
+                algorithms[qraiopv1.CanonicalAlgorithm(a)] = true
+            }
+            hybrid = hybrid || pool.HybridMode
+        }
+    }
+    pq := false
+    for a := range algorithms {
+        c.PQC.Algorithms[a]++
+        pq = pq || IsPostQuantum(a)
+    }
+    if !pq {
+        c.PQC.Classical = append(c.PQC.Classical, q.Namespace+"/"+q.Name)
+        return
+    }
+    c.PQC.PostQuantum++
+    if hybrid {
+        c.PQC.Hybrid++
+    }
+}
+
+// auditPolicies records where an instance falls short of the security
+// policies it is expected to enforce
+func (c *Compliance) auditPolicies(q *qraiopv1.Qraiop, policies []qraiopv1.QraiopSecurityPolicy) {
+    violation := func(rule, msg string) {
+        c.Violations = append(c.Violations, PolicyViolation{Namespace: q.Namespace, Instance: q.Name, Rule: rule, Message: msg})
+    }
+
+    security := &q.Spec.SecurityPolicies
+    if ref := q.Spec.SecurityPolicyRef; ref != nil {
+        security = nil
+        for i := range policies {
+            if policies[i].Namespace == q.Namespace && policies[i].Name == ref.Name {
+                security = &policies[i].Spec.SecurityConfig
+            }
+        }
+        if security == nil {
+            violation(RuleSecurityPolicy, "references QraiopSecurityPolicy "+ref.Name+", which does not exist")
+        }
+    }
+    if security != nil {
+        if !security.NetworkPolicies.DefaultDenyAll {
+            violation(RuleDefaultDeny, "traffic is not denied by default")
+        }
+        switch reg := security.Registries; {
+        case reg == nil || !reg.Enabled:
+            violation(RuleRegistryAllowlist, "images may be pulled from any registry")
+        case reg.Mode == qraiopv1.RegistryPolicyAudit:
+            violation(RuleRegistryAllowlist, "images from other registries are only audited")
+        }
+    }
+
+    if meta.IsStatusConditionTrue(q.Status.Conditions, "UnsupportedAlgorithm") {
+        violation(RuleUnsupportedAlgorithm, meta.FindStatusCondition(q.Status.Conditions, "UnsupportedAlgorithm").Message)
+    }
+    for _, d := range q.Deprecations() {
+        violation(RuleDeprecatedAlgorithm, d.String())
+    }
+}
+
+// auditSecret checks the certificate of a TLS Secret
+func (c *Compliance) auditSecret(s corev1.Secret, now time.Time) {
+    if s.Type != corev1.SecretTypeTLS {
+        return
+    }
+    c.Certificates.Total++
+    finding := CertFinding{Namespace: s.Namespace, Secret: s.Name}
+    block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+    if block == nil {
+        finding.Problem = "no PEM certificate"
+        c.Certificates.Findings = append(c.Certificates.Findings, finding)
+        return
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        finding.Problem = "unparseable certificate: " + err.Error()
+        c.Certificates.Findings = append(c.Certificates.Findings, finding)
+        return
+    }
+    notAfter := cert.NotAfter
+    finding.Subject, finding.NotAfter = cert.Subject.String(), &notAfter
+
+    var problems []string
+    switch {
+    case now.After(cert.NotAfter):
+        c.Certificates.Expired++
+        problems = append(problems, "expired")
+    case cert.NotAfter.Sub(now) < certExpiryWarning:
+        c.Certificates.Expiring++
+        problems = append(problems, fmt.Sprintf("expires within %d days", int(certExpiryWarning.Hours()/24)))
+    }
+    if weak := weakKey(cert); weak != "" {
+        c.Certificates.Weak++
+        problems = append(problems, weak)
+    }
+    if len(problems) > 0 {
+        finding.Problem = strings.Join(problems, "; ")
+        c.Certificates.Findings = append(c.Certificates.Findings, finding)
+    }
+}
+
+// weakKey describes what makes the key or signature of a certificate
+// weak, or returns ""
+func weakKey(cert *x509.Certificate) string {
+    switch cert.SignatureAlgorithm {
+    case x509.MD5WithRSA, x509.SHA1WithRSA, x509.ECDSAWithSHA1, x509.DSAWithSHA1:
+        return "signed with " + cert.SignatureAlgorithm.String()
+    }
+    switch key := cert.PublicKey.(type) {
+    case *rsa.PublicKey:
+        if bits := key.N.BitLen(); bits < 2048 {
+            return fmt.Sprintf("%d bit RSA key", bits)
+        }
+    case *ecdsa.PublicKey:
+        if bits := key.Curve.Params().BitSize; bits < 256 {
+            return fmt.Sprintf("%d bit ECDSA key", bits)
+        }
+    }
+    return ""
+}
+
+// chaosCoverage counts the experiments of the window per namespace running
+// QRAIOP, and per namespace experiments target besides
+func chaosCoverage(instances []qraiopv1.Qraiop, experiments []qraiopv1.ChaosExperiment, now time.Time) ChaosCoverage {
+    byNamespace := make(map[string]*NamespaceCoverage)
+    coverage := func(ns string) *NamespaceCoverage {
+        if byNamespace[ns] == nil {
+            byNamespace[ns] = &NamespaceCoverage{Namespace: ns}
+        }
+        return byNamespace[ns]
+    }
+    for _, q := range instances {
+        coverage(q.Namespace)
+    }
+    faults := make(map[string]map[string]bool)
+    for i := range experiments {
+        exp := &experiments[i]
+        started := exp.CreationTimestamp.Time
+        if exp.Status.StartTime != nil {
+            started = exp.Status.StartTime.Time
+        }
+        if now.Sub(started) > chaosCoverageWindow {
+            continue
+        }
+        ns := exp.Spec.Target.Namespace
+        if ns == "" {
+            ns = exp.Namespace
+        }
+        nc := coverage(ns)
+        nc.Experiments++
+        switch exp.Status.Verdict {
+        case qraiopv1.VerdictPassed:
+            nc.Passed++
+        case qraiopv1.VerdictFailed:
+            nc.Failed++
+        }
+        if nc.LastRun == nil || started.After(*nc.LastRun) {
+            nc.LastRun = &started
+        }
+        if faults[ns] == nil {
+            faults[ns] = make(map[string]bool)
+        }
+        faults[ns][exp.Spec.Type] = true
+    }
+
+    cov := ChaosCoverage{Window: chaosCoverageWindow.String()}
+    for ns, nc := range byNamespace {
+        for fault := range faults[ns] {
+            nc.FaultTypes = append(nc.FaultTypes, fault)
+        }
+        sort.Strings(nc.FaultTypes)
+        nc.Score = resilienceScore(nc, now)
+        if nc.Experiments == 0 {
+            cov.Uncovered++
+        }
+        cov.Namespaces = append(cov.Namespaces, *nc)
+    }
+    sort.Slice(cov.Namespaces, func(i, j int) bool { return cov.Namespaces[i].Namespace < cov.Namespaces[j].Namespace })
+    return cov
+}
+
+var complianceTemplate = template.Must(template.New("compliance").Funcs(template.FuncMap{
+    "date": func(t *time.Time) string {
+        if t == nil {
+            return "-"
+        }
+        return t.UTC().Format("2006-01-02")
+    },
+    "join": func(s []string) string { return strings.Join(s, ", ") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>QRAIOP crypto compliance report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+.bad { background: #fce8e6; }
+</style>
+</head>
+<body>
+<h1>QRAIOP crypto compliance report</h1>
+<p>Generated {{.GeneratedAt.UTC.Format "2006-01-02T15:04:05Z07:00"}}.</p>
+
+<h2>Post-quantum adoption</h2>
+<p>{{.PQC.PostQuantum}} of {{.PQC.Instances}} instances offer post-quantum algorithms, {{.PQC.Hybrid}} in hybrid mode.</p>
+{{if .PQC.Classical}}<p>Classical only: {{join .PQC.Classical}}</p>{{end}}
+<table>
+<tr><th>Algorithm</th><th>Instances</th></tr>
+{{range $name, $n := .PQC.Algorithms}}<tr><td>{{$name}}</td><td>{{$n}}</td></tr>
+{{end}}</table>
+
+<h2>Certificate hygiene</h2>
+<p>{{.Certificates.Total}} certificates: {{.Certificates.Expired}} expired, {{.Certificates.Expiring}} expiring soon, {{.Certificates.Weak}} with weak keys.</p>
+{{if .Certificates.Findings}}<table>
+<tr><th>Secret</th><th>Subject</th><th>Not after</th><th>Problem</th></tr>
+{{range .Certificates.Findings}}<tr class="bad"><td>{{.Namespace}}/{{.Secret}}</td><td>{{.Subject}}</td><td>{{date .NotAfter}}</td><td>{{.Problem}}</td></tr>
+{{end}}</table>{{end}}
+
+<h2>Policy violations</h2>
+{{if .Violations}}<table>
+<tr><th>Instance</th><th>Rule</th><th>Message</th></tr>
+{{range .Violations}}<tr class="bad"><td>{{.Namespace}}/{{.Instance}}</td><td>{{.Rule}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+
+<h2>Chaos coverage</h2>
+<p>Experiments over the last {{.Chaos.Window}}; {{.Chaos.Uncovered}} namespaces were not tested.</p>
+<table>
+<tr><th>Namespace</th><th>Experiments</th><th>Passed</th><th>Failed</th><th>Fault types</th><th>Last run</th><th>Resilience</th></tr>
+{{range .Chaos.Namespaces}}<tr{{if eq .Experiments 0}} class="bad"{{end}}><td>{{.Namespace}}</td><td>{{.Experiments}}</td><td>{{.Passed}}</td><td>{{.Failed}}</td><td>{{join .FaultTypes}}</td><td>{{date .LastRun}}</td><td>{{.Score}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// HTML renders the compliance report as a standalone HTML page, the
+// source of its PDF rendering
+func (c *Compliance) HTML() ([]byte, error) {
+    var buf bytes.Buffer
+    if err := complianceTemplate.Execute(&buf, c); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
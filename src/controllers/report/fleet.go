@@ -0,0 +1,134 @@
+// src/controllers/report/fleet.go
+package report
+
+import (
+    "crypto/x509"
+    "encoding/pem"
+    "sort"
+    "time"
+
+    corev1 "k8s.io/api/core/v1"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// fleetWeek is the window experiments are counted over
+const fleetWeek = 7 * 24 * time.Hour
+
+// Fleet summarizes every Qraiop instance of a cluster for platform
+// dashboards spanning many namespaces
+type Fleet struct {
+    GeneratedAt time.Time `json:"generatedAt"`
+    Instances   int       `json:"instances"`
+    Namespaces  int       `json:"namespaces"`
+    // Phases counts the instances by phase
+    Phases map[string]int `json:"phases"`
+    // Unhealthy are the instances not Ready, the difference from a healthy
+    // fleet, sorted by namespace and name
+    Unhealthy []FleetInstance `json:"unhealthy,omitempty"`
+    // OldestCert is the QRAIOP certificate expiring first
+    OldestCert *FleetCert `json:"oldestCert,omitempty"`
+    // ExperimentsThisWeek counts the experiments started in the last seven
+    // days by verdict; running ones count as Running
+    ExperimentsThisWeek map[string]int `json:"experimentsThisWeek"`
+    // Resilience is the chaos coverage and resilience score per namespace,
+    // least resilient first
+    Resilience []NamespaceCoverage `json:"resilience,omitempty"`
+}
+
+// FleetInstance is an instance that needs attention
+type FleetInstance struct {
+    Namespace string `json:"namespace"`
+    Name      string `json:"name"`
+    Phase     string `json:"phase"`
+    Message   string `json:"message,omitempty"`
+    // Since is when the instance became Degraded, if it is
+    Since *time.Time `json:"since,omitempty"`
+}
+
+// FleetCert is a certificate in a TLS Secret
+type FleetCert struct {
+    Namespace string    `json:"namespace"`
+    Secret    string    `json:"secret"`
+    NotAfter  time.Time `json:"notAfter"`
+}
+
+// Total returns the number of experiments this week
+func (f *Fleet) Total() int {
+    n := 0
+    for _, c := range f.ExperimentsThisWeek {
+        n += c
+    }
+    return n
+}
+
+// Summarize aggregates the instances, experiments and the TLS Secrets
+// labelled app.kubernetes.io/part-of=qraiop of a cluster
+func Summarize(instances []qraiopv1.Qraiop, experiments []qraiopv1.ChaosExperiment, secrets []corev1.Secret, now time.Time) *Fleet {
+    f := &Fleet{
+        GeneratedAt:         now,
+        Instances:           len(instances),
+        Phases:              make(map[string]int),
+        ExperimentsThisWeek: make(map[string]int),
+    }
+    namespaces := make(map[string]bool)
+    for _, q := range instances {
+        namespaces[q.Namespace] = true
+        phase := q.Status.Phase
+        if phase == "" {
+            phase = "Unknown"
+        }
+        f.Phases[phase]++
+        if phase == "Ready" {
+            continue
+        }
+        u := FleetInstance{Namespace: q.Namespace, Name: q.Name, Phase: phase, Message: q.Status.Message}
+        if q.Status.DegradedSince != nil {
+            since := q.Status.DegradedSince.Time
+            u.Since = &since
+        }
+        f.Unhealthy = append(f.Unhealthy, u)
+    }
+    f.Namespaces = len(namespaces)
+    sort.Slice(f.Unhealthy, func(i, j int) bool {
+        a, b := f.Unhealthy[i], f.Unhealthy[j]
+        if a.Namespace != b.Namespace {
+            return a.Namespace < b.Namespace
+        }
+        return a.Name < b.Name
+    })
+
+    for _, exp := range experiments {
+        started := exp.CreationTimestamp.Time
+        if exp.Status.StartTime != nil {
+            started = exp.Status.StartTime.Time
+        }
+        if now.Sub(started) > fleetWeek {
+            continue
+        }
+        verdict := exp.Status.Verdict
+        if verdict == "" {
+            verdict = phaseOf(&exp)
+        }
+        f.ExperimentsThisWeek[verdict]++
+    }
+    f.Resilience = Resilience(instances, experiments, now)
+
+    for _, s := range secrets {
+        if s.Type != corev1.SecretTypeTLS {
+            continue
+        }
+        block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+        if block == nil {
+            continue
+        }
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            continue
+        }
+        if f.OldestCert == nil || cert.NotAfter.Before(f.OldestCert.NotAfter) {
+            f.OldestCert = &FleetCert{Namespace: s.Namespace, Secret: s.Name, NotAfter: cert.NotAfter}
+        }
+    }
+    return f
+}
@@ -0,0 +1,81 @@
+// src/controllers/report/html.go
+package report
+
+import (
+    "bytes"
+    "html/template"
+    "time"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>QRAIOP chaos report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+.Passed { background: #e6f4ea; }
+.Failed { background: #fce8e6; }
+.Aborted, .Unfinished { background: #fef7e0; }
+pre { margin: 0; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>QRAIOP chaos report</h1>
+<p>Generated {{.Generated}}: {{.Passed}} passed, {{.Failed}} failed, {{.Other}} aborted or unfinished.</p>
+<table>
+<tr><th>Experiment</th><th>Type</th><th>Verdict</th><th>Duration</th><th>Details</th></tr>
+{{range .Rows}}<tr class="{{.Verdict}}">
+<td>{{.Namespace}}/{{.Name}}</td><td>{{.Type}}</td><td>{{.Verdict}}</td><td>{{.Duration}}</td><td><pre>{{.Details}}</pre></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type htmlRow struct {
+    Namespace, Name, Type, Verdict, Duration, Details string
+}
+
+// HTML renders experiments as a standalone HTML summary
+func HTML(experiments []qraiopv1.ChaosExperiment) ([]byte, error) {
+    data := struct {
+        Generated             string
+        Passed, Failed, Other int
+        Rows                  []htmlRow
+    }{Generated: time.Now().UTC().Format(time.RFC3339)}
+
+    for i := range experiments {
+        exp := &experiments[i]
+        verdict := exp.Status.Verdict
+        if !exp.Status.IsFinished() {
+            verdict = "Unfinished"
+        }
+        switch verdict {
+        case qraiopv1.VerdictPassed:
+            data.Passed++
+        case qraiopv1.VerdictFailed:
+            data.Failed++
+        default:
+            data.Other++
+        }
+        data.Rows = append(data.Rows, htmlRow{
+            Namespace: exp.Namespace,
+            Name:      exp.Name,
+            Type:      exp.Spec.Type,
+            Verdict:   verdict,
+            Duration:  Duration(exp).Round(time.Second).String(),
+            Details:   Details(exp),
+        })
+    }
+
+    var buf bytes.Buffer
+    if err := htmlTemplate.Execute(&buf, data); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
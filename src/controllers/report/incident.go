@@ -0,0 +1,71 @@
+// src/controllers/report/incident.go
+package report
+
+import (
+    "bytes"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Timeline entry sources
+const (
+    SourceCondition  = "condition"
+    SourceEvent      = "event"
+    SourceExperiment = "experiment"
+    SourceAI         = "ai"
+    SourceAlert      = "alert"
+    SourceSecurity   = "security"
+)
+
+// Incident is the post-incident summary of a period an instance spent Degraded
+type Incident struct {
+    Instance  string          `json:"instance"`
+    Namespace string          `json:"namespace"`
+    Start     time.Time       `json:"start"`
+    End       time.Time       `json:"end"`
+    Timeline  []TimelineEntry `json:"timeline"`
+}
+
+// TimelineEntry is something that happened during an incident
+type TimelineEntry struct {
+    Time    time.Time `json:"time"`
+    Source  string    `json:"source"`
+    Summary string    `json:"summary"`
+}
+
+// Sort orders the timeline chronologically
+func (inc *Incident) Sort() {
+    sort.SliceStable(inc.Timeline, func(i, j int) bool { return inc.Timeline[i].Time.Before(inc.Timeline[j].Time) })
+}
+
+// Duration is how long the incident lasted
+func (inc *Incident) Duration() time.Duration {
+    return inc.End.Sub(inc.Start).Round(time.Second)
+}
+
+// IncidentMarkdown renders inc as a Markdown postmortem draft
+func IncidentMarkdown(inc *Incident) []byte {
+    var b bytes.Buffer
+    fmt.Fprintf(&b, "# Incident: %s/%s degraded for %s\n\n", inc.Namespace, inc.Instance, inc.Duration())
+    fmt.Fprintf(&b, "- Start: %s\n- End: %s\n\n", inc.Start.UTC().Format(time.RFC3339), inc.End.UTC().Format(time.RFC3339))
+
+    counts := make(map[string]int)
+    for _, e := range inc.Timeline {
+        counts[e.Source]++
+    }
+    b.WriteString("## Summary\n\n")
+    for _, src := range []string{SourceCondition, SourceEvent, SourceExperiment, SourceAI, SourceAlert, SourceSecurity} {
+        if counts[src] > 0 {
+            fmt.Fprintf(&b, "- %s: %d\n", src, counts[src])
+        }
+    }
+
+    b.WriteString("\n## Timeline\n\n| Time | Source | What happened |\n|---|---|---|\n")
+    for _, e := range inc.Timeline {
+        summary := strings.ReplaceAll(strings.ReplaceAll(e.Summary, "|", "\\|"), "\n", " ")
+        fmt.Fprintf(&b, "| %s | %s | %s |\n", e.Time.UTC().Format(time.RFC3339), e.Source, summary)
+    }
+    return b.Bytes()
+}
@@ -0,0 +1,176 @@
+// src/controllers/report/junit.go
+
+// Package report renders chaos experiment results for CI pipelines, as
+// JUnit XML that test-result tooling understands and as a standalone HTML
+// summary, and post-incident reports of degraded periods.
+package report
+
+import (
+    "encoding/xml"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+type junitSuites struct {
+    XMLName  xml.Name     `xml:"testsuites"`
+    Name     string       `xml:"name,attr"`
+    Tests    int          `xml:"tests,attr"`
+    Failures int          `xml:"failures,attr"`
+    Errors   int          `xml:"errors,attr"`
+    Skipped  int          `xml:"skipped,attr"`
+    Time     string       `xml:"time,attr"`
+    Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+    Name     string      `xml:"name,attr"`
+    Tests    int         `xml:"tests,attr"`
+    Failures int         `xml:"failures,attr"`
+    Errors   int         `xml:"errors,attr"`
+    Skipped  int         `xml:"skipped,attr"`
+    Time     string      `xml:"time,attr"`
+    Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+    Name      string        `xml:"name,attr"`
+    ClassName string        `xml:"classname,attr"`
+    Time      string        `xml:"time,attr"`
+    Failure   *junitMessage `xml:"failure,omitempty"`
+    Error     *junitMessage `xml:"error,omitempty"`
+    Skipped   *junitMessage `xml:"skipped,omitempty"`
+    SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+    Message string `xml:"message,attr"`
+    Text    string `xml:",chardata"`
+}
+
+// JUnit renders experiments as JUnit XML, one testsuite per namespace and
+// one testcase per experiment. A failed verdict is a failure, an experiment
+// that could not run is an error, and aborted or unfinished experiments are
+// skipped.
+func JUnit(experiments []qraiopv1.ChaosExperiment) ([]byte, error) {
+    suites := junitSuites{Name: "qraiop-chaos"}
+    byNamespace := make(map[string]*junitSuite)
+    var namespaces []string
+    var total time.Duration
+
+    for i := range experiments {
+        exp := &experiments[i]
+        suite, ok := byNamespace[exp.Namespace]
+        if !ok {
+            suite = &junitSuite{Name: exp.Namespace}
+            byNamespace[exp.Namespace] = suite
+            namespaces = append(namespaces, exp.Namespace)
+        }
+
+        d := Duration(exp)
+        total += d
+        tc := junitCase{
+            Name:      exp.Name,
+            ClassName: exp.Namespace + "." + exp.Spec.Type,
+            Time:      seconds(d),
+            SystemOut: Details(exp),
+        }
+        switch {
+        case !exp.Status.IsFinished():
+            tc.Skipped = &junitMessage{Message: "experiment has not finished (phase " + phaseOf(exp) + ")"}
+            suite.Skipped++
+        case exp.Status.Phase == qraiopv1.ExperimentFailed:
+            tc.Error = &junitMessage{Message: exp.Status.Message}
+            suite.Errors++
+        case exp.Status.Verdict == qraiopv1.VerdictAborted:
+            tc.Skipped = &junitMessage{Message: exp.Status.Message}
+            suite.Skipped++
+        case exp.Status.Verdict == qraiopv1.VerdictFailed:
+            tc.Failure = &junitMessage{Message: exp.Status.Message, Text: exp.Status.Message}
+            suite.Failures++
+        }
+        suite.Tests++
+        suite.Cases = append(suite.Cases, tc)
+    }
+
+    sort.Strings(namespaces)
+    for _, ns := range namespaces {
+        s := byNamespace[ns]
+        var d time.Duration
+        for i := range experiments {
+            if experiments[i].Namespace == ns {
+                d += Duration(&experiments[i])
+            }
+        }
+        s.Time = seconds(d)
+        suites.Tests += s.Tests
+        suites.Failures += s.Failures
+        suites.Errors += s.Errors
+        suites.Skipped += s.Skipped
+        suites.Suites = append(suites.Suites, *s)
+    }
+    suites.Time = seconds(total)
+
+    out, err := xml.MarshalIndent(suites, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// Duration is how long an experiment ran, up to now if it hasn't finished
+func Duration(exp *qraiopv1.ChaosExperiment) time.Duration {
+    if exp.Status.StartTime == nil {
+        return 0
+    }
+    end := time.Now()
+    if exp.Status.CompletionTime != nil {
+        end = exp.Status.CompletionTime.Time
+    }
+    return end.Sub(exp.Status.StartTime.Time)
+}
+
+// Details describes what an experiment did: its targets and the alerts
+// suppressed while it ran
+func Details(exp *qraiopv1.ChaosExperiment) string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "type: %s\n", exp.Spec.Type)
+    fmt.Fprintf(&b, "target: %s %v (%d%%)\n", exp.Spec.Target.Namespace, exp.Spec.Target.Selector, exp.Spec.Percentage)
+    if res := exp.Status.Resolution; res != nil {
+        // Repeating the run with this seed draws the same pods
+        fmt.Fprintf(&b, "seed: %d, drew %d of %d matching pods\n", res.Seed, len(res.Pods), res.Matched)
+    }
+    if len(exp.Status.Targets) > 0 {
+        fmt.Fprintf(&b, "pods: %s\n", strings.Join(exp.Status.Targets, ", "))
+    }
+    if ramp := exp.Status.Ramp; ramp != nil {
+        for _, step := range ramp.Steps {
+            fmt.Fprintf(&b, "ramp %s: %s to %d%%, %d pod(s)", step.At.Format(time.RFC3339), step.Reason, step.Percentage, step.Targets)
+            if step.Message != "" {
+                fmt.Fprintf(&b, " (%s)", step.Message)
+            }
+            b.WriteString("\n")
+        }
+    }
+    if s := exp.Status.AlertSuppression; s != nil {
+        fmt.Fprintf(&b, "suppressed alerts: %s (silence %s)\n", strings.Join(s.Matchers, ", "), s.SilenceID)
+    }
+    if exp.Status.Message != "" {
+        fmt.Fprintf(&b, "result: %s\n", exp.Status.Message)
+    }
+    return b.String()
+}
+
+func phaseOf(exp *qraiopv1.ChaosExperiment) string {
+    if exp.Status.Phase == "" {
+        return qraiopv1.ExperimentPending
+    }
+    return exp.Status.Phase
+}
+
+func seconds(d time.Duration) string {
+    return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,63 @@
+// src/controllers/report/resilience.go
+package report
+
+import (
+    "math"
+    "sort"
+    "time"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// The resilience score of a namespace is out of 100, weighted as follows.
+// Each part is full once its target is reached.
+const (
+    // resiliencePassWeight is earned by the pass rate of the decided
+    // experiments
+    resiliencePassWeight = 40
+    // resilienceFaultWeight is earned by the fault types tried, up to
+    // resilienceFaultTarget
+    resilienceFaultWeight = 25
+    resilienceFaultTarget = 4
+    // resilienceRecencyWeight is earned in full by an experiment in the
+    // last resilienceFreshWindow, fading over the rest of the coverage window
+    resilienceRecencyWeight = 20
+    resilienceFreshWindow   = 7 * 24 * time.Hour
+    // resilienceVolumeWeight is earned by the experiments run, up to
+    // resilienceVolumeTarget
+    resilienceVolumeWeight = 15
+    resilienceVolumeTarget = 10
+)
+
+// Resilience returns the chaos coverage and resilience score of each
+// namespace running QRAIOP or targeted by experiments, least resilient
+// first, so platform teams see where hardening is most needed
+func Resilience(instances []qraiopv1.Qraiop, experiments []qraiopv1.ChaosExperiment, now time.Time) []NamespaceCoverage {
+    namespaces := chaosCoverage(instances, experiments, now).Namespaces
+    sort.SliceStable(namespaces, func(i, j int) bool { return namespaces[i].Score < namespaces[j].Score })
+    return namespaces
+}
+
+// resilienceScore combines the experiments run in a namespace, their pass
+// rate, the fault types covered and the time since the last one
+func resilienceScore(nc *NamespaceCoverage, now time.Time) int {
+    if nc.Experiments == 0 {
+        return 0
+    }
+    score := 0.0
+    if decided := nc.Passed + nc.Failed; decided > 0 {
+        score += resiliencePassWeight * float64(nc.Passed) / float64(decided)
+    }
+    score += resilienceFaultWeight * math.Min(float64(len(nc.FaultTypes))/resilienceFaultTarget, 1)
+    score += resilienceVolumeWeight * math.Min(float64(nc.Experiments)/resilienceVolumeTarget, 1)
+    if nc.LastRun != nil {
+        age := now.Sub(*nc.LastRun)
+        switch {
+        case age <= resilienceFreshWindow:
+            score += resilienceRecencyWeight
+        case age < chaosCoverageWindow:
+            score += resilienceRecencyWeight * float64(chaosCoverageWindow-age) / float64(chaosCoverageWindow-resilienceFreshWindow)
+        }
+    }
+    return int(math.Round(score))
+}
@@ -0,0 +1,101 @@
+// src/controllers/report/sign.go
+package report
+
+import (
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// SignatureSuffix is appended to the name of a report for its detached
+// signature
+const SignatureSuffix = ".sig"
+
+// Sign returns the base64 detached signature of a report made with a PEM
+// private key: Ed25519 over the report, ECDSA and RSA over its SHA-256
+func Sign(data, keyPEM []byte) ([]byte, error) {
+    block, _ := pem.Decode(keyPEM)
+    if block == nil {
+        return nil, errors.New("signing key is not PEM encoded")
+    }
+    var key interface{}
+    var err error
+    switch block.Type {
+    case "EC PRIVATE KEY":
+        key, err = x509.ParseECPrivateKey(block.Bytes)
+    case "RSA PRIVATE KEY":
+        key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+    default:
+        key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("parsing signing key: %w", err)
+    }
+
+    var sig []byte
+    switch k := key.(type) {
+    case ed25519.PrivateKey:
+        sig = ed25519.Sign(k, data)
+    case *ecdsa.PrivateKey, *rsa.PrivateKey:
+        digest := sha256.Sum256(data)
+        sig, err = k.(crypto.Signer).Sign(rand.Reader, digest[:], crypto.SHA256)
+        if err != nil {
+            return nil, err
+        }
+    default:
+        return nil, fmt.Errorf("unsupported signing key type %T", key)
+    }
+    return []byte(base64.StdEncoding.EncodeToString(sig) + "\n"), nil
+}
+
+// Verify checks a detached signature made by Sign against a PEM public key
+// or certificate
+func Verify(data, sig, pubPEM []byte) error {
+    block, _ := pem.Decode(pubPEM)
+    if block == nil {
+        return errors.New("public key is not PEM encoded")
+    }
+    var pub interface{}
+    if block.Type == "CERTIFICATE" {
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            return fmt.Errorf("parsing certificate: %w", err)
+        }
+        pub = cert.PublicKey
+    } else {
+        var err error
+        if pub, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+            return fmt.Errorf("parsing public key: %w", err)
+        }
+    }
+    raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+    if err != nil {
+        return fmt.Errorf("decoding signature: %w", err)
+    }
+
+    digest := sha256.Sum256(data)
+    ok := false
+    switch k := pub.(type) {
+    case ed25519.PublicKey:
+        ok = ed25519.Verify(k, data, raw)
+    case *ecdsa.PublicKey:
+        ok = ecdsa.VerifyASN1(k, digest[:], raw)
+    case *rsa.PublicKey:
+        ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], raw) == nil
+    default:
+        return fmt.Errorf("unsupported public key type %T", pub)
+    }
+    if !ok {
+        return errors.New("signature does not match")
+    }
+    return nil
+}
@@ -0,0 +1,213 @@
+// src/controllers/sealed/sealed.go
+
+// Package sealed encrypts configuration values so they can be committed to
+// Git and only the operator can read them. A sealed value looks like
+//
+//	qraiopsealed://<base64url payload>
+//
+// where the payload is an AES-256-GCM ciphertext whose key is wrapped with
+// the operator's RSA public key. The namespace the value is sealed for is
+// bound as additional data, so a value can't be copied into another
+// namespace's instance.
+package sealed
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/json"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// Prefix marks sealed values
+const Prefix = "qraiopsealed://"
+
+// KeyBits is the size of generated sealing keys
+const KeyBits = 4096
+
+// KeyName names the Secret holding the cluster's sealing key and the
+// ConfigMap publishing its public half, both in the operator namespace
+const (
+    KeyName        = "qraiop-sealing-key"
+    PrivateKeyFile = "private.pem"
+    PublicKeyFile  = "public.pem"
+)
+
+// IsSealed reports whether s is a sealed value
+func IsSealed(s string) bool {
+    return strings.HasPrefix(s, Prefix)
+}
+
+// GenerateKey returns a new sealing key
+func GenerateKey() (*rsa.PrivateKey, error) {
+    return rsa.GenerateKey(rand.Reader, KeyBits)
+}
+
+// Seal encrypts plaintext for namespace with the operator's public key
+func Seal(pub *rsa.PublicKey, namespace, plaintext string) (string, error) {
+    sessionKey := make([]byte, 32)
+    if _, err := rand.Read(sessionKey); err != nil {
+        return "", err
+    }
+    wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, sessionKey, []byte(namespace))
+    if err != nil {
+        return "", err
+    }
+    gcm, err := newGCM(sessionKey)
+    if err != nil {
+        return "", err
+    }
+    nonce := make([]byte, gcm.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return "", err
+    }
+
+    // payload: wrapped key length, wrapped key, nonce, ciphertext
+    payload := make([]byte, 2, 2+len(wrapped)+len(nonce)+len(plaintext)+gcm.Overhead())
+    binary.BigEndian.PutUint16(payload, uint16(len(wrapped)))
+    payload = append(payload, wrapped...)
+    payload = append(payload, nonce...)
+    payload = gcm.Seal(payload, nonce, []byte(plaintext), []byte(namespace))
+    return Prefix + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Unseal decrypts a value sealed for namespace
+func Unseal(priv *rsa.PrivateKey, namespace, value string) (string, error) {
+    if !IsSealed(value) {
+        return "", errors.New("not a sealed value")
+    }
+    payload, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+    if err != nil {
+        return "", fmt.Errorf("malformed sealed value: %w", err)
+    }
+    if len(payload) < 2 {
+        return "", errors.New("malformed sealed value: too short")
+    }
+    n := int(binary.BigEndian.Uint16(payload))
+    payload = payload[2:]
+    if len(payload) < n {
+        return "", errors.New("malformed sealed value: truncated key")
+    }
+    sessionKey, err := rsa.DecryptOAEP(sha256.New(), nil, priv, payload[:n], []byte(namespace))
+    if err != nil {
+        return "", errors.New("sealed value was not sealed for this namespace or with this cluster's key")
+    }
+    gcm, err := newGCM(sessionKey)
+    if err != nil {
+        return "", err
+    }
+    payload = payload[n:]
+    if len(payload) < gcm.NonceSize() {
+        return "", errors.New("malformed sealed value: truncated nonce")
+    }
+    plaintext, err := gcm.Open(nil, payload[:gcm.NonceSize()], payload[gcm.NonceSize():], []byte(namespace))
+    if err != nil {
+        return "", errors.New("sealed value was tampered with")
+    }
+    return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
+
+// UnsealAll replaces every sealed string in v, which must round-trip
+// through JSON such as an API spec, with its plaintext. It returns how many
+// values were unsealed.
+func UnsealAll(priv *rsa.PrivateKey, namespace string, v interface{}) (int, error) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return 0, err
+    }
+    var tree interface{}
+    if err := json.Unmarshal(data, &tree); err != nil {
+        return 0, err
+    }
+    count := 0
+    var walk func(path string, node interface{}) (interface{}, error)
+    walk = func(path string, node interface{}) (interface{}, error) {
+        switch n := node.(type) {
+        case string:
+            if !IsSealed(n) {
+                return n, nil
+            }
+            plain, err := Unseal(priv, namespace, n)
+            if err != nil {
+                return nil, fmt.Errorf("%s: %w", path, err)
+            }
+            count++
+            return plain, nil
+        case map[string]interface{}:
+            for k, child := range n {
+                out, err := walk(path+"."+k, child)
+                if err != nil {
+                    return nil, err
+                }
+                n[k] = out
+            }
+        case []interface{}:
+            for i, child := range n {
+                out, err := walk(fmt.Sprintf("%s[%d]", path, i), child)
+                if err != nil {
+                    return nil, err
+                }
+                n[i] = out
+            }
+        }
+        return node, nil
+    }
+    if tree, err = walk("spec", tree); err != nil {
+        return 0, err
+    }
+    if count == 0 {
+        return 0, nil
+    }
+    if data, err = json.Marshal(tree); err != nil {
+        return 0, err
+    }
+    return count, json.Unmarshal(data, v)
+}
+
+// Contains reports whether v holds any sealed string
+func Contains(v interface{}) bool {
+    data, err := json.Marshal(v)
+    return err == nil && strings.Contains(string(data), Prefix)
+}
+
+// EncodePrivateKey and EncodePublicKey return keys as PEM
+func EncodePrivateKey(key *rsa.PrivateKey) []byte {
+    return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func EncodePublicKey(key *rsa.PublicKey) []byte {
+    return pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(key)})
+}
+
+// ParsePrivateKey and ParsePublicKey read PEM keys
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, errors.New("no PEM key found")
+    }
+    return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, errors.New("no PEM key found")
+    }
+    return x509.ParsePKCS1PublicKey(block.Bytes)
+}
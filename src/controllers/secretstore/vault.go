@@ -0,0 +1,102 @@
+// src/controllers/secretstore/vault.go
+
+// Package secretstore reads secrets from the external stores instances
+// sync Secrets from: Vault KV and AWS Secrets Manager.
+package secretstore
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+)
+
+// Store reads one secret as key/value pairs
+type Store interface {
+    Fetch(ctx context.Context) (map[string][]byte, error)
+}
+
+// StatusError is a non-2xx response of a store
+type StatusError struct {
+    Code    int
+    Message string
+    Server  string
+}
+
+func (e *StatusError) Error() string {
+    return fmt.Sprintf("%s returned %d: %s", e.Server, e.Code, e.Message)
+}
+
+// Vault reads a secret of a KV version 2 engine
+type Vault struct {
+    URL       string
+    Namespace string
+    Mount     string
+    Path      string
+    Token     string
+    HTTP      *http.Client
+}
+
+// Fetch returns the latest version of the secret. Values that aren't
+// strings are returned as JSON.
+func (v *Vault) Fetch(ctx context.Context) (map[string][]byte, error) {
+    path := "/v1/" + strings.Trim(v.Mount, "/") + "/data/" + strings.Trim(v.Path, "/")
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(v.URL, "/")+path, nil)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("X-Vault-Token", v.Token)
+    if v.Namespace != "" {
+        req.Header.Set("X-Vault-Namespace", v.Namespace)
+    }
+    resp, err := v.HTTP.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        var e struct {
+            Errors []string `json:"errors"`
+        }
+        data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        msg := strings.TrimSpace(string(data))
+        if json.Unmarshal(data, &e) == nil && len(e.Errors) > 0 {
+            msg = strings.Join(e.Errors, "; ")
+        }
+        // KV answers a missing secret with a bare 404
+        if resp.StatusCode == http.StatusNotFound && msg == "" {
+            msg = "no secret at " + path
+        }
+        return nil, &StatusError{Code: resp.StatusCode, Message: msg, Server: "vault"}
+    }
+    var out struct {
+        Data struct {
+            Data map[string]json.RawMessage `json:"data"`
+        } `json:"data"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+        return nil, err
+    }
+    if out.Data.Data == nil {
+        return nil, fmt.Errorf("secret at %s is deleted or destroyed", path)
+    }
+    return values(out.Data.Data), nil
+}
+
+// values turns the fields of a JSON object into Secret data, unquoting
+// strings
+func values(fields map[string]json.RawMessage) map[string][]byte {
+    data := make(map[string][]byte, len(fields))
+    for k, raw := range fields {
+        var s string
+        if json.Unmarshal(raw, &s) == nil {
+            data[k] = []byte(s)
+            continue
+        }
+        data[k] = []byte(raw)
+    }
+    return data
+}
@@ -0,0 +1,240 @@
+// src/controllers/test/e2e/cluster.go
+package main
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/client-go/tools/clientcmd"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/yaml"
+
+    "github.com/Bailey7220/QRAIOP/controllers/pkg/qraiopclient"
+)
+
+const (
+    operatorNamespace  = "qraiop-system"
+    operatorDeployment = "qraiop-controller"
+    // testNamespace holds the instance and workloads of the scenarios
+    testNamespace = "qraiop-e2e"
+    pollInterval  = 2 * time.Second
+)
+
+// env is the cluster the scenarios run against
+type env struct {
+    opts       options
+    kubeconfig string
+    c          *qraiopclient.Client
+    // created is set when the run created the cluster, so it is only
+    // deleted then
+    created bool
+}
+
+// setUp creates the kind cluster unless it exists, loads the images and
+// installs the CRDs, RBAC and the operator under test
+func setUp(ctx context.Context, o options) (*env, error) {
+    e := &env{opts: o}
+    clusters, err := output(ctx, "kind", "get", "clusters")
+    if err != nil {
+        return nil, err
+    }
+    exists := false
+    for _, name := range strings.Fields(clusters) {
+        exists = exists || name == o.cluster
+    }
+    if !exists {
+        args := []string{"create", "cluster", "--name", o.cluster, "--wait", "2m"}
+        if o.kindImage != "" {
+            args = append(args, "--image", o.kindImage)
+        }
+        if err := command(ctx, "kind", args...); err != nil {
+            return nil, err
+        }
+        e.created = true
+    }
+
+    kubeconfig, err := output(ctx, "kind", "get", "kubeconfig", "--name", o.cluster)
+    if err != nil {
+        return e, err
+    }
+    f, err := os.CreateTemp("", "qraiop-e2e-kubeconfig-")
+    if err != nil {
+        return e, err
+    }
+    defer f.Close()
+    if _, err := f.WriteString(kubeconfig); err != nil {
+        return e, err
+    }
+    e.kubeconfig = f.Name()
+    cfg, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+    if err != nil {
+        return e, err
+    }
+    if e.c, err = qraiopclient.New(cfg); err != nil {
+        return e, err
+    }
+
+    images := append([]string{o.image}, splitList(o.componentImages)...)
+    if o.previousImage != "" {
+        images = append(images, o.previousImage)
+    }
+    for _, image := range images {
+        if err := command(ctx, "kind", "load", "docker-image", image, "--name", o.cluster); err != nil {
+            return e, fmt.Errorf("loading %s: %w", image, err)
+        }
+    }
+
+    if err := e.kubectl(ctx, nil, "apply", "--server-side", "-f", o.crds); err != nil {
+        return e, fmt.Errorf("installing CRDs: %w", err)
+    }
+    if err := e.kubectl(ctx, nil, "wait", "--for=condition=Established", "--timeout=60s", "crd", "--all"); err != nil {
+        return e, err
+    }
+    if err := e.kubectl(ctx, nil, "apply", "-f", filepath.Join(o.manifests, "namespace.yml")); err != nil {
+        return e, fmt.Errorf("installing RBAC: %w", err)
+    }
+    if err := e.kubectl(ctx, nil, "create", "namespace", testNamespace); err != nil && !strings.Contains(err.Error(), "AlreadyExists") {
+        return e, err
+    }
+    return e, nil
+}
+
+func (e *env) tearDown() {
+    if e.kubeconfig != "" {
+        os.Remove(e.kubeconfig)
+    }
+    if e.created {
+        // The run's context may be cancelled already
+        _ = command(context.Background(), "kind", "delete", "cluster", "--name", e.opts.cluster)
+    }
+}
+
+// deployOperator applies the operator Deployment from the manifests with
+// image, a single replica and the webhooks off, as they need a serving
+// certificate the cluster does not have, then waits for it to roll out
+func (e *env) deployOperator(ctx context.Context, image string) error {
+    raw, err := os.ReadFile(filepath.Join(e.opts.manifests, "controller-deployment.yml"))
+    if err != nil {
+        return err
+    }
+    var deployment *appsv1.Deployment
+    for _, doc := range strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n---") {
+        var d appsv1.Deployment
+        if err := yaml.Unmarshal([]byte(doc), &d); err == nil && d.Kind == "Deployment" {
+            deployment = &d
+            break
+        }
+    }
+    if deployment == nil {
+        return fmt.Errorf("no Deployment in controller-deployment.yml")
+    }
+    one := int32(1)
+    deployment.Spec.Replicas = &one
+    container := &deployment.Spec.Template.Spec.Containers[0]
+    container.Image = image
+    container.ImagePullPolicy = corev1.PullIfNotPresent
+    for i, arg := range container.Args {
+        if strings.HasPrefix(arg, "--enable-webhooks") {
+            container.Args[i] = "--enable-webhooks=false"
+        }
+    }
+    manifest, err := yaml.Marshal(deployment)
+    if err != nil {
+        return err
+    }
+    if err := e.kubectl(ctx, manifest, "apply", "-f", "-"); err != nil {
+        return err
+    }
+    return e.kubectl(ctx, nil, "rollout", "status", "-n", operatorNamespace, "deployment/"+operatorDeployment, "--timeout=3m")
+}
+
+// waitFor polls cond until it reports done, fails or ctx expires. The last
+// progress message is returned with a timeout.
+func waitFor(ctx context.Context, what string, cond func() (done bool, progress string, err error)) error {
+    last := ""
+    for {
+        done, progress, err := cond()
+        if err != nil {
+            return fmt.Errorf("waiting for %s: %w", what, err)
+        }
+        if done {
+            return nil
+        }
+        last = progress
+        select {
+        case <-ctx.Done():
+            if last != "" {
+                return fmt.Errorf("timed out waiting for %s: %s", what, last)
+            }
+            return fmt.Errorf("timed out waiting for %s", what)
+        case <-time.After(pollInterval):
+        }
+    }
+}
+
+// diagnostics collects what a failed scenario leaves behind: the
+// instances, experiments, pods and the operator's recent logs
+func (e *env) diagnostics() string {
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    var b strings.Builder
+    for _, args := range [][]string{
+        {"get", "qraiops,chaosexperiments,deployments,pods", "-n", testNamespace, "-o", "wide"},
+        {"get", "qraiops", "-n", testNamespace, "-o", "yaml"},
+        {"logs", "-n", operatorNamespace, "deployment/" + operatorDeployment, "--tail=200"},
+    } {
+        out, err := output(ctx, "kubectl", append([]string{"--kubeconfig", e.kubeconfig}, args...)...)
+        fmt.Fprintf(&b, "$ kubectl %s\n%s", strings.Join(args, " "), out)
+        if err != nil {
+            fmt.Fprintf(&b, "error: %v\n", err)
+        }
+    }
+    return b.String()
+}
+
+// deploymentAvailable reports whether a Deployment has rolled out
+func (e *env) deploymentAvailable(ctx context.Context, namespace, name string) (bool, error) {
+    var d appsv1.Deployment
+    if err := e.c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &d); err != nil {
+        return false, client.IgnoreNotFound(err)
+    }
+    return d.Status.ObservedGeneration >= d.Generation && d.Spec.Replicas != nil &&
+        d.Status.UpdatedReplicas == *d.Spec.Replicas && d.Status.AvailableReplicas == *d.Spec.Replicas, nil
+}
+
+func (e *env) kubectl(ctx context.Context, stdin []byte, args ...string) error {
+    cmd := exec.CommandContext(ctx, "kubectl", append([]string{"--kubeconfig", e.kubeconfig}, args...)...)
+    if stdin != nil {
+        cmd.Stdin = bytes.NewReader(stdin)
+    }
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        return fmt.Errorf("kubectl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+    }
+    return nil
+}
+
+func command(ctx context.Context, name string, args ...string) error {
+    cmd := exec.CommandContext(ctx, name, args...)
+    cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+    if err := cmd.Run(); err != nil {
+        return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+    }
+    return nil
+}
+
+func output(ctx context.Context, name string, args ...string) (string, error) {
+    out, err := exec.CommandContext(ctx, name, args...).Output()
+    if err != nil {
+        return string(out), fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+    }
+    return string(out), nil
+}
@@ -0,0 +1,203 @@
+// src/controllers/test/e2e/main.go
+
+// Command e2e runs the end-to-end scenarios against a kind cluster running
+// the real operator image: install, component rollout, key rotation, a
+// chaos experiment and an upgrade from the previous release. Results are
+// written as JUnit XML.
+//
+//	go run ./test/e2e -image qraiop-controller:e2e -crds config/crd \
+//	    -previous-image ghcr.io/bailey7220/qraiop-controller:v0.3.0 -junit e2e.xml
+//
+// The cluster is created unless it already exists and deleted afterwards
+// unless -keep is set. `make e2e` builds the image and CRDs first.
+package main
+
+import (
+    "context"
+    "encoding/xml"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// options are the command line flags
+type options struct {
+    cluster         string
+    kindImage       string
+    keep            bool
+    image           string
+    version         string
+    previousImage   string
+    previousVersion string
+    componentImages string
+    crds            string
+    manifests       string
+    junit           string
+    run             string
+    timeout         time.Duration
+    requireReady    bool
+}
+
+// scenario is one end-to-end test case. Scenarios run in order against the
+// same cluster and may build on each other's state.
+type scenario struct {
+    name string
+    run  func(ctx context.Context, e *env) error
+}
+
+var scenarios = []scenario{
+    {name: "install", run: testInstall},
+    {name: "component-rollout", run: testComponentRollout},
+    {name: "key-rotation", run: testKeyRotation},
+    {name: "chaos-experiment", run: testChaosExperiment},
+    {name: "upgrade", run: testUpgrade},
+}
+
+// skipped marks a scenario that could not run in this cluster, e.g.
+// because the component images are not available
+type skipped struct{ reason string }
+
+func (s skipped) Error() string { return s.reason }
+
+func main() {
+    var o options
+    flag.StringVar(&o.cluster, "cluster", "qraiop-e2e", "name of the kind cluster")
+    flag.StringVar(&o.kindImage, "kind-image", "", "node image of the kind cluster (default: kind's)")
+    flag.BoolVar(&o.keep, "keep", false, "keep the cluster after the run")
+    flag.StringVar(&o.image, "image", "qraiop-controller:e2e", "operator image under test, loaded into the cluster")
+    flag.StringVar(&o.version, "version", "e2e", "operator version the image under test was built with")
+    flag.StringVar(&o.previousImage, "previous-image", "", "image of the previous release, for the upgrade scenario")
+    flag.StringVar(&o.previousVersion, "previous-version", "", "version of the previous release image")
+    flag.StringVar(&o.componentImages, "component-images", "", "comma-separated component images to load into the cluster")
+    flag.StringVar(&o.crds, "crds", "config/crd", "directory of the generated CRD manifests")
+    flag.StringVar(&o.manifests, "manifests", "../../configs/k8", "directory of the operator manifests")
+    flag.StringVar(&o.junit, "junit", "", "file to write JUnit XML results to")
+    flag.StringVar(&o.run, "run", "", "regular expression selecting the scenarios to run")
+    flag.DurationVar(&o.timeout, "timeout", 5*time.Minute, "timeout of each scenario")
+    flag.BoolVar(&o.requireReady, "require-ready", false, "fail instead of skipping when components never become Ready")
+    flag.Parse()
+
+    ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer cancel()
+    if err := run(ctx, o); err != nil {
+        fmt.Fprintln(os.Stderr, "e2e:", err)
+        os.Exit(1)
+    }
+}
+
+func run(ctx context.Context, o options) error {
+    var filter *regexp.Regexp
+    if o.run != "" {
+        var err error
+        if filter, err = regexp.Compile(o.run); err != nil {
+            return fmt.Errorf("invalid -run: %w", err)
+        }
+    }
+
+    e, err := setUp(ctx, o)
+    if err != nil {
+        return fmt.Errorf("setting up cluster %s: %w", o.cluster, err)
+    }
+    if !o.keep {
+        defer e.tearDown()
+    }
+
+    suite := junitSuite{Name: "qraiop-e2e", Timestamp: time.Now().UTC().Format(time.RFC3339)}
+    start := time.Now()
+    failed := 0
+    for _, s := range scenarios {
+        if filter != nil && !filter.MatchString(s.name) {
+            continue
+        }
+        fmt.Printf("=== RUN   %s\n", s.name)
+        sctx, cancel := context.WithTimeout(ctx, o.timeout)
+        began := time.Now()
+        err := s.run(sctx, e)
+        cancel()
+        tc := junitCase{Name: s.name, ClassName: "qraiop.e2e", Time: seconds(time.Since(began))}
+        var skip skipped
+        switch {
+        case errors.As(err, &skip):
+            tc.Skipped = &junitMessage{Message: skip.reason}
+            fmt.Printf("--- SKIP: %s: %s\n", s.name, skip.reason)
+        case err != nil:
+            failed++
+            tc.Failure = &junitMessage{Message: err.Error(), Type: "failure", Body: e.diagnostics()}
+            fmt.Printf("--- FAIL: %s: %v\n", s.name, err)
+        default:
+            fmt.Printf("--- PASS: %s (%s)\n", s.name, time.Since(began).Round(time.Second))
+        }
+        suite.Cases = append(suite.Cases, tc)
+    }
+    suite.Tests = len(suite.Cases)
+    suite.Failures = failed
+    for _, tc := range suite.Cases {
+        if tc.Skipped != nil {
+            suite.Skipped++
+        }
+    }
+    suite.Time = seconds(time.Since(start))
+
+    if o.junit != "" {
+        out, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "  ")
+        if err != nil {
+            return err
+        }
+        if err := os.WriteFile(o.junit, append([]byte(xml.Header), out...), 0o644); err != nil {
+            return err
+        }
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d of %d scenarios failed", failed, suite.Tests)
+    }
+    return nil
+}
+
+type junitSuites struct {
+    XMLName xml.Name     `xml:"testsuites"`
+    Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+    Name      string      `xml:"name,attr"`
+    Tests     int         `xml:"tests,attr"`
+    Failures  int         `xml:"failures,attr"`
+    Skipped   int         `xml:"skipped,attr"`
+    Time      string      `xml:"time,attr"`
+    Timestamp string      `xml:"timestamp,attr"`
+    Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+    Name      string        `xml:"name,attr"`
+    ClassName string        `xml:"classname,attr"`
+    Time      string        `xml:"time,attr"`
+    Failure   *junitMessage `xml:"failure,omitempty"`
+    Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+    Message string `xml:"message,attr"`
+    Type    string `xml:"type,attr,omitempty"`
+    Body    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+    return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// splitList splits a comma-separated flag value
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
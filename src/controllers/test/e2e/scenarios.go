@@ -0,0 +1,314 @@
+// src/controllers/test/e2e/scenarios.go
+package main
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    "k8s.io/apimachinery/pkg/api/meta"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    qraioptesting "github.com/Bailey7220/QRAIOP/controllers/pkg/testing"
+)
+
+const (
+    instanceName = "e2e"
+    targetName   = "e2e-target"
+    // targetImage runs the chaos target; it is small and needs no config
+    targetImage = "registry.k8s.io/pause:3.9"
+)
+
+// testInstall rolls out the operator under test and checks it serves the API
+func testInstall(ctx context.Context, e *env) error {
+    if err := e.deployOperator(ctx, e.opts.image); err != nil {
+        return err
+    }
+    if _, err := e.c.ListQraiops(ctx, testNamespace); err != nil {
+        return fmt.Errorf("listing Qraiops: %w", err)
+    }
+    return nil
+}
+
+// testComponentRollout creates an instance and waits for every enabled
+// component to be rolled out. Without the component images in the cluster
+// the components stay Progressing; that skips the scenario unless
+// -require-ready is set.
+func testComponentRollout(ctx context.Context, e *env) error {
+    q := qraioptesting.NewQraiop(testNamespace, instanceName,
+        qraioptesting.WithCryptography("ML-KEM-768", "ML-DSA-65"),
+        qraioptesting.WithMonitoring(),
+        qraioptesting.WithChaos())
+    // The builder's fixtures are for fakes; the API server assigns these
+    q.UID, q.Generation = "", 0
+    if err := e.c.Create(ctx, q); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+
+    want := []string{qraiopv1.ComponentCryptography, qraiopv1.ComponentMonitoring, qraiopv1.ComponentChaosEngineering}
+    err := waitFor(ctx, "components to be reported", func() (bool, string, error) {
+        q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+        if err != nil {
+            return false, "", err
+        }
+        var missing []string
+        for _, name := range want {
+            if _, ok := q.Status.Components[name]; !ok {
+                missing = append(missing, name)
+            }
+        }
+        return len(missing) == 0, "no status for " + strings.Join(missing, ", "), nil
+    })
+    if err != nil {
+        return err
+    }
+
+    err = waitFor(ctx, "components to be Ready", func() (bool, string, error) {
+        return e.componentsReady(ctx)
+    })
+    if err != nil && !e.opts.requireReady {
+        return skipped{reason: "components not Ready, are the component images loaded? " + err.Error()}
+    }
+    return err
+}
+
+// testKeyRotation turns on a short rotation schedule and waits for the
+// crypto service to rotate its keys, then for the previous key to be
+// retired once the consumers rolled out with the new one
+func testKeyRotation(ctx context.Context, e *env) error {
+    if ready, progress, err := e.componentsReady(ctx); err != nil || !ready {
+        if err == nil {
+            err = skipped{reason: "key rotation needs Ready components: " + progress}
+        }
+        return err
+    }
+    q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+    if err != nil {
+        return err
+    }
+    patch := client.MergeFrom(q.DeepCopy())
+    q.Spec.Cryptography.KeyRotation = &qraiopv1.KeyRotationConfig{
+        Enabled:      true,
+        Interval:     metav1.Duration{Duration: 20 * time.Second},
+        GraceOverlap: metav1.Duration{Duration: 20 * time.Second},
+    }
+    if err := e.c.Patch(ctx, q, patch); err != nil {
+        return err
+    }
+    defer func() {
+        // Later scenarios expect a quiet instance
+        if q, err := e.c.GetQraiop(context.Background(), testNamespace, instanceName); err == nil {
+            patch := client.MergeFrom(q.DeepCopy())
+            q.Spec.Cryptography.KeyRotation = nil
+            _ = e.c.Patch(context.Background(), q, patch)
+        }
+    }()
+
+    var rotated string
+    err = waitFor(ctx, "a key rotation", func() (bool, string, error) {
+        q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+        if err != nil {
+            return false, "", err
+        }
+        st := q.Status.KeyRotation
+        if st == nil || len(st.History) == 0 {
+            return false, conditionMessage(q, "KeyRotation"), nil
+        }
+        rotated = st.History[0].KeyID
+        return true, "", nil
+    })
+    if err != nil {
+        return err
+    }
+    return waitFor(ctx, "the previous key to be retired", func() (bool, string, error) {
+        q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+        if err != nil {
+            return false, "", err
+        }
+        for _, h := range q.Status.KeyRotation.History {
+            if h.KeyID == rotated {
+                return h.RetiredAt != nil, conditionMessage(q, "KeyRotation"), nil
+            }
+        }
+        return false, "rotation to " + rotated + " dropped from the history", nil
+    })
+}
+
+// testChaosExperiment kills half the pods of a throwaway Deployment and
+// checks the experiment passes and the Deployment recovers
+func testChaosExperiment(ctx context.Context, e *env) error {
+    labels := map[string]string{"app": targetName}
+    two := int32(2)
+    target := &appsv1.Deployment{
+        ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: targetName, Labels: labels},
+        Spec: appsv1.DeploymentSpec{
+            Replicas: &two,
+            Selector: &metav1.LabelSelector{MatchLabels: labels},
+            Template: corev1.PodTemplateSpec{
+                ObjectMeta: metav1.ObjectMeta{Labels: labels},
+                Spec: corev1.PodSpec{
+                    Containers: []corev1.Container{{Name: "pause", Image: targetImage}},
+                },
+            },
+        },
+    }
+    if err := e.c.Create(ctx, target); err != nil && !apierrors.IsAlreadyExists(err) {
+        return err
+    }
+    if err := waitFor(ctx, "the target to be available", func() (bool, string, error) {
+        ok, err := e.deploymentAvailable(ctx, testNamespace, targetName)
+        return ok, "", err
+    }); err != nil {
+        return err
+    }
+    before, err := e.podUIDs(ctx, labels)
+    if err != nil {
+        return err
+    }
+
+    exp := qraioptesting.NewChaosExperiment(testNamespace, "e2e-pod-kill", "pod_kill", labels,
+        qraioptesting.WithPercentage(50),
+        qraioptesting.WithDuration(10))
+    exp.UID, exp.Generation = "", 0
+    _ = e.c.Delete(ctx, exp)
+    if err := waitFor(ctx, "the previous experiment to be deleted", func() (bool, string, error) {
+        _, err := e.c.GetChaosExperiment(ctx, testNamespace, exp.Name)
+        return apierrors.IsNotFound(err), "", client.IgnoreNotFound(err)
+    }); err != nil {
+        return err
+    }
+    if err := e.c.Create(ctx, exp); err != nil {
+        return err
+    }
+
+    var finished *qraiopv1.ChaosExperiment
+    if err := waitFor(ctx, "the experiment to finish", func() (bool, string, error) {
+        exp, err := e.c.GetChaosExperiment(ctx, testNamespace, "e2e-pod-kill")
+        if err != nil {
+            return false, "", err
+        }
+        finished = exp
+        return exp.Status.IsFinished(), "phase " + exp.Status.Phase + ": " + exp.Status.Message, nil
+    }); err != nil {
+        return err
+    }
+    if finished.Status.Phase != qraiopv1.ExperimentCompleted || finished.Status.Verdict != qraiopv1.VerdictPassed {
+        return fmt.Errorf("experiment ended %s with verdict %s: %s", finished.Status.Phase, finished.Status.Verdict, finished.Status.Message)
+    }
+    if len(finished.Status.Targets) != 1 {
+        return fmt.Errorf("50%% of 2 pods should target 1, got %v", finished.Status.Targets)
+    }
+
+    return waitFor(ctx, "the target to recover", func() (bool, string, error) {
+        ok, err := e.deploymentAvailable(ctx, testNamespace, targetName)
+        if err != nil || !ok {
+            return false, "not available", err
+        }
+        after, err := e.podUIDs(ctx, labels)
+        if err != nil {
+            return false, "", err
+        }
+        replaced := 0
+        for _, uid := range after {
+            if !contains(before, uid) {
+                replaced++
+            }
+        }
+        return replaced >= 1, fmt.Sprintf("%d pods replaced", replaced), nil
+    })
+}
+
+// testUpgrade rolls back to the previous release, lets it reconcile the
+// instance, then upgrades to the image under test and checks its upgrade
+// hooks ran and it recorded its version
+func testUpgrade(ctx context.Context, e *env) error {
+    if e.opts.previousImage == "" {
+        return skipped{reason: "no -previous-image given"}
+    }
+    if err := e.deployOperator(ctx, e.opts.previousImage); err != nil {
+        return fmt.Errorf("installing previous release: %w", err)
+    }
+    if e.opts.previousVersion != "" {
+        if err := e.waitOperatorVersion(ctx, e.opts.previousVersion); err != nil {
+            return err
+        }
+    }
+    if err := e.deployOperator(ctx, e.opts.image); err != nil {
+        return fmt.Errorf("upgrading: %w", err)
+    }
+    if err := e.waitOperatorVersion(ctx, e.opts.version); err != nil {
+        return err
+    }
+    q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+    if err != nil {
+        return err
+    }
+    cond := meta.FindStatusCondition(q.Status.Conditions, "Upgraded")
+    if cond == nil || cond.Status != metav1.ConditionTrue {
+        return fmt.Errorf("upgrade hooks did not succeed: %s", conditionMessage(q, "Upgraded"))
+    }
+    return nil
+}
+
+// componentsReady reports whether every component of the instance is Ready
+func (e *env) componentsReady(ctx context.Context) (bool, string, error) {
+    q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+    if err != nil {
+        return false, "", client.IgnoreNotFound(err)
+    }
+    var pending []string
+    for name, st := range q.Status.Components {
+        if st.Status != qraiopv1.ComponentReady && st.Status != qraiopv1.ComponentDisabled {
+            pending = append(pending, fmt.Sprintf("%s is %s: %s", name, st.Status, st.Message))
+        }
+    }
+    sort.Strings(pending)
+    return len(q.Status.Components) > 0 && len(pending) == 0, strings.Join(pending, "; "), nil
+}
+
+func (e *env) waitOperatorVersion(ctx context.Context, version string) error {
+    return waitFor(ctx, "operator version "+version, func() (bool, string, error) {
+        q, err := e.c.GetQraiop(ctx, testNamespace, instanceName)
+        if err != nil {
+            return false, "", err
+        }
+        return q.Status.OperatorVersion == version, "instance at " + q.Status.OperatorVersion, nil
+    })
+}
+
+func (e *env) podUIDs(ctx context.Context, labels map[string]string) ([]string, error) {
+    var pods corev1.PodList
+    if err := e.c.List(ctx, &pods, client.InNamespace(testNamespace), client.MatchingLabels(labels)); err != nil {
+        return nil, err
+    }
+    var uids []string
+    for _, p := range pods.Items {
+        if p.DeletionTimestamp == nil {
+            uids = append(uids, string(p.UID))
+        }
+    }
+    return uids, nil
+}
+
+func conditionMessage(q *qraiopv1.Qraiop, conditionType string) string {
+    if c := meta.FindStatusCondition(q.Status.Conditions, conditionType); c != nil {
+        return c.Reason + ": " + c.Message
+    }
+    return "no " + conditionType + " condition"
+}
+
+func contains(items []string, item string) bool {
+    for _, i := range items {
+        if i == item {
+            return true
+        }
+    }
+    return false
+}
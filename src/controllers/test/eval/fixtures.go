@@ -0,0 +1,136 @@
+// src/controllers/test/eval/fixtures.go
+package main
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+
+    "sigs.k8s.io/yaml"
+)
+
+// fixture is a recorded cluster incident and what an agent should propose
+// for it
+type fixture struct {
+    Name        string `json:"name"`
+    Description string `json:"description,omitempty"`
+    // Agent is the agent the incident is handed to
+    Agent string `json:"agent"`
+    Task  task   `json:"task"`
+    // Incident is the recorded cluster state: alerts, events, objects and
+    // logs as captured when it happened. It is passed to the agent as-is.
+    Incident map[string]interface{} `json:"incident"`
+    Expect   expectation            `json:"expect"`
+
+    file string
+}
+
+// task is what the agent is asked to do about the incident, as in an
+// AgentTask
+type task struct {
+    Type       string            `json:"type"`
+    Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// expectation is the outcome a fixture scores proposals against
+type expectation struct {
+    // Actions should all be proposed; an empty list expects the agent to
+    // propose nothing
+    Actions []expectedAction `json:"actions,omitempty"`
+    // Forbidden actions fail the fixture whatever else was proposed
+    Forbidden []actionPattern `json:"forbidden,omitempty"`
+    // MinScore overrides -min-score for this fixture
+    MinScore *float64 `json:"minScore,omitempty"`
+}
+
+// actionPattern matches proposed actions. Type and Target are shell
+// patterns as in path.Match; every parameter must be proposed with the
+// value given.
+type actionPattern struct {
+    Type       string            `json:"type"`
+    Target     string            `json:"target,omitempty"`
+    Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+type expectedAction struct {
+    actionPattern
+    // Weight is the share of the score the action is worth. Defaults to 1.
+    Weight float64 `json:"weight,omitempty"`
+}
+
+func (p actionPattern) String() string {
+    if p.Target == "" {
+        return p.Type
+    }
+    return p.Type + " " + p.Target
+}
+
+// loadFixtures reads every *.yaml fixture of dir, sorted by name
+func loadFixtures(dir string) ([]fixture, error) {
+    files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+    if err != nil {
+        return nil, err
+    }
+    var fixtures []fixture
+    names := map[string]string{}
+    for _, file := range files {
+        data, err := os.ReadFile(file)
+        if err != nil {
+            return nil, err
+        }
+        var f fixture
+        if err := yaml.UnmarshalStrict(data, &f); err != nil {
+            return nil, fmt.Errorf("%s: %w", file, err)
+        }
+        f.file = file
+        if err := f.validate(); err != nil {
+            return nil, fmt.Errorf("%s: %w", file, err)
+        }
+        if other, ok := names[f.Name]; ok {
+            return nil, fmt.Errorf("%s: fixture %s already defined in %s", file, f.Name, other)
+        }
+        names[f.Name] = file
+        fixtures = append(fixtures, f)
+    }
+    if len(fixtures) == 0 {
+        return nil, fmt.Errorf("no fixtures in %s", dir)
+    }
+    sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Name < fixtures[j].Name })
+    return fixtures, nil
+}
+
+func (f *fixture) validate() error {
+    switch {
+    case f.Name == "":
+        return fmt.Errorf("fixture has no name")
+    case f.Agent == "":
+        return fmt.Errorf("fixture %s has no agent", f.Name)
+    case f.Task.Type == "":
+        return fmt.Errorf("fixture %s has no task type", f.Name)
+    case len(f.Incident) == 0:
+        return fmt.Errorf("fixture %s records no incident", f.Name)
+    }
+    for _, p := range append(expectedPatterns(f.Expect.Actions), f.Expect.Forbidden...) {
+        if p.Type == "" {
+            return fmt.Errorf("fixture %s has an action without type", f.Name)
+        }
+        if !validPattern(p.Type) || !validPattern(p.Target) {
+            return fmt.Errorf("fixture %s: malformed pattern in %q", f.Name, p)
+        }
+    }
+    for _, a := range f.Expect.Actions {
+        if a.Weight < 0 {
+            return fmt.Errorf("fixture %s: negative weight for %q", f.Name, a.actionPattern)
+        }
+    }
+    return nil
+}
+
+func expectedPatterns(actions []expectedAction) []actionPattern {
+    patterns := make([]actionPattern, len(actions))
+    for i, a := range actions {
+        patterns[i] = a.actionPattern
+    }
+    return patterns
+}
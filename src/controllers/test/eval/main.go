@@ -0,0 +1,390 @@
+// src/controllers/test/eval/main.go
+
+// Command eval replays recorded cluster incidents to the AI agents and
+// scores the actions they propose against the expected outcome of each
+// incident, so a model or prompt upgrade can be validated before rollout.
+//
+//	go run ./test/eval -image ghcr.io/bailey7220/qraiop-ai:v0.4.0 \
+//	    -provider anthropic -model claude-sonnet-4 -env ANTHROPIC_API_KEY \
+//	    -baseline eval-baseline.json -out eval-results.json -junit eval.xml
+//
+// The agents run in a sandbox: the image is started in a local container
+// with QRAIOP_SANDBOX set and no Kubernetes credentials, and each incident
+// is posted to /v1/eval/propose, which answers with the actions the agent
+// would take. -agent-url uses a sandbox that is already running instead.
+//
+// A fixture fails when its mean score over -runs stays below its minimum,
+// when any run proposes a forbidden action or, given -baseline, when it
+// scores lower than in the baseline run by more than -tolerance. Results
+// are written as JSON, to be used as the next baseline, as JUnit XML and
+// as Prometheus metrics. `make eval` runs the fixtures of test/eval/fixtures.
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "encoding/xml"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// options are the command line flags
+type options struct {
+    fixtures    string
+    agentURL    string
+    image       string
+    provider    string
+    model       string
+    env         string
+    run         string
+    runs        int
+    minScore    float64
+    baseline    string
+    tolerance   float64
+    out         string
+    junit       string
+    metricsFile string
+    pushgateway string
+    timeout     time.Duration
+}
+
+// report is the result of a run, and the baseline of the next one
+type report struct {
+    Provider  string `json:"provider,omitempty"`
+    Model     string `json:"model"`
+    Timestamp string `json:"timestamp"`
+    // Score is the mean score over all fixtures
+    Score    float64         `json:"score"`
+    Fixtures []fixtureResult `json:"fixtures"`
+}
+
+// fixtureResult is the outcome of one fixture over all its runs
+type fixtureResult struct {
+    Name     string   `json:"name"`
+    Agent    string   `json:"agent"`
+    Score    float64  `json:"score"`
+    MinScore float64  `json:"minScore"`
+    Baseline *float64 `json:"baseline,omitempty"`
+    Passed   bool     `json:"passed"`
+    // Regressed is set when the score fell below the baseline by more
+    // than the tolerance
+    Regressed bool        `json:"regressed,omitempty"`
+    Runs      []runResult `json:"runs"`
+}
+
+// runResult is one proposal for a fixture and its score
+type runResult struct {
+    score
+    Actions  []action `json:"actions,omitempty"`
+    Summary  string   `json:"summary,omitempty"`
+    Duration string   `json:"duration"`
+    Error    string   `json:"error,omitempty"`
+}
+
+func main() {
+    var o options
+    flag.StringVar(&o.fixtures, "fixtures", "test/eval/fixtures", "directory of the recorded incident fixtures")
+    flag.StringVar(&o.agentURL, "agent-url", "", "URL of a running sandbox to use instead of starting -image")
+    flag.StringVar(&o.image, "image", "", "AI orchestration image to run in the sandbox")
+    flag.StringVar(&o.provider, "provider", "", "LLM provider the agents use (default: the image's)")
+    flag.StringVar(&o.model, "model", "", "LLM model the agents use (default: the image's)")
+    flag.StringVar(&o.env, "env", "", "comma-separated environment variables passed on to the sandbox, e.g. API keys")
+    flag.StringVar(&o.run, "run", "", "regular expression selecting the fixtures to run")
+    flag.IntVar(&o.runs, "runs", 1, "how often each incident is replayed; the score is the mean")
+    flag.Float64Var(&o.minScore, "min-score", 0.8, "score a fixture needs unless it sets its own minScore")
+    flag.StringVar(&o.baseline, "baseline", "", "results of a previous run to check for regressions against")
+    flag.Float64Var(&o.tolerance, "tolerance", 0.05, "how far a score may fall below the baseline")
+    flag.StringVar(&o.out, "out", "", "file to write the results to as JSON")
+    flag.StringVar(&o.junit, "junit", "", "file to write JUnit XML results to")
+    flag.StringVar(&o.metricsFile, "metrics-file", "", "file to write Prometheus metrics to in the text format")
+    flag.StringVar(&o.pushgateway, "pushgateway", "", "URL of a Pushgateway to push the metrics to")
+    flag.DurationVar(&o.timeout, "timeout", 5*time.Minute, "timeout of each proposal")
+    flag.Parse()
+
+    ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer cancel()
+    if err := run(ctx, o); err != nil {
+        fmt.Fprintln(os.Stderr, "eval:", err)
+        os.Exit(1)
+    }
+}
+
+func run(ctx context.Context, o options) error {
+    if (o.image == "") == (o.agentURL == "") {
+        return errors.New("set exactly one of -image and -agent-url")
+    }
+    if o.runs < 1 {
+        return errors.New("-runs must be at least 1")
+    }
+    var filter *regexp.Regexp
+    if o.run != "" {
+        var err error
+        if filter, err = regexp.Compile(o.run); err != nil {
+            return fmt.Errorf("invalid -run: %w", err)
+        }
+    }
+    fixtures, err := loadFixtures(o.fixtures)
+    if err != nil {
+        return err
+    }
+    baseline := map[string]float64{}
+    if o.baseline != "" {
+        if baseline, err = loadBaseline(o.baseline); err != nil {
+            return fmt.Errorf("reading baseline: %w", err)
+        }
+    }
+
+    var s *sandbox
+    if o.agentURL != "" {
+        s, err = connectSandbox(ctx, o.agentURL)
+    } else {
+        s, err = startSandbox(ctx, o.image, o.provider, o.model, splitList(o.env))
+    }
+    if err != nil {
+        return fmt.Errorf("starting sandbox: %w", err)
+    }
+    defer s.stop()
+
+    model := o.model
+    if model == "" {
+        model = "default"
+    }
+    m := newMetrics(model)
+    rep := report{Provider: o.provider, Model: model, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+    suite := junitSuite{Name: "qraiop-eval", Timestamp: rep.Timestamp}
+    start := time.Now()
+    failed := 0
+    for _, f := range fixtures {
+        if filter != nil && !filter.MatchString(f.Name) {
+            continue
+        }
+        fmt.Printf("=== RUN   %s\n", f.Name)
+        began := time.Now()
+        res := evaluate(ctx, s, f, o, m)
+        if b, ok := baseline[f.Name]; ok {
+            res.Baseline = &b
+            res.Regressed = res.Score < b-o.tolerance
+        }
+        rep.Fixtures = append(rep.Fixtures, res)
+        rep.Score += res.Score
+
+        labels := []string{f.Name, f.Agent}
+        m.score.WithLabelValues(labels...).Set(res.Score)
+        m.passed.WithLabelValues(labels...).Set(boolValue(res.Passed))
+        m.regressed.WithLabelValues(labels...).Set(boolValue(res.Regressed))
+
+        tc := junitCase{Name: f.Name, ClassName: "qraiop.eval." + f.Agent, Time: seconds(time.Since(began))}
+        if problem := res.problem(); problem != "" {
+            failed++
+            tc.Failure = &junitMessage{Message: problem, Type: "failure", Body: res.details()}
+            fmt.Printf("--- FAIL: %s: %s\n", f.Name, problem)
+        } else {
+            fmt.Printf("--- PASS: %s (score %.2f)\n", f.Name, res.Score)
+        }
+        suite.Cases = append(suite.Cases, tc)
+    }
+    if len(rep.Fixtures) == 0 {
+        return errors.New("-run selects no fixtures")
+    }
+    rep.Score /= float64(len(rep.Fixtures))
+    m.suiteScore.Set(rep.Score)
+    suite.Tests = len(suite.Cases)
+    suite.Failures = failed
+    suite.Time = seconds(time.Since(start))
+    fmt.Printf("score %.3f over %d fixtures\n", rep.Score, len(rep.Fixtures))
+
+    if err := writeResults(o, rep, suite, m); err != nil {
+        return err
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d of %d fixtures failed", failed, suite.Tests)
+    }
+    return nil
+}
+
+// evaluate replays a fixture's incident o.runs times and scores each
+// proposal. A failed proposal scores 0.
+func evaluate(ctx context.Context, s *sandbox, f fixture, o options, m *metrics) fixtureResult {
+    res := fixtureResult{Name: f.Name, Agent: f.Agent, MinScore: o.minScore}
+    if f.Expect.MinScore != nil {
+        res.MinScore = *f.Expect.MinScore
+    }
+    forbidden := false
+    for i := 0; i < o.runs; i++ {
+        pctx, cancel := context.WithTimeout(ctx, o.timeout)
+        began := time.Now()
+        p, err := s.propose(pctx, f)
+        cancel()
+        took := time.Since(began)
+        r := runResult{Duration: took.Round(time.Millisecond).String()}
+        if err != nil {
+            r.Error = err.Error()
+            m.errors.WithLabelValues(f.Name, f.Agent).Inc()
+        } else {
+            m.latency.WithLabelValues(f.Agent).Observe(took.Seconds())
+            r.score = scoreProposal(f.Expect, p.Actions)
+            r.Actions, r.Summary = p.Actions, p.Summary
+            m.forbidden.WithLabelValues(f.Name, f.Agent).Add(float64(len(r.Forbidden)))
+            forbidden = forbidden || len(r.Forbidden) > 0
+        }
+        res.Runs = append(res.Runs, r)
+        res.Score += r.Value
+    }
+    res.Score /= float64(o.runs)
+    res.Passed = res.Score >= res.MinScore && !forbidden
+    return res
+}
+
+// problem says why a fixture failed, or is empty when it didn't
+func (r fixtureResult) problem() string {
+    var problems []string
+    for i, run := range r.Runs {
+        if len(run.Forbidden) > 0 {
+            problems = append(problems, fmt.Sprintf("run %d proposed forbidden %s", i+1, strings.Join(run.Forbidden, ", ")))
+        }
+    }
+    if r.Score < r.MinScore {
+        problems = append(problems, fmt.Sprintf("score %.2f below %.2f", r.Score, r.MinScore))
+    }
+    if r.Regressed {
+        problems = append(problems, fmt.Sprintf("score %.2f regressed from baseline %.2f", r.Score, *r.Baseline))
+    }
+    return strings.Join(problems, "; ")
+}
+
+// details lists what each run of a failed fixture got wrong
+func (r fixtureResult) details() string {
+    var b strings.Builder
+    for i, run := range r.Runs {
+        fmt.Fprintf(&b, "run %d: score %.2f (recall %.2f, precision %.2f) in %s\n", i+1, run.Value, run.Recall, run.Precision, run.Duration)
+        for _, l := range []struct {
+            what  string
+            items []string
+        }{
+            {"error", nonEmpty(run.Error)},
+            {"missing", run.Missing},
+            {"unexpected", run.Unexpected},
+            {"forbidden", run.Forbidden},
+        } {
+            if len(l.items) > 0 {
+                fmt.Fprintf(&b, "  %s: %s\n", l.what, strings.Join(l.items, ", "))
+            }
+        }
+        if run.Summary != "" {
+            fmt.Fprintf(&b, "  summary: %s\n", run.Summary)
+        }
+    }
+    return b.String()
+}
+
+// loadBaseline returns the fixture scores of a previous run's results
+func loadBaseline(file string) (map[string]float64, error) {
+    data, err := os.ReadFile(file)
+    if err != nil {
+        return nil, err
+    }
+    var prev report
+    if err := json.Unmarshal(data, &prev); err != nil {
+        return nil, err
+    }
+    scores := make(map[string]float64, len(prev.Fixtures))
+    for _, f := range prev.Fixtures {
+        scores[f.Name] = f.Score
+    }
+    return scores, nil
+}
+
+// writeResults writes the report, JUnit XML and metrics o asks for
+func writeResults(o options, rep report, suite junitSuite, m *metrics) error {
+    if o.out != "" {
+        out, err := json.MarshalIndent(rep, "", "  ")
+        if err != nil {
+            return err
+        }
+        if err := os.WriteFile(o.out, append(out, '\n'), 0o644); err != nil {
+            return err
+        }
+    }
+    if o.junit != "" {
+        out, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "  ")
+        if err != nil {
+            return err
+        }
+        if err := os.WriteFile(o.junit, append([]byte(xml.Header), out...), 0o644); err != nil {
+            return err
+        }
+    }
+    if o.metricsFile != "" {
+        if err := m.writeFile(o.metricsFile); err != nil {
+            return fmt.Errorf("writing metrics: %w", err)
+        }
+    }
+    if o.pushgateway != "" {
+        if err := m.push(o.pushgateway, rep.Model); err != nil {
+            return fmt.Errorf("pushing metrics: %w", err)
+        }
+    }
+    return nil
+}
+
+type junitSuites struct {
+    XMLName xml.Name     `xml:"testsuites"`
+    Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+    Name      string      `xml:"name,attr"`
+    Tests     int         `xml:"tests,attr"`
+    Failures  int         `xml:"failures,attr"`
+    Time      string      `xml:"time,attr"`
+    Timestamp string      `xml:"timestamp,attr"`
+    Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+    Name      string        `xml:"name,attr"`
+    ClassName string        `xml:"classname,attr"`
+    Time      string        `xml:"time,attr"`
+    Failure   *junitMessage `xml:"failure,omitempty"`
+}
+
+type junitMessage struct {
+    Message string `xml:"message,attr"`
+    Type    string `xml:"type,attr,omitempty"`
+    Body    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+    return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func boolValue(b bool) float64 {
+    if b {
+        return 1
+    }
+    return 0
+}
+
+func nonEmpty(s string) []string {
+    if s == "" {
+        return nil
+    }
+    return []string{s}
+}
+
+// splitList splits a comma-separated flag value
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
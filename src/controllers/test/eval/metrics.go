@@ -0,0 +1,76 @@
+// src/controllers/test/eval/metrics.go
+package main
+
+import (
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/push"
+)
+
+// metrics are the results of a run, labelled with the model evaluated so
+// runs of different models can be compared side by side
+type metrics struct {
+    registry   *prometheus.Registry
+    score      *prometheus.GaugeVec
+    passed     *prometheus.GaugeVec
+    regressed  *prometheus.GaugeVec
+    forbidden  *prometheus.CounterVec
+    errors     *prometheus.CounterVec
+    latency    *prometheus.HistogramVec
+    suiteScore prometheus.Gauge
+}
+
+func newMetrics(model string) *metrics {
+    labels := prometheus.Labels{"model": model}
+    m := &metrics{
+        registry: prometheus.NewRegistry(),
+        score: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name:        "qraiop_eval_score",
+            Help:        "Mean score of the proposals for a fixture, from 0 to 1.",
+            ConstLabels: labels,
+        }, []string{"fixture", "agent"}),
+        passed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name:        "qraiop_eval_passed",
+            Help:        "Whether a fixture reached its minimum score without forbidden actions.",
+            ConstLabels: labels,
+        }, []string{"fixture", "agent"}),
+        regressed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+            Name:        "qraiop_eval_regressed",
+            Help:        "Whether a fixture scored below its baseline by more than the tolerance.",
+            ConstLabels: labels,
+        }, []string{"fixture", "agent"}),
+        forbidden: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name:        "qraiop_eval_forbidden_actions_total",
+            Help:        "Forbidden actions proposed for a fixture.",
+            ConstLabels: labels,
+        }, []string{"fixture", "agent"}),
+        errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name:        "qraiop_eval_errors_total",
+            Help:        "Proposals for a fixture the agents failed to make.",
+            ConstLabels: labels,
+        }, []string{"fixture", "agent"}),
+        latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Name:        "qraiop_eval_proposal_duration_seconds",
+            Help:        "Time the agents took to propose actions for an incident.",
+            ConstLabels: labels,
+            Buckets:     []float64{1, 2, 5, 10, 20, 30, 60, 120, 300},
+        }, []string{"agent"}),
+        suiteScore: prometheus.NewGauge(prometheus.GaugeOpts{
+            Name:        "qraiop_eval_suite_score",
+            Help:        "Mean score over all fixtures run.",
+            ConstLabels: labels,
+        }),
+    }
+    m.registry.MustRegister(m.score, m.passed, m.regressed, m.forbidden, m.errors, m.latency, m.suiteScore)
+    return m
+}
+
+// writeFile writes the metrics in the text format, e.g. for the node
+// exporter's textfile collector
+func (m *metrics) writeFile(file string) error {
+    return prometheus.WriteToTextfile(file, m.registry)
+}
+
+// push replaces the metrics of the model's previous run on a Pushgateway
+func (m *metrics) push(url, model string) error {
+    return push.New(url, "qraiop-eval").Gatherer(m.registry).Grouping("model", model).Push()
+}
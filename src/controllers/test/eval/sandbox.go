@@ -0,0 +1,183 @@
+// src/controllers/test/eval/sandbox.go
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "os"
+    "os/exec"
+    "strings"
+    "time"
+)
+
+const (
+    // agentPort is the port the AI orchestration service listens on
+    agentPort    = "8080"
+    pollInterval = 2 * time.Second
+)
+
+// sandbox is an AI orchestration service running in propose-only mode:
+// agents answer with the actions they would take instead of taking them.
+// A sandbox started from an image has no Kubernetes credentials at all.
+type sandbox struct {
+    url       string
+    container string
+    http      *http.Client
+}
+
+// startSandbox runs image in a container with QRAIOP_SANDBOX set and waits
+// for it to answer. env names variables, e.g. LLM API keys, passed on from
+// the environment of the harness; their values never appear on a command
+// line.
+func startSandbox(ctx context.Context, image, provider, model string, env []string) (*sandbox, error) {
+    name := fmt.Sprintf("qraiop-eval-%d", os.Getpid())
+    args := []string{"run", "--detach", "--rm", "--name", name,
+        "--publish", "127.0.0.1::" + agentPort,
+        "--env", "QRAIOP_SANDBOX=true",
+    }
+    if provider != "" {
+        args = append(args, "--env", "QRAIOP_LLM_PROVIDER="+provider)
+    }
+    if model != "" {
+        args = append(args, "--env", "QRAIOP_LLM_MODEL="+model)
+    }
+    for _, v := range env {
+        if _, ok := os.LookupEnv(v); !ok {
+            return nil, fmt.Errorf("%s is not set", v)
+        }
+        args = append(args, "--env", v)
+    }
+    if _, err := output(ctx, "docker", append(args, image)...); err != nil {
+        return nil, err
+    }
+    s := &sandbox{container: name, http: &http.Client{}}
+    addr, err := output(ctx, "docker", "port", name, agentPort)
+    if err != nil {
+        s.stop()
+        return nil, err
+    }
+    // docker port lists one address per line, IPv4 first
+    addr = strings.TrimSpace(strings.SplitN(addr, "\n", 2)[0])
+    if _, _, err := net.SplitHostPort(addr); err != nil {
+        s.stop()
+        return nil, fmt.Errorf("unexpected address %q of container %s", addr, name)
+    }
+    s.url = "http://" + addr
+    if err := s.waitReady(ctx); err != nil {
+        fmt.Fprint(os.Stderr, s.logs())
+        s.stop()
+        return nil, err
+    }
+    return s, nil
+}
+
+// connectSandbox uses a sandbox already running at url
+func connectSandbox(ctx context.Context, url string) (*sandbox, error) {
+    s := &sandbox{url: strings.TrimSuffix(url, "/"), http: &http.Client{}}
+    return s, s.waitReady(ctx)
+}
+
+// waitReady polls the service's activity endpoint until it answers
+func (s *sandbox) waitReady(ctx context.Context) error {
+    ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+    defer cancel()
+    var last error
+    for {
+        req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/v1/tasks/activity", nil)
+        if err != nil {
+            return err
+        }
+        resp, err := s.http.Do(req)
+        if err == nil {
+            resp.Body.Close()
+            if resp.StatusCode == http.StatusOK {
+                return nil
+            }
+            err = fmt.Errorf("%s", resp.Status)
+        }
+        last = err
+        select {
+        case <-ctx.Done():
+            return fmt.Errorf("agents at %s never became ready: %v", s.url, last)
+        case <-time.After(pollInterval):
+        }
+    }
+}
+
+// proposal is the answer of the agents to an incident
+type proposal struct {
+    Actions []action `json:"actions"`
+    // Summary is the agent's reasoning, kept for the report
+    Summary string `json:"summary,omitempty"`
+}
+
+// propose hands a fixture's incident to its agent and returns the actions
+// the agent proposes
+func (s *sandbox) propose(ctx context.Context, f fixture) (proposal, error) {
+    body, err := json.Marshal(map[string]interface{}{
+        "agent":    f.Agent,
+        "task":     f.Task,
+        "incident": f.Incident,
+    })
+    if err != nil {
+        return proposal{}, err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/v1/eval/propose", bytes.NewReader(body))
+    if err != nil {
+        return proposal{}, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    resp, err := s.http.Do(req)
+    if err != nil {
+        return proposal{}, err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        return proposal{}, fmt.Errorf("agents returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+    }
+    var p proposal
+    if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+        return proposal{}, fmt.Errorf("decoding proposal: %w", err)
+    }
+    return p, nil
+}
+
+// logs returns the recent output of a sandbox container
+func (s *sandbox) logs() string {
+    if s.container == "" {
+        return ""
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    out, _ := exec.CommandContext(ctx, "docker", "logs", "--tail", "200", s.container).CombinedOutput()
+    return string(out)
+}
+
+// stop removes a container started by startSandbox
+func (s *sandbox) stop() {
+    if s.container == "" {
+        return
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    if _, err := output(ctx, "docker", "rm", "--force", s.container); err != nil {
+        fmt.Fprintln(os.Stderr, "eval:", err)
+    }
+}
+
+func output(ctx context.Context, name string, args ...string) (string, error) {
+    var stderr bytes.Buffer
+    cmd := exec.CommandContext(ctx, name, args...)
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        return string(out), fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+    }
+    return string(out), nil
+}
@@ -0,0 +1,118 @@
+// src/controllers/test/eval/score.go
+package main
+
+import (
+    "fmt"
+    "path"
+    "sort"
+)
+
+// action is one action an agent proposed
+type action struct {
+    Type       string                 `json:"type"`
+    Target     string                 `json:"target,omitempty"`
+    Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+func (a action) String() string {
+    if a.Target == "" {
+        return a.Type
+    }
+    return a.Type + " " + a.Target
+}
+
+// score is how well one proposal met a fixture's expectation
+type score struct {
+    // Value is Recall times Precision, or 0 with a forbidden action
+    Value float64 `json:"score"`
+    // Recall is the weighted share of the expected actions proposed
+    Recall float64 `json:"recall"`
+    // Precision is the share of the proposed actions that were expected
+    Precision  float64  `json:"precision"`
+    Missing    []string `json:"missing,omitempty"`
+    Unexpected []string `json:"unexpected,omitempty"`
+    Forbidden  []string `json:"forbidden,omitempty"`
+}
+
+// scoreProposal scores proposed against expect. Each expected action is
+// matched by at most one proposed action, the heaviest first. Proposing
+// nothing when nothing is expected scores 1.
+func scoreProposal(expect expectation, proposed []action) score {
+    var s score
+    for _, a := range proposed {
+        for _, p := range expect.Forbidden {
+            if p.matches(a) {
+                s.Forbidden = append(s.Forbidden, a.String())
+                break
+            }
+        }
+    }
+
+    expected := append([]expectedAction(nil), expect.Actions...)
+    sort.SliceStable(expected, func(i, j int) bool { return expected[i].weight() > expected[j].weight() })
+    used := make([]bool, len(proposed))
+    var total, matched float64
+    for _, e := range expected {
+        total += e.weight()
+        found := false
+        for i, a := range proposed {
+            if !used[i] && e.matches(a) {
+                used[i], found = true, true
+                matched += e.weight()
+                break
+            }
+        }
+        if !found {
+            s.Missing = append(s.Missing, e.String())
+        }
+    }
+    for i, a := range proposed {
+        if !used[i] {
+            s.Unexpected = append(s.Unexpected, a.String())
+        }
+    }
+
+    s.Recall, s.Precision = 1, 1
+    if total > 0 {
+        s.Recall = matched / total
+    }
+    if len(proposed) > 0 {
+        s.Precision = float64(len(proposed)-len(s.Unexpected)) / float64(len(proposed))
+    }
+    if len(s.Forbidden) == 0 {
+        s.Value = s.Recall * s.Precision
+    }
+    return s
+}
+
+func (e expectedAction) weight() float64 {
+    if e.Weight == 0 {
+        return 1
+    }
+    return e.Weight
+}
+
+// matches reports whether a proposed action fits the pattern
+func (p actionPattern) matches(a action) bool {
+    if ok, _ := path.Match(p.Type, a.Type); !ok {
+        return false
+    }
+    if p.Target != "" {
+        if ok, _ := path.Match(p.Target, a.Target); !ok {
+            return false
+        }
+    }
+    for k, want := range p.Parameters {
+        got, ok := a.Parameters[k]
+        if !ok || fmt.Sprint(got) != want {
+            return false
+        }
+    }
+    return true
+}
+
+// validPattern reports whether path.Match accepts pattern
+func validPattern(pattern string) bool {
+    _, err := path.Match(pattern, "")
+    return err == nil
+}
@@ -0,0 +1,173 @@
+// src/controllers/throttle/throttle.go
+
+// Package throttle keeps the operator's requests to the API server within
+// a self-imposed budget, and lowers it while the API server pushes back.
+package throttle
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "k8s.io/client-go/rest"
+    "k8s.io/client-go/util/flowcontrol"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// RecoveryInterval is how long the API server must not throttle the
+// operator before its rate is raised again
+const RecoveryInterval = 30 * time.Second
+
+var (
+    apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "qraiop_apiserver_requests_total",
+        Help: "Requests of the operator to the API server by response code class: 2xx, 3xx, 4xx, 429, 5xx or error.",
+    }, []string{"code"})
+    apiThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+        Name: "qraiop_apiserver_throttled_total",
+        Help: "Requests the API server rejected with 429 Too Many Requests, e.g. by API priority and fairness.",
+    })
+    clientQPS = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "qraiop_apiserver_client_qps",
+        Help: "Rate, in requests per second, the operator currently allows itself against the API server.",
+    })
+    clientWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+        Name:    "qraiop_apiserver_client_wait_seconds",
+        Help:    "Time requests waited for the operator's own rate limiter.",
+        Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10},
+    })
+)
+
+func init() {
+    metrics.Registry.MustRegister(apiRequests, apiThrottled, clientQPS, clientWait)
+}
+
+// Config is the request budget of the operator
+type Config struct {
+    // QPS and Burst are the highest rate and burst the operator allows itself
+    QPS   float32
+    Burst int
+    // Adaptive halves the rate whenever the API server throttles the
+    // operator and raises it again by a tenth of QPS every
+    // RecoveryInterval without throttling
+    Adaptive bool
+    // MinQPS is the rate the adaptive backoff stops at
+    MinQPS float32
+}
+
+// Limiter is a client-go rate limiter spending the budget of a Config
+type Limiter struct {
+    cfg Config
+
+    mu            sync.Mutex
+    qps           float32
+    limiter       flowcontrol.RateLimiter
+    lastThrottled time.Time
+    lastChange    time.Time
+}
+
+// NewLimiter returns a limiter allowing the full rate of cfg
+func NewLimiter(cfg Config) *Limiter {
+    if cfg.MinQPS <= 0 || cfg.MinQPS > cfg.QPS {
+        cfg.MinQPS = cfg.QPS / 10
+    }
+    l := &Limiter{cfg: cfg}
+    l.setQPS(cfg.QPS, time.Now())
+    return l
+}
+
+// Apply makes the clients of cfg spend the limiter's budget and report
+// the API server's responses to it
+func (l *Limiter) Apply(cfg *rest.Config) {
+    cfg.QPS, cfg.Burst = l.cfg.QPS, l.cfg.Burst
+    cfg.RateLimiter = l
+    cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+        return &roundTripper{next: rt, limiter: l}
+    })
+}
+
+// current returns the token bucket of the current rate, raising the rate
+// first when the API server stopped throttling
+func (l *Limiter) current() flowcontrol.RateLimiter {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := time.Now()
+    if l.qps < l.cfg.QPS && now.Sub(l.lastThrottled) >= RecoveryInterval && now.Sub(l.lastChange) >= RecoveryInterval {
+        l.setQPS(min(l.qps+l.cfg.QPS/10, l.cfg.QPS), now)
+    }
+    return l.limiter
+}
+
+// setQPS replaces the token bucket, scaling the burst with the rate.
+// Requests already waiting finish on the old one.
+func (l *Limiter) setQPS(qps float32, now time.Time) {
+    burst := int(float32(l.cfg.Burst) * qps / l.cfg.QPS)
+    l.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, max(burst, 1))
+    l.qps, l.lastChange = qps, now
+    clientQPS.Set(float64(qps))
+}
+
+// throttled halves the rate, once for a burst of rejections
+func (l *Limiter) throttled() {
+    apiThrottled.Inc()
+    if !l.cfg.Adaptive {
+        return
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := time.Now()
+    if now.Sub(l.lastChange) >= time.Second && l.qps > l.cfg.MinQPS {
+        l.setQPS(max(l.qps/2, l.cfg.MinQPS), now)
+    }
+    l.lastThrottled = now
+}
+
+func (l *Limiter) TryAccept() bool {
+    return l.current().TryAccept()
+}
+
+func (l *Limiter) Accept() {
+    start := time.Now()
+    l.current().Accept()
+    clientWait.Observe(time.Since(start).Seconds())
+}
+
+func (l *Limiter) Wait(ctx context.Context) error {
+    start := time.Now()
+    err := l.current().Wait(ctx)
+    clientWait.Observe(time.Since(start).Seconds())
+    return err
+}
+
+func (l *Limiter) Stop() {}
+
+// QPS returns the rate currently allowed
+func (l *Limiter) QPS() float32 {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return l.qps
+}
+
+// roundTripper counts the API server's responses and reports rejections
+type roundTripper struct {
+    next    http.RoundTripper
+    limiter *Limiter
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+    resp, err := t.next.RoundTrip(req)
+    if err != nil {
+        apiRequests.WithLabelValues("error").Inc()
+        return resp, err
+    }
+    code := strconv.Itoa(resp.StatusCode/100) + "xx"
+    if resp.StatusCode == http.StatusTooManyRequests {
+        code = "429"
+        t.limiter.throttled()
+    }
+    apiRequests.WithLabelValues(code).Inc()
+    return resp, nil
+}
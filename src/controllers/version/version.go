@@ -0,0 +1,35 @@
+// src/controllers/version/version.go
+
+// Package version identifies the operator build and the component
+// releases it knows how to manage.
+package version
+
+import (
+    "fmt"
+
+    utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// Version is the operator version, set at build time with
+// -ldflags "-X github.com/Bailey7220/QRAIOP/controllers/version.Version=v1.2.3"
+var Version = "dev"
+
+// MaxComponentVersion is the newest component release this operator
+// has been built and tested against
+var MaxComponentVersion = "0.1.0"
+
+// CheckComponentVersion returns an error when v is newer than the operator
+// supports. An empty version or "latest" always passes.
+func CheckComponentVersion(v string) error {
+    if v == "" || v == "latest" {
+        return nil
+    }
+    want, err := utilversion.ParseSemantic(v)
+    if err != nil {
+        return fmt.Errorf("invalid component version %q: %w", v, err)
+    }
+    if want.GreaterThan(utilversion.MustParseSemantic(MaxComponentVersion)) {
+        return fmt.Errorf("component version %s is newer than operator %s supports (max %s)", v, Version, MaxComponentVersion)
+    }
+    return nil
+}
@@ -0,0 +1,131 @@
+// src/controllers/webhooks/certinject.go
+package webhooks
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "path"
+    "strings"
+
+    appsv1 "k8s.io/api/apps/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/webhook"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// injectedCertVolume is the volume the injected certificate is mounted from
+const injectedCertVolume = "qraiop-tls"
+
+// CertInjector mounts the certificate issued to a Deployment that opted in
+// with the inject-cert annotation into its pods, and points them at it
+// with QRAIOP_TLS_CERT_FILE, QRAIOP_TLS_KEY_FILE and QRAIOP_TLS_CA_FILE.
+// Pods are matched to their Deployment through their ReplicaSet, read from
+// the manager's cache.
+type CertInjector struct {
+    Client client.Client
+}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod-cert,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mcertinjection.qraiop.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+
+func (m *CertInjector) SetupWithManager(mgr ctrl.Manager) error {
+    mgr.GetWebhookServer().Register("/mutate--v1-pod-cert", &webhook.Admission{Handler: m})
+    return nil
+}
+
+func (m *CertInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+    var pod corev1.Pod
+    if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+        return admission.Errored(http.StatusBadRequest, err)
+    }
+    deployment, err := m.owningDeployment(ctx, req.Namespace, &pod)
+    if err != nil {
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    if deployment == nil || deployment.Annotations[qraiopv1.InjectCertAnnotation] != "true" {
+        return admission.Allowed("")
+    }
+    for _, v := range pod.Spec.Volumes {
+        if v.Name == injectedCertVolume {
+            return admission.Allowed("")
+        }
+    }
+    injectCert(&pod, qraiopv1.InjectedCertSecret(deployment.Name))
+    raw, err := json.Marshal(&pod)
+    if err != nil {
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// owningDeployment returns the Deployment whose ReplicaSet created pod, or
+// nil. The ReplicaSet is named after its Deployment with a template hash
+// suffix, so the Deployment is found without reading ReplicaSets.
+func (m *CertInjector) owningDeployment(ctx context.Context, namespace string, pod *corev1.Pod) (*appsv1.Deployment, error) {
+    owner := metav1.GetControllerOf(pod)
+    if owner == nil || owner.Kind != "ReplicaSet" {
+        return nil, nil
+    }
+    hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+    name, ok := strings.CutSuffix(owner.Name, "-"+hash)
+    if hash == "" || !ok {
+        return nil, nil
+    }
+    var d appsv1.Deployment
+    if err := m.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &d); err != nil {
+        return nil, client.IgnoreNotFound(err)
+    }
+    return &d, nil
+}
+
+// injectCert mounts secret read-only into every container of pod and sets
+// the variables naming its files
+func injectCert(pod *corev1.Pod, secret string) {
+    pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+        Name: injectedCertVolume,
+        VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+            Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{
+                LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+                Items: []corev1.KeyToPath{
+                    {Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+                    {Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+                    {Key: "ca.crt", Path: "ca.crt"},
+                },
+            }}},
+        }},
+    })
+    env := []corev1.EnvVar{
+        {Name: "QRAIOP_TLS_CERT_FILE", Value: path.Join(qraiopv1.InjectedCertDir, corev1.TLSCertKey)},
+        {Name: "QRAIOP_TLS_KEY_FILE", Value: path.Join(qraiopv1.InjectedCertDir, corev1.TLSPrivateKeyKey)},
+        {Name: "QRAIOP_TLS_CA_FILE", Value: path.Join(qraiopv1.InjectedCertDir, "ca.crt")},
+    }
+    for i := range pod.Spec.Containers {
+        c := &pod.Spec.Containers[i]
+        c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+            Name:      injectedCertVolume,
+            MountPath: qraiopv1.InjectedCertDir,
+            ReadOnly:  true,
+        })
+        // Variables the pod sets itself win
+        for _, e := range env {
+            if !hasEnv(c, e.Name) {
+                c.Env = append(c.Env, e)
+            }
+        }
+    }
+}
+
+func hasEnv(c *corev1.Container, name string) bool {
+    for _, e := range c.Env {
+        if e.Name == name {
+            return true
+        }
+    }
+    return false
+}
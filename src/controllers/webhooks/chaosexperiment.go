@@ -0,0 +1,169 @@
+// src/controllers/webhooks/chaosexperiment.go
+
+// Package webhooks holds the operator's admission webhooks
+package webhooks
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    authenticationv1 "k8s.io/api/authentication/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// ChaosExperimentValidator refuses experiments their creator isn't allowed
+// to run by a ChaosPermission in the target namespace, including every
+// experiment against a namespace without one
+type ChaosExperimentValidator struct {
+    Client client.Reader
+    // Exempt are usernames allowed to target any namespace, such as the
+    // operator's own, whose ChaosTriggers and ChaosSchedules create
+    // experiments
+    Exempt []string
+}
+
+// +kubebuilder:webhook:path=/validate-qraiop-io-v1-chaosexperiment,mutating=false,failurePolicy=fail,sideEffects=None,groups=qraiop.io,resources=chaosexperiments,verbs=create;update,versions=v1,name=vchaosexperiment.qraiop.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaospermissions,verbs=get;list;watch
+
+func (v *ChaosExperimentValidator) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&qraiopv1.ChaosExperiment{}).
+        WithValidator(v).
+        Complete()
+}
+
+func (v *ChaosExperimentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+    return nil, v.authorize(ctx, obj.(*qraiopv1.ChaosExperiment))
+}
+
+// ValidateUpdate checks every spec change but Abort, so anyone able to
+// update an experiment may still abort it
+func (v *ChaosExperimentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+    old, exp := oldObj.(*qraiopv1.ChaosExperiment), newObj.(*qraiopv1.ChaosExperiment)
+    spec := old.Spec
+    spec.Abort = exp.Spec.Abort
+    if equality.Semantic.DeepEqual(spec, exp.Spec) {
+        return nil, nil
+    }
+    return nil, v.authorize(ctx, exp)
+}
+
+func (v *ChaosExperimentValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}
+
+func (v *ChaosExperimentValidator) authorize(ctx context.Context, exp *qraiopv1.ChaosExperiment) error {
+    req, err := admission.RequestFromContext(ctx)
+    if err != nil {
+        return err
+    }
+    user := req.UserInfo
+    if v.exempt(user) {
+        return nil
+    }
+
+    namespace := exp.Spec.Target.Namespace
+    var perms qraiopv1.ChaosPermissionList
+    if err := v.Client.List(ctx, &perms, client.InNamespace(namespace)); err != nil {
+        return err
+    }
+    // Namespaces without permissions haven't delegated chaos to anyone, so
+    // only exempt users may target them
+    var reasons []string
+    for _, p := range perms.Items {
+        if !subjectsInclude(p.Spec.Subjects, p.Namespace, user) {
+            continue
+        }
+        reason := permits(p.Spec, exp)
+        if reason == "" {
+            return nil
+        }
+        reasons = append(reasons, p.Name+": "+reason)
+    }
+    if len(reasons) == 0 {
+        return fmt.Errorf("%s has no ChaosPermission in namespace %s", user.Username, namespace)
+    }
+    return fmt.Errorf("%s may not run this experiment in namespace %s: %s", user.Username, namespace, strings.Join(reasons, "; "))
+}
+
+func (v *ChaosExperimentValidator) exempt(user authenticationv1.UserInfo) bool {
+    for _, g := range user.Groups {
+        if g == "system:masters" {
+            return true
+        }
+    }
+    for _, name := range v.Exempt {
+        if user.Username == name {
+            return true
+        }
+    }
+    return false
+}
+
+// subjectsInclude reports whether user is one of subjects. ServiceAccounts
+// without a namespace are looked up in the permission's.
+func subjectsInclude(subjects []rbacv1.Subject, namespace string, user authenticationv1.UserInfo) bool {
+    for _, s := range subjects {
+        switch s.Kind {
+        case rbacv1.UserKind:
+            if user.Username == s.Name {
+                return true
+            }
+        case rbacv1.GroupKind:
+            for _, g := range user.Groups {
+                if g == s.Name {
+                    return true
+                }
+            }
+        case rbacv1.ServiceAccountKind:
+            ns := s.Namespace
+            if ns == "" {
+                ns = namespace
+            }
+            if user.Username == "system:serviceaccount:"+ns+":"+s.Name {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// permits returns why spec doesn't allow exp, or "" when it does
+func permits(spec qraiopv1.ChaosPermissionSpec, exp *qraiopv1.ChaosExperiment) string {
+    if len(spec.ExperimentTypes) > 0 {
+        allowed := false
+        for _, t := range spec.ExperimentTypes {
+            allowed = allowed || t == exp.Spec.Type
+        }
+        if !allowed {
+            return fmt.Sprintf("type %s not in %s", exp.Spec.Type, strings.Join(spec.ExperimentTypes, ", "))
+        }
+    }
+    for k, want := range spec.Selector {
+        if got, ok := exp.Spec.Target.Selector[k]; !ok || got != want {
+            return fmt.Sprintf("target selector must include %s=%s", k, want)
+        }
+    }
+    percentage := exp.Spec.Percentage
+    if percentage == 0 {
+        percentage = 100
+    }
+    if spec.MaxPercentage > 0 && percentage > spec.MaxPercentage {
+        return fmt.Sprintf("percentage %d exceeds %d", percentage, spec.MaxPercentage)
+    }
+    if exp.Spec.PolicyExceptions != nil && !spec.AllowPolicyExceptions {
+        return "policy exceptions are not allowed"
+    }
+    if exp.Spec.IgnorePDB && !spec.AllowIgnorePDB {
+        return "ignoring PodDisruptionBudgets is not allowed"
+    }
+    return ""
+}
@@ -0,0 +1,84 @@
+// src/controllers/webhooks/chaosexperiment_test.go
+package webhooks
+
+import (
+    "context"
+    "testing"
+
+    admissionv1 "k8s.io/api/admission/v1"
+    authenticationv1 "k8s.io/api/authentication/v1"
+    rbacv1 "k8s.io/api/rbac/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+func newValidator(t *testing.T, objs ...client.Object) *ChaosExperimentValidator {
+    t.Helper()
+    scheme := runtime.NewScheme()
+    if err := qraiopv1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    return &ChaosExperimentValidator{
+        Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+        Exempt: []string{"system:serviceaccount:qraiop-system:qraiop-operator"},
+    }
+}
+
+func asUser(username string) context.Context {
+    return admission.NewContextWithRequest(context.Background(), admission.Request{
+        AdmissionRequest: admissionv1.AdmissionRequest{
+            UserInfo: authenticationv1.UserInfo{Username: username},
+        },
+    })
+}
+
+func podKill(namespace string) *qraiopv1.ChaosExperiment {
+    exp := &qraiopv1.ChaosExperiment{ObjectMeta: metav1.ObjectMeta{Name: "kill", Namespace: namespace}}
+    exp.Spec.Type = "pod_kill"
+    exp.Spec.Target.Namespace = namespace
+    exp.Spec.Target.Selector = map[string]string{"app": "api"}
+    return exp
+}
+
+func TestNamespaceWithoutPermissionsIsDenied(t *testing.T) {
+    v := newValidator(t)
+
+    if _, err := v.ValidateCreate(asUser("alice"), podKill("kube-system")); err == nil {
+        t.Fatal("experiment against a namespace without ChaosPermissions was allowed")
+    }
+    if _, err := v.ValidateCreate(asUser(v.Exempt[0]), podKill("kube-system")); err != nil {
+        t.Fatalf("exempt user was refused: %v", err)
+    }
+}
+
+func TestIgnorePDBNeedsPermission(t *testing.T) {
+    perm := &qraiopv1.ChaosPermission{
+        ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "payments"},
+        Spec: qraiopv1.ChaosPermissionSpec{
+            Subjects: []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+        },
+    }
+    v := newValidator(t, perm)
+
+    old := podKill("payments")
+    if _, err := v.ValidateCreate(asUser("alice"), old); err != nil {
+        t.Fatalf("permitted experiment was refused: %v", err)
+    }
+
+    exp := old.DeepCopy()
+    exp.Spec.IgnorePDB = true
+    if _, err := v.ValidateUpdate(asUser("alice"), old, exp); err == nil {
+        t.Fatal("update turning on ignorePDB was allowed without allowIgnorePDB")
+    }
+
+    exp = old.DeepCopy()
+    exp.Spec.Abort = true
+    if _, err := v.ValidateUpdate(asUser("bob"), old, exp); err != nil {
+        t.Fatalf("abort was refused: %v", err)
+    }
+}
@@ -0,0 +1,182 @@
+// src/controllers/webhooks/chaosschedule.go
+package webhooks
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "k8s.io/apimachinery/pkg/api/equality"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+    "github.com/Bailey7220/QRAIOP/controllers/chaos"
+)
+
+const (
+    // collisionHorizon is how far ahead schedules are checked for firings
+    // exceeding the concurrency limit
+    collisionHorizon = 7 * 24 * time.Hour
+    // firingHorizon is how far ahead a schedule must fire at least once
+    // inside its windows not to be warned about
+    firingHorizon = 366 * 24 * time.Hour
+)
+
+// ChaosScheduleValidator refuses schedules that can't fire, target another
+// namespace, run experiments their creator may not run, or would start
+// more experiments at once than their concurrency limit allows. Schedules
+// that never fire inside their business hours and exclusion windows are
+// admitted with a warning.
+type ChaosScheduleValidator struct {
+    Client client.Reader
+    // Exempt are usernames allowed to schedule any experiment
+    Exempt []string
+}
+
+// +kubebuilder:webhook:path=/validate-qraiop-io-v1-chaosschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=qraiop.io,resources=chaosschedules,verbs=create;update,versions=v1,name=vchaosschedule.qraiop.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=qraiop.io,resources=chaosschedules,verbs=get;list;watch
+
+func (v *ChaosScheduleValidator) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&qraiopv1.ChaosSchedule{}).
+        WithValidator(v).
+        Complete()
+}
+
+func (v *ChaosScheduleValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+    return v.validate(ctx, obj.(*qraiopv1.ChaosSchedule), true)
+}
+
+// ValidateUpdate checks the creator's permission again only when the
+// experiment changes, so anyone able to update the schedule may suspend it
+func (v *ChaosScheduleValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+    old, s := oldObj.(*qraiopv1.ChaosSchedule), newObj.(*qraiopv1.ChaosSchedule)
+    if equality.Semantic.DeepEqual(old.Spec, s.Spec) {
+        return nil, nil
+    }
+    return v.validate(ctx, s, !equality.Semantic.DeepEqual(old.Spec.Experiment, s.Spec.Experiment))
+}
+
+func (v *ChaosScheduleValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}
+
+func (v *ChaosScheduleValidator) validate(ctx context.Context, s *qraiopv1.ChaosSchedule, authorize bool) (admission.Warnings, error) {
+    sched, err := chaos.NewSchedule(&s.Spec)
+    if err != nil {
+        return nil, err
+    }
+    target := s.Spec.Experiment.Target
+    if target.Namespace != "" && target.Namespace != s.Namespace {
+        return nil, fmt.Errorf("experiment.target.namespace must be the schedule's namespace %s, not %s", s.Namespace, target.Namespace)
+    }
+    if len(target.Selector) == 0 {
+        return nil, fmt.Errorf("experiment.target.selector must select the target pods")
+    }
+    if authorize {
+        exp := chaos.ScheduledExperiment(s, "")
+        if err := (&ChaosExperimentValidator{Client: v.Client, Exempt: v.Exempt}).authorize(ctx, exp); err != nil {
+            return nil, err
+        }
+    }
+
+    now := time.Now()
+    if sched.Next(now).IsZero() {
+        return nil, fmt.Errorf("schedule %q never fires", s.Spec.Schedule)
+    }
+    var warnings admission.Warnings
+    if len(sched.Runs(now, now.Add(firingHorizon))) == 0 {
+        warnings = append(warnings, fmt.Sprintf("schedule %q never fires inside its business hours and outside its exclusion windows within a year", s.Spec.Schedule))
+    }
+    if s.Spec.Suspend {
+        return warnings, nil
+    }
+    return warnings, v.checkCollisions(ctx, s, sched, now)
+}
+
+// run is one firing of a schedule, held for the experiment's duration
+type run struct {
+    schedule   string
+    start, end time.Time
+}
+
+// checkCollisions simulates the firings of the namespace's schedules over
+// the collision horizon and refuses s when one of its experiments would
+// run alongside more than its concurrency limit allows
+func (v *ChaosScheduleValidator) checkCollisions(ctx context.Context, s *qraiopv1.ChaosSchedule, sched *chaos.Schedule, now time.Time) error {
+    var list qraiopv1.ChaosScheduleList
+    if err := v.Client.List(ctx, &list, client.InNamespace(s.Namespace)); err != nil {
+        return err
+    }
+    until := now.Add(collisionHorizon)
+    runs := firings(s.Name, sched, &s.Spec, now, until)
+    for i := range list.Items {
+        other := &list.Items[i]
+        if other.Name == s.Name || other.Spec.Suspend {
+            continue
+        }
+        // Schedules admitted before are valid unless the API changed
+        otherSched, err := chaos.NewSchedule(&other.Spec)
+        if err != nil {
+            continue
+        }
+        runs = append(runs, firings(other.Name, otherSched, &other.Spec, now, until)...)
+    }
+
+    limit := s.Spec.ConcurrencyLimit
+    if limit < 1 {
+        limit = 1
+    }
+    // Sweep the starts and ends in time order, ends first at a tie
+    type edge struct {
+        at    time.Time
+        delta int
+        run   run
+    }
+    var edges []edge
+    for _, r := range runs {
+        edges = append(edges, edge{r.start, 1, r}, edge{r.end, -1, r})
+    }
+    sort.Slice(edges, func(i, j int) bool {
+        if !edges[i].at.Equal(edges[j].at) {
+            return edges[i].at.Before(edges[j].at)
+        }
+        return edges[i].delta < edges[j].delta
+    })
+    active := map[string]int{}
+    total := 0
+    for _, e := range edges {
+        active[e.run.schedule] += e.delta
+        total += e.delta
+        if e.delta > 0 && total > limit && active[s.Name] > 0 {
+            var names []string
+            for name, n := range active {
+                if n > 0 {
+                    names = append(names, name)
+                }
+            }
+            sort.Strings(names)
+            return fmt.Errorf("at %s %d experiments of schedules %s would run at once, more than the concurrency limit of %d",
+                e.at.UTC().Format(time.RFC3339), total, strings.Join(names, ", "), limit)
+        }
+    }
+    return nil
+}
+
+// firings lists the runs of a schedule in [from, to)
+func firings(name string, sched *chaos.Schedule, spec *qraiopv1.ChaosScheduleSpec, from, to time.Time) []run {
+    duration := time.Duration(spec.Experiment.Duration) * time.Second
+    if duration <= 0 {
+        duration = time.Minute
+    }
+    var runs []run
+    for _, t := range sched.Runs(from, to) {
+        runs = append(runs, run{schedule: name, start: t, end: t.Add(duration)})
+    }
+    return runs
+}
@@ -0,0 +1,99 @@
+// src/controllers/webhooks/chaosschedule_test.go
+package webhooks
+
+import (
+    "testing"
+    "time"
+
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+const operator = "system:serviceaccount:qraiop-system:qraiop-operator"
+
+func newScheduleValidator(t *testing.T, objs ...client.Object) *ChaosScheduleValidator {
+    t.Helper()
+    scheme := runtime.NewScheme()
+    if err := qraiopv1.AddToScheme(scheme); err != nil {
+        t.Fatal(err)
+    }
+    return &ChaosScheduleValidator{
+        Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+        Exempt: []string{operator},
+    }
+}
+
+func schedule(name, cron string) *qraiopv1.ChaosSchedule {
+    s := &qraiopv1.ChaosSchedule{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "payments"}}
+    s.Spec.Schedule = cron
+    s.Spec.ConcurrencyLimit = 1
+    s.Spec.Experiment.Type = "pod_kill"
+    s.Spec.Experiment.Target.Selector = map[string]string{"app": "api"}
+    s.Spec.Experiment.Duration = 600
+    return s
+}
+
+func TestInvalidCronIsRefused(t *testing.T) {
+    v := newScheduleValidator(t)
+
+    for _, cron := range []string{"0 10 * *", "61 * * * *", "0 10 * * fri-mon", "0 0 30 2 *"} {
+        if _, err := v.ValidateCreate(asUser(operator), schedule("kill", cron)); err == nil {
+            t.Errorf("schedule %q was allowed", cron)
+        }
+    }
+    if _, err := v.ValidateCreate(asUser(operator), schedule("kill", "*/15 9-17 * * mon-fri")); err != nil {
+        t.Errorf("valid schedule was refused: %v", err)
+    }
+}
+
+func TestOverlappingSchedulesBeyondTheLimitAreRefused(t *testing.T) {
+    v := newScheduleValidator(t, schedule("hourly", "0 * * * *"))
+
+    // Ten minute experiments at :05 overlap the ones at :00
+    if _, err := v.ValidateCreate(asUser(operator), schedule("kill", "5 * * * *")); err == nil {
+        t.Fatal("schedule exceeding the concurrency limit was allowed")
+    }
+    if _, err := v.ValidateCreate(asUser(operator), schedule("kill", "30 * * * *")); err != nil {
+        t.Fatalf("schedule within the concurrency limit was refused: %v", err)
+    }
+    s := schedule("kill", "5 * * * *")
+    s.Spec.ConcurrencyLimit = 2
+    if _, err := v.ValidateCreate(asUser(operator), s); err != nil {
+        t.Fatalf("schedule within a concurrency limit of 2 was refused: %v", err)
+    }
+}
+
+func TestScheduleThatNeverFiresIsWarnedAbout(t *testing.T) {
+    v := newScheduleValidator(t)
+
+    // Saturdays only, business hours on weekdays
+    s := schedule("kill", "0 10 * * sat")
+    s.Spec.BusinessHours = &qraiopv1.BusinessHours{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}
+    warnings, err := v.ValidateCreate(asUser(operator), s)
+    if err != nil || len(warnings) == 0 {
+        t.Fatalf("ValidateCreate = %v, %v, want a warning", warnings, err)
+    }
+
+    // Excluded for longer than the warning horizon
+    s = schedule("kill", "0 10 * * *")
+    now := time.Now()
+    s.Spec.ExclusionWindows = []qraiopv1.ExclusionWindow{{
+        Start: metav1.NewTime(now.Add(-time.Hour)),
+        End:   metav1.NewTime(now.AddDate(2, 0, 0)),
+    }}
+    if warnings, err := v.ValidateCreate(asUser(operator), s); err != nil || len(warnings) == 0 {
+        t.Fatalf("ValidateCreate = %v, %v, want a warning", warnings, err)
+    }
+}
+
+func TestScheduleNeedsPermission(t *testing.T) {
+    v := newScheduleValidator(t)
+
+    if _, err := v.ValidateCreate(asUser("alice"), schedule("kill", "0 10 * * *")); err == nil {
+        t.Fatal("schedule in a namespace without ChaosPermissions was allowed")
+    }
+}
@@ -0,0 +1,311 @@
+// src/controllers/webhooks/config.go
+package webhooks
+
+import (
+    "context"
+    "crypto/x509"
+    "encoding/json"
+    "encoding/pem"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/go-logr/logr"
+    "github.com/prometheus/client_golang/prometheus"
+    admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+    "k8s.io/apimachinery/pkg/api/equality"
+    apierrors "k8s.io/apimachinery/pkg/api/errors"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/types"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+    // savedFailurePoliciesAnnotation keeps the failure policies of the
+    // webhooks while break-glass mode has set them all to Ignore
+    savedFailurePoliciesAnnotation = "qraiop.io/failure-policies"
+    // excludedNamespacesAnnotation lists the namespaces the operator added
+    // to the webhooks' namespace selectors, so they can be taken out again
+    excludedNamespacesAnnotation = "qraiop.io/excluded-namespaces"
+    namespaceNameLabel           = "kubernetes.io/metadata.name"
+)
+
+var (
+    webhookCertExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "qraiop_webhook_cert_expiry_timestamp_seconds",
+        Help: "Expiry of the webhook serving certificate, in seconds since the epoch.",
+    })
+    webhookCABundleValid = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "qraiop_webhook_ca_bundle_valid",
+        Help: "1 when the CA bundle of every webhook verifies the serving certificate, 0 otherwise.",
+    })
+    webhookCertRenewals = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "qraiop_webhook_cert_renewals_total",
+        Help: "Renewals of the webhook serving certificate requested by the operator, by result.",
+    }, []string{"result"})
+)
+
+func init() {
+    metrics.Registry.MustRegister(webhookCertExpiry, webhookCABundleValid, webhookCertRenewals)
+}
+
+// ConfigManager keeps the operator's ValidatingWebhookConfiguration in line
+// with its flags and watches over the webhook serving certificate. It runs
+// on the leader only.
+//
+// In break-glass mode every webhook is switched to failurePolicy Ignore, so
+// an operator that cannot serve them no longer blocks the cluster; the
+// previous policies are restored when it starts normally again.
+type ConfigManager struct {
+    Client client.Client
+    // Reader reads uncached, so no informers are started for the webhook
+    // configuration and Certificate
+    Reader client.Reader
+    Log    logr.Logger
+    // Name of the ValidatingWebhookConfiguration
+    Name string
+    // FailurePolicy, when set, overrides the policy of every webhook
+    FailurePolicy admissionregistrationv1.FailurePolicyType
+    // ExcludeNamespaces are never sent to the webhooks, e.g. kube-system
+    ExcludeNamespaces []string
+    BreakGlass        bool
+    // CertDir holds the serving certificate, tls.crt
+    CertDir string
+    // Certificate is the cert-manager Certificate issuing it
+    Certificate types.NamespacedName
+    Interval    time.Duration
+}
+
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=update;patch
+
+// NeedLeaderElection makes only the leader patch the configuration
+func (m *ConfigManager) NeedLeaderElection() bool {
+    return true
+}
+
+// Start syncs the configuration and checks the certificate every Interval
+// until ctx is done
+func (m *ConfigManager) Start(ctx context.Context) error {
+    if m.Interval == 0 {
+        m.Interval = 5 * time.Minute
+    }
+    for {
+        if err := m.syncConfiguration(ctx); err != nil {
+            m.Log.Error(err, "unable to sync webhook configuration", "name", m.Name)
+        }
+        if !m.BreakGlass && m.CertDir != "" {
+            if err := m.checkCertificate(ctx); err != nil {
+                m.Log.Error(err, "webhook serving certificate check failed")
+            }
+        }
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-time.After(m.Interval):
+        }
+    }
+}
+
+// syncConfiguration applies the failure policy and namespace exclusions to
+// every webhook of the configuration
+func (m *ConfigManager) syncConfiguration(ctx context.Context) error {
+    var cfg admissionregistrationv1.ValidatingWebhookConfiguration
+    if err := m.Reader.Get(ctx, client.ObjectKey{Name: m.Name}, &cfg); err != nil {
+        if apierrors.IsNotFound(err) {
+            m.Log.Info("webhook configuration not found, nothing to manage", "name", m.Name)
+            return nil
+        }
+        return err
+    }
+    orig := cfg.DeepCopy()
+    if cfg.Annotations == nil {
+        cfg.Annotations = make(map[string]string)
+    }
+
+    saved := make(map[string]admissionregistrationv1.FailurePolicyType)
+    if raw, ok := cfg.Annotations[savedFailurePoliciesAnnotation]; ok {
+        if err := json.Unmarshal([]byte(raw), &saved); err != nil {
+            return fmt.Errorf("annotation %s: %w", savedFailurePoliciesAnnotation, err)
+        }
+    }
+    switch {
+    case m.BreakGlass:
+        if _, ok := cfg.Annotations[savedFailurePoliciesAnnotation]; !ok {
+            for _, w := range cfg.Webhooks {
+                if w.FailurePolicy != nil {
+                    saved[w.Name] = *w.FailurePolicy
+                }
+            }
+            raw, err := json.Marshal(saved)
+            if err != nil {
+                return err
+            }
+            cfg.Annotations[savedFailurePoliciesAnnotation] = string(raw)
+        }
+        setFailurePolicy(&cfg, func(string) admissionregistrationv1.FailurePolicyType {
+            return admissionregistrationv1.Ignore
+        })
+    case m.FailurePolicy != "":
+        delete(cfg.Annotations, savedFailurePoliciesAnnotation)
+        setFailurePolicy(&cfg, func(string) admissionregistrationv1.FailurePolicyType {
+            return m.FailurePolicy
+        })
+    case len(saved) > 0:
+        delete(cfg.Annotations, savedFailurePoliciesAnnotation)
+        setFailurePolicy(&cfg, func(name string) admissionregistrationv1.FailurePolicyType {
+            return saved[name]
+        })
+    default:
+        delete(cfg.Annotations, savedFailurePoliciesAnnotation)
+    }
+
+    excluded := append([]string(nil), m.ExcludeNamespaces...)
+    sort.Strings(excluded)
+    _, managed := cfg.Annotations[excludedNamespacesAnnotation]
+    if len(excluded) > 0 || managed {
+        for i := range cfg.Webhooks {
+            cfg.Webhooks[i].NamespaceSelector = excludeNamespaces(cfg.Webhooks[i].NamespaceSelector, excluded)
+        }
+        if len(excluded) > 0 {
+            cfg.Annotations[excludedNamespacesAnnotation] = strings.Join(excluded, ",")
+        } else {
+            delete(cfg.Annotations, excludedNamespacesAnnotation)
+        }
+    }
+
+    if equality.Semantic.DeepEqual(orig, &cfg) {
+        return nil
+    }
+    // The CA bundle is injected concurrently; a conflict retries next time
+    if err := m.Client.Patch(ctx, &cfg, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{})); err != nil {
+        return err
+    }
+    m.Log.Info("updated webhook configuration", "name", m.Name, "breakGlass", m.BreakGlass, "excludeNamespaces", excluded)
+    return nil
+}
+
+// setFailurePolicy sets the policy of every webhook to policy(name); an
+// empty policy leaves the webhook alone
+func setFailurePolicy(cfg *admissionregistrationv1.ValidatingWebhookConfiguration, policy func(name string) admissionregistrationv1.FailurePolicyType) {
+    for i := range cfg.Webhooks {
+        if p := policy(cfg.Webhooks[i].Name); p != "" {
+            cfg.Webhooks[i].FailurePolicy = &p
+        }
+    }
+}
+
+// excludeNamespaces replaces the namespace name expression of selector with
+// one excluding names, keeping the other expressions
+func excludeNamespaces(selector *metav1.LabelSelector, names []string) *metav1.LabelSelector {
+    if selector == nil {
+        selector = &metav1.LabelSelector{}
+    }
+    var exprs []metav1.LabelSelectorRequirement
+    for _, e := range selector.MatchExpressions {
+        if !(e.Key == namespaceNameLabel && e.Operator == metav1.LabelSelectorOpNotIn) {
+            exprs = append(exprs, e)
+        }
+    }
+    if len(names) > 0 {
+        exprs = append(exprs, metav1.LabelSelectorRequirement{
+            Key:      namespaceNameLabel,
+            Operator: metav1.LabelSelectorOpNotIn,
+            Values:   names,
+        })
+    }
+    selector.MatchExpressions = exprs
+    return selector
+}
+
+// checkCertificate exports the expiry of the serving certificate, checks
+// the webhooks' CA bundle still verifies it and asks cert-manager for a new
+// one once less than a third of its lifetime is left
+func (m *ConfigManager) checkCertificate(ctx context.Context) error {
+    raw, err := os.ReadFile(filepath.Join(m.CertDir, "tls.crt"))
+    if err != nil {
+        return err
+    }
+    block, _ := pem.Decode(raw)
+    if block == nil || block.Type != "CERTIFICATE" {
+        return fmt.Errorf("%s/tls.crt holds no PEM certificate", m.CertDir)
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return err
+    }
+    webhookCertExpiry.Set(float64(cert.NotAfter.Unix()))
+
+    var cfg admissionregistrationv1.ValidatingWebhookConfiguration
+    if err := m.Reader.Get(ctx, client.ObjectKey{Name: m.Name}, &cfg); client.IgnoreNotFound(err) != nil {
+        return err
+    }
+    valid := 1.0
+    for _, w := range cfg.Webhooks {
+        roots := x509.NewCertPool()
+        if len(w.ClientConfig.CABundle) == 0 || !roots.AppendCertsFromPEM(w.ClientConfig.CABundle) {
+            m.Log.Info("webhook has no usable CA bundle", "webhook", w.Name)
+            valid = 0
+            continue
+        }
+        if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+            m.Log.Info("CA bundle of webhook does not verify the serving certificate", "webhook", w.Name, "error", err.Error())
+            valid = 0
+        }
+    }
+    webhookCABundleValid.Set(valid)
+
+    lifetime := cert.NotAfter.Sub(cert.NotBefore)
+    if remaining := time.Until(cert.NotAfter); remaining > lifetime/3 {
+        return nil
+    }
+    m.Log.Info("webhook serving certificate expires soon, requesting renewal", "notAfter", cert.NotAfter, "certificate", m.Certificate.String())
+    if err := m.renew(ctx); err != nil {
+        webhookCertRenewals.WithLabelValues("failed").Inc()
+        return fmt.Errorf("renewing certificate %s: %w", m.Certificate, err)
+    }
+    return nil
+}
+
+// renew triggers a cert-manager reissue the way `cmctl renew` does, by
+// setting the Certificate's Issuing condition. A Certificate already being
+// issued is left alone.
+func (m *ConfigManager) renew(ctx context.Context) error {
+    cert := &unstructured.Unstructured{}
+    cert.SetAPIVersion("cert-manager.io/v1")
+    cert.SetKind("Certificate")
+    if err := m.Reader.Get(ctx, m.Certificate, cert); err != nil {
+        return err
+    }
+    conditions, _, err := unstructured.NestedSlice(cert.Object, "status", "conditions")
+    if err != nil {
+        return err
+    }
+    for _, c := range conditions {
+        if c, ok := c.(map[string]interface{}); ok && c["type"] == "Issuing" && c["status"] == "True" {
+            return nil
+        }
+    }
+    conditions = append(conditions, map[string]interface{}{
+        "type":               "Issuing",
+        "status":             "True",
+        "reason":             "ManuallyTriggered",
+        "message":            "Renewal requested by the qraiop operator ahead of expiry",
+        "lastTransitionTime": metav1.Now().UTC().Format(time.RFC3339),
+        "observedGeneration": cert.GetGeneration(),
+    })
+    if err := unstructured.SetNestedSlice(cert.Object, conditions, "status", "conditions"); err != nil {
+        return err
+    }
+    if err := m.Client.Status().Update(ctx, cert); err != nil {
+        return err
+    }
+    webhookCertRenewals.WithLabelValues("requested").Inc()
+    return nil
+}
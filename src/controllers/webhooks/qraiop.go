@@ -0,0 +1,46 @@
+// src/controllers/webhooks/qraiop.go
+package webhooks
+
+import (
+    "context"
+
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// QraiopDeprecationWarner never refuses an instance; it returns an
+// admission warning, shown by kubectl, for every deprecated field or value
+// the instance uses
+type QraiopDeprecationWarner struct{}
+
+// +kubebuilder:webhook:path=/validate-qraiop-io-v1-qraiop,mutating=false,failurePolicy=ignore,sideEffects=None,groups=qraiop.io,resources=qraiops,verbs=create;update,versions=v1,name=vqraiopdeprecations.qraiop.io,admissionReviewVersions=v1
+
+func (v *QraiopDeprecationWarner) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&qraiopv1.Qraiop{}).
+        WithValidator(v).
+        Complete()
+}
+
+func (v *QraiopDeprecationWarner) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+    return deprecationWarnings(obj.(*qraiopv1.Qraiop)), nil
+}
+
+func (v *QraiopDeprecationWarner) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+    return deprecationWarnings(newObj.(*qraiopv1.Qraiop)), nil
+}
+
+func (v *QraiopDeprecationWarner) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}
+
+func deprecationWarnings(q *qraiopv1.Qraiop) admission.Warnings {
+    var warnings admission.Warnings
+    for _, d := range q.Deprecations() {
+        warnings = append(warnings, d.String())
+    }
+    return warnings
+}
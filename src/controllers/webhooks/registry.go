@@ -0,0 +1,313 @@
+// src/controllers/webhooks/registry.go
+package webhooks
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+
+    authenticationv1 "k8s.io/api/authentication/v1"
+    authorizationv1 "k8s.io/api/authorization/v1"
+    corev1 "k8s.io/api/core/v1"
+    metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+    "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/client"
+    "sigs.k8s.io/controller-runtime/pkg/webhook"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    qraiopv1 "github.com/Bailey7220/QRAIOP/controllers/api/v1"
+)
+
+// RegistryPolicyValidator refuses pods with images from registries outside
+// the allowlists of the instances covering their namespace. Only
+// namespaces labelled qraiop.io/registry-policy are sent to it.
+type RegistryPolicyValidator struct {
+    Client client.Client
+}
+
+// +kubebuilder:webhook:path=/validate--v1-pod,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create;update,versions=v1,name=vregistrypolicy.qraiop.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=qraiop.io,resources=qraiops;qraiopsecuritypolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+func (v *RegistryPolicyValidator) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&corev1.Pod{}).
+        WithValidator(v).
+        Complete()
+}
+
+func (v *RegistryPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+    pod := obj.(*corev1.Pod)
+    return v.validate(ctx, pod, podImages(pod))
+}
+
+// ValidateUpdate only checks images the update introduces, such as
+// ephemeral debug containers, so existing pods keep being updatable
+func (v *RegistryPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+    old, pod := oldObj.(*corev1.Pod), newObj.(*corev1.Pod)
+    known := make(map[string]bool)
+    for _, image := range podImages(old) {
+        known[image] = true
+    }
+    var added []string
+    for _, image := range podImages(pod) {
+        if !known[image] {
+            added = append(added, image)
+        }
+    }
+    if len(added) == 0 {
+        return nil, nil
+    }
+    return v.validate(ctx, pod, added)
+}
+
+func (v *RegistryPolicyValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}
+
+func (v *RegistryPolicyValidator) validate(ctx context.Context, pod *corev1.Pod, images []string) (admission.Warnings, error) {
+    policies, err := registryPolicies(ctx, v.Client, pod.Namespace)
+    if err != nil || len(policies) == 0 {
+        return nil, err
+    }
+    if reason, ok := pod.Annotations[qraiopv1.RegistryBypassAnnotation]; ok {
+        req, err := admission.RequestFromContext(ctx)
+        if err != nil {
+            return nil, err
+        }
+        // Pods the workload controllers create carry the annotation of
+        // their template, which was checked when it was set
+        if metav1.GetControllerOf(pod) != nil && workloadController(req.UserInfo) {
+            return admission.Warnings{"registry policy bypassed: " + reason}, nil
+        }
+        if err := mayBypass(ctx, v.Client, req.UserInfo, pod.Namespace); err != nil {
+            return nil, err
+        }
+        return admission.Warnings{"registry policy bypassed: " + reason}, nil
+    }
+
+    var warnings admission.Warnings
+    var denied []string
+    for _, image := range images {
+        for _, p := range policies {
+            if registryAllowed(image, p.policy.Allowed) {
+                continue
+            }
+            msg := fmt.Sprintf("image %s is not from a registry allowed by %s", image, p.source)
+            if p.policy.Mode == qraiopv1.RegistryPolicyAudit {
+                warnings = append(warnings, msg)
+            } else {
+                denied = append(denied, msg)
+            }
+        }
+    }
+    if len(denied) > 0 {
+        return warnings, fmt.Errorf("%s", strings.Join(denied, "; "))
+    }
+    return warnings, nil
+}
+
+// RegistryBypassValidator guards the bypass annotation on the pod
+// templates of workloads, whose pods are then trusted to carry it
+type RegistryBypassValidator struct {
+    Client client.Client
+}
+
+// +kubebuilder:webhook:path=/validate-registry-bypass,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps;batch,resources=deployments;statefulsets;daemonsets;replicasets;jobs;cronjobs,verbs=create;update,versions=v1,name=vregistrybypass.qraiop.io,admissionReviewVersions=v1
+
+func (v *RegistryBypassValidator) SetupWithManager(mgr ctrl.Manager) error {
+    mgr.GetWebhookServer().Register("/validate-registry-bypass", &webhook.Admission{Handler: v})
+    return nil
+}
+
+func (v *RegistryBypassValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+    reason, err := templateBypass(req.Object.Raw)
+    if err != nil {
+        return admission.Errored(http.StatusBadRequest, err)
+    }
+    // ReplicaSets and Jobs the controllers create copy an owner's template
+    if reason == "" || workloadController(req.UserInfo) {
+        return admission.Allowed("")
+    }
+    // Unchanged annotations were authorized when they were set
+    if len(req.OldObject.Raw) > 0 {
+        if old, err := templateBypass(req.OldObject.Raw); err == nil && old == reason {
+            return admission.Allowed("")
+        }
+    }
+    policies, err := registryPolicies(ctx, v.Client, req.Namespace)
+    if err != nil {
+        return admission.Errored(http.StatusInternalServerError, err)
+    }
+    if len(policies) == 0 {
+        return admission.Allowed("")
+    }
+    if err := mayBypass(ctx, v.Client, req.UserInfo, req.Namespace); err != nil {
+        return admission.Denied(err.Error())
+    }
+    return admission.Allowed("").WithWarnings("registry policy bypassed: " + reason)
+}
+
+// templateBypass returns the bypass annotation of a workload's pod template
+func templateBypass(raw []byte) (string, error) {
+    var obj unstructured.Unstructured
+    if err := json.Unmarshal(raw, &obj.Object); err != nil {
+        return "", err
+    }
+    path := []string{"spec", "template", "metadata", "annotations"}
+    if obj.GetKind() == "CronJob" {
+        path = []string{"spec", "jobTemplate", "spec", "template", "metadata", "annotations"}
+    }
+    annotations, _, _ := unstructured.NestedStringMap(obj.Object, path...)
+    reason, ok := annotations[qraiopv1.RegistryBypassAnnotation]
+    if ok && reason == "" {
+        reason = "no reason given"
+    }
+    return reason, nil
+}
+
+// mayBypass checks that user holds the bypass verb on registrypolicies in
+// the namespace
+func mayBypass(ctx context.Context, c client.Client, user authenticationv1.UserInfo, namespace string) error {
+    extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+    for k, v := range user.Extra {
+        extra[k] = authorizationv1.ExtraValue(v)
+    }
+    sar := &authorizationv1.SubjectAccessReview{
+        Spec: authorizationv1.SubjectAccessReviewSpec{
+            User:   user.Username,
+            UID:    user.UID,
+            Groups: user.Groups,
+            Extra:  extra,
+            ResourceAttributes: &authorizationv1.ResourceAttributes{
+                Namespace: namespace,
+                Verb:      "bypass",
+                Group:     qraiopv1.GroupVersion.Group,
+                Resource:  "registrypolicies",
+            },
+        },
+    }
+    if err := c.Create(ctx, sar); err != nil {
+        return err
+    }
+    if !sar.Status.Allowed {
+        return fmt.Errorf("%s may not set %s in namespace %s", user.Username, qraiopv1.RegistryBypassAnnotation, namespace)
+    }
+    return nil
+}
+
+// namespacePolicy is a registry policy covering a namespace and the
+// instance it comes from
+type namespacePolicy struct {
+    policy *qraiopv1.RegistryPolicy
+    source string
+}
+
+// registryPolicies returns the enabled registry policies of the instances
+// covering namespace, resolving referenced QraiopSecurityPolicies
+func registryPolicies(ctx context.Context, c client.Reader, namespace string) ([]namespacePolicy, error) {
+    var instances qraiopv1.QraiopList
+    if err := c.List(ctx, &instances); err != nil {
+        return nil, err
+    }
+    var policies []namespacePolicy
+    for i := range instances.Items {
+        q := &instances.Items[i]
+        cfg := q.Spec.SecurityPolicies
+        if ref := q.Spec.SecurityPolicyRef; ref != nil {
+            var policy qraiopv1.QraiopSecurityPolicy
+            if err := c.Get(ctx, client.ObjectKey{Namespace: q.Namespace, Name: ref.Name}, &policy); err != nil {
+                // The instance is blocked without its policy; so are its pods
+                return nil, fmt.Errorf("security policy %s of %s/%s: %w", ref.Name, q.Namespace, q.Name, err)
+            }
+            cfg = policy.Spec.SecurityConfig
+        }
+        reg := cfg.Registries
+        if reg == nil || !reg.Enabled {
+            continue
+        }
+        covered := q.Namespace == namespace
+        for _, ns := range reg.Namespaces {
+            covered = covered || ns == namespace
+        }
+        if covered {
+            policies = append(policies, namespacePolicy{policy: reg, source: "qraiop " + q.Namespace + "/" + q.Name})
+        }
+    }
+    sort.Slice(policies, func(i, j int) bool { return policies[i].source < policies[j].source })
+    return policies, nil
+}
+
+func podImages(pod *corev1.Pod) []string {
+    var images []string
+    for _, c := range pod.Spec.InitContainers {
+        images = append(images, c.Image)
+    }
+    for _, c := range pod.Spec.Containers {
+        images = append(images, c.Image)
+    }
+    for _, c := range pod.Spec.EphemeralContainers {
+        images = append(images, c.Image)
+    }
+    return images
+}
+
+// workloadController reports whether user is the controller manager,
+// which creates the pods of workloads
+func workloadController(user authenticationv1.UserInfo) bool {
+    return user.Username == "system:kube-controller-manager" ||
+        strings.HasPrefix(user.Username, "system:serviceaccount:kube-system:")
+}
+
+// imageRegistry splits an image reference into its registry and
+// repository, defaulting to Docker Hub like the container runtime
+func imageRegistry(image string) (string, string) {
+    name := image
+    if i := strings.Index(name, "@"); i >= 0 {
+        name = name[:i]
+    }
+    if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+        name = name[:i]
+    }
+    host, repo, found := strings.Cut(name, "/")
+    if !found || !(strings.ContainsAny(host, ".:") || host == "localhost") {
+        if !strings.Contains(name, "/") {
+            name = "library/" + name
+        }
+        return "docker.io", name
+    }
+    host = strings.ToLower(host)
+    if host == "index.docker.io" || host == "registry-1.docker.io" {
+        host = "docker.io"
+    }
+    if host == "docker.io" && !strings.Contains(repo, "/") {
+        repo = "library/" + repo
+    }
+    return host, repo
+}
+
+// registryAllowed reports whether image comes from one of the allowed
+// registries. Entries may carry a repository prefix and start with *. to
+// match any subdomain.
+func registryAllowed(image string, allowed []string) bool {
+    registry, repo := imageRegistry(image)
+    for _, a := range allowed {
+        host, prefix, _ := strings.Cut(strings.TrimSuffix(strings.ToLower(a), "/"), "/")
+        if strings.HasPrefix(host, "*.") {
+            if !strings.HasSuffix(registry, host[1:]) {
+                continue
+            }
+        } else if host != registry {
+            continue
+        }
+        if prefix == "" || repo == prefix || strings.HasPrefix(repo, prefix+"/") {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,41 @@
+// src/controllers/webhooks/templates.go
+package webhooks
+
+import (
+    "context"
+
+    corev1 "k8s.io/api/core/v1"
+    "k8s.io/apimachinery/pkg/runtime"
+    ctrl "sigs.k8s.io/controller-runtime"
+    "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+    "github.com/Bailey7220/QRAIOP/controllers/alerting"
+)
+
+// NotificationTemplateValidator refuses ConfigMaps of notification
+// templates that don't parse or fail on a sample notification. Only
+// ConfigMaps labelled qraiop.io/notification-templates are sent to it.
+type NotificationTemplateValidator struct{}
+
+// +kubebuilder:webhook:path=/validate--v1-configmap,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=configmaps,verbs=create;update,versions=v1,name=vnotificationtemplates.qraiop.io,admissionReviewVersions=v1
+
+func (v *NotificationTemplateValidator) SetupWithManager(mgr ctrl.Manager) error {
+    return ctrl.NewWebhookManagedBy(mgr).
+        For(&corev1.ConfigMap{}).
+        WithValidator(v).
+        Complete()
+}
+
+func (v *NotificationTemplateValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+    _, err := alerting.ParseTemplates(obj.(*corev1.ConfigMap).Data)
+    return nil, err
+}
+
+func (v *NotificationTemplateValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+    _, err := alerting.ParseTemplates(newObj.(*corev1.ConfigMap).Data)
+    return nil, err
+}
+
+func (v *NotificationTemplateValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+    return nil, nil
+}